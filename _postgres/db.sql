@@ -1,9 +1,130 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS roles
+(
+    name VARCHAR(15) PRIMARY KEY NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS permissions
+(
+    name VARCHAR(50) PRIMARY KEY NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+    role       VARCHAR(15) REFERENCES roles (name) ON DELETE CASCADE       NOT NULL,
+    permission VARCHAR(50) REFERENCES permissions (name) ON DELETE CASCADE NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name)
+VALUES ('default'),
+       ('editor'),
+       ('moderator'),
+       ('admin')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO permissions (name)
+VALUES ('film:write'),
+       ('actor:write'),
+       ('review:moderate'),
+       ('stats:read'),
+       ('user:manage')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('editor', 'film:write'),
+       ('editor', 'actor:write'),
+       ('editor', 'stats:read'),
+       ('moderator', 'review:moderate'),
+       ('admin', 'film:write'),
+       ('admin', 'actor:write'),
+       ('admin', 'review:moderate'),
+       ('admin', 'stats:read'),
+       ('admin', 'user:manage')
+ON CONFLICT DO NOTHING;
+
 CREATE TABLE IF NOT EXISTS "users"
 (
-    id       SERIAL PRIMARY KEY NOT NULL,
-    username VARCHAR(255)       NOT NULL,
-    password VARCHAR(255)       NOT NULL,
-    role     VARCHAR(15)        NOT NULL
+    id                 SERIAL PRIMARY KEY       NOT NULL,
+    username           VARCHAR(255)             NOT NULL,
+    password           VARCHAR(255)             NOT NULL,
+    role               VARCHAR(15)              NOT NULL REFERENCES roles (name),
+    is_disabled        BOOLEAN                  NOT NULL DEFAULT FALSE,
+    email              VARCHAR(255),
+    display_name       VARCHAR(100)             NOT NULL DEFAULT '',
+    avatar_url         VARCHAR(2048)            NOT NULL DEFAULT '',
+    preferred_language VARCHAR(3)               NOT NULL DEFAULT '',
+    created_at         TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS sessions
+(
+    id             VARCHAR(64) PRIMARY KEY                     NOT NULL,
+    user_id        INT REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    created_at     TIMESTAMP WITH TIME ZONE                    NOT NULL,
+    last_seen_at   TIMESTAMP WITH TIME ZONE                    NOT NULL,
+    expires_at     TIMESTAMP WITH TIME ZONE                    NOT NULL,
+    max_expires_at TIMESTAMP WITH TIME ZONE                    NOT NULL,
+    ip             TEXT                                        NOT NULL DEFAULT '',
+    user_agent     TEXT                                        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions (expires_at);
+
+CREATE TABLE IF NOT EXISTS user_identities
+(
+    provider   VARCHAR(50)                                 NOT NULL,
+    subject    VARCHAR(255)                                NOT NULL,
+    user_id    INT REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE                    NOT NULL DEFAULT now(),
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens
+(
+    token_hash CHAR(64) PRIMARY KEY                            NOT NULL,
+    user_id    INT REFERENCES users (id) ON DELETE CASCADE      NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE                        NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE                        NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS password_reset_tokens_user_id_idx ON password_reset_tokens (user_id);
+
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id           SERIAL PRIMARY KEY                               NOT NULL,
+    user_id      INT REFERENCES users (id) ON DELETE CASCADE       NOT NULL,
+    name         VARCHAR(100)                                     NOT NULL,
+    prefix       VARCHAR(20)                                      NOT NULL,
+    key_hash     CHAR(64) UNIQUE                                  NOT NULL,
+    scopes       TEXT                                             NOT NULL DEFAULT '',
+    expires_at   TIMESTAMP WITH TIME ZONE,
+    created_at   TIMESTAMP WITH TIME ZONE                         NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys (user_id);
+
+CREATE TABLE IF NOT EXISTS user_totp
+(
+    user_id        INT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    secret         VARCHAR(64)                                        NOT NULL,
+    is_enabled     BOOLEAN                                            NOT NULL DEFAULT FALSE,
+    last_used_step BIGINT                                             NOT NULL DEFAULT 0,
+    created_at     TIMESTAMP WITH TIME ZONE                           NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS totp_recovery_codes
+(
+    id        SERIAL PRIMARY KEY                         NOT NULL,
+    user_id   INT REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    code_hash CHAR(64)                                   NOT NULL,
+    UNIQUE (user_id, code_hash)
 );
 
 
@@ -20,11 +141,12 @@ CREATE TABLE IF NOT EXISTS "films"
 
 CREATE TABLE IF NOT EXISTS "actors"
 (
-    id       SERIAL PRIMARY KEY NOT NULL,
-    name     VARCHAR(100)       NOT NULL,
-    surname  VARCHAR(100)       NOT NULL,
-    gender   VARCHAR(6)         NOT NULL,
-    birthday TIMESTAMP          NOT NULL
+    id         SERIAL PRIMARY KEY NOT NULL,
+    name       VARCHAR(100)       NOT NULL,
+    surname    VARCHAR(100)       NOT NULL,
+    gender     VARCHAR(6)         NOT NULL,
+    birthday   TIMESTAMP          NOT NULL,
+    death_date TIMESTAMP
 );
 
 CREATE TABLE IF NOT EXISTS film_actors
@@ -34,6 +156,52 @@ CREATE TABLE IF NOT EXISTS film_actors
     PRIMARY KEY (film_id, actor_id)
 );
 
+CREATE TABLE IF NOT EXISTS actor_aliases
+(
+    old_id   INT PRIMARY KEY NOT NULL,
+    actor_id INT REFERENCES actors (id) ON DELETE CASCADE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS film_aliases
+(
+    old_id  INT PRIMARY KEY NOT NULL,
+    film_id INT REFERENCES films (id) ON DELETE CASCADE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS film_titles
+(
+    film_id     INT REFERENCES films (id) ON DELETE CASCADE NOT NULL,
+    locale      VARCHAR(3)                                  NOT NULL,
+    title       VARCHAR(150)                                NOT NULL,
+    is_original BOOLEAN                                     NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (film_id, locale)
+);
+
+CREATE TABLE IF NOT EXISTS actor_names
+(
+    actor_id INT REFERENCES actors (id) ON DELETE CASCADE NOT NULL,
+    locale   VARCHAR(3)                                   NOT NULL,
+    name     VARCHAR(100)                                 NOT NULL,
+    surname  VARCHAR(100)                                 NOT NULL,
+    PRIMARY KEY (actor_id, locale)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (LOWER(username));
+
+CREATE INDEX IF NOT EXISTS idx_actor_aliases_actor_id ON actor_aliases (actor_id);
+
+CREATE INDEX IF NOT EXISTS idx_film_aliases_film_id ON film_aliases (film_id);
+
 CREATE INDEX IF NOT EXISTS idx_actor_id ON actors (id);
 
 CREATE INDEX IF NOT EXISTS idx_film_id ON films (id);
+
+CREATE INDEX IF NOT EXISTS idx_films_name_trgm ON films USING GIN (LOWER(name) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_actors_name_surname_birthday ON actors (LOWER(name), LOWER(surname), birthday);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_film_titles_original ON film_titles (film_id) WHERE is_original;
+
+CREATE INDEX IF NOT EXISTS idx_film_titles_title_trgm ON film_titles USING GIN (LOWER(title) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_actor_names_name_surname ON actor_names (LOWER(name || ' ' || surname));
@@ -0,0 +1,28 @@
+-- Добавляет уникальный индекс без учета регистра на users.username в существующую базу.
+-- Новые базы получают индекс из db.sql. Если в базе уже есть пользователи, чьи имена
+-- совпадают без учета регистра, миграция ничего не меняет и перечисляет их в ошибке:
+-- дубликаты нужно переименовать или удалить и запустить миграцию снова.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/001_users_username_unique.sql
+
+DO
+$$
+    DECLARE
+        duplicates TEXT;
+    BEGIN
+        SELECT string_agg(format('%s (ids: %s)', lower_username, ids), E'\n')
+        INTO duplicates
+        FROM (SELECT LOWER(username)                          AS lower_username,
+                     string_agg(id::TEXT, ', ' ORDER BY id) AS ids
+              FROM users
+              GROUP BY LOWER(username)
+              HAVING COUNT(*) > 1) AS duplicate_usernames;
+
+        IF duplicates IS NOT NULL THEN
+            RAISE EXCEPTION 'users with duplicate usernames found, resolve them before creating the index:%',
+                E'\n' || duplicates;
+        END IF;
+
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (LOWER(username));
+    END
+$$;
@@ -0,0 +1,15 @@
+-- Добавляет в существующую базу таблицу связей пользователей с учетными записями внешних
+-- провайдеров OpenID Connect. Новые базы получают ее из db.sql.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/002_user_identities.sql
+
+CREATE TABLE IF NOT EXISTS user_identities
+(
+    provider   VARCHAR(50)                                 NOT NULL,
+    subject    VARCHAR(255)                                NOT NULL,
+    user_id    INT REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE                    NOT NULL DEFAULT now(),
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
@@ -0,0 +1,20 @@
+-- Добавляет в существующую базу таблицу sessions для хранения сессий в PostgreSQL
+-- (sessionStore=postgres). Новые базы получают ее из db.sql.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/003_sessions.sql
+
+CREATE TABLE IF NOT EXISTS sessions
+(
+    id             VARCHAR(64) PRIMARY KEY                     NOT NULL,
+    user_id        INT REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    created_at     TIMESTAMP WITH TIME ZONE                    NOT NULL,
+    last_seen_at   TIMESTAMP WITH TIME ZONE                    NOT NULL,
+    expires_at     TIMESTAMP WITH TIME ZONE                    NOT NULL,
+    max_expires_at TIMESTAMP WITH TIME ZONE                    NOT NULL,
+    ip             TEXT                                        NOT NULL DEFAULT '',
+    user_agent     TEXT                                        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions (expires_at);
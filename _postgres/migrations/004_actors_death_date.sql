@@ -0,0 +1,6 @@
+-- Добавляет в существующую базу дату смерти актера. Новые базы получают столбец из db.sql.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/004_actors_death_date.sql
+
+ALTER TABLE actors
+    ADD COLUMN IF NOT EXISTS death_date TIMESTAMP;
@@ -0,0 +1,6 @@
+-- Добавляет в существующую базу признак блокировки пользователя. Новые базы получают столбец из db.sql.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/005_users_is_disabled.sql
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS is_disabled BOOLEAN NOT NULL DEFAULT FALSE;
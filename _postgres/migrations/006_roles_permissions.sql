@@ -0,0 +1,80 @@
+-- Добавляет в существующую базу таблицы ролей и прав и внешний ключ users.role -> roles.name.
+-- Новые базы получают их из db.sql. Если у пользователей есть роли, которых нет в roles,
+-- внешний ключ не создается, а миграция перечисляет таких пользователей в ошибке: им нужно
+-- назначить одну из ролей default, editor, moderator или admin и запустить миграцию снова.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/006_roles_permissions.sql
+
+CREATE TABLE IF NOT EXISTS roles
+(
+    name VARCHAR(15) PRIMARY KEY NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS permissions
+(
+    name VARCHAR(50) PRIMARY KEY NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+    role       VARCHAR(15) REFERENCES roles (name) ON DELETE CASCADE       NOT NULL,
+    permission VARCHAR(50) REFERENCES permissions (name) ON DELETE CASCADE NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name)
+VALUES ('default'),
+       ('editor'),
+       ('moderator'),
+       ('admin')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO permissions (name)
+VALUES ('film:write'),
+       ('actor:write'),
+       ('review:moderate'),
+       ('stats:read'),
+       ('user:manage')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('editor', 'film:write'),
+       ('editor', 'actor:write'),
+       ('editor', 'stats:read'),
+       ('moderator', 'review:moderate'),
+       ('admin', 'film:write'),
+       ('admin', 'actor:write'),
+       ('admin', 'review:moderate'),
+       ('admin', 'stats:read'),
+       ('admin', 'user:manage')
+ON CONFLICT DO NOTHING;
+
+DO
+$$
+    DECLARE
+        unknown_roles TEXT;
+    BEGIN
+        IF EXISTS(SELECT 1
+                  FROM pg_constraint
+                  WHERE conrelid = 'users'::regclass
+                    AND conname = 'users_role_fkey') THEN
+            RETURN;
+        END IF;
+
+        SELECT string_agg(format('%s (ids: %s)', role, ids), E'\n')
+        INTO unknown_roles
+        FROM (SELECT u.role, string_agg(u.id::TEXT, ', ' ORDER BY u.id) AS ids
+              FROM users u
+                       LEFT JOIN roles r ON r.name = u.role
+              WHERE r.name IS NULL
+              GROUP BY u.role) AS users_with_unknown_roles;
+
+        IF unknown_roles IS NOT NULL THEN
+            RAISE EXCEPTION 'users with unknown roles found, change their roles before creating the foreign key:%',
+                E'\n' || unknown_roles;
+        END IF;
+
+        ALTER TABLE users
+            ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles (name);
+    END
+$$;
@@ -0,0 +1,6 @@
+-- Добавляет в существующую базу email пользователя для сброса пароля. Новые базы получают столбец из db.sql.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/007_users_email.sql
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email VARCHAR(255);
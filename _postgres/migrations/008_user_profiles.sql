@@ -0,0 +1,10 @@
+-- Добавляет в существующую базу поля профиля пользователя. Новые базы получают их из db.sql.
+-- Пользователи, созданные до миграции, получают дату регистрации, равную времени ее применения.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/008_user_profiles.sql
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS display_name       VARCHAR(100)             NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS avatar_url         VARCHAR(2048)            NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS preferred_language VARCHAR(3)               NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS created_at         TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now();
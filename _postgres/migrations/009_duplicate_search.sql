@@ -0,0 +1,10 @@
+-- Добавляет в существующую базу расширение pg_trgm и индексы для поиска дубликатов фильмов и актеров.
+-- Новые базы получают их из db.sql.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/009_duplicate_search.sql
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_films_name_trgm ON films USING GIN (LOWER(name) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_actors_name_surname_birthday ON actors (LOWER(name), LOWER(surname), birthday);
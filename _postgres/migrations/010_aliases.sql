@@ -0,0 +1,20 @@
+-- Добавляет в существующую базу таблицы actor_aliases и film_aliases для старых id объединенных записей.
+-- Новые базы получают их из db.sql.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/010_aliases.sql
+
+CREATE TABLE IF NOT EXISTS actor_aliases
+(
+    old_id   INT PRIMARY KEY NOT NULL,
+    actor_id INT REFERENCES actors (id) ON DELETE CASCADE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS film_aliases
+(
+    old_id  INT PRIMARY KEY NOT NULL,
+    film_id INT REFERENCES films (id) ON DELETE CASCADE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_actor_aliases_actor_id ON actor_aliases (actor_id);
+
+CREATE INDEX IF NOT EXISTS idx_film_aliases_film_id ON film_aliases (film_id);
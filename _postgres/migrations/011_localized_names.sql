@@ -0,0 +1,30 @@
+-- Добавляет в существующую базу таблицы film_titles и actor_names с локализованными названиями фильмов и именами актеров.
+-- Новые базы получают их из db.sql.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/011_localized_names.sql
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS film_titles
+(
+    film_id     INT REFERENCES films (id) ON DELETE CASCADE NOT NULL,
+    locale      VARCHAR(3)                                  NOT NULL,
+    title       VARCHAR(150)                                NOT NULL,
+    is_original BOOLEAN                                     NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (film_id, locale)
+);
+
+CREATE TABLE IF NOT EXISTS actor_names
+(
+    actor_id INT REFERENCES actors (id) ON DELETE CASCADE NOT NULL,
+    locale   VARCHAR(3)                                   NOT NULL,
+    name     VARCHAR(100)                                 NOT NULL,
+    surname  VARCHAR(100)                                 NOT NULL,
+    PRIMARY KEY (actor_id, locale)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_film_titles_original ON film_titles (film_id) WHERE is_original;
+
+CREATE INDEX IF NOT EXISTS idx_film_titles_title_trgm ON film_titles USING GIN (LOWER(title) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_actor_names_name_surname ON actor_names (LOWER(name || ' ' || surname));
@@ -0,0 +1,19 @@
+-- Добавляет в существующую базу таблицу api_keys с персональными API ключами пользователей.
+-- Новые базы получают ее из db.sql.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/012_api_keys.sql
+
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id           SERIAL PRIMARY KEY                               NOT NULL,
+    user_id      INT REFERENCES users (id) ON DELETE CASCADE       NOT NULL,
+    name         VARCHAR(100)                                     NOT NULL,
+    prefix       VARCHAR(20)                                      NOT NULL,
+    key_hash     CHAR(64) UNIQUE                                  NOT NULL,
+    scopes       TEXT                                             NOT NULL DEFAULT '',
+    expires_at   TIMESTAMP WITH TIME ZONE,
+    created_at   TIMESTAMP WITH TIME ZONE                         NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys (user_id);
@@ -0,0 +1,21 @@
+-- Добавляет в существующую базу таблицы user_totp и totp_recovery_codes для второго фактора входа.
+-- Новые базы получают их из db.sql.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/013_totp.sql
+
+CREATE TABLE IF NOT EXISTS user_totp
+(
+    user_id        INT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    secret         VARCHAR(64)                                        NOT NULL,
+    is_enabled     BOOLEAN                                            NOT NULL DEFAULT FALSE,
+    last_used_step BIGINT                                             NOT NULL DEFAULT 0,
+    created_at     TIMESTAMP WITH TIME ZONE                           NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS totp_recovery_codes
+(
+    id        SERIAL PRIMARY KEY                         NOT NULL,
+    user_id   INT REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    code_hash CHAR(64)                                   NOT NULL,
+    UNIQUE (user_id, code_hash)
+);
@@ -0,0 +1,14 @@
+-- Добавляет в существующую базу таблицу password_reset_tokens с хешами токенов сброса пароля.
+-- Новые базы получают ее из db.sql.
+--
+-- psql -v ON_ERROR_STOP=1 -U "$user" -d "$dbName" -f _postgres/migrations/014_password_reset_tokens.sql
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens
+(
+    token_hash CHAR(64) PRIMARY KEY                            NOT NULL,
+    user_id    INT REFERENCES users (id) ON DELETE CASCADE      NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE                        NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE                        NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS password_reset_tokens_user_id_idx ON password_reset_tokens (user_id);
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ilyushkaaa/Filmoteka/config"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	sessionRepo "github.com/ilyushkaaa/Filmoteka/internal/session/repo"
+	sessionUseCase "github.com/ilyushkaaa/Filmoteka/internal/session/usecase"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	userRepo "github.com/ilyushkaaa/Filmoteka/internal/users/repo"
+	userUseCase "github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/dbinit"
+	"github.com/ilyushkaaa/Filmoteka/pkg/password_hash"
+)
+
+const usage = `usage:
+  filmotekactl user create --username NAME [--password PASSWORD] [--email EMAIL] [--role default|editor|moderator|admin]
+  filmotekactl user set-role --username NAME --role default|editor|moderator|admin
+  filmotekactl user reset-password --username NAME [--password PASSWORD]
+  filmotekactl user list
+  filmotekactl session revoke-all --user NAME
+
+If --password is omitted, the password is read from the first line of standard input.
+Connection settings are taken from the same environment variables, .env file and configFile as the API server.
+`
+
+var errUsage = errors.New("bad command line arguments")
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout)
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "filmotekactl: %s\n\n%s", err, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "filmotekactl: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	cfg, err := config.Load(flag.NewFlagSet("filmotekactl", flag.ContinueOnError), nil)
+	if err != nil {
+		return fmt.Errorf("bad config:\n%w", err)
+	}
+
+	command, subcommand, flagArgs := args[0], args[1], args[2:]
+	switch command + " " + subcommand {
+	case "user create":
+		return userCreate(cfg, flagArgs, stdin, stdout)
+	case "user set-role":
+		return userSetRole(cfg, flagArgs, stdout)
+	case "user reset-password":
+		return userResetPassword(cfg, flagArgs, stdin, stdout)
+	case "user list":
+		return userList(cfg, flagArgs, stdout)
+	case "session revoke-all":
+		return sessionRevokeAll(cfg, flagArgs, stdout)
+	default:
+		return errUsage
+	}
+}
+
+func newUserUseCase(cfg *config.Config) (*userUseCase.UserUseCaseApp, func(), error) {
+	hasher, err := password_hash.NewHasher(cfg.Auth.PasswordHasher, cfg.Auth.PasswordHashParams())
+	if err != nil {
+		return nil, nil, err
+	}
+	pgxDB, err := getPostgres(cfg.Postgres)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error in connection to postgres: %w", err)
+	}
+	closeDB := func() {
+		err := pgxDB.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "filmotekactl: error in closing postgres connection: %s\n", err)
+		}
+	}
+	return userUseCase.NewUserUseCase(userRepo.NewUserRepo(pgxDB), hasher), closeDB, nil
+}
+
+func newSessionUseCase(cfg *config.Config) (*sessionUseCase.SessionUseCaseApp, func(), error) {
+	var (
+		sr         sessionRepo.SessionRepo
+		closeStore func()
+	)
+	switch cfg.Session.Store {
+	case config.SessionStoreRedis:
+		redisPool, err := dbinit.GetRedis(cfg.Redis.Host, cfg.Redis.Port, dbinit.RedisOptions{
+			MaxIdle:             cfg.Redis.MaxIdle,
+			MaxActive:           cfg.Redis.MaxActive,
+			IdleTimeout:         cfg.Redis.IdleTimeout,
+			ConnectTimeout:      cfg.Redis.ConnectTimeout,
+			ReadTimeout:         cfg.Redis.ReadTimeout,
+			WriteTimeout:        cfg.Redis.WriteTimeout,
+			HealthCheckInterval: cfg.Redis.HealthCheckInterval,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("error in connection to redis: %w", err)
+		}
+		sr = sessionRepo.NewSessionRepo(redisPool)
+		closeStore = func() {
+			err := redisPool.Close()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "filmotekactl: error in closing redis connection: %s\n", err)
+			}
+		}
+	case config.SessionStorePostgres:
+		pgxDB, err := getPostgres(cfg.Postgres)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error in connection to postgres: %w", err)
+		}
+		sr = sessionRepo.NewSessionRepoPG(pgxDB)
+		closeStore = func() {
+			err := pgxDB.Close()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "filmotekactl: error in closing postgres connection: %s\n", err)
+			}
+		}
+	case config.SessionStoreMemory:
+		return nil, nil, fmt.Errorf("sessions are kept in the memory of the server process and can not be revoked from here")
+	default:
+		return nil, nil, fmt.Errorf("unknown session store: %s", cfg.Session.Store)
+	}
+	// утилита только отзывает сессии, поэтому время их жизни не влияет на ее работу
+	su := sessionUseCase.NewSessionUseCase(sr, sessionUseCase.DefaultIdleTimeout, sessionUseCase.DefaultAbsoluteLifetime)
+	return su, closeStore, nil
+}
+
+func getPostgres(pgConfig config.PostgresConfig) (*sql.DB, error) {
+	return dbinit.GetPostgres(pgConfig.Host, pgConfig.Port, pgConfig.User, string(pgConfig.Password),
+		pgConfig.DBName, pgConfig.SSLMode)
+}
+
+func parseFlags(flagSet *flag.FlagSet, args []string, required ...string) error {
+	flagSet.SetOutput(io.Discard)
+	err := flagSet.Parse(args)
+	if err != nil || flagSet.NArg() != 0 {
+		return errUsage
+	}
+	for _, name := range required {
+		if flagSet.Lookup(name).Value.String() == "" {
+			return fmt.Errorf("%w: --%s is required", errUsage, name)
+		}
+	}
+	return nil
+}
+
+func readPassword(password string, stdin io.Reader) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func validateCredentials(username, password, email string) error {
+	authRequest := &dto.AuthRequest{
+		Username: username,
+		Password: password,
+		Email:    email,
+	}
+	if validationErrors := authRequest.Validate(); len(validationErrors) != 0 {
+		return fmt.Errorf("validation failed: %s", strings.Join(validationErrors, "; "))
+	}
+	return nil
+}
+
+func userCreate(cfg *config.Config, args []string, stdin io.Reader, stdout io.Writer) error {
+	flagSet := flag.NewFlagSet("user create", flag.ContinueOnError)
+	username := flagSet.String("username", "", "")
+	password := flagSet.String("password", "", "")
+	email := flagSet.String("email", "", "")
+	role := flagSet.String("role", entity.RoleDefault, "")
+	if err := parseFlags(flagSet, args, "username"); err != nil {
+		return err
+	}
+	userPassword, err := readPassword(*password, stdin)
+	if err != nil {
+		return err
+	}
+	if err = validateCredentials(*username, userPassword, *email); err != nil {
+		return err
+	}
+
+	uu, closeDB, err := newUserUseCase(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	newUser, err := uu.CreateUser(context.Background(), *username, userPassword, *email, *role)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "created user %s with id %d and role %s\n", newUser.Username, newUser.ID, newUser.Role)
+	return nil
+}
+
+func userSetRole(cfg *config.Config, args []string, stdout io.Writer) error {
+	flagSet := flag.NewFlagSet("user set-role", flag.ContinueOnError)
+	username := flagSet.String("username", "", "")
+	role := flagSet.String("role", "", "")
+	if err := parseFlags(flagSet, args, "username", "role"); err != nil {
+		return err
+	}
+
+	uu, closeDB, err := newUserUseCase(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	user, err := uu.GetUserByUsername(context.Background(), *username)
+	if err != nil {
+		return err
+	}
+	err = uu.SetUserRole(context.Background(), user.ID, *role)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "user %s now has role %s\n", user.Username, *role)
+	return nil
+}
+
+func userResetPassword(cfg *config.Config, args []string, stdin io.Reader, stdout io.Writer) error {
+	flagSet := flag.NewFlagSet("user reset-password", flag.ContinueOnError)
+	username := flagSet.String("username", "", "")
+	password := flagSet.String("password", "", "")
+	if err := parseFlags(flagSet, args, "username"); err != nil {
+		return err
+	}
+	newPassword, err := readPassword(*password, stdin)
+	if err != nil {
+		return err
+	}
+	if err = validateCredentials(*username, newPassword, ""); err != nil {
+		return err
+	}
+
+	uu, closeDB, err := newUserUseCase(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	user, err := uu.GetUserByUsername(context.Background(), *username)
+	if err != nil {
+		return err
+	}
+	err = uu.ResetPassword(context.Background(), user.ID, newPassword)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "password of user %s has been reset\n", user.Username)
+	return nil
+}
+
+func userList(cfg *config.Config, args []string, stdout io.Writer) error {
+	flagSet := flag.NewFlagSet("user list", flag.ContinueOnError)
+	if err := parseFlags(flagSet, args); err != nil {
+		return err
+	}
+
+	uu, closeDB, err := newUserUseCase(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	users, _, err := uu.GetUsers(context.Background(), "", 0, 0)
+	if err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tUSERNAME\tROLE\tDISABLED")
+	for _, user := range users {
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%t\n", user.ID, user.Username, user.Role, user.IsDisabled)
+	}
+	return writer.Flush()
+}
+
+func sessionRevokeAll(cfg *config.Config, args []string, stdout io.Writer) error {
+	flagSet := flag.NewFlagSet("session revoke-all", flag.ContinueOnError)
+	username := flagSet.String("user", "", "")
+	if err := parseFlags(flagSet, args, "user"); err != nil {
+		return err
+	}
+
+	uu, closeDB, err := newUserUseCase(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	user, err := uu.GetUserByUsername(context.Background(), *username)
+	if err != nil {
+		return err
+	}
+
+	su, closeStore, err := newSessionUseCase(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+	revokedCount, err := su.DeleteUserSessions(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "revoked %d sessions of user %s\n", revokedCount, user.Username)
+	return nil
+}
@@ -1,31 +1,67 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/gomodule/redigo/redis"
 	"github.com/gorilla/mux"
 	"github.com/ilyushkaaa/Filmoteka/config"
 	_ "github.com/ilyushkaaa/Filmoteka/docs"
 	actorDelivery "github.com/ilyushkaaa/Filmoteka/internal/actors/delivery"
 	actorRepo "github.com/ilyushkaaa/Filmoteka/internal/actors/repo"
 	actorUseCase "github.com/ilyushkaaa/Filmoteka/internal/actors/usecase"
+	apiKeyDelivery "github.com/ilyushkaaa/Filmoteka/internal/apikeys/delivery"
+	apiKeyRepo "github.com/ilyushkaaa/Filmoteka/internal/apikeys/repo"
+	apiKeyUseCase "github.com/ilyushkaaa/Filmoteka/internal/apikeys/usecase"
 	filmDelivery "github.com/ilyushkaaa/Filmoteka/internal/films/delivery"
 	filmRepo "github.com/ilyushkaaa/Filmoteka/internal/films/repo"
 	filmUseCase "github.com/ilyushkaaa/Filmoteka/internal/films/usecase"
+	healthDelivery "github.com/ilyushkaaa/Filmoteka/internal/health/delivery"
+	lockoutRepo "github.com/ilyushkaaa/Filmoteka/internal/lockout/repo"
+	lockoutUseCase "github.com/ilyushkaaa/Filmoteka/internal/lockout/usecase"
 	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
+	oidcDelivery "github.com/ilyushkaaa/Filmoteka/internal/oidc/delivery"
+	oidcRepo "github.com/ilyushkaaa/Filmoteka/internal/oidc/repo"
+	oidcUseCase "github.com/ilyushkaaa/Filmoteka/internal/oidc/usecase"
+	passwordResetDelivery "github.com/ilyushkaaa/Filmoteka/internal/passwordreset/delivery"
+	passwordResetRepo "github.com/ilyushkaaa/Filmoteka/internal/passwordreset/repo"
+	passwordResetUseCase "github.com/ilyushkaaa/Filmoteka/internal/passwordreset/usecase"
+	sessionDelivery "github.com/ilyushkaaa/Filmoteka/internal/session/delivery"
 	sessionRepo "github.com/ilyushkaaa/Filmoteka/internal/session/repo"
 	sessionUseCase "github.com/ilyushkaaa/Filmoteka/internal/session/usecase"
+	statsDelivery "github.com/ilyushkaaa/Filmoteka/internal/stats/delivery"
+	statsRepo "github.com/ilyushkaaa/Filmoteka/internal/stats/repo"
+	statsUseCase "github.com/ilyushkaaa/Filmoteka/internal/stats/usecase"
+	tokenDelivery "github.com/ilyushkaaa/Filmoteka/internal/token/delivery"
+	tokenRepo "github.com/ilyushkaaa/Filmoteka/internal/token/repo"
+	tokenUseCase "github.com/ilyushkaaa/Filmoteka/internal/token/usecase"
+	twoFactorDelivery "github.com/ilyushkaaa/Filmoteka/internal/twofactor/delivery"
+	twoFactorRepo "github.com/ilyushkaaa/Filmoteka/internal/twofactor/repo"
+	twoFactorUseCase "github.com/ilyushkaaa/Filmoteka/internal/twofactor/usecase"
 	userDelivery "github.com/ilyushkaaa/Filmoteka/internal/users/delivery"
+	userEntity "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
 	userRepo "github.com/ilyushkaaa/Filmoteka/internal/users/repo"
 	userUseCase "github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/access_token"
+	"github.com/ilyushkaaa/Filmoteka/pkg/cookie"
 	"github.com/ilyushkaaa/Filmoteka/pkg/dbinit"
+	"github.com/ilyushkaaa/Filmoteka/pkg/mailer"
 	"github.com/ilyushkaaa/Filmoteka/pkg/password_hash"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"go.uber.org/zap"
 )
 
+var defaultOIDCScopes = []string{"profile", "email"}
+
 // @title Фильмотека
 // @description бэкенд приложения “Фильмотека”, который предоставляет REST API для управления базой данных фильмов.
 // @version 1.0
@@ -43,13 +79,20 @@ func main() {
 		}
 	}()
 
-	err = config.GetConfig()
+	flagSet := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	printConfig := flagSet.Bool("print-config", false, "print the resulting config with secrets redacted and exit")
+	cfg, err := config.Load(flagSet, os.Args[1:])
 	if err != nil {
-		logger.Errorf("error in getting config")
+		logger.Errorf("bad config:\n%s", err)
+		return
+	}
+	if *printConfig {
+		fmt.Print(cfg)
 		return
 	}
 
-	pgxDB, err := dbinit.GetPostgres()
+	pgxDB, err := dbinit.GetPostgres(cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.User,
+		string(cfg.Postgres.Password), cfg.Postgres.DBName, cfg.Postgres.SSLMode)
 	if err != nil {
 		logger.Errorf("error in connection to postgres: %s", err)
 		return
@@ -62,25 +105,67 @@ func main() {
 		}
 	}()
 
-	redisConn, err := dbinit.GetRedis()
+	redisPool, err := dbinit.GetRedis(cfg.Redis.Host, cfg.Redis.Port, redisOptions(cfg.Redis))
 	if err != nil {
-		logger.Infof("error on connection to redis: %s", err.Error())
+		logger.Errorf("error in connection to redis: %s", err)
+		return
 	}
 	defer func() {
-		err = redisConn.Close()
+		err = redisPool.Close()
 		if err != nil {
-			logger.Infof("error on redis close: %s", err.Error())
+			logger.Errorf("error on redis close: %s", err)
 		}
 	}()
 	logger.Infof("connected to redis")
 
-	sr := sessionRepo.NewSessionRepo(redisConn)
-	su := sessionUseCase.NewSessionUseCase(sr)
+	sr, err := newSessionRepo(cfg.Session.Store, redisPool, pgxDB)
+	if err != nil {
+		logger.Errorf("error in session store setup: %s", err)
+		return
+	}
+	if cleaner, ok := sr.(sessionRepo.ExpiredSessionsCleaner); ok {
+		stopSessionCleanup := startSessionCleanup(cleaner, cfg.Session.CleanupInterval, logger)
+		defer stopSessionCleanup()
+	}
+	su := sessionUseCase.NewSessionUseCase(sr, cfg.Session.IdleTimeout, cfg.Session.Lifetime)
 
-	hasher := &password_hash.SHA256Hasher{}
+	hasher, err := password_hash.NewHasher(cfg.Auth.PasswordHasher, cfg.Auth.PasswordHashParams())
+	if err != nil {
+		logger.Errorf("error in password hasher creation: %s", err)
+		return
+	}
 	ur := userRepo.NewUserRepo(pgxDB)
 	uu := userUseCase.NewUserUseCase(ur, hasher)
-	uh := userDelivery.NewUserHandler(uu, su)
+	tu, err := newTokenUseCase(cfg.Auth, redisPool, ur)
+	if err != nil {
+		logger.Errorf("error in token authentication setup: %s", err)
+		return
+	}
+	cookieConfig, err := cookie.NewConfig(cfg.Session.Cookie.Domain, cfg.Session.Cookie.Path,
+		cfg.Session.Cookie.Secure, cfg.Session.Cookie.SameSite)
+	if err != nil {
+		logger.Errorf("error in session cookie config: %s", err)
+		return
+	}
+	lr := lockoutRepo.NewLoginAttemptRepo(redisPool)
+	lu := lockoutUseCase.NewLockoutUseCase(lr, lockoutPolicy(cfg.Lockout))
+	tfr := twoFactorRepo.NewTOTPRepo(pgxDB, logger)
+	tcr := twoFactorRepo.NewLoginChallengeRepo(redisPool)
+	tfu := twoFactorUseCase.NewTwoFactorUseCase(tfr, tcr, ur)
+	tfh := twoFactorDelivery.NewTwoFactorHandler(tfu)
+	uh := userDelivery.NewUserHandler(uu, su, tu, lu, tfu, cookieConfig)
+	sh := sessionDelivery.NewSessionHandler(su, tu)
+
+	mailSender, closeMailer, err := newMailer(cfg.Mail)
+	if err != nil {
+		logger.Errorf("error in mailer setup: %s", err)
+		return
+	}
+	defer closeMailer()
+	prr := passwordResetRepo.NewPasswordResetRepo(pgxDB, logger)
+	pru := passwordResetUseCase.NewPasswordResetUseCase(prr, ur, hasher, mailSender, cfg.PasswordReset.TTL,
+		cfg.PasswordReset.URL)
+	prh := passwordResetDelivery.NewPasswordResetHandler(pru, su, tu)
 
 	fr := filmRepo.NewFilmRepo(pgxDB, logger)
 	fu := filmUseCase.NewFilmUseCase(fr)
@@ -90,20 +175,46 @@ func main() {
 	au := actorUseCase.NewActorUseCase(ar)
 	ah := actorDelivery.NewActorHandler(au)
 
-	mw := middleware.NewMiddleware(su, uu)
+	str := statsRepo.NewStatsRepo(pgxDB, logger)
+	stc := statsRepo.NewStatsCache(redisPool)
+	stu := statsUseCase.NewStatsUseCase(str, stc, cfg.Stats.CacheTTL)
+	sth := statsDelivery.NewStatsHandler(stu)
+
+	kr := apiKeyRepo.NewAPIKeyRepo(pgxDB)
+	ku := apiKeyUseCase.NewAPIKeyUseCase(kr, ur)
+	kh := apiKeyDelivery.NewAPIKeyHandler(ku)
+	ph := userDelivery.NewProfileHandler(uu, su, tu, ku, cookieConfig)
+
+	oir := oidcRepo.NewIdentityRepo(pgxDB)
+	osr := oidcRepo.NewLoginStateRepo(redisPool)
+	ou := oidcUseCase.NewOIDCUseCase(oidcProviders(cfg.OIDC), osr, oir, uu, cfg.OIDC.StateTTL)
+	oh := oidcDelivery.NewOIDCHandler(ou, su, tfu, cookieConfig, cfg.OIDC.PostLoginURL)
+
+	mw := middleware.NewMiddleware(su, uu, tu, ku, cookieConfig)
+	hh := healthDelivery.NewHealthHandler(map[string]healthDelivery.Check{
+		"postgres": pgxDB.PingContext,
+		"redis": func(ctx context.Context) error {
+			return pingRedis(ctx, redisPool)
+		},
+	})
 
 	mainRouter := mux.NewRouter()
 	router := mux.NewRouter()
-	adminRouter := mux.NewRouter()
-	authRouter := mux.NewRouter()
+	adminRouter := newAuthenticatedRouter(mw)
+	authRouter := newAuthenticatedRouter(mw)
+	meRouter := newAuthenticatedRouter(mw)
 
+	mainRouter.HandleFunc("/healthz", hh.Live).Methods(http.MethodGet)
+	mainRouter.HandleFunc("/readyz", hh.Ready).Methods(http.MethodGet)
 	mainRouter.PathPrefix("/api/v1").Handler(router)
 	mainRouter.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
 	router.PathPrefix("/api/v1/admin").Handler(adminRouter)
 	router.PathPrefix("/api/v1/logout").Handler(authRouter)
+	router.PathPrefix("/api/v1/me").Handler(meRouter)
 
 	router.HandleFunc("/api/v1/actor/{ACTOR_ID}", ah.GetActorByID).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/actor/{ACTOR_ID}/stats", ah.GetActorStats).Methods(http.MethodGet)
 	router.HandleFunc("/api/v1/actors", ah.GetActors).Methods(http.MethodGet)
 
 	router.HandleFunc("/api/v1/film/{FILM_ID}", fh.GetFilmByID).Methods(http.MethodGet)
@@ -112,34 +223,267 @@ func main() {
 	router.HandleFunc("/api/v1/film/search/{SEARCH_STR}", fh.GetFilmsBySearch).Methods(http.MethodGet)
 
 	router.HandleFunc("/api/v1/login", uh.Login).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/login/totp", uh.LoginTOTP).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/login/totp/enroll", tfh.EnrollForLogin).Methods(http.MethodPost)
 	router.HandleFunc("/api/v1/register", uh.Register).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/password/reset/request", prh.RequestPasswordReset).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/password/reset", prh.ResetPassword).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/auth/oidc/{PROVIDER}/start", oh.StartLogin).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/auth/oidc/{PROVIDER}/callback", oh.Callback).Methods(http.MethodGet)
+	if tu != nil {
+		th := tokenDelivery.NewTokenHandler(tu)
+		router.HandleFunc("/api/v1/token/refresh", th.Refresh).Methods(http.MethodPost)
+		router.HandleFunc("/api/v1/token/revoke", th.Revoke).Methods(http.MethodPost)
+	}
 	authRouter.HandleFunc("/api/v1/logout", uh.Logout).Methods(http.MethodPost)
+	authRouter.HandleFunc("/api/v1/logout-all", sh.LogoutAll).Methods(http.MethodPost)
 
-	adminRouter.HandleFunc("/api/v1/admin/actor/{ACTOR_ID}", ah.DeleteActor).Methods(http.MethodDelete)
-	adminRouter.HandleFunc("/api/v1/admin/actor", ah.UpdateActor).Methods(http.MethodPut)
-	adminRouter.HandleFunc("/api/v1/admin/actor", ah.AddActor).Methods(http.MethodPost)
+	meRouter.HandleFunc("/api/v1/me", ph.GetMe).Methods(http.MethodGet)
+	meRouter.HandleFunc("/api/v1/me", ph.UpdateMe).Methods(http.MethodPatch)
+	meRouter.HandleFunc("/api/v1/me", ph.DeleteMe).Methods(http.MethodDelete)
+	meRouter.HandleFunc("/api/v1/me/export", ph.ExportMe).Methods(http.MethodGet)
+	meRouter.HandleFunc("/api/v1/me/password", uh.ChangePassword).Methods(http.MethodPost)
+	meRouter.HandleFunc("/api/v1/me/sessions", sh.GetMySessions).Methods(http.MethodGet)
+	meRouter.HandleFunc("/api/v1/me/sessions/{SESSION_ID}", sh.DeleteMySession).Methods(http.MethodDelete)
+	meRouter.HandleFunc("/api/v1/me/totp", tfh.EnrollTOTP).Methods(http.MethodPost)
+	meRouter.HandleFunc("/api/v1/me/totp/verify", tfh.VerifyTOTP).Methods(http.MethodPost)
+	meRouter.HandleFunc("/api/v1/me/totp", tfh.DisableTOTP).Methods(http.MethodDelete)
+	meRouter.HandleFunc("/api/v1/me/api-keys", kh.GetMyAPIKeys).Methods(http.MethodGet)
+	meRouter.HandleFunc("/api/v1/me/api-keys", kh.CreateMyAPIKey).Methods(http.MethodPost)
+	meRouter.HandleFunc("/api/v1/me/api-keys/{KEY_ID}", kh.DeleteMyAPIKey).Methods(http.MethodDelete)
 
-	adminRouter.HandleFunc("/api/v1/admin/film/{FILM_ID}", fh.DeleteFilm).Methods(http.MethodDelete)
-	adminRouter.HandleFunc("/api/v1/admin/film", fh.UpdateFilm).Methods(http.MethodPut)
-	adminRouter.HandleFunc("/api/v1/admin/film", fh.AddFilm).Methods(http.MethodPost)
+	actorWrite := mw.RequirePermission(userEntity.PermissionActorWrite)
+	filmWrite := mw.RequirePermission(userEntity.PermissionFilmWrite)
+	statsRead := mw.RequirePermission(userEntity.PermissionStatsRead)
+	userManage := mw.RequirePermission(userEntity.PermissionUserManage)
 
-	router.Use(mw.RequestInitMiddleware)
-	router.Use(mw.AccessLog)
+	adminRouter.Handle("/api/v1/admin/actor/{ACTOR_ID}", actorWrite(http.HandlerFunc(ah.DeleteActor))).Methods(http.MethodDelete)
+	adminRouter.Handle("/api/v1/admin/actor", actorWrite(http.HandlerFunc(ah.UpdateActor))).Methods(http.MethodPut)
+	adminRouter.Handle("/api/v1/admin/actor", actorWrite(http.HandlerFunc(ah.AddActor))).Methods(http.MethodPost)
+	adminRouter.Handle("/api/v1/admin/actors/duplicates", actorWrite(http.HandlerFunc(ah.GetActorDuplicates))).Methods(http.MethodGet)
+	adminRouter.Handle("/api/v1/admin/actor/{ACTOR_ID}/merge", actorWrite(http.HandlerFunc(ah.MergeActors))).Methods(http.MethodPost)
+	adminRouter.Handle("/api/v1/admin/actor/{ACTOR_ID}/names", actorWrite(http.HandlerFunc(ah.SetActorNames))).Methods(http.MethodPut)
 
-	adminRouter.Use(mw.AuthMiddleware)
-	adminRouter.Use(mw.AdminMiddleware)
+	adminRouter.Handle("/api/v1/admin/film/{FILM_ID}", filmWrite(http.HandlerFunc(fh.DeleteFilm))).Methods(http.MethodDelete)
+	adminRouter.Handle("/api/v1/admin/film", filmWrite(http.HandlerFunc(fh.UpdateFilm))).Methods(http.MethodPut)
+	adminRouter.Handle("/api/v1/admin/film", filmWrite(http.HandlerFunc(fh.AddFilm))).Methods(http.MethodPost)
+	adminRouter.Handle("/api/v1/admin/films/duplicates", filmWrite(http.HandlerFunc(fh.GetFilmDuplicates))).Methods(http.MethodGet)
+	adminRouter.Handle("/api/v1/admin/film/{FILM_ID}/merge", filmWrite(http.HandlerFunc(fh.MergeFilms))).Methods(http.MethodPost)
+	adminRouter.Handle("/api/v1/admin/film/{FILM_ID}/titles", filmWrite(http.HandlerFunc(fh.SetFilmTitles))).Methods(http.MethodPut)
 
-	authRouter.Use(mw.AuthMiddleware)
+	adminRouter.Handle("/api/v1/admin/stats", statsRead(http.HandlerFunc(sth.GetCatalogStats))).Methods(http.MethodGet)
 
-	port := os.Getenv("appPort")
+	adminRouter.Handle("/api/v1/admin/users", userManage(http.HandlerFunc(uh.GetUsers))).Methods(http.MethodGet)
+	adminRouter.Handle("/api/v1/admin/users/{USER_ID}", userManage(http.HandlerFunc(uh.GetUserByID))).Methods(http.MethodGet)
+	adminRouter.Handle("/api/v1/admin/users/{USER_ID}", userManage(http.HandlerFunc(uh.DeleteUser))).Methods(http.MethodDelete)
+	adminRouter.Handle("/api/v1/admin/users/{USER_ID}/role", userManage(http.HandlerFunc(uh.SetUserRole))).Methods(http.MethodPut)
+	adminRouter.Handle("/api/v1/admin/users/{USER_ID}/disable", userManage(http.HandlerFunc(uh.DisableUser))).Methods(http.MethodPost)
+	adminRouter.Handle("/api/v1/admin/users/{USER_ID}/enable", userManage(http.HandlerFunc(uh.EnableUser))).Methods(http.MethodPost)
+	adminRouter.Handle("/api/v1/admin/users/{USER_ID}/unlock", userManage(http.HandlerFunc(uh.UnlockUserLogin))).Methods(http.MethodPost)
+	adminRouter.Handle("/api/v1/admin/users/{USER_ID}/sessions", userManage(http.HandlerFunc(sh.GetUserSessions))).Methods(http.MethodGet)
+	adminRouter.Handle("/api/v1/admin/users/{USER_ID}/sessions", userManage(http.HandlerFunc(sh.RevokeUserSessions))).Methods(http.MethodDelete)
+	adminRouter.Handle("/api/v1/admin/users/{USER_ID}/api-keys", userManage(http.HandlerFunc(kh.GetUserAPIKeys))).Methods(http.MethodGet)
+	adminRouter.Handle("/api/v1/admin/users/{USER_ID}/api-keys", userManage(http.HandlerFunc(kh.CreateUserAPIKey))).Methods(http.MethodPost)
+	adminRouter.Handle("/api/v1/admin/users/{USER_ID}/api-keys/{KEY_ID}", userManage(http.HandlerFunc(kh.DeleteUserAPIKey))).Methods(http.MethodDelete)
 
+	router.Use(mw.RequestInitMiddleware)
+	router.Use(mw.AccessLog)
+
+	server := &http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           mainRouter,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
 	logger.Infow("starting server",
 		"type", "START",
-		"addr", port,
+		"addr", cfg.HTTP.Addr,
 	)
-	err = http.ListenAndServe(port, mainRouter)
+	err = runServer(server, hh, cfg.HTTP, logger)
 	if err != nil {
-		logger.Fatalf("errror in server start")
+		logger.Errorf("error in server work: %s", err)
 	}
+}
+
+// runServer после сигнала ждет drainDelay, чтобы балансировщик перестал направлять запросы, и вызывает Shutdown
+func runServer(server *http.Server, hh *healthDelivery.HealthHandler, httpConfig config.HTTPConfig,
+	logger *zap.SugaredLogger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-serverErrors:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
 
+	logger.Infow("shutting down server",
+		"type", "STOP",
+		"drain_delay", httpConfig.DrainDelay,
+	)
+	hh.StartDraining()
+	time.Sleep(httpConfig.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), httpConfig.ShutdownTimeout)
+	defer cancel()
+	err := server.Shutdown(shutdownCtx)
+	if err != nil {
+		closeErr := server.Close()
+		if closeErr != nil {
+			logger.Errorf("error in closing server: %s", closeErr)
+		}
+		return fmt.Errorf("error in graceful shutdown: %w", err)
+	}
+	logger.Infof("server stopped")
+	return nil
+}
+
+func pingRedis(ctx context.Context, redisPool *redis.Pool) error {
+	conn, err := redisPool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, err = redis.DoContext(conn, ctx, "PING")
+	return err
+}
+
+func newAuthenticatedRouter(mw *middleware.Middleware) *mux.Router {
+	authenticatedRouter := mux.NewRouter()
+	authenticatedRouter.Use(mw.AuthMiddleware)
+	authenticatedRouter.Use(mw.CSRFMiddleware)
+	return authenticatedRouter
+}
+
+func newSessionRepo(store string, redisPool *redis.Pool, db *sql.DB) (sessionRepo.SessionRepo, error) {
+	switch store {
+	case config.SessionStoreRedis:
+		return sessionRepo.NewSessionRepo(redisPool), nil
+	case config.SessionStorePostgres:
+		return sessionRepo.NewSessionRepoPG(db), nil
+	case config.SessionStoreMemory:
+		return sessionRepo.NewSessionRepoMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown session store: %s", store)
+	}
+}
+
+func startSessionCleanup(cleaner sessionRepo.ExpiredSessionsCleaner, interval time.Duration,
+	logger *zap.SugaredLogger) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				deletedCount, err := cleaner.DeleteExpiredSessions(context.Background())
+				if err != nil {
+					logger.Errorf("error in deleting expired sessions: %s", err)
+					continue
+				}
+				logger.Infof("%d expired sessions were deleted", deletedCount)
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+func newTokenUseCase(authConfig config.AuthConfig, redisPool *redis.Pool,
+	ur userRepo.UserRepo) (tokenUseCase.TokenUseCase, error) {
+	if authConfig.Mode != config.AuthModeJWT {
+		return nil, nil
+	}
+	accessTokens, err := access_token.NewManager(authConfig.JWT.Algorithm, string(authConfig.JWT.Keys),
+		authConfig.JWT.ActiveKeyID, authConfig.JWT.AccessTTL)
+	if err != nil {
+		return nil, err
+	}
+	tr := tokenRepo.NewRefreshTokenRepo(redisPool)
+	return tokenUseCase.NewTokenUseCase(tr, ur, accessTokens, authConfig.JWT.RefreshTTL), nil
+}
+
+func redisOptions(redisConfig config.RedisConfig) dbinit.RedisOptions {
+	return dbinit.RedisOptions{
+		MaxIdle:             redisConfig.MaxIdle,
+		MaxActive:           redisConfig.MaxActive,
+		IdleTimeout:         redisConfig.IdleTimeout,
+		ConnectTimeout:      redisConfig.ConnectTimeout,
+		ReadTimeout:         redisConfig.ReadTimeout,
+		WriteTimeout:        redisConfig.WriteTimeout,
+		HealthCheckInterval: redisConfig.HealthCheckInterval,
+	}
+}
+
+func lockoutPolicy(lockoutConfig config.LockoutConfig) lockoutUseCase.Policy {
+	return lockoutUseCase.Policy{
+		UserThreshold: lockoutConfig.UserThreshold,
+		IPThreshold:   lockoutConfig.IPThreshold,
+		BaseDelay:     lockoutConfig.BaseDelay,
+		MaxDelay:      lockoutConfig.MaxDelay,
+		FailureWindow: lockoutConfig.FailureWindow,
+	}
+}
+
+func oidcProviders(oidcConfig config.OIDCConfig) []oidcUseCase.ProviderConfig {
+	providers := make([]oidcUseCase.ProviderConfig, 0, len(oidcConfig.Providers))
+	for _, p := range oidcConfig.Providers {
+		scopes := p.Scopes
+		if len(scopes) == 0 {
+			scopes = defaultOIDCScopes
+		}
+		providerConfig := oidcUseCase.ProviderConfig{
+			Name:         p.Name,
+			IssuerURL:    p.IssuerURL,
+			ClientID:     p.ClientID,
+			ClientSecret: string(p.ClientSecret),
+			RedirectURL:  p.RedirectURL,
+			Scopes:       scopes,
+			GroupsClaim:  p.GroupsClaim,
+		}
+		for _, mapping := range p.Roles {
+			providerConfig.RoleMappings = append(providerConfig.RoleMappings,
+				oidcUseCase.RoleMapping{Group: mapping.Group, Role: mapping.Role})
+		}
+		providers = append(providers, providerConfig)
+	}
+	return providers
+}
+
+func newMailer(mailConfig config.MailConfig) (mailer.Mailer, func(), error) {
+	switch mailConfig.Mailer {
+	case config.MailerSMTP:
+		return mailer.NewSMTPMailer(mailConfig.SMTP.Host, mailConfig.SMTP.Port, mailConfig.SMTP.Username,
+			string(mailConfig.SMTP.Password), mailConfig.From), func() {}, nil
+	case config.MailerFile:
+		if mailConfig.File == "" {
+			return mailer.NewFileMailer(os.Stdout, mailConfig.From), func() {}, nil
+		}
+		file, err := os.OpenFile(mailConfig.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, nil, err
+		}
+		closeFile := func() {
+			err := file.Close()
+			if err != nil {
+				log.Printf("error in closing mailer file: %s", err)
+			}
+		}
+		return mailer.NewFileMailer(file, mailConfig.From), closeFile, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown mailer: %s", mailConfig.Mailer)
+	}
 }
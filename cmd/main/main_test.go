@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ilyushkaaa/Filmoteka/config"
+	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
+	sessionEntity "github.com/ilyushkaaa/Filmoteka/internal/session/entity"
+	sessionMock "github.com/ilyushkaaa/Filmoteka/internal/session/usecase/mock"
+	"github.com/ilyushkaaa/Filmoteka/pkg/cookie"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestAuthenticatedRouterRequiresCSRFToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessionUseCase := sessionMock.NewMockSessionUseCase(ctrl)
+	sessionUseCase.EXPECT().GetSession(gomock.Any(), "qqq").
+		Return(&sessionEntity.Session{ID: "qqq", UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, false, nil).
+		AnyTimes()
+	mw := middleware.NewMiddleware(sessionUseCase, nil, nil, nil, &cookie.Config{Path: "/"})
+	authenticatedRouter := newAuthenticatedRouter(mw)
+	for _, path := range []string{"/api/v1/me/password", "/api/v1/logout", "/api/v1/admin/film"} {
+		authenticatedRouter.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}).Methods(http.MethodPost)
+	}
+
+	testCases := []struct {
+		path       string
+		csrfToken  string
+		statusCode int
+	}{
+		{path: "/api/v1/me/password", statusCode: http.StatusForbidden},
+		{path: "/api/v1/logout", statusCode: http.StatusForbidden},
+		{path: "/api/v1/admin/film", statusCode: http.StatusForbidden},
+		{path: "/api/v1/logout", csrfToken: cookie.CSRFToken("www"), statusCode: http.StatusForbidden},
+		{path: "/api/v1/me/password", csrfToken: cookie.CSRFToken("qqq"), statusCode: http.StatusOK},
+		{path: "/api/v1/logout", csrfToken: cookie.CSRFToken("qqq"), statusCode: http.StatusOK},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+		req.AddCookie(&http.Cookie{Name: cookie.SessionCookieName, Value: "qqq"})
+		if tc.csrfToken != "" {
+			req.Header.Set(cookie.CSRFHeaderName, tc.csrfToken)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), logger.MyLoggerKey, zap.NewNop().Sugar()))
+		recorder := httptest.NewRecorder()
+		authenticatedRouter.ServeHTTP(recorder, req)
+		assert.Equal(t, tc.statusCode, recorder.Result().StatusCode, tc.path)
+	}
+}
+
+func TestOIDCProviders(t *testing.T) {
+	providers := oidcProviders(config.OIDCConfig{Providers: []config.OIDCProviderConfig{
+		{
+			Name:         "file",
+			ClientSecret: config.Secret("client-secret"),
+			Roles:        []config.RoleMapping{{Group: "admins", Role: "admin"}},
+		},
+		{Name: "corp", Scopes: []string{"email", "groups"}},
+	}})
+	if assert.Len(t, providers, 2) {
+		assert.Equal(t, []string{"profile", "email"}, providers[0].Scopes)
+		assert.Equal(t, "client-secret", providers[0].ClientSecret)
+		assert.Equal(t, "admins", providers[0].RoleMappings[0].Group)
+		assert.Equal(t, "admin", providers[0].RoleMappings[0].Role)
+		assert.Equal(t, []string{"email", "groups"}, providers[1].Scopes)
+		assert.Empty(t, providers[1].RoleMappings)
+	}
+}
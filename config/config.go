@@ -1,10 +1,232 @@
 package config
 
 import (
-	"github.com/joho/godotenv"
+	"time"
+
+	"github.com/ilyushkaaa/Filmoteka/pkg/password_hash"
+)
+
+const (
+	AuthModeSession = "session"
+	AuthModeJWT     = "jwt"
+
+	SessionStoreRedis    = "redis"
+	SessionStorePostgres = "postgres"
+	SessionStoreMemory   = "memory"
+
+	MailerSMTP = "smtp"
+	MailerFile = "file"
 )
 
-func GetConfig() error {
-	envFilePath := ".env"
-	return godotenv.Load(envFilePath)
+// Config - настройки сервера и filmotekactl; тег env задает имя переменной окружения и флага
+type Config struct {
+	HTTP          HTTPConfig          `yaml:"http"`
+	Postgres      PostgresConfig      `yaml:"postgres"`
+	Redis         RedisConfig         `yaml:"redis"`
+	Session       SessionConfig       `yaml:"session"`
+	Auth          AuthConfig          `yaml:"auth"`
+	Lockout       LockoutConfig       `yaml:"lockout"`
+	Mail          MailConfig          `yaml:"mail"`
+	PasswordReset PasswordResetConfig `yaml:"passwordReset"`
+	Stats         StatsConfig         `yaml:"stats"`
+	OIDC          OIDCConfig          `yaml:"oidc"`
+}
+
+// HTTPConfig - при остановке сервер DrainDelay не проходит проверку готовности, затем до ShutdownTimeout ждет запросы
+type HTTPConfig struct {
+	Addr              string        `yaml:"addr" env:"appPort"`
+	ReadTimeout       time.Duration `yaml:"readTimeout" env:"httpReadTimeout"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" env:"httpReadHeaderTimeout"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout" env:"httpWriteTimeout"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout" env:"httpIdleTimeout"`
+	DrainDelay        time.Duration `yaml:"drainDelay" env:"httpDrainDelay"`
+	ShutdownTimeout   time.Duration `yaml:"shutdownTimeout" env:"httpShutdownTimeout"`
+}
+
+type PostgresConfig struct {
+	Host     string `yaml:"host" env:"hostPG"`
+	Port     string `yaml:"port" env:"portPG"`
+	User     string `yaml:"user" env:"user"`
+	Password Secret `yaml:"password" env:"pass"`
+	DBName   string `yaml:"dbName" env:"dbName"`
+	SSLMode  string `yaml:"sslMode" env:"sslMode"`
+}
+
+type RedisConfig struct {
+	Host                string        `yaml:"host" env:"hostRD"`
+	Port                string        `yaml:"port" env:"portRD"`
+	MaxIdle             int           `yaml:"maxIdle" env:"redisMaxIdle"`
+	MaxActive           int           `yaml:"maxActive" env:"redisMaxActive"`
+	IdleTimeout         time.Duration `yaml:"idleTimeout" env:"redisIdleTimeout"`
+	ConnectTimeout      time.Duration `yaml:"connectTimeout" env:"redisConnectTimeout"`
+	ReadTimeout         time.Duration `yaml:"readTimeout" env:"redisReadTimeout"`
+	WriteTimeout        time.Duration `yaml:"writeTimeout" env:"redisWriteTimeout"`
+	HealthCheckInterval time.Duration `yaml:"healthCheckInterval" env:"redisHealthCheckInterval"`
+}
+
+type SessionConfig struct {
+	Store           string        `yaml:"store" env:"sessionStore"`
+	IdleTimeout     time.Duration `yaml:"idleTimeout" env:"sessionIdleTimeout"`
+	Lifetime        time.Duration `yaml:"lifetime" env:"sessionLifetime"`
+	CleanupInterval time.Duration `yaml:"cleanupInterval" env:"sessionCleanupInterval"`
+	Cookie          CookieConfig  `yaml:"cookie"`
+}
+
+type CookieConfig struct {
+	Domain   string `yaml:"domain" env:"sessionCookieDomain"`
+	Path     string `yaml:"path" env:"sessionCookiePath"`
+	Secure   bool   `yaml:"secure" env:"sessionCookieSecure"`
+	SameSite string `yaml:"sameSite" env:"sessionCookieSameSite"`
+}
+
+// AuthConfig - Argon2Memory задается в КиБ
+type AuthConfig struct {
+	Mode           string    `yaml:"mode" env:"authMode"`
+	PasswordHasher string    `yaml:"passwordHasher" env:"passwordHasher"`
+	Argon2Time     int       `yaml:"argon2Time" env:"argon2Time"`
+	Argon2Memory   int       `yaml:"argon2Memory" env:"argon2Memory"`
+	Argon2Threads  int       `yaml:"argon2Threads" env:"argon2Threads"`
+	BcryptCost     int       `yaml:"bcryptCost" env:"bcryptCost"`
+	JWT            JWTConfig `yaml:"jwt"`
+}
+
+type JWTConfig struct {
+	Algorithm   string        `yaml:"algorithm" env:"jwtAlgorithm"`
+	Keys        Secret        `yaml:"keys" env:"jwtKeys"`
+	ActiveKeyID string        `yaml:"activeKeyID" env:"jwtActiveKeyID"`
+	AccessTTL   time.Duration `yaml:"accessTTL" env:"jwtAccessTTL"`
+	RefreshTTL  time.Duration `yaml:"refreshTTL" env:"jwtRefreshTTL"`
+}
+
+type LockoutConfig struct {
+	UserThreshold int           `yaml:"userThreshold" env:"loginUserThreshold"`
+	IPThreshold   int           `yaml:"ipThreshold" env:"loginIPThreshold"`
+	BaseDelay     time.Duration `yaml:"baseDelay" env:"loginBaseDelay"`
+	MaxDelay      time.Duration `yaml:"maxDelay" env:"loginMaxDelay"`
+	FailureWindow time.Duration `yaml:"failureWindow" env:"loginFailureWindow"`
+}
+
+// MailConfig - файловый отправитель пишет письма в File, а если он не задан - в стандартный вывод
+type MailConfig struct {
+	Mailer string     `yaml:"mailer" env:"mailer"`
+	From   string     `yaml:"from" env:"mailFrom"`
+	File   string     `yaml:"file" env:"mailerFile"`
+	SMTP   SMTPConfig `yaml:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host" env:"smtpHost"`
+	Port     int    `yaml:"port" env:"smtpPort"`
+	Username string `yaml:"username" env:"smtpUsername"`
+	Password Secret `yaml:"password" env:"smtpPassword"`
+}
+
+type PasswordResetConfig struct {
+	TTL time.Duration `yaml:"ttl" env:"passwordResetTTL"`
+	URL string        `yaml:"url" env:"passwordResetURL"`
+}
+
+type StatsConfig struct {
+	CacheTTL time.Duration `yaml:"cacheTTL" env:"statsCacheTTL"`
+}
+
+// OIDCConfig - если задана переменная oidcProviders, список провайдеров из файла не используется
+type OIDCConfig struct {
+	StateTTL     time.Duration        `yaml:"stateTTL" env:"oidcStateTTL"`
+	PostLoginURL string               `yaml:"postLoginURL" env:"oidcPostLoginURL"`
+	Providers    []OIDCProviderConfig `yaml:"providers"`
+}
+
+type OIDCProviderConfig struct {
+	Name         string        `yaml:"name"`
+	IssuerURL    string        `yaml:"issuer"`
+	ClientID     string        `yaml:"clientID"`
+	ClientSecret Secret        `yaml:"clientSecret"`
+	RedirectURL  string        `yaml:"redirectURL"`
+	Scopes       []string      `yaml:"scopes"`
+	GroupsClaim  string        `yaml:"groupsClaim"`
+	Roles        []RoleMapping `yaml:"roles"`
+}
+
+type RoleMapping struct {
+	Group string `yaml:"group"`
+	Role  string `yaml:"role"`
+}
+
+func Default() *Config {
+	hasherParams := password_hash.DefaultParams()
+	return &Config{
+		HTTP: HTTPConfig{
+			Addr:              ":8080",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			DrainDelay:        5 * time.Second,
+			ShutdownTimeout:   15 * time.Second,
+		},
+		Redis: RedisConfig{
+			MaxIdle:             10,
+			MaxActive:           50,
+			IdleTimeout:         5 * time.Minute,
+			ConnectTimeout:      5 * time.Second,
+			ReadTimeout:         3 * time.Second,
+			WriteTimeout:        3 * time.Second,
+			HealthCheckInterval: time.Minute,
+		},
+		Session: SessionConfig{
+			Store:           SessionStoreRedis,
+			IdleTimeout:     24 * time.Hour,
+			Lifetime:        7 * 24 * time.Hour,
+			CleanupInterval: 10 * time.Minute,
+			Cookie: CookieConfig{
+				Path:     "/",
+				Secure:   true,
+				SameSite: "lax",
+			},
+		},
+		Auth: AuthConfig{
+			Mode:          AuthModeSession,
+			Argon2Time:    hasherParams.Argon2Time,
+			Argon2Memory:  hasherParams.Argon2Memory,
+			Argon2Threads: hasherParams.Argon2Threads,
+			BcryptCost:    hasherParams.BcryptCost,
+			JWT: JWTConfig{
+				AccessTTL:  15 * time.Minute,
+				RefreshTTL: 30 * 24 * time.Hour,
+			},
+		},
+		Lockout: LockoutConfig{
+			UserThreshold: 5,
+			IPThreshold:   20,
+			BaseDelay:     time.Second,
+			MaxDelay:      15 * time.Minute,
+			FailureWindow: time.Hour,
+		},
+		Mail: MailConfig{
+			Mailer: MailerFile,
+			From:   "noreply@filmoteka.local",
+			SMTP: SMTPConfig{
+				Port: 587,
+			},
+		},
+		PasswordReset: PasswordResetConfig{
+			TTL: time.Hour,
+		},
+		Stats: StatsConfig{
+			CacheTTL: 5 * time.Minute,
+		},
+		OIDC: OIDCConfig{
+			StateTTL: 10 * time.Minute,
+		},
+	}
+}
+
+func (c AuthConfig) PasswordHashParams() password_hash.Params {
+	return password_hash.Params{
+		Argon2Time:    c.Argon2Time,
+		Argon2Memory:  c.Argon2Memory,
+		Argon2Threads: c.Argon2Threads,
+		BcryptCost:    c.BcryptCost,
+	}
 }
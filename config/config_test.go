@@ -0,0 +1,253 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setRequiredEnv(t *testing.T) {
+	t.Setenv("hostPG", "localhost")
+	t.Setenv("portPG", "5432")
+	t.Setenv("user", "filmoteka")
+	t.Setenv("dbName", "filmoteka")
+	t.Setenv("hostRD", "localhost")
+	t.Setenv("portRD", "6379")
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("error writing %s: %v", name, err)
+	}
+	return path
+}
+
+func load(args ...string) (*Config, error) {
+	return Load(flag.NewFlagSet("test", flag.ContinueOnError), args)
+}
+
+func TestLoadDefaultsWithoutEnvFile(t *testing.T) {
+	setRequiredEnv(t)
+	cfg, err := load("-env-file", filepath.Join(t.TempDir(), ".env"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	expected := Default()
+	expected.Postgres = PostgresConfig{Host: "localhost", Port: "5432", User: "filmoteka", DBName: "filmoteka"}
+	expected.Redis.Host = "localhost"
+	expected.Redis.Port = "6379"
+	assert.Equal(t, expected, cfg)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	setRequiredEnv(t)
+	configFile := writeFile(t, "config.yaml", `
+http:
+  addr: ":9000"
+redis:
+  maxActive: 20
+  maxIdle: 5
+stats:
+  cacheTTL: 1m
+session:
+  cookie:
+    secure: false
+`)
+	envFile := writeFile(t, ".env", "redisMaxActive=30\nstatsCacheTTL=2m\nloginUserThreshold=7\n")
+	t.Setenv("statsCacheTTL", "3m")
+	t.Setenv("loginIPThreshold", "")
+
+	cfg, err := load("-config", configFile, "-env-file", envFile, "-loginUserThreshold", "9")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, ":9000", cfg.HTTP.Addr)
+	assert.Equal(t, 5, cfg.Redis.MaxIdle)
+	assert.Equal(t, 30, cfg.Redis.MaxActive)
+	assert.Equal(t, 3*time.Minute, cfg.Stats.CacheTTL)
+	assert.Equal(t, 9, cfg.Lockout.UserThreshold)
+	assert.Equal(t, Default().Lockout.IPThreshold, cfg.Lockout.IPThreshold)
+	assert.False(t, cfg.Session.Cookie.Secure)
+}
+
+func TestLoadConfigFileFromEnv(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv(configFileEnvName, writeFile(t, "config.yaml", "auth:\n  passwordHasher: bcrypt\n  bcryptCost: 12\n"))
+	cfg, err := load("-env-file", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "bcrypt", cfg.Auth.PasswordHasher)
+		assert.Equal(t, 12, cfg.Auth.BcryptCost)
+		assert.Equal(t, Default().Auth.Argon2Memory, cfg.Auth.Argon2Memory)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		args     []string
+		problems []string
+	}{
+		{
+			name: "all problems at once",
+			env: map[string]string{
+				"hostPG":              "",
+				"redisIdleTimeout":    "soon",
+				"redisMaxIdle":        "100",
+				"sessionStore":        "file",
+				"authMode":            "jwt",
+				"mailer":              "smtp",
+				"loginMaxDelay":       "1ms",
+				"oidcProviders":       "corp",
+				"oidc_corp_issuer":    "https://sso.example",
+				"oidc_corp_roles":     "admins",
+				"sessionCookieSecure": "maybe",
+			},
+			problems: []string{
+				"bad redisIdleTimeout",
+				"bad sessionCookieSecure",
+				"bad oidc_corp_roles",
+				"hostPG is required",
+				"redisMaxIdle must be between 0 and redisMaxActive",
+				"unknown sessionStore: file",
+				"bad jwt keys",
+				"loginMaxDelay must not be less than loginBaseDelay",
+				"smtpHost is required for smtp mailer",
+				"oidc provider corp: issuer, clientID and redirectURL are required",
+			},
+		},
+		{
+			name:     "stats cache ttl below millisecond",
+			env:      map[string]string{"statsCacheTTL": "500us"},
+			problems: []string{"statsCacheTTL must be 0 or at least 1ms"},
+		},
+		{
+			name:     "argon2 memory too low",
+			env:      map[string]string{"argon2Memory": "4"},
+			problems: []string{"bad passwordHasher: argon2 memory must be at least 8 KiB per thread"},
+		},
+		{
+			name:     "bad flag value",
+			args:     []string{"-smtpPort", "smtp"},
+			problems: []string{"bad smtpPort"},
+		},
+		{
+			name:     "unknown key in config file",
+			args:     []string{"-config", "config.yaml"},
+			problems: []string{"field adress not found"},
+		},
+	}
+	configFile := writeFile(t, "config.yaml", "http:\n  adress: \":8080\"\n")
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setRequiredEnv(t)
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+			args := []string{"-env-file", ""}
+			for _, arg := range tc.args {
+				if arg == "config.yaml" {
+					arg = configFile
+				}
+				args = append(args, arg)
+			}
+			cfg, err := load(args...)
+			assert.Nil(t, cfg)
+			if !assert.Error(t, err) {
+				return
+			}
+			for _, problem := range tc.problems {
+				assert.Contains(t, err.Error(), problem)
+			}
+			if len(tc.problems) > 1 {
+				assert.Len(t, strings.Split(err.Error(), "\n"), len(tc.problems))
+			}
+		})
+	}
+}
+
+func TestLoadOIDCProviders(t *testing.T) {
+	setRequiredEnv(t)
+	configFile := writeFile(t, "config.yaml", `
+oidc:
+  providers:
+    - name: file
+      issuer: https://file.example
+      clientID: filmoteka
+      redirectURL: https://films.example/api/v1/auth/oidc/file/callback
+      roles:
+        - group: admins
+          role: admin
+`)
+	cfg, err := load("-env-file", "", "-config", configFile)
+	if !assert.NoError(t, err) {
+		return
+	}
+	providers := cfg.OIDC.Providers
+	if assert.Len(t, providers, 1) {
+		assert.Equal(t, "file", providers[0].Name)
+		assert.Empty(t, providers[0].Scopes)
+		assert.Equal(t, "admins", providers[0].Roles[0].Group)
+	}
+
+	t.Setenv("oidcProviders", "corp")
+	t.Setenv("oidc_corp_issuer", "https://sso.example")
+	t.Setenv("oidc_corp_clientID", "filmoteka")
+	t.Setenv("oidc_corp_clientSecret", "client-secret")
+	t.Setenv("oidc_corp_redirectURL", "https://films.example/api/v1/auth/oidc/corp/callback")
+	t.Setenv("oidc_corp_scopes", "email, groups")
+	t.Setenv("oidc_corp_roles", "film-admins=admin, film-editors=editor")
+	cfg, err = load("-env-file", "", "-config", configFile)
+	if !assert.NoError(t, err) {
+		return
+	}
+	providers = cfg.OIDC.Providers
+	if assert.Len(t, providers, 1) {
+		assert.Equal(t, "corp", providers[0].Name)
+		assert.Equal(t, Secret("client-secret"), providers[0].ClientSecret)
+		assert.Equal(t, []string{"email", "groups"}, providers[0].Scopes)
+		assert.Len(t, providers[0].Roles, 2)
+		assert.Equal(t, "editor", providers[0].Roles[1].Role)
+	}
+}
+
+func TestSecretRedaction(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("pass", "pg-password")
+	t.Setenv("smtpPassword", "smtp-password")
+	t.Setenv("oidcProviders", "corp")
+	t.Setenv("oidc_corp_issuer", "https://sso.example")
+	t.Setenv("oidc_corp_clientID", "filmoteka")
+	t.Setenv("oidc_corp_clientSecret", "client-secret")
+	t.Setenv("oidc_corp_redirectURL", "https://films.example/api/v1/auth/oidc/corp/callback")
+	cfg, err := load("-env-file", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Secret("pg-password"), cfg.Postgres.Password)
+
+	jsonConfig, err := json.Marshal(cfg)
+	assert.NoError(t, err)
+	for _, printed := range []string{
+		cfg.String(),
+		fmt.Sprintf("%v", cfg.Postgres),
+		fmt.Sprintf("%+v", *cfg),
+		fmt.Sprintf("%#v", cfg.Mail),
+		string(jsonConfig),
+	} {
+		for _, secret := range []string{"pg-password", "smtp-password", "client-secret"} {
+			assert.NotContains(t, printed, secret)
+		}
+		assert.Contains(t, printed, redacted)
+	}
+	assert.Contains(t, cfg.String(), "clientSecret: '[REDACTED]'")
+	assert.Equal(t, "", Secret("").String())
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultEnvFile    = ".env"
+	configFileEnvName = "configFile"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load собирает настройки по возрастанию приоритета: значения по умолчанию, YAML файл, .env, окружение, флаги
+func Load(flagSet *flag.FlagSet, args []string) (*Config, error) {
+	cfg := Default()
+	configFile := flagSet.String("config", "", "path to YAML config file, overrides $"+configFileEnvName)
+	envFile := flagSet.String("env-file", defaultEnvFile, "path to .env file, ignored if it does not exist")
+	flagValues := make(map[string]string)
+	walkParams(cfg, func(name, path string, _ reflect.Value) {
+		flagSet.Func(name, "overrides "+path, func(value string) error {
+			flagValues[name] = value
+			return nil
+		})
+	})
+	if err := flagSet.Parse(args); err != nil {
+		return nil, err
+	}
+
+	dotEnv, err := readEnvFile(*envFile)
+	if err != nil {
+		return nil, fmt.Errorf("error in reading %s: %w", *envFile, err)
+	}
+	lookup := func(name string) string {
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		return dotEnv[name]
+	}
+	if *configFile == "" {
+		*configFile = lookup(configFileEnvName)
+	}
+	if *configFile != "" {
+		if err = readYAMLFile(*configFile, cfg); err != nil {
+			return nil, fmt.Errorf("error in reading %s: %w", *configFile, err)
+		}
+	}
+
+	var problems []error
+	walkParams(cfg, func(name, _ string, field reflect.Value) {
+		value, ok := flagValues[name]
+		if !ok {
+			value = lookup(name)
+		}
+		// пустое значение переменной считается незаданным
+		if value == "" {
+			return
+		}
+		if err := setParam(field, value); err != nil {
+			problems = append(problems, fmt.Errorf("bad %s: %w", name, err))
+		}
+	})
+	problems = append(problems, loadOIDCProviders(cfg, lookup)...)
+	problems = append(problems, cfg.validate()...)
+	if len(problems) != 0 {
+		return nil, errors.Join(problems...)
+	}
+	return cfg, nil
+}
+
+func (c *Config) String() string {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Sprintf("error in config marshalling: %s", err)
+	}
+	return string(data)
+}
+
+func walkParams(cfg *Config, visit func(name, path string, field reflect.Value)) {
+	var walk func(value reflect.Value, prefix string)
+	walk = func(value reflect.Value, prefix string) {
+		for i := 0; i < value.NumField(); i++ {
+			fieldType := value.Type().Field(i)
+			path := prefix + fieldType.Tag.Get("yaml")
+			if name := fieldType.Tag.Get("env"); name != "" {
+				visit(name, path, value.Field(i))
+				continue
+			}
+			if fieldType.Type.Kind() == reflect.Struct {
+				walk(value.Field(i), path+".")
+			}
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+}
+
+func setParam(field reflect.Value, value string) error {
+	switch {
+	case field.Type() == durationType:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Int:
+		result, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(result))
+	case field.Kind() == reflect.Bool:
+		result, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(result)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+func readEnvFile(path string) (map[string]string, error) {
+	values, err := godotenv.Read(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return values, err
+}
+
+func readYAMLFile(path string, cfg *Config) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	err = decoder.Decode(cfg)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// loadOIDCProviders читает oidc_<имя>_* для имен из oidcProviders; roles задаются парами группа=роль
+func loadOIDCProviders(cfg *Config, lookup func(name string) string) []error {
+	names := lookup("oidcProviders")
+	if names == "" {
+		return nil
+	}
+	var problems []error
+	cfg.OIDC.Providers = nil
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "oidc_" + name + "_"
+		providerConfig := OIDCProviderConfig{
+			Name:         name,
+			IssuerURL:    lookup(prefix + "issuer"),
+			ClientID:     lookup(prefix + "clientID"),
+			ClientSecret: Secret(lookup(prefix + "clientSecret")),
+			RedirectURL:  lookup(prefix + "redirectURL"),
+			Scopes:       splitList(lookup(prefix + "scopes")),
+			GroupsClaim:  lookup(prefix + "groupsClaim"),
+		}
+		for _, pair := range splitList(lookup(prefix + "roles")) {
+			group, role, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(group) == "" || strings.TrimSpace(role) == "" {
+				problems = append(problems, fmt.Errorf("bad %sroles: bad role mapping %q, expected group=role",
+					prefix, pair))
+				continue
+			}
+			providerConfig.Roles = append(providerConfig.Roles,
+				RoleMapping{Group: strings.TrimSpace(group), Role: strings.TrimSpace(role)})
+		}
+		cfg.OIDC.Providers = append(cfg.OIDC.Providers, providerConfig)
+	}
+	return problems
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,25 @@
+package config
+
+const redacted = "[REDACTED]"
+
+// Secret - строковый параметр, который при выводе заменяется на [REDACTED]
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return `"` + s.String() + `"`
+}
+
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ilyushkaaa/Filmoteka/pkg/access_token"
+	"github.com/ilyushkaaa/Filmoteka/pkg/cookie"
+	"github.com/ilyushkaaa/Filmoteka/pkg/password_hash"
+)
+
+var sslModes = map[string]struct{}{
+	"": {}, "disable": {}, "allow": {}, "prefer": {}, "require": {}, "verify-ca": {}, "verify-full": {},
+}
+
+func (c *Config) validate() []error {
+	var problems []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.HTTP.Addr != "", "appPort is required")
+	check(c.HTTP.ReadTimeout > 0, "httpReadTimeout must be positive")
+	check(c.HTTP.ReadHeaderTimeout > 0, "httpReadHeaderTimeout must be positive")
+	check(c.HTTP.WriteTimeout > 0, "httpWriteTimeout must be positive")
+	check(c.HTTP.IdleTimeout > 0, "httpIdleTimeout must be positive")
+	check(c.HTTP.DrainDelay >= 0, "httpDrainDelay must not be negative")
+	check(c.HTTP.ShutdownTimeout > 0, "httpShutdownTimeout must be positive")
+
+	check(c.Postgres.Host != "", "hostPG is required")
+	check(c.Postgres.Port != "", "portPG is required")
+	check(c.Postgres.User != "", "user is required")
+	check(c.Postgres.DBName != "", "dbName is required")
+	_, ok := sslModes[c.Postgres.SSLMode]
+	check(ok, "unknown sslMode: %s", c.Postgres.SSLMode)
+
+	check(c.Redis.Host != "", "hostRD is required")
+	check(c.Redis.Port != "", "portRD is required")
+	check(c.Redis.MaxActive > 0, "redisMaxActive must be positive")
+	check(c.Redis.MaxIdle >= 0 && c.Redis.MaxIdle <= c.Redis.MaxActive,
+		"redisMaxIdle must be between 0 and redisMaxActive")
+	check(c.Redis.IdleTimeout >= 0, "redisIdleTimeout must not be negative")
+	check(c.Redis.ConnectTimeout > 0, "redisConnectTimeout must be positive")
+	check(c.Redis.ReadTimeout > 0, "redisReadTimeout must be positive")
+	check(c.Redis.WriteTimeout > 0, "redisWriteTimeout must be positive")
+	check(c.Redis.HealthCheckInterval >= 0, "redisHealthCheckInterval must not be negative")
+
+	switch c.Session.Store {
+	case SessionStoreRedis, SessionStorePostgres, SessionStoreMemory:
+	default:
+		check(false, "unknown sessionStore: %s", c.Session.Store)
+	}
+	check(c.Session.IdleTimeout > 0, "sessionIdleTimeout must be positive")
+	check(c.Session.Lifetime > 0, "sessionLifetime must be positive")
+	check(c.Session.CleanupInterval > 0, "sessionCleanupInterval must be positive")
+	_, err := cookie.NewConfig(c.Session.Cookie.Domain, c.Session.Cookie.Path, c.Session.Cookie.Secure,
+		c.Session.Cookie.SameSite)
+	check(err == nil, "bad session cookie: %v", err)
+
+	_, err = password_hash.NewHasher(c.Auth.PasswordHasher, c.Auth.PasswordHashParams())
+	check(err == nil, "bad passwordHasher: %v", err)
+	switch c.Auth.Mode {
+	case AuthModeSession:
+	case AuthModeJWT:
+		check(c.Auth.JWT.AccessTTL > 0, "jwtAccessTTL must be positive")
+		check(c.Auth.JWT.RefreshTTL > 0, "jwtRefreshTTL must be positive")
+		_, err = access_token.NewManager(c.Auth.JWT.Algorithm, string(c.Auth.JWT.Keys), c.Auth.JWT.ActiveKeyID,
+			c.Auth.JWT.AccessTTL)
+		check(err == nil, "bad jwt keys: %v", err)
+	default:
+		check(false, "unknown authMode: %s", c.Auth.Mode)
+	}
+
+	check(c.Lockout.UserThreshold > 0, "loginUserThreshold must be positive")
+	check(c.Lockout.IPThreshold > 0, "loginIPThreshold must be positive")
+	check(c.Lockout.BaseDelay > 0, "loginBaseDelay must be positive")
+	check(c.Lockout.MaxDelay >= c.Lockout.BaseDelay, "loginMaxDelay must not be less than loginBaseDelay")
+	check(c.Lockout.FailureWindow > 0, "loginFailureWindow must be positive")
+
+	switch c.Mail.Mailer {
+	case MailerFile:
+	case MailerSMTP:
+		check(c.Mail.SMTP.Host != "", "smtpHost is required for smtp mailer")
+		check(c.Mail.SMTP.Port > 0 && c.Mail.SMTP.Port <= 65535, "smtpPort must be between 1 and 65535")
+	default:
+		check(false, "unknown mailer: %s", c.Mail.Mailer)
+	}
+	check(c.PasswordReset.TTL > 0, "passwordResetTTL must be positive")
+	check(c.Stats.CacheTTL >= 0, "statsCacheTTL must not be negative")
+	check(c.Stats.CacheTTL == 0 || c.Stats.CacheTTL >= time.Millisecond,
+		"statsCacheTTL must be 0 or at least 1ms")
+
+	check(c.OIDC.StateTTL > 0, "oidcStateTTL must be positive")
+	providerNames := make(map[string]struct{}, len(c.OIDC.Providers))
+	for _, p := range c.OIDC.Providers {
+		_, duplicate := providerNames[p.Name]
+		providerNames[p.Name] = struct{}{}
+		check(p.Name != "", "oidc provider name is required")
+		check(!duplicate, "oidc provider %s is configured twice", p.Name)
+		check(p.IssuerURL != "" && p.ClientID != "" && p.RedirectURL != "",
+			"oidc provider %s: issuer, clientID and redirectURL are required", p.Name)
+		for _, mapping := range p.Roles {
+			check(mapping.Group != "" && mapping.Role != "",
+				"oidc provider %s: group and role are required in role mappings", p.Name)
+		}
+	}
+	return problems
+}
@@ -34,6 +34,12 @@ const docTemplate = `{
                         "name": "ACTOR_ID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык имен (например, ru или en), по умолчанию берется из заголовка Accept-Language",
+                        "name": "lang",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -64,6 +70,55 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/actor/{ACTOR_ID}/stats": {
+            "get": {
+                "description": "Получить возраст актера, его возраст на момент выхода каждого фильма, количество фильмов по десятилетиям, средний рейтинг фильмов, первое и последнее появление",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "actors"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID актера",
+                        "name": "ACTOR_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.ActorStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Идентификатор актера передан в неверном формате",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Актёр не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/actors": {
             "get": {
                 "description": "Получить список всех актеров",
@@ -76,6 +131,14 @@ const docTemplate = `{
                 "tags": [
                     "actors"
                 ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Язык имен (например, ru или en), по умолчанию берется из заголовка Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -158,7 +221,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Данный метод позволяет добавить нового актера в систему.",
+                "description": "Данный метод позволяет добавить нового актера в систему. Если актер с такими же именем, фамилией и датой рождения уже существует, возвращается список возможных дубликатов.",
                 "consumes": [
                     "application/json"
                 ],
@@ -177,6 +240,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.ActorAdd"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Добавить актера, даже если найдены возможные дубликаты",
+                        "name": "force",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -204,6 +273,12 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "409": {
+                        "description": "Найдены возможные дубликаты актера",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.DuplicatesResponse"
+                        }
+                    },
                     "422": {
                         "description": "Ошибка валидации данных",
                         "schema": {
@@ -280,6 +355,201 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/admin/actor/{ACTOR_ID}/merge": {
+            "post": {
+                "description": "Данный метод переносит все связи актера с фильмами на целевой актер, сохраняет старый идентификатор как псевдоним целевого и удаляет исходный актер.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "actors"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор актера, который будет удален",
+                        "name": "ACTOR_ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Идентификатор целевого актера",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.MergeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Данные целевого актера после объединения",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.ActorWithFilms"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Актер не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/actor/{ACTOR_ID}/names": {
+            "put": {
+                "description": "Данный метод заменяет все варианты имени и фамилии актера на разных языках. Если варианта на запрошенном языке нет, возвращается исходное имя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "actors"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор актера",
+                        "name": "ACTOR_ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Имя и фамилия актера на разных языках",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.ActorNamesUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Имена успешно сохранены",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Актер не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/actors/duplicates": {
+            "get": {
+                "description": "Получить пары актеров с совпадающими именем, фамилией и датой рождения",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "actors"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.DuplicatePair"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/admin/film": {
             "put": {
                 "security": [
@@ -353,7 +623,7 @@ const docTemplate = `{
                         "CookieAuth": []
                     }
                 ],
-                "description": "Данный метод позволяет добавить новый фильм в систему.",
+                "description": "Данный метод позволяет добавить новый фильм в систему. Если фильм с похожим названием и тем же годом выхода уже существует, возвращается список возможных дубликатов.",
                 "consumes": [
                     "application/json"
                 ],
@@ -372,6 +642,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_films_entity.Film"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Добавить фильм, даже если найдены возможные дубликаты",
+                        "name": "force",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -399,6 +675,12 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "409": {
+                        "description": "Найдены возможные дубликаты фильма",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.DuplicatesResponse"
+                        }
+                    },
                     "422": {
                         "description": "Ошибка валидации данных",
                         "schema": {
@@ -480,9 +762,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/film/{FILM_ID}": {
-            "get": {
-                "description": "Получить информацию о фильме по его идентификатору",
+        "/api/v1/admin/film/{FILM_ID}/merge": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Данный метод переносит все связи фильма с актерами на целевой фильм, сохраняет старый идентификатор как псевдоним целевого и удаляет исходный фильм.",
                 "consumes": [
                     "application/json"
                 ],
@@ -494,22 +781,43 @@ const docTemplate = `{
                 ],
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID актера",
+                        "type": "integer",
+                        "description": "Идентификатор фильма, который будет удален",
                         "name": "FILM_ID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Идентификатор целевого фильма",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.MergeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Данные целевого фильма после объединения",
                         "schema": {
                             "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_films_entity.Film"
                         }
                     },
                     "400": {
-                        "description": "Идентификатор фильма передан в неверном формате",
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
                         "schema": {
                             "type": "string"
                         }
@@ -520,6 +828,12 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "422": {
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
@@ -529,9 +843,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/films": {
-            "get": {
-                "description": "Получить список всех фильмов",
+        "/api/v1/admin/film/{FILM_ID}/titles": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Данный метод заменяет все альтернативные названия фильма. Одно из названий может быть отмечено как оригинальное, оно используется, если названия на запрошенном языке нет.",
                 "consumes": [
                     "application/json"
                 ],
@@ -541,18 +860,57 @@ const docTemplate = `{
                 "tags": [
                     "films"
                 ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор фильма",
+                        "name": "FILM_ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Названия фильма на разных языках",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.FilmTitlesUpdate"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Названия успешно сохранены",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_films_entity.Film"
-                            }
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Передан неверный параметр сортировки",
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Фильм не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Ошибка валидации данных",
                         "schema": {
                             "type": "string"
                         }
@@ -566,9 +924,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/films/search/{SEARCH_STR}": {
+        "/api/v1/admin/films/duplicates": {
             "get": {
-                "description": "Данный метод позволяет получить список фильмов, соответствующих поисковому запросу.",
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Получить пары фильмов с похожими названиями и одинаковым годом выхода",
                 "consumes": [
                     "application/json"
                 ],
@@ -578,27 +941,24 @@ const docTemplate = `{
                 "tags": [
                     "films"
                 ],
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Строка поиска",
-                        "name": "SEARCH_STR",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
                 "responses": {
                     "200": {
-                        "description": "Список фильмов",
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_films_entity.Film"
+                                "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.DuplicatePair"
                             }
                         }
                     },
-                    "404": {
-                        "description": "Фильмы не найдены",
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
                         "schema": {
                             "type": "string"
                         }
@@ -612,9 +972,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/login": {
-            "post": {
-                "description": "Данный метод позволяет пользователям войти в систему, используя свои учетные данные.",
+        "/api/v1/admin/stats": {
+            "get": {
+                "description": "Получить количество фильмов, актеров и пользователей, распределение фильмов по годам и рейтингу, топ актеров по количеству фильмов, фильмы без актеров и актеров без фильмов. Период ограничивает фильмы по дате выхода, актеров - по участию в этих фильмах, пользователей - по дате регистрации; обе границы включаются",
                 "consumes": [
                     "application/json"
                 ],
@@ -622,28 +982,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
                 "parameters": [
                     {
-                        "description": "Данные пользователя для входа",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.AuthRequest"
-                        }
+                        "type": "string",
+                        "description": "Начало периода в формате YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Последний день периода в формате YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешный вход, получен идентификатор сессии",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.AuthResponse"
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.CatalogStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Период передан в неверном формате",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "401": {
-                        "description": "Неверные учетные данные",
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
                         "schema": {
                             "type": "string"
                         }
@@ -657,14 +1032,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/logout": {
-            "post": {
-                "security": [
-                    {
-                        "CookieAuth": []
-                    }
-                ],
-                "description": "Данный метод позволяет пользователям выйти из системы, завершая сеанс.",
+        "/api/v1/admin/users": {
+            "get": {
+                "description": "Получить постраничный список пользователей с возможностью поиска по имени пользователя",
                 "consumes": [
                     "application/json"
                 ],
@@ -672,23 +1042,49 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Часть имени пользователя",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество пользователей на странице (от 1 до 100, по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение от начала списка",
+                        "name": "offset",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешный выход",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.UsersPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Параметры страницы переданы в неверном формате",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "401": {
-                        "description": "Неверный или отсутствующий токен аутентификации",
+                        "description": "Пользователь не аутентифицирован",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "Сеанс не найден",
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
                         "schema": {
                             "type": "string"
                         }
@@ -702,9 +1098,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/register": {
-            "post": {
-                "description": "Данный метод позволяет новым пользователям зарегистрироваться в системе.",
+        "/api/v1/admin/users/{USER_ID}": {
+            "get": {
+                "description": "Получить имя, роль и статус блокировки пользователя по его идентификатору",
                 "consumes": [
                     "application/json"
                 ],
@@ -712,28 +1108,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
                 "parameters": [
                     {
-                        "description": "Данные нового пользователя",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.AuthRequest"
-                        }
+                        "type": "integer",
+                        "description": "Идентификатор пользователя",
+                        "name": "USER_ID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешная регистрация, получен идентификатор сессии",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.AuthResponse"
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_users_entity.User"
                         }
                     },
-                    "422": {
-                        "description": "Пользователь уже существует",
+                    "400": {
+                        "description": "Неверный формат идентификатора пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
                         "schema": {
                             "type": "string"
                         }
@@ -745,100 +1157,2985 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "github_com_ilyushkaaa_Filmoteka_internal_actors_entity.Actor": {
-            "type": "object",
-            "properties": {
-                "birthday": {
-                    "type": "string"
-                },
-                "gender": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "surname": {
-                    "type": "string"
-                }
-            }
-        },
-        "github_com_ilyushkaaa_Filmoteka_internal_dto.ActorAdd": {
-            "type": "object",
-            "properties": {
-                "birthday": {
-                    "type": "string"
-                },
-                "gender": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "surname": {
-                    "type": "string"
-                }
-            }
-        },
-        "github_com_ilyushkaaa_Filmoteka_internal_dto.ActorUpdate": {
+            },
+            "delete": {
+                "description": "Удалить пользователя и завершить все его сессии. Нельзя удалить последнего активного администратора",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор пользователя",
+                        "name": "USER_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пользователь удален",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат идентификатора пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Пользователь является последним активным администратором",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{USER_ID}/api-keys": {
+            "get": {
+                "description": "Получить список API ключей пользователя, например сервисного аккаунта",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "USER_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_apikeys_entity.APIKey"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат ID пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создать API ключ для пользователя, например сервисного аккаунта. Секрет ключа возвращается только в этом ответе",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "USER_ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Параметры ключа",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.APIKeyCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_apikeys_entity.CreatedAPIKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли валидацию",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{USER_ID}/api-keys/{KEY_ID}": {
+            "delete": {
+                "description": "Удалить API ключ пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "USER_ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID ключа",
+                        "name": "KEY_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ключ отозван",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Ключ не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{USER_ID}/disable": {
+            "post": {
+                "description": "Запретить пользователю вход в систему и завершить все его сессии. Нельзя заблокировать последнего активного администратора",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор пользователя",
+                        "name": "USER_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пользователь заблокирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат идентификатора пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Пользователь является последним активным администратором",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{USER_ID}/enable": {
+            "post": {
+                "description": "Снова разрешить пользователю вход в систему",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор пользователя",
+                        "name": "USER_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пользователь разблокирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат идентификатора пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{USER_ID}/role": {
+            "put": {
+                "description": "Назначить пользователю новую роль. Все сессии пользователя завершаются. Нельзя лишить роли последнего активного администратора",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор пользователя",
+                        "name": "USER_ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новая роль пользователя",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.UserRoleUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Роль успешно изменена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Пользователь является последним активным администратором",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли валидацию или роль не существует",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{USER_ID}/sessions": {
+            "get": {
+                "description": "Получить список активных сессий пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "USER_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.SessionInfo"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат ID пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Завершить все сессии пользователя и отозвать его refresh токены",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "USER_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сессии завершены",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат ID пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{USER_ID}/unlock": {
+            "post": {
+                "description": "Сбросить счетчик неудачных попыток входа пользователя и снять временную блокировку входа по его имени",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор пользователя",
+                        "name": "USER_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Блокировка входа снята",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат идентификатора пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Запрещено для данного пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/oidc/{PROVIDER}/callback": {
+            "get": {
+                "description": "Адрес возврата от провайдера. Проверяет state, обменивает код на ID токен, находит, связывает или создает пользователя, назначает роль по группам провайдера и создает сессию. Если задан oidcPostLoginURL, перенаправляет на него. Если пользователю нужен второй фактор, сессия не создается: ответ такой же, как у /api/v1/login (202 с challenge_id), а при заданном oidcPostLoginURL challenge_id и enrollment_required передаются в параметрах перенаправления",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя провайдера",
+                        "name": "PROVIDER",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Код авторизации",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State, выданный при начале входа",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешный вход, получен идентификатор сессии",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.AuthResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Нужен второй фактор, вход завершается через /api/v1/login/totp",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.LoginChallengeResponse"
+                        }
+                    },
+                    "302": {
+                        "description": "Успешный вход или запрос второго фактора, перенаправление на oidcPostLoginURL",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный или просроченный state",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Провайдер отказал во входе или вернул неверный токен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Пользователь заблокирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Провайдер не настроен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/oidc/{PROVIDER}/start": {
+            "get": {
+                "description": "Перенаправляет пользователя на страницу входа провайдера OpenID Connect (authorization code с PKCE) и устанавливает cookie oidc_state",
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя провайдера",
+                        "name": "PROVIDER",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Перенаправление на страницу входа провайдера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Провайдер не настроен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/film/{FILM_ID}": {
+            "get": {
+                "description": "Получить информацию о фильме по его идентификатору",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID актера",
+                        "name": "FILM_ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык названий (например, ru или en), по умолчанию берется из заголовка Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_films_entity.Film"
+                        }
+                    },
+                    "400": {
+                        "description": "Идентификатор фильма передан в неверном формате",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Фильм не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/films": {
+            "get": {
+                "description": "Получить список всех фильмов",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Язык названий (например, ru или en), по умолчанию берется из заголовка Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_films_entity.Film"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Передан неверный параметр сортировки",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/films/search/{SEARCH_STR}": {
+            "get": {
+                "description": "Данный метод позволяет получить список фильмов, соответствующих поисковому запросу.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Строка поиска",
+                        "name": "SEARCH_STR",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык названий (например, ru или en), по умолчанию берется из заголовка Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список фильмов",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_films_entity.Film"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Фильмы не найдены",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/login": {
+            "post": {
+                "description": "Данный метод позволяет пользователям войти в систему, используя свои учетные данные. При аутентификации по сессиям устанавливает cookie session_id и csrf_token. После серии неудачных попыток вход для пользователя или IP адреса временно блокируется. Если пользователю нужен второй фактор, возвращается незавершенный вход (202), который завершается через /api/v1/login/totp.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "description": "Данные пользователя для входа",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.AuthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешный вход, получен идентификатор сессии (или dto.TokenResponse при аутентификации по токенам)",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.AuthResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Пароль верный, требуется код второго фактора",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.LoginChallengeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверные учетные данные",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Пользователь заблокирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток входа, повторить можно через Retry-After секунд",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/login/totp": {
+            "post": {
+                "description": "Завершить вход кодом из приложения-аутентификатора или одноразовым кодом восстановления. Если TOTP подключался во время входа, в ответе возвращаются коды восстановления",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "description": "Идентификатор незавершенного входа и код",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.LoginChallengeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешный вход, получен идентификатор сессии (или dto.TokenResponse при аутентификации по токенам)",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверный код, вход не найден или истек",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Роль пользователя требует подключить TOTP",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли валидацию",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток входа, повторить можно через Retry-After секунд",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/login/totp/enroll": {
+            "post": {
+                "description": "Создать секрет для пользователя, которому TOTP обязателен, но еще не подключен. Вход завершается кодом из приложения-аутентификатора через /api/v1/login/totp",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "description": "Идентификатор незавершенного входа",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.LoginChallengeEnrollRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_twofactor_entity.Enrollment"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Вход не найден или истек",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "TOTP уже подключен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли валидацию",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/logout": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Данный метод позволяет пользователям выйти из системы, завершая сеанс и удаляя cookie сессии.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешный выход",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Запрос аутентифицирован не сессией, а токеном",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверный или отсутствующий токен аутентификации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сеанс не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/logout-all": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Завершить все сессии текущего пользователя, включая текущую, и отозвать его refresh токены",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Все сессии завершены",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Получить профиль текущего пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.ProfileResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Удалить учетную запись текущего пользователя после подтверждения паролем. Вместе с ней удаляются все связанные данные: API ключи, настройки второго фактора, токены сброса пароля, сессии и refresh токены",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "description": "Текущий пароль",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.AccountDeleteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Учетная запись удалена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный пароль или аутентификация API ключом",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Нельзя удалить последнего администратора",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли валидацию",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Изменить отображаемое имя, email, аватар, предпочитаемый язык или имя пользователя. Переданные поля заменяются, отсутствующие не меняются, пустая строка очищает значение",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "description": "Изменяемые поля профиля",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.ProfileUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.ProfileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Аутентификация API ключом",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Имя пользователя уже занято",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли валидацию",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Получить список API ключей текущего пользователя. Секреты ключей не возвращаются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api keys"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_apikeys_entity.APIKey"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "API ключами нельзя управлять при аутентификации API ключом",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Создать именованный API ключ для машинного клиента. Секрет ключа возвращается только в этом ответе, в дальнейшем его нужно передавать в заголовке \"Authorization: ApiKey \u003cсекрет\u003e\". Область действия (scopes) может только сужать разрешения роли пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api keys"
+                ],
+                "parameters": [
+                    {
+                        "description": "Параметры ключа",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.APIKeyCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_apikeys_entity.CreatedAPIKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "API ключами нельзя управлять при аутентификации API ключом",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли валидацию",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/api-keys/{KEY_ID}": {
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Удалить API ключ текущего пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api keys"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID ключа",
+                        "name": "KEY_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ключ отозван",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат ID ключа",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "API ключами нельзя управлять при аутентификации API ключом",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Ключ не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/export": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Выгрузить все данные текущего пользователя (профиль, сессии, API ключи) одним JSON файлом",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.AccountExport"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Аутентификация API ключом",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/password": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Сменить пароль текущего пользователя. Требуется текущий пароль. Все сессии пользователя, кроме текущей, завершаются, а refresh токены отзываются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "description": "Текущий и новый пароль",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.PasswordChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пароль изменен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный текущий пароль или аутентификация API ключом",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли валидацию",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Получить список активных сессий текущего пользователя (устройств, с которых выполнен вход). Текущая сессия отмечена полем is_current",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.SessionInfo"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/sessions/{SESSION_ID}": {
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Завершить одну из сессий текущего пользователя, например на потерянном устройстве",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сессии",
+                        "name": "SESSION_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сессия завершена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сессия не найдена",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/totp": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Создать секрет для приложения-аутентификатора. Второй фактор начнет действовать после подтверждения кодом через /api/v1/me/totp/verify",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "two-factor"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_twofactor_entity.Enrollment"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Вторым фактором нельзя управлять при аутентификации API ключом",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "TOTP уже подключен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Отключить второй фактор, подтвердив это кодом из приложения-аутентификатора или кодом восстановления. Для ролей с правом управления пользователями отключение запрещено",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "two-factor"
+                ],
+                "parameters": [
+                    {
+                        "description": "Код из приложения-аутентификатора или код восстановления",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.TOTPCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "TOTP отключен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Вторым фактором нельзя управлять при аутентификации API ключом",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "TOTP не подключен или обязателен для роли пользователя",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Неверный код",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/me/totp/verify": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Подтвердить подключение TOTP кодом из приложения-аутентификатора. В ответе возвращаются одноразовые коды восстановления, они показываются только один раз",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "two-factor"
+                ],
+                "parameters": [
+                    {
+                        "description": "Код из приложения-аутентификатора",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.TOTPCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не аутентифицирован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Вторым фактором нельзя управлять при аутентификации API ключом",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "TOTP не подключается или уже подключен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Неверный код",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/password/reset": {
+            "post": {
+                "description": "Установить новый пароль по токену из письма. Токен одноразовый; после сброса завершаются все сессии пользователя и отзываются его refresh токены",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "description": "Токен и новый пароль",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.PasswordResetConfirm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пароль изменен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе или токен недействителен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли валидацию",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/password/reset/request": {
+            "post": {
+                "description": "Отправить на email пользователя одноразовый токен сброса пароля. Ответ не зависит от того, существует ли пользователь и указан ли у него email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "description": "Имя пользователя",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.PasswordResetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Запрос принят",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли валидацию",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/register": {
+            "post": {
+                "description": "Данный метод позволяет новым пользователям зарегистрироваться в системе. При аутентификации по сессиям устанавливает cookie session_id и csrf_token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "description": "Данные нового пользователя",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.AuthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешная регистрация, получен идентификатор сессии (или dto.TokenResponse при аутентификации по токенам)",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.AuthResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Пользователь уже существует",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/token/refresh": {
+            "post": {
+                "description": "Обменять refresh токен на новую пару access и refresh токенов. Каждый refresh токен одноразовый: его повторное использование отзывает все токены, выданные при этом входе",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "description": "Refresh токен",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Новая пара токенов",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Refresh токен недействителен или уже был использован",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли валидацию",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/token/revoke": {
+            "post": {
+                "description": "Отозвать refresh токен и все токены, выданные при том же входе. Используется для выхода при аутентификации по токенам",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "parameters": [
+                    {
+                        "description": "Refresh токен",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Токен отозван",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Refresh токен недействителен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Данные не прошли валидацию",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Сервер запущен и обрабатывает запросы",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сервер работает",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Сервер готов принимать запросы: он не останавливается, а PostgreSQL и Redis доступны",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сервер готов",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Сервер останавливается или зависимости недоступны",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.HealthResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "github_com_ilyushkaaa_Filmoteka_internal_actors_entity.Actor": {
+            "type": "object",
+            "properties": {
+                "birthday": {
+                    "type": "string"
+                },
+                "deathDate": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                },
+                "transliterations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_actors_entity.ActorName"
+                    }
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_actors_entity.ActorName": {
+            "type": "object",
+            "properties": {
+                "locale": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_apikeys_entity.APIKey": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "userID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_apikeys_entity.CreatedAPIKey": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.APIKeyCreate": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.APIKeyInfo": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.AccountDeleteRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.AccountExport": {
+            "type": "object",
+            "properties": {
+                "api_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.APIKeyInfo"
+                    }
+                },
+                "exported_at": {
+                    "type": "string"
+                },
+                "profile": {
+                    "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.ProfileResponse"
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.SessionInfo"
+                    }
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.ActorAdd": {
+            "type": "object",
+            "properties": {
+                "birthday": {
+                    "type": "string"
+                },
+                "death_date": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.ActorFilmsCount": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "films_count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.ActorName": {
+            "type": "object",
+            "properties": {
+                "locale": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.ActorNamesUpdate": {
+            "type": "object",
+            "properties": {
+                "names": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.ActorName"
+                    }
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.ActorStats": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "age": {
+                    "type": "integer"
+                },
+                "appearances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.FilmAppearance"
+                    }
+                },
+                "average_rating": {
+                    "type": "number"
+                },
+                "films_by_decade": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "films_count": {
+                    "type": "integer"
+                },
+                "first_appearance": {
+                    "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.FilmAppearance"
+                },
+                "is_alive": {
+                    "type": "boolean"
+                },
+                "latest_appearance": {
+                    "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.FilmAppearance"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.ActorUpdate": {
+            "type": "object",
+            "properties": {
+                "birthday": {
+                    "type": "string"
+                },
+                "death_date": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.ActorWithFilms": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_actors_entity.Actor"
+                },
+                "films": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_films_entity.Film"
+                    }
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.AuthRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "session_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.CatalogStats": {
+            "type": "object",
+            "properties": {
+                "actors_count": {
+                    "type": "integer"
+                },
+                "actors_without_films": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_actors_entity.Actor"
+                    }
+                },
+                "films_count": {
+                    "type": "integer"
+                },
+                "films_per_year": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "films_without_cast": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_films_entity.Film"
+                    }
+                },
+                "rating_distribution": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "top_actors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.ActorFilmsCount"
+                    }
+                },
+                "users_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.DuplicatePair": {
             "type": "object",
             "properties": {
-                "birthday": {
+                "duplicate_id": {
+                    "type": "integer"
+                },
+                "original_id": {
+                    "type": "integer"
+                },
+                "similarity": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.DuplicatesResponse": {
+            "type": "object",
+            "properties": {
+                "candidate_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "error": {
                     "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.FilmAppearance": {
+            "type": "object",
+            "properties": {
+                "age_at_release": {
+                    "type": "integer"
                 },
-                "gender": {
+                "date_of_release": {
                     "type": "string"
                 },
-                "id": {
+                "film_id": {
                     "type": "integer"
                 },
                 "name": {
                     "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.FilmTitle": {
+            "type": "object",
+            "properties": {
+                "is_original": {
+                    "type": "boolean"
                 },
-                "surname": {
+                "locale": {
+                    "type": "string"
+                },
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "github_com_ilyushkaaa_Filmoteka_internal_dto.ActorWithFilms": {
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.FilmTitlesUpdate": {
             "type": "object",
             "properties": {
-                "actor": {
-                    "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_actors_entity.Actor"
-                },
-                "films": {
+                "titles": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_films_entity.Film"
+                        "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_dto.FilmTitle"
                     }
                 }
             }
         },
-        "github_com_ilyushkaaa_Filmoteka_internal_dto.AuthRequest": {
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.LoginChallengeEnrollRequest": {
+            "type": "object",
+            "properties": {
+                "challenge_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.LoginChallengeRequest": {
+            "type": "object",
+            "properties": {
+                "challenge_id": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.LoginChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "challenge_id": {
+                    "type": "string"
+                },
+                "enrollment_required": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.MergeRequest": {
+            "type": "object",
+            "properties": {
+                "target_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.PasswordChangeRequest": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.PasswordResetConfirm": {
             "type": "object",
             "properties": {
                 "password": {
                     "type": "string"
                 },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.PasswordResetRequest": {
+            "type": "object",
+            "properties": {
                 "username": {
                     "type": "string"
                 }
             }
         },
-        "github_com_ilyushkaaa_Filmoteka_internal_dto.AuthResponse": {
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.ProfileResponse": {
             "type": "object",
             "properties": {
-                "session_id": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "preferred_language": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.ProfileUpdate": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "preferred_language": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.RefreshTokenRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.SessionInfo": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "is_current": {
+                    "type": "boolean"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.TOTPCodeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "recovery_codes": {
+                    "description": "RecoveryCodes заполняется, только если TOTP был подключен во время входа",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.UserRoleUpdate": {
+            "type": "object",
+            "properties": {
+                "role": {
                     "type": "string"
                 }
             }
         },
+        "github_com_ilyushkaaa_Filmoteka_internal_dto.UsersPage": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_users_entity.User"
+                    }
+                }
+            }
+        },
         "github_com_ilyushkaaa_Filmoteka_internal_films_entity.Film": {
             "type": "object",
             "properties": {
@@ -856,6 +4153,54 @@ const docTemplate = `{
                 },
                 "rating": {
                     "type": "number"
+                },
+                "titles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_ilyushkaaa_Filmoteka_internal_films_entity.FilmTitle"
+                    }
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_films_entity.FilmTitle": {
+            "type": "object",
+            "properties": {
+                "isOriginal": {
+                    "type": "boolean"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_twofactor_entity.Enrollment": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_ilyushkaaa_Filmoteka_internal_users_entity.User": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "isDisabled": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         }
@@ -12,6 +12,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/ilyushkaaa/Filmoteka/internal/actors/usecase"
 	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/pkg/locale"
 	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
 	"github.com/ilyushkaaa/Filmoteka/pkg/response"
 )
@@ -31,6 +32,7 @@ func NewActorHandler(actorUseCase usecase.ActorUseCase) *ActorHandler {
 // @Tags actors
 // @Accept json
 // @Produce json
+// @Param lang query string false "Язык имен (например, ru или en), по умолчанию берется из заголовка Accept-Language"
 // @Success 200 {array} dto.ActorWithFilms
 // @Failure 500 {object} string "Внутренняя ошибка сервера"
 // @Router /api/v1/actors [get]
@@ -45,7 +47,7 @@ func (h *ActorHandler) GetActors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	actors, err := h.actorUseCase.GetActors()
+	actors, err := h.actorUseCase.GetActors(r.Context(), locale.FromRequest(r))
 	if err != nil {
 		zapLogger.Errorf("error in getting actors: %s", err)
 		errText := `{"error": "internal server error}`
@@ -77,6 +79,7 @@ func (h *ActorHandler) GetActors(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param ACTOR_ID path string true "ID актера"
+// @Param lang query string false "Язык имен (например, ru или en), по умолчанию берется из заголовка Accept-Language"
 // @Success 200 {object} dto.ActorWithFilms
 // @Failure 400 {object} string "Идентификатор актера передан в неверном формате"
 // @Failure 404 {object} string "Актёр не найден"
@@ -104,7 +107,7 @@ func (h *ActorHandler) GetActorByID(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	actor, err := h.actorUseCase.GetActorByID(actorIDInt)
+	actor, err := h.actorUseCase.GetActorByID(r.Context(), actorIDInt, locale.FromRequest(r))
 	if errors.Is(err, usecase.ErrActorNotFound) {
 		zapLogger.Errorf("actor with id %d is not found", actorIDInt)
 		errText := fmt.Sprintf(`{"error": "actor with ID %d is not found"}`, actorIDInt)
@@ -140,16 +143,18 @@ func (h *ActorHandler) GetActorByID(w http.ResponseWriter, r *http.Request) {
 }
 
 // AddActor @Summary Добавление нового актера
-// @Description Данный метод позволяет добавить нового актера в систему.
+// @Description Данный метод позволяет добавить нового актера в систему. Если актер с такими же именем, фамилией и датой рождения уже существует, возвращается список возможных дубликатов.
 // @Tags actors
 // @Accept json
 // @Produce json
 // @SecurityRequirement CookieAuth
 // @Param body body dto.ActorAdd true "Данные о новом актере"
+// @Param force query bool false "Добавить актера, даже если найдены возможные дубликаты"
 // @Success 200 {object} dto.ActorAdd "Данные добавленного актера"
 // @Failure 400 {object} string "Ошибка в запросе"
 // @Failure 401 {object} string "Пользователь не аутентифицирован"
 // @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 409 {object} dto.DuplicatesResponse "Найдены возможные дубликаты актера"
 // @Failure 422 {object} string "Ошибка валидации данных"
 // @Failure 500 {object} string "Внутренняя ошибка сервера"
 // @Router /api/v1/admin/actor [post]
@@ -163,6 +168,19 @@ func (h *ActorHandler) AddActor(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	force := false
+	if forceParam := r.URL.Query().Get("force"); forceParam != "" {
+		force, err = strconv.ParseBool(forceParam)
+		if err != nil {
+			zapLogger.Errorf("error in force param conversion: %s", err)
+			errText := fmt.Sprintf(`{"error": "bad format of force param: %s"}`, err)
+			err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+			if err != nil {
+				zapLogger.Errorf("error in writing response: %s", err)
+			}
+			return
+		}
+	}
 	actorDTO := &dto.ActorAdd{}
 	rBody, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -205,7 +223,30 @@ func (h *ActorHandler) AddActor(w http.ResponseWriter, r *http.Request) {
 	}
 
 	actor := actorDTO.Convert()
-	addedActor, err := h.actorUseCase.AddActor(actor)
+	addedActor, err := h.actorUseCase.AddActor(r.Context(), actor, force)
+	var duplicateErr *usecase.DuplicateActorError
+	if errors.As(err, &duplicateErr) {
+		zapLogger.Errorf("error in adding actor: %s", err)
+		var duplicatesJSON []byte
+		duplicatesJSON, err = json.Marshal(dto.DuplicatesResponse{
+			Error:        usecase.ErrActorDuplicate.Error(),
+			CandidateIDs: duplicateErr.CandidateIDs,
+		})
+		if err != nil {
+			zapLogger.Errorf("error in marshalling duplicates: %s", err)
+			errText := `{"error": "internal server error"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if err != nil {
+				zapLogger.Errorf("error in writing response: %s", err)
+			}
+			return
+		}
+		err = response.WriteResponse(w, duplicatesJSON, http.StatusConflict)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
 	if err != nil {
 		errText := `{"error": "internal server error"}`
 		zapLogger.Errorf("error in adding actor: %s", err)
@@ -297,7 +338,7 @@ func (h *ActorHandler) UpdateActor(w http.ResponseWriter, r *http.Request) {
 	}
 
 	actor := actorDTO.Convert()
-	err = h.actorUseCase.UpdateActor(actor)
+	err = h.actorUseCase.UpdateActor(r.Context(), actor)
 	if errors.Is(err, usecase.ErrActorNotFound) {
 		errText := `{"error": "bad update data"}`
 		zapLogger.Errorf("error in updating film: %s", err)
@@ -368,7 +409,7 @@ func (h *ActorHandler) DeleteActor(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	err = h.actorUseCase.DeleteActor(actorIDInt)
+	err = h.actorUseCase.DeleteActor(r.Context(), actorIDInt)
 	if errors.Is(err, usecase.ErrActorNotFound) {
 		zapLogger.Errorf("actor with id %d is not found", actorIDInt)
 		errText := fmt.Sprintf(`{"error": "actor with ID %d is not found"}`, actorIDInt)
@@ -393,3 +434,346 @@ func (h *ActorHandler) DeleteActor(w http.ResponseWriter, r *http.Request) {
 		zapLogger.Errorf("error in writing response: %s", err)
 	}
 }
+
+// GetActorStats @Summary Получить статистику актера
+// @Description Получить возраст актера, его возраст на момент выхода каждого фильма, количество фильмов по десятилетиям, средний рейтинг фильмов, первое и последнее появление
+// @Tags actors
+// @Accept json
+// @Produce json
+// @Param ACTOR_ID path string true "ID актера"
+// @Success 200 {object} dto.ActorStats
+// @Failure 400 {object} string "Идентификатор актера передан в неверном формате"
+// @Failure 404 {object} string "Актёр не найден"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/actor/{ACTOR_ID}/stats [get]
+func (h *ActorHandler) GetActorStats(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	vars := mux.Vars(r)
+	actorID := vars["ACTOR_ID"]
+	actorIDInt, err := strconv.ParseUint(actorID, 10, 64)
+	if err != nil {
+		zapLogger.Errorf("error in actor id conversion: %s", err)
+		errText := fmt.Sprintf(`{"error": "bad format of actor id: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	stats, err := h.actorUseCase.GetActorStats(r.Context(), actorIDInt)
+	if errors.Is(err, usecase.ErrActorNotFound) {
+		zapLogger.Errorf("actor with id %d is not found", actorIDInt)
+		errText := fmt.Sprintf(`{"error": "actor with ID %d is not found"}`, actorIDInt)
+		err = response.WriteResponse(w, []byte(errText), http.StatusNotFound)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		zapLogger.Errorf("error in getting actor stats: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		zapLogger.Errorf("error marshalling response: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, statsJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+// GetActorDuplicates @Summary Получить возможные дубликаты актеров
+// @Description Получить пары актеров с совпадающими именем, фамилией и датой рождения
+// @Tags actors
+// @Accept json
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Success 200 {array} dto.DuplicatePair
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/actors/duplicates [get]
+func (h *ActorHandler) GetActorDuplicates(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	duplicates, err := h.actorUseCase.GetActorDuplicates(r.Context())
+	if err != nil {
+		zapLogger.Errorf("error in getting actor duplicates: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	duplicatesJSON, err := json.Marshal(duplicates)
+	if err != nil {
+		zapLogger.Errorf("error in marshalling actor duplicates: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, duplicatesJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+// MergeActors @Summary Объединение дубликатов актера
+// @Description Данный метод переносит все связи актера с фильмами на целевой актер, сохраняет старый идентификатор как псевдоним целевого и удаляет исходный актер.
+// @Tags actors
+// @Accept json
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param ACTOR_ID path int true "Идентификатор актера, который будет удален"
+// @Param body body dto.MergeRequest true "Идентификатор целевого актера"
+// @Success 200 {object} dto.ActorWithFilms "Данные целевого актера после объединения"
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 404 {object} string "Актер не найден"
+// @Failure 422 {object} string "Ошибка валидации данных"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/actor/{ACTOR_ID}/merge [post]
+func (h *ActorHandler) MergeActors(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	vars := mux.Vars(r)
+	actorID := vars["ACTOR_ID"]
+	actorIDInt, err := strconv.ParseUint(actorID, 10, 64)
+	if err != nil {
+		zapLogger.Errorf("error in actor id conversion: %s", err)
+		errText := fmt.Sprintf(`{"error": "bad format of actor id: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	mergeDTO := &dto.MergeRequest{}
+	rBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		zapLogger.Errorf("error in reading request body: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in reading request body: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = json.Unmarshal(rBody, mergeDTO)
+	if err != nil {
+		zapLogger.Errorf("error in unmarshalling merge request: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in decoding merge request: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+
+	if validationErrors := mergeDTO.Validate(); len(validationErrors) != 0 {
+		var errorsJSON []byte
+		errorsJSON, err = json.Marshal(validationErrors)
+		if err != nil {
+			zapLogger.Errorf("error in marshalling validation errors: %s", err)
+			errText := `{"error": "internal server error"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if err != nil {
+				zapLogger.Errorf("error in writing response: %s", err)
+			}
+			return
+		}
+		err = response.WriteResponse(w, errorsJSON, http.StatusUnprocessableEntity)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+
+	mergedActor, err := h.actorUseCase.MergeActors(r.Context(), actorIDInt, mergeDTO.TargetID)
+	if errors.Is(err, usecase.ErrSameActorMerge) {
+		zapLogger.Errorf("error in merging actors: %s", err)
+		errText := fmt.Sprintf(`{"error": "%s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if errors.Is(err, usecase.ErrActorNotFound) {
+		zapLogger.Errorf("actor with id %d or %d is not found", actorIDInt, mergeDTO.TargetID)
+		errText := fmt.Sprintf(`{"error": "actor with ID %d or %d is not found"}`, actorIDInt, mergeDTO.TargetID)
+		err = response.WriteResponse(w, []byte(errText), http.StatusNotFound)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		errText := `{"error": "internal server error"}`
+		zapLogger.Errorf("error in merging actors: %s", err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	actorJSON, err := json.Marshal(mergedActor)
+	if err != nil {
+		zapLogger.Errorf("error in marshalling actor: %s", err)
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, actorJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+// SetActorNames @Summary Задание транслитераций имени актера
+// @Description Данный метод заменяет все варианты имени и фамилии актера на разных языках. Если варианта на запрошенном языке нет, возвращается исходное имя.
+// @Tags actors
+// @Accept json
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param ACTOR_ID path int true "Идентификатор актера"
+// @Param body body dto.ActorNamesUpdate true "Имя и фамилия актера на разных языках"
+// @Success 200 {object} string "Имена успешно сохранены"
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 404 {object} string "Актер не найден"
+// @Failure 422 {object} string "Ошибка валидации данных"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/actor/{ACTOR_ID}/names [put]
+func (h *ActorHandler) SetActorNames(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	vars := mux.Vars(r)
+	actorID := vars["ACTOR_ID"]
+	actorIDInt, err := strconv.ParseUint(actorID, 10, 64)
+	if err != nil {
+		zapLogger.Errorf("error in actor id conversion: %s", err)
+		errText := fmt.Sprintf(`{"error": "bad format of actor id: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	namesDTO := &dto.ActorNamesUpdate{}
+	rBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		zapLogger.Errorf("error in reading request body: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in reading request body: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = json.Unmarshal(rBody, namesDTO)
+	if err != nil {
+		zapLogger.Errorf("error in unmarshalling actor names: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in decoding actor names: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+
+	if validationErrors := namesDTO.Validate(); len(validationErrors) != 0 {
+		var errorsJSON []byte
+		errorsJSON, err = json.Marshal(validationErrors)
+		if err != nil {
+			zapLogger.Errorf("error in marshalling validation errors: %s", err)
+			errText := `{"error": "internal server error"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if err != nil {
+				zapLogger.Errorf("error in writing response: %s", err)
+			}
+			return
+		}
+		err = response.WriteResponse(w, errorsJSON, http.StatusUnprocessableEntity)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+
+	err = h.actorUseCase.SetActorNames(r.Context(), actorIDInt, namesDTO.Convert())
+	if errors.Is(err, usecase.ErrActorNotFound) {
+		zapLogger.Errorf("actor with id %d is not found", actorIDInt)
+		errText := fmt.Sprintf(`{"error": "actor with ID %d is not found"}`, actorIDInt)
+		err = response.WriteResponse(w, []byte(errText), http.StatusNotFound)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		errText := `{"error": "internal server error"}`
+		zapLogger.Errorf("error in setting actor names: %s", err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	result := `{"result": "success"}`
+	err = response.WriteResponse(w, []byte(result), http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
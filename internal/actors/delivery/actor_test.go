@@ -80,7 +80,7 @@ func TestGetActors(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().GetActors().Return(nil, fmt.Errorf("error"))
+	testUseCase.EXPECT().GetActors(gomock.Any(), []string{}).Return(nil, fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodGet, "/actors", nil)
 	ctx := request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -100,7 +100,7 @@ func TestGetActors(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusUnauthorized, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().GetActors().Return(nil, fmt.Errorf("error"))
+	testUseCase.EXPECT().GetActors(gomock.Any(), []string{}).Return(nil, fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodGet, "/actors", nil)
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -120,7 +120,7 @@ func TestGetActors(t *testing.T) {
 	}
 
 	actors := make([]dto.ActorWithFilms, 0)
-	testUseCase.EXPECT().GetActors().Return(actors, nil)
+	testUseCase.EXPECT().GetActors(gomock.Any(), []string{}).Return(actors, nil)
 	request = httptest.NewRequest(http.MethodGet, "/actors", nil)
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -204,7 +204,7 @@ func TestGetActorByID(t *testing.T) {
 	}
 
 	var id uint64 = 1
-	testUseCase.EXPECT().GetActorByID(id).Return(nil, fmt.Errorf("error"))
+	testUseCase.EXPECT().GetActorByID(gomock.Any(), id, []string{}).Return(nil, fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodGet, "/actor/1", nil)
 	request = mux.SetURLVars(request, map[string]string{"ACTOR_ID": "1"})
 	ctx = request.Context()
@@ -225,7 +225,7 @@ func TestGetActorByID(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().GetActorByID(id).Return(nil, usecase.ErrActorNotFound)
+	testUseCase.EXPECT().GetActorByID(gomock.Any(), id, []string{}).Return(nil, usecase.ErrActorNotFound)
 	request = httptest.NewRequest(http.MethodGet, "/actor/1", nil)
 	request = mux.SetURLVars(request, map[string]string{"ACTOR_ID": "1"})
 	ctx = request.Context()
@@ -247,7 +247,7 @@ func TestGetActorByID(t *testing.T) {
 	}
 
 	actor := dto.ActorWithFilms{}
-	testUseCase.EXPECT().GetActorByID(id).Return(&actor, nil)
+	testUseCase.EXPECT().GetActorByID(gomock.Any(), id, []string{}).Return(&actor, nil)
 	request = httptest.NewRequest(http.MethodGet, "/actor/1", nil)
 	request = mux.SetURLVars(request, map[string]string{"ACTOR_ID": "1"})
 	ctx = request.Context()
@@ -374,7 +374,7 @@ func TestAddActor(t *testing.T) {
 		Gender:   "male",
 		Birthday: time.Time{}.Add(time.Hour),
 	}
-	testUseCase.EXPECT().AddActor(actor).Return(nil, fmt.Errorf("error"))
+	testUseCase.EXPECT().AddActor(gomock.Any(), actor, false).Return(nil, fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodPost, "/actor", strings.NewReader(
 		`{"name":"Aaa","surname":"Aaa","birthday":"0001-01-01T01:00:00Z","gender":"male"}`))
 	ctx = request.Context()
@@ -395,10 +395,48 @@ func TestAddActor(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 
+	testUseCase.EXPECT().AddActor(gomock.Any(), actor, false).Return(nil, &usecase.DuplicateActorError{CandidateIDs: []uint64{2}})
+	request = httptest.NewRequest(http.MethodPost, "/actor", strings.NewReader(
+		`{"name":"Aaa","surname":"Aaa","birthday":"0001-01-01T01:00:00Z","gender":"male"}`))
+	ctx = request.Context()
+	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.AddActor(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable to read response body")
+		return
+	}
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 409 {
+		t.Errorf("expected status %d, got status %d", http.StatusConflict, resp.StatusCode)
+	}
+	if !strings.Contains(string(body), `"candidate_ids":[2]`) {
+		t.Errorf("expected candidate ids in response, got %s", body)
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/actor?force=bad", nil)
+	ctx = request.Context()
+	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.AddActor(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 400 {
+		t.Errorf("expected status %d, got status %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
 	actorAdded := actor
 	actorAdded.ID = 1
-	testUseCase.EXPECT().AddActor(actor).Return(&actorAdded, nil)
-	request = httptest.NewRequest(http.MethodPost, "/actor", strings.NewReader(
+	testUseCase.EXPECT().AddActor(gomock.Any(), actor, true).Return(&actorAdded, nil)
+	request = httptest.NewRequest(http.MethodPost, "/actor?force=true", strings.NewReader(
 		`{"name":"Aaa","surname":"Aaa","birthday":"0001-01-01T01:00:00Z","gender":"male"}`))
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -525,7 +563,7 @@ func TestUpdateActor(t *testing.T) {
 		Gender:   "male",
 		Birthday: time.Time{}.Add(time.Hour),
 	}
-	testUseCase.EXPECT().UpdateActor(actor).Return(fmt.Errorf("error"))
+	testUseCase.EXPECT().UpdateActor(gomock.Any(), actor).Return(fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodPut, "/actor", strings.NewReader(
 		`{"id":1,"name":"Aaa","surname":"Aaa","birthday":"0001-01-01T01:00:00Z","gender":"male"}`))
 	ctx = request.Context()
@@ -546,7 +584,7 @@ func TestUpdateActor(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().UpdateActor(actor).Return(nil)
+	testUseCase.EXPECT().UpdateActor(gomock.Any(), actor).Return(nil)
 	request = httptest.NewRequest(http.MethodPut, "/actor", strings.NewReader(
 		`{"id":1,"name":"Aaa","surname":"Aaa","birthday":"0001-01-01T01:00:00Z","gender":"male"}`))
 	ctx = request.Context()
@@ -631,7 +669,7 @@ func TestDeleteActor(t *testing.T) {
 	}
 
 	var id uint64 = 1
-	testUseCase.EXPECT().DeleteActor(id).Return(fmt.Errorf("error"))
+	testUseCase.EXPECT().DeleteActor(gomock.Any(), id).Return(fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodGet, "/actor/1", nil)
 	request = mux.SetURLVars(request, map[string]string{"ACTOR_ID": "1"})
 	ctx = request.Context()
@@ -652,7 +690,7 @@ func TestDeleteActor(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().DeleteActor(id).Return(nil)
+	testUseCase.EXPECT().DeleteActor(gomock.Any(), id).Return(nil)
 	request = httptest.NewRequest(http.MethodGet, "/actor/1", nil)
 	request = mux.SetURLVars(request, map[string]string{"ACTOR_ID": "1"})
 	ctx = request.Context()
@@ -674,3 +712,277 @@ func TestDeleteActor(t *testing.T) {
 	}
 
 }
+
+func TestGetActorStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockActorUseCase(ctrl)
+	testHandler := NewActorHandler(testUseCase)
+
+	request := httptest.NewRequest(http.MethodGet, "/actor/1/stats", nil)
+	respWriter := httptest.NewRecorder()
+	testHandler.GetActorStats(respWriter, request)
+	resp := respWriter.Result()
+	err := resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 500 {
+		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/actor/bad_id/stats", nil)
+	request = mux.SetURLVars(request, map[string]string{"ACTOR_ID": "bad_id"})
+	ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.GetActorStats(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 400 {
+		t.Errorf("expected status %d, got status %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	var id uint64 = 1
+	testUseCase.EXPECT().GetActorStats(gomock.Any(), id).Return(nil, fmt.Errorf("error"))
+	request = httptest.NewRequest(http.MethodGet, "/actor/1/stats", nil)
+	request = mux.SetURLVars(request, map[string]string{"ACTOR_ID": "1"})
+	ctx = context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.GetActorStats(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 500 {
+		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	testUseCase.EXPECT().GetActorStats(gomock.Any(), id).Return(nil, usecase.ErrActorNotFound)
+	request = httptest.NewRequest(http.MethodGet, "/actor/1/stats", nil)
+	request = mux.SetURLVars(request, map[string]string{"ACTOR_ID": "1"})
+	ctx = context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.GetActorStats(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 404 {
+		t.Errorf("expected status %d, got status %d", http.StatusNotFound, resp.StatusCode)
+	}
+
+	testUseCase.EXPECT().GetActorStats(gomock.Any(), id).Return(&dto.ActorStats{ActorID: id}, nil)
+	request = httptest.NewRequest(http.MethodGet, "/actor/1/stats", nil)
+	request = mux.SetURLVars(request, map[string]string{"ACTOR_ID": "1"})
+	ctx = context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.GetActorStats(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 200 {
+		t.Errorf("expected status %d, got status %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestGetActorDuplicates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockActorUseCase(ctrl)
+	testHandler := NewActorHandler(testUseCase)
+
+	request := httptest.NewRequest(http.MethodGet, "/admin/actors/duplicates", nil)
+	respWriter := httptest.NewRecorder()
+	testHandler.GetActorDuplicates(respWriter, request)
+	resp := respWriter.Result()
+	err := resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 500 {
+		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	testUseCase.EXPECT().GetActorDuplicates(gomock.Any()).Return(nil, fmt.Errorf("error"))
+	request = httptest.NewRequest(http.MethodGet, "/admin/actors/duplicates", nil)
+	ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.GetActorDuplicates(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 500 {
+		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	testUseCase.EXPECT().GetActorDuplicates(gomock.Any()).Return([]dto.DuplicatePair{{OriginalID: 1, DuplicateID: 2}}, nil)
+	request = httptest.NewRequest(http.MethodGet, "/admin/actors/duplicates", nil)
+	ctx = context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.GetActorDuplicates(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 200 {
+		t.Errorf("expected status %d, got status %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestMergeActors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockActorUseCase(ctrl)
+	testHandler := NewActorHandler(testUseCase)
+
+	request := httptest.NewRequest(http.MethodPost, "/admin/actor/1/merge", nil)
+	respWriter := httptest.NewRecorder()
+	testHandler.MergeActors(respWriter, request)
+	resp := respWriter.Result()
+	err := resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 500 {
+		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	testCases := []struct {
+		id         string
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{id: "bad_id", body: `{"target_id":2}`, statusCode: http.StatusBadRequest},
+		{id: "1", body: `{"`, statusCode: http.StatusBadRequest},
+		{id: "1", body: `{}`, statusCode: http.StatusUnprocessableEntity},
+		{
+			id:   "1",
+			body: `{"target_id":1}`,
+			setup: func() {
+				testUseCase.EXPECT().MergeActors(gomock.Any(), uint64(1), uint64(1)).Return(nil, usecase.ErrSameActorMerge)
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			id:   "1",
+			body: `{"target_id":2}`,
+			setup: func() {
+				testUseCase.EXPECT().MergeActors(gomock.Any(), uint64(1), uint64(2)).Return(nil, usecase.ErrActorNotFound)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			id:   "1",
+			body: `{"target_id":2}`,
+			setup: func() {
+				testUseCase.EXPECT().MergeActors(gomock.Any(), uint64(1), uint64(2)).Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			id:   "1",
+			body: `{"target_id":2}`,
+			setup: func() {
+				testUseCase.EXPECT().MergeActors(gomock.Any(), uint64(1), uint64(2)).Return(&dto.ActorWithFilms{}, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request = httptest.NewRequest(http.MethodPost, "/admin/actor/"+tc.id+"/merge", strings.NewReader(tc.body))
+		request = mux.SetURLVars(request, map[string]string{"ACTOR_ID": tc.id})
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter = httptest.NewRecorder()
+		testHandler.MergeActors(respWriter, request.WithContext(ctx))
+		resp = respWriter.Result()
+		err = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestSetActorNames(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockActorUseCase(ctrl)
+	testHandler := NewActorHandler(testUseCase)
+
+	testCases := []struct {
+		id         string
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{id: "bad_id", body: `{"names":[{"locale":"ru","name":"Джон","surname":"Доу"}]}`, statusCode: http.StatusBadRequest},
+		{id: "1", body: `{"`, statusCode: http.StatusBadRequest},
+		{id: "1", body: `{"names":[{"locale":"RU","name":"Джон","surname":"Доу"}]}`, statusCode: http.StatusUnprocessableEntity},
+		{
+			id:   "1",
+			body: `{"names":[{"locale":"ru","name":"Джон","surname":"Доу"}]}`,
+			setup: func() {
+				testUseCase.EXPECT().SetActorNames(gomock.Any(), uint64(1), gomock.Any()).Return(usecase.ErrActorNotFound)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			id:   "1",
+			body: `{"names":[{"locale":"ru","name":"Джон","surname":"Доу"}]}`,
+			setup: func() {
+				testUseCase.EXPECT().SetActorNames(gomock.Any(), uint64(1), gomock.Any()).Return(fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			id:   "1",
+			body: `{"names":[{"locale":"ru","name":"Джон","surname":"Доу"}]}`,
+			setup: func() {
+				testUseCase.EXPECT().SetActorNames(gomock.Any(), uint64(1), []entity.ActorName{{Locale: "ru", Name: "Джон", Surname: "Доу"}}).Return(nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodPut, "/admin/actor/"+tc.id+"/names", strings.NewReader(tc.body))
+		request = mux.SetURLVars(request, map[string]string{"ACTOR_ID": tc.id})
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.SetActorNames(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
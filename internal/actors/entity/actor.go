@@ -3,9 +3,17 @@ package entity
 import "time"
 
 type Actor struct {
-	ID       uint64
-	Name     string
-	Surname  string
-	Gender   string
-	Birthday time.Time
+	ID               uint64
+	Name             string
+	Surname          string
+	Gender           string
+	Birthday         time.Time
+	DeathDate        *time.Time
+	Transliterations []ActorName `json:",omitempty"`
+}
+
+type ActorName struct {
+	Locale  string
+	Name    string
+	Surname string
 }
@@ -1,8 +1,10 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"strings"
 
 	entityActor "github.com/ilyushkaaa/Filmoteka/internal/actors/entity"
 	"github.com/ilyushkaaa/Filmoteka/internal/dto"
@@ -12,11 +14,16 @@ import (
 
 //go:generate mockgen -source=actor.go -destination=actor_mock.go -package=repo ActorRepo
 type ActorRepo interface {
-	GetActorByID(actorID uint64) (*dto.ActorWithFilms, error)
-	GetActors() ([]dto.ActorWithFilms, error)
-	AddActor(actor entityActor.Actor) (uint64, error)
-	UpdateActor(actor entityActor.Actor) (bool, error)
-	DeleteActor(ID uint64) (bool, error)
+	GetActorByID(ctx context.Context, actorID uint64, langs []string) (*dto.ActorWithFilms, error)
+	GetActors(ctx context.Context, langs []string) ([]dto.ActorWithFilms, error)
+	AddActor(ctx context.Context, actor entityActor.Actor) (uint64, error)
+	UpdateActor(ctx context.Context, actor entityActor.Actor) (bool, error)
+	DeleteActor(ctx context.Context, ID uint64) (bool, error)
+	GetActorStats(ctx context.Context, actorID uint64) (*dto.ActorStats, error)
+	FindDuplicateActors(ctx context.Context, actor entityActor.Actor) ([]uint64, error)
+	GetActorDuplicates(ctx context.Context) ([]dto.DuplicatePair, error)
+	MergeActors(ctx context.Context, sourceID, targetID uint64) (bool, error)
+	SetActorNames(ctx context.Context, actorID uint64, names []entityActor.ActorName) (bool, error)
 }
 
 type ActorRepoPG struct {
@@ -30,14 +37,21 @@ func NewActorRepo(db *sql.DB, zapLogger *zap.SugaredLogger) *ActorRepoPG {
 		zapLogger: zapLogger,
 	}
 }
-func (r *ActorRepoPG) GetActorByID(actorID uint64) (*dto.ActorWithFilms, error) {
-	rows, err := r.db.Query(`
-        SELECT a.id, a.name, a.surname, a.gender, a.birthday, f.id, f.name, f.description, f.date_of_release, f.rating
+func (r *ActorRepoPG) GetActorByID(ctx context.Context, actorID uint64, langs []string) (*dto.ActorWithFilms, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT a.id, COALESCE(an.name, a.name), COALESCE(an.surname, a.surname), a.gender, a.birthday, a.death_date, f.id,
+               COALESCE((SELECT ft.title FROM film_titles ft
+                         WHERE ft.film_id = f.id AND (ft.locale = ANY(string_to_array($2, ',')) OR ft.is_original)
+                         ORDER BY array_position(string_to_array($2, ','), ft.locale::TEXT) LIMIT 1), f.name),
+               f.description, f.date_of_release, f.rating
         FROM actors a
+        LEFT JOIN LATERAL (SELECT an.name, an.surname FROM actor_names an
+                          WHERE an.actor_id = a.id AND an.locale = ANY(string_to_array($2, ','))
+                          ORDER BY array_position(string_to_array($2, ','), an.locale::TEXT) LIMIT 1) an ON TRUE
         LEFT JOIN film_actors fa ON a.id = fa.actor_id
         LEFT JOIN films f ON fa.film_id = f.id
-        WHERE a.id = $1
-    `, actorID)
+        WHERE a.id = COALESCE((SELECT aa.actor_id FROM actor_aliases aa WHERE aa.old_id = $1), $1)
+    `, actorID, strings.Join(langs, ","))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -57,7 +71,7 @@ func (r *ActorRepoPG) GetActorByID(actorID uint64) (*dto.ActorWithFilms, error)
 	for rows.Next() {
 		var actor entityActor.Actor
 		var filmDB dto.FilmDB
-		err = rows.Scan(&actor.ID, &actor.Name, &actor.Surname, &actor.Gender, &actor.Birthday, &filmDB.ID, &filmDB.Name,
+		err = rows.Scan(&actor.ID, &actor.Name, &actor.Surname, &actor.Gender, &actor.Birthday, &actor.DeathDate, &filmDB.ID, &filmDB.Name,
 			&filmDB.Description, &filmDB.DateOfRelease, &filmDB.Rating)
 		if err != nil {
 			return nil, err
@@ -73,16 +87,50 @@ func (r *ActorRepoPG) GetActorByID(actorID uint64) (*dto.ActorWithFilms, error)
 		return nil, nil
 	}
 
+	actorWithFilms.Actor.Transliterations, err = r.getActorNames(ctx, actorWithFilms.Actor.ID)
+	if err != nil {
+		return nil, err
+	}
 	return &actorWithFilms, nil
 }
 
-func (r *ActorRepoPG) GetActors() ([]dto.ActorWithFilms, error) {
-	rows, err := r.db.Query(`
-        SELECT a.id, a.name, a.surname, a.gender, a.birthday, f.id, f.name, f.description, f.date_of_release, f.rating
+func (r *ActorRepoPG) getActorNames(ctx context.Context, actorID uint64) ([]entityActor.ActorName, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT locale, name, surname FROM actor_names WHERE actor_id = $1 ORDER BY locale", actorID)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			r.zapLogger.Errorf("error in closing query rows: %s", err)
+		}
+	}(rows)
+	names := make([]entityActor.ActorName, 0)
+	for rows.Next() {
+		var name entityActor.ActorName
+		err = rows.Scan(&name.Locale, &name.Name, &name.Surname)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (r *ActorRepoPG) GetActors(ctx context.Context, langs []string) ([]dto.ActorWithFilms, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT a.id, COALESCE(an.name, a.name), COALESCE(an.surname, a.surname), a.gender, a.birthday, a.death_date, f.id,
+               COALESCE((SELECT ft.title FROM film_titles ft
+                         WHERE ft.film_id = f.id AND (ft.locale = ANY(string_to_array($1, ',')) OR ft.is_original)
+                         ORDER BY array_position(string_to_array($1, ','), ft.locale::TEXT) LIMIT 1), f.name),
+               f.description, f.date_of_release, f.rating
         FROM actors a
+        LEFT JOIN LATERAL (SELECT an.name, an.surname FROM actor_names an
+                          WHERE an.actor_id = a.id AND an.locale = ANY(string_to_array($1, ','))
+                          ORDER BY array_position(string_to_array($1, ','), an.locale::TEXT) LIMIT 1) an ON TRUE
         LEFT JOIN film_actors fa ON a.id = fa.actor_id
         LEFT JOIN films f ON fa.film_id = f.id
-    `)
+    `, strings.Join(langs, ","))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -101,7 +149,7 @@ func (r *ActorRepoPG) GetActors() ([]dto.ActorWithFilms, error) {
 		var actorID int
 		var actor entityActor.Actor
 		var filmDB dto.FilmDB
-		err = rows.Scan(&actorID, &actor.Name, &actor.Surname, &actor.Gender, &actor.Birthday, &filmDB.ID, &filmDB.Name,
+		err = rows.Scan(&actorID, &actor.Name, &actor.Surname, &actor.Gender, &actor.Birthday, &actor.DeathDate, &filmDB.ID, &filmDB.Name,
 			&filmDB.Description, &filmDB.DateOfRelease, &filmDB.Rating)
 		if err != nil {
 			return nil, err
@@ -133,18 +181,18 @@ func (r *ActorRepoPG) GetActors() ([]dto.ActorWithFilms, error) {
 	return actorsWithFilms, nil
 }
 
-func (r *ActorRepoPG) AddActor(actor entityActor.Actor) (uint64, error) {
+func (r *ActorRepoPG) AddActor(ctx context.Context, actor entityActor.Actor) (uint64, error) {
 	var actorID uint64
 	err := r.db.
-		QueryRow("INSERT INTO actors (name, surname, gender, birthday) VALUES ($1, $2, $3, $4) RETURNING id",
-			actor.Name, actor.Surname, actor.Gender, actor.Birthday).
+		QueryRowContext(ctx, "INSERT INTO actors (name, surname, gender, birthday, death_date) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+			actor.Name, actor.Surname, actor.Gender, actor.Birthday, actor.DeathDate).
 		Scan(&actorID)
 	return actorID, err
 }
 
-func (r *ActorRepoPG) UpdateActor(actor entityActor.Actor) (bool, error) {
-	_, err := r.db.Exec("UPDATE actors SET name = $1, surname = $2, gender = $3, birthday = $4 WHERE id = $5",
-		actor.Name, actor.Surname, actor.Gender, actor.Birthday, actor.ID)
+func (r *ActorRepoPG) UpdateActor(ctx context.Context, actor entityActor.Actor) (bool, error) {
+	_, err := r.db.ExecContext(ctx, "UPDATE actors SET name = $1, surname = $2, gender = $3, birthday = $4, death_date = $5 WHERE id = $6",
+		actor.Name, actor.Surname, actor.Gender, actor.Birthday, actor.DeathDate, actor.ID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return false, nil
@@ -154,8 +202,8 @@ func (r *ActorRepoPG) UpdateActor(actor entityActor.Actor) (bool, error) {
 	return true, nil
 }
 
-func (r *ActorRepoPG) DeleteActor(ID uint64) (bool, error) {
-	result, err := r.db.Exec("DELETE FROM actors WHERE id = $1", ID)
+func (r *ActorRepoPG) DeleteActor(ctx context.Context, ID uint64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM actors WHERE id = $1", ID)
 	if err != nil {
 		return false, err
 	}
@@ -165,3 +213,253 @@ func (r *ActorRepoPG) DeleteActor(ID uint64) (bool, error) {
 	}
 	return rowsDeleted > 0, nil
 }
+
+func (r *ActorRepoPG) GetActorStats(ctx context.Context, actorID uint64) (*dto.ActorStats, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT a.id, a.death_date,
+               DATE_PART('year', AGE(COALESCE(a.death_date, NOW()), a.birthday))::INT,
+               f.id, f.name, f.date_of_release,
+               DATE_PART('year', AGE(f.date_of_release, a.birthday))::INT,
+               (DATE_PART('year', f.date_of_release)::INT / 10) * 10,
+               COUNT(f.id) OVER (),
+               AVG(f.rating) OVER ()
+        FROM actors a
+        LEFT JOIN film_actors fa ON a.id = fa.actor_id
+        LEFT JOIN films f ON fa.film_id = f.id
+        WHERE a.id = $1
+        ORDER BY f.date_of_release
+    `, actorID)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			r.zapLogger.Errorf("error in closing query rows: %s", err)
+		}
+	}(rows)
+
+	var stats *dto.ActorStats
+	for rows.Next() {
+		var (
+			deathDate     sql.NullTime
+			age           int
+			filmID        sql.NullInt64
+			filmName      sql.NullString
+			dateOfRelease sql.NullTime
+			ageAtRelease  sql.NullInt64
+			decade        sql.NullInt64
+			filmsCount    int
+			averageRating sql.NullFloat64
+		)
+		if stats == nil {
+			stats = &dto.ActorStats{
+				FilmsByDecade: make(map[int]int),
+				Appearances:   make([]dto.FilmAppearance, 0),
+			}
+		}
+		err = rows.Scan(&stats.ActorID, &deathDate, &age, &filmID, &filmName, &dateOfRelease, &ageAtRelease,
+			&decade, &filmsCount, &averageRating)
+		if err != nil {
+			return nil, err
+		}
+		stats.Age = age
+		stats.IsAlive = !deathDate.Valid
+		stats.FilmsCount = filmsCount
+		if averageRating.Valid {
+			stats.AverageRating = &averageRating.Float64
+		}
+		if !filmID.Valid {
+			continue
+		}
+		stats.FilmsByDecade[int(decade.Int64)]++
+		stats.Appearances = append(stats.Appearances, dto.FilmAppearance{
+			FilmID:        uint64(filmID.Int64),
+			Name:          filmName.String,
+			DateOfRelease: dateOfRelease.Time,
+			AgeAtRelease:  int(ageAtRelease.Int64),
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		return nil, nil
+	}
+	if len(stats.Appearances) != 0 {
+		stats.FirstAppearance = &stats.Appearances[0]
+		stats.LatestAppearance = &stats.Appearances[len(stats.Appearances)-1]
+	}
+	return stats, nil
+}
+
+func (r *ActorRepoPG) FindDuplicateActors(ctx context.Context, actor entityActor.Actor) ([]uint64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id
+        FROM actors
+        WHERE LOWER(name) = LOWER($1) AND LOWER(surname) = LOWER($2) AND birthday = $3
+        ORDER BY id
+    `, actor.Name, actor.Surname, actor.Birthday)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			r.zapLogger.Errorf("error in closing query rows: %s", err)
+		}
+	}(rows)
+	candidateIDs := make([]uint64, 0)
+	for rows.Next() {
+		var candidateID uint64
+		err = rows.Scan(&candidateID)
+		if err != nil {
+			return nil, err
+		}
+		candidateIDs = append(candidateIDs, candidateID)
+	}
+	return candidateIDs, rows.Err()
+}
+
+func (r *ActorRepoPG) GetActorDuplicates(ctx context.Context) ([]dto.DuplicatePair, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT a1.id, a2.id
+        FROM actors a1
+        JOIN actors a2 ON a1.id < a2.id
+        AND LOWER(a1.name) = LOWER(a2.name) AND LOWER(a1.surname) = LOWER(a2.surname) AND a1.birthday = a2.birthday
+        ORDER BY a1.id, a2.id
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			r.zapLogger.Errorf("error in closing query rows: %s", err)
+		}
+	}(rows)
+	duplicates := make([]dto.DuplicatePair, 0)
+	for rows.Next() {
+		duplicate := dto.DuplicatePair{Similarity: 1}
+		err = rows.Scan(&duplicate.OriginalID, &duplicate.DuplicateID)
+		if err != nil {
+			return nil, err
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+	return duplicates, rows.Err()
+}
+
+func (r *ActorRepoPG) MergeActors(ctx context.Context, sourceID, targetID uint64) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			err = tx.Rollback()
+			if err != nil {
+				r.zapLogger.Errorf("error in transaction rollback: %s", err)
+			}
+		}
+	}()
+
+	var actorsCount int
+	err = tx.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM (SELECT id FROM actors WHERE id IN ($1, $2) ORDER BY id FOR UPDATE) AS merged_actors
+    `, sourceID, targetID).Scan(&actorsCount)
+	if err != nil {
+		return false, err
+	}
+	if actorsCount != 2 {
+		err = tx.Rollback()
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO film_actors (film_id, actor_id)
+        SELECT film_id, $2 FROM film_actors WHERE actor_id = $1
+        ON CONFLICT DO NOTHING
+    `, sourceID, targetID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM film_actors WHERE actor_id = $1", sourceID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO actor_names (actor_id, locale, name, surname)
+        SELECT $2, locale, name, surname FROM actor_names WHERE actor_id = $1
+        ON CONFLICT DO NOTHING
+    `, sourceID, targetID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE actor_aliases SET actor_id = $2 WHERE actor_id = $1", sourceID, targetID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO actor_aliases (old_id, actor_id) VALUES ($1, $2)", sourceID, targetID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM actors WHERE id = $1", sourceID)
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *ActorRepoPG) SetActorNames(ctx context.Context, actorID uint64, names []entityActor.ActorName) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			err = tx.Rollback()
+			if err != nil {
+				r.zapLogger.Errorf("error in transaction rollback: %s", err)
+			}
+		}
+	}()
+
+	var existingActorID uint64
+	err = tx.QueryRowContext(ctx, "SELECT id FROM actors WHERE id = $1", actorID).Scan(&existingActorID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM actor_names WHERE actor_id = $1", actorID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, name := range names {
+		_, err = tx.ExecContext(ctx, "INSERT INTO actor_names (actor_id, locale, name, surname) VALUES ($1, $2, $3, $4)",
+			actorID, name.Locale, name.Name, name.Surname)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
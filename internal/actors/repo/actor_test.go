@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"testing"
@@ -23,13 +24,9 @@ func TestGetActors(t *testing.T) {
 
 	var nilActors []dto.ActorWithFilms
 
-	mock.ExpectQuery(`
-        SELECT a.id, a.name, a.surname, a.gender, a.birthday, f.id, f.name, f.description, f.date_of_release, f.rating
-        FROM actors a
-        LEFT JOIN film_actors fa ON a.id = fa.actor_id
-        LEFT JOIN films f ON fa.film_id = f.id
-    `).WillReturnError(fmt.Errorf("error"))
-	actor, err := testRepo.GetActors()
+	mock.ExpectQuery("SELECT a.id, (.+) FROM actors a LEFT JOIN LATERAL (.+) FROM actor_names an (.+) LEFT JOIN films f ON fa.film_id = f.id").
+		WithArgs("ru,en").WillReturnError(fmt.Errorf("error"))
+	actor, err := testRepo.GetActors(context.Background(), []string{"ru", "en"})
 	if errExp := mock.ExpectationsWereMet(); errExp != nil {
 		t.Errorf("there were unfulfilled expectations: %s", errExp)
 		return
@@ -37,18 +34,14 @@ func TestGetActors(t *testing.T) {
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, nilActors, actor)
 
-	actorRows := sqlmock.NewRows([]string{"id", "name", "surname", "gender", "birthday", "f_id", "f_name", "f_description", "f_date_of_release", "f_rating"}).
-		AddRow(1, "John", "Doe", "Male", time.Time{}.Add(time.Hour), 1, "Film 1", "Description 1", time.Time{}.Add(time.Hour), 8.0).
-		AddRow(2, "Jane", "Smith", "Female", time.Time{}.Add(time.Hour), 2, "Film 2", "Description 2", time.Time{}.Add(time.Hour), 7.5)
+	actorRows := sqlmock.NewRows([]string{"id", "name", "surname", "gender", "birthday", "death_date", "f_id", "f_name", "f_description", "f_date_of_release", "f_rating"}).
+		AddRow(1, "John", "Doe", "Male", time.Time{}.Add(time.Hour), nil, 1, "Film 1", "Description 1", time.Time{}.Add(time.Hour), 8.0).
+		AddRow(2, "Jane", "Smith", "Female", time.Time{}.Add(time.Hour), nil, 2, "Film 2", "Description 2", time.Time{}.Add(time.Hour), 7.5)
 
-	mock.ExpectQuery(`
-        SELECT a.id, a.name, a.surname, a.gender, a.birthday, f.id, f.name, f.description, f.date_of_release, f.rating
-        FROM actors a
-        LEFT JOIN film_actors fa ON a.id = fa.actor_id
-        LEFT JOIN films f ON fa.film_id = f.id
-    `).WillReturnRows(actorRows)
+	mock.ExpectQuery("SELECT a.id, (.+) FROM actors a LEFT JOIN LATERAL (.+) FROM actor_names an (.+) LEFT JOIN films f ON fa.film_id = f.id").
+		WithArgs("ru,en").WillReturnRows(actorRows)
 
-	actors, err := testRepo.GetActors()
+	actors, err := testRepo.GetActors(context.Background(), []string{"ru", "en"})
 	assert.NoError(t, err)
 
 	assert.Equal(t, 2, len(actors))
@@ -67,15 +60,10 @@ func TestGetActorByID(t *testing.T) {
 	var nilActorWithFilms *dto.ActorWithFilms
 
 	mock.
-		ExpectQuery(`
-        SELECT a.id, a.name, a.surname, a.gender, a.birthday, f.id, f.name, f.description, f.date_of_release, f.rating
-        FROM actors a
-        LEFT JOIN film_actors fa ON a.id = fa.actor_id
-        LEFT JOIN films f ON fa.film_id = f.id
-        WHERE a.id
-    `).WithArgs(id).WillReturnError(fmt.Errorf("db_error"))
-
-	actor, err := testRepo.GetActorByID(id)
+		ExpectQuery("SELECT a.id, (.+) FROM actors a LEFT JOIN LATERAL (.+) FROM actor_names an (.+) WHERE a.id").
+		WithArgs(id, "ru").WillReturnError(fmt.Errorf("db_error"))
+
+	actor, err := testRepo.GetActorByID(context.Background(), id, []string{"ru"})
 	if errExp := mock.ExpectationsWereMet(); errExp != nil {
 		t.Errorf("there were unfulfilled expectations: %s", errExp)
 		return
@@ -84,15 +72,10 @@ func TestGetActorByID(t *testing.T) {
 	assert.Equal(t, nilActorWithFilms, actor)
 
 	mock.
-		ExpectQuery(`
-        SELECT a.id, a.name, a.surname, a.gender, a.birthday, f.id, f.name, f.description, f.date_of_release, f.rating
-        FROM actors a
-        LEFT JOIN film_actors fa ON a.id = fa.actor_id
-        LEFT JOIN films f ON fa.film_id = f.id
-        WHERE a.id
-    `).WithArgs(id).WillReturnError(sql.ErrNoRows)
-
-	actor, err = testRepo.GetActorByID(id)
+		ExpectQuery("SELECT a.id, (.+) FROM actors a LEFT JOIN LATERAL (.+) FROM actor_names an (.+) WHERE a.id").
+		WithArgs(id, "ru").WillReturnError(sql.ErrNoRows)
+
+	actor, err = testRepo.GetActorByID(context.Background(), id, []string{"ru"})
 	if errExp := mock.ExpectationsWereMet(); errExp != nil {
 		t.Errorf("there were unfulfilled expectations: %s", errExp)
 		return
@@ -104,23 +87,23 @@ func TestGetActorByID(t *testing.T) {
 	var expectedActorName = "John"
 	var expectedFilmID uint64 = 1
 	var expectedFilmName = "Film 1"
-	mock.ExpectQuery(`
-        SELECT a.id, a.name, a.surname, a.gender, a.birthday, f.id, f.name, f.description, f.date_of_release, f.rating
-        FROM actors a
-        LEFT JOIN film_actors fa ON a.id = fa.actor_id
-        LEFT JOIN films f ON fa.film_id = f.id
-        WHERE a.id
-    `).WithArgs(id).
-		WillReturnRows(sqlmock.NewRows([]string{"a.id", "a.name", "a.surname", "a.gender", "a.birthday", "f.id", "f.name", "f.description", "f.date_of_release", "f.rating"}).
-			AddRow(expectedActorID, expectedActorName, "Doe", "male", time.Time{}.Add(time.Hour), expectedFilmID, expectedFilmName, "Film Description", time.Time{}.Add(time.Hour), 8.0))
+	mock.ExpectQuery("SELECT a.id, (.+) FROM actors a LEFT JOIN LATERAL (.+) FROM actor_names an (.+) WHERE a.id").
+		WithArgs(id, "ru").
+		WillReturnRows(sqlmock.NewRows([]string{"a.id", "a.name", "a.surname", "a.gender", "a.birthday", "a.death_date", "f.id", "f.name", "f.description", "f.date_of_release", "f.rating"}).
+			AddRow(expectedActorID, expectedActorName, "Doe", "male", time.Time{}.Add(time.Hour), nil, expectedFilmID, expectedFilmName, "Film Description", time.Time{}.Add(time.Hour), 8.0))
+	mock.ExpectQuery("SELECT locale, name, surname FROM actor_names WHERE actor_id = (.+)").
+		WithArgs(expectedActorID).
+		WillReturnRows(sqlmock.NewRows([]string{"locale", "name", "surname"}).
+			AddRow("ru", "Джон", "Доу"))
 
-	actor, err = testRepo.GetActorByID(id)
+	actor, err = testRepo.GetActorByID(context.Background(), id, []string{"ru"})
 
 	assert.Equal(t, nil, err)
 	assert.NotEqual(t, nil, actor)
 	assert.Equal(t, expectedActorID, actor.Actor.ID)
 	assert.Equal(t, expectedActorName, actor.Actor.Name)
 	assert.Equal(t, 1, len(actor.Films))
+	assert.Equal(t, []entityActor.ActorName{{Locale: "ru", Name: "Джон", Surname: "Доу"}}, actor.Actor.Transliterations)
 
 	err = mock.ExpectationsWereMet()
 	assert.Equal(t, nil, err)
@@ -138,11 +121,11 @@ func TestAddActor(t *testing.T) {
 	expectedActorID := uint64(123)
 
 	mock.ExpectQuery("INSERT INTO actors (.+) RETURNING id").
-		WithArgs("John", "Doe", "Male", time.Time{}.Add(time.Hour)).
+		WithArgs("John", "Doe", "Male", time.Time{}.Add(time.Hour), nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(expectedActorID))
 
 	actor := entityActor.Actor{Name: "John", Surname: "Doe", Gender: "Male", Birthday: time.Time{}.Add(time.Hour)}
-	actorID, err := testRepo.AddActor(actor)
+	actorID, err := testRepo.AddActor(context.Background(), actor)
 	assert.Equal(t, nil, err)
 	assert.Equal(t, expectedActorID, actorID)
 	err = mock.ExpectationsWereMet()
@@ -160,24 +143,258 @@ func TestUpdateActor(t *testing.T) {
 	testRepo := NewActorRepo(db, zap.NewNop().Sugar())
 
 	mock.ExpectExec("UPDATE actors SET (.+) WHERE id = (.+)").
-		WithArgs("John", "Doe", "Male", time.Time{}.Add(1), 1).
+		WithArgs("John", "Doe", "Male", time.Time{}.Add(1), nil, 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	actor := entityActor.Actor{ID: 1, Name: "John", Surname: "Doe", Gender: "Male", Birthday: time.Time{}.Add(1)}
-	success, err := testRepo.UpdateActor(actor)
+	success, err := testRepo.UpdateActor(context.Background(), actor)
 	assert.Equal(t, nil, err)
 	assert.Equal(t, true, success)
 	err = mock.ExpectationsWereMet()
 	assert.Equal(t, nil, err)
 
 	mock.ExpectExec("UPDATE actors SET (.+) WHERE id = (.+)").
-		WithArgs("John", "Doe", "Male", time.Time{}.Add(1), 1).
+		WithArgs("John", "Doe", "Male", time.Time{}.Add(1), nil, 1).
 		WillReturnError(fmt.Errorf("error"))
 
-	success, err = testRepo.UpdateActor(actor)
+	success, err = testRepo.UpdateActor(context.Background(), actor)
 
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, false, success)
 	err = mock.ExpectationsWereMet()
 	assert.Equal(t, nil, err)
 }
+
+func TestGetActorStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can not create mock")
+	}
+	defer db.Close()
+	testRepo := NewActorRepo(db, zap.NewNop().Sugar())
+
+	var id uint64 = 1
+	var nilStats *dto.ActorStats
+
+	mock.ExpectQuery("SELECT (.+) FROM actors a (.+) WHERE a.id = (.+)").
+		WithArgs(id).WillReturnError(fmt.Errorf("db_error"))
+	stats, err := testRepo.GetActorStats(context.Background(), id)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, nilStats, stats)
+
+	columns := []string{"a.id", "a.death_date", "age", "f.id", "f.name", "f.date_of_release", "age_at_release",
+		"decade", "films_count", "avg_rating"}
+	mock.ExpectQuery("SELECT (.+) FROM actors a (.+) WHERE a.id = (.+)").
+		WithArgs(id).WillReturnRows(sqlmock.NewRows(columns))
+	stats, err = testRepo.GetActorStats(context.Background(), id)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nilStats, stats)
+
+	mock.ExpectQuery("SELECT (.+) FROM actors a (.+) WHERE a.id = (.+)").
+		WithArgs(id).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow(id, nil, 40, nil, nil, nil, nil, nil, 0, nil))
+	stats, err = testRepo.GetActorStats(context.Background(), id)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 40, stats.Age)
+	assert.Equal(t, true, stats.IsAlive)
+	assert.Equal(t, 0, stats.FilmsCount)
+	assert.Equal(t, (*float64)(nil), stats.AverageRating)
+	assert.Equal(t, (*dto.FilmAppearance)(nil), stats.FirstAppearance)
+
+	deathDate := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	firstRelease := time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC)
+	latestRelease := time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT (.+) FROM actors a (.+) WHERE a.id = (.+)").
+		WithArgs(id).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow(id, deathDate, 60, 1, "Film 1", firstRelease, 35, 1990, 3, 7.5).
+		AddRow(id, deathDate, 60, 2, "Film 2", firstRelease, 35, 1990, 3, 7.5).
+		AddRow(id, deathDate, 60, 3, "Film 3", latestRelease, 45, 2000, 3, 7.5))
+	stats, err = testRepo.GetActorStats(context.Background(), id)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, stats.IsAlive)
+	assert.Equal(t, 3, stats.FilmsCount)
+	assert.Equal(t, 7.5, *stats.AverageRating)
+	assert.Equal(t, map[int]int{1990: 2, 2000: 1}, stats.FilmsByDecade)
+	assert.Equal(t, uint64(1), stats.FirstAppearance.FilmID)
+	assert.Equal(t, uint64(3), stats.LatestAppearance.FilmID)
+	assert.Equal(t, 45, stats.LatestAppearance.AgeAtRelease)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindDuplicateActors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	testRepo := NewActorRepo(db, zap.NewNop().Sugar())
+	actor := entityActor.Actor{Name: "John", Surname: "Doe", Gender: "male", Birthday: time.Time{}.Add(time.Hour)}
+
+	mock.ExpectQuery("SELECT id FROM actors WHERE (.+)").
+		WithArgs("John", "Doe", time.Time{}.Add(time.Hour)).
+		WillReturnError(fmt.Errorf("error"))
+	candidateIDs, err := testRepo.FindDuplicateActors(context.Background(), actor)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 0, len(candidateIDs))
+
+	mock.ExpectQuery("SELECT id FROM actors WHERE (.+)").
+		WithArgs("John", "Doe", time.Time{}.Add(time.Hour)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(5))
+	candidateIDs, err = testRepo.FindDuplicateActors(context.Background(), actor)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []uint64{2, 5}, candidateIDs)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetActorDuplicates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	testRepo := NewActorRepo(db, zap.NewNop().Sugar())
+
+	mock.ExpectQuery("SELECT a1.id, a2.id FROM actors a1 JOIN actors a2 (.+)").
+		WillReturnError(fmt.Errorf("error"))
+	duplicates, err := testRepo.GetActorDuplicates(context.Background())
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 0, len(duplicates))
+
+	mock.ExpectQuery("SELECT a1.id, a2.id FROM actors a1 JOIN actors a2 (.+)").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "id"}).AddRow(1, 2))
+	duplicates, err = testRepo.GetActorDuplicates(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []dto.DuplicatePair{{OriginalID: 1, DuplicateID: 2, Similarity: 1}}, duplicates)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeActors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	testRepo := NewActorRepo(db, zap.NewNop().Sugar())
+
+	var sourceID, targetID uint64 = 1, 2
+
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("error"))
+	wasMerged, err := testRepo.MergeActors(context.Background(), sourceID, targetID)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, false, wasMerged)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COUNT(.+) FROM \\(SELECT id FROM actors WHERE id IN (.+) ORDER BY id FOR UPDATE\\)").
+		WithArgs(sourceID, targetID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+	wasMerged, err = testRepo.MergeActors(context.Background(), sourceID, targetID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, wasMerged)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COUNT(.+) FROM \\(SELECT id FROM actors WHERE id IN (.+) ORDER BY id FOR UPDATE\\)").
+		WithArgs(sourceID, targetID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectExec("INSERT INTO film_actors (.+) SELECT film_id, (.+) FROM film_actors WHERE actor_id (.+) ON CONFLICT DO NOTHING").
+		WithArgs(sourceID, targetID).
+		WillReturnError(fmt.Errorf("error"))
+	mock.ExpectRollback()
+	wasMerged, err = testRepo.MergeActors(context.Background(), sourceID, targetID)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, false, wasMerged)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COUNT(.+) FROM \\(SELECT id FROM actors WHERE id IN (.+) ORDER BY id FOR UPDATE\\)").
+		WithArgs(sourceID, targetID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectExec("INSERT INTO film_actors (.+) SELECT film_id, (.+) FROM film_actors WHERE actor_id (.+) ON CONFLICT DO NOTHING").
+		WithArgs(sourceID, targetID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("DELETE FROM film_actors WHERE actor_id = (.+)").
+		WithArgs(sourceID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO actor_names (.+) SELECT (.+) FROM actor_names WHERE actor_id (.+) ON CONFLICT DO NOTHING").
+		WithArgs(sourceID, targetID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE actor_aliases SET actor_id = (.+) WHERE actor_id = (.+)").
+		WithArgs(sourceID, targetID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO actor_aliases (.+)").
+		WithArgs(sourceID, targetID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM actors WHERE id = (.+)").
+		WithArgs(sourceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	wasMerged, err = testRepo.MergeActors(context.Background(), sourceID, targetID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, wasMerged)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetActorNames(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	testRepo := NewActorRepo(db, zap.NewNop().Sugar())
+
+	var actorID uint64 = 1
+	names := []entityActor.ActorName{
+		{Locale: "en", Name: "John", Surname: "Doe"},
+		{Locale: "ru", Name: "Джон", Surname: "Доу"},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM actors WHERE id = (.+)").
+		WithArgs(actorID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	wasSet, err := testRepo.SetActorNames(context.Background(), actorID, names)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, wasSet)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM actors WHERE id = (.+)").
+		WithArgs(actorID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(actorID))
+	mock.ExpectExec("DELETE FROM actor_names WHERE actor_id = (.+)").
+		WithArgs(actorID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO actor_names").
+		WithArgs(actorID, names[0].Locale, names[0].Name, names[0].Surname).
+		WillReturnError(fmt.Errorf("error"))
+	mock.ExpectRollback()
+	wasSet, err = testRepo.SetActorNames(context.Background(), actorID, names)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, false, wasSet)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM actors WHERE id = (.+)").
+		WithArgs(actorID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(actorID))
+	mock.ExpectExec("DELETE FROM actor_names WHERE actor_id = (.+)").
+		WithArgs(actorID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, name := range names {
+		mock.ExpectExec("INSERT INTO actor_names").
+			WithArgs(actorID, name.Locale, name.Name, name.Surname).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+	wasSet, err = testRepo.SetActorNames(context.Background(), actorID, names)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, wasSet)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -5,6 +5,7 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -36,76 +37,151 @@ func (m *MockActorRepo) EXPECT() *MockActorRepoMockRecorder {
 }
 
 // AddActor mocks base method.
-func (m *MockActorRepo) AddActor(actor entity.Actor) (uint64, error) {
+func (m *MockActorRepo) AddActor(ctx context.Context, actor entity.Actor) (uint64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddActor", actor)
+	ret := m.ctrl.Call(m, "AddActor", ctx, actor)
 	ret0, _ := ret[0].(uint64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddActor indicates an expected call of AddActor.
-func (mr *MockActorRepoMockRecorder) AddActor(actor interface{}) *gomock.Call {
+func (mr *MockActorRepoMockRecorder) AddActor(ctx, actor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddActor", reflect.TypeOf((*MockActorRepo)(nil).AddActor), actor)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddActor", reflect.TypeOf((*MockActorRepo)(nil).AddActor), ctx, actor)
 }
 
 // DeleteActor mocks base method.
-func (m *MockActorRepo) DeleteActor(ID uint64) (bool, error) {
+func (m *MockActorRepo) DeleteActor(ctx context.Context, ID uint64) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteActor", ID)
+	ret := m.ctrl.Call(m, "DeleteActor", ctx, ID)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DeleteActor indicates an expected call of DeleteActor.
-func (mr *MockActorRepoMockRecorder) DeleteActor(ID interface{}) *gomock.Call {
+func (mr *MockActorRepoMockRecorder) DeleteActor(ctx, ID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteActor", reflect.TypeOf((*MockActorRepo)(nil).DeleteActor), ID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteActor", reflect.TypeOf((*MockActorRepo)(nil).DeleteActor), ctx, ID)
+}
+
+// FindDuplicateActors mocks base method.
+func (m *MockActorRepo) FindDuplicateActors(ctx context.Context, actor entity.Actor) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDuplicateActors", ctx, actor)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDuplicateActors indicates an expected call of FindDuplicateActors.
+func (mr *MockActorRepoMockRecorder) FindDuplicateActors(ctx, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDuplicateActors", reflect.TypeOf((*MockActorRepo)(nil).FindDuplicateActors), ctx, actor)
 }
 
 // GetActorByID mocks base method.
-func (m *MockActorRepo) GetActorByID(actorID uint64) (*dto.ActorWithFilms, error) {
+func (m *MockActorRepo) GetActorByID(ctx context.Context, actorID uint64, langs []string) (*dto.ActorWithFilms, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetActorByID", actorID)
+	ret := m.ctrl.Call(m, "GetActorByID", ctx, actorID, langs)
 	ret0, _ := ret[0].(*dto.ActorWithFilms)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetActorByID indicates an expected call of GetActorByID.
-func (mr *MockActorRepoMockRecorder) GetActorByID(actorID interface{}) *gomock.Call {
+func (mr *MockActorRepoMockRecorder) GetActorByID(ctx, actorID, langs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActorByID", reflect.TypeOf((*MockActorRepo)(nil).GetActorByID), ctx, actorID, langs)
+}
+
+// GetActorDuplicates mocks base method.
+func (m *MockActorRepo) GetActorDuplicates(ctx context.Context) ([]dto.DuplicatePair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActorDuplicates", ctx)
+	ret0, _ := ret[0].([]dto.DuplicatePair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActorDuplicates indicates an expected call of GetActorDuplicates.
+func (mr *MockActorRepoMockRecorder) GetActorDuplicates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActorDuplicates", reflect.TypeOf((*MockActorRepo)(nil).GetActorDuplicates), ctx)
+}
+
+// GetActorStats mocks base method.
+func (m *MockActorRepo) GetActorStats(ctx context.Context, actorID uint64) (*dto.ActorStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActorStats", ctx, actorID)
+	ret0, _ := ret[0].(*dto.ActorStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActorStats indicates an expected call of GetActorStats.
+func (mr *MockActorRepoMockRecorder) GetActorStats(ctx, actorID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActorByID", reflect.TypeOf((*MockActorRepo)(nil).GetActorByID), actorID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActorStats", reflect.TypeOf((*MockActorRepo)(nil).GetActorStats), ctx, actorID)
 }
 
 // GetActors mocks base method.
-func (m *MockActorRepo) GetActors() ([]dto.ActorWithFilms, error) {
+func (m *MockActorRepo) GetActors(ctx context.Context, langs []string) ([]dto.ActorWithFilms, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetActors")
+	ret := m.ctrl.Call(m, "GetActors", ctx, langs)
 	ret0, _ := ret[0].([]dto.ActorWithFilms)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetActors indicates an expected call of GetActors.
-func (mr *MockActorRepoMockRecorder) GetActors() *gomock.Call {
+func (mr *MockActorRepoMockRecorder) GetActors(ctx, langs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActors", reflect.TypeOf((*MockActorRepo)(nil).GetActors), ctx, langs)
+}
+
+// MergeActors mocks base method.
+func (m *MockActorRepo) MergeActors(ctx context.Context, sourceID, targetID uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeActors", ctx, sourceID, targetID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeActors indicates an expected call of MergeActors.
+func (mr *MockActorRepoMockRecorder) MergeActors(ctx, sourceID, targetID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeActors", reflect.TypeOf((*MockActorRepo)(nil).MergeActors), ctx, sourceID, targetID)
+}
+
+// SetActorNames mocks base method.
+func (m *MockActorRepo) SetActorNames(ctx context.Context, actorID uint64, names []entity.ActorName) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetActorNames", ctx, actorID, names)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetActorNames indicates an expected call of SetActorNames.
+func (mr *MockActorRepoMockRecorder) SetActorNames(ctx, actorID, names interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActors", reflect.TypeOf((*MockActorRepo)(nil).GetActors))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActorNames", reflect.TypeOf((*MockActorRepo)(nil).SetActorNames), ctx, actorID, names)
 }
 
 // UpdateActor mocks base method.
-func (m *MockActorRepo) UpdateActor(actor entity.Actor) (bool, error) {
+func (m *MockActorRepo) UpdateActor(ctx context.Context, actor entity.Actor) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateActor", actor)
+	ret := m.ctrl.Call(m, "UpdateActor", ctx, actor)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateActor indicates an expected call of UpdateActor.
-func (mr *MockActorRepoMockRecorder) UpdateActor(actor interface{}) *gomock.Call {
+func (mr *MockActorRepoMockRecorder) UpdateActor(ctx, actor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateActor", reflect.TypeOf((*MockActorRepo)(nil).UpdateActor), actor)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateActor", reflect.TypeOf((*MockActorRepo)(nil).UpdateActor), ctx, actor)
 }
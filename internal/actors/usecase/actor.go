@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"context"
+
 	"github.com/ilyushkaaa/Filmoteka/internal/actors/entity"
 	"github.com/ilyushkaaa/Filmoteka/internal/actors/repo"
 	"github.com/ilyushkaaa/Filmoteka/internal/dto"
@@ -8,11 +10,15 @@ import (
 
 //go:generate mockgen -source=actor.go -destination=actor_mock.go -package=usecase ActorUseCase
 type ActorUseCase interface {
-	GetActorByID(actorID uint64) (*dto.ActorWithFilms, error)
-	GetActors() ([]dto.ActorWithFilms, error)
-	AddActor(actor entity.Actor) (*entity.Actor, error)
-	UpdateActor(actor entity.Actor) error
-	DeleteActor(ID uint64) error
+	GetActorByID(ctx context.Context, actorID uint64, langs []string) (*dto.ActorWithFilms, error)
+	GetActors(ctx context.Context, langs []string) ([]dto.ActorWithFilms, error)
+	AddActor(ctx context.Context, actor entity.Actor, force bool) (*entity.Actor, error)
+	UpdateActor(ctx context.Context, actor entity.Actor) error
+	DeleteActor(ctx context.Context, ID uint64) error
+	GetActorStats(ctx context.Context, actorID uint64) (*dto.ActorStats, error)
+	GetActorDuplicates(ctx context.Context) ([]dto.DuplicatePair, error)
+	MergeActors(ctx context.Context, sourceID, targetID uint64) (*dto.ActorWithFilms, error)
+	SetActorNames(ctx context.Context, actorID uint64, names []entity.ActorName) error
 }
 
 type ActorUseCaseApp struct {
@@ -24,8 +30,8 @@ func NewActorUseCase(actorRepo repo.ActorRepo) *ActorUseCaseApp {
 		actorRepo: actorRepo,
 	}
 }
-func (r *ActorUseCaseApp) GetActorByID(actorID uint64) (*dto.ActorWithFilms, error) {
-	actor, err := r.actorRepo.GetActorByID(actorID)
+func (r *ActorUseCaseApp) GetActorByID(ctx context.Context, actorID uint64, langs []string) (*dto.ActorWithFilms, error) {
+	actor, err := r.actorRepo.GetActorByID(ctx, actorID, langs)
 	if err != nil {
 		return nil, err
 	}
@@ -35,16 +41,25 @@ func (r *ActorUseCaseApp) GetActorByID(actorID uint64) (*dto.ActorWithFilms, err
 	return actor, nil
 }
 
-func (r *ActorUseCaseApp) GetActors() ([]dto.ActorWithFilms, error) {
-	actors, err := r.actorRepo.GetActors()
+func (r *ActorUseCaseApp) GetActors(ctx context.Context, langs []string) ([]dto.ActorWithFilms, error) {
+	actors, err := r.actorRepo.GetActors(ctx, langs)
 	if err != nil {
 		return nil, err
 	}
 	return actors, nil
 }
 
-func (r *ActorUseCaseApp) AddActor(actor entity.Actor) (*entity.Actor, error) {
-	actorID, err := r.actorRepo.AddActor(actor)
+func (r *ActorUseCaseApp) AddActor(ctx context.Context, actor entity.Actor, force bool) (*entity.Actor, error) {
+	if !force {
+		candidateIDs, err := r.actorRepo.FindDuplicateActors(ctx, actor)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidateIDs) != 0 {
+			return nil, &DuplicateActorError{CandidateIDs: candidateIDs}
+		}
+	}
+	actorID, err := r.actorRepo.AddActor(ctx, actor)
 	if err != nil {
 		return nil, err
 	}
@@ -52,8 +67,8 @@ func (r *ActorUseCaseApp) AddActor(actor entity.Actor) (*entity.Actor, error) {
 	return &actor, nil
 }
 
-func (r *ActorUseCaseApp) UpdateActor(actor entity.Actor) error {
-	wasUpdated, err := r.actorRepo.UpdateActor(actor)
+func (r *ActorUseCaseApp) UpdateActor(ctx context.Context, actor entity.Actor) error {
+	wasUpdated, err := r.actorRepo.UpdateActor(ctx, actor)
 	if err != nil {
 		return err
 	}
@@ -63,8 +78,8 @@ func (r *ActorUseCaseApp) UpdateActor(actor entity.Actor) error {
 	return nil
 }
 
-func (r *ActorUseCaseApp) DeleteActor(ID uint64) error {
-	wasDeleted, err := r.actorRepo.DeleteActor(ID)
+func (r *ActorUseCaseApp) DeleteActor(ctx context.Context, ID uint64) error {
+	wasDeleted, err := r.actorRepo.DeleteActor(ctx, ID)
 	if err != nil {
 		return err
 	}
@@ -73,3 +88,54 @@ func (r *ActorUseCaseApp) DeleteActor(ID uint64) error {
 	}
 	return nil
 }
+
+func (r *ActorUseCaseApp) GetActorStats(ctx context.Context, actorID uint64) (*dto.ActorStats, error) {
+	stats, err := r.actorRepo.GetActorStats(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		return nil, ErrActorNotFound
+	}
+	return stats, nil
+}
+
+func (r *ActorUseCaseApp) GetActorDuplicates(ctx context.Context) ([]dto.DuplicatePair, error) {
+	duplicates, err := r.actorRepo.GetActorDuplicates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return duplicates, nil
+}
+
+func (r *ActorUseCaseApp) MergeActors(ctx context.Context, sourceID, targetID uint64) (*dto.ActorWithFilms, error) {
+	if sourceID == targetID {
+		return nil, ErrSameActorMerge
+	}
+	wasMerged, err := r.actorRepo.MergeActors(ctx, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if !wasMerged {
+		return nil, ErrActorNotFound
+	}
+	mergedActor, err := r.actorRepo.GetActorByID(ctx, targetID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if mergedActor == nil {
+		return nil, ErrActorNotFound
+	}
+	return mergedActor, nil
+}
+
+func (r *ActorUseCaseApp) SetActorNames(ctx context.Context, actorID uint64, names []entity.ActorName) error {
+	wasSet, err := r.actorRepo.SetActorNames(ctx, actorID, names)
+	if err != nil {
+		return err
+	}
+	if !wasSet {
+		return ErrActorNotFound
+	}
+	return nil
+}
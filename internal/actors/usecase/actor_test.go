@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -19,16 +20,16 @@ func TestGetActors(t *testing.T) {
 	testUseCase := NewActorUseCase(testRepo)
 
 	var actorWithFilmsExpected []dto.ActorWithFilms
-	testRepo.EXPECT().GetActors().
+	testRepo.EXPECT().GetActors(gomock.Any(), nil).
 		Return(nil, fmt.Errorf("error"))
-	actors, err := testUseCase.GetActors()
+	actors, err := testUseCase.GetActors(context.Background(), nil)
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, actorWithFilmsExpected, actors)
 
 	actorWithFilmsResult := make([]dto.ActorWithFilms, 0)
-	testRepo.EXPECT().GetActors().
+	testRepo.EXPECT().GetActors(gomock.Any(), nil).
 		Return(actorWithFilmsResult, nil)
-	actors, err = testUseCase.GetActors()
+	actors, err = testUseCase.GetActors(context.Background(), nil)
 	assert.Equal(t, nil, err)
 	assert.Equal(t, actorWithFilmsResult, actors)
 
@@ -43,22 +44,22 @@ func TestGetActorByID(t *testing.T) {
 
 	var id uint64 = 1
 	var actorWithFilmsExpected *dto.ActorWithFilms
-	testRepo.EXPECT().GetActorByID(id).
+	testRepo.EXPECT().GetActorByID(gomock.Any(), id, nil).
 		Return(nil, fmt.Errorf("error"))
-	actors, err := testUseCase.GetActorByID(id)
+	actors, err := testUseCase.GetActorByID(context.Background(), id, nil)
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, actorWithFilmsExpected, actors)
 
-	testRepo.EXPECT().GetActorByID(id).
+	testRepo.EXPECT().GetActorByID(gomock.Any(), id, nil).
 		Return(nil, nil)
-	actors, err = testUseCase.GetActorByID(id)
+	actors, err = testUseCase.GetActorByID(context.Background(), id, nil)
 	assert.Equal(t, ErrActorNotFound, err)
 	assert.Equal(t, actorWithFilmsExpected, actors)
 
 	actorWithFilmsResult := &dto.ActorWithFilms{}
-	testRepo.EXPECT().GetActorByID(id).
+	testRepo.EXPECT().GetActorByID(gomock.Any(), id, nil).
 		Return(actorWithFilmsResult, nil)
-	actors, err = testUseCase.GetActorByID(id)
+	actors, err = testUseCase.GetActorByID(context.Background(), id, nil)
 	assert.Equal(t, nil, err)
 	assert.Equal(t, actorWithFilmsResult, actors)
 }
@@ -73,15 +74,33 @@ func TestAddActor(t *testing.T) {
 	var id uint64 = 1
 	actorToAdd := entity.Actor{}
 	var actorExpected *entity.Actor
-	testRepo.EXPECT().AddActor(actorToAdd).
+
+	testRepo.EXPECT().FindDuplicateActors(gomock.Any(), actorToAdd).
+		Return(nil, fmt.Errorf("error"))
+	actor, err := testUseCase.AddActor(context.Background(), actorToAdd, false)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, actorExpected, actor)
+
+	testRepo.EXPECT().FindDuplicateActors(gomock.Any(), actorToAdd).
+		Return([]uint64{2, 3}, nil)
+	actor, err = testUseCase.AddActor(context.Background(), actorToAdd, false)
+	assert.ErrorIs(t, err, ErrActorDuplicate)
+	var duplicateErr *DuplicateActorError
+	assert.ErrorAs(t, err, &duplicateErr)
+	assert.Equal(t, []uint64{2, 3}, duplicateErr.CandidateIDs)
+	assert.Equal(t, actorExpected, actor)
+
+	testRepo.EXPECT().FindDuplicateActors(gomock.Any(), actorToAdd).
+		Return([]uint64{}, nil)
+	testRepo.EXPECT().AddActor(gomock.Any(), actorToAdd).
 		Return(id, fmt.Errorf("error"))
-	actor, err := testUseCase.AddActor(actorToAdd)
+	actor, err = testUseCase.AddActor(context.Background(), actorToAdd, false)
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, actorExpected, actor)
 
-	testRepo.EXPECT().AddActor(actorToAdd).
+	testRepo.EXPECT().AddActor(gomock.Any(), actorToAdd).
 		Return(id, nil)
-	actor, err = testUseCase.AddActor(actorToAdd)
+	actor, err = testUseCase.AddActor(context.Background(), actorToAdd, true)
 	actorToAdd.ID = id
 	assert.Equal(t, nil, err)
 	assert.Equal(t, &actorToAdd, actor)
@@ -95,13 +114,123 @@ func TestUpdateActor(t *testing.T) {
 	testUseCase := NewActorUseCase(testRepo)
 
 	actorToUpdate := entity.Actor{}
-	testRepo.EXPECT().UpdateActor(actorToUpdate).
+	testRepo.EXPECT().UpdateActor(gomock.Any(), actorToUpdate).
 		Return(false, fmt.Errorf("error"))
-	err := testUseCase.UpdateActor(actorToUpdate)
+	err := testUseCase.UpdateActor(context.Background(), actorToUpdate)
 	assert.NotEqual(t, nil, err)
 
-	testRepo.EXPECT().UpdateActor(actorToUpdate).
+	testRepo.EXPECT().UpdateActor(gomock.Any(), actorToUpdate).
 		Return(false, nil)
-	err = testUseCase.UpdateActor(actorToUpdate)
+	err = testUseCase.UpdateActor(context.Background(), actorToUpdate)
 	assert.Equal(t, ErrActorNotFound, err)
 }
+
+func TestGetActorStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockActorRepo(ctrl)
+	testUseCase := NewActorUseCase(testRepo)
+
+	var id uint64 = 1
+	var statsExpected *dto.ActorStats
+	testRepo.EXPECT().GetActorStats(gomock.Any(), id).
+		Return(nil, fmt.Errorf("error"))
+	stats, err := testUseCase.GetActorStats(context.Background(), id)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, statsExpected, stats)
+
+	testRepo.EXPECT().GetActorStats(gomock.Any(), id).
+		Return(nil, nil)
+	stats, err = testUseCase.GetActorStats(context.Background(), id)
+	assert.Equal(t, ErrActorNotFound, err)
+	assert.Equal(t, statsExpected, stats)
+
+	statsResult := &dto.ActorStats{ActorID: id}
+	testRepo.EXPECT().GetActorStats(gomock.Any(), id).
+		Return(statsResult, nil)
+	stats, err = testUseCase.GetActorStats(context.Background(), id)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, statsResult, stats)
+}
+
+func TestGetActorDuplicates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockActorRepo(ctrl)
+	testUseCase := NewActorUseCase(testRepo)
+
+	var duplicatesExpected []dto.DuplicatePair
+	testRepo.EXPECT().GetActorDuplicates(gomock.Any()).
+		Return(nil, fmt.Errorf("error"))
+	duplicates, err := testUseCase.GetActorDuplicates(context.Background())
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, duplicatesExpected, duplicates)
+
+	duplicatesResult := []dto.DuplicatePair{{OriginalID: 1, DuplicateID: 2, Similarity: 1}}
+	testRepo.EXPECT().GetActorDuplicates(gomock.Any()).
+		Return(duplicatesResult, nil)
+	duplicates, err = testUseCase.GetActorDuplicates(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, duplicatesResult, duplicates)
+}
+
+func TestMergeActors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockActorRepo(ctrl)
+	testUseCase := NewActorUseCase(testRepo)
+
+	var sourceID, targetID uint64 = 1, 2
+	var actorExpected *dto.ActorWithFilms
+
+	merged, err := testUseCase.MergeActors(context.Background(), sourceID, sourceID)
+	assert.Equal(t, ErrSameActorMerge, err)
+	assert.Equal(t, actorExpected, merged)
+
+	testRepo.EXPECT().MergeActors(gomock.Any(), sourceID, targetID).
+		Return(false, fmt.Errorf("error"))
+	merged, err = testUseCase.MergeActors(context.Background(), sourceID, targetID)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, actorExpected, merged)
+
+	testRepo.EXPECT().MergeActors(gomock.Any(), sourceID, targetID).
+		Return(false, nil)
+	merged, err = testUseCase.MergeActors(context.Background(), sourceID, targetID)
+	assert.Equal(t, ErrActorNotFound, err)
+	assert.Equal(t, actorExpected, merged)
+
+	actorResult := &dto.ActorWithFilms{}
+	testRepo.EXPECT().MergeActors(gomock.Any(), sourceID, targetID).
+		Return(true, nil)
+	testRepo.EXPECT().GetActorByID(gomock.Any(), targetID, nil).
+		Return(actorResult, nil)
+	merged, err = testUseCase.MergeActors(context.Background(), sourceID, targetID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, actorResult, merged)
+}
+
+func TestSetActorNames(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockActorRepo(ctrl)
+	testUseCase := NewActorUseCase(testRepo)
+
+	var actorID uint64 = 1
+	items := []entity.ActorName{{Locale: "ru", Name: "Джон", Surname: "Доу"}}
+
+	testRepo.EXPECT().SetActorNames(gomock.Any(), actorID, items).Return(false, fmt.Errorf("error"))
+	err := testUseCase.SetActorNames(context.Background(), actorID, items)
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().SetActorNames(gomock.Any(), actorID, items).Return(false, nil)
+	err = testUseCase.SetActorNames(context.Background(), actorID, items)
+	assert.Equal(t, ErrActorNotFound, err)
+
+	testRepo.EXPECT().SetActorNames(gomock.Any(), actorID, items).Return(true, nil)
+	err = testUseCase.SetActorNames(context.Background(), actorID, items)
+	assert.Equal(t, nil, err)
+}
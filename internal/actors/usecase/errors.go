@@ -1,7 +1,24 @@
 package usecase
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrActorNotFound = errors.New("no actors with such ID")
+	ErrActorNotFound  = errors.New("no actors with such ID")
+	ErrActorDuplicate = errors.New("actor with such data already exists")
+	ErrSameActorMerge = errors.New("actor can not be merged with itself")
 )
+
+type DuplicateActorError struct {
+	CandidateIDs []uint64
+}
+
+func (e *DuplicateActorError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrActorDuplicate, e.CandidateIDs)
+}
+
+func (e *DuplicateActorError) Unwrap() error {
+	return ErrActorDuplicate
+}
@@ -5,6 +5,7 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -36,74 +37,133 @@ func (m *MockActorUseCase) EXPECT() *MockActorUseCaseMockRecorder {
 }
 
 // AddActor mocks base method.
-func (m *MockActorUseCase) AddActor(actor entity.Actor) (*entity.Actor, error) {
+func (m *MockActorUseCase) AddActor(ctx context.Context, actor entity.Actor, force bool) (*entity.Actor, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddActor", actor)
+	ret := m.ctrl.Call(m, "AddActor", ctx, actor, force)
 	ret0, _ := ret[0].(*entity.Actor)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddActor indicates an expected call of AddActor.
-func (mr *MockActorUseCaseMockRecorder) AddActor(actor interface{}) *gomock.Call {
+func (mr *MockActorUseCaseMockRecorder) AddActor(ctx, actor, force interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddActor", reflect.TypeOf((*MockActorUseCase)(nil).AddActor), actor)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddActor", reflect.TypeOf((*MockActorUseCase)(nil).AddActor), ctx, actor, force)
 }
 
 // DeleteActor mocks base method.
-func (m *MockActorUseCase) DeleteActor(ID uint64) error {
+func (m *MockActorUseCase) DeleteActor(ctx context.Context, ID uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteActor", ID)
+	ret := m.ctrl.Call(m, "DeleteActor", ctx, ID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteActor indicates an expected call of DeleteActor.
-func (mr *MockActorUseCaseMockRecorder) DeleteActor(ID interface{}) *gomock.Call {
+func (mr *MockActorUseCaseMockRecorder) DeleteActor(ctx, ID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteActor", reflect.TypeOf((*MockActorUseCase)(nil).DeleteActor), ID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteActor", reflect.TypeOf((*MockActorUseCase)(nil).DeleteActor), ctx, ID)
 }
 
 // GetActorByID mocks base method.
-func (m *MockActorUseCase) GetActorByID(actorID uint64) (*dto.ActorWithFilms, error) {
+func (m *MockActorUseCase) GetActorByID(ctx context.Context, actorID uint64, langs []string) (*dto.ActorWithFilms, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetActorByID", actorID)
+	ret := m.ctrl.Call(m, "GetActorByID", ctx, actorID, langs)
 	ret0, _ := ret[0].(*dto.ActorWithFilms)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetActorByID indicates an expected call of GetActorByID.
-func (mr *MockActorUseCaseMockRecorder) GetActorByID(actorID interface{}) *gomock.Call {
+func (mr *MockActorUseCaseMockRecorder) GetActorByID(ctx, actorID, langs interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActorByID", reflect.TypeOf((*MockActorUseCase)(nil).GetActorByID), actorID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActorByID", reflect.TypeOf((*MockActorUseCase)(nil).GetActorByID), ctx, actorID, langs)
+}
+
+// GetActorDuplicates mocks base method.
+func (m *MockActorUseCase) GetActorDuplicates(ctx context.Context) ([]dto.DuplicatePair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActorDuplicates", ctx)
+	ret0, _ := ret[0].([]dto.DuplicatePair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActorDuplicates indicates an expected call of GetActorDuplicates.
+func (mr *MockActorUseCaseMockRecorder) GetActorDuplicates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActorDuplicates", reflect.TypeOf((*MockActorUseCase)(nil).GetActorDuplicates), ctx)
+}
+
+// GetActorStats mocks base method.
+func (m *MockActorUseCase) GetActorStats(ctx context.Context, actorID uint64) (*dto.ActorStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActorStats", ctx, actorID)
+	ret0, _ := ret[0].(*dto.ActorStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActorStats indicates an expected call of GetActorStats.
+func (mr *MockActorUseCaseMockRecorder) GetActorStats(ctx, actorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActorStats", reflect.TypeOf((*MockActorUseCase)(nil).GetActorStats), ctx, actorID)
 }
 
 // GetActors mocks base method.
-func (m *MockActorUseCase) GetActors() ([]dto.ActorWithFilms, error) {
+func (m *MockActorUseCase) GetActors(ctx context.Context, langs []string) ([]dto.ActorWithFilms, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetActors")
+	ret := m.ctrl.Call(m, "GetActors", ctx, langs)
 	ret0, _ := ret[0].([]dto.ActorWithFilms)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetActors indicates an expected call of GetActors.
-func (mr *MockActorUseCaseMockRecorder) GetActors() *gomock.Call {
+func (mr *MockActorUseCaseMockRecorder) GetActors(ctx, langs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActors", reflect.TypeOf((*MockActorUseCase)(nil).GetActors), ctx, langs)
+}
+
+// MergeActors mocks base method.
+func (m *MockActorUseCase) MergeActors(ctx context.Context, sourceID, targetID uint64) (*dto.ActorWithFilms, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeActors", ctx, sourceID, targetID)
+	ret0, _ := ret[0].(*dto.ActorWithFilms)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeActors indicates an expected call of MergeActors.
+func (mr *MockActorUseCaseMockRecorder) MergeActors(ctx, sourceID, targetID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeActors", reflect.TypeOf((*MockActorUseCase)(nil).MergeActors), ctx, sourceID, targetID)
+}
+
+// SetActorNames mocks base method.
+func (m *MockActorUseCase) SetActorNames(ctx context.Context, actorID uint64, names []entity.ActorName) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetActorNames", ctx, actorID, names)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetActorNames indicates an expected call of SetActorNames.
+func (mr *MockActorUseCaseMockRecorder) SetActorNames(ctx, actorID, names interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActors", reflect.TypeOf((*MockActorUseCase)(nil).GetActors))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActorNames", reflect.TypeOf((*MockActorUseCase)(nil).SetActorNames), ctx, actorID, names)
 }
 
 // UpdateActor mocks base method.
-func (m *MockActorUseCase) UpdateActor(actor entity.Actor) error {
+func (m *MockActorUseCase) UpdateActor(ctx context.Context, actor entity.Actor) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateActor", actor)
+	ret := m.ctrl.Call(m, "UpdateActor", ctx, actor)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateActor indicates an expected call of UpdateActor.
-func (mr *MockActorUseCaseMockRecorder) UpdateActor(actor interface{}) *gomock.Call {
+func (mr *MockActorUseCaseMockRecorder) UpdateActor(ctx, actor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateActor", reflect.TypeOf((*MockActorUseCase)(nil).UpdateActor), actor)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateActor", reflect.TypeOf((*MockActorUseCase)(nil).UpdateActor), ctx, actor)
 }
@@ -0,0 +1,371 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/apikeys/usecase"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+	"go.uber.org/zap"
+)
+
+type APIKeyHandler struct {
+	apiKeyUseCase usecase.APIKeyUseCase
+}
+
+func NewAPIKeyHandler(apiKeyUseCase usecase.APIKeyUseCase) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUseCase: apiKeyUseCase,
+	}
+}
+
+// GetMyAPIKeys @Summary Получить свои API ключи
+// @Description Получить список API ключей текущего пользователя. Секреты ключей не возвращаются
+// @Tags api keys
+// @Produce json
+// @Security CookieAuth
+// @Success 200 {array} entity.APIKey
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "API ключами нельзя управлять при аутентификации API ключом"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/me/api-keys [get]
+func (h *APIKeyHandler) GetMyAPIKeys(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(zapLogger, w, r, false)
+	if err != nil {
+		return
+	}
+	h.writeAPIKeys(r.Context(), zapLogger, w, userID)
+}
+
+// CreateMyAPIKey @Summary Создать API ключ
+// @Description Создать именованный API ключ для машинного клиента. Секрет ключа возвращается только в этом ответе, в дальнейшем его нужно передавать в заголовке "Authorization: ApiKey <секрет>". Область действия (scopes) может только сужать разрешения роли пользователя
+// @Tags api keys
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param body body dto.APIKeyCreate true "Параметры ключа"
+// @Success 200 {object} entity.CreatedAPIKey
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "API ключами нельзя управлять при аутентификации API ключом"
+// @Failure 422 {object} string "Данные не прошли валидацию"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/me/api-keys [post]
+func (h *APIKeyHandler) CreateMyAPIKey(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(zapLogger, w, r, false)
+	if err != nil {
+		return
+	}
+	h.createAPIKey(zapLogger, w, r, userID)
+}
+
+// DeleteMyAPIKey @Summary Отозвать API ключ
+// @Description Удалить API ключ текущего пользователя
+// @Tags api keys
+// @Produce json
+// @Security CookieAuth
+// @Param KEY_ID path int true "ID ключа"
+// @Success 200 {object} string "Ключ отозван"
+// @Failure 400 {object} string "Неверный формат ID ключа"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "API ключами нельзя управлять при аутентификации API ключом"
+// @Failure 404 {object} string "Ключ не найден"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/me/api-keys/{KEY_ID} [delete]
+func (h *APIKeyHandler) DeleteMyAPIKey(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(zapLogger, w, r, false)
+	if err != nil {
+		return
+	}
+	h.deleteAPIKey(zapLogger, w, r, userID)
+}
+
+// GetUserAPIKeys @Summary Получить API ключи пользователя
+// @Description Получить список API ключей пользователя, например сервисного аккаунта
+// @Tags admin
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param USER_ID path int true "ID пользователя"
+// @Success 200 {array} entity.APIKey
+// @Failure 400 {object} string "Неверный формат ID пользователя"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/users/{USER_ID}/api-keys [get]
+func (h *APIKeyHandler) GetUserAPIKeys(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := getIDFromPath(zapLogger, w, r, "USER_ID")
+	if err != nil {
+		return
+	}
+	h.writeAPIKeys(r.Context(), zapLogger, w, userID)
+}
+
+// CreateUserAPIKey @Summary Создать API ключ пользователю
+// @Description Создать API ключ для пользователя, например сервисного аккаунта. Секрет ключа возвращается только в этом ответе
+// @Tags admin
+// @Accept json
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param USER_ID path int true "ID пользователя"
+// @Param body body dto.APIKeyCreate true "Параметры ключа"
+// @Success 200 {object} entity.CreatedAPIKey
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 404 {object} string "Пользователь не найден"
+// @Failure 422 {object} string "Данные не прошли валидацию"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/users/{USER_ID}/api-keys [post]
+func (h *APIKeyHandler) CreateUserAPIKey(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := getIDFromPath(zapLogger, w, r, "USER_ID")
+	if err != nil {
+		return
+	}
+	h.createAPIKey(zapLogger, w, r, userID)
+}
+
+// DeleteUserAPIKey @Summary Отозвать API ключ пользователя
+// @Description Удалить API ключ пользователя
+// @Tags admin
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param USER_ID path int true "ID пользователя"
+// @Param KEY_ID path int true "ID ключа"
+// @Success 200 {object} string "Ключ отозван"
+// @Failure 400 {object} string "Неверный формат ID"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 404 {object} string "Ключ не найден"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/users/{USER_ID}/api-keys/{KEY_ID} [delete]
+func (h *APIKeyHandler) DeleteUserAPIKey(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := getIDFromPath(zapLogger, w, r, "USER_ID")
+	if err != nil {
+		return
+	}
+	h.deleteAPIKey(zapLogger, w, r, userID)
+}
+
+func (h *APIKeyHandler) writeAPIKeys(ctx context.Context, zapLogger *zap.SugaredLogger, w http.ResponseWriter, userID uint64) {
+	apiKeys, err := h.apiKeyUseCase.GetUserAPIKeys(ctx, userID)
+	if err != nil {
+		zapLogger.Errorf("error in getting api keys: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	apiKeysJSON, err := json.Marshal(apiKeys)
+	if err != nil {
+		zapLogger.Errorf("error marshalling response: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, apiKeysJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+func (h *APIKeyHandler) createAPIKey(zapLogger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request, userID uint64) {
+	rBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		zapLogger.Errorf("error in reading request body: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in reading request body: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	apiKeyCreate := &dto.APIKeyCreate{}
+	err = json.Unmarshal(rBody, apiKeyCreate)
+	if err != nil {
+		zapLogger.Errorf("error in unmarshalling api key: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in decoding api key: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	validationErrors := apiKeyCreate.Validate()
+	if len(validationErrors) != 0 {
+		zapLogger.Errorf("api key did not pass validation: %v", validationErrors)
+		errorsJSON, err := json.Marshal(validationErrors)
+		if err != nil {
+			zapLogger.Errorf("error in marshalling validation errors: %s", err)
+			errText := `{"error": "internal server error"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if err != nil {
+				zapLogger.Errorf("error in writing response: %s", err)
+			}
+			return
+		}
+		err = response.WriteResponse(w, errorsJSON, http.StatusUnprocessableEntity)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	var createdKey *entity.CreatedAPIKey
+	createdKey, err = h.apiKeyUseCase.CreateAPIKey(r.Context(), userID, apiKeyCreate.Name, apiKeyCreate.Scopes, apiKeyCreate.ExpiresAt)
+	if errors.Is(err, usecase.ErrNoUser) {
+		zapLogger.Errorf("user with id %d was not found", userID)
+		errText := fmt.Sprintf(`{"error": "user with id %d is not found"}`, userID)
+		err = response.WriteResponse(w, []byte(errText), http.StatusNotFound)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if errors.Is(err, usecase.ErrBadScope) || errors.Is(err, usecase.ErrBadExpires) {
+		zapLogger.Errorf("api key was not created: %s", err)
+		errText := fmt.Sprintf(`["%s"]`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusUnprocessableEntity)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		zapLogger.Errorf("error in creating api key: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	createdKeyJSON, err := json.Marshal(createdKey)
+	if err != nil {
+		zapLogger.Errorf("error marshalling response: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, createdKeyJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+func (h *APIKeyHandler) deleteAPIKey(zapLogger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request, userID uint64) {
+	keyID, err := getIDFromPath(zapLogger, w, r, "KEY_ID")
+	if err != nil {
+		return
+	}
+	isDeleted, err := h.apiKeyUseCase.DeleteAPIKey(r.Context(), userID, keyID)
+	if err != nil {
+		zapLogger.Errorf("error in deleting api key: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if !isDeleted {
+		zapLogger.Errorf("api key %d of user %d is not found", keyID, userID)
+		errText := fmt.Sprintf(`{"error": "api key with id %d is not found"}`, keyID)
+		err = response.WriteResponse(w, []byte(errText), http.StatusNotFound)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	result := `{"result": "success"}`
+	err = response.WriteResponse(w, []byte(result), http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+func getIDFromPath(zapLogger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request, name string) (uint64, error) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars[name], 10, 64)
+	if err != nil {
+		zapLogger.Errorf("error in %s conversion: %s", name, err)
+		errText := fmt.Sprintf(`{"error": "bad format of id: %s"}`, err)
+		writeErr := response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if writeErr != nil {
+			zapLogger.Errorf("error in writing response: %s", writeErr)
+		}
+		return 0, err
+	}
+	return id, nil
+}
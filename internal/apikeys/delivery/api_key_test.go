@@ -0,0 +1,221 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/apikeys/usecase"
+	"github.com/ilyushkaaa/Filmoteka/internal/apikeys/usecase/mock"
+	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
+	logger2 "github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func TestGetMyAPIKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockAPIKeyUseCase(ctrl)
+	testHandler := NewAPIKeyHandler(testUseCase)
+
+	testCases := []struct {
+		ctx        func(ctx context.Context) context.Context
+		setup      func()
+		statusCode int
+	}{
+		{
+			ctx:        func(ctx context.Context) context.Context { return ctx },
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			ctx: func(ctx context.Context) context.Context {
+				ctx = context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+				return context.WithValue(ctx, middleware.MyAPIKeyKey, &entity.APIKey{ID: 1, UserID: 1})
+			},
+			statusCode: http.StatusForbidden,
+		},
+		{
+			ctx: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+			},
+			setup: func() {
+				testUseCase.EXPECT().GetUserAPIKeys(gomock.Any(), uint64(1)).Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			ctx: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+			},
+			setup: func() {
+				testUseCase.EXPECT().GetUserAPIKeys(gomock.Any(), uint64(1)).Return([]entity.APIKey{{ID: 1, UserID: 1}}, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodGet, "/me/api-keys", nil)
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.GetMyAPIKeys(respWriter, request.WithContext(tc.ctx(ctx)))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestCreateMyAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockAPIKeyUseCase(ctrl)
+	testHandler := NewAPIKeyHandler(testUseCase)
+
+	request := httptest.NewRequest(http.MethodPost, "/me/api-keys", nil)
+	respWriter := httptest.NewRecorder()
+	testHandler.CreateMyAPIKey(respWriter, request)
+	resp := respWriter.Result()
+	err := resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	testCases := []struct {
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{body: `{"`, statusCode: http.StatusBadRequest},
+		{body: `{}`, statusCode: http.StatusUnprocessableEntity},
+		{body: `{"name": "import", "scopes": ["everything"]}`, statusCode: http.StatusUnprocessableEntity},
+		{
+			body: `{"name": "import", "scopes": ["user:manage"]}`,
+			setup: func() {
+				testUseCase.EXPECT().CreateAPIKey(gomock.Any(), uint64(1), "import", []string{"user:manage"}, nil).
+					Return(nil, usecase.ErrBadScope)
+			},
+			statusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			body: `{"name": "import"}`,
+			setup: func() {
+				testUseCase.EXPECT().CreateAPIKey(gomock.Any(), uint64(1), "import", nil, nil).Return(nil, usecase.ErrNoUser)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			body: `{"name": "import"}`,
+			setup: func() {
+				testUseCase.EXPECT().CreateAPIKey(gomock.Any(), uint64(1), "import", nil, nil).Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			body: `{"name": "import", "expires_at": "2030-01-01T00:00:00Z"}`,
+			setup: func() {
+				testUseCase.EXPECT().CreateAPIKey(gomock.Any(), uint64(1), "import", nil, gomock.Not(nil)).
+					Return(&entity.CreatedAPIKey{APIKey: entity.APIKey{ID: 1, UserID: 1}, Secret: "fmk_secret"}, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request = httptest.NewRequest(http.MethodPost, "/me/api-keys", strings.NewReader(tc.body))
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		ctx = context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+		respWriter = httptest.NewRecorder()
+		testHandler.CreateMyAPIKey(respWriter, request.WithContext(ctx))
+		resp = respWriter.Result()
+		err = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestDeleteUserAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockAPIKeyUseCase(ctrl)
+	testHandler := NewAPIKeyHandler(testUseCase)
+
+	testCases := []struct {
+		userID     string
+		keyID      string
+		setup      func()
+		statusCode int
+	}{
+		{userID: "a", keyID: "1", statusCode: http.StatusBadRequest},
+		{userID: "2", keyID: "a", statusCode: http.StatusBadRequest},
+		{
+			userID: "2",
+			keyID:  "1",
+			setup: func() {
+				testUseCase.EXPECT().DeleteAPIKey(gomock.Any(), uint64(2), uint64(1)).Return(false, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			userID: "2",
+			keyID:  "1",
+			setup: func() {
+				testUseCase.EXPECT().DeleteAPIKey(gomock.Any(), uint64(2), uint64(1)).Return(false, nil)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			userID: "2",
+			keyID:  "1",
+			setup: func() {
+				testUseCase.EXPECT().DeleteAPIKey(gomock.Any(), uint64(2), uint64(1)).Return(true, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodDelete, "/admin/users/api-keys", nil)
+		request = mux.SetURLVars(request, map[string]string{"USER_ID": tc.userID, "KEY_ID": tc.keyID})
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.DeleteUserAPIKey(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+type APIKey struct {
+	ID         uint64
+	UserID     uint64
+	Name       string
+	Prefix     string
+	Scopes     []string
+	ExpiresAt  *time.Time
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+type CreatedAPIKey struct {
+	APIKey
+	Secret string
+}
+
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !k.ExpiresAt.After(now)
+}
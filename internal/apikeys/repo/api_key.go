@@ -0,0 +1,118 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+)
+
+const scopesSeparator = " "
+
+//go:generate mockgen -source=api_key.go -destination=api_key_mock.go -package=repo APIKeyRepo
+type APIKeyRepo interface {
+	AddAPIKey(ctx context.Context, apiKey *entity.APIKey, keyHash string) (*entity.APIKey, error)
+	GetUserAPIKeys(ctx context.Context, userID uint64) ([]entity.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	DeleteAPIKey(ctx context.Context, userID, keyID uint64) (bool, error)
+	TouchAPIKey(ctx context.Context, keyID uint64) error
+}
+
+type APIKeyRepoPG struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepo(db *sql.DB) *APIKeyRepoPG {
+	return &APIKeyRepoPG{
+		db: db,
+	}
+}
+
+func (a *APIKeyRepoPG) AddAPIKey(ctx context.Context, apiKey *entity.APIKey, keyHash string) (*entity.APIKey, error) {
+	addedKey := *apiKey
+	err := a.db.
+		QueryRowContext(ctx, `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+			apiKey.UserID, apiKey.Name, apiKey.Prefix, keyHash, strings.Join(apiKey.Scopes, scopesSeparator), apiKey.ExpiresAt).
+		Scan(&addedKey.ID, &addedKey.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &addedKey, nil
+}
+
+func (a *APIKeyRepoPG) GetUserAPIKeys(ctx context.Context, userID uint64) ([]entity.APIKey, error) {
+	rows, err := a.db.QueryContext(ctx, `
+        SELECT id, user_id, name, prefix, scopes, expires_at, created_at, last_used_at
+        FROM api_keys
+        WHERE user_id = $1
+        ORDER BY id
+    `, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	apiKeys := make([]entity.APIKey, 0)
+	for rows.Next() {
+		apiKey, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, *apiKey)
+	}
+	return apiKeys, rows.Err()
+}
+
+// GetAPIKeyByHash не возвращает ключи заблокированных пользователей
+func (a *APIKeyRepoPG) GetAPIKeyByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	apiKey, err := scanAPIKey(a.db.QueryRowContext(ctx, `
+        SELECT k.id, k.user_id, k.name, k.prefix, k.scopes, k.expires_at, k.created_at, k.last_used_at
+        FROM api_keys k
+            JOIN users u ON u.id = k.user_id
+        WHERE k.key_hash = $1 AND NOT u.is_disabled
+    `, keyHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
+
+func (a *APIKeyRepoPG) DeleteAPIKey(ctx context.Context, userID, keyID uint64) (bool, error) {
+	result, err := a.db.ExecContext(ctx, "DELETE FROM api_keys WHERE id = $1 AND user_id = $2", keyID, userID)
+	if err != nil {
+		return false, err
+	}
+	rowsDeleted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsDeleted > 0, nil
+}
+
+// TouchAPIKey обновляет время последнего использования не чаще раза в минуту
+func (a *APIKeyRepoPG) TouchAPIKey(ctx context.Context, keyID uint64) error {
+	_, err := a.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = now()
+        WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - INTERVAL '1 minute')`, keyID)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row rowScanner) (*entity.APIKey, error) {
+	apiKey := &entity.APIKey{}
+	var scopes string
+	err := row.Scan(&apiKey.ID, &apiKey.UserID, &apiKey.Name, &apiKey.Prefix, &scopes,
+		&apiKey.ExpiresAt, &apiKey.CreatedAt, &apiKey.LastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.Scopes = strings.Fields(scopes)
+	return apiKey, nil
+}
@@ -0,0 +1,157 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var apiKeyColumns = []string{"id", "user_id", "name", "prefix", "scopes", "expires_at", "created_at", "last_used_at"}
+
+func TestAddAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepo(db)
+	createdAt := time.Now()
+	apiKey := &entity.APIKey{UserID: 1, Name: "import", Prefix: "fmk_abcdefgh", Scopes: []string{"film:write", "actor:write"}}
+
+	mock.ExpectQuery("INSERT INTO api_keys (.+) RETURNING id, created_at").
+		WithArgs(uint64(1), "import", "fmk_abcdefgh", "hash", "film:write actor:write", nil).
+		WillReturnError(fmt.Errorf("error"))
+	addedKey, err := repo.AddAPIKey(context.Background(), apiKey, "hash")
+	assert.Error(t, err)
+	assert.Nil(t, addedKey)
+
+	mock.ExpectQuery("INSERT INTO api_keys (.+) RETURNING id, created_at").
+		WithArgs(uint64(1), "import", "fmk_abcdefgh", "hash", "film:write actor:write", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, createdAt))
+	addedKey, err = repo.AddAPIKey(context.Background(), apiKey, "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), addedKey.ID)
+	assert.Equal(t, createdAt, addedKey.CreatedAt)
+	assert.Equal(t, uint64(0), apiKey.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserAPIKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepo(db)
+	createdAt := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE user_id = (.+)").
+		WithArgs(uint64(1)).
+		WillReturnError(fmt.Errorf("error"))
+	apiKeys, err := repo.GetUserAPIKeys(context.Background(), 1)
+	assert.Error(t, err)
+	assert.Nil(t, apiKeys)
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE user_id = (.+)").
+		WithArgs(uint64(1)).
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+			AddRow(1, 1, "import", "fmk_abcdefgh", "", nil, createdAt, nil).
+			AddRow(2, 1, "stats", "fmk_ijklmnop", "stats:read", nil, createdAt, createdAt))
+	apiKeys, err = repo.GetUserAPIKeys(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, apiKeys, 2)
+	assert.Empty(t, apiKeys[0].Scopes)
+	assert.Nil(t, apiKeys[0].LastUsedAt)
+	assert.Equal(t, []string{"stats:read"}, apiKeys[1].Scopes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAPIKeyByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepo(db)
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys k JOIN users u (.+) WHERE k.key_hash = (.+) AND NOT u.is_disabled").
+		WithArgs("hash").
+		WillReturnError(sql.ErrNoRows)
+	apiKey, err := repo.GetAPIKeyByHash(context.Background(), "hash")
+	assert.NoError(t, err)
+	assert.Nil(t, apiKey)
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys k JOIN users u (.+)").
+		WithArgs("hash").
+		WillReturnError(fmt.Errorf("error"))
+	apiKey, err = repo.GetAPIKeyByHash(context.Background(), "hash")
+	assert.Error(t, err)
+	assert.Nil(t, apiKey)
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys k JOIN users u (.+)").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+			AddRow(1, 2, "import", "fmk_abcdefgh", "film:write", nil, time.Now(), nil))
+	apiKey, err = repo.GetAPIKeyByHash(context.Background(), "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), apiKey.UserID)
+	assert.Equal(t, []string{"film:write"}, apiKey.Scopes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepo(db)
+
+	mock.ExpectExec("DELETE FROM api_keys WHERE id = (.+) AND user_id = (.+)").
+		WithArgs(uint64(3), uint64(1)).
+		WillReturnError(fmt.Errorf("error"))
+	isDeleted, err := repo.DeleteAPIKey(context.Background(), 1, 3)
+	assert.Error(t, err)
+	assert.False(t, isDeleted)
+
+	mock.ExpectExec("DELETE FROM api_keys WHERE id = (.+) AND user_id = (.+)").
+		WithArgs(uint64(3), uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	isDeleted, err = repo.DeleteAPIKey(context.Background(), 1, 3)
+	assert.NoError(t, err)
+	assert.False(t, isDeleted)
+
+	mock.ExpectExec("DELETE FROM api_keys WHERE id = (.+) AND user_id = (.+)").
+		WithArgs(uint64(3), uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	isDeleted, err = repo.DeleteAPIKey(context.Background(), 1, 3)
+	assert.NoError(t, err)
+	assert.True(t, isDeleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTouchAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepo(db)
+
+	mock.ExpectExec("UPDATE api_keys SET last_used_at = now()").
+		WithArgs(uint64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.TouchAPIKey(context.Background(), 3))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,151 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: api_key.go
+
+// Package repo is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+)
+
+// MockAPIKeyRepo is a mock of APIKeyRepo interface.
+type MockAPIKeyRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyRepoMockRecorder
+}
+
+// MockAPIKeyRepoMockRecorder is the mock recorder for MockAPIKeyRepo.
+type MockAPIKeyRepoMockRecorder struct {
+	mock *MockAPIKeyRepo
+}
+
+// NewMockAPIKeyRepo creates a new mock instance.
+func NewMockAPIKeyRepo(ctrl *gomock.Controller) *MockAPIKeyRepo {
+	mock := &MockAPIKeyRepo{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyRepo) EXPECT() *MockAPIKeyRepoMockRecorder {
+	return m.recorder
+}
+
+// AddAPIKey mocks base method.
+func (m *MockAPIKeyRepo) AddAPIKey(ctx context.Context, apiKey *entity.APIKey, keyHash string) (*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAPIKey", ctx, apiKey, keyHash)
+	ret0, _ := ret[0].(*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAPIKey indicates an expected call of AddAPIKey.
+func (mr *MockAPIKeyRepoMockRecorder) AddAPIKey(ctx, apiKey, keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAPIKey", reflect.TypeOf((*MockAPIKeyRepo)(nil).AddAPIKey), ctx, apiKey, keyHash)
+}
+
+// DeleteAPIKey mocks base method.
+func (m *MockAPIKeyRepo) DeleteAPIKey(ctx context.Context, userID, keyID uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", ctx, userID, keyID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey.
+func (mr *MockAPIKeyRepoMockRecorder) DeleteAPIKey(ctx, userID, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockAPIKeyRepo)(nil).DeleteAPIKey), ctx, userID, keyID)
+}
+
+// GetAPIKeyByHash mocks base method.
+func (m *MockAPIKeyRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", ctx, keyHash)
+	ret0, _ := ret[0].(*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockAPIKeyRepoMockRecorder) GetAPIKeyByHash(ctx, keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockAPIKeyRepo)(nil).GetAPIKeyByHash), ctx, keyHash)
+}
+
+// GetUserAPIKeys mocks base method.
+func (m *MockAPIKeyRepo) GetUserAPIKeys(ctx context.Context, userID uint64) ([]entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAPIKeys", ctx, userID)
+	ret0, _ := ret[0].([]entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAPIKeys indicates an expected call of GetUserAPIKeys.
+func (mr *MockAPIKeyRepoMockRecorder) GetUserAPIKeys(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAPIKeys", reflect.TypeOf((*MockAPIKeyRepo)(nil).GetUserAPIKeys), ctx, userID)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockAPIKeyRepo) TouchAPIKey(ctx context.Context, keyID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", ctx, keyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockAPIKeyRepoMockRecorder) TouchAPIKey(ctx, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockAPIKeyRepo)(nil).TouchAPIKey), ctx, keyID)
+}
+
+// MockrowScanner is a mock of rowScanner interface.
+type MockrowScanner struct {
+	ctrl     *gomock.Controller
+	recorder *MockrowScannerMockRecorder
+}
+
+// MockrowScannerMockRecorder is the mock recorder for MockrowScanner.
+type MockrowScannerMockRecorder struct {
+	mock *MockrowScanner
+}
+
+// NewMockrowScanner creates a new mock instance.
+func NewMockrowScanner(ctrl *gomock.Controller) *MockrowScanner {
+	mock := &MockrowScanner{ctrl: ctrl}
+	mock.recorder = &MockrowScannerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrowScanner) EXPECT() *MockrowScannerMockRecorder {
+	return m.recorder
+}
+
+// Scan mocks base method.
+func (m *MockrowScanner) Scan(dest ...interface{}) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range dest {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Scan", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockrowScannerMockRecorder) Scan(dest ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockrowScanner)(nil).Scan), dest...)
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/apikeys/repo"
+	userRepo "github.com/ilyushkaaa/Filmoteka/internal/users/repo"
+)
+
+const (
+	apiKeySecretPrefix = "fmk_"
+	apiKeySecretBytes  = 32
+	apiKeyPrefixLength = len(apiKeySecretPrefix) + 8
+)
+
+//go:generate mockgen -source=api_key.go -destination=api_key_mock.go -package=usecase APIKeyUseCase
+type APIKeyUseCase interface {
+	CreateAPIKey(ctx context.Context, userID uint64, name string, scopes []string, expiresAt *time.Time) (*entity.CreatedAPIKey, error)
+	GetUserAPIKeys(ctx context.Context, userID uint64) ([]entity.APIKey, error)
+	DeleteAPIKey(ctx context.Context, userID, keyID uint64) (bool, error)
+	Authenticate(ctx context.Context, secret string) (*entity.APIKey, error)
+}
+
+type APIKeyUseCaseApp struct {
+	apiKeyRepo repo.APIKeyRepo
+	userRepo   userRepo.UserRepo
+}
+
+func NewAPIKeyUseCase(apiKeyRepo repo.APIKeyRepo, userRepo userRepo.UserRepo) *APIKeyUseCaseApp {
+	return &APIKeyUseCaseApp{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// CreateAPIKey - пустой scopes означает все разрешения роли
+func (au *APIKeyUseCaseApp) CreateAPIKey(ctx context.Context, userID uint64, name string, scopes []string, expiresAt *time.Time) (*entity.CreatedAPIKey, error) {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, ErrBadExpires
+	}
+	role, err := au.userRepo.GetUserRole(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrNoUser
+	}
+	if len(scopes) != 0 {
+		err = au.checkScopes(ctx, role, scopes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	secretRaw := make([]byte, apiKeySecretBytes)
+	_, err = rand.Read(secretRaw)
+	if err != nil {
+		return nil, err
+	}
+	secret := apiKeySecretPrefix + base64.RawURLEncoding.EncodeToString(secretRaw)
+	apiKey := &entity.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    secret[:apiKeyPrefixLength],
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	addedKey, err := au.apiKeyRepo.AddAPIKey(ctx, apiKey, hashAPIKey(secret))
+	if err != nil {
+		return nil, err
+	}
+	return &entity.CreatedAPIKey{
+		APIKey: *addedKey,
+		Secret: secret,
+	}, nil
+}
+
+func (au *APIKeyUseCaseApp) GetUserAPIKeys(ctx context.Context, userID uint64) ([]entity.APIKey, error) {
+	return au.apiKeyRepo.GetUserAPIKeys(ctx, userID)
+}
+
+func (au *APIKeyUseCaseApp) DeleteAPIKey(ctx context.Context, userID, keyID uint64) (bool, error) {
+	return au.apiKeyRepo.DeleteAPIKey(ctx, userID, keyID)
+}
+
+func (au *APIKeyUseCaseApp) Authenticate(ctx context.Context, secret string) (*entity.APIKey, error) {
+	if !strings.HasPrefix(secret, apiKeySecretPrefix) {
+		return nil, ErrBadAPIKey
+	}
+	apiKey, err := au.apiKeyRepo.GetAPIKeyByHash(ctx, hashAPIKey(secret))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil || apiKey.IsExpired(time.Now()) {
+		return nil, ErrBadAPIKey
+	}
+	err = au.apiKeyRepo.TouchAPIKey(ctx, apiKey.ID)
+	if err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
+
+func (au *APIKeyUseCaseApp) checkScopes(ctx context.Context, role string, scopes []string) error {
+	permissions, err := au.userRepo.GetRolePermissions(ctx, role)
+	if err != nil {
+		return err
+	}
+	granted := make(map[string]struct{}, len(permissions))
+	for _, permission := range permissions {
+		granted[permission] = struct{}{}
+	}
+	for _, scope := range scopes {
+		if _, ok := granted[scope]; !ok {
+			return ErrBadScope
+		}
+	}
+	return nil
+}
+
+// hashAPIKey - секрет случаен и длинен, поэтому соль не нужна
+func hashAPIKey(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
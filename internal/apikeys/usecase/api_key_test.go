@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/apikeys/repo/mock"
+	userEntity "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	userMock "github.com/ilyushkaaa/Filmoteka/internal/users/repo/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockAPIKeyRepo(ctrl)
+	testUserRepo := userMock.NewMockUserRepo(ctrl)
+	testUseCase := NewAPIKeyUseCase(testRepo, testUserRepo)
+
+	past := time.Now().Add(-time.Hour)
+	createdKey, err := testUseCase.CreateAPIKey(context.Background(), 1, "import", nil, &past)
+	assert.Equal(t, ErrBadExpires, err)
+	assert.Nil(t, createdKey)
+
+	testUserRepo.EXPECT().GetUserRole(gomock.Any(), uint64(1)).Return("", fmt.Errorf("error"))
+	createdKey, err = testUseCase.CreateAPIKey(context.Background(), 1, "import", nil, nil)
+	assert.Error(t, err)
+	assert.Nil(t, createdKey)
+
+	testUserRepo.EXPECT().GetUserRole(gomock.Any(), uint64(1)).Return("", nil)
+	createdKey, err = testUseCase.CreateAPIKey(context.Background(), 1, "import", nil, nil)
+	assert.Equal(t, ErrNoUser, err)
+	assert.Nil(t, createdKey)
+
+	testUserRepo.EXPECT().GetUserRole(gomock.Any(), uint64(1)).Return(userEntity.RoleEditor, nil).Times(3)
+	testUserRepo.EXPECT().GetRolePermissions(gomock.Any(), userEntity.RoleEditor).
+		Return([]string{userEntity.PermissionFilmWrite, userEntity.PermissionActorWrite}, nil).Times(2)
+
+	createdKey, err = testUseCase.CreateAPIKey(context.Background(), 1, "import", []string{userEntity.PermissionUserManage}, nil)
+	assert.Equal(t, ErrBadScope, err)
+	assert.Nil(t, createdKey)
+
+	testRepo.EXPECT().AddAPIKey(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("error"))
+	createdKey, err = testUseCase.CreateAPIKey(context.Background(), 1, "import", []string{userEntity.PermissionFilmWrite}, nil)
+	assert.Error(t, err)
+	assert.Nil(t, createdKey)
+
+	var savedHash string
+	testRepo.EXPECT().AddAPIKey(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, apiKey *entity.APIKey, keyHash string) (*entity.APIKey, error) {
+			savedHash = keyHash
+			addedKey := *apiKey
+			addedKey.ID = 5
+			return &addedKey, nil
+		})
+	createdKey, err = testUseCase.CreateAPIKey(context.Background(), 1, "import", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), createdKey.ID)
+	assert.True(t, strings.HasPrefix(createdKey.Secret, createdKey.Prefix))
+	assert.Equal(t, hashAPIKey(createdKey.Secret), savedHash)
+	assert.NotEqual(t, createdKey.Secret, savedHash)
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockAPIKeyRepo(ctrl)
+	testUseCase := NewAPIKeyUseCase(testRepo, userMock.NewMockUserRepo(ctrl))
+	secret := apiKeySecretPrefix + "secret"
+
+	apiKey, err := testUseCase.Authenticate(context.Background(), "secret")
+	assert.Equal(t, ErrBadAPIKey, err)
+	assert.Nil(t, apiKey)
+
+	testRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), hashAPIKey(secret)).Return(nil, fmt.Errorf("error"))
+	apiKey, err = testUseCase.Authenticate(context.Background(), secret)
+	assert.Error(t, err)
+	assert.Nil(t, apiKey)
+
+	testRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), hashAPIKey(secret)).Return(nil, nil)
+	apiKey, err = testUseCase.Authenticate(context.Background(), secret)
+	assert.Equal(t, ErrBadAPIKey, err)
+	assert.Nil(t, apiKey)
+
+	past := time.Now().Add(-time.Minute)
+	testRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), hashAPIKey(secret)).Return(&entity.APIKey{ID: 1, ExpiresAt: &past}, nil)
+	apiKey, err = testUseCase.Authenticate(context.Background(), secret)
+	assert.Equal(t, ErrBadAPIKey, err)
+	assert.Nil(t, apiKey)
+
+	testRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), hashAPIKey(secret)).Return(&entity.APIKey{ID: 1, UserID: 2}, nil)
+	testRepo.EXPECT().TouchAPIKey(gomock.Any(), uint64(1)).Return(nil)
+	apiKey, err = testUseCase.Authenticate(context.Background(), secret)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), apiKey.UserID)
+}
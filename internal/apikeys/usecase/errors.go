@@ -0,0 +1,10 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrBadAPIKey  = errors.New("api key is invalid or expired")
+	ErrNoUser     = errors.New("user not exists")
+	ErrBadScope   = errors.New("api key scope is not granted to user")
+	ErrBadExpires = errors.New("api key expiration time must be in the future")
+)
@@ -0,0 +1,97 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: api_key.go
+
+// Package usecase is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+)
+
+// MockAPIKeyUseCase is a mock of APIKeyUseCase interface.
+type MockAPIKeyUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyUseCaseMockRecorder
+}
+
+// MockAPIKeyUseCaseMockRecorder is the mock recorder for MockAPIKeyUseCase.
+type MockAPIKeyUseCaseMockRecorder struct {
+	mock *MockAPIKeyUseCase
+}
+
+// NewMockAPIKeyUseCase creates a new mock instance.
+func NewMockAPIKeyUseCase(ctrl *gomock.Controller) *MockAPIKeyUseCase {
+	mock := &MockAPIKeyUseCase{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyUseCase) EXPECT() *MockAPIKeyUseCaseMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockAPIKeyUseCase) Authenticate(ctx context.Context, secret string) (*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, secret)
+	ret0, _ := ret[0].(*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAPIKeyUseCaseMockRecorder) Authenticate(ctx, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAPIKeyUseCase)(nil).Authenticate), ctx, secret)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAPIKeyUseCase) CreateAPIKey(ctx context.Context, userID uint64, name string, scopes []string, expiresAt *time.Time) (*entity.CreatedAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, userID, name, scopes, expiresAt)
+	ret0, _ := ret[0].(*entity.CreatedAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAPIKeyUseCaseMockRecorder) CreateAPIKey(ctx, userID, name, scopes, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAPIKeyUseCase)(nil).CreateAPIKey), ctx, userID, name, scopes, expiresAt)
+}
+
+// DeleteAPIKey mocks base method.
+func (m *MockAPIKeyUseCase) DeleteAPIKey(ctx context.Context, userID, keyID uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", ctx, userID, keyID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey.
+func (mr *MockAPIKeyUseCaseMockRecorder) DeleteAPIKey(ctx, userID, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockAPIKeyUseCase)(nil).DeleteAPIKey), ctx, userID, keyID)
+}
+
+// GetUserAPIKeys mocks base method.
+func (m *MockAPIKeyUseCase) GetUserAPIKeys(ctx context.Context, userID uint64) ([]entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAPIKeys", ctx, userID)
+	ret0, _ := ret[0].([]entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAPIKeys indicates an expected call of GetUserAPIKeys.
+func (mr *MockAPIKeyUseCaseMockRecorder) GetUserAPIKeys(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAPIKeys", reflect.TypeOf((*MockAPIKeyUseCase)(nil).GetUserAPIKeys), ctx, userID)
+}
@@ -1,11 +1,13 @@
 package dto
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/asaskevich/govalidator"
 	entityActor "github.com/ilyushkaaa/Filmoteka/internal/actors/entity"
 	entityFilm "github.com/ilyushkaaa/Filmoteka/internal/films/entity"
+	"github.com/ilyushkaaa/Filmoteka/pkg/locale"
 	"github.com/ilyushkaaa/Filmoteka/pkg/validator"
 )
 
@@ -14,46 +16,110 @@ type (
 		Actor entityActor.Actor
 		Films []entityFilm.Film
 	}
+	ActorStats struct {
+		ActorID          uint64           `json:"actor_id"`
+		Age              int              `json:"age"`
+		IsAlive          bool             `json:"is_alive"`
+		FilmsCount       int              `json:"films_count"`
+		AverageRating    *float64         `json:"average_rating"`
+		FilmsByDecade    map[int]int      `json:"films_by_decade"`
+		FirstAppearance  *FilmAppearance  `json:"first_appearance"`
+		LatestAppearance *FilmAppearance  `json:"latest_appearance"`
+		Appearances      []FilmAppearance `json:"appearances"`
+	}
+	FilmAppearance struct {
+		FilmID        uint64    `json:"film_id"`
+		Name          string    `json:"name"`
+		DateOfRelease time.Time `json:"date_of_release"`
+		AgeAtRelease  int       `json:"age_at_release"`
+	}
 	ActorAdd struct {
-		Name     string    `json:"name" valid:"required,length(1|40)"`
-		Surname  string    `json:"surname" valid:"required,length(1|40)"`
-		Gender   string    `json:"gender" valid:"required,in(male|female)"`
-		Birthday time.Time `json:"birthday" valid:"required"`
+		Name      string     `json:"name" valid:"required,length(1|40)"`
+		Surname   string     `json:"surname" valid:"required,length(1|40)"`
+		Gender    string     `json:"gender" valid:"required,in(male|female)"`
+		Birthday  time.Time  `json:"birthday" valid:"required"`
+		DeathDate *time.Time `json:"death_date" valid:"optional"`
 	}
 	ActorUpdate struct {
-		ID       uint64    `json:"id" valid:"required"`
-		Name     string    `json:"name" valid:"required,length(1|40)"`
-		Surname  string    `json:"surname" valid:"required,length(1|40)"`
-		Gender   string    `json:"gender" valid:"required,in(male|female)"`
-		Birthday time.Time `json:"birthday" valid:"required"`
+		ID        uint64     `json:"id" valid:"required"`
+		Name      string     `json:"name" valid:"required,length(1|40)"`
+		Surname   string     `json:"surname" valid:"required,length(1|40)"`
+		Gender    string     `json:"gender" valid:"required,in(male|female)"`
+		Birthday  time.Time  `json:"birthday" valid:"required"`
+		DeathDate *time.Time `json:"death_date" valid:"optional"`
+	}
+	ActorName struct {
+		Locale  string `json:"locale" valid:"required"`
+		Name    string `json:"name" valid:"required,length(1|40)"`
+		Surname string `json:"surname" valid:"required,length(1|40)"`
+	}
+	ActorNamesUpdate struct {
+		Names []ActorName `json:"names"`
 	}
 )
 
 func (a *ActorAdd) Validate() []string {
 	_, err := govalidator.ValidateStruct(a)
-	return validator.CollectErrors(err)
+	return append(validator.CollectErrors(err), validateLifeDates(a.Birthday, a.DeathDate)...)
 }
 
 func (a *ActorUpdate) Validate() []string {
 	_, err := govalidator.ValidateStruct(a)
-	return validator.CollectErrors(err)
+	return append(validator.CollectErrors(err), validateLifeDates(a.Birthday, a.DeathDate)...)
+}
+
+func validateLifeDates(birthday time.Time, deathDate *time.Time) []string {
+	if deathDate != nil && deathDate.Before(birthday) {
+		return []string{"death_date: death date can not be before birthday"}
+	}
+	return nil
 }
 
 func (a *ActorAdd) Convert() entityActor.Actor {
 	return entityActor.Actor{
-		Name:     a.Name,
-		Surname:  a.Surname,
-		Gender:   a.Gender,
-		Birthday: a.Birthday,
+		Name:      a.Name,
+		Surname:   a.Surname,
+		Gender:    a.Gender,
+		Birthday:  a.Birthday,
+		DeathDate: a.DeathDate,
 	}
 }
 
 func (a *ActorUpdate) Convert() entityActor.Actor {
 	return entityActor.Actor{
-		ID:       a.ID,
-		Name:     a.Name,
-		Surname:  a.Surname,
-		Gender:   a.Gender,
-		Birthday: a.Birthday,
+		ID:        a.ID,
+		Name:      a.Name,
+		Surname:   a.Surname,
+		Gender:    a.Gender,
+		Birthday:  a.Birthday,
+		DeathDate: a.DeathDate,
+	}
+}
+
+func (a *ActorNamesUpdate) Validate() []string {
+	_, err := govalidator.ValidateStruct(a)
+	validationErrors := validator.CollectErrors(err)
+	locales := make(map[string]struct{}, len(a.Names))
+	for _, name := range a.Names {
+		if name.Locale != "" && locale.Normalize(name.Locale) != name.Locale {
+			validationErrors = append(validationErrors, fmt.Sprintf("locale: %s is not a lowercase language code", name.Locale))
+		}
+		if _, ok := locales[name.Locale]; ok {
+			validationErrors = append(validationErrors, fmt.Sprintf("locale: name for %s is passed more than once", name.Locale))
+		}
+		locales[name.Locale] = struct{}{}
+	}
+	return validationErrors
+}
+
+func (a *ActorNamesUpdate) Convert() []entityActor.ActorName {
+	names := make([]entityActor.ActorName, len(a.Names))
+	for i, name := range a.Names {
+		names[i] = entityActor.ActorName{
+			Locale:  name.Locale,
+			Name:    name.Name,
+			Surname: name.Surname,
+		}
 	}
+	return names
 }
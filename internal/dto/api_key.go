@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/ilyushkaaa/Filmoteka/pkg/validator"
+)
+
+var permissionPattern = regexp.MustCompile(`^[a-z_]+:[a-z_]+$`)
+
+type APIKeyCreate struct {
+	Name      string     `json:"name" valid:"required,length(1|100)"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (apiKeyDTO *APIKeyCreate) Validate() []string {
+	_, err := govalidator.ValidateStruct(apiKeyDTO)
+	validationErrors := validator.CollectErrors(err)
+	for _, scope := range apiKeyDTO.Scopes {
+		if !permissionPattern.MatchString(scope) {
+			validationErrors = append(validationErrors, fmt.Sprintf("scopes: %s does not validate as permission", scope))
+		}
+	}
+	return validationErrors
+}
@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"github.com/asaskevich/govalidator"
+	"github.com/ilyushkaaa/Filmoteka/pkg/validator"
+)
+
+type (
+	DuplicatePair struct {
+		OriginalID  uint64  `json:"original_id"`
+		DuplicateID uint64  `json:"duplicate_id"`
+		Similarity  float64 `json:"similarity"`
+	}
+	DuplicatesResponse struct {
+		Error        string   `json:"error"`
+		CandidateIDs []uint64 `json:"candidate_ids"`
+	}
+	MergeRequest struct {
+		TargetID uint64 `json:"target_id" valid:"required"`
+	}
+)
+
+func (m *MergeRequest) Validate() []string {
+	_, err := govalidator.ValidateStruct(m)
+	return validator.CollectErrors(err)
+}
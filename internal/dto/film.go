@@ -2,10 +2,12 @@ package dto
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/ilyushkaaa/Filmoteka/internal/films/entity"
+	"github.com/ilyushkaaa/Filmoteka/pkg/locale"
 	"github.com/ilyushkaaa/Filmoteka/pkg/validator"
 )
 
@@ -25,6 +27,14 @@ type (
 		Rating        float64   `json:"rating" valid:"required,range(0|10)"`
 		ActorIDs      []uint64  `json:"actor_ids"`
 	}
+	FilmTitle struct {
+		Locale     string `json:"locale" valid:"required"`
+		Title      string `json:"title" valid:"required,length(1|150)"`
+		IsOriginal bool   `json:"is_original"`
+	}
+	FilmTitlesUpdate struct {
+		Titles []FilmTitle `json:"titles"`
+	}
 	FilmDB struct {
 		ID            sql.NullInt64
 		Name          sql.NullString
@@ -85,3 +95,38 @@ func (f *FilmDB) GetFilm() *entity.Film {
 		Rating:        f.Rating.Float64,
 	}
 }
+
+func (f *FilmTitlesUpdate) Validate() []string {
+	_, err := govalidator.ValidateStruct(f)
+	validationErrors := validator.CollectErrors(err)
+	locales := make(map[string]struct{}, len(f.Titles))
+	originalsCount := 0
+	for _, title := range f.Titles {
+		if title.Locale != "" && locale.Normalize(title.Locale) != title.Locale {
+			validationErrors = append(validationErrors, fmt.Sprintf("locale: %s is not a lowercase language code", title.Locale))
+		}
+		if _, ok := locales[title.Locale]; ok {
+			validationErrors = append(validationErrors, fmt.Sprintf("locale: title for %s is passed more than once", title.Locale))
+		}
+		locales[title.Locale] = struct{}{}
+		if title.IsOriginal {
+			originalsCount++
+		}
+	}
+	if originalsCount > 1 {
+		validationErrors = append(validationErrors, "is_original: only one title can be original")
+	}
+	return validationErrors
+}
+
+func (f *FilmTitlesUpdate) Convert() []entity.FilmTitle {
+	titles := make([]entity.FilmTitle, len(f.Titles))
+	for i, title := range f.Titles {
+		titles[i] = entity.FilmTitle{
+			Locale:     title.Locale,
+			Title:      title.Title,
+			IsOriginal: title.IsOriginal,
+		}
+	}
+	return titles
+}
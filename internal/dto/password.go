@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"github.com/asaskevich/govalidator"
+	"github.com/ilyushkaaa/Filmoteka/pkg/validator"
+)
+
+type (
+	PasswordChangeRequest struct {
+		CurrentPassword string `json:"current_password" valid:"required"`
+		NewPassword     string `json:"new_password" valid:"required,length(8|255)"`
+	}
+	PasswordResetRequest struct {
+		Username string `json:"username" valid:"required,matches(^[a-zA-Z0-9_]+$)"`
+	}
+	PasswordResetConfirm struct {
+		Token    string `json:"token" valid:"required,length(1|100)"`
+		Password string `json:"password" valid:"required,length(8|255)"`
+	}
+)
+
+func (changeDTO *PasswordChangeRequest) Validate() []string {
+	_, err := govalidator.ValidateStruct(changeDTO)
+	return validator.CollectErrors(err)
+}
+
+func (resetDTO *PasswordResetRequest) Validate() []string {
+	_, err := govalidator.ValidateStruct(resetDTO)
+	return validator.CollectErrors(err)
+}
+
+func (confirmDTO *PasswordResetConfirm) Validate() []string {
+	_, err := govalidator.ValidateStruct(confirmDTO)
+	return validator.CollectErrors(err)
+}
@@ -0,0 +1,101 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	apiKeyEntity "github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	"github.com/ilyushkaaa/Filmoteka/pkg/validator"
+)
+
+type (
+	ProfileResponse struct {
+		ID                uint64    `json:"id"`
+		Username          string    `json:"username"`
+		Role              string    `json:"role"`
+		DisplayName       string    `json:"display_name"`
+		Email             string    `json:"email"`
+		AvatarURL         string    `json:"avatar_url"`
+		PreferredLanguage string    `json:"preferred_language"`
+		CreatedAt         time.Time `json:"created_at"`
+	}
+	ProfileUpdate struct {
+		Username          *string `json:"username" valid:"matches(^[a-zA-Z0-9_]+$),length(1|255)"`
+		DisplayName       *string `json:"display_name" valid:"length(0|100)"`
+		Email             *string `json:"email" valid:"email,length(3|255)"`
+		AvatarURL         *string `json:"avatar_url" valid:"url,matches(^https?://),length(0|2048)"`
+		PreferredLanguage *string `json:"preferred_language" valid:"matches(^[a-z]{2,3}$)"`
+	}
+	AccountDeleteRequest struct {
+		Password string `json:"password" valid:"required"`
+	}
+	APIKeyInfo struct {
+		ID         uint64     `json:"id"`
+		Name       string     `json:"name"`
+		Prefix     string     `json:"prefix"`
+		Scopes     []string   `json:"scopes"`
+		ExpiresAt  *time.Time `json:"expires_at"`
+		CreatedAt  time.Time  `json:"created_at"`
+		LastUsedAt *time.Time `json:"last_used_at"`
+	}
+	AccountExport struct {
+		ExportedAt time.Time       `json:"exported_at"`
+		Profile    ProfileResponse `json:"profile"`
+		Sessions   []SessionInfo   `json:"sessions"`
+		APIKeys    []APIKeyInfo    `json:"api_keys"`
+	}
+)
+
+func NewProfileResponse(profile *entity.Profile) ProfileResponse {
+	return ProfileResponse{
+		ID:                profile.UserID,
+		Username:          profile.Username,
+		Role:              profile.Role,
+		DisplayName:       profile.DisplayName,
+		Email:             profile.Email,
+		AvatarURL:         profile.AvatarURL,
+		PreferredLanguage: profile.PreferredLanguage,
+		CreatedAt:         profile.CreatedAt,
+	}
+}
+
+func NewAPIKeyInfos(apiKeys []apiKeyEntity.APIKey) []APIKeyInfo {
+	apiKeyInfos := make([]APIKeyInfo, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		apiKeyInfos = append(apiKeyInfos, APIKeyInfo{
+			ID:         apiKey.ID,
+			Name:       apiKey.Name,
+			Prefix:     apiKey.Prefix,
+			Scopes:     apiKey.Scopes,
+			ExpiresAt:  apiKey.ExpiresAt,
+			CreatedAt:  apiKey.CreatedAt,
+			LastUsedAt: apiKey.LastUsedAt,
+		})
+	}
+	return apiKeyInfos
+}
+
+func (updateDTO *ProfileUpdate) Validate() []string {
+	_, err := govalidator.ValidateStruct(updateDTO)
+	validationErrors := validator.CollectErrors(err)
+	if updateDTO.Username != nil && *updateDTO.Username == "" {
+		validationErrors = append(validationErrors, "username: non zero value required")
+	}
+	return validationErrors
+}
+
+func (updateDTO *ProfileUpdate) ToEntity() *entity.ProfileUpdate {
+	return &entity.ProfileUpdate{
+		Username:          updateDTO.Username,
+		DisplayName:       updateDTO.DisplayName,
+		Email:             updateDTO.Email,
+		AvatarURL:         updateDTO.AvatarURL,
+		PreferredLanguage: updateDTO.PreferredLanguage,
+	}
+}
+
+func (deleteDTO *AccountDeleteRequest) Validate() []string {
+	_, err := govalidator.ValidateStruct(deleteDTO)
+	return validator.CollectErrors(err)
+}
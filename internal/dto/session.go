@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/session/entity"
+)
+
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	IsCurrent  bool      `json:"is_current"`
+}
+
+func NewSessionInfos(sessions []entity.Session, currentSessionID string) []SessionInfo {
+	sessionInfos := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		sessionInfos = append(sessionInfos, SessionInfo{
+			ID:         session.ID,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			IsCurrent:  session.ID == currentSessionID,
+		})
+	}
+	return sessionInfos
+}
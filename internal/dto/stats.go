@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	entityActor "github.com/ilyushkaaa/Filmoteka/internal/actors/entity"
+	entityFilm "github.com/ilyushkaaa/Filmoteka/internal/films/entity"
+)
+
+type (
+	StatsPeriod struct {
+		From time.Time
+		To   time.Time
+	}
+	CatalogStats struct {
+		FilmsCount         int                 `json:"films_count"`
+		ActorsCount        int                 `json:"actors_count"`
+		UsersCount         int                 `json:"users_count"`
+		FilmsPerYear       map[int]int         `json:"films_per_year"`
+		RatingDistribution map[int]int         `json:"rating_distribution"`
+		TopActors          []ActorFilmsCount   `json:"top_actors"`
+		FilmsWithoutCast   []entityFilm.Film   `json:"films_without_cast"`
+		ActorsWithoutFilms []entityActor.Actor `json:"actors_without_films"`
+	}
+	ActorFilmsCount struct {
+		ActorID    uint64 `json:"actor_id"`
+		Name       string `json:"name"`
+		Surname    string `json:"surname"`
+		FilmsCount int    `json:"films_count"`
+	}
+)
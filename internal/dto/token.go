@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	tokenEntity "github.com/ilyushkaaa/Filmoteka/internal/token/entity"
+	"github.com/ilyushkaaa/Filmoteka/pkg/validator"
+)
+
+const tokenTypeBearer = "Bearer"
+
+type (
+	RefreshTokenRequest struct {
+		RefreshToken string `json:"refresh_token" valid:"required"`
+	}
+	TokenResponse struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int64  `json:"expires_in"`
+		RefreshToken string `json:"refresh_token"`
+		// RecoveryCodes заполняется, только если TOTP был подключен во время входа
+		RecoveryCodes []string `json:"recovery_codes,omitempty"`
+	}
+)
+
+func (refreshReqDTO *RefreshTokenRequest) Validate() []string {
+	_, err := govalidator.ValidateStruct(refreshReqDTO)
+	return validator.CollectErrors(err)
+}
+
+func NewTokenResponse(tokens *tokenEntity.TokenPair) TokenResponse {
+	return TokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int64(time.Until(tokens.AccessTokenExpiresAt).Seconds()),
+		RefreshToken: tokens.RefreshToken,
+	}
+}
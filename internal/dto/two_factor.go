@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+	"github.com/ilyushkaaa/Filmoteka/pkg/validator"
+)
+
+type (
+	TOTPCodeRequest struct {
+		Code string `json:"code" valid:"required,length(6|32)"`
+	}
+	LoginChallengeRequest struct {
+		ChallengeID string `json:"challenge_id" valid:"required,uuid"`
+		Code        string `json:"code" valid:"required,length(6|32)"`
+	}
+	LoginChallengeEnrollRequest struct {
+		ChallengeID string `json:"challenge_id" valid:"required,uuid"`
+	}
+	LoginChallengeResponse struct {
+		ChallengeID        string    `json:"challenge_id"`
+		EnrollmentRequired bool      `json:"enrollment_required"`
+		ExpiresAt          time.Time `json:"expires_at"`
+	}
+	RecoveryCodesResponse struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+)
+
+func (codeReqDTO *TOTPCodeRequest) Validate() []string {
+	_, err := govalidator.ValidateStruct(codeReqDTO)
+	return validator.CollectErrors(err)
+}
+
+func (challengeReqDTO *LoginChallengeRequest) Validate() []string {
+	_, err := govalidator.ValidateStruct(challengeReqDTO)
+	return validator.CollectErrors(err)
+}
+
+func (enrollReqDTO *LoginChallengeEnrollRequest) Validate() []string {
+	_, err := govalidator.ValidateStruct(enrollReqDTO)
+	return validator.CollectErrors(err)
+}
+
+func NewLoginChallengeResponse(challenge *entity.LoginChallenge) LoginChallengeResponse {
+	return LoginChallengeResponse{
+		ChallengeID:        challenge.ID,
+		EnrollmentRequired: challenge.EnrollmentRequired,
+		ExpiresAt:          challenge.ExpiresAt,
+	}
+}
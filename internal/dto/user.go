@@ -2,6 +2,7 @@ package dto
 
 import (
 	"github.com/asaskevich/govalidator"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/entity"
 	"github.com/ilyushkaaa/Filmoteka/pkg/validator"
 )
 
@@ -9,9 +10,11 @@ type (
 	AuthRequest struct {
 		Password string `json:"password" valid:"required,length(8|255)"`
 		Username string `json:"username" valid:"required,matches(^[a-zA-Z0-9_]+$)"`
+		Email    string `json:"email,omitempty" valid:"optional,email,length(3|255)"`
 	}
 	AuthResponse struct {
-		SessionID string `json:"session_id"`
+		SessionID     string   `json:"session_id"`
+		RecoveryCodes []string `json:"recovery_codes,omitempty"`
 	}
 )
 
@@ -19,3 +22,20 @@ func (authReqDTO *AuthRequest) Validate() []string {
 	_, err := govalidator.ValidateStruct(authReqDTO)
 	return validator.CollectErrors(err)
 }
+
+type (
+	UserRoleUpdate struct {
+		Role string `json:"role" valid:"required,matches(^[a-z_]+$)"`
+	}
+	UsersPage struct {
+		Users  []entity.User `json:"users"`
+		Total  uint64        `json:"total"`
+		Limit  uint64        `json:"limit"`
+		Offset uint64        `json:"offset"`
+	}
+)
+
+func (roleUpdateDTO *UserRoleUpdate) Validate() []string {
+	_, err := govalidator.ValidateStruct(roleUpdateDTO)
+	return validator.CollectErrors(err)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/ilyushkaaa/Filmoteka/internal/dto"
 	_ "github.com/ilyushkaaa/Filmoteka/internal/films/entity"
 	"github.com/ilyushkaaa/Filmoteka/internal/films/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/locale"
 	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
 	"github.com/ilyushkaaa/Filmoteka/pkg/response"
 
@@ -33,6 +34,7 @@ func NewFilmHandler(filmUseCase usecase.FilmUseCase) *FilmHandler {
 // @Tags films
 // @Accept json
 // @Produce json
+// @Param lang query string false "Язык названий (например, ru или en), по умолчанию берется из заголовка Accept-Language"
 // @Success 200 {array} entity.Film
 // @Failure 400 {object} string "Передан неверный параметр сортировки"
 // @Failure 500 {object} string "Внутренняя ошибка сервера"
@@ -58,7 +60,7 @@ func (h *FilmHandler) GetFilms(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	films, err := h.filmUseCase.GetFilms(sortParam)
+	films, err := h.filmUseCase.GetFilms(r.Context(), sortParam, locale.FromRequest(r))
 	if err != nil {
 		zapLogger.Errorf("error in getting films: %s", err)
 		errText := `{"error": "internal server error}`
@@ -90,6 +92,7 @@ func (h *FilmHandler) GetFilms(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param FILM_ID path string true "ID актера"
+// @Param lang query string false "Язык названий (например, ru или en), по умолчанию берется из заголовка Accept-Language"
 // @Success 200 {object} entity.Film
 // @Failure 400 {object} string "Идентификатор фильма передан в неверном формате"
 // @Failure 404 {object} string "Фильм не найден"
@@ -117,7 +120,7 @@ func (h *FilmHandler) GetFilmByID(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	film, err := h.filmUseCase.GetFilmByID(filmIDInt)
+	film, err := h.filmUseCase.GetFilmByID(r.Context(), filmIDInt, locale.FromRequest(r))
 	if errors.Is(err, usecase.ErrFilmNotFound) {
 		zapLogger.Errorf("film with id %d is not found", filmIDInt)
 		errText := fmt.Sprintf(`{"error": "film with ID %d is not found"}`, filmIDInt)
@@ -153,16 +156,18 @@ func (h *FilmHandler) GetFilmByID(w http.ResponseWriter, r *http.Request) {
 }
 
 // AddFilm @Summary Добавление нового фильма
-// @Description Данный метод позволяет добавить новый фильм в систему.
+// @Description Данный метод позволяет добавить новый фильм в систему. Если фильм с похожим названием и тем же годом выхода уже существует, возвращается список возможных дубликатов.
 // @Tags films
 // @Accept json
 // @Produce json
 // @Security CookieAuth
 // @Param body body entity.Film true "Данные о новом фильме"
+// @Param force query bool false "Добавить фильм, даже если найдены возможные дубликаты"
 // @Success 200 {object} entity.Film "Данные добавленного фильма"
 // @Failure 400 {object} string "Ошибка в запросе"
 // @Failure 401 {object} string "Пользователь не аутентифицирован"
 // @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 409 {object} dto.DuplicatesResponse "Найдены возможные дубликаты фильма"
 // @Failure 422 {object} string "Ошибка валидации данных"
 // @Failure 500 {object} string "Внутренняя ошибка сервера"
 // @Router /api/v1/admin/film [post]
@@ -176,6 +181,19 @@ func (h *FilmHandler) AddFilm(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	force := false
+	if forceParam := r.URL.Query().Get("force"); forceParam != "" {
+		force, err = strconv.ParseBool(forceParam)
+		if err != nil {
+			zapLogger.Errorf("error in force param conversion: %s", err)
+			errText := fmt.Sprintf(`{"error": "bad format of force param: %s"}`, err)
+			err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+			if err != nil {
+				zapLogger.Errorf("error in writing response: %s", err)
+			}
+			return
+		}
+	}
 	filmDTO := &dto.FilmAdd{}
 	rBody, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -218,7 +236,30 @@ func (h *FilmHandler) AddFilm(w http.ResponseWriter, r *http.Request) {
 	}
 
 	film, actorIDs := filmDTO.GetFilmAndActorIDs()
-	addedFilm, err := h.filmUseCase.AddFilm(film, actorIDs)
+	addedFilm, err := h.filmUseCase.AddFilm(r.Context(), film, actorIDs, force)
+	var duplicateErr *usecase.DuplicateFilmError
+	if errors.As(err, &duplicateErr) {
+		zapLogger.Errorf("error in adding film: %s", err)
+		var duplicatesJSON []byte
+		duplicatesJSON, err = json.Marshal(dto.DuplicatesResponse{
+			Error:        usecase.ErrFilmDuplicate.Error(),
+			CandidateIDs: duplicateErr.CandidateIDs,
+		})
+		if err != nil {
+			zapLogger.Errorf("error in marshalling duplicates: %s", err)
+			errText := `{"error": "internal server error"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if err != nil {
+				zapLogger.Errorf("error in writing response: %s", err)
+			}
+			return
+		}
+		err = response.WriteResponse(w, duplicatesJSON, http.StatusConflict)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
 	if errors.Is(err, usecase.ErrBadFilmAddData) {
 		errText := `{"error": "bad add data"}`
 		zapLogger.Errorf("error in adding film: %s", err)
@@ -319,7 +360,7 @@ func (h *FilmHandler) UpdateFilm(w http.ResponseWriter, r *http.Request) {
 	}
 
 	film, actorIDs := filmDTO.GetFilmAndActorIDs()
-	err = h.filmUseCase.UpdateFilm(film, actorIDs)
+	err = h.filmUseCase.UpdateFilm(r.Context(), film, actorIDs)
 	if errors.Is(err, usecase.ErrBadFilmUpdateData) {
 		errText := `{"error": "bad update data"}`
 		zapLogger.Errorf("error in updating film: %s", err)
@@ -360,6 +401,7 @@ func (h *FilmHandler) UpdateFilm(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param SEARCH_STR path string true "Строка поиска"
+// @Param lang query string false "Язык названий (например, ru или en), по умолчанию берется из заголовка Accept-Language"
 // @Success 200 {array} entity.Film "Список фильмов"
 // @Failure 404 {object} string "Фильмы не найдены"
 // @Failure 500 {object} string "Внутренняя ошибка сервера"
@@ -376,7 +418,7 @@ func (h *FilmHandler) GetFilmsBySearch(w http.ResponseWriter, r *http.Request) {
 	}
 	vars := mux.Vars(r)
 	searchStr := vars["SEARCH_STR"]
-	films, err := h.filmUseCase.GetFilmsBySearch(searchStr)
+	films, err := h.filmUseCase.GetFilmsBySearch(r.Context(), searchStr, locale.FromRequest(r))
 	if errors.Is(err, usecase.ErrFilmsNotFound) {
 		zapLogger.Errorf("no films as a rusult of search %s", searchStr)
 		errText := fmt.Sprintf(`{"error": "no films found for search %s"}`, searchStr)
@@ -447,7 +489,7 @@ func (h *FilmHandler) DeleteFilm(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	err = h.filmUseCase.DeleteFilm(filmIDInt)
+	err = h.filmUseCase.DeleteFilm(r.Context(), filmIDInt)
 	if errors.Is(err, usecase.ErrFilmNotFound) {
 		zapLogger.Errorf("film with id %d is not found", filmIDInt)
 		errText := fmt.Sprintf(`{"error": "film with ID %d is not found"}`, filmIDInt)
@@ -472,3 +514,278 @@ func (h *FilmHandler) DeleteFilm(w http.ResponseWriter, r *http.Request) {
 		zapLogger.Errorf("error in writing response: %s", err)
 	}
 }
+
+// GetFilmDuplicates @Summary Получить возможные дубликаты фильмов
+// @Description Получить пары фильмов с похожими названиями и одинаковым годом выхода
+// @Tags films
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Success 200 {array} dto.DuplicatePair
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/films/duplicates [get]
+func (h *FilmHandler) GetFilmDuplicates(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	duplicates, err := h.filmUseCase.GetFilmDuplicates(r.Context())
+	if err != nil {
+		zapLogger.Errorf("error in getting film duplicates: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	duplicatesJSON, err := json.Marshal(duplicates)
+	if err != nil {
+		zapLogger.Errorf("error in marshalling film duplicates: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, duplicatesJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+// MergeFilms @Summary Объединение дубликатов фильма
+// @Description Данный метод переносит все связи фильма с актерами на целевой фильм, сохраняет старый идентификатор как псевдоним целевого и удаляет исходный фильм.
+// @Tags films
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param FILM_ID path int true "Идентификатор фильма, который будет удален"
+// @Param body body dto.MergeRequest true "Идентификатор целевого фильма"
+// @Success 200 {object} entity.Film "Данные целевого фильма после объединения"
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 404 {object} string "Фильм не найден"
+// @Failure 422 {object} string "Ошибка валидации данных"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/film/{FILM_ID}/merge [post]
+func (h *FilmHandler) MergeFilms(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	vars := mux.Vars(r)
+	filmID := vars["FILM_ID"]
+	filmIDInt, err := strconv.ParseUint(filmID, 10, 64)
+	if err != nil {
+		zapLogger.Errorf("error in film id conversion: %s", err)
+		errText := fmt.Sprintf(`{"error": "bad format of film id: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	mergeDTO := &dto.MergeRequest{}
+	rBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		zapLogger.Errorf("error in reading request body: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in reading request body: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = json.Unmarshal(rBody, mergeDTO)
+	if err != nil {
+		zapLogger.Errorf("error in unmarshalling merge request: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in decoding merge request: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+
+	if validationErrors := mergeDTO.Validate(); len(validationErrors) != 0 {
+		var errorsJSON []byte
+		errorsJSON, err = json.Marshal(validationErrors)
+		if err != nil {
+			zapLogger.Errorf("error in marshalling validation errors: %s", err)
+			errText := `{"error": "internal server error"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if err != nil {
+				zapLogger.Errorf("error in writing response: %s", err)
+			}
+			return
+		}
+		err = response.WriteResponse(w, errorsJSON, http.StatusUnprocessableEntity)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+
+	mergedFilm, err := h.filmUseCase.MergeFilms(r.Context(), filmIDInt, mergeDTO.TargetID)
+	if errors.Is(err, usecase.ErrSameFilmMerge) {
+		zapLogger.Errorf("error in merging films: %s", err)
+		errText := fmt.Sprintf(`{"error": "%s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if errors.Is(err, usecase.ErrFilmNotFound) {
+		zapLogger.Errorf("film with id %d or %d is not found", filmIDInt, mergeDTO.TargetID)
+		errText := fmt.Sprintf(`{"error": "film with ID %d or %d is not found"}`, filmIDInt, mergeDTO.TargetID)
+		err = response.WriteResponse(w, []byte(errText), http.StatusNotFound)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		errText := `{"error": "internal server error"}`
+		zapLogger.Errorf("error in merging films: %s", err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	filmJSON, err := json.Marshal(mergedFilm)
+	if err != nil {
+		zapLogger.Errorf("error in marshalling film: %s", err)
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, filmJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+// SetFilmTitles @Summary Задание локализованных названий фильма
+// @Description Данный метод заменяет все альтернативные названия фильма. Одно из названий может быть отмечено как оригинальное, оно используется, если названия на запрошенном языке нет.
+// @Tags films
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param FILM_ID path int true "Идентификатор фильма"
+// @Param body body dto.FilmTitlesUpdate true "Названия фильма на разных языках"
+// @Success 200 {object} string "Названия успешно сохранены"
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 404 {object} string "Фильм не найден"
+// @Failure 422 {object} string "Ошибка валидации данных"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/film/{FILM_ID}/titles [put]
+func (h *FilmHandler) SetFilmTitles(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	vars := mux.Vars(r)
+	filmID := vars["FILM_ID"]
+	filmIDInt, err := strconv.ParseUint(filmID, 10, 64)
+	if err != nil {
+		zapLogger.Errorf("error in film id conversion: %s", err)
+		errText := fmt.Sprintf(`{"error": "bad format of film id: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	titlesDTO := &dto.FilmTitlesUpdate{}
+	rBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		zapLogger.Errorf("error in reading request body: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in reading request body: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = json.Unmarshal(rBody, titlesDTO)
+	if err != nil {
+		zapLogger.Errorf("error in unmarshalling film titles: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in decoding film titles: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+
+	if validationErrors := titlesDTO.Validate(); len(validationErrors) != 0 {
+		var errorsJSON []byte
+		errorsJSON, err = json.Marshal(validationErrors)
+		if err != nil {
+			zapLogger.Errorf("error in marshalling validation errors: %s", err)
+			errText := `{"error": "internal server error"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if err != nil {
+				zapLogger.Errorf("error in writing response: %s", err)
+			}
+			return
+		}
+		err = response.WriteResponse(w, errorsJSON, http.StatusUnprocessableEntity)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+
+	err = h.filmUseCase.SetFilmTitles(r.Context(), filmIDInt, titlesDTO.Convert())
+	if errors.Is(err, usecase.ErrFilmNotFound) {
+		zapLogger.Errorf("film with id %d is not found", filmIDInt)
+		errText := fmt.Sprintf(`{"error": "film with ID %d is not found"}`, filmIDInt)
+		err = response.WriteResponse(w, []byte(errText), http.StatusNotFound)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		errText := `{"error": "internal server error"}`
+		zapLogger.Errorf("error in setting film titles: %s", err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	result := `{"result": "success"}`
+	err = response.WriteResponse(w, []byte(result), http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
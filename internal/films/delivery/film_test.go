@@ -12,6 +12,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
 	"github.com/ilyushkaaa/Filmoteka/internal/films/entity"
 	"github.com/ilyushkaaa/Filmoteka/internal/films/usecase"
 	"github.com/ilyushkaaa/Filmoteka/internal/films/usecase/mock"
@@ -81,7 +82,7 @@ func TestGetFilms(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().GetFilms("").Return(nil, fmt.Errorf("error"))
+	testUseCase.EXPECT().GetFilms(gomock.Any(), "", []string{}).Return(nil, fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodGet, "/films", nil)
 	ctx := request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -101,7 +102,7 @@ func TestGetFilms(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusUnauthorized, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().GetFilms("").Return(nil, fmt.Errorf("error"))
+	testUseCase.EXPECT().GetFilms(gomock.Any(), "", []string{}).Return(nil, fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodGet, "/films", nil)
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -121,8 +122,9 @@ func TestGetFilms(t *testing.T) {
 	}
 
 	films := make([]entity.Film, 0)
-	testUseCase.EXPECT().GetFilms("").Return(films, nil)
+	testUseCase.EXPECT().GetFilms(gomock.Any(), "", []string{"ru", "en"}).Return(films, nil)
 	request = httptest.NewRequest(http.MethodGet, "/films", nil)
+	request.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
 	respWriter = httptest.NewRecorder()
@@ -208,7 +210,7 @@ func TestGetFilmByID(t *testing.T) {
 	}
 
 	var id uint64 = 1
-	testUseCase.EXPECT().GetFilmByID(id).Return(nil, fmt.Errorf("error"))
+	testUseCase.EXPECT().GetFilmByID(gomock.Any(), id, []string{}).Return(nil, fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodGet, "/film/1", nil)
 	request = mux.SetURLVars(request, map[string]string{"FILM_ID": "1"})
 	ctx = request.Context()
@@ -229,7 +231,7 @@ func TestGetFilmByID(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().GetFilmByID(id).Return(nil, usecase.ErrFilmNotFound)
+	testUseCase.EXPECT().GetFilmByID(gomock.Any(), id, []string{}).Return(nil, usecase.ErrFilmNotFound)
 	request = httptest.NewRequest(http.MethodGet, "/film/1", nil)
 	request = mux.SetURLVars(request, map[string]string{"FILM_ID": "1"})
 	ctx = request.Context()
@@ -251,7 +253,7 @@ func TestGetFilmByID(t *testing.T) {
 	}
 
 	film := &entity.Film{}
-	testUseCase.EXPECT().GetFilmByID(id).Return(film, nil)
+	testUseCase.EXPECT().GetFilmByID(gomock.Any(), id, []string{}).Return(film, nil)
 	request = httptest.NewRequest(http.MethodGet, "/film/1", nil)
 	request = mux.SetURLVars(request, map[string]string{"FILM_ID": "1"})
 	ctx = request.Context()
@@ -378,7 +380,7 @@ func TestAddFilm(t *testing.T) {
 		DateOfRelease: time.Time{}.Add(time.Hour),
 		Rating:        5.1,
 	}
-	testUseCase.EXPECT().AddFilm(film, []uint64{}).Return(nil, fmt.Errorf("error"))
+	testUseCase.EXPECT().AddFilm(gomock.Any(), film, []uint64{}, false).Return(nil, fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodPost, "/film", strings.NewReader(
 		`{"name":"qqq","description":"fff","date_of_release":"0001-01-01T01:00:00Z","rating":5.1}`))
 	ctx = request.Context()
@@ -399,7 +401,7 @@ func TestAddFilm(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().AddFilm(film, []uint64{}).Return(nil, usecase.ErrBadFilmAddData)
+	testUseCase.EXPECT().AddFilm(gomock.Any(), film, []uint64{}, false).Return(nil, usecase.ErrBadFilmAddData)
 	request = httptest.NewRequest(http.MethodPost, "/film", strings.NewReader(
 		`{"name":"qqq","description":"fff","date_of_release":"0001-01-01T01:00:00Z","rating":5.1}`))
 	ctx = request.Context()
@@ -420,10 +422,48 @@ func TestAddFilm(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusBadRequest, resp.StatusCode)
 	}
 
+	testUseCase.EXPECT().AddFilm(gomock.Any(), film, []uint64{}, false).Return(nil, &usecase.DuplicateFilmError{CandidateIDs: []uint64{2}})
+	request = httptest.NewRequest(http.MethodPost, "/film", strings.NewReader(
+		`{"name":"qqq","description":"fff","date_of_release":"0001-01-01T01:00:00Z","rating":5.1}`))
+	ctx = request.Context()
+	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.AddFilm(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable to read response body")
+		return
+	}
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 409 {
+		t.Errorf("expected status %d, got status %d", http.StatusConflict, resp.StatusCode)
+	}
+	if !strings.Contains(string(body), `"candidate_ids":[2]`) {
+		t.Errorf("expected candidate ids in response, got %s", body)
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/film?force=bad", nil)
+	ctx = request.Context()
+	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.AddFilm(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 400 {
+		t.Errorf("expected status %d, got status %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
 	filmAdded := film
 	filmAdded.ID = 1
-	testUseCase.EXPECT().AddFilm(film, []uint64{}).Return(&filmAdded, nil)
-	request = httptest.NewRequest(http.MethodPost, "/film", strings.NewReader(
+	testUseCase.EXPECT().AddFilm(gomock.Any(), film, []uint64{}, true).Return(&filmAdded, nil)
+	request = httptest.NewRequest(http.MethodPost, "/film?force=true", strings.NewReader(
 		`{"name":"qqq","description":"fff","date_of_release":"0001-01-01T01:00:00Z","rating":5.1}`))
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -551,7 +591,7 @@ func TestUpdateFilm(t *testing.T) {
 		DateOfRelease: time.Time{}.Add(time.Hour),
 		Rating:        5.1,
 	}
-	testUseCase.EXPECT().UpdateFilm(film, []uint64{}).Return(fmt.Errorf("error"))
+	testUseCase.EXPECT().UpdateFilm(gomock.Any(), film, []uint64{}).Return(fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodPut, "/film", strings.NewReader(
 		`{"id":1,"name":"qqq","description":"fff","date_of_release":"0001-01-01T01:00:00Z","rating":5.1}`))
 	ctx = request.Context()
@@ -572,7 +612,7 @@ func TestUpdateFilm(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().UpdateFilm(film, []uint64{}).Return(usecase.ErrBadFilmUpdateData)
+	testUseCase.EXPECT().UpdateFilm(gomock.Any(), film, []uint64{}).Return(usecase.ErrBadFilmUpdateData)
 	request = httptest.NewRequest(http.MethodPut, "/film", strings.NewReader(
 		`{"id":1,"name":"qqq","description":"fff","date_of_release":"0001-01-01T01:00:00Z","rating":5.1}`))
 	ctx = request.Context()
@@ -593,7 +633,7 @@ func TestUpdateFilm(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusBadRequest, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().UpdateFilm(film, []uint64{}).Return(nil)
+	testUseCase.EXPECT().UpdateFilm(gomock.Any(), film, []uint64{}).Return(nil)
 	request = httptest.NewRequest(http.MethodPut, "/film", strings.NewReader(
 		`{"id":1,"name":"qqq","description":"fff","date_of_release":"0001-01-01T01:00:00Z","rating":5.1}`))
 	ctx = request.Context()
@@ -658,7 +698,7 @@ func TestGetFilmsBySearch(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().GetFilmsBySearch("").Return(nil, fmt.Errorf("error"))
+	testUseCase.EXPECT().GetFilmsBySearch(gomock.Any(), "", []string{}).Return(nil, fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodGet, "/films/search", nil)
 	ctx := request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -678,7 +718,7 @@ func TestGetFilmsBySearch(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().GetFilmsBySearch("").Return(nil, usecase.ErrFilmsNotFound)
+	testUseCase.EXPECT().GetFilmsBySearch(gomock.Any(), "", []string{}).Return(nil, usecase.ErrFilmsNotFound)
 	request = httptest.NewRequest(http.MethodGet, "/films/search", nil)
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -698,7 +738,7 @@ func TestGetFilmsBySearch(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusNotFound, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().GetFilmsBySearch("").Return([]entity.Film{}, nil)
+	testUseCase.EXPECT().GetFilmsBySearch(gomock.Any(), "", []string{}).Return([]entity.Film{}, nil)
 	request = httptest.NewRequest(http.MethodGet, "/films/search", nil)
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -783,7 +823,7 @@ func TestDeleteFilm(t *testing.T) {
 	}
 
 	var id uint64 = 1
-	testUseCase.EXPECT().DeleteFilm(id).Return(fmt.Errorf("error"))
+	testUseCase.EXPECT().DeleteFilm(gomock.Any(), id).Return(fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodGet, "/film/1", nil)
 	request = mux.SetURLVars(request, map[string]string{"FILM_ID": "1"})
 	ctx = request.Context()
@@ -804,3 +844,196 @@ func TestDeleteFilm(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 }
+
+func TestGetFilmDuplicates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockFilmUseCase(ctrl)
+	testHandler := NewFilmHandler(testUseCase)
+
+	request := httptest.NewRequest(http.MethodGet, "/admin/films/duplicates", nil)
+	respWriter := httptest.NewRecorder()
+	testHandler.GetFilmDuplicates(respWriter, request)
+	resp := respWriter.Result()
+	err := resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 500 {
+		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	testUseCase.EXPECT().GetFilmDuplicates(gomock.Any()).Return(nil, fmt.Errorf("error"))
+	request = httptest.NewRequest(http.MethodGet, "/admin/films/duplicates", nil)
+	ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.GetFilmDuplicates(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 500 {
+		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	testUseCase.EXPECT().GetFilmDuplicates(gomock.Any()).Return([]dto.DuplicatePair{{OriginalID: 1, DuplicateID: 2}}, nil)
+	request = httptest.NewRequest(http.MethodGet, "/admin/films/duplicates", nil)
+	ctx = context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.GetFilmDuplicates(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 200 {
+		t.Errorf("expected status %d, got status %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestMergeFilms(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockFilmUseCase(ctrl)
+	testHandler := NewFilmHandler(testUseCase)
+
+	request := httptest.NewRequest(http.MethodPost, "/admin/film/1/merge", nil)
+	respWriter := httptest.NewRecorder()
+	testHandler.MergeFilms(respWriter, request)
+	resp := respWriter.Result()
+	err := resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 500 {
+		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	testCases := []struct {
+		id         string
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{id: "bad_id", body: `{"target_id":2}`, statusCode: http.StatusBadRequest},
+		{id: "1", body: `{"`, statusCode: http.StatusBadRequest},
+		{id: "1", body: `{}`, statusCode: http.StatusUnprocessableEntity},
+		{
+			id:   "1",
+			body: `{"target_id":1}`,
+			setup: func() {
+				testUseCase.EXPECT().MergeFilms(gomock.Any(), uint64(1), uint64(1)).Return(nil, usecase.ErrSameFilmMerge)
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			id:   "1",
+			body: `{"target_id":2}`,
+			setup: func() {
+				testUseCase.EXPECT().MergeFilms(gomock.Any(), uint64(1), uint64(2)).Return(nil, usecase.ErrFilmNotFound)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			id:   "1",
+			body: `{"target_id":2}`,
+			setup: func() {
+				testUseCase.EXPECT().MergeFilms(gomock.Any(), uint64(1), uint64(2)).Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			id:   "1",
+			body: `{"target_id":2}`,
+			setup: func() {
+				testUseCase.EXPECT().MergeFilms(gomock.Any(), uint64(1), uint64(2)).Return(&entity.Film{}, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request = httptest.NewRequest(http.MethodPost, "/admin/film/"+tc.id+"/merge", strings.NewReader(tc.body))
+		request = mux.SetURLVars(request, map[string]string{"FILM_ID": tc.id})
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter = httptest.NewRecorder()
+		testHandler.MergeFilms(respWriter, request.WithContext(ctx))
+		resp = respWriter.Result()
+		err = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestSetFilmTitles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockFilmUseCase(ctrl)
+	testHandler := NewFilmHandler(testUseCase)
+
+	testCases := []struct {
+		id         string
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{id: "bad_id", body: `{"titles":[{"locale":"ru","title":"Фильм","is_original":true}]}`, statusCode: http.StatusBadRequest},
+		{id: "1", body: `{"`, statusCode: http.StatusBadRequest},
+		{id: "1", body: `{"titles":[{"locale":"ru","title":"Фильм"},{"locale":"ru","title":"Кино"}]}`, statusCode: http.StatusUnprocessableEntity},
+		{
+			id:   "1",
+			body: `{"titles":[{"locale":"ru","title":"Фильм","is_original":true}]}`,
+			setup: func() {
+				testUseCase.EXPECT().SetFilmTitles(gomock.Any(), uint64(1), gomock.Any()).Return(usecase.ErrFilmNotFound)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			id:   "1",
+			body: `{"titles":[{"locale":"ru","title":"Фильм","is_original":true}]}`,
+			setup: func() {
+				testUseCase.EXPECT().SetFilmTitles(gomock.Any(), uint64(1), gomock.Any()).Return(fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			id:   "1",
+			body: `{"titles":[{"locale":"ru","title":"Фильм","is_original":true}]}`,
+			setup: func() {
+				testUseCase.EXPECT().SetFilmTitles(gomock.Any(), uint64(1), []entity.FilmTitle{{Locale: "ru", Title: "Фильм", IsOriginal: true}}).Return(nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodPut, "/admin/film/"+tc.id+"/titles", strings.NewReader(tc.body))
+		request = mux.SetURLVars(request, map[string]string{"FILM_ID": tc.id})
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.SetFilmTitles(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
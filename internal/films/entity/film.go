@@ -8,4 +8,11 @@ type Film struct {
 	Description   string
 	DateOfRelease time.Time
 	Rating        float64
+	Titles        []FilmTitle `json:",omitempty"`
+}
+
+type FilmTitle struct {
+	Locale     string
+	Title      string
+	IsOriginal bool
 }
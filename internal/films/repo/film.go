@@ -1,21 +1,30 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"strings"
 
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
 	"github.com/ilyushkaaa/Filmoteka/internal/films/entity"
 	"go.uber.org/zap"
 )
 
+const filmSimilarityThreshold = 0.5
+
 //go:generate mockgen -source=film.go -destination=film_mock.go -package=repo FilmRepo
 type FilmRepo interface {
-	GetFilms(sortParam string) ([]entity.Film, error)
-	GetFilmByID(filmID uint64) (*entity.Film, error)
-	AddFilm(film entity.Film, actorIDs []uint64) (uint64, error)
-	UpdateFilm(film entity.Film, actorIDs []uint64) (bool, error)
-	GetFilmsBySearch(searchStr string) ([]entity.Film, error)
-	DeleteFilm(ID uint64) (bool, error)
+	GetFilms(ctx context.Context, sortParam string, langs []string) ([]entity.Film, error)
+	GetFilmByID(ctx context.Context, filmID uint64, langs []string) (*entity.Film, error)
+	AddFilm(ctx context.Context, film entity.Film, actorIDs []uint64) (uint64, error)
+	UpdateFilm(ctx context.Context, film entity.Film, actorIDs []uint64) (bool, error)
+	GetFilmsBySearch(ctx context.Context, searchStr string, langs []string) ([]entity.Film, error)
+	DeleteFilm(ctx context.Context, ID uint64) (bool, error)
+	FindDuplicateFilms(ctx context.Context, film entity.Film) ([]uint64, error)
+	GetFilmDuplicates(ctx context.Context) ([]dto.DuplicatePair, error)
+	MergeFilms(ctx context.Context, sourceID, targetID uint64) (bool, error)
+	SetFilmTitles(ctx context.Context, filmID uint64, titles []entity.FilmTitle) (bool, error)
 }
 
 type FilmRepoPG struct {
@@ -30,12 +39,19 @@ func NewFilmRepo(db *sql.DB, zapLogger *zap.SugaredLogger) *FilmRepoPG {
 	}
 }
 
-func (r *FilmRepoPG) GetFilms(sortParam string) ([]entity.Film, error) {
+func (r *FilmRepoPG) GetFilms(ctx context.Context, sortParam string, langs []string) ([]entity.Film, error) {
 	if sortParam == "" {
 		sortParam = "rating"
 	}
 
-	rows, err := r.db.Query("SELECT f.id, f.name, f.description, f.date_of_release, f.rating FROM films f ORDER BY " + sortParam + " DESC")
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT f.id,
+               COALESCE((SELECT ft.title FROM film_titles ft
+                         WHERE ft.film_id = f.id AND (ft.locale = ANY(string_to_array($1, ',')) OR ft.is_original)
+                         ORDER BY array_position(string_to_array($1, ','), ft.locale::TEXT) LIMIT 1), f.name) AS name,
+               f.description, f.date_of_release, f.rating
+        FROM films f
+        ORDER BY `+sortParam+` DESC`, strings.Join(langs, ","))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -60,10 +76,18 @@ func (r *FilmRepoPG) GetFilms(sortParam string) ([]entity.Film, error) {
 	return films, nil
 }
 
-func (r *FilmRepoPG) GetFilmByID(filmID uint64) (*entity.Film, error) {
+func (r *FilmRepoPG) GetFilmByID(ctx context.Context, filmID uint64, langs []string) (*entity.Film, error) {
 	film := &entity.Film{}
 	err := r.db.
-		QueryRow("SELECT id, name, description, date_of_release, rating FROM films WHERE id = ?", filmID).
+		QueryRowContext(ctx, `
+        SELECT id,
+               COALESCE((SELECT ft.title FROM film_titles ft
+                         WHERE ft.film_id = f.id AND (ft.locale = ANY(string_to_array($2, ',')) OR ft.is_original)
+                         ORDER BY array_position(string_to_array($2, ','), ft.locale::TEXT) LIMIT 1), f.name),
+               description, date_of_release, rating
+        FROM films f
+        WHERE id = COALESCE((SELECT fa.film_id FROM film_aliases fa WHERE fa.old_id = $1), $1)
+    `, filmID, strings.Join(langs, ",")).
 		Scan(&film.ID, &film.Name, &film.Description, &film.DateOfRelease, &film.Rating)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -71,10 +95,38 @@ func (r *FilmRepoPG) GetFilmByID(filmID uint64) (*entity.Film, error) {
 		}
 		return nil, err
 	}
+	film.Titles, err = r.getFilmTitles(ctx, film.ID)
+	if err != nil {
+		return nil, err
+	}
 	return film, nil
 }
-func (r *FilmRepoPG) AddFilm(film entity.Film, actorIDs []uint64) (uint64, error) {
-	tx, err := r.db.Begin()
+
+func (r *FilmRepoPG) getFilmTitles(ctx context.Context, filmID uint64) ([]entity.FilmTitle, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT locale, title, is_original FROM film_titles WHERE film_id = $1 ORDER BY is_original DESC, locale",
+		filmID)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			r.zapLogger.Errorf("error in closing query rows: %s", err)
+		}
+	}(rows)
+	titles := make([]entity.FilmTitle, 0)
+	for rows.Next() {
+		var title entity.FilmTitle
+		err = rows.Scan(&title.Locale, &title.Title, &title.IsOriginal)
+		if err != nil {
+			return nil, err
+		}
+		titles = append(titles, title)
+	}
+	return titles, rows.Err()
+}
+func (r *FilmRepoPG) AddFilm(ctx context.Context, film entity.Film, actorIDs []uint64) (uint64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return 0, err
 	}
@@ -88,7 +140,7 @@ func (r *FilmRepoPG) AddFilm(film entity.Film, actorIDs []uint64) (uint64, error
 	}()
 
 	var lastInsertId uint64
-	err = tx.QueryRow("INSERT INTO films (name, description, date_of_release, rating) VALUES ($1, $2, $3, $4) RETURNING id",
+	err = tx.QueryRowContext(ctx, "INSERT INTO films (name, description, date_of_release, rating) VALUES ($1, $2, $3, $4) RETURNING id",
 		film.Name, film.Description, film.DateOfRelease, film.Rating).Scan(&lastInsertId)
 	if err != nil {
 		return 0, err
@@ -96,7 +148,7 @@ func (r *FilmRepoPG) AddFilm(film entity.Film, actorIDs []uint64) (uint64, error
 
 	for _, id := range actorIDs {
 		var actorID uint64
-		err = tx.QueryRow("SELECT id FROM actors WHERE id = $1", id).Scan(&actorID)
+		err = tx.QueryRowContext(ctx, "SELECT id FROM actors WHERE id = $1", id).Scan(&actorID)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return 0, nil
@@ -104,7 +156,7 @@ func (r *FilmRepoPG) AddFilm(film entity.Film, actorIDs []uint64) (uint64, error
 			return 0, err
 		}
 
-		_, err = tx.Exec("INSERT INTO film_actors (film_id, actor_id) VALUES ($1, $2)",
+		_, err = tx.ExecContext(ctx, "INSERT INTO film_actors (film_id, actor_id) VALUES ($1, $2)",
 			lastInsertId, id)
 		if err != nil {
 			return 0, err
@@ -117,8 +169,8 @@ func (r *FilmRepoPG) AddFilm(film entity.Film, actorIDs []uint64) (uint64, error
 	}
 	return lastInsertId, nil
 }
-func (r *FilmRepoPG) UpdateFilm(film entity.Film, actorIDs []uint64) (bool, error) {
-	tx, err := r.db.Begin()
+func (r *FilmRepoPG) UpdateFilm(ctx context.Context, film entity.Film, actorIDs []uint64) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return false, err
 	}
@@ -131,7 +183,7 @@ func (r *FilmRepoPG) UpdateFilm(film entity.Film, actorIDs []uint64) (bool, erro
 		}
 	}()
 
-	_, err = tx.Exec("UPDATE films SET name = $1, description = $2, date_of_release = $3, rating = $4 WHERE id = $5",
+	_, err = tx.ExecContext(ctx, "UPDATE films SET name = $1, description = $2, date_of_release = $3, rating = $4 WHERE id = $5",
 		film.Name, film.Description, film.DateOfRelease, film.Rating, film.ID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -140,21 +192,21 @@ func (r *FilmRepoPG) UpdateFilm(film entity.Film, actorIDs []uint64) (bool, erro
 		return false, err
 	}
 
-	_, err = tx.Exec("DELETE FROM film_actors WHERE film_id = $1", film.ID)
+	_, err = tx.ExecContext(ctx, "DELETE FROM film_actors WHERE film_id = $1", film.ID)
 	if err != nil {
 		return false, err
 	}
 
 	for _, id := range actorIDs {
 		var actorID uint64
-		err = tx.QueryRow("SELECT id FROM actors WHERE id = $1", id).Scan(&actorID)
+		err = tx.QueryRowContext(ctx, "SELECT id FROM actors WHERE id = $1", id).Scan(&actorID)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return false, nil
 			}
 			return false, err
 		}
-		_, err = tx.Exec("INSERT INTO film_actors (film_id, actor_id) VALUES ($1, $2)", film.ID, id)
+		_, err = tx.ExecContext(ctx, "INSERT INTO film_actors (film_id, actor_id) VALUES ($1, $2)", film.ID, id)
 		if err != nil {
 			return false, err
 		}
@@ -167,16 +219,22 @@ func (r *FilmRepoPG) UpdateFilm(film entity.Film, actorIDs []uint64) (bool, erro
 	return true, nil
 }
 
-func (r *FilmRepoPG) GetFilmsBySearch(searchStr string) ([]entity.Film, error) {
-	rows, err := r.db.Query(`
-    SELECT DISTINCT f.id, f.name, f.description, f.date_of_release, f.rating
+func (r *FilmRepoPG) GetFilmsBySearch(ctx context.Context, searchStr string, langs []string) ([]entity.Film, error) {
+	rows, err := r.db.QueryContext(ctx, `
+    SELECT DISTINCT f.id,
+           COALESCE((SELECT ft.title FROM film_titles ft
+                     WHERE ft.film_id = f.id AND (ft.locale = ANY(string_to_array($2, ',')) OR ft.is_original)
+                     ORDER BY array_position(string_to_array($2, ','), ft.locale::TEXT) LIMIT 1), f.name),
+           f.description, f.date_of_release, f.rating
 	FROM films f
 	LEFT JOIN film_actors fa ON f.id = fa.film_id
 	LEFT JOIN actors a ON fa.actor_id = a.id
 	WHERE LOWER(f.name) LIKE LOWER('%' || $1 || '%')
-    OR LOWER(a.name || ' ' || a.surname) LIKE LOWER('%' || $1 || '%');
+    OR EXISTS (SELECT 1 FROM film_titles ft WHERE ft.film_id = f.id AND LOWER(ft.title) LIKE LOWER('%' || $1 || '%'))
+    OR LOWER(a.name || ' ' || a.surname) LIKE LOWER('%' || $1 || '%')
+    OR EXISTS (SELECT 1 FROM actor_names an WHERE an.actor_id = a.id AND LOWER(an.name || ' ' || an.surname) LIKE LOWER('%' || $1 || '%'));
 `,
-		searchStr)
+		searchStr, strings.Join(langs, ","))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -201,8 +259,8 @@ func (r *FilmRepoPG) GetFilmsBySearch(searchStr string) ([]entity.Film, error) {
 	return films, nil
 }
 
-func (r *FilmRepoPG) DeleteFilm(ID uint64) (bool, error) {
-	result, err := r.db.Exec(
+func (r *FilmRepoPG) DeleteFilm(ctx context.Context, ID uint64) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
 		"DELETE FROM films WHERE id = $1",
 		ID,
 	)
@@ -215,3 +273,176 @@ func (r *FilmRepoPG) DeleteFilm(ID uint64) (bool, error) {
 	}
 	return num > 0, nil
 }
+
+func (r *FilmRepoPG) FindDuplicateFilms(ctx context.Context, film entity.Film) ([]uint64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id
+        FROM films
+        WHERE DATE_PART('year', date_of_release) = DATE_PART('year', $1::TIMESTAMP)
+        AND LOWER(name) % LOWER($2) AND SIMILARITY(LOWER(name), LOWER($2)) >= $3
+        ORDER BY SIMILARITY(LOWER(name), LOWER($2)) DESC, id
+    `, film.DateOfRelease, film.Name, filmSimilarityThreshold)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			r.zapLogger.Errorf("error in closing query rows: %s", err)
+		}
+	}(rows)
+	candidateIDs := make([]uint64, 0)
+	for rows.Next() {
+		var candidateID uint64
+		err = rows.Scan(&candidateID)
+		if err != nil {
+			return nil, err
+		}
+		candidateIDs = append(candidateIDs, candidateID)
+	}
+	return candidateIDs, rows.Err()
+}
+
+func (r *FilmRepoPG) GetFilmDuplicates(ctx context.Context) ([]dto.DuplicatePair, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT f1.id, f2.id, SIMILARITY(LOWER(f1.name), LOWER(f2.name))
+        FROM films f1
+        JOIN films f2 ON f1.id < f2.id
+        AND DATE_PART('year', f1.date_of_release) = DATE_PART('year', f2.date_of_release)
+        AND LOWER(f1.name) % LOWER(f2.name) AND SIMILARITY(LOWER(f1.name), LOWER(f2.name)) >= $1
+        ORDER BY f1.id, f2.id
+    `, filmSimilarityThreshold)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			r.zapLogger.Errorf("error in closing query rows: %s", err)
+		}
+	}(rows)
+	duplicates := make([]dto.DuplicatePair, 0)
+	for rows.Next() {
+		var duplicate dto.DuplicatePair
+		err = rows.Scan(&duplicate.OriginalID, &duplicate.DuplicateID, &duplicate.Similarity)
+		if err != nil {
+			return nil, err
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+	return duplicates, rows.Err()
+}
+
+func (r *FilmRepoPG) MergeFilms(ctx context.Context, sourceID, targetID uint64) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			err = tx.Rollback()
+			if err != nil {
+				r.zapLogger.Errorf("error in transaction rollback: %s", err)
+			}
+		}
+	}()
+
+	var filmsCount int
+	err = tx.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM (SELECT id FROM films WHERE id IN ($1, $2) ORDER BY id FOR UPDATE) AS merged_films
+    `, sourceID, targetID).Scan(&filmsCount)
+	if err != nil {
+		return false, err
+	}
+	if filmsCount != 2 {
+		err = tx.Rollback()
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO film_actors (film_id, actor_id)
+        SELECT $2, actor_id FROM film_actors WHERE film_id = $1
+        ON CONFLICT DO NOTHING
+    `, sourceID, targetID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM film_actors WHERE film_id = $1", sourceID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO film_titles (film_id, locale, title, is_original)
+        SELECT $2, locale, title, FALSE FROM film_titles WHERE film_id = $1
+        ON CONFLICT DO NOTHING
+    `, sourceID, targetID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE film_aliases SET film_id = $2 WHERE film_id = $1", sourceID, targetID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO film_aliases (old_id, film_id) VALUES ($1, $2)", sourceID, targetID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM films WHERE id = $1", sourceID)
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *FilmRepoPG) SetFilmTitles(ctx context.Context, filmID uint64, titles []entity.FilmTitle) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			err = tx.Rollback()
+			if err != nil {
+				r.zapLogger.Errorf("error in transaction rollback: %s", err)
+			}
+		}
+	}()
+
+	var existingFilmID uint64
+	err = tx.QueryRowContext(ctx, "SELECT id FROM films WHERE id = $1", filmID).Scan(&existingFilmID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM film_titles WHERE film_id = $1", filmID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, title := range titles {
+		_, err = tx.ExecContext(ctx, "INSERT INTO film_titles (film_id, locale, title, is_original) VALUES ($1, $2, $3, $4)",
+			filmID, title.Locale, title.Title, title.IsOriginal)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -1,11 +1,13 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
 	"github.com/ilyushkaaa/Filmoteka/internal/films/entity"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -27,14 +29,23 @@ func TestGetFilmByID(t *testing.T) {
 		Description:   "Description 1",
 		DateOfRelease: time.Time{}.Add(time.Hour),
 		Rating:        8.5,
+		Titles: []entity.FilmTitle{
+			{Locale: "en", Title: "Film 1", IsOriginal: true},
+			{Locale: "ru", Title: "Фильм 1"},
+		},
 	}
 
-	mock.ExpectQuery("SELECT id, name, description, date_of_release, rating FROM films WHERE id = ?").
-		WithArgs(1).
+	mock.ExpectQuery("SELECT id, (.+) film_titles (.+) FROM films f WHERE id = ?").
+		WithArgs(1, "en").
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "date_of_release", "rating"}).
 			AddRow(1, "Film 1", "Description 1", time.Time{}.Add(time.Hour), 8.5))
+	mock.ExpectQuery("SELECT locale, title, is_original FROM film_titles WHERE film_id = (.+)").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"locale", "title", "is_original"}).
+			AddRow("en", "Film 1", true).
+			AddRow("ru", "Фильм 1", false))
 
-	film, err := repo.GetFilmByID(1)
+	film, err := repo.GetFilmByID(context.Background(), 1, []string{"en"})
 
 	assert.NoError(t, err, "unexpected error")
 	assert.Equal(t, expectedFilm, film, "films do not match expected")
@@ -42,11 +53,15 @@ func TestGetFilmByID(t *testing.T) {
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 
-	mock.ExpectQuery("SELECT id, name, description, date_of_release, rating FROM films WHERE id = ?").
+	mock.ExpectQuery("SELECT id, (.+) film_titles (.+) FROM films f WHERE id = ?").
+		WithArgs(1, "en").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "date_of_release", "rating"}).
+			AddRow(1, "Film 1", "Description 1", time.Time{}.Add(time.Hour), 8.5))
+	mock.ExpectQuery("SELECT locale, title, is_original FROM film_titles WHERE film_id = (.+)").
 		WithArgs(1).
 		WillReturnError(fmt.Errorf("error"))
 
-	film, err = repo.GetFilmByID(1)
+	film, err = repo.GetFilmByID(context.Background(), 1, []string{"en"})
 
 	var nilFilm *entity.Film
 	assert.Error(t, err)
@@ -54,6 +69,18 @@ func TestGetFilmByID(t *testing.T) {
 
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
+
+	mock.ExpectQuery("SELECT id, (.+) film_titles (.+) FROM films f WHERE id = ?").
+		WithArgs(1, "en").
+		WillReturnError(fmt.Errorf("error"))
+
+	film, err = repo.GetFilmByID(context.Background(), 1, []string{"en"})
+
+	assert.Error(t, err)
+	assert.Equal(t, nilFilm, film)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
 }
 
 func TestGetFilms(t *testing.T) {
@@ -70,45 +97,49 @@ func TestGetFilms(t *testing.T) {
 		{ID: 2, Name: "Film 2", Description: "Description 2", DateOfRelease: time.Time{}.Add(time.Hour), Rating: 7.8},
 	}
 
-	mock.ExpectQuery("SELECT f.id, f.name, f.description, f.date_of_release, f.rating FROM films f ORDER BY (.+) DESC").
+	mock.ExpectQuery("SELECT f.id, (.+) AS name, f.description, f.date_of_release, f.rating FROM films f ORDER BY (.+) DESC").
+		WithArgs("").
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "date_of_release", "rating"}).
 			AddRow(1, "Film 1", "Description 1", time.Time{}.Add(time.Hour), 8.5).
 			AddRow(2, "Film 2", "Description 2", time.Time{}.Add(time.Hour), 7.8))
 
-	films, err := repo.GetFilms("rating")
+	films, err := repo.GetFilms(context.Background(), "rating", nil)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedFilms, films)
 
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 
-	mock.ExpectQuery("SELECT f.id, f.name, f.description, f.date_of_release, f.rating FROM films f ORDER BY (.+) DESC").
+	mock.ExpectQuery("SELECT f.id, (.+) AS name, f.description, f.date_of_release, f.rating FROM films f ORDER BY (.+) DESC").
+		WithArgs("").
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "date_of_release", "rating"}).
 			AddRow(1, "Film 1", "Description 1", time.Time{}.Add(time.Hour), 8.5).
 			AddRow(2, "Film 2", "Description 2", time.Time{}.Add(time.Hour), 7.8))
 
-	films, err = repo.GetFilms("")
+	films, err = repo.GetFilms(context.Background(), "", nil)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedFilms, films)
 
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 
-	mock.ExpectQuery("SELECT f.id, f.name, f.description, f.date_of_release, f.rating FROM films f ORDER BY (.+) DESC").
+	mock.ExpectQuery("SELECT f.id, (.+) AS name, f.description, f.date_of_release, f.rating FROM films f ORDER BY (.+) DESC").
+		WithArgs("").
 		WillReturnError(fmt.Errorf("error"))
 
 	var nilFilms []entity.Film
-	films, err = repo.GetFilms("")
+	films, err = repo.GetFilms(context.Background(), "", nil)
 	assert.Error(t, err)
 	assert.Equal(t, nilFilms, films)
 
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 
-	mock.ExpectQuery("SELECT f.id, f.name, f.description, f.date_of_release, f.rating FROM films f ORDER BY (.+) DESC").
+	mock.ExpectQuery("SELECT f.id, (.+) AS name, f.description, f.date_of_release, f.rating FROM films f ORDER BY (.+) DESC").
+		WithArgs("").
 		WillReturnError(sql.ErrNoRows)
 
-	films, err = repo.GetFilms("")
+	films, err = repo.GetFilms(context.Background(), "", nil)
 	assert.NoError(t, err)
 	assert.Equal(t, nilFilms, films)
 
@@ -143,7 +174,7 @@ func TestAddFilm(t *testing.T) {
 
 	mock.ExpectCommit()
 
-	lastInsertID, err := repo.AddFilm(entity.Film{Name: "Film 1", Description: "Description 1", DateOfRelease: time.Time{}.Add(time.Hour), Rating: 8.5}, []uint64{1, 2})
+	lastInsertID, err := repo.AddFilm(context.Background(), entity.Film{Name: "Film 1", Description: "Description 1", DateOfRelease: time.Time{}.Add(time.Hour), Rating: 8.5}, []uint64{1, 2})
 
 	assert.NoError(t, err, "unexpected error")
 	assert.Equal(t, expectedLastInsertID, lastInsertID, "last insert ID does not match expected")
@@ -158,7 +189,7 @@ func TestAddFilm(t *testing.T) {
 
 	mock.ExpectRollback()
 
-	lastInsertID, err = repo.AddFilm(entity.Film{Name: "Film 1", Description: "Description 1", DateOfRelease: time.Time{}.Add(time.Hour), Rating: 8.5}, []uint64{1, 2})
+	lastInsertID, err = repo.AddFilm(context.Background(), entity.Film{Name: "Film 1", Description: "Description 1", DateOfRelease: time.Time{}.Add(time.Hour), Rating: 8.5}, []uint64{1, 2})
 
 	assert.Error(t, err)
 
@@ -178,7 +209,7 @@ func TestAddFilm(t *testing.T) {
 
 	mock.ExpectRollback()
 
-	lastInsertID, err = repo.AddFilm(entity.Film{Name: "Film 1", Description: "Description 1", DateOfRelease: time.Time{}.Add(time.Hour), Rating: 8.5}, []uint64{1, 2})
+	lastInsertID, err = repo.AddFilm(context.Background(), entity.Film{Name: "Film 1", Description: "Description 1", DateOfRelease: time.Time{}.Add(time.Hour), Rating: 8.5}, []uint64{1, 2})
 
 	assert.Error(t, err)
 
@@ -217,7 +248,7 @@ func TestUpdateFilm(t *testing.T) {
 
 	mock.ExpectCommit()
 
-	updated, err := repo.UpdateFilm(entity.Film{ID: filmID, Name: "Updated Film", Description: "Updated Description", DateOfRelease: time.Time{}.Add(time.Hour), Rating: 9.0}, []uint64{1, 2})
+	updated, err := repo.UpdateFilm(context.Background(), entity.Film{ID: filmID, Name: "Updated Film", Description: "Updated Description", DateOfRelease: time.Time{}.Add(time.Hour), Rating: 9.0}, []uint64{1, 2})
 
 	assert.NoError(t, err, "unexpected error")
 	assert.True(t, updated, "film was not updated successfully")
@@ -231,7 +262,7 @@ func TestUpdateFilm(t *testing.T) {
 
 	mock.ExpectRollback()
 
-	updated, err = repo.UpdateFilm(entity.Film{ID: filmID, Name: "Updated Film", Description: "Updated Description", DateOfRelease: time.Time{}.Add(time.Hour), Rating: 9.0}, []uint64{1, 2})
+	updated, err = repo.UpdateFilm(context.Background(), entity.Film{ID: filmID, Name: "Updated Film", Description: "Updated Description", DateOfRelease: time.Time{}.Add(time.Hour), Rating: 9.0}, []uint64{1, 2})
 
 	assert.Error(t, err)
 	assert.False(t, updated)
@@ -250,13 +281,13 @@ func TestGetFilmsBySearch(t *testing.T) {
 
 	searchStr := "Film"
 
-	mock.ExpectQuery("SELECT DISTINCT").
-		WithArgs(searchStr).
+	mock.ExpectQuery("SELECT DISTINCT (.+) FROM films f (.+) film_titles (.+) actor_names").
+		WithArgs(searchStr, "en,ru").
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "date_of_release", "rating"}).
 			AddRow(1, "Film 1", "Description 1", time.Time{}.Add(time.Hour), 8.0).
 			AddRow(2, "Film 2", "Description 2", time.Time{}.Add(time.Hour), 7.5))
 
-	films, err := repo.GetFilmsBySearch(searchStr)
+	films, err := repo.GetFilmsBySearch(context.Background(), searchStr, []string{"en", "ru"})
 
 	assert.NoError(t, err, "unexpected error")
 	assert.NotNil(t, films, "films list is nil")
@@ -265,11 +296,11 @@ func TestGetFilmsBySearch(t *testing.T) {
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 
-	mock.ExpectQuery("SELECT DISTINCT").
-		WithArgs(searchStr).
+	mock.ExpectQuery("SELECT DISTINCT (.+) FROM films f (.+) film_titles (.+) actor_names").
+		WithArgs(searchStr, "en,ru").
 		WillReturnError(fmt.Errorf("error"))
 
-	films, err = repo.GetFilmsBySearch(searchStr)
+	films, err = repo.GetFilmsBySearch(context.Background(), searchStr, []string{"en", "ru"})
 
 	assert.Error(t, err)
 	assert.Nil(t, films)
@@ -277,3 +308,179 @@ func TestGetFilmsBySearch(t *testing.T) {
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 }
+
+func TestFindDuplicateFilms(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	testRepo := NewFilmRepo(db, zap.NewNop().Sugar())
+	film := entity.Film{Name: "Film", DateOfRelease: time.Time{}.Add(time.Hour)}
+
+	mock.ExpectQuery("SELECT id FROM films WHERE (.+) SIMILARITY(.+)").
+		WithArgs(film.DateOfRelease, film.Name, filmSimilarityThreshold).
+		WillReturnError(fmt.Errorf("error"))
+	candidateIDs, err := testRepo.FindDuplicateFilms(context.Background(), film)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 0, len(candidateIDs))
+
+	mock.ExpectQuery("SELECT id FROM films WHERE (.+) SIMILARITY(.+)").
+		WithArgs(film.DateOfRelease, film.Name, filmSimilarityThreshold).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	candidateIDs, err = testRepo.FindDuplicateFilms(context.Background(), film)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []uint64{3}, candidateIDs)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFilmDuplicates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	testRepo := NewFilmRepo(db, zap.NewNop().Sugar())
+
+	mock.ExpectQuery("SELECT f1.id, f2.id, SIMILARITY(.+) FROM films f1 JOIN films f2 (.+)").
+		WithArgs(filmSimilarityThreshold).
+		WillReturnError(fmt.Errorf("error"))
+	duplicates, err := testRepo.GetFilmDuplicates(context.Background())
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 0, len(duplicates))
+
+	mock.ExpectQuery("SELECT f1.id, f2.id, SIMILARITY(.+) FROM films f1 JOIN films f2 (.+)").
+		WithArgs(filmSimilarityThreshold).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "id", "similarity"}).AddRow(1, 2, 0.75))
+	duplicates, err = testRepo.GetFilmDuplicates(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []dto.DuplicatePair{{OriginalID: 1, DuplicateID: 2, Similarity: 0.75}}, duplicates)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeFilms(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	testRepo := NewFilmRepo(db, zap.NewNop().Sugar())
+
+	var sourceID, targetID uint64 = 1, 2
+
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("error"))
+	wasMerged, err := testRepo.MergeFilms(context.Background(), sourceID, targetID)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, false, wasMerged)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COUNT(.+) FROM \\(SELECT id FROM films WHERE id IN (.+) ORDER BY id FOR UPDATE\\)").
+		WithArgs(sourceID, targetID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+	wasMerged, err = testRepo.MergeFilms(context.Background(), sourceID, targetID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, wasMerged)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COUNT(.+) FROM \\(SELECT id FROM films WHERE id IN (.+) ORDER BY id FOR UPDATE\\)").
+		WithArgs(sourceID, targetID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectExec("INSERT INTO film_actors (.+) SELECT (.+), actor_id FROM film_actors WHERE film_id (.+) ON CONFLICT DO NOTHING").
+		WithArgs(sourceID, targetID).
+		WillReturnError(fmt.Errorf("error"))
+	mock.ExpectRollback()
+	wasMerged, err = testRepo.MergeFilms(context.Background(), sourceID, targetID)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, false, wasMerged)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COUNT(.+) FROM \\(SELECT id FROM films WHERE id IN (.+) ORDER BY id FOR UPDATE\\)").
+		WithArgs(sourceID, targetID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectExec("INSERT INTO film_actors (.+) SELECT (.+), actor_id FROM film_actors WHERE film_id (.+) ON CONFLICT DO NOTHING").
+		WithArgs(sourceID, targetID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("DELETE FROM film_actors WHERE film_id = (.+)").
+		WithArgs(sourceID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO film_titles (.+) SELECT (.+) FROM film_titles WHERE film_id (.+) ON CONFLICT DO NOTHING").
+		WithArgs(sourceID, targetID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE film_aliases SET film_id = (.+) WHERE film_id = (.+)").
+		WithArgs(sourceID, targetID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO film_aliases (.+)").
+		WithArgs(sourceID, targetID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM films WHERE id = (.+)").
+		WithArgs(sourceID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	wasMerged, err = testRepo.MergeFilms(context.Background(), sourceID, targetID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, wasMerged)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetFilmTitles(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	testRepo := NewFilmRepo(db, zap.NewNop().Sugar())
+
+	var filmID uint64 = 1
+	titles := []entity.FilmTitle{
+		{Locale: "en", Title: "Film 1", IsOriginal: true},
+		{Locale: "ru", Title: "Фильм 1"},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM films WHERE id = (.+)").
+		WithArgs(filmID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	wasSet, err := testRepo.SetFilmTitles(context.Background(), filmID, titles)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, wasSet)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM films WHERE id = (.+)").
+		WithArgs(filmID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(filmID))
+	mock.ExpectExec("DELETE FROM film_titles WHERE film_id = (.+)").
+		WithArgs(filmID).
+		WillReturnError(fmt.Errorf("error"))
+	mock.ExpectRollback()
+	wasSet, err = testRepo.SetFilmTitles(context.Background(), filmID, titles)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, false, wasSet)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM films WHERE id = (.+)").
+		WithArgs(filmID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(filmID))
+	mock.ExpectExec("DELETE FROM film_titles WHERE film_id = (.+)").
+		WithArgs(filmID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, title := range titles {
+		mock.ExpectExec("INSERT INTO film_titles").
+			WithArgs(filmID, title.Locale, title.Title, title.IsOriginal).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+	wasSet, err = testRepo.SetFilmTitles(context.Background(), filmID, titles)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, wasSet)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -5,9 +5,11 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	dto "github.com/ilyushkaaa/Filmoteka/internal/dto"
 	entity "github.com/ilyushkaaa/Filmoteka/internal/films/entity"
 )
 
@@ -35,91 +37,151 @@ func (m *MockFilmRepo) EXPECT() *MockFilmRepoMockRecorder {
 }
 
 // AddFilm mocks base method.
-func (m *MockFilmRepo) AddFilm(film entity.Film, actorIDs []uint64) (uint64, error) {
+func (m *MockFilmRepo) AddFilm(ctx context.Context, film entity.Film, actorIDs []uint64) (uint64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddFilm", film, actorIDs)
+	ret := m.ctrl.Call(m, "AddFilm", ctx, film, actorIDs)
 	ret0, _ := ret[0].(uint64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddFilm indicates an expected call of AddFilm.
-func (mr *MockFilmRepoMockRecorder) AddFilm(film, actorIDs interface{}) *gomock.Call {
+func (mr *MockFilmRepoMockRecorder) AddFilm(ctx, film, actorIDs interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFilm", reflect.TypeOf((*MockFilmRepo)(nil).AddFilm), film, actorIDs)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFilm", reflect.TypeOf((*MockFilmRepo)(nil).AddFilm), ctx, film, actorIDs)
 }
 
 // DeleteFilm mocks base method.
-func (m *MockFilmRepo) DeleteFilm(ID uint64) (bool, error) {
+func (m *MockFilmRepo) DeleteFilm(ctx context.Context, ID uint64) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteFilm", ID)
+	ret := m.ctrl.Call(m, "DeleteFilm", ctx, ID)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DeleteFilm indicates an expected call of DeleteFilm.
-func (mr *MockFilmRepoMockRecorder) DeleteFilm(ID interface{}) *gomock.Call {
+func (mr *MockFilmRepoMockRecorder) DeleteFilm(ctx, ID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFilm", reflect.TypeOf((*MockFilmRepo)(nil).DeleteFilm), ID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFilm", reflect.TypeOf((*MockFilmRepo)(nil).DeleteFilm), ctx, ID)
+}
+
+// FindDuplicateFilms mocks base method.
+func (m *MockFilmRepo) FindDuplicateFilms(ctx context.Context, film entity.Film) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDuplicateFilms", ctx, film)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDuplicateFilms indicates an expected call of FindDuplicateFilms.
+func (mr *MockFilmRepoMockRecorder) FindDuplicateFilms(ctx, film interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDuplicateFilms", reflect.TypeOf((*MockFilmRepo)(nil).FindDuplicateFilms), ctx, film)
 }
 
 // GetFilmByID mocks base method.
-func (m *MockFilmRepo) GetFilmByID(filmID uint64) (*entity.Film, error) {
+func (m *MockFilmRepo) GetFilmByID(ctx context.Context, filmID uint64, langs []string) (*entity.Film, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFilmByID", filmID)
+	ret := m.ctrl.Call(m, "GetFilmByID", ctx, filmID, langs)
 	ret0, _ := ret[0].(*entity.Film)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFilmByID indicates an expected call of GetFilmByID.
-func (mr *MockFilmRepoMockRecorder) GetFilmByID(filmID interface{}) *gomock.Call {
+func (mr *MockFilmRepoMockRecorder) GetFilmByID(ctx, filmID, langs interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmByID", reflect.TypeOf((*MockFilmRepo)(nil).GetFilmByID), filmID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmByID", reflect.TypeOf((*MockFilmRepo)(nil).GetFilmByID), ctx, filmID, langs)
+}
+
+// GetFilmDuplicates mocks base method.
+func (m *MockFilmRepo) GetFilmDuplicates(ctx context.Context) ([]dto.DuplicatePair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilmDuplicates", ctx)
+	ret0, _ := ret[0].([]dto.DuplicatePair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilmDuplicates indicates an expected call of GetFilmDuplicates.
+func (mr *MockFilmRepoMockRecorder) GetFilmDuplicates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmDuplicates", reflect.TypeOf((*MockFilmRepo)(nil).GetFilmDuplicates), ctx)
 }
 
 // GetFilms mocks base method.
-func (m *MockFilmRepo) GetFilms(sortParam string) ([]entity.Film, error) {
+func (m *MockFilmRepo) GetFilms(ctx context.Context, sortParam string, langs []string) ([]entity.Film, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFilms", sortParam)
+	ret := m.ctrl.Call(m, "GetFilms", ctx, sortParam, langs)
 	ret0, _ := ret[0].([]entity.Film)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFilms indicates an expected call of GetFilms.
-func (mr *MockFilmRepoMockRecorder) GetFilms(sortParam interface{}) *gomock.Call {
+func (mr *MockFilmRepoMockRecorder) GetFilms(ctx, sortParam, langs interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilms", reflect.TypeOf((*MockFilmRepo)(nil).GetFilms), sortParam)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilms", reflect.TypeOf((*MockFilmRepo)(nil).GetFilms), ctx, sortParam, langs)
 }
 
 // GetFilmsBySearch mocks base method.
-func (m *MockFilmRepo) GetFilmsBySearch(searchStr string) ([]entity.Film, error) {
+func (m *MockFilmRepo) GetFilmsBySearch(ctx context.Context, searchStr string, langs []string) ([]entity.Film, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFilmsBySearch", searchStr)
+	ret := m.ctrl.Call(m, "GetFilmsBySearch", ctx, searchStr, langs)
 	ret0, _ := ret[0].([]entity.Film)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFilmsBySearch indicates an expected call of GetFilmsBySearch.
-func (mr *MockFilmRepoMockRecorder) GetFilmsBySearch(searchStr interface{}) *gomock.Call {
+func (mr *MockFilmRepoMockRecorder) GetFilmsBySearch(ctx, searchStr, langs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmsBySearch", reflect.TypeOf((*MockFilmRepo)(nil).GetFilmsBySearch), ctx, searchStr, langs)
+}
+
+// MergeFilms mocks base method.
+func (m *MockFilmRepo) MergeFilms(ctx context.Context, sourceID, targetID uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeFilms", ctx, sourceID, targetID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeFilms indicates an expected call of MergeFilms.
+func (mr *MockFilmRepoMockRecorder) MergeFilms(ctx, sourceID, targetID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeFilms", reflect.TypeOf((*MockFilmRepo)(nil).MergeFilms), ctx, sourceID, targetID)
+}
+
+// SetFilmTitles mocks base method.
+func (m *MockFilmRepo) SetFilmTitles(ctx context.Context, filmID uint64, titles []entity.FilmTitle) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFilmTitles", ctx, filmID, titles)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFilmTitles indicates an expected call of SetFilmTitles.
+func (mr *MockFilmRepoMockRecorder) SetFilmTitles(ctx, filmID, titles interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmsBySearch", reflect.TypeOf((*MockFilmRepo)(nil).GetFilmsBySearch), searchStr)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFilmTitles", reflect.TypeOf((*MockFilmRepo)(nil).SetFilmTitles), ctx, filmID, titles)
 }
 
 // UpdateFilm mocks base method.
-func (m *MockFilmRepo) UpdateFilm(film entity.Film, actorIDs []uint64) (bool, error) {
+func (m *MockFilmRepo) UpdateFilm(ctx context.Context, film entity.Film, actorIDs []uint64) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateFilm", film, actorIDs)
+	ret := m.ctrl.Call(m, "UpdateFilm", ctx, film, actorIDs)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateFilm indicates an expected call of UpdateFilm.
-func (mr *MockFilmRepoMockRecorder) UpdateFilm(film, actorIDs interface{}) *gomock.Call {
+func (mr *MockFilmRepoMockRecorder) UpdateFilm(ctx, film, actorIDs interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFilm", reflect.TypeOf((*MockFilmRepo)(nil).UpdateFilm), film, actorIDs)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFilm", reflect.TypeOf((*MockFilmRepo)(nil).UpdateFilm), ctx, film, actorIDs)
 }
@@ -1,10 +1,27 @@
 package usecase
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrFilmsNotFound     = errors.New("films for this search were not found")
 	ErrFilmNotFound      = errors.New("film with such id does not exist")
 	ErrBadFilmUpdateData = errors.New("invalid data to update film")
 	ErrBadFilmAddData    = errors.New("invalid data to add film")
+	ErrFilmDuplicate     = errors.New("film with similar name and release year already exists")
+	ErrSameFilmMerge     = errors.New("film can not be merged with itself")
 )
+
+type DuplicateFilmError struct {
+	CandidateIDs []uint64
+}
+
+func (e *DuplicateFilmError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrFilmDuplicate, e.CandidateIDs)
+}
+
+func (e *DuplicateFilmError) Unwrap() error {
+	return ErrFilmDuplicate
+}
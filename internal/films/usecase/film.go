@@ -1,18 +1,24 @@
 package usecase
 
 import (
+	"context"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
 	"github.com/ilyushkaaa/Filmoteka/internal/films/entity"
 	"github.com/ilyushkaaa/Filmoteka/internal/films/repo"
 )
 
 //go:generate mockgen -source=film.go -destination=film_mock.go -package=usecase FilmUseCase
 type FilmUseCase interface {
-	GetFilms(sortParam string) ([]entity.Film, error)
-	GetFilmByID(filmID uint64) (*entity.Film, error)
-	AddFilm(film entity.Film, actorIDs []uint64) (*entity.Film, error)
-	UpdateFilm(film entity.Film, actorIDs []uint64) error
-	GetFilmsBySearch(searchStr string) ([]entity.Film, error)
-	DeleteFilm(ID uint64) error
+	GetFilms(ctx context.Context, sortParam string, langs []string) ([]entity.Film, error)
+	GetFilmByID(ctx context.Context, filmID uint64, langs []string) (*entity.Film, error)
+	AddFilm(ctx context.Context, film entity.Film, actorIDs []uint64, force bool) (*entity.Film, error)
+	UpdateFilm(ctx context.Context, film entity.Film, actorIDs []uint64) error
+	GetFilmsBySearch(ctx context.Context, searchStr string, langs []string) ([]entity.Film, error)
+	DeleteFilm(ctx context.Context, ID uint64) error
+	GetFilmDuplicates(ctx context.Context) ([]dto.DuplicatePair, error)
+	MergeFilms(ctx context.Context, sourceID, targetID uint64) (*entity.Film, error)
+	SetFilmTitles(ctx context.Context, filmID uint64, titles []entity.FilmTitle) error
 }
 
 type FilmUseCaseApp struct {
@@ -25,16 +31,16 @@ func NewFilmUseCase(filmRepo repo.FilmRepo) *FilmUseCaseApp {
 	}
 }
 
-func (r *FilmUseCaseApp) GetFilms(sortParam string) ([]entity.Film, error) {
-	films, err := r.filmRepo.GetFilms(sortParam)
+func (r *FilmUseCaseApp) GetFilms(ctx context.Context, sortParam string, langs []string) ([]entity.Film, error) {
+	films, err := r.filmRepo.GetFilms(ctx, sortParam, langs)
 	if err != nil {
 		return nil, err
 	}
 	return films, nil
 }
 
-func (r *FilmUseCaseApp) GetFilmByID(filmID uint64) (*entity.Film, error) {
-	film, err := r.filmRepo.GetFilmByID(filmID)
+func (r *FilmUseCaseApp) GetFilmByID(ctx context.Context, filmID uint64, langs []string) (*entity.Film, error) {
+	film, err := r.filmRepo.GetFilmByID(ctx, filmID, langs)
 	if err != nil {
 		return nil, err
 	}
@@ -44,8 +50,17 @@ func (r *FilmUseCaseApp) GetFilmByID(filmID uint64) (*entity.Film, error) {
 	return film, nil
 }
 
-func (r *FilmUseCaseApp) AddFilm(film entity.Film, actorIDs []uint64) (*entity.Film, error) {
-	filmID, err := r.filmRepo.AddFilm(film, actorIDs)
+func (r *FilmUseCaseApp) AddFilm(ctx context.Context, film entity.Film, actorIDs []uint64, force bool) (*entity.Film, error) {
+	if !force {
+		candidateIDs, err := r.filmRepo.FindDuplicateFilms(ctx, film)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidateIDs) != 0 {
+			return nil, &DuplicateFilmError{CandidateIDs: candidateIDs}
+		}
+	}
+	filmID, err := r.filmRepo.AddFilm(ctx, film, actorIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -56,8 +71,8 @@ func (r *FilmUseCaseApp) AddFilm(film entity.Film, actorIDs []uint64) (*entity.F
 	return &film, nil
 }
 
-func (r *FilmUseCaseApp) UpdateFilm(film entity.Film, actorIDs []uint64) error {
-	wasUpdated, err := r.filmRepo.UpdateFilm(film, actorIDs)
+func (r *FilmUseCaseApp) UpdateFilm(ctx context.Context, film entity.Film, actorIDs []uint64) error {
+	wasUpdated, err := r.filmRepo.UpdateFilm(ctx, film, actorIDs)
 	if err != nil {
 		return err
 	}
@@ -67,8 +82,8 @@ func (r *FilmUseCaseApp) UpdateFilm(film entity.Film, actorIDs []uint64) error {
 	return nil
 }
 
-func (r *FilmUseCaseApp) GetFilmsBySearch(searchStr string) ([]entity.Film, error) {
-	films, err := r.filmRepo.GetFilmsBySearch(searchStr)
+func (r *FilmUseCaseApp) GetFilmsBySearch(ctx context.Context, searchStr string, langs []string) ([]entity.Film, error) {
+	films, err := r.filmRepo.GetFilmsBySearch(ctx, searchStr, langs)
 	if err != nil {
 		return nil, err
 	}
@@ -78,8 +93,8 @@ func (r *FilmUseCaseApp) GetFilmsBySearch(searchStr string) ([]entity.Film, erro
 	return films, nil
 }
 
-func (r *FilmUseCaseApp) DeleteFilm(ID uint64) error {
-	wasDeleted, err := r.filmRepo.DeleteFilm(ID)
+func (r *FilmUseCaseApp) DeleteFilm(ctx context.Context, ID uint64) error {
+	wasDeleted, err := r.filmRepo.DeleteFilm(ctx, ID)
 	if err != nil {
 		return err
 	}
@@ -88,3 +103,43 @@ func (r *FilmUseCaseApp) DeleteFilm(ID uint64) error {
 	}
 	return nil
 }
+
+func (r *FilmUseCaseApp) GetFilmDuplicates(ctx context.Context) ([]dto.DuplicatePair, error) {
+	duplicates, err := r.filmRepo.GetFilmDuplicates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return duplicates, nil
+}
+
+func (r *FilmUseCaseApp) MergeFilms(ctx context.Context, sourceID, targetID uint64) (*entity.Film, error) {
+	if sourceID == targetID {
+		return nil, ErrSameFilmMerge
+	}
+	wasMerged, err := r.filmRepo.MergeFilms(ctx, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if !wasMerged {
+		return nil, ErrFilmNotFound
+	}
+	mergedFilm, err := r.filmRepo.GetFilmByID(ctx, targetID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if mergedFilm == nil {
+		return nil, ErrFilmNotFound
+	}
+	return mergedFilm, nil
+}
+
+func (r *FilmUseCaseApp) SetFilmTitles(ctx context.Context, filmID uint64, titles []entity.FilmTitle) error {
+	wasSet, err := r.filmRepo.SetFilmTitles(ctx, filmID, titles)
+	if err != nil {
+		return err
+	}
+	if !wasSet {
+		return ErrFilmNotFound
+	}
+	return nil
+}
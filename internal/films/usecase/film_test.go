@@ -1,10 +1,12 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
 	"github.com/ilyushkaaa/Filmoteka/internal/films/entity"
 	"github.com/ilyushkaaa/Filmoteka/internal/films/repo/mock"
 	"github.com/stretchr/testify/assert"
@@ -18,16 +20,16 @@ func TestGetFilms(t *testing.T) {
 	testUseCase := NewFilmUseCase(testRepo)
 
 	var filmsExpected []entity.Film
-	testRepo.EXPECT().GetFilms("").
+	testRepo.EXPECT().GetFilms(gomock.Any(), "", nil).
 		Return(nil, fmt.Errorf("error"))
-	films, err := testUseCase.GetFilms("")
+	films, err := testUseCase.GetFilms(context.Background(), "", nil)
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, filmsExpected, films)
 
 	filmsResult := make([]entity.Film, 0)
-	testRepo.EXPECT().GetFilms("").
+	testRepo.EXPECT().GetFilms(gomock.Any(), "", nil).
 		Return(filmsResult, nil)
-	films, err = testUseCase.GetFilms("")
+	films, err = testUseCase.GetFilms(context.Background(), "", nil)
 	assert.Equal(t, nil, err)
 	assert.Equal(t, filmsResult, films)
 
@@ -42,9 +44,9 @@ func TestGetFilmByID(t *testing.T) {
 
 	var id uint64 = 1
 	var filmExpected *entity.Film
-	testRepo.EXPECT().GetFilmByID(id).
+	testRepo.EXPECT().GetFilmByID(gomock.Any(), id, nil).
 		Return(nil, fmt.Errorf("error"))
-	film, err := testUseCase.GetFilmByID(id)
+	film, err := testUseCase.GetFilmByID(context.Background(), id, nil)
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, filmExpected, film)
 
@@ -53,15 +55,15 @@ func TestGetFilmByID(t *testing.T) {
 		Name:        "aaa",
 		Description: "aaa",
 	}
-	testRepo.EXPECT().GetFilmByID(id).
+	testRepo.EXPECT().GetFilmByID(gomock.Any(), id, nil).
 		Return(filmResult, nil)
-	film, err = testUseCase.GetFilmByID(id)
+	film, err = testUseCase.GetFilmByID(context.Background(), id, nil)
 	assert.Equal(t, nil, err)
 	assert.Equal(t, filmResult, film)
 
-	testRepo.EXPECT().GetFilmByID(id).
+	testRepo.EXPECT().GetFilmByID(gomock.Any(), id, nil).
 		Return(nil, nil)
-	film, err = testUseCase.GetFilmByID(id)
+	film, err = testUseCase.GetFilmByID(context.Background(), id, nil)
 	assert.Equal(t, ErrFilmNotFound, err)
 	assert.Equal(t, filmExpected, film)
 
@@ -80,21 +82,38 @@ func TestAddFilm(t *testing.T) {
 	filmToAdd := entity.Film{}
 	actorIDsToAdd := make([]uint64, 0)
 
-	testRepo.EXPECT().AddFilm(filmToAdd, actorIDsToAdd).
+	testRepo.EXPECT().FindDuplicateFilms(gomock.Any(), filmToAdd).
+		Return(nil, fmt.Errorf("error"))
+	film, err := testUseCase.AddFilm(context.Background(), filmToAdd, actorIDsToAdd, false)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, filmExpected, film)
+
+	testRepo.EXPECT().FindDuplicateFilms(gomock.Any(), filmToAdd).
+		Return([]uint64{2}, nil)
+	film, err = testUseCase.AddFilm(context.Background(), filmToAdd, actorIDsToAdd, false)
+	assert.ErrorIs(t, err, ErrFilmDuplicate)
+	var duplicateErr *DuplicateFilmError
+	assert.ErrorAs(t, err, &duplicateErr)
+	assert.Equal(t, []uint64{2}, duplicateErr.CandidateIDs)
+	assert.Equal(t, filmExpected, film)
+
+	testRepo.EXPECT().FindDuplicateFilms(gomock.Any(), filmToAdd).
+		Return([]uint64{}, nil)
+	testRepo.EXPECT().AddFilm(gomock.Any(), filmToAdd, actorIDsToAdd).
 		Return(idNull, fmt.Errorf("error"))
-	film, err := testUseCase.AddFilm(filmToAdd, actorIDsToAdd)
+	film, err = testUseCase.AddFilm(context.Background(), filmToAdd, actorIDsToAdd, false)
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, filmExpected, film)
 
-	testRepo.EXPECT().AddFilm(filmToAdd, actorIDsToAdd).
+	testRepo.EXPECT().AddFilm(gomock.Any(), filmToAdd, actorIDsToAdd).
 		Return(idNull, nil)
-	film, err = testUseCase.AddFilm(filmToAdd, actorIDsToAdd)
+	film, err = testUseCase.AddFilm(context.Background(), filmToAdd, actorIDsToAdd, true)
 	assert.Equal(t, ErrBadFilmAddData, err)
 	assert.Equal(t, filmExpected, film)
 
-	testRepo.EXPECT().AddFilm(filmToAdd, actorIDsToAdd).
+	testRepo.EXPECT().AddFilm(gomock.Any(), filmToAdd, actorIDsToAdd).
 		Return(id, nil)
-	film, err = testUseCase.AddFilm(filmToAdd, actorIDsToAdd)
+	film, err = testUseCase.AddFilm(context.Background(), filmToAdd, actorIDsToAdd, true)
 	assert.Equal(t, nil, err)
 	filmToAdd.ID = 1
 	assert.Equal(t, &filmToAdd, film)
@@ -111,19 +130,19 @@ func TestUpdateFilm(t *testing.T) {
 	filmToUpdate := entity.Film{}
 	actorIDsToAdd := make([]uint64, 0)
 
-	testRepo.EXPECT().UpdateFilm(filmToUpdate, actorIDsToAdd).
+	testRepo.EXPECT().UpdateFilm(gomock.Any(), filmToUpdate, actorIDsToAdd).
 		Return(false, fmt.Errorf("error"))
-	err := testUseCase.UpdateFilm(filmToUpdate, actorIDsToAdd)
+	err := testUseCase.UpdateFilm(context.Background(), filmToUpdate, actorIDsToAdd)
 	assert.NotEqual(t, nil, err)
 
-	testRepo.EXPECT().UpdateFilm(filmToUpdate, actorIDsToAdd).
+	testRepo.EXPECT().UpdateFilm(gomock.Any(), filmToUpdate, actorIDsToAdd).
 		Return(false, nil)
-	err = testUseCase.UpdateFilm(filmToUpdate, actorIDsToAdd)
+	err = testUseCase.UpdateFilm(context.Background(), filmToUpdate, actorIDsToAdd)
 	assert.Equal(t, ErrBadFilmUpdateData, err)
 
-	testRepo.EXPECT().UpdateFilm(filmToUpdate, actorIDsToAdd).
+	testRepo.EXPECT().UpdateFilm(gomock.Any(), filmToUpdate, actorIDsToAdd).
 		Return(true, nil)
-	err = testUseCase.UpdateFilm(filmToUpdate, actorIDsToAdd)
+	err = testUseCase.UpdateFilm(context.Background(), filmToUpdate, actorIDsToAdd)
 	assert.Equal(t, nil, err)
 }
 
@@ -135,18 +154,99 @@ func TestDeleteFilm(t *testing.T) {
 	testUseCase := NewFilmUseCase(testRepo)
 
 	var id uint64 = 1
-	testRepo.EXPECT().DeleteFilm(id).
+	testRepo.EXPECT().DeleteFilm(gomock.Any(), id).
+		Return(false, fmt.Errorf("error"))
+	err := testUseCase.DeleteFilm(context.Background(), id)
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().DeleteFilm(gomock.Any(), id).
+		Return(false, nil)
+	err = testUseCase.DeleteFilm(context.Background(), id)
+	assert.Equal(t, ErrFilmNotFound, err)
+
+	testRepo.EXPECT().DeleteFilm(gomock.Any(), id).
+		Return(true, nil)
+	err = testUseCase.DeleteFilm(context.Background(), id)
+	assert.Equal(t, nil, err)
+}
+
+func TestGetFilmDuplicates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockFilmRepo(ctrl)
+	testUseCase := NewFilmUseCase(testRepo)
+
+	var duplicatesExpected []dto.DuplicatePair
+	testRepo.EXPECT().GetFilmDuplicates(gomock.Any()).
+		Return(nil, fmt.Errorf("error"))
+	duplicates, err := testUseCase.GetFilmDuplicates(context.Background())
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, duplicatesExpected, duplicates)
+
+	duplicatesResult := []dto.DuplicatePair{{OriginalID: 1, DuplicateID: 2, Similarity: 0.8}}
+	testRepo.EXPECT().GetFilmDuplicates(gomock.Any()).
+		Return(duplicatesResult, nil)
+	duplicates, err = testUseCase.GetFilmDuplicates(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, duplicatesResult, duplicates)
+}
+
+func TestMergeFilms(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockFilmRepo(ctrl)
+	testUseCase := NewFilmUseCase(testRepo)
+
+	var sourceID, targetID uint64 = 1, 2
+	var filmExpected *entity.Film
+
+	merged, err := testUseCase.MergeFilms(context.Background(), sourceID, sourceID)
+	assert.Equal(t, ErrSameFilmMerge, err)
+	assert.Equal(t, filmExpected, merged)
+
+	testRepo.EXPECT().MergeFilms(gomock.Any(), sourceID, targetID).
 		Return(false, fmt.Errorf("error"))
-	err := testUseCase.DeleteFilm(id)
+	merged, err = testUseCase.MergeFilms(context.Background(), sourceID, targetID)
 	assert.NotEqual(t, nil, err)
+	assert.Equal(t, filmExpected, merged)
 
-	testRepo.EXPECT().DeleteFilm(id).
+	testRepo.EXPECT().MergeFilms(gomock.Any(), sourceID, targetID).
 		Return(false, nil)
-	err = testUseCase.DeleteFilm(id)
+	merged, err = testUseCase.MergeFilms(context.Background(), sourceID, targetID)
 	assert.Equal(t, ErrFilmNotFound, err)
+	assert.Equal(t, filmExpected, merged)
 
-	testRepo.EXPECT().DeleteFilm(id).
+	filmResult := &entity.Film{}
+	testRepo.EXPECT().MergeFilms(gomock.Any(), sourceID, targetID).
 		Return(true, nil)
-	err = testUseCase.DeleteFilm(id)
+	testRepo.EXPECT().GetFilmByID(gomock.Any(), targetID, nil).
+		Return(filmResult, nil)
+	merged, err = testUseCase.MergeFilms(context.Background(), sourceID, targetID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, filmResult, merged)
+}
+
+func TestSetFilmTitles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockFilmRepo(ctrl)
+	testUseCase := NewFilmUseCase(testRepo)
+
+	var filmID uint64 = 1
+	items := []entity.FilmTitle{{Locale: "ru", Title: "Фильм", IsOriginal: true}}
+
+	testRepo.EXPECT().SetFilmTitles(gomock.Any(), filmID, items).Return(false, fmt.Errorf("error"))
+	err := testUseCase.SetFilmTitles(context.Background(), filmID, items)
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().SetFilmTitles(gomock.Any(), filmID, items).Return(false, nil)
+	err = testUseCase.SetFilmTitles(context.Background(), filmID, items)
+	assert.Equal(t, ErrFilmNotFound, err)
+
+	testRepo.EXPECT().SetFilmTitles(gomock.Any(), filmID, items).Return(true, nil)
+	err = testUseCase.SetFilmTitles(context.Background(), filmID, items)
 	assert.Equal(t, nil, err)
 }
@@ -5,9 +5,11 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	dto "github.com/ilyushkaaa/Filmoteka/internal/dto"
 	entity "github.com/ilyushkaaa/Filmoteka/internal/films/entity"
 )
 
@@ -35,89 +37,133 @@ func (m *MockFilmUseCase) EXPECT() *MockFilmUseCaseMockRecorder {
 }
 
 // AddFilm mocks base method.
-func (m *MockFilmUseCase) AddFilm(film entity.Film, actorIDs []uint64) (*entity.Film, error) {
+func (m *MockFilmUseCase) AddFilm(ctx context.Context, film entity.Film, actorIDs []uint64, force bool) (*entity.Film, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddFilm", film, actorIDs)
+	ret := m.ctrl.Call(m, "AddFilm", ctx, film, actorIDs, force)
 	ret0, _ := ret[0].(*entity.Film)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddFilm indicates an expected call of AddFilm.
-func (mr *MockFilmUseCaseMockRecorder) AddFilm(film, actorIDs interface{}) *gomock.Call {
+func (mr *MockFilmUseCaseMockRecorder) AddFilm(ctx, film, actorIDs, force interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFilm", reflect.TypeOf((*MockFilmUseCase)(nil).AddFilm), film, actorIDs)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFilm", reflect.TypeOf((*MockFilmUseCase)(nil).AddFilm), ctx, film, actorIDs, force)
 }
 
 // DeleteFilm mocks base method.
-func (m *MockFilmUseCase) DeleteFilm(ID uint64) error {
+func (m *MockFilmUseCase) DeleteFilm(ctx context.Context, ID uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteFilm", ID)
+	ret := m.ctrl.Call(m, "DeleteFilm", ctx, ID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteFilm indicates an expected call of DeleteFilm.
-func (mr *MockFilmUseCaseMockRecorder) DeleteFilm(ID interface{}) *gomock.Call {
+func (mr *MockFilmUseCaseMockRecorder) DeleteFilm(ctx, ID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFilm", reflect.TypeOf((*MockFilmUseCase)(nil).DeleteFilm), ID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFilm", reflect.TypeOf((*MockFilmUseCase)(nil).DeleteFilm), ctx, ID)
 }
 
 // GetFilmByID mocks base method.
-func (m *MockFilmUseCase) GetFilmByID(filmID uint64) (*entity.Film, error) {
+func (m *MockFilmUseCase) GetFilmByID(ctx context.Context, filmID uint64, langs []string) (*entity.Film, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFilmByID", filmID)
+	ret := m.ctrl.Call(m, "GetFilmByID", ctx, filmID, langs)
 	ret0, _ := ret[0].(*entity.Film)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFilmByID indicates an expected call of GetFilmByID.
-func (mr *MockFilmUseCaseMockRecorder) GetFilmByID(filmID interface{}) *gomock.Call {
+func (mr *MockFilmUseCaseMockRecorder) GetFilmByID(ctx, filmID, langs interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmByID", reflect.TypeOf((*MockFilmUseCase)(nil).GetFilmByID), filmID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmByID", reflect.TypeOf((*MockFilmUseCase)(nil).GetFilmByID), ctx, filmID, langs)
+}
+
+// GetFilmDuplicates mocks base method.
+func (m *MockFilmUseCase) GetFilmDuplicates(ctx context.Context) ([]dto.DuplicatePair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilmDuplicates", ctx)
+	ret0, _ := ret[0].([]dto.DuplicatePair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilmDuplicates indicates an expected call of GetFilmDuplicates.
+func (mr *MockFilmUseCaseMockRecorder) GetFilmDuplicates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmDuplicates", reflect.TypeOf((*MockFilmUseCase)(nil).GetFilmDuplicates), ctx)
 }
 
 // GetFilms mocks base method.
-func (m *MockFilmUseCase) GetFilms(sortParam string) ([]entity.Film, error) {
+func (m *MockFilmUseCase) GetFilms(ctx context.Context, sortParam string, langs []string) ([]entity.Film, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFilms", sortParam)
+	ret := m.ctrl.Call(m, "GetFilms", ctx, sortParam, langs)
 	ret0, _ := ret[0].([]entity.Film)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFilms indicates an expected call of GetFilms.
-func (mr *MockFilmUseCaseMockRecorder) GetFilms(sortParam interface{}) *gomock.Call {
+func (mr *MockFilmUseCaseMockRecorder) GetFilms(ctx, sortParam, langs interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilms", reflect.TypeOf((*MockFilmUseCase)(nil).GetFilms), sortParam)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilms", reflect.TypeOf((*MockFilmUseCase)(nil).GetFilms), ctx, sortParam, langs)
 }
 
 // GetFilmsBySearch mocks base method.
-func (m *MockFilmUseCase) GetFilmsBySearch(searchStr string) ([]entity.Film, error) {
+func (m *MockFilmUseCase) GetFilmsBySearch(ctx context.Context, searchStr string, langs []string) ([]entity.Film, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFilmsBySearch", searchStr)
+	ret := m.ctrl.Call(m, "GetFilmsBySearch", ctx, searchStr, langs)
 	ret0, _ := ret[0].([]entity.Film)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFilmsBySearch indicates an expected call of GetFilmsBySearch.
-func (mr *MockFilmUseCaseMockRecorder) GetFilmsBySearch(searchStr interface{}) *gomock.Call {
+func (mr *MockFilmUseCaseMockRecorder) GetFilmsBySearch(ctx, searchStr, langs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmsBySearch", reflect.TypeOf((*MockFilmUseCase)(nil).GetFilmsBySearch), ctx, searchStr, langs)
+}
+
+// MergeFilms mocks base method.
+func (m *MockFilmUseCase) MergeFilms(ctx context.Context, sourceID, targetID uint64) (*entity.Film, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeFilms", ctx, sourceID, targetID)
+	ret0, _ := ret[0].(*entity.Film)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeFilms indicates an expected call of MergeFilms.
+func (mr *MockFilmUseCaseMockRecorder) MergeFilms(ctx, sourceID, targetID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeFilms", reflect.TypeOf((*MockFilmUseCase)(nil).MergeFilms), ctx, sourceID, targetID)
+}
+
+// SetFilmTitles mocks base method.
+func (m *MockFilmUseCase) SetFilmTitles(ctx context.Context, filmID uint64, titles []entity.FilmTitle) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFilmTitles", ctx, filmID, titles)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFilmTitles indicates an expected call of SetFilmTitles.
+func (mr *MockFilmUseCaseMockRecorder) SetFilmTitles(ctx, filmID, titles interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmsBySearch", reflect.TypeOf((*MockFilmUseCase)(nil).GetFilmsBySearch), searchStr)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFilmTitles", reflect.TypeOf((*MockFilmUseCase)(nil).SetFilmTitles), ctx, filmID, titles)
 }
 
 // UpdateFilm mocks base method.
-func (m *MockFilmUseCase) UpdateFilm(film entity.Film, actorIDs []uint64) error {
+func (m *MockFilmUseCase) UpdateFilm(ctx context.Context, film entity.Film, actorIDs []uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateFilm", film, actorIDs)
+	ret := m.ctrl.Call(m, "UpdateFilm", ctx, film, actorIDs)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateFilm indicates an expected call of UpdateFilm.
-func (mr *MockFilmUseCaseMockRecorder) UpdateFilm(film, actorIDs interface{}) *gomock.Call {
+func (mr *MockFilmUseCaseMockRecorder) UpdateFilm(ctx, film, actorIDs interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFilm", reflect.TypeOf((*MockFilmUseCase)(nil).UpdateFilm), film, actorIDs)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFilm", reflect.TypeOf((*MockFilmUseCase)(nil).UpdateFilm), ctx, film, actorIDs)
 }
@@ -0,0 +1,91 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusDraining    = "draining"
+	StatusUnavailable = "unavailable"
+
+	checkTimeout = 2 * time.Second
+)
+
+type Check func(ctx context.Context) error
+
+type HealthHandler struct {
+	checks   map[string]Check
+	draining atomic.Bool
+}
+
+func NewHealthHandler(checks map[string]Check) *HealthHandler {
+	return &HealthHandler{
+		checks: checks,
+	}
+}
+
+func (h *HealthHandler) StartDraining() {
+	h.draining.Store(true)
+}
+
+// Live @Summary Проверка живости
+// @Description Сервер запущен и обрабатывает запросы
+// @Tags health
+// @Produce json
+// @Success 200 {object} dto.HealthResponse "Сервер работает"
+// @Router /healthz [get]
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	writeHealthResponse(w, &dto.HealthResponse{Status: StatusOK}, http.StatusOK)
+}
+
+// Ready @Summary Проверка готовности
+// @Description Сервер готов принимать запросы: он не останавливается, а PostgreSQL и Redis доступны
+// @Tags health
+// @Produce json
+// @Success 200 {object} dto.HealthResponse "Сервер готов"
+// @Failure 503 {object} dto.HealthResponse "Сервер останавливается или зависимости недоступны"
+// @Router /readyz [get]
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	if h.draining.Load() {
+		writeHealthResponse(w, &dto.HealthResponse{Status: StatusDraining}, http.StatusServiceUnavailable)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+	defer cancel()
+	var failed []string
+	for name, check := range h.checks {
+		if err := check(ctx); err != nil {
+			log.Printf("readiness check %s failed: %s", name, err)
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) != 0 {
+		sort.Strings(failed)
+		writeHealthResponse(w, &dto.HealthResponse{Status: StatusUnavailable, Failed: failed},
+			http.StatusServiceUnavailable)
+		return
+	}
+	writeHealthResponse(w, &dto.HealthResponse{Status: StatusOK}, http.StatusOK)
+}
+
+func writeHealthResponse(w http.ResponseWriter, healthResponse *dto.HealthResponse, statusCode int) {
+	healthResponseJSON, err := json.Marshal(healthResponse)
+	if err != nil {
+		log.Printf("error in marshalling health response: %s", err)
+		return
+	}
+	err = response.WriteResponse(w, healthResponseJSON, statusCode)
+	if err != nil {
+		log.Printf("can not write response: %s", err)
+	}
+}
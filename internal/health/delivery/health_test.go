@@ -0,0 +1,88 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func doHealthRequest(t *testing.T, handler http.HandlerFunc) (int, *dto.HealthResponse) {
+	request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	respWriter := httptest.NewRecorder()
+	handler(respWriter, request)
+	resp := respWriter.Result()
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+	}()
+	healthResponse := &dto.HealthResponse{}
+	err := json.NewDecoder(resp.Body).Decode(healthResponse)
+	if err != nil {
+		t.Fatalf("error in decoding response: %s", err)
+	}
+	return resp.StatusCode, healthResponse
+}
+
+func TestReady(t *testing.T) {
+	var postgresErr, redisErr error
+	testHandler := NewHealthHandler(map[string]Check{
+		"postgres": func(ctx context.Context) error {
+			return postgresErr
+		},
+		"redis": func(ctx context.Context) error {
+			return redisErr
+		},
+	})
+
+	testCases := []struct {
+		postgresErr error
+		redisErr    error
+		draining    bool
+		statusCode  int
+		response    dto.HealthResponse
+	}{
+		{
+			statusCode: http.StatusOK,
+			response:   dto.HealthResponse{Status: StatusOK},
+		},
+		{
+			redisErr:   fmt.Errorf("connection refused"),
+			statusCode: http.StatusServiceUnavailable,
+			response:   dto.HealthResponse{Status: StatusUnavailable, Failed: []string{"redis"}},
+		},
+		{
+			postgresErr: fmt.Errorf("connection refused"),
+			redisErr:    fmt.Errorf("connection refused"),
+			statusCode:  http.StatusServiceUnavailable,
+			response:    dto.HealthResponse{Status: StatusUnavailable, Failed: []string{"postgres", "redis"}},
+		},
+		{
+			draining:   true,
+			statusCode: http.StatusServiceUnavailable,
+			response:   dto.HealthResponse{Status: StatusDraining},
+		},
+	}
+
+	for _, tc := range testCases {
+		postgresErr, redisErr = tc.postgresErr, tc.redisErr
+		if tc.draining {
+			testHandler.StartDraining()
+		}
+		statusCode, healthResponse := doHealthRequest(t, testHandler.Ready)
+		assert.Equal(t, tc.statusCode, statusCode)
+		assert.Equal(t, tc.response, *healthResponse)
+
+		// во время остановки сервер остается живым
+		statusCode, healthResponse = doHealthRequest(t, testHandler.Live)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, StatusOK, healthResponse.Status)
+	}
+}
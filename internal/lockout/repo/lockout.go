@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+//go:generate mockgen -source=lockout.go -destination=lockout_mock.go -package=repo LoginAttemptRepo
+type LoginAttemptRepo interface {
+	AddFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	GetLockedUntil(ctx context.Context, key string) (time.Time, error)
+	Lock(ctx context.Context, key string, lockedUntil time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+const (
+	loginFailuresKeyPrefix = "login_failures:"
+	loginLockKeyPrefix     = "login_lock:"
+)
+
+type LoginAttemptRepoRedis struct {
+	redisPool *redis.Pool
+}
+
+func NewLoginAttemptRepo(redisPool *redis.Pool) *LoginAttemptRepoRedis {
+	return &LoginAttemptRepoRedis{
+		redisPool: redisPool,
+	}
+}
+
+func (r *LoginAttemptRepoRedis) AddFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	failuresCount, err := redis.Int(redis.DoContext(conn, ctx, "INCR", loginFailuresKeyPrefix+key))
+	if err != nil {
+		return 0, err
+	}
+	_, err = redis.DoContext(conn, ctx, "EXPIRE", loginFailuresKeyPrefix+key, int(window.Seconds()))
+	if err != nil {
+		return 0, err
+	}
+	return failuresCount, nil
+}
+
+func (r *LoginAttemptRepoRedis) GetLockedUntil(ctx context.Context, key string) (time.Time, error) {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	lockedUntil, err := redis.Int64(redis.DoContext(conn, ctx, "GET", loginLockKeyPrefix+key))
+	if errors.Is(err, redis.ErrNil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(lockedUntil, 0), nil
+}
+
+func (r *LoginAttemptRepoRedis) Lock(ctx context.Context, key string, lockedUntil time.Time) error {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, err = redis.DoContext(conn, ctx, "SET", loginLockKeyPrefix+key, lockedUntil.Unix())
+	if err != nil {
+		return err
+	}
+	_, err = redis.DoContext(conn, ctx, "EXPIREAT", loginLockKeyPrefix+key, lockedUntil.Unix())
+	return err
+}
+
+func (r *LoginAttemptRepoRedis) Reset(ctx context.Context, key string) error {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, err = redis.DoContext(conn, ctx, "DEL", loginFailuresKeyPrefix+key, loginLockKeyPrefix+key)
+	return err
+}
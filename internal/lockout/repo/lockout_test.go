@@ -0,0 +1,161 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockRedisConn struct {
+	values    map[string][]byte
+	expiresAt map[string]int64
+}
+
+func newMockRedisConn() *MockRedisConn {
+	return &MockRedisConn{
+		values:    make(map[string][]byte),
+		expiresAt: make(map[string]int64),
+	}
+}
+
+func (m *MockRedisConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	// пул отправляет пустую команду, когда соединение возвращается в него
+	if commandName == "" {
+		return nil, nil
+	}
+	key := args[0].(string)
+	if strings.HasSuffix(key, "broken") {
+		return nil, fmt.Errorf("err")
+	}
+	switch commandName {
+	case "INCR":
+		var counter int64
+		if value, ok := m.values[key]; ok {
+			_, _ = fmt.Sscan(string(value), &counter)
+		}
+		counter++
+		m.values[key] = []byte(fmt.Sprint(counter))
+		return counter, nil
+	case "SET":
+		m.values[key] = []byte(fmt.Sprint(args[1]))
+		return "OK", nil
+	case "GET":
+		value, ok := m.values[key]
+		if !ok {
+			return nil, nil
+		}
+		return value, nil
+	case "EXPIRE":
+		return int64(1), nil
+	case "EXPIREAT":
+		m.expiresAt[key] = args[1].(int64)
+		return int64(1), nil
+	case "DEL":
+		deleted := int64(0)
+		for _, arg := range args {
+			if _, ok := m.values[arg.(string)]; ok {
+				delete(m.values, arg.(string))
+				deleted++
+			}
+		}
+		return deleted, nil
+	}
+	return nil, fmt.Errorf("err")
+}
+
+func (m *MockRedisConn) DoContext(_ context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	return m.Do(commandName, args...)
+}
+
+func (m *MockRedisConn) Close() error {
+	return nil
+}
+
+func (m *MockRedisConn) Err() error {
+	return nil
+}
+
+func (m *MockRedisConn) Send(_ string, _ ...interface{}) error {
+	return nil
+}
+
+func (m *MockRedisConn) Flush() error {
+	return nil
+}
+
+func (m *MockRedisConn) Receive() (interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRedisConn) ReceiveContext(_ context.Context) (interface{}, error) {
+	return nil, nil
+}
+
+var _ redis.ConnWithContext = &MockRedisConn{}
+
+func newMockRedisPool(redisConn redis.Conn) *redis.Pool {
+	return &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redisConn, nil
+		},
+	}
+}
+
+func TestAddFailure(t *testing.T) {
+	repo := NewLoginAttemptRepo(newMockRedisPool(newMockRedisConn()))
+
+	for i := 1; i <= 3; i++ {
+		failuresCount, err := repo.AddFailure(context.Background(), "user:hello12", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, i, failuresCount)
+	}
+
+	failuresCount, err := repo.AddFailure(context.Background(), "ip:192.0.2.1", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, failuresCount)
+
+	_, err = repo.AddFailure(context.Background(), "broken", time.Hour)
+	assert.Error(t, err)
+}
+
+func TestLockAndReset(t *testing.T) {
+	redisConn := newMockRedisConn()
+	repo := NewLoginAttemptRepo(newMockRedisPool(redisConn))
+
+	lockedUntil, err := repo.GetLockedUntil(context.Background(), "user:hello12")
+	assert.NoError(t, err)
+	assert.True(t, lockedUntil.IsZero())
+
+	expectedLockedUntil := time.Unix(time.Now().Add(time.Minute).Unix(), 0)
+	err = repo.Lock(context.Background(), "user:hello12", expectedLockedUntil)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedLockedUntil.Unix(), redisConn.expiresAt["login_lock:user:hello12"])
+
+	lockedUntil, err = repo.GetLockedUntil(context.Background(), "user:hello12")
+	assert.NoError(t, err)
+	assert.Equal(t, expectedLockedUntil, lockedUntil)
+
+	_, err = repo.AddFailure(context.Background(), "user:hello12", time.Hour)
+	assert.NoError(t, err)
+	err = repo.Reset(context.Background(), "user:hello12")
+	assert.NoError(t, err)
+
+	lockedUntil, err = repo.GetLockedUntil(context.Background(), "user:hello12")
+	assert.NoError(t, err)
+	assert.True(t, lockedUntil.IsZero())
+	failuresCount, err := repo.AddFailure(context.Background(), "user:hello12", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, failuresCount)
+
+	_, err = repo.GetLockedUntil(context.Background(), "broken")
+	assert.Error(t, err)
+	err = repo.Lock(context.Background(), "broken", expectedLockedUntil)
+	assert.Error(t, err)
+	err = repo.Reset(context.Background(), "broken")
+	assert.Error(t, err)
+}
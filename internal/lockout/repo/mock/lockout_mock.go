@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: lockout.go
+
+// Package repo is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLoginAttemptRepo is a mock of LoginAttemptRepo interface.
+type MockLoginAttemptRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptRepoMockRecorder
+}
+
+// MockLoginAttemptRepoMockRecorder is the mock recorder for MockLoginAttemptRepo.
+type MockLoginAttemptRepoMockRecorder struct {
+	mock *MockLoginAttemptRepo
+}
+
+// NewMockLoginAttemptRepo creates a new mock instance.
+func NewMockLoginAttemptRepo(ctrl *gomock.Controller) *MockLoginAttemptRepo {
+	mock := &MockLoginAttemptRepo{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAttemptRepo) EXPECT() *MockLoginAttemptRepoMockRecorder {
+	return m.recorder
+}
+
+// AddFailure mocks base method.
+func (m *MockLoginAttemptRepo) AddFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFailure", ctx, key, window)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddFailure indicates an expected call of AddFailure.
+func (mr *MockLoginAttemptRepoMockRecorder) AddFailure(ctx, key, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFailure", reflect.TypeOf((*MockLoginAttemptRepo)(nil).AddFailure), ctx, key, window)
+}
+
+// GetLockedUntil mocks base method.
+func (m *MockLoginAttemptRepo) GetLockedUntil(ctx context.Context, key string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLockedUntil", ctx, key)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLockedUntil indicates an expected call of GetLockedUntil.
+func (mr *MockLoginAttemptRepoMockRecorder) GetLockedUntil(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLockedUntil", reflect.TypeOf((*MockLoginAttemptRepo)(nil).GetLockedUntil), ctx, key)
+}
+
+// Lock mocks base method.
+func (m *MockLoginAttemptRepo) Lock(ctx context.Context, key string, lockedUntil time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, key, lockedUntil)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockLoginAttemptRepoMockRecorder) Lock(ctx, key, lockedUntil interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockLoginAttemptRepo)(nil).Lock), ctx, key, lockedUntil)
+}
+
+// Reset mocks base method.
+func (m *MockLoginAttemptRepo) Reset(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockLoginAttemptRepoMockRecorder) Reset(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockLoginAttemptRepo)(nil).Reset), ctx, key)
+}
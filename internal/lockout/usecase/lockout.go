@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/lockout/repo"
+)
+
+const (
+	DefaultUserThreshold = 5
+	DefaultIPThreshold   = 20
+	DefaultBaseDelay     = time.Second
+	DefaultMaxDelay      = 15 * time.Minute
+	DefaultFailureWindow = time.Hour
+
+	userKeyPrefix = "user:"
+	ipKeyPrefix   = "ip:"
+)
+
+type Policy struct {
+	UserThreshold int
+	IPThreshold   int
+	BaseDelay     time.Duration
+	MaxDelay      time.Duration
+	FailureWindow time.Duration
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		UserThreshold: DefaultUserThreshold,
+		IPThreshold:   DefaultIPThreshold,
+		BaseDelay:     DefaultBaseDelay,
+		MaxDelay:      DefaultMaxDelay,
+		FailureWindow: DefaultFailureWindow,
+	}
+}
+
+//go:generate mockgen -source=lockout.go -destination=lockout_mock.go -package=usecase LockoutUseCase
+type LockoutUseCase interface {
+	CheckLogin(ctx context.Context, username, ip string) (time.Duration, error)
+	LoginFailed(ctx context.Context, username, ip string) (time.Duration, error)
+	LoginSucceeded(ctx context.Context, username string) error
+	UnlockUser(ctx context.Context, username string) error
+}
+
+type LockoutUseCaseApp struct {
+	attemptRepo repo.LoginAttemptRepo
+	policy      Policy
+}
+
+func NewLockoutUseCase(attemptRepo repo.LoginAttemptRepo, policy Policy) *LockoutUseCaseApp {
+	return &LockoutUseCaseApp{
+		attemptRepo: attemptRepo,
+		policy:      policy,
+	}
+}
+
+func (lu *LockoutUseCaseApp) CheckLogin(ctx context.Context, username, ip string) (time.Duration, error) {
+	var retryAfter time.Duration
+	for _, key := range []string{userKey(username), ipKey(ip)} {
+		lockedUntil, err := lu.attemptRepo.GetLockedUntil(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if remaining := time.Until(lockedUntil); remaining > retryAfter {
+			retryAfter = remaining
+		}
+	}
+	return retryAfter, nil
+}
+
+// LoginFailed начиная с порога удваивает срок блокировки, но не больше MaxDelay
+func (lu *LockoutUseCaseApp) LoginFailed(ctx context.Context, username, ip string) (time.Duration, error) {
+	var lockDuration time.Duration
+	limits := []struct {
+		key       string
+		threshold int
+	}{
+		{key: userKey(username), threshold: lu.policy.UserThreshold},
+		{key: ipKey(ip), threshold: lu.policy.IPThreshold},
+	}
+	now := time.Now()
+	for _, limit := range limits {
+		failuresCount, err := lu.attemptRepo.AddFailure(ctx, limit.key, lu.policy.FailureWindow)
+		if err != nil {
+			return 0, err
+		}
+		if failuresCount < limit.threshold {
+			continue
+		}
+		delay := lu.getDelay(failuresCount - limit.threshold)
+		err = lu.attemptRepo.Lock(ctx, limit.key, now.Add(delay))
+		if err != nil {
+			return 0, err
+		}
+		if delay > lockDuration {
+			lockDuration = delay
+		}
+	}
+	return lockDuration, nil
+}
+
+// LoginSucceeded не сбрасывает счетчик IP, иначе перебор чужих паролей можно было бы прерывать входом в свой
+func (lu *LockoutUseCaseApp) LoginSucceeded(ctx context.Context, username string) error {
+	return lu.attemptRepo.Reset(ctx, userKey(username))
+}
+
+func (lu *LockoutUseCaseApp) UnlockUser(ctx context.Context, username string) error {
+	return lu.attemptRepo.Reset(ctx, userKey(username))
+}
+
+func (lu *LockoutUseCaseApp) getDelay(overThreshold int) time.Duration {
+	delay := lu.policy.BaseDelay
+	for i := 0; i < overThreshold && delay < lu.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > lu.policy.MaxDelay {
+		return lu.policy.MaxDelay
+	}
+	return delay
+}
+
+func userKey(username string) string {
+	return userKeyPrefix + strings.ToLower(username)
+}
+
+func ipKey(ip string) string {
+	return ipKeyPrefix + ip
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ilyushkaaa/Filmoteka/internal/lockout/repo/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPolicy() Policy {
+	return Policy{
+		UserThreshold: 3,
+		IPThreshold:   10,
+		BaseDelay:     time.Second,
+		MaxDelay:      10 * time.Second,
+		FailureWindow: time.Hour,
+	}
+}
+
+func TestCheckLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockLoginAttemptRepo(ctrl)
+	testUseCase := NewLockoutUseCase(testRepo, newTestPolicy())
+
+	testRepo.EXPECT().GetLockedUntil(gomock.Any(), "user:hello12").Return(time.Time{}, fmt.Errorf("error"))
+	_, err := testUseCase.CheckLogin(context.Background(), "Hello12", "192.0.2.1")
+	assert.Error(t, err)
+
+	testRepo.EXPECT().GetLockedUntil(gomock.Any(), "user:hello12").Return(time.Time{}, nil)
+	testRepo.EXPECT().GetLockedUntil(gomock.Any(), "ip:192.0.2.1").Return(time.Now().Add(-time.Minute), nil)
+	retryAfter, err := testUseCase.CheckLogin(context.Background(), "hello12", "192.0.2.1")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), retryAfter)
+
+	// действует более долгая из двух блокировок
+	testRepo.EXPECT().GetLockedUntil(gomock.Any(), "user:hello12").Return(time.Now().Add(time.Minute), nil)
+	testRepo.EXPECT().GetLockedUntil(gomock.Any(), "ip:192.0.2.1").Return(time.Now().Add(time.Hour), nil)
+	retryAfter, err = testUseCase.CheckLogin(context.Background(), "hello12", "192.0.2.1")
+	assert.NoError(t, err)
+	assert.Greater(t, retryAfter, 59*time.Minute)
+}
+
+func TestLoginFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockLoginAttemptRepo(ctrl)
+	testUseCase := NewLockoutUseCase(testRepo, newTestPolicy())
+
+	testCases := []struct {
+		userFailures int
+		ipFailures   int
+		lockDuration time.Duration
+	}{
+		{userFailures: 2, ipFailures: 2, lockDuration: 0},
+		{userFailures: 3, ipFailures: 3, lockDuration: time.Second},
+		{userFailures: 5, ipFailures: 5, lockDuration: 4 * time.Second},
+		{userFailures: 1, ipFailures: 11, lockDuration: 2 * time.Second},
+		{userFailures: 40, ipFailures: 40, lockDuration: 10 * time.Second},
+	}
+	for _, tc := range testCases {
+		testRepo.EXPECT().AddFailure(gomock.Any(), "user:hello12", time.Hour).Return(tc.userFailures, nil)
+		testRepo.EXPECT().AddFailure(gomock.Any(), "ip:192.0.2.1", time.Hour).Return(tc.ipFailures, nil)
+		if tc.userFailures >= 3 {
+			testRepo.EXPECT().Lock(gomock.Any(), "user:hello12", gomock.Any()).Return(nil)
+		}
+		if tc.ipFailures >= 10 {
+			testRepo.EXPECT().Lock(gomock.Any(), "ip:192.0.2.1", gomock.Any()).Return(nil)
+		}
+		lockDuration, err := testUseCase.LoginFailed(context.Background(), "hello12", "192.0.2.1")
+		assert.NoError(t, err)
+		assert.Equal(t, tc.lockDuration, lockDuration)
+	}
+
+	testRepo.EXPECT().AddFailure(gomock.Any(), "user:hello12", time.Hour).Return(0, fmt.Errorf("error"))
+	_, err := testUseCase.LoginFailed(context.Background(), "hello12", "192.0.2.1")
+	assert.Error(t, err)
+
+	testRepo.EXPECT().AddFailure(gomock.Any(), "user:hello12", time.Hour).Return(3, nil)
+	testRepo.EXPECT().Lock(gomock.Any(), "user:hello12", gomock.Any()).Return(fmt.Errorf("error"))
+	_, err = testUseCase.LoginFailed(context.Background(), "hello12", "192.0.2.1")
+	assert.Error(t, err)
+}
+
+func TestLoginSucceededAndUnlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockLoginAttemptRepo(ctrl)
+	testUseCase := NewLockoutUseCase(testRepo, newTestPolicy())
+
+	testRepo.EXPECT().Reset(gomock.Any(), "user:hello12").Return(nil)
+	err := testUseCase.LoginSucceeded(context.Background(), "hello12")
+	assert.NoError(t, err)
+
+	testRepo.EXPECT().Reset(gomock.Any(), "user:hello12").Return(fmt.Errorf("error"))
+	err = testUseCase.UnlockUser(context.Background(), "HELLO12")
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: lockout.go
+
+// Package usecase is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLockoutUseCase is a mock of LockoutUseCase interface.
+type MockLockoutUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockLockoutUseCaseMockRecorder
+}
+
+// MockLockoutUseCaseMockRecorder is the mock recorder for MockLockoutUseCase.
+type MockLockoutUseCaseMockRecorder struct {
+	mock *MockLockoutUseCase
+}
+
+// NewMockLockoutUseCase creates a new mock instance.
+func NewMockLockoutUseCase(ctrl *gomock.Controller) *MockLockoutUseCase {
+	mock := &MockLockoutUseCase{ctrl: ctrl}
+	mock.recorder = &MockLockoutUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLockoutUseCase) EXPECT() *MockLockoutUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CheckLogin mocks base method.
+func (m *MockLockoutUseCase) CheckLogin(ctx context.Context, username, ip string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckLogin", ctx, username, ip)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckLogin indicates an expected call of CheckLogin.
+func (mr *MockLockoutUseCaseMockRecorder) CheckLogin(ctx, username, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckLogin", reflect.TypeOf((*MockLockoutUseCase)(nil).CheckLogin), ctx, username, ip)
+}
+
+// LoginFailed mocks base method.
+func (m *MockLockoutUseCase) LoginFailed(ctx context.Context, username, ip string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginFailed", ctx, username, ip)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginFailed indicates an expected call of LoginFailed.
+func (mr *MockLockoutUseCaseMockRecorder) LoginFailed(ctx, username, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginFailed", reflect.TypeOf((*MockLockoutUseCase)(nil).LoginFailed), ctx, username, ip)
+}
+
+// LoginSucceeded mocks base method.
+func (m *MockLockoutUseCase) LoginSucceeded(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginSucceeded", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LoginSucceeded indicates an expected call of LoginSucceeded.
+func (mr *MockLockoutUseCaseMockRecorder) LoginSucceeded(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginSucceeded", reflect.TypeOf((*MockLockoutUseCase)(nil).LoginSucceeded), ctx, username)
+}
+
+// UnlockUser mocks base method.
+func (m *MockLockoutUseCase) UnlockUser(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockLockoutUseCaseMockRecorder) UnlockUser(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockLockoutUseCase)(nil).UnlockUser), ctx, username)
+}
@@ -6,20 +6,60 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
+	apiKeyEntity "github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+	apiKeyUsecase "github.com/ilyushkaaa/Filmoteka/internal/apikeys/usecase"
 	"github.com/ilyushkaaa/Filmoteka/internal/session/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/cookie"
 	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
 	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+	"go.uber.org/zap"
 )
 
 type userKey int
 type tokenKey int
+type roleKey int
+type apiKeyKey int
 
 const (
-	MyUserKey      userKey  = 1
-	MySessionIDKey tokenKey = 2
+	MyUserKey      userKey   = 1
+	MySessionIDKey tokenKey  = 2
+	MyRoleKey      roleKey   = 4
+	MyAPIKeyKey    apiKeyKey = 5
 )
 
+const (
+	bearerPrefix = "Bearer "
+	apiKeyPrefix = "ApiKey "
+)
+
+// GetCurrentUserID пишет ответ с ошибкой, если в контексте нет пользователя или запрос
+// аутентифицирован API ключом, а allowAPIKey = false
+func GetCurrentUserID(zapLogger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request, allowAPIKey bool) (uint64, error) {
+	ctx := r.Context()
+	if _, ok := ctx.Value(MyAPIKeyKey).(*apiKeyEntity.APIKey); ok && !allowAPIKey {
+		zapLogger.Errorf("action is not allowed with api key authentication")
+		errText := `{"error": "action is not allowed with api key authentication"}`
+		err := response.WriteResponse(w, []byte(errText), http.StatusForbidden)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return 0, fmt.Errorf("api key authentication")
+	}
+	userID, ok := ctx.Value(MyUserKey).(uint64)
+	if !ok {
+		zapLogger.Errorf("can not get user id from context")
+		errText := `{"error": "internal server error"}`
+		err := response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return 0, fmt.Errorf("no user id in context")
+	}
+	return userID, nil
+}
+
 func (mw *Middleware) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		zapLogger, err := logger.GetLoggerFromContext(r.Context())
@@ -32,7 +72,16 @@ func (mw *Middleware) AuthMiddleware(next http.Handler) http.Handler {
 			}
 			return
 		}
-		sessionCookie, err := r.Cookie("session_id")
+		authHeader := r.Header.Get("Authorization")
+		if strings.HasPrefix(authHeader, bearerPrefix) {
+			mw.authenticateBearer(w, r, next, strings.TrimPrefix(authHeader, bearerPrefix))
+			return
+		}
+		if strings.HasPrefix(authHeader, apiKeyPrefix) {
+			mw.authenticateAPIKey(w, r, next, strings.TrimPrefix(authHeader, apiKeyPrefix))
+			return
+		}
+		sessionCookie, err := r.Cookie(cookie.SessionCookieName)
 		if errors.Is(err, http.ErrNoCookie) {
 			zapLogger.Errorf("no cookie in request")
 			errText := `{"error": "no cookie in request""}`
@@ -52,7 +101,7 @@ func (mw *Middleware) AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 		sessionID := sessionCookie.Value
-		mySession, err := mw.sessionUseCase.GetSession(sessionID)
+		mySession, isRefreshed, err := mw.sessionUseCase.GetSession(r.Context(), sessionID)
 		if errors.Is(err, usecase.ErrNoSession) {
 			zapLogger.Errorf("no session for id: %s", sessionID)
 			errText := fmt.Sprintf(`{"error": "there is no session for session id %s}`, sessionID)
@@ -71,10 +120,91 @@ func (mw *Middleware) AuthMiddleware(next http.Handler) http.Handler {
 			}
 			return
 		}
+		if isRefreshed {
+			// браузер не должен удалить cookie раньше, чем истечет продленная сессия
+			mw.cookieConfig.SetSessionCookies(w, mySession.ID, mySession.ExpiresAt)
+		}
 
 		ctx := r.Context()
 		ctx = context.WithValue(ctx, MyUserKey, mySession.UserID)
 		ctx = context.WithValue(ctx, MySessionIDKey, mySession.ID)
+		ctx = context.WithValue(ctx, MyPermissionsKey, &userPermissions{})
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+func (mw *Middleware) authenticateBearer(w http.ResponseWriter, r *http.Request, next http.Handler, accessToken string) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	if mw.tokenUseCase == nil {
+		zapLogger.Errorf("bearer token was passed, but token authentication is disabled")
+		errText := `{"error": "bearer tokens are not supported"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusUnauthorized)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	claims, err := mw.tokenUseCase.ParseAccessToken(r.Context(), accessToken)
+	if err != nil {
+		zapLogger.Errorf("bad access token: %s", err)
+		errText := `{"error": "access token is invalid or expired"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusUnauthorized)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, MyUserKey, claims.UserID)
+	ctx = context.WithValue(ctx, MyRoleKey, claims.Role)
+	ctx = context.WithValue(ctx, MyPermissionsKey, &userPermissions{})
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+func (mw *Middleware) authenticateAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, secret string) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	apiKey, err := mw.apiKeyUseCase.Authenticate(r.Context(), secret)
+	if errors.Is(err, apiKeyUsecase.ErrBadAPIKey) {
+		zapLogger.Errorf("bad api key was passed")
+		errText := `{"error": "api key is invalid or expired"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusUnauthorized)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		zapLogger.Errorf("error in api key authentication: %s", err)
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, MyUserKey, apiKey.UserID)
+	ctx = context.WithValue(ctx, MyAPIKeyKey, apiKey)
+	ctx = context.WithValue(ctx, MyPermissionsKey, &userPermissions{})
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
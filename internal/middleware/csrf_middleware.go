@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+
+	"github.com/ilyushkaaa/Filmoteka/pkg/cookie"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+)
+
+// CSRFMiddleware проверяет только запросы с cookie сессии и должен выполняться после AuthMiddleware
+func (mw *Middleware) CSRFMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isSafeMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		sessionID, ok := r.Context().Value(MySessionIDKey).(string)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		zapLogger, err := logger.GetLoggerFromContext(r.Context())
+		if err != nil {
+			log.Printf("can not get logger from context: %s", err)
+			err = response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+			if err != nil {
+				log.Printf("can not write response: %s", err)
+			}
+			return
+		}
+		csrfToken := r.Header.Get(cookie.CSRFHeaderName)
+		expectedToken := cookie.CSRFToken(sessionID)
+		if subtle.ConstantTimeCompare([]byte(csrfToken), []byte(expectedToken)) != 1 {
+			zapLogger.Errorf("csrf token is missing or invalid")
+			errText := `{"error": "csrf token is missing or invalid"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusForbidden)
+			if err != nil {
+				zapLogger.Errorf("can not write response: %s", err)
+			}
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -1,18 +1,29 @@
 package middleware
 
 import (
+	apiKeyUseCase "github.com/ilyushkaaa/Filmoteka/internal/apikeys/usecase"
 	sessionUseCase "github.com/ilyushkaaa/Filmoteka/internal/session/usecase"
+	tokenUseCase "github.com/ilyushkaaa/Filmoteka/internal/token/usecase"
 	userUsecase "github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/cookie"
 )
 
 type Middleware struct {
 	sessionUseCase sessionUseCase.SessionUseCase
 	userUseCase    userUsecase.UserUseCase
+	tokenUseCase   tokenUseCase.TokenUseCase
+	apiKeyUseCase  apiKeyUseCase.APIKeyUseCase
+	cookieConfig   *cookie.Config
 }
 
-func NewMiddleware(sessionUseCase sessionUseCase.SessionUseCase, userUseCase userUsecase.UserUseCase) *Middleware {
+// NewMiddleware - tokenUseCase может быть nil, тогда Bearer токены не принимаются
+func NewMiddleware(sessionUseCase sessionUseCase.SessionUseCase, userUseCase userUsecase.UserUseCase,
+	tokenUseCase tokenUseCase.TokenUseCase, apiKeyUseCase apiKeyUseCase.APIKeyUseCase, cookieConfig *cookie.Config) *Middleware {
 	return &Middleware{
 		sessionUseCase: sessionUseCase,
 		userUseCase:    userUseCase,
+		tokenUseCase:   tokenUseCase,
+		apiKeyUseCase:  apiKeyUseCase,
+		cookieConfig:   cookieConfig,
 	}
 }
@@ -6,17 +6,29 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	apiKeyEntity "github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+	apiKeyUsecase "github.com/ilyushkaaa/Filmoteka/internal/apikeys/usecase"
+	apiKeyMock "github.com/ilyushkaaa/Filmoteka/internal/apikeys/usecase/mock"
+	sessionEntity "github.com/ilyushkaaa/Filmoteka/internal/session/entity"
 	"github.com/ilyushkaaa/Filmoteka/internal/session/usecase"
 	"github.com/ilyushkaaa/Filmoteka/internal/session/usecase/mock"
+	tokenUsecase "github.com/ilyushkaaa/Filmoteka/internal/token/usecase"
+	tokenMock "github.com/ilyushkaaa/Filmoteka/internal/token/usecase/mock"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/entity"
 	usecase2 "github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
 	mock2 "github.com/ilyushkaaa/Filmoteka/internal/users/usecase/mock"
+	"github.com/ilyushkaaa/Filmoteka/pkg/access_token"
+	"github.com/ilyushkaaa/Filmoteka/pkg/cookie"
 	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
 
+var testCookieConfig = &cookie.Config{Path: "/", Secure: true, SameSite: http.SameSiteLaxMode}
+
 type fakeHandler struct{}
 
 func (h *fakeHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
@@ -70,7 +82,7 @@ func TestAuth(t *testing.T) {
 
 	sessionUseCase := mock.NewMockSessionUseCase(ctrl)
 	userUseCase := mock2.NewMockUserUseCase(ctrl)
-	middleware := NewMiddleware(sessionUseCase, userUseCase)
+	middleware := NewMiddleware(sessionUseCase, userUseCase, nil, nil, testCookieConfig)
 
 	handler := &fakeHandler{}
 
@@ -92,7 +104,7 @@ func TestAuth(t *testing.T) {
 	req = httptest.NewRequest("GET", "http://films", nil)
 	middleware.AuthMiddleware(handler).ServeHTTP(&errorWriter{}, req)
 
-	sessionUseCase.EXPECT().GetSession("qqq").Return(nil, fmt.Errorf("error"))
+	sessionUseCase.EXPECT().GetSession(gomock.Any(), "qqq").Return(nil, false, fmt.Errorf("error"))
 	req = httptest.NewRequest("GET", "http://films", nil)
 	req.Header = map[string][]string{
 		"Cookie": {`session_id="qqq"`},
@@ -104,7 +116,7 @@ func TestAuth(t *testing.T) {
 	resp = recorder.Result()
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 
-	sessionUseCase.EXPECT().GetSession("qqq").Return(nil, usecase.ErrNoSession)
+	sessionUseCase.EXPECT().GetSession(gomock.Any(), "qqq").Return(nil, false, usecase.ErrNoSession)
 	req = httptest.NewRequest("GET", "http://films", nil)
 	req.Header = map[string][]string{
 		"Cookie": {`session_id="qqq"`},
@@ -116,21 +128,147 @@ func TestAuth(t *testing.T) {
 	resp = recorder.Result()
 	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 
+	expiresAt := time.Now().Add(time.Hour)
+	sessionUseCase.EXPECT().GetSession(gomock.Any(), "qqq").
+		Return(&sessionEntity.Session{ID: "qqq", UserID: 1, ExpiresAt: expiresAt}, false, nil)
+	req = httptest.NewRequest("GET", "http://films", nil)
+	req.AddCookie(&http.Cookie{Name: "session_id", Value: "qqq"})
+	req = req.WithContext(context.WithValue(req.Context(), logger.MyLoggerKey, fakeLogger))
+	recorder = httptest.NewRecorder()
+	middleware.AuthMiddleware(handler).ServeHTTP(recorder, req)
+	resp = recorder.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Cookies())
+
+	// продленная сессия переиздает cookie с новым сроком действия
+	sessionUseCase.EXPECT().GetSession(gomock.Any(), "qqq").
+		Return(&sessionEntity.Session{ID: "qqq", UserID: 1, ExpiresAt: expiresAt}, true, nil)
+	req = httptest.NewRequest("GET", "http://films", nil)
+	req.AddCookie(&http.Cookie{Name: "session_id", Value: "qqq"})
+	req = req.WithContext(context.WithValue(req.Context(), logger.MyLoggerKey, fakeLogger))
+	recorder = httptest.NewRecorder()
+	middleware.AuthMiddleware(handler).ServeHTTP(recorder, req)
+	resp = recorder.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	if assert.Len(t, resp.Cookies(), 2) {
+		assert.Equal(t, cookie.SessionCookieName, resp.Cookies()[0].Name)
+		assert.Equal(t, "qqq", resp.Cookies()[0].Value)
+		assert.Equal(t, expiresAt.Unix(), resp.Cookies()[0].Expires.Unix())
+		assert.True(t, resp.Cookies()[0].Secure)
+		assert.Equal(t, cookie.CSRFCookieName, resp.Cookies()[1].Name)
+	}
+
+}
+
+func TestAuthBearer(t *testing.T) {
+	fakeLogger := zap.NewNop().Sugar()
+	ctrl := gomock.NewController(t)
+
+	sessionUseCase := mock.NewMockSessionUseCase(ctrl)
+	userUseCase := mock2.NewMockUserUseCase(ctrl)
+	tokenUseCase := tokenMock.NewMockTokenUseCase(ctrl)
+
+	var gotUserID uint64
+	var gotRole string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserID, _ = r.Context().Value(MyUserKey).(uint64)
+		gotRole, _ = r.Context().Value(MyRoleKey).(string)
+		w.WriteHeader(http.StatusOK)
+	})
+	newBearerRequest := func() *http.Request {
+		req := httptest.NewRequest("GET", "http://films", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		ctx := context.WithValue(req.Context(), logger.MyLoggerKey, fakeLogger)
+		return req.WithContext(ctx)
+	}
+
+	sessionOnlyMiddleware := NewMiddleware(sessionUseCase, userUseCase, nil, nil, testCookieConfig)
+	recorder := httptest.NewRecorder()
+	sessionOnlyMiddleware.AuthMiddleware(handler).ServeHTTP(recorder, newBearerRequest())
+	assert.Equal(t, http.StatusUnauthorized, recorder.Result().StatusCode)
+
+	middleware := NewMiddleware(sessionUseCase, userUseCase, tokenUseCase, nil, testCookieConfig)
+
+	tokenUseCase.EXPECT().ParseAccessToken(gomock.Any(), "token").Return(nil, tokenUsecase.ErrBadAccessToken)
+	recorder = httptest.NewRecorder()
+	middleware.AuthMiddleware(handler).ServeHTTP(recorder, newBearerRequest())
+	assert.Equal(t, http.StatusUnauthorized, recorder.Result().StatusCode)
+
+	tokenUseCase.EXPECT().ParseAccessToken(gomock.Any(), "token").Return(&access_token.Claims{UserID: 5, Role: entity.RoleEditor}, nil)
+	recorder = httptest.NewRecorder()
+	middleware.AuthMiddleware(handler).ServeHTTP(recorder, newBearerRequest())
+	assert.Equal(t, http.StatusOK, recorder.Result().StatusCode)
+	assert.Equal(t, uint64(5), gotUserID)
+	assert.Equal(t, entity.RoleEditor, gotRole)
+
+	// роль из токена используется без обращения к пользователю в базе данных
+	userUseCase.EXPECT().GetRolePermissions(gomock.Any(), entity.RoleEditor).Return([]string{entity.PermissionFilmWrite}, nil)
+	tokenUseCase.EXPECT().ParseAccessToken(gomock.Any(), "token").Return(&access_token.Claims{UserID: 5, Role: entity.RoleEditor}, nil)
+	recorder = httptest.NewRecorder()
+	middleware.AuthMiddleware(middleware.RequirePermission(entity.PermissionFilmWrite)(handler)).
+		ServeHTTP(recorder, newBearerRequest())
+	assert.Equal(t, http.StatusOK, recorder.Result().StatusCode)
+}
+
+func TestAuthAPIKey(t *testing.T) {
+	fakeLogger := zap.NewNop().Sugar()
+	ctrl := gomock.NewController(t)
+
+	sessionUseCase := mock.NewMockSessionUseCase(ctrl)
+	userUseCase := mock2.NewMockUserUseCase(ctrl)
+	apiKeyUseCase := apiKeyMock.NewMockAPIKeyUseCase(ctrl)
+	middleware := NewMiddleware(sessionUseCase, userUseCase, nil, apiKeyUseCase, testCookieConfig)
+
+	newAPIKeyRequest := func() *http.Request {
+		req := httptest.NewRequest("POST", "http://films", nil)
+		req.Header.Set("Authorization", "ApiKey fmk_secret")
+		ctx := context.WithValue(req.Context(), logger.MyLoggerKey, fakeLogger)
+		return req.WithContext(ctx)
+	}
+	filmWrite := middleware.AuthMiddleware(middleware.RequirePermission(entity.PermissionFilmWrite)(&fakeHandler{}))
+
+	apiKeyUseCase.EXPECT().Authenticate(gomock.Any(), "fmk_secret").Return(nil, apiKeyUsecase.ErrBadAPIKey)
+	recorder := httptest.NewRecorder()
+	filmWrite.ServeHTTP(recorder, newAPIKeyRequest())
+	assert.Equal(t, http.StatusUnauthorized, recorder.Result().StatusCode)
+
+	apiKeyUseCase.EXPECT().Authenticate(gomock.Any(), "fmk_secret").Return(nil, fmt.Errorf("error"))
+	recorder = httptest.NewRecorder()
+	filmWrite.ServeHTTP(recorder, newAPIKeyRequest())
+	assert.Equal(t, http.StatusInternalServerError, recorder.Result().StatusCode)
+
+	// ключ без области действия получает все разрешения роли владельца
+	apiKeyUseCase.EXPECT().Authenticate(gomock.Any(), "fmk_secret").Return(&apiKeyEntity.APIKey{ID: 1, UserID: 5}, nil)
+	userUseCase.EXPECT().GetUserPermissions(gomock.Any(), uint64(5)).Return([]string{entity.PermissionFilmWrite}, nil)
+	recorder = httptest.NewRecorder()
+	filmWrite.ServeHTTP(recorder, newAPIKeyRequest())
+	assert.Equal(t, http.StatusOK, recorder.Result().StatusCode)
+
+	// область действия ключа сужает разрешения роли
+	apiKeyUseCase.EXPECT().Authenticate(gomock.Any(), "fmk_secret").
+		Return(&apiKeyEntity.APIKey{ID: 1, UserID: 5, Scopes: []string{entity.PermissionActorWrite}}, nil)
+	userUseCase.EXPECT().GetUserPermissions(gomock.Any(), uint64(5)).
+		Return([]string{entity.PermissionFilmWrite, entity.PermissionActorWrite}, nil)
+	recorder = httptest.NewRecorder()
+	filmWrite.ServeHTTP(recorder, newAPIKeyRequest())
+	assert.Equal(t, http.StatusForbidden, recorder.Result().StatusCode)
 }
 
-func TestAdmin(t *testing.T) {
+func TestRequirePermission(t *testing.T) {
 	fakeLogger := zap.NewNop().Sugar()
 	ctrl := gomock.NewController(t)
 
 	sessionUseCase := mock.NewMockSessionUseCase(ctrl)
 	userUseCase := mock2.NewMockUserUseCase(ctrl)
-	middleware := NewMiddleware(sessionUseCase, userUseCase)
+	middleware := NewMiddleware(sessionUseCase, userUseCase, nil, nil, testCookieConfig)
+	filmWrite := middleware.RequirePermission(entity.PermissionFilmWrite)
+	userManage := middleware.RequirePermission(entity.PermissionUserManage)
 
 	handler := &fakeHandler{}
 
 	req := httptest.NewRequest("GET", "http://films", nil)
 	recorder := httptest.NewRecorder()
-	middleware.AdminMiddleware(handler).ServeHTTP(recorder, req)
+	filmWrite(handler).ServeHTTP(recorder, req)
 	resp := recorder.Result()
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 
@@ -138,40 +276,144 @@ func TestAdmin(t *testing.T) {
 	ctx := context.WithValue(req.Context(), logger.MyLoggerKey, fakeLogger)
 	req = req.WithContext(ctx)
 	recorder = httptest.NewRecorder()
-	middleware.AdminMiddleware(handler).ServeHTTP(recorder, req)
+	filmWrite(handler).ServeHTTP(recorder, req)
 	resp = recorder.Result()
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 
-	userUseCase.EXPECT().GetUserRole(uint64(1)).Return("", fmt.Errorf("error"))
 	req = httptest.NewRequest("GET", "http://films", nil)
 	ctx = context.WithValue(req.Context(), logger.MyLoggerKey, fakeLogger)
 	ctx = context.WithValue(ctx, MyUserKey, uint64(1))
 	req = req.WithContext(ctx)
 	recorder = httptest.NewRecorder()
-	middleware.AdminMiddleware(handler).ServeHTTP(recorder, req)
+	filmWrite(handler).ServeHTTP(recorder, req)
 	resp = recorder.Result()
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 
-	userUseCase.EXPECT().GetUserRole(uint64(1)).Return("", usecase2.ErrNoUser)
-	req = httptest.NewRequest("GET", "http://films", nil)
-	ctx = context.WithValue(req.Context(), logger.MyLoggerKey, fakeLogger)
-	ctx = context.WithValue(ctx, MyUserKey, uint64(1))
-	req = req.WithContext(ctx)
+	userUseCase.EXPECT().GetUserPermissions(gomock.Any(), uint64(1)).Return(nil, fmt.Errorf("error"))
+	req = newPermissionsRequest(fakeLogger, 1)
 	recorder = httptest.NewRecorder()
-	middleware.AdminMiddleware(handler).ServeHTTP(recorder, req)
+	filmWrite(handler).ServeHTTP(recorder, req)
+	resp = recorder.Result()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	userUseCase.EXPECT().GetUserPermissions(gomock.Any(), uint64(1)).Return(nil, usecase2.ErrNoUser)
+	req = newPermissionsRequest(fakeLogger, 1)
+	recorder = httptest.NewRecorder()
+	filmWrite(handler).ServeHTTP(recorder, req)
 	resp = recorder.Result()
 	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 
-	userUseCase.EXPECT().GetUserRole(uint64(1)).Return("default", nil)
-	req = httptest.NewRequest("GET", "http://films", nil)
-	ctx = context.WithValue(req.Context(), logger.MyLoggerKey, fakeLogger)
-	ctx = context.WithValue(ctx, MyUserKey, uint64(1))
-	req = req.WithContext(ctx)
+	userUseCase.EXPECT().GetUserPermissions(gomock.Any(), uint64(1)).Return([]string{}, nil)
+	req = newPermissionsRequest(fakeLogger, 1)
 	recorder = httptest.NewRecorder()
-	middleware.AdminMiddleware(handler).ServeHTTP(recorder, req)
+	filmWrite(handler).ServeHTTP(recorder, req)
 	resp = recorder.Result()
 	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
 
+	userUseCase.EXPECT().GetUserPermissions(gomock.Any(), uint64(1)).
+		Return([]string{entity.PermissionFilmWrite, entity.PermissionActorWrite}, nil)
+	req = newPermissionsRequest(fakeLogger, 1)
+	recorder = httptest.NewRecorder()
+	filmWrite(handler).ServeHTTP(recorder, req)
+	resp = recorder.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// разрешения загружаются один раз за запрос, даже если проверок несколько
+	userUseCase.EXPECT().GetUserPermissions(gomock.Any(), uint64(1)).
+		Return([]string{entity.PermissionFilmWrite, entity.PermissionUserManage}, nil).Times(1)
+	req = newPermissionsRequest(fakeLogger, 1)
+	recorder = httptest.NewRecorder()
+	filmWrite(userManage(handler)).ServeHTTP(recorder, req)
+	resp = recorder.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	userUseCase.EXPECT().GetUserPermissions(gomock.Any(), uint64(1)).
+		Return([]string{entity.PermissionFilmWrite}, nil).Times(1)
+	req = newPermissionsRequest(fakeLogger, 1)
+	recorder = httptest.NewRecorder()
+	filmWrite(userManage(handler)).ServeHTTP(recorder, req)
+	resp = recorder.Result()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestCSRF(t *testing.T) {
+	fakeLogger := zap.NewNop().Sugar()
+	middleware := NewMiddleware(nil, nil, nil, nil, testCookieConfig)
+	handler := middleware.CSRFMiddleware(&fakeHandler{})
+
+	testCases := []struct {
+		method     string
+		sessionID  string
+		csrfToken  string
+		withLogger bool
+		statusCode int
+	}{
+		// безопасные методы не проверяются
+		{method: http.MethodGet, sessionID: "qqq", withLogger: true, statusCode: http.StatusOK},
+		// запрос аутентифицирован не cookie сессии
+		{method: http.MethodPost, withLogger: true, statusCode: http.StatusOK},
+		{method: http.MethodPost, sessionID: "qqq", statusCode: http.StatusInternalServerError},
+		{method: http.MethodPost, sessionID: "qqq", withLogger: true, statusCode: http.StatusForbidden},
+		{method: http.MethodDelete, sessionID: "qqq", csrfToken: cookie.CSRFToken("www"), withLogger: true,
+			statusCode: http.StatusForbidden},
+		{method: http.MethodPut, sessionID: "qqq", csrfToken: cookie.CSRFToken("qqq"), withLogger: true,
+			statusCode: http.StatusOK},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, "http://films", nil)
+		ctx := req.Context()
+		if tc.withLogger {
+			ctx = context.WithValue(ctx, logger.MyLoggerKey, fakeLogger)
+		}
+		if tc.sessionID != "" {
+			ctx = context.WithValue(ctx, MySessionIDKey, tc.sessionID)
+		}
+		if tc.csrfToken != "" {
+			req.Header.Set(cookie.CSRFHeaderName, tc.csrfToken)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req.WithContext(ctx))
+		assert.Equal(t, tc.statusCode, recorder.Result().StatusCode)
+	}
+}
+
+func TestGetCurrentUserID(t *testing.T) {
+	fakeLogger := zap.NewNop().Sugar()
+	testCases := []struct {
+		withUser    bool
+		withAPIKey  bool
+		allowAPIKey bool
+		statusCode  int
+		userID      uint64
+	}{
+		{withUser: true, statusCode: http.StatusOK, userID: 5},
+		{statusCode: http.StatusInternalServerError},
+		{withUser: true, withAPIKey: true, statusCode: http.StatusForbidden},
+		{withUser: true, withAPIKey: true, allowAPIKey: true, statusCode: http.StatusOK, userID: 5},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "http://films", nil)
+		ctx := req.Context()
+		if tc.withUser {
+			ctx = context.WithValue(ctx, MyUserKey, uint64(5))
+		}
+		if tc.withAPIKey {
+			ctx = context.WithValue(ctx, MyAPIKeyKey, &apiKeyEntity.APIKey{ID: 1, UserID: 5})
+		}
+		recorder := httptest.NewRecorder()
+		userID, err := GetCurrentUserID(fakeLogger, recorder, req.WithContext(ctx), tc.allowAPIKey)
+		assert.Equal(t, tc.statusCode, recorder.Result().StatusCode)
+		assert.Equal(t, tc.userID, userID)
+		assert.Equal(t, tc.statusCode != http.StatusOK, err != nil)
+	}
+}
+
+func newPermissionsRequest(fakeLogger *zap.SugaredLogger, userID uint64) *http.Request {
+	req := httptest.NewRequest("GET", "http://films", nil)
+	ctx := context.WithValue(req.Context(), logger.MyLoggerKey, fakeLogger)
+	ctx = context.WithValue(ctx, MyUserKey, userID)
+	ctx = context.WithValue(ctx, MyPermissionsKey, &userPermissions{})
+	return req.WithContext(ctx)
 }
 
 func TestRequestInit(t *testing.T) {
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+)
+
+type permissionsKey int
+
+const MyPermissionsKey permissionsKey = 3
+
+// userPermissions загружается один раз за запрос
+type userPermissions struct {
+	isLoaded    bool
+	permissions map[string]struct{}
+}
+
+func (mw *Middleware) RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			zapLogger, err := logger.GetLoggerFromContext(ctx)
+			if err != nil {
+				log.Printf("can not get logger from context: %s", err)
+				err = response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+				if err != nil {
+					log.Printf("can not write response: %s", err)
+				}
+				return
+			}
+			userID, ok := ctx.Value(MyUserKey).(uint64)
+			if !ok {
+				zapLogger.Errorf("can not get user id from context")
+				err = response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+				if err != nil {
+					zapLogger.Errorf("can not write response: %s", err)
+				}
+				return
+			}
+			cachedPermissions, ok := ctx.Value(MyPermissionsKey).(*userPermissions)
+			if !ok {
+				zapLogger.Errorf("can not get permissions cache from context")
+				err = response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+				if err != nil {
+					zapLogger.Errorf("can not write response: %s", err)
+				}
+				return
+			}
+			if !cachedPermissions.isLoaded {
+				permissions, err := mw.getPermissions(ctx, userID)
+				if errors.Is(err, usecase.ErrNoUser) {
+					zapLogger.Errorf("user with id %d was not found", userID)
+					err = response.WriteResponse(w, []byte(`{"error": "user is not found"}`), http.StatusUnauthorized)
+					if err != nil {
+						zapLogger.Errorf("can not write response: %s", err)
+					}
+					return
+				}
+				if err != nil {
+					zapLogger.Errorf("internal error in getting user permissions: %s", err)
+					err = response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+					if err != nil {
+						zapLogger.Errorf("can not write response: %s", err)
+					}
+					return
+				}
+				cachedPermissions.permissions = make(map[string]struct{}, len(permissions))
+				for _, userPermission := range permissions {
+					cachedPermissions.permissions[userPermission] = struct{}{}
+				}
+				cachedPermissions.isLoaded = true
+			}
+			if _, ok = cachedPermissions.permissions[permission]; !ok {
+				zapLogger.Errorf("user %d has no permission %s", userID, permission)
+				err = response.WriteResponse(w, []byte(`{"error": "resource is forbidden for you"}`), http.StatusForbidden)
+				if err != nil {
+					zapLogger.Errorf("can not write response: %s", err)
+				}
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (mw *Middleware) getPermissions(ctx context.Context, userID uint64) ([]string, error) {
+	var permissions []string
+	var err error
+	if role, ok := ctx.Value(MyRoleKey).(string); ok {
+		permissions, err = mw.userUseCase.GetRolePermissions(ctx, role)
+	} else {
+		permissions, err = mw.userUseCase.GetUserPermissions(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	apiKey, ok := ctx.Value(MyAPIKeyKey).(*entity.APIKey)
+	if !ok || len(apiKey.Scopes) == 0 {
+		return permissions, nil
+	}
+	scopes := make(map[string]struct{}, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		scopes[scope] = struct{}{}
+	}
+	scopedPermissions := make([]string, 0, len(apiKey.Scopes))
+	for _, permission := range permissions {
+		if _, ok = scopes[permission]; ok {
+			scopedPermissions = append(scopedPermissions, permission)
+		}
+	}
+	return scopedPermissions, nil
+}
@@ -0,0 +1,237 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/oidc/usecase"
+	usecaseSession "github.com/ilyushkaaa/Filmoteka/internal/session/usecase"
+	twoFactorEntity "github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+	usecaseTwoFactor "github.com/ilyushkaaa/Filmoteka/internal/twofactor/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/client_ip"
+	"github.com/ilyushkaaa/Filmoteka/pkg/cookie"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+	"go.uber.org/zap"
+)
+
+type OIDCHandler struct {
+	oidcUseCase      usecase.OIDCUseCase
+	sessionUseCase   usecaseSession.SessionUseCase
+	twoFactorUseCase usecaseTwoFactor.TwoFactorUseCase
+	cookieConfig     *cookie.Config
+	postLoginURL     string
+}
+
+// NewOIDCHandler - если postLoginURL пуст, callback отвечает так же, как /api/v1/login
+func NewOIDCHandler(oidcUseCase usecase.OIDCUseCase, sessionUseCase usecaseSession.SessionUseCase,
+	twoFactorUseCase usecaseTwoFactor.TwoFactorUseCase, cookieConfig *cookie.Config, postLoginURL string) *OIDCHandler {
+	return &OIDCHandler{
+		oidcUseCase:      oidcUseCase,
+		sessionUseCase:   sessionUseCase,
+		twoFactorUseCase: twoFactorUseCase,
+		cookieConfig:     cookieConfig,
+		postLoginURL:     postLoginURL,
+	}
+}
+
+// StartLogin @Summary Начать вход через внешнего провайдера
+// @Description Перенаправляет пользователя на страницу входа провайдера OpenID Connect (authorization code с PKCE) и устанавливает cookie oidc_state
+// @Tags users
+// @Param PROVIDER path string true "Имя провайдера"
+// @Success 302 {object} string "Перенаправление на страницу входа провайдера"
+// @Failure 404 {object} string "Провайдер не настроен"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/oidc/{PROVIDER}/start [get]
+func (oh *OIDCHandler) StartLogin(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	providerName := mux.Vars(r)["PROVIDER"]
+	state, authURL, err := oh.oidcUseCase.StartLogin(r.Context(), providerName)
+	if err != nil {
+		writeLoginError(zapLogger, w, providerName, err)
+		return
+	}
+	oh.cookieConfig.SetOIDCStateCookie(w, state.ID, state.ExpiresAt)
+	zapLogger.Infof("login through provider %s was started", providerName)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback @Summary Завершить вход через внешнего провайдера
+// @Description Адрес возврата от провайдера. Проверяет state, обменивает код на ID токен, находит, связывает или создает пользователя, назначает роль по группам провайдера и создает сессию. Если задан oidcPostLoginURL, перенаправляет на него. Если пользователю нужен второй фактор, сессия не создается: ответ такой же, как у /api/v1/login (202 с challenge_id), а при заданном oidcPostLoginURL challenge_id и enrollment_required передаются в параметрах перенаправления
+// @Tags users
+// @Produce json
+// @Param PROVIDER path string true "Имя провайдера"
+// @Param code query string true "Код авторизации"
+// @Param state query string true "State, выданный при начале входа"
+// @Success 200 {object} dto.AuthResponse "Успешный вход, получен идентификатор сессии"
+// @Success 202 {object} dto.LoginChallengeResponse "Нужен второй фактор, вход завершается через /api/v1/login/totp"
+// @Success 302 {object} string "Успешный вход или запрос второго фактора, перенаправление на oidcPostLoginURL"
+// @Failure 400 {object} string "Неверный или просроченный state"
+// @Failure 401 {object} string "Провайдер отказал во входе или вернул неверный токен"
+// @Failure 403 {object} string "Пользователь заблокирован"
+// @Failure 404 {object} string "Провайдер не настроен"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/auth/oidc/{PROVIDER}/callback [get]
+func (oh *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	providerName := mux.Vars(r)["PROVIDER"]
+	query := r.URL.Query()
+	oh.cookieConfig.ClearOIDCStateCookie(w)
+	if providerError := query.Get("error"); providerError != "" {
+		zapLogger.Errorf("provider %s rejected login: %s %s", providerName, providerError, query.Get("error_description"))
+		errText := `{"error": "identity provider rejected login"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusUnauthorized)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	stateID := query.Get("state")
+	stateCookie, err := r.Cookie(cookie.OIDCStateCookieName)
+	if err != nil || stateID == "" || stateCookie.Value != stateID {
+		zapLogger.Errorf("state of login through provider %s does not match state cookie", providerName)
+		errText := `{"error": "login state does not match, start login again"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	user, err := oh.oidcUseCase.CompleteLogin(r.Context(), providerName, stateID, query.Get("code"))
+	if err != nil {
+		writeLoginError(zapLogger, w, providerName, err)
+		return
+	}
+	challenge, err := oh.twoFactorUseCase.StartLogin(r.Context(), user)
+	if err != nil {
+		zapLogger.Errorf("internal error in starting second factor check: %s", err)
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if challenge != nil {
+		zapLogger.Infof("login of user %d through provider %s is waiting for second factor", user.ID, providerName)
+		oh.writeLoginChallenge(w, r, challenge, zapLogger)
+		return
+	}
+	session, err := oh.sessionUseCase.CreateSession(r.Context(), user.ID, client_ip.FromRequest(r), r.UserAgent())
+	if err != nil {
+		zapLogger.Errorf("internal error in creating session: %s", err)
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	zapLogger.Infof("user %d logged in through provider %s", user.ID, providerName)
+	oh.cookieConfig.SetSessionCookies(w, session.ID, session.ExpiresAt)
+	if oh.postLoginURL != "" {
+		http.Redirect(w, r, oh.postLoginURL, http.StatusFound)
+		return
+	}
+	sessionJSON, err := json.Marshal(&dto.AuthResponse{SessionID: session.ID})
+	if err != nil {
+		zapLogger.Errorf("error in marshalling session: %s", err)
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, sessionJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("can not write response: %s", err)
+	}
+}
+
+func (oh *OIDCHandler) writeLoginChallenge(w http.ResponseWriter, r *http.Request,
+	challenge *twoFactorEntity.LoginChallenge, zapLogger *zap.SugaredLogger) {
+	if oh.postLoginURL != "" {
+		redirectURL, err := url.Parse(oh.postLoginURL)
+		if err != nil {
+			zapLogger.Errorf("bad post login url: %s", err)
+			errText := `{"error": "internal error"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if err != nil {
+				zapLogger.Errorf("can not write response: %s", err)
+			}
+			return
+		}
+		query := redirectURL.Query()
+		query.Set("challenge_id", challenge.ID)
+		query.Set("enrollment_required", strconv.FormatBool(challenge.EnrollmentRequired))
+		redirectURL.RawQuery = query.Encode()
+		http.Redirect(w, r, redirectURL.String(), http.StatusFound)
+		return
+	}
+	challengeJSON, err := json.Marshal(dto.NewLoginChallengeResponse(challenge))
+	if err != nil {
+		zapLogger.Errorf("error in marshalling login challenge: %s", err)
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, challengeJSON, http.StatusAccepted)
+	if err != nil {
+		zapLogger.Errorf("can not write response: %s", err)
+	}
+}
+
+var loginErrors = []struct {
+	err        error
+	statusCode int
+}{
+	{err: usecase.ErrUnknownProvider, statusCode: http.StatusNotFound},
+	{err: usecase.ErrBadState, statusCode: http.StatusBadRequest},
+	{err: usecase.ErrBadCode, statusCode: http.StatusUnauthorized},
+	{err: usecase.ErrBadToken, statusCode: http.StatusUnauthorized},
+	{err: usecase.ErrUserDisabled, statusCode: http.StatusForbidden},
+}
+
+func writeLoginError(zapLogger *zap.SugaredLogger, w http.ResponseWriter, providerName string, err error) {
+	zapLogger.Errorf("error in login through provider %s: %s", providerName, err)
+	statusCode := http.StatusInternalServerError
+	errText := `{"error": "internal error"}`
+	for _, loginError := range loginErrors {
+		if errors.Is(err, loginError.err) {
+			statusCode = loginError.statusCode
+			errText = fmt.Sprintf(`{"error": "%s"}`, loginError.err)
+			break
+		}
+	}
+	err = response.WriteResponse(w, []byte(errText), statusCode)
+	if err != nil {
+		zapLogger.Errorf("can not write response: %s", err)
+	}
+}
@@ -0,0 +1,321 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/ilyushkaaa/Filmoteka/internal/oidc/entity"
+	repoMock "github.com/ilyushkaaa/Filmoteka/internal/oidc/repo/mock"
+	"github.com/ilyushkaaa/Filmoteka/internal/oidc/usecase"
+	"github.com/ilyushkaaa/Filmoteka/internal/oidc/usecase/mock"
+	sessionEntity "github.com/ilyushkaaa/Filmoteka/internal/session/entity"
+	sessionMock "github.com/ilyushkaaa/Filmoteka/internal/session/usecase/mock"
+	twoFactorEntity "github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+	twoFactorMock "github.com/ilyushkaaa/Filmoteka/internal/twofactor/usecase/mock"
+	userEntity "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	userMock "github.com/ilyushkaaa/Filmoteka/internal/users/usecase/mock"
+	"github.com/ilyushkaaa/Filmoteka/pkg/cookie"
+	logger2 "github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/ilyushkaaa/Filmoteka/pkg/oidctest"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+var testCookieConfig = &cookie.Config{Path: "/", Secure: true, SameSite: http.SameSiteLaxMode}
+
+func newRouter(handler *OIDCHandler) http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/auth/oidc/{PROVIDER}/start", handler.StartLogin).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/auth/oidc/{PROVIDER}/callback", handler.Callback).Methods(http.MethodGet)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), logger2.MyLoggerKey, zap.NewNop().Sugar())
+		router.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func serve(router http.Handler, target string, cookies ...*http.Cookie) *http.Response {
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	for _, requestCookie := range cookies {
+		request.AddCookie(requestCookie)
+	}
+	respWriter := httptest.NewRecorder()
+	router.ServeHTTP(respWriter, request)
+	return respWriter.Result()
+}
+
+func findCookie(resp *http.Response, name string) *http.Cookie {
+	for _, responseCookie := range resp.Cookies() {
+		if responseCookie.Name == name {
+			return responseCookie
+		}
+	}
+	return nil
+}
+
+func TestStartLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase := mock.NewMockOIDCUseCase(ctrl)
+	router := newRouter(NewOIDCHandler(testUseCase, sessionMock.NewMockSessionUseCase(ctrl),
+		twoFactorMock.NewMockTwoFactorUseCase(ctrl), testCookieConfig, ""))
+
+	testUseCase.EXPECT().StartLogin(gomock.Any(), "unknown").Return(nil, "", usecase.ErrUnknownProvider)
+	resp := serve(router, "/api/v1/auth/oidc/unknown/start")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	testUseCase.EXPECT().StartLogin(gomock.Any(), "corp").Return(nil, "", fmt.Errorf("discovery failed"))
+	resp = serve(router, "/api/v1/auth/oidc/corp/start")
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	state := &entity.LoginState{ID: "state", Provider: "corp", ExpiresAt: time.Now().Add(time.Minute)}
+	testUseCase.EXPECT().StartLogin(gomock.Any(), "corp").Return(state, "https://idp.example/authorize?state=state", nil)
+	resp = serve(router, "/api/v1/auth/oidc/corp/start")
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.Equal(t, "https://idp.example/authorize?state=state", resp.Header.Get("Location"))
+	if stateCookie := findCookie(resp, cookie.OIDCStateCookieName); assert.NotNil(t, stateCookie) {
+		assert.Equal(t, "state", stateCookie.Value)
+	}
+}
+
+func TestCallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase := mock.NewMockOIDCUseCase(ctrl)
+	sessionTestUseCase := sessionMock.NewMockSessionUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	router := newRouter(NewOIDCHandler(testUseCase, sessionTestUseCase, twoFactorTestUseCase, testCookieConfig, ""))
+	redirectRouter := newRouter(NewOIDCHandler(testUseCase, sessionTestUseCase, twoFactorTestUseCase,
+		testCookieConfig, "/films"))
+	stateCookie := &http.Cookie{Name: cookie.OIDCStateCookieName, Value: "state"}
+	user := &userEntity.User{ID: 5, Username: "alice", Role: userEntity.RoleDefault}
+	admin := &userEntity.User{ID: 1, Username: "root", Role: userEntity.RoleAdmin}
+	session := &sessionEntity.Session{ID: "session", UserID: 5, ExpiresAt: time.Now().Add(time.Hour)}
+	challenge := &twoFactorEntity.LoginChallenge{ID: "challenge", UserID: 1, Username: "root",
+		EnrollmentRequired: true, ExpiresAt: time.Now().Add(time.Minute)}
+
+	testCases := []struct {
+		router     http.Handler
+		target     string
+		cookies    []*http.Cookie
+		setup      func()
+		statusCode int
+		location   string
+		withCookie bool
+	}{
+		{
+			router:     router,
+			target:     "/api/v1/auth/oidc/corp/callback?error=access_denied&state=state",
+			cookies:    []*http.Cookie{stateCookie},
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			router:     router,
+			target:     "/api/v1/auth/oidc/corp/callback?code=code&state=state",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			router:     router,
+			target:     "/api/v1/auth/oidc/corp/callback?code=code&state=other",
+			cookies:    []*http.Cookie{stateCookie},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			router:  router,
+			target:  "/api/v1/auth/oidc/corp/callback?code=code&state=state",
+			cookies: []*http.Cookie{stateCookie},
+			setup: func() {
+				testUseCase.EXPECT().CompleteLogin(gomock.Any(), "corp", "state", "code").Return(nil, usecase.ErrBadState)
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			router:  router,
+			target:  "/api/v1/auth/oidc/corp/callback?code=code&state=state",
+			cookies: []*http.Cookie{stateCookie},
+			setup: func() {
+				testUseCase.EXPECT().CompleteLogin(gomock.Any(), "corp", "state", "code").
+					Return(nil, fmt.Errorf("%w: invalid_grant", usecase.ErrBadCode))
+			},
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			router:  router,
+			target:  "/api/v1/auth/oidc/corp/callback?code=code&state=state",
+			cookies: []*http.Cookie{stateCookie},
+			setup: func() {
+				testUseCase.EXPECT().CompleteLogin(gomock.Any(), "corp", "state", "code").Return(nil, usecase.ErrUserDisabled)
+			},
+			statusCode: http.StatusForbidden,
+		},
+		{
+			router:  router,
+			target:  "/api/v1/auth/oidc/corp/callback?code=code&state=state",
+			cookies: []*http.Cookie{stateCookie},
+			setup: func() {
+				testUseCase.EXPECT().CompleteLogin(gomock.Any(), "corp", "state", "code").Return(user, nil)
+				twoFactorTestUseCase.EXPECT().StartLogin(gomock.Any(), user).Return(nil, nil)
+				sessionTestUseCase.EXPECT().CreateSession(gomock.Any(), uint64(5), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			router:  router,
+			target:  "/api/v1/auth/oidc/corp/callback?code=code&state=state",
+			cookies: []*http.Cookie{stateCookie},
+			setup: func() {
+				testUseCase.EXPECT().CompleteLogin(gomock.Any(), "corp", "state", "code").Return(user, nil)
+				twoFactorTestUseCase.EXPECT().StartLogin(gomock.Any(), user).Return(nil, nil)
+				sessionTestUseCase.EXPECT().CreateSession(gomock.Any(), uint64(5), gomock.Any(), gomock.Any()).Return(session, nil)
+			},
+			statusCode: http.StatusOK,
+			withCookie: true,
+		},
+		{
+			router:  redirectRouter,
+			target:  "/api/v1/auth/oidc/corp/callback?code=code&state=state",
+			cookies: []*http.Cookie{stateCookie},
+			setup: func() {
+				testUseCase.EXPECT().CompleteLogin(gomock.Any(), "corp", "state", "code").Return(user, nil)
+				twoFactorTestUseCase.EXPECT().StartLogin(gomock.Any(), user).Return(nil, nil)
+				sessionTestUseCase.EXPECT().CreateSession(gomock.Any(), uint64(5), gomock.Any(), gomock.Any()).Return(session, nil)
+			},
+			statusCode: http.StatusFound,
+			location:   "/films",
+			withCookie: true,
+		},
+		{
+			router:  router,
+			target:  "/api/v1/auth/oidc/corp/callback?code=code&state=state",
+			cookies: []*http.Cookie{stateCookie},
+			setup: func() {
+				testUseCase.EXPECT().CompleteLogin(gomock.Any(), "corp", "state", "code").Return(admin, nil)
+				twoFactorTestUseCase.EXPECT().StartLogin(gomock.Any(), admin).Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			router:  router,
+			target:  "/api/v1/auth/oidc/corp/callback?code=code&state=state",
+			cookies: []*http.Cookie{stateCookie},
+			setup: func() {
+				testUseCase.EXPECT().CompleteLogin(gomock.Any(), "corp", "state", "code").Return(admin, nil)
+				twoFactorTestUseCase.EXPECT().StartLogin(gomock.Any(), admin).Return(challenge, nil)
+			},
+			statusCode: http.StatusAccepted,
+		},
+		{
+			router:  redirectRouter,
+			target:  "/api/v1/auth/oidc/corp/callback?code=code&state=state",
+			cookies: []*http.Cookie{stateCookie},
+			setup: func() {
+				testUseCase.EXPECT().CompleteLogin(gomock.Any(), "corp", "state", "code").Return(admin, nil)
+				twoFactorTestUseCase.EXPECT().StartLogin(gomock.Any(), admin).Return(challenge, nil)
+			},
+			statusCode: http.StatusFound,
+			location:   "/films?challenge_id=challenge&enrollment_required=true",
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		resp := serve(tc.router, tc.target, tc.cookies...)
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+		if tc.location != "" {
+			assert.Equal(t, tc.location, resp.Header.Get("Location"))
+		}
+		// без второго фактора сессия не создается
+		sessionCookie := findCookie(resp, cookie.SessionCookieName)
+		if tc.withCookie && assert.NotNil(t, sessionCookie) {
+			assert.Equal(t, "session", sessionCookie.Value)
+		}
+		if !tc.withCookie {
+			assert.Nil(t, sessionCookie)
+		}
+	}
+}
+
+// TestLoginWithMockProvider проходит весь вход через локальный провайдер: от /start до сессии
+func TestLoginWithMockProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, err := oidctest.NewServer("filmoteka", "secret")
+	if err != nil {
+		t.Fatalf("error starting oidc server: %v", err)
+	}
+	defer server.Close()
+	server.SetUser(oidctest.User{Subject: "sub-1", PreferredUsername: "alice", Groups: []string{"editors"}})
+
+	states := make(map[string]*entity.LoginState)
+	stateRepo := repoMock.NewMockLoginStateRepo(ctrl)
+	stateRepo.EXPECT().SaveState(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, state *entity.LoginState) error {
+		states[state.ID] = state
+		return nil
+	})
+	stateRepo.EXPECT().PopState(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, stateID string) (*entity.LoginState, error) {
+		state := states[stateID]
+		delete(states, stateID)
+		return state, nil
+	})
+	identityRepo := repoMock.NewMockIdentityRepo(ctrl)
+	identityRepo.EXPECT().GetUserID(gomock.Any(), "corp", "sub-1").Return(uint64(0), nil)
+	identityRepo.EXPECT().LinkIdentity(gomock.Any(), &entity.Identity{Provider: "corp", Subject: "sub-1", UserID: 5}).Return(nil)
+	userUseCase := userMock.NewMockUserUseCase(ctrl)
+	userUseCase.EXPECT().CreateUser(gomock.Any(), "alice", gomock.Any(), "", userEntity.RoleEditor).
+		Return(&userEntity.User{ID: 5, Username: "alice", Role: userEntity.RoleEditor}, nil)
+	twoFactorUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	twoFactorUseCase.EXPECT().StartLogin(gomock.Any(), gomock.Any()).Return(nil, nil)
+	sessionUseCase := sessionMock.NewMockSessionUseCase(ctrl)
+	sessionUseCase.EXPECT().CreateSession(gomock.Any(), uint64(5), gomock.Any(), gomock.Any()).
+		Return(&sessionEntity.Session{ID: "session", UserID: 5, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+	oidcUseCase := usecase.NewOIDCUseCase([]usecase.ProviderConfig{{
+		Name:         "corp",
+		IssuerURL:    server.URL,
+		ClientID:     "filmoteka",
+		ClientSecret: "secret",
+		RedirectURL:  "http://filmoteka.local/api/v1/auth/oidc/corp/callback",
+		RoleMappings: []usecase.RoleMapping{{Group: "editors", Role: userEntity.RoleEditor}},
+	}}, stateRepo, identityRepo, userUseCase, usecase.DefaultStateTTL)
+	router := newRouter(NewOIDCHandler(oidcUseCase, sessionUseCase, twoFactorUseCase, testCookieConfig, ""))
+
+	resp := serve(router, "/api/v1/auth/oidc/corp/start")
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	stateCookie := findCookie(resp, cookie.OIDCStateCookieName)
+	if !assert.NotNil(t, stateCookie) {
+		return
+	}
+	callbackURL, err := server.Authorize(resp.Header.Get("Location"))
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/v1/auth/oidc/corp/callback", callbackURL.Path)
+
+	resp = serve(router, callbackURL.Path+"?"+callbackURL.RawQuery,
+		&http.Cookie{Name: stateCookie.Name, Value: stateCookie.Value})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	sessionCookie := findCookie(resp, cookie.SessionCookieName)
+	if assert.NotNil(t, sessionCookie) {
+		assert.Equal(t, "session", sessionCookie.Value)
+	}
+
+	// повторный callback с тем же state отклоняется
+	stateRepo.EXPECT().PopState(gomock.Any(), callbackURL.Query().Get("state")).Return(nil, nil)
+	resp = serve(router, callbackURL.Path+"?"+url.Values{"code": {"code"}, "state": {stateCookie.Value}}.Encode(),
+		&http.Cookie{Name: stateCookie.Name, Value: stateCookie.Value})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
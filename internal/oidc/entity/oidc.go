@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+type LoginState struct {
+	ID           string    `json:"id"`
+	Provider     string    `json:"provider"`
+	CodeVerifier string    `json:"code_verifier"`
+	Nonce        string    `json:"nonce"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type Identity struct {
+	Provider string
+	Subject  string
+	UserID   uint64
+}
+
+type Claims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Name              string
+	Groups            []string
+}
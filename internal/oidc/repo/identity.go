@@ -0,0 +1,47 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/oidc/entity"
+)
+
+//go:generate mockgen -source=identity.go -destination=identity_mock.go -package=repo IdentityRepo
+type IdentityRepo interface {
+	GetUserID(ctx context.Context, provider, subject string) (uint64, error)
+	LinkIdentity(ctx context.Context, identity *entity.Identity) error
+}
+
+type IdentityRepoPG struct {
+	db *sql.DB
+}
+
+func NewIdentityRepo(db *sql.DB) *IdentityRepoPG {
+	return &IdentityRepoPG{
+		db: db,
+	}
+}
+
+func (r *IdentityRepoPG) GetUserID(ctx context.Context, provider, subject string) (uint64, error) {
+	var userID uint64
+	err := r.db.
+		QueryRowContext(ctx, "SELECT user_id FROM user_identities WHERE provider = $1 AND subject = $2", provider, subject).
+		Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+func (r *IdentityRepoPG) LinkIdentity(ctx context.Context, identity *entity.Identity) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO user_identities (provider, subject, user_id) VALUES ($1, $2, $3)
+        ON CONFLICT (provider, subject) DO NOTHING
+    `, identity.Provider, identity.Subject, identity.UserID)
+	return err
+}
@@ -0,0 +1,70 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/oidc/entity"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestGetUserID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewIdentityRepo(db)
+
+	mock.ExpectQuery("SELECT user_id FROM user_identities WHERE provider = (.+) AND subject = (.+)").
+		WithArgs("corp", "sub-1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(7))
+	userID, err := repo.GetUserID(context.Background(), "corp", "sub-1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), userID)
+
+	mock.ExpectQuery("SELECT user_id FROM user_identities WHERE provider = (.+) AND subject = (.+)").
+		WithArgs("corp", "sub-2").
+		WillReturnError(sql.ErrNoRows)
+	userID, err = repo.GetUserID(context.Background(), "corp", "sub-2")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), userID)
+
+	mock.ExpectQuery("SELECT user_id FROM user_identities WHERE provider = (.+) AND subject = (.+)").
+		WithArgs("corp", "sub-1").
+		WillReturnError(fmt.Errorf("error"))
+	_, err = repo.GetUserID(context.Background(), "corp", "sub-1")
+	assert.Error(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestLinkIdentity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewIdentityRepo(db)
+	identity := &entity.Identity{Provider: "corp", Subject: "sub-1", UserID: 7}
+
+	mock.ExpectExec("INSERT INTO user_identities (.+) ON CONFLICT (.+) DO NOTHING").
+		WithArgs("corp", "sub-1", uint64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	err = repo.LinkIdentity(context.Background(), identity)
+	assert.NoError(t, err)
+
+	mock.ExpectExec("INSERT INTO user_identities").
+		WillReturnError(fmt.Errorf("error"))
+	err = repo.LinkIdentity(context.Background(), identity)
+	assert.Error(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
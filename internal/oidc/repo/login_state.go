@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/ilyushkaaa/Filmoteka/internal/oidc/entity"
+)
+
+//go:generate mockgen -source=login_state.go -destination=login_state_mock.go -package=repo LoginStateRepo
+type LoginStateRepo interface {
+	SaveState(ctx context.Context, state *entity.LoginState) error
+	PopState(ctx context.Context, stateID string) (*entity.LoginState, error)
+}
+
+const loginStateKeyPrefix = "oidc_state:"
+
+type LoginStateRepoRedis struct {
+	redisPool *redis.Pool
+}
+
+func NewLoginStateRepo(redisPool *redis.Pool) *LoginStateRepoRedis {
+	return &LoginStateRepoRedis{
+		redisPool: redisPool,
+	}
+}
+
+func (r *LoginStateRepoRedis) SaveState(ctx context.Context, state *entity.LoginState) error {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	stateKey := loginStateKeyPrefix + state.ID
+	_, err = redis.DoContext(conn, ctx, "SET", stateKey, stateJSON)
+	if err != nil {
+		return err
+	}
+	_, err = redis.DoContext(conn, ctx, "EXPIREAT", stateKey, state.ExpiresAt.Unix())
+	return err
+}
+
+// PopState удаляет состояние, чтобы один state нельзя было использовать дважды
+func (r *LoginStateRepoRedis) PopState(ctx context.Context, stateID string) (*entity.LoginState, error) {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	stateJSON, err := redis.Bytes(redis.DoContext(conn, ctx, "GETDEL", loginStateKeyPrefix+stateID))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := &entity.LoginState{}
+	err = json.Unmarshal(stateJSON, state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
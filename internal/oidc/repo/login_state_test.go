@@ -0,0 +1,127 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/ilyushkaaa/Filmoteka/internal/oidc/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockRedisConn struct {
+	values    map[string][]byte
+	expiresAt map[string]int64
+}
+
+func newMockRedisConn() *MockRedisConn {
+	return &MockRedisConn{
+		values:    make(map[string][]byte),
+		expiresAt: make(map[string]int64),
+	}
+}
+
+func (m *MockRedisConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	// пул отправляет пустую команду, когда соединение возвращается в него
+	if commandName == "" {
+		return nil, nil
+	}
+	key := args[0].(string)
+	if strings.HasSuffix(key, "broken") {
+		return nil, fmt.Errorf("err")
+	}
+	switch commandName {
+	case "SET":
+		m.values[key] = args[1].([]byte)
+		return "OK", nil
+	case "GETDEL":
+		value, ok := m.values[key]
+		if !ok {
+			return nil, nil
+		}
+		delete(m.values, key)
+		return value, nil
+	case "EXPIREAT":
+		m.expiresAt[key] = args[1].(int64)
+		return int64(1), nil
+	}
+	return nil, fmt.Errorf("err")
+}
+
+func (m *MockRedisConn) DoContext(_ context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	return m.Do(commandName, args...)
+}
+
+func (m *MockRedisConn) Close() error {
+	return nil
+}
+
+func (m *MockRedisConn) Err() error {
+	return nil
+}
+
+func (m *MockRedisConn) Send(_ string, _ ...interface{}) error {
+	return nil
+}
+
+func (m *MockRedisConn) Flush() error {
+	return nil
+}
+
+func (m *MockRedisConn) Receive() (interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRedisConn) ReceiveContext(_ context.Context) (interface{}, error) {
+	return nil, nil
+}
+
+var _ redis.ConnWithContext = &MockRedisConn{}
+
+func newMockRedisPool(redisConn redis.Conn) *redis.Pool {
+	return &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redisConn, nil
+		},
+	}
+}
+
+func TestLoginState(t *testing.T) {
+	redisConn := newMockRedisConn()
+	repo := NewLoginStateRepo(newMockRedisPool(redisConn))
+	expiresAt := time.Unix(time.Now().Add(time.Minute).Unix(), 0)
+	state := &entity.LoginState{
+		ID:           "state",
+		Provider:     "corp",
+		CodeVerifier: "verifier",
+		Nonce:        "nonce",
+		ExpiresAt:    expiresAt,
+	}
+
+	savedState, err := repo.PopState(context.Background(), "state")
+	assert.NoError(t, err)
+	assert.Nil(t, savedState)
+
+	err = repo.SaveState(context.Background(), state)
+	assert.NoError(t, err)
+	assert.Equal(t, expiresAt.Unix(), redisConn.expiresAt["oidc_state:state"])
+
+	savedState, err = repo.PopState(context.Background(), "state")
+	assert.NoError(t, err)
+	assert.Equal(t, "corp", savedState.Provider)
+	assert.Equal(t, "verifier", savedState.CodeVerifier)
+	assert.Equal(t, "nonce", savedState.Nonce)
+	assert.True(t, expiresAt.Equal(savedState.ExpiresAt))
+
+	savedState, err = repo.PopState(context.Background(), "state")
+	assert.NoError(t, err)
+	assert.Nil(t, savedState)
+
+	_, err = repo.PopState(context.Background(), "broken")
+	assert.Error(t, err)
+	err = repo.SaveState(context.Background(), &entity.LoginState{ID: "broken", ExpiresAt: expiresAt})
+	assert.Error(t, err)
+}
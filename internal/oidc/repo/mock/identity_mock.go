@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: identity.go
+
+// Package repo is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/ilyushkaaa/Filmoteka/internal/oidc/entity"
+)
+
+// MockIdentityRepo is a mock of IdentityRepo interface.
+type MockIdentityRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdentityRepoMockRecorder
+}
+
+// MockIdentityRepoMockRecorder is the mock recorder for MockIdentityRepo.
+type MockIdentityRepoMockRecorder struct {
+	mock *MockIdentityRepo
+}
+
+// NewMockIdentityRepo creates a new mock instance.
+func NewMockIdentityRepo(ctrl *gomock.Controller) *MockIdentityRepo {
+	mock := &MockIdentityRepo{ctrl: ctrl}
+	mock.recorder = &MockIdentityRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdentityRepo) EXPECT() *MockIdentityRepoMockRecorder {
+	return m.recorder
+}
+
+// GetUserID mocks base method.
+func (m *MockIdentityRepo) GetUserID(ctx context.Context, provider, subject string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserID", ctx, provider, subject)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserID indicates an expected call of GetUserID.
+func (mr *MockIdentityRepoMockRecorder) GetUserID(ctx, provider, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserID", reflect.TypeOf((*MockIdentityRepo)(nil).GetUserID), ctx, provider, subject)
+}
+
+// LinkIdentity mocks base method.
+func (m *MockIdentityRepo) LinkIdentity(ctx context.Context, identity *entity.Identity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkIdentity", ctx, identity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkIdentity indicates an expected call of LinkIdentity.
+func (mr *MockIdentityRepoMockRecorder) LinkIdentity(ctx, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkIdentity", reflect.TypeOf((*MockIdentityRepo)(nil).LinkIdentity), ctx, identity)
+}
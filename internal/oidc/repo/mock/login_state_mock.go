@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: login_state.go
+
+// Package repo is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/ilyushkaaa/Filmoteka/internal/oidc/entity"
+)
+
+// MockLoginStateRepo is a mock of LoginStateRepo interface.
+type MockLoginStateRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginStateRepoMockRecorder
+}
+
+// MockLoginStateRepoMockRecorder is the mock recorder for MockLoginStateRepo.
+type MockLoginStateRepoMockRecorder struct {
+	mock *MockLoginStateRepo
+}
+
+// NewMockLoginStateRepo creates a new mock instance.
+func NewMockLoginStateRepo(ctrl *gomock.Controller) *MockLoginStateRepo {
+	mock := &MockLoginStateRepo{ctrl: ctrl}
+	mock.recorder = &MockLoginStateRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginStateRepo) EXPECT() *MockLoginStateRepoMockRecorder {
+	return m.recorder
+}
+
+// PopState mocks base method.
+func (m *MockLoginStateRepo) PopState(ctx context.Context, stateID string) (*entity.LoginState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PopState", ctx, stateID)
+	ret0, _ := ret[0].(*entity.LoginState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PopState indicates an expected call of PopState.
+func (mr *MockLoginStateRepoMockRecorder) PopState(ctx, stateID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PopState", reflect.TypeOf((*MockLoginStateRepo)(nil).PopState), ctx, stateID)
+}
+
+// SaveState mocks base method.
+func (m *MockLoginStateRepo) SaveState(ctx context.Context, state *entity.LoginState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveState", ctx, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveState indicates an expected call of SaveState.
+func (mr *MockLoginStateRepoMockRecorder) SaveState(ctx, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveState", reflect.TypeOf((*MockLoginStateRepo)(nil).SaveState), ctx, state)
+}
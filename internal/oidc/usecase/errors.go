@@ -0,0 +1,11 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	ErrBadState        = errors.New("login state is invalid or expired")
+	ErrBadCode         = errors.New("authorization code was rejected by identity provider")
+	ErrBadToken        = errors.New("id token from identity provider is invalid")
+	ErrUserDisabled    = errors.New("user is disabled")
+)
@@ -0,0 +1,68 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: oidc.go
+
+// Package usecase is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/ilyushkaaa/Filmoteka/internal/oidc/entity"
+	entity0 "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+)
+
+// MockOIDCUseCase is a mock of OIDCUseCase interface.
+type MockOIDCUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCUseCaseMockRecorder
+}
+
+// MockOIDCUseCaseMockRecorder is the mock recorder for MockOIDCUseCase.
+type MockOIDCUseCaseMockRecorder struct {
+	mock *MockOIDCUseCase
+}
+
+// NewMockOIDCUseCase creates a new mock instance.
+func NewMockOIDCUseCase(ctrl *gomock.Controller) *MockOIDCUseCase {
+	mock := &MockOIDCUseCase{ctrl: ctrl}
+	mock.recorder = &MockOIDCUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCUseCase) EXPECT() *MockOIDCUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CompleteLogin mocks base method.
+func (m *MockOIDCUseCase) CompleteLogin(ctx context.Context, providerName, stateID, code string) (*entity0.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteLogin", ctx, providerName, stateID, code)
+	ret0, _ := ret[0].(*entity0.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteLogin indicates an expected call of CompleteLogin.
+func (mr *MockOIDCUseCaseMockRecorder) CompleteLogin(ctx, providerName, stateID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteLogin", reflect.TypeOf((*MockOIDCUseCase)(nil).CompleteLogin), ctx, providerName, stateID, code)
+}
+
+// StartLogin mocks base method.
+func (m *MockOIDCUseCase) StartLogin(ctx context.Context, providerName string) (*entity.LoginState, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartLogin", ctx, providerName)
+	ret0, _ := ret[0].(*entity.LoginState)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// StartLogin indicates an expected call of StartLogin.
+func (mr *MockOIDCUseCaseMockRecorder) StartLogin(ctx, providerName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartLogin", reflect.TypeOf((*MockOIDCUseCase)(nil).StartLogin), ctx, providerName)
+}
@@ -0,0 +1,382 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/ilyushkaaa/Filmoteka/internal/oidc/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/oidc/repo"
+	userEntity "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	usecaseUser "github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
+	"golang.org/x/oauth2"
+)
+
+const (
+	DefaultStateTTL    = 10 * time.Minute
+	DefaultGroupsClaim = "groups"
+
+	providerTimeout = 10 * time.Second
+
+	randomValueBytes      = 32
+	maxUsernameLength     = 50
+	maxUsernameCandidates = 10
+)
+
+type RoleMapping struct {
+	Group string
+	Role  string
+}
+
+// ProviderConfig - настройки провайдера; пользователь получает роль первой из RoleMappings группы, в которой состоит
+type ProviderConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+	RoleMappings []RoleMapping
+}
+
+//go:generate mockgen -source=oidc.go -destination=oidc_mock.go -package=usecase OIDCUseCase
+type OIDCUseCase interface {
+	StartLogin(ctx context.Context, providerName string) (*entity.LoginState, string, error)
+	CompleteLogin(ctx context.Context, providerName, stateID, code string) (*userEntity.User, error)
+}
+
+// provider выполняет discovery при первом входе, чтобы недоступный провайдер не мешал запуску
+type provider struct {
+	config ProviderConfig
+
+	mu           sync.Mutex
+	oauth2Config *oauth2.Config
+	verifier     *oidc.IDTokenVerifier
+}
+
+type OIDCUseCaseApp struct {
+	providers    map[string]*provider
+	stateRepo    repo.LoginStateRepo
+	identityRepo repo.IdentityRepo
+	userUseCase  usecaseUser.UserUseCase
+	httpClient   *http.Client
+	stateTTL     time.Duration
+}
+
+func NewOIDCUseCase(providers []ProviderConfig, stateRepo repo.LoginStateRepo, identityRepo repo.IdentityRepo,
+	userUseCase usecaseUser.UserUseCase, stateTTL time.Duration) *OIDCUseCaseApp {
+	providersByName := make(map[string]*provider, len(providers))
+	for _, providerConfig := range providers {
+		if providerConfig.GroupsClaim == "" {
+			providerConfig.GroupsClaim = DefaultGroupsClaim
+		}
+		providersByName[providerConfig.Name] = &provider{config: providerConfig}
+	}
+	return &OIDCUseCaseApp{
+		providers:    providersByName,
+		stateRepo:    stateRepo,
+		identityRepo: identityRepo,
+		userUseCase:  userUseCase,
+		httpClient:   &http.Client{Timeout: providerTimeout},
+		stateTTL:     stateTTL,
+	}
+}
+
+func (ou *OIDCUseCaseApp) StartLogin(ctx context.Context, providerName string) (*entity.LoginState, string, error) {
+	p, ok := ou.providers[providerName]
+	if !ok {
+		return nil, "", ErrUnknownProvider
+	}
+	ctx, cancel := ou.newContext(ctx)
+	defer cancel()
+	oauth2Config, _, err := p.init(ctx, ou.httpClient)
+	if err != nil {
+		return nil, "", err
+	}
+	stateID, err := randomValue()
+	if err != nil {
+		return nil, "", err
+	}
+	nonce, err := randomValue()
+	if err != nil {
+		return nil, "", err
+	}
+	state := &entity.LoginState{
+		ID:           stateID,
+		Provider:     providerName,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(ou.stateTTL),
+	}
+	err = ou.stateRepo.SaveState(ctx, state)
+	if err != nil {
+		return nil, "", err
+	}
+	authURL := oauth2Config.AuthCodeURL(state.ID, oidc.Nonce(state.Nonce), oauth2.S256ChallengeOption(state.CodeVerifier))
+	return state, authURL, nil
+}
+
+func (ou *OIDCUseCaseApp) CompleteLogin(ctx context.Context, providerName, stateID, code string) (*userEntity.User, error) {
+	p, ok := ou.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	state, err := ou.stateRepo.PopState(ctx, stateID)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil || state.Provider != providerName || !time.Now().Before(state.ExpiresAt) {
+		return nil, ErrBadState
+	}
+	claims, err := ou.exchangeCode(ctx, p, state, code)
+	if err != nil {
+		return nil, err
+	}
+	user, err := ou.getOrCreateUser(ctx, p.config, claims)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsDisabled {
+		return nil, ErrUserDisabled
+	}
+	return ou.syncRole(ctx, p.config, user, claims.Groups)
+}
+
+func (ou *OIDCUseCaseApp) exchangeCode(ctx context.Context, p *provider, state *entity.LoginState, code string) (*entity.Claims, error) {
+	ctx, cancel := ou.newContext(ctx)
+	defer cancel()
+	oauth2Config, verifier, err := p.init(ctx, ou.httpClient)
+	if err != nil {
+		return nil, err
+	}
+	token, err := oauth2Config.Exchange(ctx, code, oauth2.VerifierOption(state.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBadCode, err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: token response has no id_token", ErrBadToken)
+	}
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBadToken, err)
+	}
+	if idToken.Nonce != state.Nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrBadToken)
+	}
+	return parseClaims(idToken, p.config.GroupsClaim)
+}
+
+// getOrCreateUser связывает новую учетную запись провайдера с пользователем с тем же подтвержденным email
+func (ou *OIDCUseCaseApp) getOrCreateUser(ctx context.Context, config ProviderConfig, claims *entity.Claims) (*userEntity.User, error) {
+	userID, err := ou.identityRepo.GetUserID(ctx, config.Name, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if userID != 0 {
+		return ou.userUseCase.GetUserByID(ctx, userID)
+	}
+	user, err := ou.findUserByEmail(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		user, err = ou.createUser(ctx, claims, mapRole(config.RoleMappings, claims.Groups))
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = ou.identityRepo.LinkIdentity(ctx, &entity.Identity{
+		Provider: config.Name,
+		Subject:  claims.Subject,
+		UserID:   user.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (ou *OIDCUseCaseApp) findUserByEmail(ctx context.Context, claims *entity.Claims) (*userEntity.User, error) {
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, nil
+	}
+	users, err := ou.userUseCase.GetUsersByEmail(ctx, claims.Email)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) != 1 {
+		return nil, nil
+	}
+	return &users[0], nil
+}
+
+// createUser задает случайный пароль: такой пользователь входит через провайдера
+func (ou *OIDCUseCaseApp) createUser(ctx context.Context, claims *entity.Claims, role string) (*userEntity.User, error) {
+	password, err := randomValue()
+	if err != nil {
+		return nil, err
+	}
+	email := ""
+	if claims.EmailVerified {
+		email = claims.Email
+	}
+	baseUsername := usernameFromClaims(claims)
+	for i := 1; i <= maxUsernameCandidates; i++ {
+		username := baseUsername
+		if i > 1 {
+			username = fmt.Sprintf("%s_%d", baseUsername, i)
+		}
+		if i == maxUsernameCandidates {
+			suffix := make([]byte, 4)
+			if _, err = rand.Read(suffix); err != nil {
+				return nil, err
+			}
+			username = baseUsername + "_" + hex.EncodeToString(suffix)
+		}
+		user, err := ou.userUseCase.CreateUser(ctx, username, password, email, role)
+		if errors.Is(err, usecaseUser.ErrUserAlreadyExists) {
+			continue
+		}
+		return user, err
+	}
+	return nil, usecaseUser.ErrUserAlreadyExists
+}
+
+// syncRole не понижает последнего администратора
+func (ou *OIDCUseCaseApp) syncRole(ctx context.Context, config ProviderConfig, user *userEntity.User, groups []string) (*userEntity.User, error) {
+	if len(config.RoleMappings) == 0 {
+		return user, nil
+	}
+	role := mapRole(config.RoleMappings, groups)
+	if role == user.Role {
+		return user, nil
+	}
+	err := ou.userUseCase.SetUserRole(ctx, user.ID, role)
+	if errors.Is(err, usecaseUser.ErrLastAdmin) {
+		return user, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	user.Role = role
+	return user, nil
+}
+
+func (ou *OIDCUseCaseApp) newContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ctx, providerTimeout)
+	return oidc.ClientContext(ctx, ou.httpClient), cancel
+}
+
+func (p *provider) init(ctx context.Context, httpClient *http.Client) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.oauth2Config != nil {
+		return p.oauth2Config, p.verifier, nil
+	}
+	oidcProvider, err := oidc.NewProvider(ctx, p.config.IssuerURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("discovery of provider %s failed: %w", p.config.Name, err)
+	}
+	p.oauth2Config = &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		Endpoint:     oidcProvider.Endpoint(),
+		RedirectURL:  p.config.RedirectURL,
+		Scopes:       append([]string{oidc.ScopeOpenID}, p.config.Scopes...),
+	}
+	// ключи провайдера загружаются при проверке токена, поэтому контекст discovery здесь не подходит
+	p.verifier = oidcProvider.VerifierContext(oidc.ClientContext(context.Background(), httpClient),
+		&oidc.Config{ClientID: p.config.ClientID})
+	return p.oauth2Config, p.verifier, nil
+}
+
+func parseClaims(idToken *oidc.IDToken, groupsClaim string) (*entity.Claims, error) {
+	var standardClaims struct {
+		Email             string `json:"email"`
+		EmailVerified     bool   `json:"email_verified"`
+		PreferredUsername string `json:"preferred_username"`
+		Name              string `json:"name"`
+	}
+	if err := idToken.Claims(&standardClaims); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBadToken, err)
+	}
+	var allClaims map[string]interface{}
+	if err := idToken.Claims(&allClaims); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBadToken, err)
+	}
+	return &entity.Claims{
+		Subject:           idToken.Subject,
+		Email:             standardClaims.Email,
+		EmailVerified:     standardClaims.EmailVerified,
+		PreferredUsername: standardClaims.PreferredUsername,
+		Name:              standardClaims.Name,
+		Groups:            groupsFromClaim(allClaims[groupsClaim]),
+	}, nil
+}
+
+func groupsFromClaim(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		groups := make([]string, 0, len(value))
+		for _, group := range value {
+			if groupName, ok := group.(string); ok {
+				groups = append(groups, groupName)
+			}
+		}
+		return groups
+	}
+	return nil
+}
+
+func mapRole(roleMappings []RoleMapping, groups []string) string {
+	for _, mapping := range roleMappings {
+		for _, group := range groups {
+			if group == mapping.Group {
+				return mapping.Role
+			}
+		}
+	}
+	return userEntity.RoleDefault
+}
+
+func usernameFromClaims(claims *entity.Claims) string {
+	emailName, _, _ := strings.Cut(claims.Email, "@")
+	for _, candidate := range []string{claims.PreferredUsername, emailName, claims.Name} {
+		username := strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+				return r
+			}
+			if r == '.' || r == '-' || r == ' ' {
+				return '_'
+			}
+			return -1
+		}, candidate)
+		if len(username) > maxUsernameLength {
+			username = username[:maxUsernameLength]
+		}
+		if username != "" {
+			return username
+		}
+	}
+	return "user"
+}
+
+func randomValue() (string, error) {
+	randomBytes := make([]byte, randomValueBytes)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ilyushkaaa/Filmoteka/internal/oidc/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/oidc/repo/mock"
+	userEntity "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	usecaseUser "github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
+	userMock "github.com/ilyushkaaa/Filmoteka/internal/users/usecase/mock"
+	"github.com/ilyushkaaa/Filmoteka/pkg/oidctest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRedirectURL = "http://filmoteka.local/api/v1/auth/oidc/corp/callback"
+
+type testEnv struct {
+	useCase      *OIDCUseCaseApp
+	server       *oidctest.Server
+	stateRepo    *mock.MockLoginStateRepo
+	identityRepo *mock.MockIdentityRepo
+	userUseCase  *userMock.MockUserUseCase
+}
+
+func newTestEnv(t *testing.T, ctrl *gomock.Controller) *testEnv {
+	server, err := oidctest.NewServer("filmoteka", "secret")
+	if err != nil {
+		t.Fatalf("error starting oidc server: %v", err)
+	}
+	t.Cleanup(server.Close)
+	env := &testEnv{
+		server:       server,
+		stateRepo:    mock.NewMockLoginStateRepo(ctrl),
+		identityRepo: mock.NewMockIdentityRepo(ctrl),
+		userUseCase:  userMock.NewMockUserUseCase(ctrl),
+	}
+	providers := []ProviderConfig{{
+		Name:         "corp",
+		IssuerURL:    server.URL,
+		ClientID:     "filmoteka",
+		ClientSecret: "secret",
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"profile", "email"},
+		RoleMappings: []RoleMapping{{Group: "film-admins", Role: userEntity.RoleAdmin}, {Group: "editors", Role: userEntity.RoleEditor}},
+	}}
+	env.useCase = NewOIDCUseCase(providers, env.stateRepo, env.identityRepo, env.userUseCase, DefaultStateTTL)
+	return env
+}
+
+// authorize начинает вход, проходит страницу провайдера и возвращает сохраненное состояние и код
+func (env *testEnv) authorize(t *testing.T, user oidctest.User) (*entity.LoginState, string) {
+	var savedState *entity.LoginState
+	env.stateRepo.EXPECT().SaveState(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, state *entity.LoginState) error {
+		savedState = state
+		return nil
+	})
+	_, authURL, err := env.useCase.StartLogin(context.Background(), "corp")
+	if err != nil {
+		t.Fatalf("error starting login: %v", err)
+	}
+	env.server.SetUser(user)
+	callbackURL, err := env.server.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("error authorizing: %v", err)
+	}
+	assert.Equal(t, savedState.ID, callbackURL.Query().Get("state"))
+	return savedState, callbackURL.Query().Get("code")
+}
+
+func TestStartLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	env := newTestEnv(t, ctrl)
+
+	_, _, err := env.useCase.StartLogin(context.Background(), "unknown")
+	assert.Equal(t, ErrUnknownProvider, err)
+
+	env.stateRepo.EXPECT().SaveState(gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
+	_, _, err = env.useCase.StartLogin(context.Background(), "corp")
+	assert.Error(t, err)
+
+	env.stateRepo.EXPECT().SaveState(gomock.Any(), gomock.Any()).Return(nil)
+	state, authURL, err := env.useCase.StartLogin(context.Background(), "corp")
+	assert.NoError(t, err)
+	assert.Equal(t, "corp", state.Provider)
+	assert.WithinDuration(t, time.Now().Add(DefaultStateTTL), state.ExpiresAt, time.Minute)
+	parsedURL, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	query := parsedURL.Query()
+	assert.Equal(t, env.server.URL+"/authorize", parsedURL.Scheme+"://"+parsedURL.Host+parsedURL.Path)
+	assert.Equal(t, "filmoteka", query.Get("client_id"))
+	assert.Equal(t, testRedirectURL, query.Get("redirect_uri"))
+	assert.Equal(t, "openid profile email", query.Get("scope"))
+	assert.Equal(t, state.ID, query.Get("state"))
+	assert.Equal(t, state.Nonce, query.Get("nonce"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.NotEmpty(t, query.Get("code_challenge"))
+	assert.NotContains(t, authURL, state.CodeVerifier)
+}
+
+func TestCompleteLoginRejects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	env := newTestEnv(t, ctrl)
+	user := oidctest.User{Subject: "sub-1", PreferredUsername: "alice"}
+
+	_, err := env.useCase.CompleteLogin(context.Background(), "unknown", "state", "code")
+	assert.Equal(t, ErrUnknownProvider, err)
+
+	env.stateRepo.EXPECT().PopState(gomock.Any(), "state").Return(nil, fmt.Errorf("error"))
+	_, err = env.useCase.CompleteLogin(context.Background(), "corp", "state", "code")
+	assert.Error(t, err)
+
+	env.stateRepo.EXPECT().PopState(gomock.Any(), "state").Return(nil, nil)
+	_, err = env.useCase.CompleteLogin(context.Background(), "corp", "state", "code")
+	assert.Equal(t, ErrBadState, err)
+
+	env.stateRepo.EXPECT().PopState(gomock.Any(), "state").Return(&entity.LoginState{ID: "state", Provider: "other",
+		ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	_, err = env.useCase.CompleteLogin(context.Background(), "corp", "state", "code")
+	assert.Equal(t, ErrBadState, err)
+
+	env.stateRepo.EXPECT().PopState(gomock.Any(), "state").Return(&entity.LoginState{ID: "state", Provider: "corp",
+		ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+	_, err = env.useCase.CompleteLogin(context.Background(), "corp", "state", "code")
+	assert.Equal(t, ErrBadState, err)
+
+	state, _ := env.authorize(t, user)
+	env.stateRepo.EXPECT().PopState(gomock.Any(), state.ID).Return(state, nil)
+	_, err = env.useCase.CompleteLogin(context.Background(), "corp", state.ID, "forged-code")
+	assert.ErrorIs(t, err, ErrBadCode)
+
+	// код, перехваченный без code_verifier, бесполезен
+	state, code := env.authorize(t, user)
+	stolenState := *state
+	stolenState.CodeVerifier = "attacker-verifier-attacker-verifier-attacker"
+	env.stateRepo.EXPECT().PopState(gomock.Any(), state.ID).Return(&stolenState, nil)
+	_, err = env.useCase.CompleteLogin(context.Background(), "corp", state.ID, code)
+	assert.ErrorIs(t, err, ErrBadCode)
+
+	state, code = env.authorize(t, user)
+	replayedState := *state
+	replayedState.Nonce = "other-nonce"
+	env.stateRepo.EXPECT().PopState(gomock.Any(), state.ID).Return(&replayedState, nil)
+	_, err = env.useCase.CompleteLogin(context.Background(), "corp", state.ID, code)
+	assert.ErrorIs(t, err, ErrBadToken)
+
+	state, code = env.authorize(t, user)
+	env.stateRepo.EXPECT().PopState(gomock.Any(), state.ID).Return(state, nil)
+	env.identityRepo.EXPECT().GetUserID(gomock.Any(), "corp", "sub-1").Return(uint64(5), nil)
+	env.userUseCase.EXPECT().GetUserByID(gomock.Any(), uint64(5)).
+		Return(&userEntity.User{ID: 5, Username: "alice", Role: userEntity.RoleDefault, IsDisabled: true}, nil)
+	_, err = env.useCase.CompleteLogin(context.Background(), "corp", state.ID, code)
+	assert.Equal(t, ErrUserDisabled, err)
+}
+
+func TestCompleteLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	env := newTestEnv(t, ctrl)
+
+	testCases := []struct {
+		name     string
+		user     oidctest.User
+		setup    func()
+		expected *userEntity.User
+	}{
+		{
+			name: "linked user gets role from groups",
+			user: oidctest.User{Subject: "sub-1", PreferredUsername: "alice", Groups: []string{"staff", "editors"}},
+			setup: func() {
+				env.identityRepo.EXPECT().GetUserID(gomock.Any(), "corp", "sub-1").Return(uint64(5), nil)
+				env.userUseCase.EXPECT().GetUserByID(gomock.Any(), uint64(5)).
+					Return(&userEntity.User{ID: 5, Username: "alice", Role: userEntity.RoleDefault}, nil)
+				env.userUseCase.EXPECT().SetUserRole(gomock.Any(), uint64(5), userEntity.RoleEditor).Return(nil)
+			},
+			expected: &userEntity.User{ID: 5, Username: "alice", Role: userEntity.RoleEditor},
+		},
+		{
+			name: "last admin keeps role",
+			user: oidctest.User{Subject: "sub-2", PreferredUsername: "root"},
+			setup: func() {
+				env.identityRepo.EXPECT().GetUserID(gomock.Any(), "corp", "sub-2").Return(uint64(1), nil)
+				env.userUseCase.EXPECT().GetUserByID(gomock.Any(), uint64(1)).
+					Return(&userEntity.User{ID: 1, Username: "root", Role: userEntity.RoleAdmin}, nil)
+				env.userUseCase.EXPECT().SetUserRole(gomock.Any(), uint64(1), userEntity.RoleDefault).Return(usecaseUser.ErrLastAdmin)
+			},
+			expected: &userEntity.User{ID: 1, Username: "root", Role: userEntity.RoleAdmin},
+		},
+		{
+			name: "user with verified email is linked",
+			user: oidctest.User{Subject: "sub-3", Email: "Bob@Corp.example", EmailVerified: true, Groups: []string{"film-admins"}},
+			setup: func() {
+				env.identityRepo.EXPECT().GetUserID(gomock.Any(), "corp", "sub-3").Return(uint64(0), nil)
+				env.userUseCase.EXPECT().GetUsersByEmail(gomock.Any(), "Bob@Corp.example").
+					Return([]userEntity.User{{ID: 7, Username: "bob", Role: userEntity.RoleAdmin}}, nil)
+				env.identityRepo.EXPECT().LinkIdentity(gomock.Any(), &entity.Identity{Provider: "corp", Subject: "sub-3", UserID: 7}).Return(nil)
+			},
+			expected: &userEntity.User{ID: 7, Username: "bob", Role: userEntity.RoleAdmin},
+		},
+		{
+			name: "unverified email creates new user with free username",
+			user: oidctest.User{Subject: "sub-4", Email: "bob@corp.example", PreferredUsername: "bob.smith",
+				Groups: []string{"film-admins"}},
+			setup: func() {
+				env.identityRepo.EXPECT().GetUserID(gomock.Any(), "corp", "sub-4").Return(uint64(0), nil)
+				gomock.InOrder(
+					env.userUseCase.EXPECT().CreateUser(gomock.Any(), "bob_smith", gomock.Any(), "", userEntity.RoleAdmin).
+						Return(nil, usecaseUser.ErrUserAlreadyExists),
+					env.userUseCase.EXPECT().CreateUser(gomock.Any(), "bob_smith_2", gomock.Any(), "", userEntity.RoleAdmin).
+						Return(&userEntity.User{ID: 8, Username: "bob_smith_2", Role: userEntity.RoleAdmin}, nil),
+				)
+				env.identityRepo.EXPECT().LinkIdentity(gomock.Any(), &entity.Identity{Provider: "corp", Subject: "sub-4", UserID: 8}).Return(nil)
+			},
+			expected: &userEntity.User{ID: 8, Username: "bob_smith_2", Role: userEntity.RoleAdmin},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state, code := env.authorize(t, tc.user)
+			env.stateRepo.EXPECT().PopState(gomock.Any(), state.ID).Return(state, nil)
+			tc.setup()
+			user, err := env.useCase.CompleteLogin(context.Background(), "corp", state.ID, code)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, user)
+		})
+	}
+}
+
+func TestUsernameFromClaims(t *testing.T) {
+	testCases := []struct {
+		claims   entity.Claims
+		expected string
+	}{
+		{claims: entity.Claims{PreferredUsername: "alice"}, expected: "alice"},
+		{claims: entity.Claims{PreferredUsername: "alice@corp", Email: "a@corp.example"}, expected: "alicecorp"},
+		{claims: entity.Claims{Email: "john.doe@corp.example"}, expected: "john_doe"},
+		{claims: entity.Claims{PreferredUsername: "Иван", Name: "Ivan Petrov"}, expected: "Ivan_Petrov"},
+		{claims: entity.Claims{Name: "Иван"}, expected: "user"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, usernameFromClaims(&tc.claims))
+	}
+}
+
+func TestGroupsFromClaim(t *testing.T) {
+	assert.Equal(t, []string{"admins"}, groupsFromClaim("admins"))
+	assert.Equal(t, []string{"admins", "staff"}, groupsFromClaim([]interface{}{"admins", 1, "staff"}))
+	assert.Nil(t, groupsFromClaim(nil))
+}
@@ -0,0 +1,211 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/passwordreset/usecase"
+	sessionUseCase "github.com/ilyushkaaa/Filmoteka/internal/session/usecase"
+	tokenUseCase "github.com/ilyushkaaa/Filmoteka/internal/token/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+	"go.uber.org/zap"
+)
+
+type validatableRequest interface {
+	Validate() []string
+}
+
+type PasswordResetHandler struct {
+	resetUseCase   usecase.PasswordResetUseCase
+	sessionUseCase sessionUseCase.SessionUseCase
+	tokenUseCase   tokenUseCase.TokenUseCase
+}
+
+func NewPasswordResetHandler(resetUseCase usecase.PasswordResetUseCase, sessionUseCase sessionUseCase.SessionUseCase,
+	tokenUseCase tokenUseCase.TokenUseCase) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		resetUseCase:   resetUseCase,
+		sessionUseCase: sessionUseCase,
+		tokenUseCase:   tokenUseCase,
+	}
+}
+
+// RequestPasswordReset @Summary Запросить сброс пароля
+// @Description Отправить на email пользователя одноразовый токен сброса пароля. Ответ не зависит от того, существует ли пользователь и указан ли у него email
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param body body dto.PasswordResetRequest true "Имя пользователя"
+// @Success 202 {object} string "Запрос принят"
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 422 {object} string "Данные не прошли валидацию"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/password/reset/request [post]
+func (h *PasswordResetHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	resetRequest := &dto.PasswordResetRequest{}
+	err = decodeRequest(zapLogger, w, r, resetRequest)
+	if err != nil {
+		return
+	}
+	err = h.resetUseCase.RequestReset(r.Context(), resetRequest.Username)
+	switch {
+	case errors.Is(err, usecase.ErrNoUser), errors.Is(err, usecase.ErrNoEmail):
+		// ответ совпадает с успешным, чтобы по нему нельзя было узнать, какие пользователи существуют
+		zapLogger.Infof("password reset was not sent to %s: %s", resetRequest.Username, err)
+	case err != nil:
+		zapLogger.Errorf("error in requesting password reset: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	default:
+		zapLogger.Infof("password reset was sent to user %s", resetRequest.Username)
+	}
+	result := `{"result": "success"}`
+	err = response.WriteResponse(w, []byte(result), http.StatusAccepted)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+// ResetPassword @Summary Сбросить пароль
+// @Description Установить новый пароль по токену из письма. Токен одноразовый; после сброса завершаются все сессии пользователя и отзываются его refresh токены
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param body body dto.PasswordResetConfirm true "Токен и новый пароль"
+// @Success 200 {object} string "Пароль изменен"
+// @Failure 400 {object} string "Ошибка в запросе или токен недействителен"
+// @Failure 422 {object} string "Данные не прошли валидацию"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/password/reset [post]
+func (h *PasswordResetHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	confirmRequest := &dto.PasswordResetConfirm{}
+	err = decodeRequest(zapLogger, w, r, confirmRequest)
+	if err != nil {
+		return
+	}
+	userID, err := h.resetUseCase.ResetPassword(r.Context(), confirmRequest.Token, confirmRequest.Password)
+	if errors.Is(err, usecase.ErrBadToken) || errors.Is(err, usecase.ErrNoUser) {
+		zapLogger.Errorf("password reset was rejected: %s", err)
+		errText := `{"error": "password reset token is invalid or expired"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		zapLogger.Errorf("error in resetting password: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	zapLogger.Infof("password of user %d was reset", userID)
+	h.revokeSessions(r.Context(), zapLogger, w, userID)
+}
+
+func (h *PasswordResetHandler) revokeSessions(ctx context.Context, zapLogger *zap.SugaredLogger, w http.ResponseWriter, userID uint64) {
+	sessionsDeleted, err := h.sessionUseCase.DeleteUserSessions(ctx, userID)
+	if err != nil {
+		zapLogger.Errorf("error in deleting sessions of user %d: %s", userID, err)
+		errText := `{"error": "password was reset, but sessions were not revoked"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	zapLogger.Infof("%d sessions of user %d were deleted", sessionsDeleted, userID)
+	if h.tokenUseCase != nil {
+		familiesRevoked, err := h.tokenUseCase.RevokeUserTokens(ctx, userID)
+		if err != nil {
+			zapLogger.Errorf("error in revoking tokens of user %d: %s", userID, err)
+			errText := `{"error": "password was reset, but tokens were not revoked"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if err != nil {
+				zapLogger.Errorf("error in writing response: %s", err)
+			}
+			return
+		}
+		zapLogger.Infof("%d token families of user %d were revoked", familiesRevoked, userID)
+	}
+	result := `{"result": "success"}`
+	err = response.WriteResponse(w, []byte(result), http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+func decodeRequest(zapLogger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request, request validatableRequest) error {
+	rBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		zapLogger.Errorf("error in reading request body: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in reading request body: %s"}`, err)
+		writeErr := response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if writeErr != nil {
+			zapLogger.Errorf("error in writing response: %s", writeErr)
+		}
+		return err
+	}
+	err = json.Unmarshal(rBody, request)
+	if err != nil {
+		zapLogger.Errorf("error in unmarshalling request: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in decoding request: %s"}`, err)
+		writeErr := response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if writeErr != nil {
+			zapLogger.Errorf("error in writing response: %s", writeErr)
+		}
+		return err
+	}
+	validationErrors := request.Validate()
+	if len(validationErrors) != 0 {
+		zapLogger.Errorf("request did not pass validation: %v", validationErrors)
+		errorsJSON, err := json.Marshal(validationErrors)
+		if err != nil {
+			zapLogger.Errorf("error in marshalling validation errors: %s", err)
+			errText := `{"error": "internal server error"}`
+			writeErr := response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if writeErr != nil {
+				zapLogger.Errorf("error in writing response: %s", writeErr)
+			}
+			return err
+		}
+		err = response.WriteResponse(w, errorsJSON, http.StatusUnprocessableEntity)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return fmt.Errorf("request did not pass validation")
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ilyushkaaa/Filmoteka/internal/passwordreset/usecase"
+	"github.com/ilyushkaaa/Filmoteka/internal/passwordreset/usecase/mock"
+	sessionMock "github.com/ilyushkaaa/Filmoteka/internal/session/usecase/mock"
+	tokenMock "github.com/ilyushkaaa/Filmoteka/internal/token/usecase/mock"
+	logger2 "github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func TestRequestPasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockPasswordResetUseCase(ctrl)
+	testHandler := NewPasswordResetHandler(testUseCase, sessionMock.NewMockSessionUseCase(ctrl), nil)
+
+	testCases := []struct {
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{
+			body:       `{"username": 1}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			body:       `{"username": "bad name"}`,
+			statusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			body: `{"username": "hello12"}`,
+			setup: func() {
+				testUseCase.EXPECT().RequestReset(gomock.Any(), "hello12").Return(fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			body: `{"username": "hello12"}`,
+			setup: func() {
+				testUseCase.EXPECT().RequestReset(gomock.Any(), "hello12").Return(usecase.ErrNoUser)
+			},
+			statusCode: http.StatusAccepted,
+		},
+		{
+			body: `{"username": "hello12"}`,
+			setup: func() {
+				testUseCase.EXPECT().RequestReset(gomock.Any(), "hello12").Return(usecase.ErrNoEmail)
+			},
+			statusCode: http.StatusAccepted,
+		},
+		{
+			body: `{"username": "hello12"}`,
+			setup: func() {
+				testUseCase.EXPECT().RequestReset(gomock.Any(), "hello12").Return(nil)
+			},
+			statusCode: http.StatusAccepted,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodPost, "/password/reset/request", strings.NewReader(tc.body))
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.RequestPasswordReset(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockPasswordResetUseCase(ctrl)
+	testSessionUseCase := sessionMock.NewMockSessionUseCase(ctrl)
+	testTokenUseCase := tokenMock.NewMockTokenUseCase(ctrl)
+	sessionHandler := NewPasswordResetHandler(testUseCase, testSessionUseCase, nil)
+	tokenHandler := NewPasswordResetHandler(testUseCase, testSessionUseCase, testTokenUseCase)
+	body := `{"token": "token", "password": "newpassword"}`
+
+	testCases := []struct {
+		handler    *PasswordResetHandler
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{
+			handler:    sessionHandler,
+			body:       `{"token": "token", "password": "short"}`,
+			statusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			handler: sessionHandler,
+			body:    body,
+			setup: func() {
+				testUseCase.EXPECT().ResetPassword(gomock.Any(), "token", "newpassword").Return(uint64(0), usecase.ErrBadToken)
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			handler: sessionHandler,
+			body:    body,
+			setup: func() {
+				testUseCase.EXPECT().ResetPassword(gomock.Any(), "token", "newpassword").Return(uint64(0), fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			handler: sessionHandler,
+			body:    body,
+			setup: func() {
+				testUseCase.EXPECT().ResetPassword(gomock.Any(), "token", "newpassword").Return(uint64(1), nil)
+				testSessionUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(0, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			handler: sessionHandler,
+			body:    body,
+			setup: func() {
+				testUseCase.EXPECT().ResetPassword(gomock.Any(), "token", "newpassword").Return(uint64(1), nil)
+				testSessionUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(2, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+		{
+			handler: tokenHandler,
+			body:    body,
+			setup: func() {
+				testUseCase.EXPECT().ResetPassword(gomock.Any(), "token", "newpassword").Return(uint64(1), nil)
+				testSessionUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(0, nil)
+				testTokenUseCase.EXPECT().RevokeUserTokens(gomock.Any(), uint64(1)).Return(1, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodPost, "/password/reset", strings.NewReader(tc.body))
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		tc.handler.ResetPassword(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: password_reset.go
+
+// Package repo is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPasswordResetRepo is a mock of PasswordResetRepo interface.
+type MockPasswordResetRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetRepoMockRecorder
+}
+
+// MockPasswordResetRepoMockRecorder is the mock recorder for MockPasswordResetRepo.
+type MockPasswordResetRepoMockRecorder struct {
+	mock *MockPasswordResetRepo
+}
+
+// NewMockPasswordResetRepo creates a new mock instance.
+func NewMockPasswordResetRepo(ctrl *gomock.Controller) *MockPasswordResetRepo {
+	mock := &MockPasswordResetRepo{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordResetRepo) EXPECT() *MockPasswordResetRepoMockRecorder {
+	return m.recorder
+}
+
+// ConsumeToken mocks base method.
+func (m *MockPasswordResetRepo) ConsumeToken(ctx context.Context, tokenHash string) (uint64, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeToken", ctx, tokenHash)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ConsumeToken indicates an expected call of ConsumeToken.
+func (mr *MockPasswordResetRepoMockRecorder) ConsumeToken(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeToken", reflect.TypeOf((*MockPasswordResetRepo)(nil).ConsumeToken), ctx, tokenHash)
+}
+
+// SaveToken mocks base method.
+func (m *MockPasswordResetRepo) SaveToken(ctx context.Context, userID uint64, tokenHash string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveToken", ctx, userID, tokenHash, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveToken indicates an expected call of SaveToken.
+func (mr *MockPasswordResetRepoMockRecorder) SaveToken(ctx, userID, tokenHash, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveToken", reflect.TypeOf((*MockPasswordResetRepo)(nil).SaveToken), ctx, userID, tokenHash, expiresAt)
+}
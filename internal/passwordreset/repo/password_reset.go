@@ -0,0 +1,69 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source=password_reset.go -destination=password_reset_mock.go -package=repo PasswordResetRepo
+type PasswordResetRepo interface {
+	SaveToken(ctx context.Context, userID uint64, tokenHash string, expiresAt time.Time) error
+	ConsumeToken(ctx context.Context, tokenHash string) (uint64, time.Time, error)
+}
+
+type PasswordResetRepoPG struct {
+	db        *sql.DB
+	zapLogger *zap.SugaredLogger
+}
+
+func NewPasswordResetRepo(db *sql.DB, zapLogger *zap.SugaredLogger) *PasswordResetRepoPG {
+	return &PasswordResetRepoPG{
+		db:        db,
+		zapLogger: zapLogger,
+	}
+}
+
+func (r *PasswordResetRepoPG) SaveToken(ctx context.Context, userID uint64, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = tx.Rollback()
+			if err != nil {
+				r.zapLogger.Errorf("error in transaction rollback: %s", err)
+			}
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM password_reset_tokens WHERE user_id = $1 OR expires_at <= now()", userID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO password_reset_tokens (token_hash, user_id, expires_at) VALUES ($1, $2, $3)",
+		tokenHash, userID, expiresAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PasswordResetRepoPG) ConsumeToken(ctx context.Context, tokenHash string) (uint64, time.Time, error) {
+	var userID uint64
+	var expiresAt time.Time
+	err := r.db.
+		QueryRowContext(ctx, "DELETE FROM password_reset_tokens WHERE token_hash = $1 RETURNING user_id, expires_at", tokenHash).
+		Scan(&userID, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, time.Time{}, nil
+	}
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return userID, expiresAt, nil
+}
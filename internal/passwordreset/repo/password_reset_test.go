@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestSaveToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewPasswordResetRepo(db, zap.NewNop().Sugar())
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("error"))
+	err = repo.SaveToken(context.Background(), 1, "hash", expiresAt)
+	assert.Error(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM password_reset_tokens WHERE user_id = (.+) OR expires_at <= now()").
+		WithArgs(uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO password_reset_tokens (.+)").
+		WithArgs("hash", uint64(1), expiresAt).
+		WillReturnError(fmt.Errorf("error"))
+	mock.ExpectRollback()
+	err = repo.SaveToken(context.Background(), 1, "hash", expiresAt)
+	assert.Error(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM password_reset_tokens WHERE user_id = (.+) OR expires_at <= now()").
+		WithArgs(uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO password_reset_tokens (.+)").
+		WithArgs("hash", uint64(1), expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	err = repo.SaveToken(context.Background(), 1, "hash", expiresAt)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConsumeToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewPasswordResetRepo(db, zap.NewNop().Sugar())
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectQuery("DELETE FROM password_reset_tokens WHERE token_hash = (.+) RETURNING user_id, expires_at").
+		WithArgs("hash").
+		WillReturnError(sql.ErrNoRows)
+	userID, _, err := repo.ConsumeToken(context.Background(), "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), userID)
+
+	mock.ExpectQuery("DELETE FROM password_reset_tokens WHERE token_hash = (.+) RETURNING user_id, expires_at").
+		WithArgs("hash").
+		WillReturnError(fmt.Errorf("error"))
+	_, _, err = repo.ConsumeToken(context.Background(), "hash")
+	assert.Error(t, err)
+
+	mock.ExpectQuery("DELETE FROM password_reset_tokens WHERE token_hash = (.+) RETURNING user_id, expires_at").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "expires_at"}).AddRow(1, expiresAt))
+	userID, tokenExpiresAt, err := repo.ConsumeToken(context.Background(), "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), userID)
+	assert.Equal(t, expiresAt, tokenExpiresAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
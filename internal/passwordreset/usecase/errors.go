@@ -0,0 +1,9 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrBadToken = errors.New("password reset token is invalid or expired")
+	ErrNoUser   = errors.New("user not exists")
+	ErrNoEmail  = errors.New("user has no email")
+)
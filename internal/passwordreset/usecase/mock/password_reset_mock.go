@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: password_reset.go
+
+// Package usecase is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPasswordResetUseCase is a mock of PasswordResetUseCase interface.
+type MockPasswordResetUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetUseCaseMockRecorder
+}
+
+// MockPasswordResetUseCaseMockRecorder is the mock recorder for MockPasswordResetUseCase.
+type MockPasswordResetUseCaseMockRecorder struct {
+	mock *MockPasswordResetUseCase
+}
+
+// NewMockPasswordResetUseCase creates a new mock instance.
+func NewMockPasswordResetUseCase(ctrl *gomock.Controller) *MockPasswordResetUseCase {
+	mock := &MockPasswordResetUseCase{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordResetUseCase) EXPECT() *MockPasswordResetUseCaseMockRecorder {
+	return m.recorder
+}
+
+// RequestReset mocks base method.
+func (m *MockPasswordResetUseCase) RequestReset(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestReset", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestReset indicates an expected call of RequestReset.
+func (mr *MockPasswordResetUseCaseMockRecorder) RequestReset(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestReset", reflect.TypeOf((*MockPasswordResetUseCase)(nil).RequestReset), ctx, username)
+}
+
+// ResetPassword mocks base method.
+func (m *MockPasswordResetUseCase) ResetPassword(ctx context.Context, token, newPassword string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, token, newPassword)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockPasswordResetUseCaseMockRecorder) ResetPassword(ctx, token, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockPasswordResetUseCase)(nil).ResetPassword), ctx, token, newPassword)
+}
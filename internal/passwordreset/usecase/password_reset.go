@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/passwordreset/repo"
+	userRepo "github.com/ilyushkaaa/Filmoteka/internal/users/repo"
+	"github.com/ilyushkaaa/Filmoteka/pkg/mailer"
+	passwordHash "github.com/ilyushkaaa/Filmoteka/pkg/password_hash"
+)
+
+const (
+	DefaultTokenTTL = time.Hour
+
+	resetTokenBytes = 32
+	resetMailTitle  = "Восстановление пароля в Фильмотеке"
+)
+
+//go:generate mockgen -source=password_reset.go -destination=password_reset_mock.go -package=usecase PasswordResetUseCase
+type PasswordResetUseCase interface {
+	RequestReset(ctx context.Context, username string) error
+	ResetPassword(ctx context.Context, token, newPassword string) (uint64, error)
+}
+
+type PasswordResetUseCaseApp struct {
+	resetRepo repo.PasswordResetRepo
+	userRepo  userRepo.UserRepo
+	hasher    passwordHash.Hasher
+	mailer    mailer.Mailer
+	tokenTTL  time.Duration
+	resetURL  string
+}
+
+func NewPasswordResetUseCase(resetRepo repo.PasswordResetRepo, userRepo userRepo.UserRepo, hasher passwordHash.Hasher,
+	mailer mailer.Mailer, tokenTTL time.Duration, resetURL string) *PasswordResetUseCaseApp {
+	return &PasswordResetUseCaseApp{
+		resetRepo: resetRepo,
+		userRepo:  userRepo,
+		hasher:    hasher,
+		mailer:    mailer,
+		tokenTTL:  tokenTTL,
+		resetURL:  resetURL,
+	}
+}
+
+func (pu *PasswordResetUseCaseApp) RequestReset(ctx context.Context, username string) error {
+	user, err := pu.userRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrNoUser
+	}
+	email, err := pu.userRepo.GetUserEmail(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if email == "" {
+		return ErrNoEmail
+	}
+	tokenRaw := make([]byte, resetTokenBytes)
+	_, err = rand.Read(tokenRaw)
+	if err != nil {
+		return err
+	}
+	token := base64.RawURLEncoding.EncodeToString(tokenRaw)
+	err = pu.resetRepo.SaveToken(ctx, user.ID, hashResetToken(token), time.Now().Add(pu.tokenTTL))
+	if err != nil {
+		return err
+	}
+	body, err := pu.getMailBody(user.Username, token)
+	if err != nil {
+		return err
+	}
+	return pu.mailer.Send(email, resetMailTitle, body)
+}
+
+func (pu *PasswordResetUseCaseApp) ResetPassword(ctx context.Context, token, newPassword string) (uint64, error) {
+	userID, expiresAt, err := pu.resetRepo.ConsumeToken(ctx, hashResetToken(token))
+	if err != nil {
+		return 0, err
+	}
+	if userID == 0 || !time.Now().Before(expiresAt) {
+		return 0, ErrBadToken
+	}
+	newPasswordHash, err := pu.hasher.GetHashPassword(newPassword)
+	if err != nil {
+		return 0, err
+	}
+	wasUpdated, err := pu.userRepo.UpdatePasswordHash(ctx, userID, newPasswordHash)
+	if err != nil {
+		return 0, err
+	}
+	if !wasUpdated {
+		return 0, ErrNoUser
+	}
+	return userID, nil
+}
+
+func (pu *PasswordResetUseCaseApp) getMailBody(username, token string) (string, error) {
+	if pu.resetURL == "" {
+		return fmt.Sprintf("Здравствуйте, %s!\n\nКод для сброса пароля: %s\n\nКод действует %s. "+
+			"Если вы не запрашивали сброс пароля, просто проигнорируйте это письмо.\n", username, token, pu.tokenTTL), nil
+	}
+	resetURL, err := url.Parse(pu.resetURL)
+	if err != nil {
+		return "", err
+	}
+	query := resetURL.Query()
+	query.Set("token", token)
+	resetURL.RawQuery = query.Encode()
+	return fmt.Sprintf("Здравствуйте, %s!\n\nДля сброса пароля перейдите по ссылке: %s\n\nСсылка действует %s. "+
+		"Если вы не запрашивали сброс пароля, просто проигнорируйте это письмо.\n", username, resetURL, pu.tokenTTL), nil
+}
+
+func hashResetToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
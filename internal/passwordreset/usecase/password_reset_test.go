@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ilyushkaaa/Filmoteka/internal/passwordreset/repo/mock"
+	userEntity "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	userMock "github.com/ilyushkaaa/Filmoteka/internal/users/repo/mock"
+	"github.com/ilyushkaaa/Filmoteka/pkg/mailer"
+	"github.com/ilyushkaaa/Filmoteka/pkg/password_hash"
+	"github.com/stretchr/testify/assert"
+)
+
+var tokenRegexp = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+func TestRequestReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockPasswordResetRepo(ctrl)
+	testUserRepo := userMock.NewMockUserRepo(ctrl)
+	var mailbox bytes.Buffer
+	testUseCase := NewPasswordResetUseCase(testRepo, testUserRepo, &password_hash.SHA256Hasher{},
+		mailer.NewFileMailer(&mailbox, "noreply@filmoteka.local"), time.Hour, "https://filmoteka.local/reset")
+	user := &userEntity.User{ID: 1, Username: "hello12"}
+
+	testUserRepo.EXPECT().GetUserByUsername(gomock.Any(), "hello12").Return(nil, fmt.Errorf("error"))
+	err := testUseCase.RequestReset(context.Background(), "hello12")
+	assert.Error(t, err)
+
+	testUserRepo.EXPECT().GetUserByUsername(gomock.Any(), "hello12").Return(nil, nil)
+	err = testUseCase.RequestReset(context.Background(), "hello12")
+	assert.ErrorIs(t, err, ErrNoUser)
+
+	testUserRepo.EXPECT().GetUserByUsername(gomock.Any(), "hello12").Return(user, nil)
+	testUserRepo.EXPECT().GetUserEmail(gomock.Any(), uint64(1)).Return("", nil)
+	err = testUseCase.RequestReset(context.Background(), "hello12")
+	assert.ErrorIs(t, err, ErrNoEmail)
+
+	testUserRepo.EXPECT().GetUserByUsername(gomock.Any(), "hello12").Return(user, nil)
+	testUserRepo.EXPECT().GetUserEmail(gomock.Any(), uint64(1)).Return("user@example.com", nil)
+	testRepo.EXPECT().SaveToken(gomock.Any(), uint64(1), gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
+	err = testUseCase.RequestReset(context.Background(), "hello12")
+	assert.Error(t, err)
+	assert.Empty(t, mailbox.String())
+
+	var savedHash string
+	var savedExpiresAt time.Time
+	testUserRepo.EXPECT().GetUserByUsername(gomock.Any(), "hello12").Return(user, nil)
+	testUserRepo.EXPECT().GetUserEmail(gomock.Any(), uint64(1)).Return("user@example.com", nil)
+	testRepo.EXPECT().SaveToken(gomock.Any(), uint64(1), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uint64, tokenHash string, expiresAt time.Time) error {
+			savedHash = tokenHash
+			savedExpiresAt = expiresAt
+			return nil
+		})
+	err = testUseCase.RequestReset(context.Background(), "hello12")
+	assert.NoError(t, err)
+	assert.Contains(t, mailbox.String(), "To: user@example.com")
+	assert.Contains(t, mailbox.String(), "https://filmoteka.local/reset?token=")
+	assert.WithinDuration(t, time.Now().Add(time.Hour), savedExpiresAt, time.Minute)
+
+	// в базе хранится хеш токена из письма, а не сам токен
+	match := tokenRegexp.FindStringSubmatch(mailbox.String())
+	if match == nil {
+		t.Fatalf("no token in mail: %s", mailbox.String())
+	}
+	assert.NotEqual(t, match[1], savedHash)
+	assert.Equal(t, hashResetToken(match[1]), savedHash)
+}
+
+func TestRequestResetWithoutURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockPasswordResetRepo(ctrl)
+	testUserRepo := userMock.NewMockUserRepo(ctrl)
+	var mailbox bytes.Buffer
+	testUseCase := NewPasswordResetUseCase(testRepo, testUserRepo, &password_hash.SHA256Hasher{},
+		mailer.NewFileMailer(&mailbox, "noreply@filmoteka.local"), time.Hour, "")
+
+	testUserRepo.EXPECT().GetUserByUsername(gomock.Any(), "hello12").Return(&userEntity.User{ID: 1, Username: "hello12"}, nil)
+	testUserRepo.EXPECT().GetUserEmail(gomock.Any(), uint64(1)).Return("user@example.com", nil)
+	testRepo.EXPECT().SaveToken(gomock.Any(), uint64(1), gomock.Any(), gomock.Any()).Return(nil)
+	err := testUseCase.RequestReset(context.Background(), "hello12")
+	assert.NoError(t, err)
+	assert.Contains(t, mailbox.String(), "Код для сброса пароля: ")
+	assert.NotContains(t, mailbox.String(), "http")
+}
+
+func TestResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockPasswordResetRepo(ctrl)
+	testUserRepo := userMock.NewMockUserRepo(ctrl)
+	testUseCase := NewPasswordResetUseCase(testRepo, testUserRepo, &password_hash.SHA256Hasher{},
+		mailer.NewFileMailer(&bytes.Buffer{}, "noreply@filmoteka.local"), time.Hour, "")
+	tokenHash := hashResetToken("token")
+	newHash := "ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1"
+	expiresAt := time.Now().Add(time.Hour)
+
+	testRepo.EXPECT().ConsumeToken(gomock.Any(), tokenHash).Return(uint64(0), time.Time{}, fmt.Errorf("error"))
+	_, err := testUseCase.ResetPassword(context.Background(), "token", "11111111")
+	assert.Error(t, err)
+
+	testRepo.EXPECT().ConsumeToken(gomock.Any(), tokenHash).Return(uint64(0), time.Time{}, nil)
+	_, err = testUseCase.ResetPassword(context.Background(), "token", "11111111")
+	assert.ErrorIs(t, err, ErrBadToken)
+
+	testRepo.EXPECT().ConsumeToken(gomock.Any(), tokenHash).Return(uint64(1), time.Now().Add(-time.Second), nil)
+	_, err = testUseCase.ResetPassword(context.Background(), "token", "11111111")
+	assert.ErrorIs(t, err, ErrBadToken)
+
+	testRepo.EXPECT().ConsumeToken(gomock.Any(), tokenHash).Return(uint64(1), expiresAt, nil)
+	testUserRepo.EXPECT().UpdatePasswordHash(gomock.Any(), uint64(1), newHash).Return(false, nil)
+	_, err = testUseCase.ResetPassword(context.Background(), "token", "11111111")
+	assert.ErrorIs(t, err, ErrNoUser)
+
+	testRepo.EXPECT().ConsumeToken(gomock.Any(), tokenHash).Return(uint64(1), expiresAt, nil)
+	testUserRepo.EXPECT().UpdatePasswordHash(gomock.Any(), uint64(1), newHash).Return(true, nil)
+	userID, err := testUseCase.ResetPassword(context.Background(), "token", "11111111")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), userID)
+}
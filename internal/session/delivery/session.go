@@ -0,0 +1,272 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
+	"github.com/ilyushkaaa/Filmoteka/internal/session/usecase"
+	usecaseToken "github.com/ilyushkaaa/Filmoteka/internal/token/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+	"go.uber.org/zap"
+)
+
+type SessionHandler struct {
+	sessionUseCase usecase.SessionUseCase
+	tokenUseCase   usecaseToken.TokenUseCase
+}
+
+func NewSessionHandler(sessionUseCase usecase.SessionUseCase, tokenUseCase usecaseToken.TokenUseCase) *SessionHandler {
+	return &SessionHandler{
+		sessionUseCase: sessionUseCase,
+		tokenUseCase:   tokenUseCase,
+	}
+}
+
+// GetMySessions @Summary Получить свои сессии
+// @Description Получить список активных сессий текущего пользователя (устройств, с которых выполнен вход). Текущая сессия отмечена полем is_current
+// @Tags users
+// @Produce json
+// @Security CookieAuth
+// @Success 200 {array} dto.SessionInfo
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/me/sessions [get]
+func (h *SessionHandler) GetMySessions(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(zapLogger, w, r, true)
+	if err != nil {
+		return
+	}
+	currentSessionID, _ := r.Context().Value(middleware.MySessionIDKey).(string)
+	h.writeSessions(r.Context(), zapLogger, w, userID, currentSessionID)
+}
+
+// DeleteMySession @Summary Завершить сессию
+// @Description Завершить одну из сессий текущего пользователя, например на потерянном устройстве
+// @Tags users
+// @Produce json
+// @Security CookieAuth
+// @Param SESSION_ID path string true "ID сессии"
+// @Success 200 {object} string "Сессия завершена"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 404 {object} string "Сессия не найдена"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/me/sessions/{SESSION_ID} [delete]
+func (h *SessionHandler) DeleteMySession(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(zapLogger, w, r, true)
+	if err != nil {
+		return
+	}
+	sessionID := mux.Vars(r)["SESSION_ID"]
+	_, err = h.sessionUseCase.DeleteUserSession(r.Context(), userID, sessionID)
+	if errors.Is(err, usecase.ErrNoSession) {
+		zapLogger.Errorf("session %s of user %d is not found", sessionID, userID)
+		errText := fmt.Sprintf(`{"error": "no session with session id: %s"}`, sessionID)
+		err = response.WriteResponse(w, []byte(errText), http.StatusNotFound)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		zapLogger.Errorf("error in deleting session: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	result := `{"result": "success"}`
+	err = response.WriteResponse(w, []byte(result), http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+// LogoutAll @Summary Выход со всех устройств
+// @Description Завершить все сессии текущего пользователя, включая текущую, и отозвать его refresh токены
+// @Tags users
+// @Produce json
+// @Security CookieAuth
+// @Success 200 {object} string "Все сессии завершены"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/logout-all [post]
+func (h *SessionHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(zapLogger, w, r, true)
+	if err != nil {
+		return
+	}
+	h.revokeSessions(r.Context(), zapLogger, w, userID)
+}
+
+// GetUserSessions @Summary Получить сессии пользователя
+// @Description Получить список активных сессий пользователя
+// @Tags admin
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param USER_ID path int true "ID пользователя"
+// @Success 200 {array} dto.SessionInfo
+// @Failure 400 {object} string "Неверный формат ID пользователя"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/users/{USER_ID}/sessions [get]
+func (h *SessionHandler) GetUserSessions(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := getUserIDFromPath(zapLogger, w, r)
+	if err != nil {
+		return
+	}
+	h.writeSessions(r.Context(), zapLogger, w, userID, "")
+}
+
+// RevokeUserSessions @Summary Завершить сессии пользователя
+// @Description Завершить все сессии пользователя и отозвать его refresh токены
+// @Tags admin
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param USER_ID path int true "ID пользователя"
+// @Success 200 {object} string "Сессии завершены"
+// @Failure 400 {object} string "Неверный формат ID пользователя"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/users/{USER_ID}/sessions [delete]
+func (h *SessionHandler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := getUserIDFromPath(zapLogger, w, r)
+	if err != nil {
+		return
+	}
+	h.revokeSessions(r.Context(), zapLogger, w, userID)
+}
+
+func (h *SessionHandler) writeSessions(ctx context.Context, zapLogger *zap.SugaredLogger, w http.ResponseWriter, userID uint64, currentSessionID string) {
+	sessions, err := h.sessionUseCase.GetUserSessions(ctx, userID)
+	if err != nil {
+		zapLogger.Errorf("error in getting user sessions: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	sessionsJSON, err := json.Marshal(dto.NewSessionInfos(sessions, currentSessionID))
+	if err != nil {
+		zapLogger.Errorf("error marshalling response: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, sessionsJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+func (h *SessionHandler) revokeSessions(ctx context.Context, zapLogger *zap.SugaredLogger, w http.ResponseWriter, userID uint64) {
+	sessionsDeleted, err := h.sessionUseCase.DeleteUserSessions(ctx, userID)
+	if err != nil {
+		zapLogger.Errorf("error in deleting sessions of user %d: %s", userID, err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	zapLogger.Infof("%d sessions of user %d were deleted", sessionsDeleted, userID)
+	if h.tokenUseCase != nil {
+		familiesRevoked, err := h.tokenUseCase.RevokeUserTokens(ctx, userID)
+		if err != nil {
+			zapLogger.Errorf("error in revoking tokens of user %d: %s", userID, err)
+			errText := `{"error": "sessions were revoked, but tokens were not revoked"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if err != nil {
+				zapLogger.Errorf("error in writing response: %s", err)
+			}
+			return
+		}
+		zapLogger.Infof("%d token families of user %d were revoked", familiesRevoked, userID)
+	}
+	result := `{"result": "success"}`
+	err = response.WriteResponse(w, []byte(result), http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+func getUserIDFromPath(zapLogger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request) (uint64, error) {
+	userID, err := strconv.ParseUint(mux.Vars(r)["USER_ID"], 10, 64)
+	if err != nil {
+		zapLogger.Errorf("error in user id conversion: %s", err)
+		errText := fmt.Sprintf(`{"error": "bad format of user id: %s"}`, err)
+		writeErr := response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if writeErr != nil {
+			zapLogger.Errorf("error in writing response: %s", writeErr)
+		}
+		return 0, err
+	}
+	return userID, nil
+}
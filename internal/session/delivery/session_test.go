@@ -0,0 +1,191 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
+	"github.com/ilyushkaaa/Filmoteka/internal/session/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/session/usecase"
+	"github.com/ilyushkaaa/Filmoteka/internal/session/usecase/mock"
+	tokenMock "github.com/ilyushkaaa/Filmoteka/internal/token/usecase/mock"
+	logger2 "github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestGetMySessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockSessionUseCase(ctrl)
+	testHandler := NewSessionHandler(testUseCase, nil)
+
+	request := httptest.NewRequest(http.MethodGet, "/me/sessions", nil)
+	ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter := httptest.NewRecorder()
+	testHandler.GetMySessions(respWriter, request.WithContext(ctx))
+	assert.Equal(t, http.StatusInternalServerError, respWriter.Result().StatusCode)
+
+	ctx = context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+	ctx = context.WithValue(ctx, middleware.MySessionIDKey, "current")
+
+	testUseCase.EXPECT().GetUserSessions(gomock.Any(), uint64(1)).Return(nil, fmt.Errorf("error"))
+	respWriter = httptest.NewRecorder()
+	testHandler.GetMySessions(respWriter, request.WithContext(ctx))
+	assert.Equal(t, http.StatusInternalServerError, respWriter.Result().StatusCode)
+
+	now := time.Now()
+	testUseCase.EXPECT().GetUserSessions(gomock.Any(), uint64(1)).Return([]entity.Session{
+		{ID: "other", UserID: 1, LastSeenAt: now.Add(-time.Hour)},
+		{ID: "current", UserID: 1, LastSeenAt: now},
+	}, nil)
+	respWriter = httptest.NewRecorder()
+	testHandler.GetMySessions(respWriter, request.WithContext(ctx))
+	resp := respWriter.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	var sessions []dto.SessionInfo
+	assert.NoError(t, json.Unmarshal(body, &sessions))
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, "current", sessions[0].ID)
+	assert.True(t, sessions[0].IsCurrent)
+	assert.False(t, sessions[1].IsCurrent)
+}
+
+func TestDeleteMySession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockSessionUseCase(ctrl)
+	testHandler := NewSessionHandler(testUseCase, nil)
+
+	testCases := []struct {
+		setup      func()
+		statusCode int
+	}{
+		{
+			setup: func() {
+				testUseCase.EXPECT().DeleteUserSession(gomock.Any(), uint64(1), "other").Return(false, usecase.ErrNoSession)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			setup: func() {
+				testUseCase.EXPECT().DeleteUserSession(gomock.Any(), uint64(1), "other").Return(false, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			setup: func() {
+				testUseCase.EXPECT().DeleteUserSession(gomock.Any(), uint64(1), "other").Return(true, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		tc.setup()
+		request := httptest.NewRequest(http.MethodDelete, "/me/sessions/other", nil)
+		request = mux.SetURLVars(request, map[string]string{"SESSION_ID": "other"})
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		ctx = context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+		respWriter := httptest.NewRecorder()
+		testHandler.DeleteMySession(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockSessionUseCase(ctrl)
+	testTokenUseCase := tokenMock.NewMockTokenUseCase(ctrl)
+	testHandler := NewSessionHandler(testUseCase, testTokenUseCase)
+
+	testCases := []struct {
+		userID     string
+		setup      func()
+		statusCode int
+	}{
+		{userID: "a", statusCode: http.StatusBadRequest},
+		{
+			userID: "2",
+			setup: func() {
+				testUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(2)).Return(0, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			userID: "2",
+			setup: func() {
+				testUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(2)).Return(1, nil)
+				testTokenUseCase.EXPECT().RevokeUserTokens(gomock.Any(), uint64(2)).Return(0, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			userID: "2",
+			setup: func() {
+				testUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(2)).Return(1, nil)
+				testTokenUseCase.EXPECT().RevokeUserTokens(gomock.Any(), uint64(2)).Return(1, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodDelete, "/admin/users/sessions", nil)
+		request = mux.SetURLVars(request, map[string]string{"USER_ID": tc.userID})
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.RevokeUserSessions(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestLogoutAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockSessionUseCase(ctrl)
+	testHandler := NewSessionHandler(testUseCase, nil)
+
+	testUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(2, nil)
+	request := httptest.NewRequest(http.MethodPost, "/logout-all", nil)
+	ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	ctx = context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+	respWriter := httptest.NewRecorder()
+	testHandler.LogoutAll(respWriter, request.WithContext(ctx))
+	assert.Equal(t, http.StatusOK, respWriter.Result().StatusCode)
+}
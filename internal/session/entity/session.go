@@ -1,6 +1,15 @@
 package entity
 
+import "time"
+
+// Session - ExpiresAt сдвигается при активности пользователя, но не превышает MaxExpiresAt
 type Session struct {
-	ID     string
-	UserID uint64
+	ID           string
+	UserID       uint64
+	CreatedAt    time.Time
+	LastSeenAt   time.Time
+	ExpiresAt    time.Time
+	MaxExpiresAt time.Time
+	IP           string
+	UserAgent    string
 }
@@ -0,0 +1,224 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilyushkaaa/Filmoteka/internal/session/entity"
+	_ "github.com/jackc/pgx/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+const conformanceUsersCount = 2
+
+type newConformanceRepo func(t *testing.T) (SessionRepo, []uint64)
+
+func runSessionRepoConformance(t *testing.T, newRepo newConformanceRepo) {
+	t.Run("create and get", func(t *testing.T) {
+		repo, userIDs := newRepo(t)
+		session := newConformanceSession(userIDs[0], time.Hour)
+		assert.NoError(t, repo.CreateSession(context.Background(), session))
+
+		storedSession, err := repo.GetSession(context.Background(), session.ID)
+		assert.NoError(t, err)
+		assertSameSession(t, session, storedSession)
+
+		storedSession, err = repo.GetSession(context.Background(), uuid.New().String())
+		assert.NoError(t, err)
+		assert.Nil(t, storedSession)
+	})
+
+	t.Run("expired session is not visible", func(t *testing.T) {
+		repo, userIDs := newRepo(t)
+		session := newConformanceSession(userIDs[0], -time.Hour)
+		assert.NoError(t, repo.CreateSession(context.Background(), session))
+
+		storedSession, err := repo.GetSession(context.Background(), session.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, storedSession)
+		sessions, err := repo.GetUserSessions(context.Background(), userIDs[0])
+		assert.NoError(t, err)
+		assert.Empty(t, sessions)
+		isDeleted, err := repo.DeleteSession(context.Background(), session.ID)
+		assert.NoError(t, err)
+		assert.False(t, isDeleted)
+	})
+
+	t.Run("touch", func(t *testing.T) {
+		repo, userIDs := newRepo(t)
+		session := newConformanceSession(userIDs[0], time.Hour)
+		assert.NoError(t, repo.CreateSession(context.Background(), session))
+
+		session.LastSeenAt = session.LastSeenAt.Add(time.Minute)
+		session.ExpiresAt = session.ExpiresAt.Add(time.Hour)
+		assert.NoError(t, repo.TouchSession(context.Background(), session.ID, session.LastSeenAt, session.ExpiresAt))
+		storedSession, err := repo.GetSession(context.Background(), session.ID)
+		assert.NoError(t, err)
+		assertSameSession(t, session, storedSession)
+
+		isDeleted, err := repo.DeleteSession(context.Background(), session.ID)
+		assert.NoError(t, err)
+		assert.True(t, isDeleted)
+		assert.NoError(t, repo.TouchSession(context.Background(), session.ID, session.LastSeenAt, session.ExpiresAt))
+		storedSession, err = repo.GetSession(context.Background(), session.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, storedSession)
+	})
+
+	t.Run("get user sessions", func(t *testing.T) {
+		repo, userIDs := newRepo(t)
+		firstSession := newConformanceSession(userIDs[0], time.Hour)
+		secondSession := newConformanceSession(userIDs[0], time.Hour)
+		assert.NoError(t, repo.CreateSession(context.Background(), firstSession))
+		assert.NoError(t, repo.CreateSession(context.Background(), secondSession))
+		assert.NoError(t, repo.CreateSession(context.Background(), newConformanceSession(userIDs[1], time.Hour)))
+		assert.NoError(t, repo.CreateSession(context.Background(), newConformanceSession(userIDs[0], -time.Hour)))
+
+		sessions, err := repo.GetUserSessions(context.Background(), userIDs[0])
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{firstSession.ID, secondSession.ID}, sessionIDs(sessions))
+	})
+
+	t.Run("delete session", func(t *testing.T) {
+		repo, userIDs := newRepo(t)
+		session := newConformanceSession(userIDs[0], time.Hour)
+		otherSession := newConformanceSession(userIDs[0], time.Hour)
+		assert.NoError(t, repo.CreateSession(context.Background(), session))
+		assert.NoError(t, repo.CreateSession(context.Background(), otherSession))
+
+		isDeleted, err := repo.DeleteSession(context.Background(), session.ID)
+		assert.NoError(t, err)
+		assert.True(t, isDeleted)
+		isDeleted, err = repo.DeleteSession(context.Background(), session.ID)
+		assert.NoError(t, err)
+		assert.False(t, isDeleted)
+
+		storedSession, err := repo.GetSession(context.Background(), session.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, storedSession)
+		sessions, err := repo.GetUserSessions(context.Background(), userIDs[0])
+		assert.NoError(t, err)
+		assert.Equal(t, []string{otherSession.ID}, sessionIDs(sessions))
+	})
+
+	t.Run("delete user sessions", func(t *testing.T) {
+		repo, userIDs := newRepo(t)
+		assert.NoError(t, repo.CreateSession(context.Background(), newConformanceSession(userIDs[0], time.Hour)))
+		assert.NoError(t, repo.CreateSession(context.Background(), newConformanceSession(userIDs[0], time.Hour)))
+		assert.NoError(t, repo.CreateSession(context.Background(), newConformanceSession(userIDs[0], -time.Hour)))
+		otherUserSession := newConformanceSession(userIDs[1], time.Hour)
+		assert.NoError(t, repo.CreateSession(context.Background(), otherUserSession))
+
+		deletedCount, err := repo.DeleteUserSessions(context.Background(), userIDs[0])
+		assert.NoError(t, err)
+		assert.Equal(t, 2, deletedCount)
+		sessions, err := repo.GetUserSessions(context.Background(), userIDs[0])
+		assert.NoError(t, err)
+		assert.Empty(t, sessions)
+		deletedCount, err = repo.DeleteUserSessions(context.Background(), userIDs[0])
+		assert.NoError(t, err)
+		assert.Zero(t, deletedCount)
+
+		storedSession, err := repo.GetSession(context.Background(), otherUserSession.ID)
+		assert.NoError(t, err)
+		assertSameSession(t, otherUserSession, storedSession)
+	})
+}
+
+// newConformanceSession округляет время до секунд, как его хранит Redis
+func newConformanceSession(userID uint64, expiresIn time.Duration) *entity.Session {
+	now := time.Unix(time.Now().Unix(), 0)
+	return &entity.Session{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		CreatedAt:    now.Add(-2 * time.Hour),
+		LastSeenAt:   now.Add(-time.Minute),
+		ExpiresAt:    now.Add(expiresIn),
+		MaxExpiresAt: now.Add(24 * time.Hour),
+		IP:           "192.0.2.1",
+		UserAgent:    "curl/8.0",
+	}
+}
+
+func assertSameSession(t *testing.T, expected, actual *entity.Session) {
+	t.Helper()
+	if !assert.NotNil(t, actual) {
+		return
+	}
+	normalize := func(session entity.Session) entity.Session {
+		session.CreatedAt = session.CreatedAt.UTC()
+		session.LastSeenAt = session.LastSeenAt.UTC()
+		session.ExpiresAt = session.ExpiresAt.UTC()
+		session.MaxExpiresAt = session.MaxExpiresAt.UTC()
+		return session
+	}
+	assert.Equal(t, normalize(*expected), normalize(*actual))
+}
+
+func sessionIDs(sessions []entity.Session) []string {
+	ids := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.ID)
+	}
+	return ids
+}
+
+func conformanceUserIDs() []uint64 {
+	userIDs := make([]uint64, 0, conformanceUsersCount)
+	for i := 1; i <= conformanceUsersCount; i++ {
+		userIDs = append(userIDs, uint64(i))
+	}
+	return userIDs
+}
+
+func TestSessionRepoRedisConformance(t *testing.T) {
+	runSessionRepoConformance(t, func(t *testing.T) (SessionRepo, []uint64) {
+		return NewSessionRepo(newMockRedisPool(newMockRedisConn())), conformanceUserIDs()
+	})
+}
+
+func TestSessionRepoMemoryConformance(t *testing.T) {
+	runSessionRepoConformance(t, func(t *testing.T) (SessionRepo, []uint64) {
+		return NewSessionRepoMemory(), conformanceUserIDs()
+	})
+}
+
+// TestSessionRepoPGConformance запускается, только если в testPostgresDSN указана база со схемой из _postgres/db.sql
+func TestSessionRepoPGConformance(t *testing.T) {
+	dsn := os.Getenv("testPostgresDSN")
+	if dsn == "" {
+		t.Skip("testPostgresDSN is not set")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer db.Close()
+
+	runSessionRepoConformance(t, func(t *testing.T) (SessionRepo, []uint64) {
+		userIDs := make([]uint64, 0, conformanceUsersCount)
+		for i := 0; i < conformanceUsersCount; i++ {
+			var userID uint64
+			err := db.QueryRow("INSERT INTO users (username, password, role) VALUES ($1, '', 'default') RETURNING id",
+				fmt.Sprint("session_conformance_", uuid.New().String())).Scan(&userID)
+			if err != nil {
+				t.Fatalf("error creating user: %v", err)
+			}
+			userIDs = append(userIDs, userID)
+		}
+		t.Cleanup(func() {
+			for _, userID := range userIDs {
+				_, err := db.Exec("DELETE FROM users WHERE id = $1", userID)
+				if err != nil {
+					t.Errorf("error deleting user %d: %v", userID, err)
+				}
+			}
+		})
+		return NewSessionRepoPG(db), userIDs
+	})
+}
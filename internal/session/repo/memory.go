@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/session/entity"
+)
+
+// SessionRepoMemory подходит только для локального запуска и тестов с одним экземпляром сервера
+type SessionRepoMemory struct {
+	mu           sync.RWMutex
+	sessions     map[string]entity.Session
+	userSessions map[uint64]map[string]struct{}
+}
+
+func NewSessionRepoMemory() *SessionRepoMemory {
+	return &SessionRepoMemory{
+		sessions:     make(map[string]entity.Session),
+		userSessions: make(map[uint64]map[string]struct{}),
+	}
+}
+
+func (s *SessionRepoMemory) CreateSession(ctx context.Context, session *entity.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[session.ID] = *session
+	if s.userSessions[session.UserID] == nil {
+		s.userSessions[session.UserID] = make(map[string]struct{})
+	}
+	s.userSessions[session.UserID][session.ID] = struct{}{}
+	return nil
+}
+
+func (s *SessionRepoMemory) GetSession(ctx context.Context, sessionID string) (*entity.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, ok := s.sessions[sessionID]
+	if !ok || isExpired(&session, time.Now()) {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (s *SessionRepoMemory) TouchSession(ctx context.Context, sessionID string, lastSeenAt, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return nil
+	}
+	session.LastSeenAt = lastSeenAt
+	session.ExpiresAt = expiresAt
+	s.sessions[sessionID] = session
+	return nil
+}
+
+func (s *SessionRepoMemory) GetUserSessions(ctx context.Context, userID uint64) ([]entity.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := time.Now()
+	sessions := make([]entity.Session, 0, len(s.userSessions[userID]))
+	for sessionID := range s.userSessions[userID] {
+		session := s.sessions[sessionID]
+		if isExpired(&session, now) {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+func (s *SessionRepoMemory) DeleteSession(ctx context.Context, sessionID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return false, nil
+	}
+	s.deleteSession(&session)
+	return !isExpired(&session, time.Now()), nil
+}
+
+func (s *SessionRepoMemory) DeleteUserSessions(ctx context.Context, userID uint64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	deletedCount := 0
+	for sessionID := range s.userSessions[userID] {
+		session := s.sessions[sessionID]
+		if !isExpired(&session, now) {
+			deletedCount++
+		}
+		delete(s.sessions, sessionID)
+	}
+	delete(s.userSessions, userID)
+	return deletedCount, nil
+}
+
+func (s *SessionRepoMemory) DeleteExpiredSessions(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	deletedCount := 0
+	for _, session := range s.sessions {
+		session := session
+		if isExpired(&session, now) {
+			s.deleteSession(&session)
+			deletedCount++
+		}
+	}
+	return deletedCount, nil
+}
+
+func (s *SessionRepoMemory) deleteSession(session *entity.Session) {
+	delete(s.sessions, session.ID)
+	delete(s.userSessions[session.UserID], session.ID)
+	if len(s.userSessions[session.UserID]) == 0 {
+		delete(s.userSessions, session.UserID)
+	}
+}
+
+func isExpired(session *entity.Session, now time.Time) bool {
+	return !now.Before(session.ExpiresAt)
+}
@@ -0,0 +1,28 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryDeleteExpiredSessions(t *testing.T) {
+	repo := NewSessionRepoMemory()
+	activeSession := newConformanceSession(1, time.Hour)
+	assert.NoError(t, repo.CreateSession(context.Background(), activeSession))
+	assert.NoError(t, repo.CreateSession(context.Background(), newConformanceSession(1, -time.Hour)))
+	assert.NoError(t, repo.CreateSession(context.Background(), newConformanceSession(2, -time.Hour)))
+
+	deletedCount, err := repo.DeleteExpiredSessions(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deletedCount)
+	assert.Len(t, repo.sessions, 1)
+	assert.Contains(t, repo.sessions, activeSession.ID)
+	assert.NotContains(t, repo.userSessions, uint64(2))
+
+	deletedCount, err = repo.DeleteExpiredSessions(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, deletedCount)
+}
@@ -5,7 +5,9 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	entity "github.com/ilyushkaaa/Filmoteka/internal/session/entity"
@@ -35,45 +37,127 @@ func (m *MockSessionRepo) EXPECT() *MockSessionRepoMockRecorder {
 }
 
 // CreateSession mocks base method.
-func (m *MockSessionRepo) CreateSession(session *entity.Session) error {
+func (m *MockSessionRepo) CreateSession(ctx context.Context, session *entity.Session) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSession", session)
+	ret := m.ctrl.Call(m, "CreateSession", ctx, session)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateSession indicates an expected call of CreateSession.
-func (mr *MockSessionRepoMockRecorder) CreateSession(session interface{}) *gomock.Call {
+func (mr *MockSessionRepoMockRecorder) CreateSession(ctx, session interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionRepo)(nil).CreateSession), session)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionRepo)(nil).CreateSession), ctx, session)
 }
 
 // DeleteSession mocks base method.
-func (m *MockSessionRepo) DeleteSession(sessionID string) (bool, error) {
+func (m *MockSessionRepo) DeleteSession(ctx context.Context, sessionID string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteSession", sessionID)
+	ret := m.ctrl.Call(m, "DeleteSession", ctx, sessionID)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DeleteSession indicates an expected call of DeleteSession.
-func (mr *MockSessionRepoMockRecorder) DeleteSession(sessionID interface{}) *gomock.Call {
+func (mr *MockSessionRepoMockRecorder) DeleteSession(ctx, sessionID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockSessionRepo)(nil).DeleteSession), sessionID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockSessionRepo)(nil).DeleteSession), ctx, sessionID)
+}
+
+// DeleteUserSessions mocks base method.
+func (m *MockSessionRepo) DeleteUserSessions(ctx context.Context, userID uint64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserSessions", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUserSessions indicates an expected call of DeleteUserSessions.
+func (mr *MockSessionRepoMockRecorder) DeleteUserSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSessions", reflect.TypeOf((*MockSessionRepo)(nil).DeleteUserSessions), ctx, userID)
 }
 
 // GetSession mocks base method.
-func (m *MockSessionRepo) GetSession(sessionID string) (*entity.Session, error) {
+func (m *MockSessionRepo) GetSession(ctx context.Context, sessionID string) (*entity.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", sessionID)
+	ret := m.ctrl.Call(m, "GetSession", ctx, sessionID)
 	ret0, _ := ret[0].(*entity.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetSession indicates an expected call of GetSession.
-func (mr *MockSessionRepoMockRecorder) GetSession(sessionID interface{}) *gomock.Call {
+func (mr *MockSessionRepoMockRecorder) GetSession(ctx, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockSessionRepo)(nil).GetSession), ctx, sessionID)
+}
+
+// GetUserSessions mocks base method.
+func (m *MockSessionRepo) GetUserSessions(ctx context.Context, userID uint64) ([]entity.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSessions", ctx, userID)
+	ret0, _ := ret[0].([]entity.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSessions indicates an expected call of GetUserSessions.
+func (mr *MockSessionRepoMockRecorder) GetUserSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSessions", reflect.TypeOf((*MockSessionRepo)(nil).GetUserSessions), ctx, userID)
+}
+
+// TouchSession mocks base method.
+func (m *MockSessionRepo) TouchSession(ctx context.Context, sessionID string, lastSeenAt, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", ctx, sessionID, lastSeenAt, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockSessionRepoMockRecorder) TouchSession(ctx, sessionID, lastSeenAt, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockSessionRepo)(nil).TouchSession), ctx, sessionID, lastSeenAt, expiresAt)
+}
+
+// MockExpiredSessionsCleaner is a mock of ExpiredSessionsCleaner interface.
+type MockExpiredSessionsCleaner struct {
+	ctrl     *gomock.Controller
+	recorder *MockExpiredSessionsCleanerMockRecorder
+}
+
+// MockExpiredSessionsCleanerMockRecorder is the mock recorder for MockExpiredSessionsCleaner.
+type MockExpiredSessionsCleanerMockRecorder struct {
+	mock *MockExpiredSessionsCleaner
+}
+
+// NewMockExpiredSessionsCleaner creates a new mock instance.
+func NewMockExpiredSessionsCleaner(ctrl *gomock.Controller) *MockExpiredSessionsCleaner {
+	mock := &MockExpiredSessionsCleaner{ctrl: ctrl}
+	mock.recorder = &MockExpiredSessionsCleanerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExpiredSessionsCleaner) EXPECT() *MockExpiredSessionsCleanerMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpiredSessions mocks base method.
+func (m *MockExpiredSessionsCleaner) DeleteExpiredSessions(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredSessions", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredSessions indicates an expected call of DeleteExpiredSessions.
+func (mr *MockExpiredSessionsCleanerMockRecorder) DeleteExpiredSessions(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockSessionRepo)(nil).GetSession), sessionID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSessions", reflect.TypeOf((*MockExpiredSessionsCleaner)(nil).DeleteExpiredSessions), ctx)
 }
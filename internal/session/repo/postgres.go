@@ -0,0 +1,120 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/session/entity"
+)
+
+const sessionColumns = "id, user_id, created_at, last_seen_at, expires_at, max_expires_at, ip, user_agent"
+
+type SessionRepoPG struct {
+	db *sql.DB
+}
+
+func NewSessionRepoPG(db *sql.DB) *SessionRepoPG {
+	return &SessionRepoPG{
+		db: db,
+	}
+}
+
+func (s *SessionRepoPG) CreateSession(ctx context.Context, session *entity.Session) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO sessions (`+sessionColumns+`)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		session.ID, session.UserID, session.CreatedAt, session.LastSeenAt, session.ExpiresAt, session.MaxExpiresAt,
+		session.IP, session.UserAgent)
+	return err
+}
+
+func (s *SessionRepoPG) GetSession(ctx context.Context, sessionID string) (*entity.Session, error) {
+	session, err := scanSession(s.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE id = $1 AND expires_at > $2`,
+		sessionID, time.Now()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *SessionRepoPG) TouchSession(ctx context.Context, sessionID string, lastSeenAt, expiresAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE sessions SET last_seen_at = $2, expires_at = $3 WHERE id = $1",
+		sessionID, lastSeenAt, expiresAt)
+	return err
+}
+
+func (s *SessionRepoPG) GetUserSessions(ctx context.Context, userID uint64) ([]entity.Session, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE user_id = $1 AND expires_at > $2
+        ORDER BY created_at`, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	sessions := make([]entity.Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+// DeleteSession удаляет сессию и возвращает true, только если она еще не истекла
+func (s *SessionRepoPG) DeleteSession(ctx context.Context, sessionID string) (bool, error) {
+	var expiresAt time.Time
+	err := s.db.QueryRowContext(ctx, "DELETE FROM sessions WHERE id = $1 RETURNING expires_at", sessionID).Scan(&expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return time.Now().Before(expiresAt), nil
+}
+
+// DeleteUserSessions удаляет все сессии пользователя и возвращает число еще не истекших среди них
+func (s *SessionRepoPG) DeleteUserSessions(ctx context.Context, userID uint64) (int, error) {
+	var deletedCount int
+	err := s.db.QueryRowContext(ctx, `
+        WITH deleted AS (DELETE FROM sessions WHERE user_id = $1 RETURNING expires_at)
+        SELECT COUNT(*) FROM deleted WHERE expires_at > $2
+    `, userID, time.Now()).Scan(&deletedCount)
+	if err != nil {
+		return 0, err
+	}
+	return deletedCount, nil
+}
+
+func (s *SessionRepoPG) DeleteExpiredSessions(ctx context.Context) (int, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM sessions WHERE expires_at <= $1", time.Now())
+	if err != nil {
+		return 0, err
+	}
+	deletedCount, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(deletedCount), nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSession(row rowScanner) (*entity.Session, error) {
+	session := &entity.Session{}
+	err := row.Scan(&session.ID, &session.UserID, &session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt,
+		&session.MaxExpiresAt, &session.IP, &session.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
@@ -0,0 +1,165 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var sessionRowColumns = []string{"id", "user_id", "created_at", "last_seen_at", "expires_at", "max_expires_at", "ip", "user_agent"}
+
+func newSessionRepoPGMock(t *testing.T) (*SessionRepoPG, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	return NewSessionRepoPG(db), mock, func() {
+		_ = db.Close()
+	}
+}
+
+func TestPGCreateSession(t *testing.T) {
+	repo, mock, closeDB := newSessionRepoPGMock(t)
+	defer closeDB()
+	session := newTestSession("valid_session", 1)
+
+	mock.ExpectExec("INSERT INTO sessions").
+		WithArgs(session.ID, session.UserID, session.CreatedAt, session.LastSeenAt, session.ExpiresAt,
+			session.MaxExpiresAt, session.IP, session.UserAgent).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.CreateSession(context.Background(), session))
+
+	mock.ExpectExec("INSERT INTO sessions").WillReturnError(fmt.Errorf("error"))
+	assert.Error(t, repo.CreateSession(context.Background(), session))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGGetSession(t *testing.T) {
+	repo, mock, closeDB := newSessionRepoPGMock(t)
+	defer closeDB()
+	session := newTestSession("valid_session", 1)
+
+	mock.ExpectQuery("SELECT (.+) FROM sessions WHERE id = (.+) AND expires_at > (.+)").
+		WithArgs("valid_session", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(sessionRowColumns).AddRow(session.ID, session.UserID, session.CreatedAt,
+			session.LastSeenAt, session.ExpiresAt, session.MaxExpiresAt, session.IP, session.UserAgent))
+	storedSession, err := repo.GetSession(context.Background(), "valid_session")
+	assert.NoError(t, err)
+	assert.Equal(t, session, storedSession)
+
+	mock.ExpectQuery("SELECT (.+) FROM sessions").
+		WithArgs("unknown_session", sqlmock.AnyArg()).
+		WillReturnError(sql.ErrNoRows)
+	storedSession, err = repo.GetSession(context.Background(), "unknown_session")
+	assert.NoError(t, err)
+	assert.Nil(t, storedSession)
+
+	mock.ExpectQuery("SELECT (.+) FROM sessions").WillReturnError(fmt.Errorf("error"))
+	_, err = repo.GetSession(context.Background(), "valid_session")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGTouchSession(t *testing.T) {
+	repo, mock, closeDB := newSessionRepoPGMock(t)
+	defer closeDB()
+	lastSeenAt := time.Now()
+	expiresAt := lastSeenAt.Add(time.Hour)
+
+	mock.ExpectExec("UPDATE sessions SET last_seen_at = (.+), expires_at = (.+) WHERE id = (.+)").
+		WithArgs("valid_session", lastSeenAt, expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.TouchSession(context.Background(), "valid_session", lastSeenAt, expiresAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGGetUserSessions(t *testing.T) {
+	repo, mock, closeDB := newSessionRepoPGMock(t)
+	defer closeDB()
+	session := newTestSession("valid_session", 1)
+
+	mock.ExpectQuery("SELECT (.+) FROM sessions WHERE user_id = (.+) AND expires_at > (.+) ORDER BY created_at").
+		WithArgs(uint64(1), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(sessionRowColumns).AddRow(session.ID, session.UserID, session.CreatedAt,
+			session.LastSeenAt, session.ExpiresAt, session.MaxExpiresAt, session.IP, session.UserAgent))
+	sessions, err := repo.GetUserSessions(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, *session, sessions[0])
+
+	mock.ExpectQuery("SELECT (.+) FROM sessions").WillReturnError(fmt.Errorf("error"))
+	sessions, err = repo.GetUserSessions(context.Background(), 1)
+	assert.Error(t, err)
+	assert.Nil(t, sessions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGDeleteSession(t *testing.T) {
+	repo, mock, closeDB := newSessionRepoPGMock(t)
+	defer closeDB()
+
+	mock.ExpectQuery("DELETE FROM sessions WHERE id = (.+) RETURNING expires_at").
+		WithArgs("valid_session").
+		WillReturnRows(sqlmock.NewRows([]string{"expires_at"}).AddRow(time.Now().Add(time.Hour)))
+	isDeleted, err := repo.DeleteSession(context.Background(), "valid_session")
+	assert.NoError(t, err)
+	assert.True(t, isDeleted)
+
+	mock.ExpectQuery("DELETE FROM sessions").
+		WithArgs("expired_session").
+		WillReturnRows(sqlmock.NewRows([]string{"expires_at"}).AddRow(time.Now().Add(-time.Hour)))
+	isDeleted, err = repo.DeleteSession(context.Background(), "expired_session")
+	assert.NoError(t, err)
+	assert.False(t, isDeleted)
+
+	mock.ExpectQuery("DELETE FROM sessions").
+		WithArgs("unknown_session").
+		WillReturnError(sql.ErrNoRows)
+	isDeleted, err = repo.DeleteSession(context.Background(), "unknown_session")
+	assert.NoError(t, err)
+	assert.False(t, isDeleted)
+
+	mock.ExpectQuery("DELETE FROM sessions").WillReturnError(fmt.Errorf("error"))
+	_, err = repo.DeleteSession(context.Background(), "valid_session")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGDeleteUserSessions(t *testing.T) {
+	repo, mock, closeDB := newSessionRepoPGMock(t)
+	defer closeDB()
+
+	mock.ExpectQuery("WITH deleted AS \\(DELETE FROM sessions WHERE user_id = (.+) RETURNING expires_at\\)").
+		WithArgs(uint64(1), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	deletedCount, err := repo.DeleteUserSessions(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deletedCount)
+
+	mock.ExpectQuery("WITH deleted AS").WillReturnError(fmt.Errorf("error"))
+	_, err = repo.DeleteUserSessions(context.Background(), 1)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGDeleteExpiredSessions(t *testing.T) {
+	repo, mock, closeDB := newSessionRepoPGMock(t)
+	defer closeDB()
+
+	mock.ExpectExec("DELETE FROM sessions WHERE expires_at <= (.+)").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	deletedCount, err := repo.DeleteExpiredSessions(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, deletedCount)
+
+	mock.ExpectExec("DELETE FROM sessions").WillReturnError(fmt.Errorf("error"))
+	_, err = repo.DeleteExpiredSessions(context.Background())
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,61 +1,256 @@
 package repo
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
 	"github.com/gomodule/redigo/redis"
 	"github.com/ilyushkaaa/Filmoteka/internal/session/entity"
 )
 
 //go:generate mockgen -source=session.go -destination=session_mock.go -package=repo SessionRepo
 type SessionRepo interface {
-	CreateSession(session *entity.Session) error
-	GetSession(sessionID string) (*entity.Session, error)
-	DeleteSession(sessionID string) (bool, error)
+	CreateSession(ctx context.Context, session *entity.Session) error
+	GetSession(ctx context.Context, sessionID string) (*entity.Session, error)
+	TouchSession(ctx context.Context, sessionID string, lastSeenAt, expiresAt time.Time) error
+	GetUserSessions(ctx context.Context, userID uint64) ([]entity.Session, error)
+	DeleteSession(ctx context.Context, sessionID string) (bool, error)
+	DeleteUserSessions(ctx context.Context, userID uint64) (int, error)
+}
+
+// ExpiredSessionsCleaner реализуют хранилища, в которых истекшие сессии не удаляются сами
+type ExpiredSessionsCleaner interface {
+	DeleteExpiredSessions(ctx context.Context) (int, error)
 }
 
+const (
+	sessionKeyPrefix      = "session:"
+	userSessionsKeyPrefix = "user_sessions:"
+)
+
+// touchSessionScript не создает заново хеш уже удаленной или истекшей сессии
+var touchSessionScript = redis.NewScript(1, `
+if redis.call("EXISTS", KEYS[1]) == 0 then
+    return 0
+end
+redis.call("HSET", KEYS[1], "last_seen_at", ARGV[1], "expires_at", ARGV[2])
+redis.call("EXPIREAT", KEYS[1], ARGV[2])
+return 1
+`)
+
+// SessionRepoRedis хранит сессию в хеше session:<id>, а ее идентификатор - в множестве user_sessions:<user id>
 type SessionRepoRedis struct {
-	redisConn  redis.Conn
-	expireTime int
+	redisPool *redis.Pool
 }
 
-func NewSessionRepo(redisConn redis.Conn) *SessionRepoRedis {
+func NewSessionRepo(redisPool *redis.Pool) *SessionRepoRedis {
 	return &SessionRepoRedis{
-		redisConn:  redisConn,
-		expireTime: 24 * 60 * 60,
+		redisPool: redisPool,
 	}
 }
 
-func (s *SessionRepoRedis) CreateSession(session *entity.Session) error {
-	result, err := redis.String(s.redisConn.Do("SET", session.ID, session.UserID, "EX", s.expireTime))
-	if err != nil || result != "OK" {
+func (s *SessionRepoRedis) CreateSession(ctx context.Context, session *entity.Session) error {
+	conn, err := s.redisPool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	sessionKey := sessionKeyPrefix + session.ID
+	userSessionsKey := getUserSessionsKey(session.UserID)
+	commands := [][]interface{}{
+		{"HSET", sessionKey,
+			"user_id", session.UserID,
+			"created_at", session.CreatedAt.Unix(),
+			"last_seen_at", session.LastSeenAt.Unix(),
+			"expires_at", session.ExpiresAt.Unix(),
+			"max_expires_at", session.MaxExpiresAt.Unix(),
+			"ip", session.IP,
+			"user_agent", session.UserAgent,
+		},
+		{"EXPIREAT", sessionKey, session.ExpiresAt.Unix()},
+		{"SADD", userSessionsKey, session.ID},
+		// у новой сессии самый поздний MaxExpiresAt среди сессий пользователя
+		{"EXPIREAT", userSessionsKey, session.MaxExpiresAt.Unix()},
+	}
+	err = conn.Send("MULTI")
+	if err != nil {
+		return err
+	}
+	for _, command := range commands {
+		err = conn.Send(command[0].(string), command[1:]...)
+		if err != nil {
+			return err
+		}
+	}
+	replies, err := redis.Values(redis.DoContext(conn, ctx, "EXEC"))
+	if err != nil {
 		return err
 	}
+	for _, reply := range replies {
+		if replyErr, ok := reply.(redis.Error); ok {
+			return replyErr
+		}
+	}
 	return nil
 }
 
-func (s *SessionRepoRedis) GetSession(sessionID string) (*entity.Session, error) {
-	userID, err := redis.Uint64(s.redisConn.Do("GET", sessionID))
+func (s *SessionRepoRedis) GetSession(ctx context.Context, sessionID string) (*entity.Session, error) {
+	conn, err := s.redisPool.GetContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	session := &entity.Session{
-		ID:     sessionID,
-		UserID: userID,
+	defer func() {
+		_ = conn.Close()
+	}()
+	return getSession(ctx, conn, sessionID)
+}
+
+func getSession(ctx context.Context, conn redis.Conn, sessionID string) (*entity.Session, error) {
+	fields, err := redis.StringMap(redis.DoContext(conn, ctx, "HGETALL", sessionKeyPrefix+sessionID))
+	if err != nil {
+		return nil, err
 	}
-	return session, nil
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return parseSession(sessionID, fields)
+}
 
+func (s *SessionRepoRedis) TouchSession(ctx context.Context, sessionID string, lastSeenAt, expiresAt time.Time) error {
+	conn, err := s.redisPool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, err = touchSessionScript.DoContext(ctx, conn, sessionKeyPrefix+sessionID, lastSeenAt.Unix(), expiresAt.Unix())
+	return err
+}
+
+func (s *SessionRepoRedis) GetUserSessions(ctx context.Context, userID uint64) ([]entity.Session, error) {
+	conn, err := s.redisPool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	userSessionsKey := getUserSessionsKey(userID)
+	sessionIDs, err := redis.Strings(redis.DoContext(conn, ctx, "SMEMBERS", userSessionsKey))
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]entity.Session, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		session, err := getSession(ctx, conn, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if session == nil {
+			_, err = redis.DoContext(conn, ctx, "SREM", userSessionsKey, sessionID)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, nil
 }
 
-func (s *SessionRepoRedis) DeleteSession(sessionID string) (bool, error) {
-	exists, err := redis.Bool(s.redisConn.Do("EXISTS", sessionID))
+func (s *SessionRepoRedis) DeleteSession(ctx context.Context, sessionID string) (bool, error) {
+	conn, err := s.redisPool.GetContext(ctx)
 	if err != nil {
 		return false, err
 	}
-	if !exists {
+	defer func() {
+		_ = conn.Close()
+	}()
+	sessionKey := sessionKeyPrefix + sessionID
+	userID, err := redis.Uint64(redis.DoContext(conn, ctx, "HGET", sessionKey, "user_id"))
+	if errors.Is(err, redis.ErrNil) {
 		return false, nil
 	}
-	_, err = s.redisConn.Do("DEL", sessionID)
+	if err != nil {
+		return false, err
+	}
+	_, err = redis.DoContext(conn, ctx, "DEL", sessionKey)
+	if err != nil {
+		return false, err
+	}
+	_, err = redis.DoContext(conn, ctx, "SREM", getUserSessionsKey(userID), sessionID)
 	if err != nil {
 		return false, err
 	}
 	return true, nil
 }
+
+func (s *SessionRepoRedis) DeleteUserSessions(ctx context.Context, userID uint64) (int, error) {
+	conn, err := s.redisPool.GetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	userSessionsKey := getUserSessionsKey(userID)
+	sessionIDs, err := redis.Strings(redis.DoContext(conn, ctx, "SMEMBERS", userSessionsKey))
+	if err != nil {
+		return 0, err
+	}
+	deletedCount := 0
+	for _, sessionID := range sessionIDs {
+		isDeleted, err := redis.Bool(redis.DoContext(conn, ctx, "DEL", sessionKeyPrefix+sessionID))
+		if err != nil {
+			return deletedCount, err
+		}
+		if isDeleted {
+			deletedCount++
+		}
+	}
+	_, err = redis.DoContext(conn, ctx, "DEL", userSessionsKey)
+	if err != nil {
+		return deletedCount, err
+	}
+	return deletedCount, nil
+}
+
+func getUserSessionsKey(userID uint64) string {
+	return fmt.Sprint(userSessionsKeyPrefix, userID)
+}
+
+func parseSession(sessionID string, fields map[string]string) (*entity.Session, error) {
+	userID, err := strconv.ParseUint(fields["user_id"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("bad user id in session %s: %w", sessionID, err)
+	}
+	session := &entity.Session{
+		ID:        sessionID,
+		UserID:    userID,
+		IP:        fields["ip"],
+		UserAgent: fields["user_agent"],
+	}
+	timeFields := []struct {
+		name  string
+		value *time.Time
+	}{
+		{name: "created_at", value: &session.CreatedAt},
+		{name: "last_seen_at", value: &session.LastSeenAt},
+		{name: "expires_at", value: &session.ExpiresAt},
+		{name: "max_expires_at", value: &session.MaxExpiresAt},
+	}
+	for _, field := range timeFields {
+		unixTime, err := strconv.ParseInt(fields[field.name], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad %s in session %s: %w", field.name, sessionID, err)
+		}
+		*field.value = time.Unix(unixTime, 0)
+	}
+	return session, nil
+}
@@ -1,86 +1,390 @@
 package repo
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/gomodule/redigo/redis"
 	"github.com/ilyushkaaa/Filmoteka/internal/session/entity"
 	"github.com/stretchr/testify/assert"
 )
 
-type MockRedisConn struct{}
+type MockRedisConn struct {
+	hashes   map[string]map[string]string
+	sets     map[string]map[string]struct{}
+	expireAt map[string]int64
+	queued   [][]interface{}
+	requests int
+	err      error
+}
+
+func newMockRedisConn() *MockRedisConn {
+	return &MockRedisConn{
+		hashes:   make(map[string]map[string]string),
+		sets:     make(map[string]map[string]struct{}),
+		expireAt: make(map[string]int64),
+	}
+}
 
 func (m *MockRedisConn) Do(commandName string, args ...interface{}) (interface{}, error) {
-	sessionID := args[0].(string)
-	if commandName == "GET" || commandName == "EXISTS" || commandName == "DEL" {
-		switch sessionID {
-		case "valid_session":
-			return int64(123), nil
+	// пул отправляет пустую команду, когда соединение возвращается в него
+	if commandName == "" {
+		return nil, nil
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	if commandName == "EXEC" {
+		replies := make([]interface{}, 0, len(m.queued))
+		for _, command := range m.queued {
+			reply, err := m.Do(command[0].(string), command[1:]...)
+			if err != nil {
+				reply = redis.Error(err.Error())
+			}
+			replies = append(replies, reply)
 		}
+		m.queued = nil
+		return replies, nil
 	}
-	if commandName == "SET" {
-		switch sessionID {
-		case "valid_session":
-			return "OK", nil
+	key := args[0].(string)
+	if commandName == "EVALSHA" {
+		key = args[2].(string)
+	}
+	if expireAt, ok := m.expireAt[key]; ok && expireAt <= time.Now().Unix() {
+		delete(m.hashes, key)
+		delete(m.sets, key)
+		delete(m.expireAt, key)
+	}
+	switch commandName {
+	case "HSET":
+		if m.hashes[key] == nil {
+			m.hashes[key] = make(map[string]string)
+		}
+		for i := 1; i+1 < len(args); i += 2 {
+			m.hashes[key][args[i].(string)] = fmt.Sprint(args[i+1])
+		}
+		return int64(len(args) / 2), nil
+	case "HGET":
+		value, ok := m.hashes[key][args[1].(string)]
+		if !ok {
+			return nil, nil
+		}
+		return []byte(value), nil
+	case "HGETALL":
+		values := make([]interface{}, 0, 2*len(m.hashes[key]))
+		for field, value := range m.hashes[key] {
+			values = append(values, []byte(field), []byte(value))
+		}
+		return values, nil
+	case "DEL":
+		_, isHash := m.hashes[key]
+		_, isSet := m.sets[key]
+		delete(m.hashes, key)
+		delete(m.sets, key)
+		if isHash || isSet {
+			return int64(1), nil
 		}
+		return int64(0), nil
+	case "EXPIREAT":
+		m.expireAt[key] = args[1].(int64)
+		return int64(1), nil
+	case "SADD":
+		if m.sets[key] == nil {
+			m.sets[key] = make(map[string]struct{})
+		}
+		m.sets[key][args[1].(string)] = struct{}{}
+		return int64(1), nil
+	case "SREM":
+		delete(m.sets[key], args[1].(string))
+		return int64(1), nil
+	case "EVALSHA":
+		// единственный скрипт хранилища - touchSessionScript
+		if _, ok := m.hashes[key]; !ok {
+			return int64(0), nil
+		}
+		m.hashes[key]["last_seen_at"] = fmt.Sprint(args[3])
+		m.hashes[key]["expires_at"] = fmt.Sprint(args[4])
+		m.expireAt[key] = args[4].(int64)
+		return int64(1), nil
+	case "SMEMBERS":
+		members := make([]interface{}, 0, len(m.sets[key]))
+		for member := range m.sets[key] {
+			members = append(members, []byte(member))
+		}
+		return members, nil
 	}
 	return nil, fmt.Errorf("err")
 }
 
-func TestCreateSession(t *testing.T) {
-	red := &MockRedisConn{}
-	repo := NewSessionRepo(red)
+func (m *MockRedisConn) DoContext(_ context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	if commandName != "" {
+		m.requests++
+	}
+	return m.Do(commandName, args...)
+}
 
-	sessionInvalid := &entity.Session{
-		ID:     "invalid",
-		UserID: uint64(1),
+func (m *MockRedisConn) Close() error {
+	return nil
+}
+
+func (m *MockRedisConn) Err() error {
+	return nil
+}
+
+func (m *MockRedisConn) Send(commandName string, args ...interface{}) error {
+	switch commandName {
+	case "MULTI", "DISCARD":
+		m.queued = nil
+	default:
+		m.queued = append(m.queued, append([]interface{}{commandName}, args...))
 	}
-	err := repo.CreateSession(sessionInvalid)
-	assert.Error(t, err)
+	return nil
+}
+
+func (m *MockRedisConn) Flush() error {
+	return nil
+}
+
+func (m *MockRedisConn) Receive() (interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRedisConn) ReceiveContext(_ context.Context) (interface{}, error) {
+	return nil, nil
+}
+
+var _ redis.ConnWithContext = &MockRedisConn{}
 
-	sessionValid := &entity.Session{
-		ID:     "valid_session",
-		UserID: uint64(1),
+func newMockRedisPool(redisConn redis.Conn) *redis.Pool {
+	return &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redisConn, nil
+		},
 	}
-	err = repo.CreateSession(sessionValid)
-	assert.NoError(t, err)
 }
 
-func TestGetSession(t *testing.T) {
-	red := &MockRedisConn{}
-	repo := NewSessionRepo(red)
+func newTestSession(sessionID string, userID uint64) *entity.Session {
+	now := time.Unix(time.Now().Unix(), 0)
+	return &entity.Session{
+		ID:           sessionID,
+		UserID:       userID,
+		CreatedAt:    now,
+		LastSeenAt:   now,
+		ExpiresAt:    now.Add(time.Hour),
+		MaxExpiresAt: now.Add(24 * time.Hour),
+		IP:           "192.0.2.1",
+		UserAgent:    "curl/8.0",
+	}
+}
+
+func TestCreateAndGetSession(t *testing.T) {
+	red := newMockRedisConn()
+	repo := NewSessionRepo(newMockRedisPool(red))
+
+	session := newTestSession("valid_session", 1)
+	err := repo.CreateSession(context.Background(), session)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, red.requests)
+
+	storedSession, err := repo.GetSession(context.Background(), "valid_session")
+	assert.NoError(t, err)
+	assert.Equal(t, session, storedSession)
+	assert.Equal(t, session.ExpiresAt.Unix(), red.expireAt[sessionKeyPrefix+"valid_session"])
+	assert.Equal(t, session.MaxExpiresAt.Unix(), red.expireAt[userSessionsKeyPrefix+"1"])
 
-	_, err := repo.GetSession("valid_session")
+	storedSession, err = repo.GetSession(context.Background(), "unknown_session")
 	assert.NoError(t, err)
+	assert.Nil(t, storedSession)
 
-	_, err = repo.GetSession("invalid_session")
+	red.err = fmt.Errorf("err")
+	err = repo.CreateSession(context.Background(), session)
 	assert.Error(t, err)
+	_, err = repo.GetSession(context.Background(), "valid_session")
+	assert.Error(t, err)
+}
+
+func TestTouchSession(t *testing.T) {
+	red := newMockRedisConn()
+	repo := NewSessionRepo(newMockRedisPool(red))
+
+	session := newTestSession("valid_session", 1)
+	assert.NoError(t, repo.CreateSession(context.Background(), session))
+
+	lastSeenAt := session.LastSeenAt.Add(time.Hour)
+	expiresAt := lastSeenAt.Add(time.Hour)
+	assert.NoError(t, repo.TouchSession(context.Background(), "valid_session", lastSeenAt, expiresAt))
+	storedSession, err := repo.GetSession(context.Background(), "valid_session")
+	assert.NoError(t, err)
+	assert.Equal(t, lastSeenAt, storedSession.LastSeenAt)
+	assert.Equal(t, expiresAt, storedSession.ExpiresAt)
+	assert.Equal(t, session.CreatedAt, storedSession.CreatedAt)
+	assert.Equal(t, expiresAt.Unix(), red.expireAt[sessionKeyPrefix+"valid_session"])
+
+	assert.NoError(t, repo.TouchSession(context.Background(), "unknown_session", lastSeenAt, expiresAt))
+	assert.NotContains(t, red.hashes, sessionKeyPrefix+"unknown_session")
+}
+
+func TestGetUserSessions(t *testing.T) {
+	red := newMockRedisConn()
+	repo := NewSessionRepo(newMockRedisPool(red))
+
+	assert.NoError(t, repo.CreateSession(context.Background(), newTestSession("session_1", 1)))
+	assert.NoError(t, repo.CreateSession(context.Background(), newTestSession("session_2", 2)))
+	assert.NoError(t, repo.CreateSession(context.Background(), newTestSession("session_3", 1)))
+	// сессия истекла, но осталась в множестве сессий пользователя
+	delete(red.hashes, sessionKeyPrefix+"session_3")
+
+	sessions, err := repo.GetUserSessions(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "session_1", sessions[0].ID)
+	assert.NotContains(t, red.sets[userSessionsKeyPrefix+"1"], "session_3")
+
+	sessions, err = repo.GetUserSessions(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
 }
 
 func TestDeleteSession(t *testing.T) {
-	red := &MockRedisConn{}
-	repo := NewSessionRepo(red)
+	red := newMockRedisConn()
+	repo := NewSessionRepo(newMockRedisPool(red))
+	assert.NoError(t, repo.CreateSession(context.Background(), newTestSession("valid_session", 1)))
 
-	_, err := repo.DeleteSession("valid_session")
+	isDeleted, err := repo.DeleteSession(context.Background(), "valid_session")
 	assert.NoError(t, err)
+	assert.True(t, isDeleted)
+	assert.Empty(t, red.sets[userSessionsKeyPrefix+"1"])
+
+	isDeleted, err = repo.DeleteSession(context.Background(), "valid_session")
+	assert.NoError(t, err)
+	assert.False(t, isDeleted)
+
+	red.err = fmt.Errorf("err")
+	_, err = repo.DeleteSession(context.Background(), "valid_session")
+	assert.Error(t, err)
 }
 
-func (m *MockRedisConn) Close() error {
-	return nil
+func TestDeleteUserSessions(t *testing.T) {
+	red := newMockRedisConn()
+	repo := NewSessionRepo(newMockRedisPool(red))
+
+	assert.NoError(t, repo.CreateSession(context.Background(), newTestSession("session_1", 1)))
+	assert.NoError(t, repo.CreateSession(context.Background(), newTestSession("session_2", 2)))
+	assert.NoError(t, repo.CreateSession(context.Background(), newTestSession("session_3", 1)))
+	delete(red.hashes, sessionKeyPrefix+"session_3")
+
+	deletedCount, err := repo.DeleteUserSessions(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deletedCount)
+	assert.NotContains(t, red.sets, userSessionsKeyPrefix+"1")
+
+	session, err := repo.GetSession(context.Background(), "session_2")
+	assert.NoError(t, err)
+	assert.NotNil(t, session)
+
+	red.err = fmt.Errorf("err")
+	_, err = repo.DeleteUserSessions(context.Background(), 1)
+	assert.Error(t, err)
 }
 
-func (m *MockRedisConn) Err() error {
-	return nil
+// exclusiveConn отмечает одновременное использование соединения из нескольких горутин
+type exclusiveConn struct {
+	*MockRedisConn
+	storeMu *sync.Mutex
+	inUse   int32
+	misuses *int32
+	closed  *int32
 }
 
-func (m *MockRedisConn) Send(commandName string, args ...interface{}) error {
-	return nil
+func (c *exclusiveConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	if !atomic.CompareAndSwapInt32(&c.inUse, 0, 1) {
+		atomic.AddInt32(c.misuses, 1)
+	} else {
+		defer atomic.StoreInt32(&c.inUse, 0)
+	}
+	c.storeMu.Lock()
+	defer c.storeMu.Unlock()
+	return c.MockRedisConn.Do(commandName, args...)
 }
 
-func (m *MockRedisConn) Flush() error {
+func (c *exclusiveConn) DoContext(_ context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	return c.Do(commandName, args...)
+}
+
+func (c *exclusiveConn) Close() error {
+	atomic.AddInt32(c.closed, 1)
 	return nil
 }
 
-func (m *MockRedisConn) Receive() (reply interface{}, err error) {
-	return nil, nil
+func TestSessionRepoConcurrentUse(t *testing.T) {
+	const (
+		goroutinesCount = 32
+		usersCount      = 4
+	)
+	store := newMockRedisConn()
+	var (
+		storeMu                 sync.Mutex
+		dialed, closed, misuses int32
+	)
+	pool := &redis.Pool{
+		MaxIdle:   2,
+		MaxActive: 4,
+		Wait:      true,
+		Dial: func() (redis.Conn, error) {
+			atomic.AddInt32(&dialed, 1)
+			conn := &MockRedisConn{hashes: store.hashes, sets: store.sets, expireAt: store.expireAt}
+			return &exclusiveConn{MockRedisConn: conn, storeMu: &storeMu, misuses: &misuses, closed: &closed}, nil
+		},
+	}
+	repo := NewSessionRepo(pool)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutinesCount)
+	for i := 0; i < goroutinesCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sessionID := fmt.Sprint("session_", i)
+			session := newTestSession(sessionID, uint64(i%usersCount))
+			if err := repo.CreateSession(context.Background(), session); err != nil {
+				errs <- err
+				return
+			}
+			if err := repo.TouchSession(context.Background(), sessionID, session.LastSeenAt, session.ExpiresAt); err != nil {
+				errs <- err
+				return
+			}
+			if _, err := repo.GetUserSessions(context.Background(), session.UserID); err != nil {
+				errs <- err
+				return
+			}
+			if i%2 == 0 {
+				if _, err := repo.DeleteSession(context.Background(), sessionID); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	assert.Zero(t, atomic.LoadInt32(&misuses), "connection was used by several goroutines at once")
+	stats := pool.Stats()
+	assert.Equal(t, stats.IdleCount, stats.ActiveCount, "connections were not returned to the pool")
+	remainingCount := 0
+	for userID := uint64(0); userID < usersCount; userID++ {
+		sessions, err := repo.GetUserSessions(context.Background(), userID)
+		assert.NoError(t, err)
+		remainingCount += len(sessions)
+	}
+	assert.Equal(t, goroutinesCount/2, remainingCount)
+	assert.NoError(t, pool.Close())
+	assert.Equal(t, atomic.LoadInt32(&dialed), atomic.LoadInt32(&closed))
 }
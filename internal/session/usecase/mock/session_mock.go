@@ -5,6 +5,7 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,46 +36,107 @@ func (m *MockSessionUseCase) EXPECT() *MockSessionUseCaseMockRecorder {
 }
 
 // CreateSession mocks base method.
-func (m *MockSessionUseCase) CreateSession(userID uint64) (string, error) {
+func (m *MockSessionUseCase) CreateSession(ctx context.Context, userID uint64, ip, userAgent string) (*entity.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSession", userID)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "CreateSession", ctx, userID, ip, userAgent)
+	ret0, _ := ret[0].(*entity.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateSession indicates an expected call of CreateSession.
-func (mr *MockSessionUseCaseMockRecorder) CreateSession(userID interface{}) *gomock.Call {
+func (mr *MockSessionUseCaseMockRecorder) CreateSession(ctx, userID, ip, userAgent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionUseCase)(nil).CreateSession), ctx, userID, ip, userAgent)
+}
+
+// DeleteOtherUserSessions mocks base method.
+func (m *MockSessionUseCase) DeleteOtherUserSessions(ctx context.Context, userID uint64, currentSessionID string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOtherUserSessions", ctx, userID, currentSessionID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOtherUserSessions indicates an expected call of DeleteOtherUserSessions.
+func (mr *MockSessionUseCaseMockRecorder) DeleteOtherUserSessions(ctx, userID, currentSessionID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionUseCase)(nil).CreateSession), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOtherUserSessions", reflect.TypeOf((*MockSessionUseCase)(nil).DeleteOtherUserSessions), ctx, userID, currentSessionID)
 }
 
 // DeleteSession mocks base method.
-func (m *MockSessionUseCase) DeleteSession(sessionID string) (bool, error) {
+func (m *MockSessionUseCase) DeleteSession(ctx context.Context, sessionID string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteSession", sessionID)
+	ret := m.ctrl.Call(m, "DeleteSession", ctx, sessionID)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DeleteSession indicates an expected call of DeleteSession.
-func (mr *MockSessionUseCaseMockRecorder) DeleteSession(sessionID interface{}) *gomock.Call {
+func (mr *MockSessionUseCaseMockRecorder) DeleteSession(ctx, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockSessionUseCase)(nil).DeleteSession), ctx, sessionID)
+}
+
+// DeleteUserSession mocks base method.
+func (m *MockSessionUseCase) DeleteUserSession(ctx context.Context, userID uint64, sessionID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUserSession indicates an expected call of DeleteUserSession.
+func (mr *MockSessionUseCaseMockRecorder) DeleteUserSession(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSession", reflect.TypeOf((*MockSessionUseCase)(nil).DeleteUserSession), ctx, userID, sessionID)
+}
+
+// DeleteUserSessions mocks base method.
+func (m *MockSessionUseCase) DeleteUserSessions(ctx context.Context, userID uint64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserSessions", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUserSessions indicates an expected call of DeleteUserSessions.
+func (mr *MockSessionUseCaseMockRecorder) DeleteUserSessions(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockSessionUseCase)(nil).DeleteSession), sessionID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSessions", reflect.TypeOf((*MockSessionUseCase)(nil).DeleteUserSessions), ctx, userID)
 }
 
 // GetSession mocks base method.
-func (m *MockSessionUseCase) GetSession(sessionID string) (*entity.Session, error) {
+func (m *MockSessionUseCase) GetSession(ctx context.Context, sessionID string) (*entity.Session, bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", sessionID)
+	ret := m.ctrl.Call(m, "GetSession", ctx, sessionID)
 	ret0, _ := ret[0].(*entity.Session)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockSessionUseCaseMockRecorder) GetSession(ctx, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockSessionUseCase)(nil).GetSession), ctx, sessionID)
+}
+
+// GetUserSessions mocks base method.
+func (m *MockSessionUseCase) GetUserSessions(ctx context.Context, userID uint64) ([]entity.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSessions", ctx, userID)
+	ret0, _ := ret[0].([]entity.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockSessionUseCaseMockRecorder) GetSession(sessionID interface{}) *gomock.Call {
+// GetUserSessions indicates an expected call of GetUserSessions.
+func (mr *MockSessionUseCaseMockRecorder) GetUserSessions(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockSessionUseCase)(nil).GetSession), sessionID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSessions", reflect.TypeOf((*MockSessionUseCase)(nil).GetUserSessions), ctx, userID)
 }
@@ -1,49 +1,94 @@
 package usecase
 
 import (
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/ilyushkaaa/Filmoteka/internal/session/entity"
 	"github.com/ilyushkaaa/Filmoteka/internal/session/repo"
 )
+
+const (
+	DefaultIdleTimeout      = 24 * time.Hour
+	DefaultAbsoluteLifetime = 7 * 24 * time.Hour
+)
+
+const refreshInterval = time.Minute
+
 //go:generate mockgen -source=session.go -destination=session_mock.go -package=usecase SessionUseCase
 type SessionUseCase interface {
-	CreateSession(userID uint64) (string, error)
-	GetSession(sessionID string) (*entity.Session, error)
-	DeleteSession(sessionID string) (bool, error)
+	CreateSession(ctx context.Context, userID uint64, ip, userAgent string) (*entity.Session, error)
+	GetSession(ctx context.Context, sessionID string) (*entity.Session, bool, error)
+	GetUserSessions(ctx context.Context, userID uint64) ([]entity.Session, error)
+	DeleteSession(ctx context.Context, sessionID string) (bool, error)
+	DeleteUserSession(ctx context.Context, userID uint64, sessionID string) (bool, error)
+	DeleteUserSessions(ctx context.Context, userID uint64) (int, error)
+	DeleteOtherUserSessions(ctx context.Context, userID uint64, currentSessionID string) (int, error)
 }
 
 type SessionUseCaseApp struct {
-	sessionRepo repo.SessionRepo
+	sessionRepo      repo.SessionRepo
+	idleTimeout      time.Duration
+	absoluteLifetime time.Duration
 }
 
-func NewSessionUseCase(sessionRepo repo.SessionRepo) *SessionUseCaseApp {
+func NewSessionUseCase(sessionRepo repo.SessionRepo, idleTimeout, absoluteLifetime time.Duration) *SessionUseCaseApp {
 	return &SessionUseCaseApp{
-		sessionRepo: sessionRepo,
+		sessionRepo:      sessionRepo,
+		idleTimeout:      idleTimeout,
+		absoluteLifetime: absoluteLifetime,
 	}
 }
 
-func (su *SessionUseCaseApp) CreateSession(userID uint64) (string, error) {
+func (su *SessionUseCaseApp) CreateSession(ctx context.Context, userID uint64, ip, userAgent string) (*entity.Session, error) {
+	now := time.Now()
+	maxExpiresAt := now.Add(su.absoluteLifetime)
 	newSession := &entity.Session{
-		ID:     uuid.New().String(),
-		UserID: userID,
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		CreatedAt:    now,
+		LastSeenAt:   now,
+		ExpiresAt:    su.getExpiresAt(now, maxExpiresAt),
+		MaxExpiresAt: maxExpiresAt,
+		IP:           ip,
+		UserAgent:    userAgent,
+	}
+	err := su.sessionRepo.CreateSession(ctx, newSession)
+	if err != nil {
+		return nil, err
 	}
-	err := su.sessionRepo.CreateSession(newSession)
-	return newSession.ID, err
+	return newSession, nil
 }
 
-func (su *SessionUseCaseApp) GetSession(sessionID string) (*entity.Session, error) {
-	session, err := su.sessionRepo.GetSession(sessionID)
+func (su *SessionUseCaseApp) GetSession(ctx context.Context, sessionID string) (*entity.Session, bool, error) {
+	session, err := su.sessionRepo.GetSession(ctx, sessionID)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+	now := time.Now()
+	if session == nil || !now.Before(session.ExpiresAt) {
+		return nil, false, ErrNoSession
 	}
-	if session == nil {
-		return nil, ErrNoSession
+	if now.Sub(session.LastSeenAt) < refreshInterval {
+		return session, false, nil
+	}
+	expiresAt := su.getExpiresAt(now, session.MaxExpiresAt)
+	err = su.sessionRepo.TouchSession(ctx, sessionID, now, expiresAt)
+	if err != nil {
+		return nil, false, err
 	}
-	return session, nil
+	session.LastSeenAt = now
+	session.ExpiresAt = expiresAt
+	return session, true, nil
 }
 
-func (su *SessionUseCaseApp) DeleteSession(sessionID string) (bool, error) {
-	isDeleted, err := su.sessionRepo.DeleteSession(sessionID)
+func (su *SessionUseCaseApp) GetUserSessions(ctx context.Context, userID uint64) ([]entity.Session, error) {
+	return su.sessionRepo.GetUserSessions(ctx, userID)
+}
+
+func (su *SessionUseCaseApp) DeleteSession(ctx context.Context, sessionID string) (bool, error) {
+	isDeleted, err := su.sessionRepo.DeleteSession(ctx, sessionID)
 	if err != nil {
 		return false, err
 	}
@@ -52,3 +97,47 @@ func (su *SessionUseCaseApp) DeleteSession(sessionID string) (bool, error) {
 	}
 	return true, nil
 }
+
+func (su *SessionUseCaseApp) DeleteUserSession(ctx context.Context, userID uint64, sessionID string) (bool, error) {
+	session, err := su.sessionRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	if session == nil || session.UserID != userID {
+		return false, ErrNoSession
+	}
+	return su.DeleteSession(ctx, sessionID)
+}
+
+func (su *SessionUseCaseApp) DeleteUserSessions(ctx context.Context, userID uint64) (int, error) {
+	return su.sessionRepo.DeleteUserSessions(ctx, userID)
+}
+
+func (su *SessionUseCaseApp) DeleteOtherUserSessions(ctx context.Context, userID uint64, currentSessionID string) (int, error) {
+	sessions, err := su.sessionRepo.GetUserSessions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	deletedCount := 0
+	for _, session := range sessions {
+		if session.ID == currentSessionID {
+			continue
+		}
+		isDeleted, err := su.sessionRepo.DeleteSession(ctx, session.ID)
+		if err != nil {
+			return deletedCount, err
+		}
+		if isDeleted {
+			deletedCount++
+		}
+	}
+	return deletedCount, nil
+}
+
+func (su *SessionUseCaseApp) getExpiresAt(now, maxExpiresAt time.Time) time.Time {
+	expiresAt := now.Add(su.idleTimeout)
+	if expiresAt.After(maxExpiresAt) {
+		return maxExpiresAt
+	}
+	return expiresAt
+}
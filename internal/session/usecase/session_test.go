@@ -1,8 +1,10 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/ilyushkaaa/Filmoteka/internal/session/entity"
@@ -15,28 +17,120 @@ func TestGetSession(t *testing.T) {
 	defer ctrl.Finish()
 
 	testRepo := mock.NewMockSessionRepo(ctrl)
-	testUseCase := NewSessionUseCase(testRepo)
+	testUseCase := NewSessionUseCase(testRepo, time.Hour, 24*time.Hour)
 
 	var sessionExpected *entity.Session
-	testRepo.EXPECT().GetSession("qqqq").
+	testRepo.EXPECT().GetSession(gomock.Any(), "qqqq").
 		Return(nil, fmt.Errorf("error"))
-	session, err := testUseCase.GetSession("qqqq")
+	session, _, err := testUseCase.GetSession(context.Background(), "qqqq")
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, sessionExpected, session)
 
-	testRepo.EXPECT().GetSession("qqqq").
+	testRepo.EXPECT().GetSession(gomock.Any(), "qqqq").
 		Return(nil, nil)
-	session, err = testUseCase.GetSession("qqqq")
+	session, _, err = testUseCase.GetSession(context.Background(), "qqqq")
 	assert.Equal(t, ErrNoSession, err)
 	assert.Equal(t, sessionExpected, session)
 
-	sessionResult := &entity.Session{}
-	testRepo.EXPECT().GetSession("qqqq").
+	now := time.Now()
+	testRepo.EXPECT().GetSession(gomock.Any(), "qqqq").
+		Return(&entity.Session{LastSeenAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)}, nil)
+	session, _, err = testUseCase.GetSession(context.Background(), "qqqq")
+	assert.Equal(t, ErrNoSession, err)
+	assert.Equal(t, sessionExpected, session)
+
+	sessionResult := &entity.Session{LastSeenAt: now, ExpiresAt: now.Add(time.Hour)}
+	testRepo.EXPECT().GetSession(gomock.Any(), "qqqq").
 		Return(sessionResult, nil)
-	session, err = testUseCase.GetSession("qqqq")
+	session, isRefreshed, err := testUseCase.GetSession(context.Background(), "qqqq")
 	assert.Equal(t, nil, err)
 	assert.Equal(t, sessionResult, session)
+	assert.False(t, isRefreshed)
+
+	newStaleSession := func() *entity.Session {
+		return &entity.Session{
+			LastSeenAt:   now.Add(-10 * time.Minute),
+			ExpiresAt:    now.Add(50 * time.Minute),
+			MaxExpiresAt: now.Add(20 * time.Hour),
+		}
+	}
+	testRepo.EXPECT().GetSession(gomock.Any(), "qqqq").
+		Return(newStaleSession(), nil)
+	testRepo.EXPECT().TouchSession(gomock.Any(), "qqqq", gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
+	session, _, err = testUseCase.GetSession(context.Background(), "qqqq")
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, sessionExpected, session)
+
+	testRepo.EXPECT().GetSession(gomock.Any(), "qqqq").
+		Return(newStaleSession(), nil)
+	testRepo.EXPECT().TouchSession(gomock.Any(), "qqqq", gomock.Any(), gomock.Any()).Return(nil)
+	session, isRefreshed, err = testUseCase.GetSession(context.Background(), "qqqq")
+	assert.Equal(t, nil, err)
+	assert.True(t, isRefreshed)
+	assert.WithinDuration(t, time.Now(), session.LastSeenAt, time.Second)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), session.ExpiresAt, time.Second)
+
+	// продление не выходит за абсолютное время жизни сессии
+	closeToMaxSession := newStaleSession()
+	closeToMaxSession.MaxExpiresAt = now.Add(10 * time.Minute)
+	testRepo.EXPECT().GetSession(gomock.Any(), "qqqq").
+		Return(closeToMaxSession, nil)
+	testRepo.EXPECT().TouchSession(gomock.Any(), "qqqq", gomock.Any(), closeToMaxSession.MaxExpiresAt).Return(nil)
+	session, isRefreshed, err = testUseCase.GetSession(context.Background(), "qqqq")
+	assert.Equal(t, nil, err)
+	assert.True(t, isRefreshed)
+	assert.Equal(t, closeToMaxSession.MaxExpiresAt, session.ExpiresAt)
+}
+
+func TestCreateSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockSessionRepo(ctrl)
+	testUseCase := NewSessionUseCase(testRepo, time.Hour, 24*time.Hour)
+
+	testRepo.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
+	_, err := testUseCase.CreateSession(context.Background(), 1, "192.0.2.1", "curl/8.0")
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().CreateSession(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, session *entity.Session) error {
+		assert.Equal(t, uint64(1), session.UserID)
+		assert.Equal(t, "192.0.2.1", session.IP)
+		assert.Equal(t, "curl/8.0", session.UserAgent)
+		assert.Equal(t, session.CreatedAt, session.LastSeenAt)
+		assert.Equal(t, session.CreatedAt.Add(time.Hour), session.ExpiresAt)
+		assert.Equal(t, session.CreatedAt.Add(24*time.Hour), session.MaxExpiresAt)
+		return nil
+	})
+	session, err := testUseCase.CreateSession(context.Background(), 1, "192.0.2.1", "curl/8.0")
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, "", session.ID)
+}
+
+func TestDeleteUserSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockSessionRepo(ctrl)
+	testUseCase := NewSessionUseCase(testRepo, time.Hour, 24*time.Hour)
+
+	testRepo.EXPECT().GetSession(gomock.Any(), "qqqq").Return(nil, fmt.Errorf("error"))
+	_, err := testUseCase.DeleteUserSession(context.Background(), 1, "qqqq")
+	assert.NotEqual(t, nil, err)
 
+	testRepo.EXPECT().GetSession(gomock.Any(), "qqqq").Return(nil, nil)
+	_, err = testUseCase.DeleteUserSession(context.Background(), 1, "qqqq")
+	assert.Equal(t, ErrNoSession, err)
+
+	testRepo.EXPECT().GetSession(gomock.Any(), "qqqq").Return(&entity.Session{ID: "qqqq", UserID: 2}, nil)
+	_, err = testUseCase.DeleteUserSession(context.Background(), 1, "qqqq")
+	assert.Equal(t, ErrNoSession, err)
+
+	testRepo.EXPECT().GetSession(gomock.Any(), "qqqq").Return(&entity.Session{ID: "qqqq", UserID: 1}, nil)
+	testRepo.EXPECT().DeleteSession(gomock.Any(), "qqqq").Return(true, nil)
+	wasDeleted, err := testUseCase.DeleteUserSession(context.Background(), 1, "qqqq")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, wasDeleted)
 }
 
 func TestDeleteSession(t *testing.T) {
@@ -44,23 +138,65 @@ func TestDeleteSession(t *testing.T) {
 	defer ctrl.Finish()
 
 	testRepo := mock.NewMockSessionRepo(ctrl)
-	testUseCase := NewSessionUseCase(testRepo)
+	testUseCase := NewSessionUseCase(testRepo, time.Hour, 24*time.Hour)
 
-	testRepo.EXPECT().DeleteSession("qqqq").
+	testRepo.EXPECT().DeleteSession(gomock.Any(), "qqqq").
 		Return(false, fmt.Errorf("error"))
-	wasDeleted, err := testUseCase.DeleteSession("qqqq")
+	wasDeleted, err := testUseCase.DeleteSession(context.Background(), "qqqq")
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, false, wasDeleted)
 
-	testRepo.EXPECT().DeleteSession("qqqq").
+	testRepo.EXPECT().DeleteSession(gomock.Any(), "qqqq").
 		Return(false, nil)
-	wasDeleted, err = testUseCase.DeleteSession("qqqq")
+	wasDeleted, err = testUseCase.DeleteSession(context.Background(), "qqqq")
 	assert.Equal(t, ErrNoSession, err)
 	assert.Equal(t, false, wasDeleted)
 
-	testRepo.EXPECT().DeleteSession("qqqq").
+	testRepo.EXPECT().DeleteSession(gomock.Any(), "qqqq").
 		Return(true, nil)
-	wasDeleted, err = testUseCase.DeleteSession("qqqq")
+	wasDeleted, err = testUseCase.DeleteSession(context.Background(), "qqqq")
 	assert.Equal(t, nil, err)
 	assert.Equal(t, true, wasDeleted)
 }
+
+func TestDeleteUserSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockSessionRepo(ctrl)
+	testUseCase := NewSessionUseCase(testRepo, time.Hour, 24*time.Hour)
+
+	testRepo.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(0, fmt.Errorf("error"))
+	_, err := testUseCase.DeleteUserSessions(context.Background(), 1)
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(2, nil)
+	deletedCount, err := testUseCase.DeleteUserSessions(context.Background(), 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, deletedCount)
+}
+
+func TestDeleteOtherUserSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockSessionRepo(ctrl)
+	testUseCase := NewSessionUseCase(testRepo, time.Hour, 24*time.Hour)
+	sessions := []entity.Session{{ID: "current", UserID: 1}, {ID: "other1", UserID: 1}, {ID: "other2", UserID: 1}}
+
+	testRepo.EXPECT().GetUserSessions(gomock.Any(), uint64(1)).Return(nil, fmt.Errorf("error"))
+	_, err := testUseCase.DeleteOtherUserSessions(context.Background(), 1, "current")
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().GetUserSessions(gomock.Any(), uint64(1)).Return(sessions, nil)
+	testRepo.EXPECT().DeleteSession(gomock.Any(), "other1").Return(false, fmt.Errorf("error"))
+	_, err = testUseCase.DeleteOtherUserSessions(context.Background(), 1, "current")
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().GetUserSessions(gomock.Any(), uint64(1)).Return(sessions, nil)
+	testRepo.EXPECT().DeleteSession(gomock.Any(), "other1").Return(true, nil)
+	testRepo.EXPECT().DeleteSession(gomock.Any(), "other2").Return(false, nil)
+	deletedCount, err := testUseCase.DeleteOtherUserSessions(context.Background(), 1, "current")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, deletedCount)
+}
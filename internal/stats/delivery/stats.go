@@ -0,0 +1,123 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/stats/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+)
+
+const periodDateLayout = "2006-01-02"
+
+var (
+	minPeriodDate = time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	maxPeriodDate = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+)
+
+type StatsHandler struct {
+	statsUseCase usecase.StatsUseCase
+}
+
+func NewStatsHandler(statsUseCase usecase.StatsUseCase) *StatsHandler {
+	return &StatsHandler{
+		statsUseCase: statsUseCase,
+	}
+}
+
+// GetCatalogStats @Summary Получить статистику каталога
+// @Description Получить количество фильмов, актеров и пользователей, распределение фильмов по годам и рейтингу, топ актеров по количеству фильмов, фильмы без актеров и актеров без фильмов. Период ограничивает фильмы по дате выхода, актеров - по участию в этих фильмах, пользователей - по дате регистрации; обе границы включаются
+// @Tags admin
+// @Accept json
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param from query string false "Начало периода в формате YYYY-MM-DD"
+// @Param to query string false "Последний день периода в формате YYYY-MM-DD"
+// @Success 200 {object} dto.CatalogStats
+// @Failure 400 {object} string "Период передан в неверном формате"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/stats [get]
+func (h *StatsHandler) GetCatalogStats(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	period, err := getPeriod(r)
+	if err != nil {
+		zapLogger.Errorf("error in period conversion: %s", err)
+		errText := fmt.Sprintf(`{"error": "bad format of period: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	stats, err := h.statsUseCase.GetCatalogStats(r.Context(), period)
+	if errors.Is(err, usecase.ErrBadPeriod) {
+		zapLogger.Errorf("bad period passed: %s - %s", period.From, period.To)
+		errText := fmt.Sprintf(`{"error": "%s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		zapLogger.Errorf("error in getting catalog stats: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		zapLogger.Errorf("error marshalling response: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, statsJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+func getPeriod(r *http.Request) (dto.StatsPeriod, error) {
+	period := dto.StatsPeriod{
+		From: minPeriodDate,
+		To:   maxPeriodDate,
+	}
+	query := r.URL.Query()
+	var err error
+	if from := query.Get("from"); from != "" {
+		period.From, err = time.Parse(periodDateLayout, from)
+		if err != nil {
+			return period, err
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		period.To, err = time.Parse(periodDateLayout, to)
+		if err != nil {
+			return period, err
+		}
+	}
+	return period, nil
+}
@@ -0,0 +1,64 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/stats/usecase"
+	"github.com/ilyushkaaa/Filmoteka/internal/stats/usecase/mock"
+	logger2 "github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestGetCatalogStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockStatsUseCase(ctrl)
+	testHandler := NewStatsHandler(testUseCase)
+
+	request := httptest.NewRequest(http.MethodGet, "/admin/stats", nil)
+	respWriter := httptest.NewRecorder()
+	testHandler.GetCatalogStats(respWriter, request)
+	assert.Equal(t, http.StatusInternalServerError, respWriter.Code)
+
+	request = httptest.NewRequest(http.MethodGet, "/admin/stats?from=bad", nil)
+	ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.GetCatalogStats(respWriter, request.WithContext(ctx))
+	assert.Equal(t, http.StatusBadRequest, respWriter.Code)
+
+	period := dto.StatsPeriod{
+		From: time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	testUseCase.EXPECT().GetCatalogStats(gomock.Any(), period).Return(nil, usecase.ErrBadPeriod)
+	request = httptest.NewRequest(http.MethodGet, "/admin/stats?from=2010-01-01&to=2000-01-01", nil)
+	ctx = context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.GetCatalogStats(respWriter, request.WithContext(ctx))
+	assert.Equal(t, http.StatusBadRequest, respWriter.Code)
+
+	fullPeriod := dto.StatsPeriod{From: minPeriodDate, To: maxPeriodDate}
+	testUseCase.EXPECT().GetCatalogStats(gomock.Any(), fullPeriod).Return(nil, fmt.Errorf("error"))
+	request = httptest.NewRequest(http.MethodGet, "/admin/stats", nil)
+	ctx = context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.GetCatalogStats(respWriter, request.WithContext(ctx))
+	assert.Equal(t, http.StatusInternalServerError, respWriter.Code)
+
+	testUseCase.EXPECT().GetCatalogStats(gomock.Any(), fullPeriod).Return(&dto.CatalogStats{}, nil)
+	request = httptest.NewRequest(http.MethodGet, "/admin/stats", nil)
+	ctx = context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.GetCatalogStats(respWriter, request.WithContext(ctx))
+	assert.Equal(t, http.StatusOK, respWriter.Code)
+}
@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+)
+
+//go:generate mockgen -source=cache.go -destination=cache_mock.go -package=repo StatsCache
+type StatsCache interface {
+	GetStats(ctx context.Context, key string) (*dto.CatalogStats, error)
+	SetStats(ctx context.Context, key string, stats *dto.CatalogStats, ttl time.Duration) error
+}
+
+type StatsCacheRedis struct {
+	redisPool *redis.Pool
+}
+
+func NewStatsCache(redisPool *redis.Pool) *StatsCacheRedis {
+	return &StatsCacheRedis{
+		redisPool: redisPool,
+	}
+}
+
+func (c *StatsCacheRedis) GetStats(ctx context.Context, key string) (*dto.CatalogStats, error) {
+	conn, err := c.redisPool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	statsJSON, err := redis.Bytes(redis.DoContext(conn, ctx, "GET", key))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	stats := &dto.CatalogStats{}
+	err = json.Unmarshal(statsJSON, stats)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (c *StatsCacheRedis) SetStats(ctx context.Context, key string, stats *dto.CatalogStats, ttl time.Duration) error {
+	conn, err := c.redisPool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	// PX, а не EX: TTL меньше секунды не должен округляться до нуля
+	_, err = redis.DoContext(conn, ctx, "SET", key, statsJSON, "PX", ttl.Milliseconds())
+	return err
+}
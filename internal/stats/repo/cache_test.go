@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockRedisConn struct {
+	data    map[string][]byte
+	options map[string][]interface{}
+}
+
+func (m *MockRedisConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	// пул отправляет пустую команду, когда соединение возвращается в него
+	if commandName == "" {
+		return nil, nil
+	}
+	key := args[0].(string)
+	if key == "broken" {
+		return nil, fmt.Errorf("err")
+	}
+	switch commandName {
+	case "GET":
+		value, ok := m.data[key]
+		if !ok {
+			return nil, nil
+		}
+		return value, nil
+	case "SET":
+		m.data[key] = args[1].([]byte)
+		m.options[key] = args[2:]
+		return "OK", nil
+	}
+	return nil, fmt.Errorf("err")
+}
+
+func (m *MockRedisConn) DoContext(_ context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	return m.Do(commandName, args...)
+}
+
+func (m *MockRedisConn) Close() error {
+	return nil
+}
+
+func (m *MockRedisConn) Err() error {
+	return nil
+}
+
+func (m *MockRedisConn) Send(_ string, _ ...interface{}) error {
+	return nil
+}
+
+func (m *MockRedisConn) Flush() error {
+	return nil
+}
+
+func (m *MockRedisConn) Receive() (interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRedisConn) ReceiveContext(_ context.Context) (interface{}, error) {
+	return nil, nil
+}
+
+var _ redis.ConnWithContext = &MockRedisConn{}
+
+func newMockRedisPool(redisConn redis.Conn) *redis.Pool {
+	return &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redisConn, nil
+		},
+	}
+}
+
+func TestStatsCache(t *testing.T) {
+	redisConn := &MockRedisConn{data: make(map[string][]byte), options: make(map[string][]interface{})}
+	cache := NewStatsCache(newMockRedisPool(redisConn))
+
+	stats, err := cache.GetStats(context.Background(), "missing")
+	assert.NoError(t, err)
+	assert.Equal(t, (*dto.CatalogStats)(nil), stats)
+
+	_, err = cache.GetStats(context.Background(), "broken")
+	assert.Error(t, err)
+
+	err = cache.SetStats(context.Background(), "broken", &dto.CatalogStats{}, time.Minute)
+	assert.Error(t, err)
+
+	statsToCache := &dto.CatalogStats{FilmsCount: 3, FilmsPerYear: map[int]int{2001: 3}}
+	err = cache.SetStats(context.Background(), "key", statsToCache, time.Minute)
+	assert.NoError(t, err)
+	stats, err = cache.GetStats(context.Background(), "key")
+	assert.NoError(t, err)
+	assert.Equal(t, statsToCache, stats)
+	assert.Equal(t, []interface{}{"PX", int64(60000)}, redisConn.options["key"])
+
+	err = cache.SetStats(context.Background(), "short", statsToCache, 500*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"PX", int64(500)}, redisConn.options["short"])
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cache.go
+
+// Package repo is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	dto "github.com/ilyushkaaa/Filmoteka/internal/dto"
+)
+
+// MockStatsCache is a mock of StatsCache interface.
+type MockStatsCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsCacheMockRecorder
+}
+
+// MockStatsCacheMockRecorder is the mock recorder for MockStatsCache.
+type MockStatsCacheMockRecorder struct {
+	mock *MockStatsCache
+}
+
+// NewMockStatsCache creates a new mock instance.
+func NewMockStatsCache(ctrl *gomock.Controller) *MockStatsCache {
+	mock := &MockStatsCache{ctrl: ctrl}
+	mock.recorder = &MockStatsCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsCache) EXPECT() *MockStatsCacheMockRecorder {
+	return m.recorder
+}
+
+// GetStats mocks base method.
+func (m *MockStatsCache) GetStats(ctx context.Context, key string) (*dto.CatalogStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", ctx, key)
+	ret0, _ := ret[0].(*dto.CatalogStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockStatsCacheMockRecorder) GetStats(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockStatsCache)(nil).GetStats), ctx, key)
+}
+
+// SetStats mocks base method.
+func (m *MockStatsCache) SetStats(ctx context.Context, key string, stats *dto.CatalogStats, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStats", ctx, key, stats, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStats indicates an expected call of SetStats.
+func (mr *MockStatsCacheMockRecorder) SetStats(ctx, key, stats, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStats", reflect.TypeOf((*MockStatsCache)(nil).SetStats), ctx, key, stats, ttl)
+}
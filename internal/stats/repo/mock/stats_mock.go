@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: stats.go
+
+// Package repo is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	dto "github.com/ilyushkaaa/Filmoteka/internal/dto"
+)
+
+// MockStatsRepo is a mock of StatsRepo interface.
+type MockStatsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsRepoMockRecorder
+}
+
+// MockStatsRepoMockRecorder is the mock recorder for MockStatsRepo.
+type MockStatsRepoMockRecorder struct {
+	mock *MockStatsRepo
+}
+
+// NewMockStatsRepo creates a new mock instance.
+func NewMockStatsRepo(ctrl *gomock.Controller) *MockStatsRepo {
+	mock := &MockStatsRepo{ctrl: ctrl}
+	mock.recorder = &MockStatsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsRepo) EXPECT() *MockStatsRepoMockRecorder {
+	return m.recorder
+}
+
+// GetCatalogStats mocks base method.
+func (m *MockStatsRepo) GetCatalogStats(ctx context.Context, period dto.StatsPeriod) (*dto.CatalogStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCatalogStats", ctx, period)
+	ret0, _ := ret[0].(*dto.CatalogStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCatalogStats indicates an expected call of GetCatalogStats.
+func (mr *MockStatsRepoMockRecorder) GetCatalogStats(ctx, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCatalogStats", reflect.TypeOf((*MockStatsRepo)(nil).GetCatalogStats), ctx, period)
+}
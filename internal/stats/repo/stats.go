@@ -0,0 +1,199 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	entityActor "github.com/ilyushkaaa/Filmoteka/internal/actors/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	entityFilm "github.com/ilyushkaaa/Filmoteka/internal/films/entity"
+	"go.uber.org/zap"
+)
+
+const topActorsLimit = 10
+
+//go:generate mockgen -source=stats.go -destination=stats_mock.go -package=repo StatsRepo
+type StatsRepo interface {
+	GetCatalogStats(ctx context.Context, period dto.StatsPeriod) (*dto.CatalogStats, error)
+}
+
+type StatsRepoPG struct {
+	db        *sql.DB
+	zapLogger *zap.SugaredLogger
+}
+
+func NewStatsRepo(db *sql.DB, zapLogger *zap.SugaredLogger) *StatsRepoPG {
+	return &StatsRepoPG{
+		db:        db,
+		zapLogger: zapLogger,
+	}
+}
+
+func (r *StatsRepoPG) GetCatalogStats(ctx context.Context, period dto.StatsPeriod) (*dto.CatalogStats, error) {
+	stats := &dto.CatalogStats{}
+	err := r.db.QueryRowContext(ctx, `
+        SELECT (SELECT COUNT(*) FROM films WHERE date_of_release >= $1 AND date_of_release < $2::TIMESTAMP + INTERVAL '1 day'),
+               (SELECT COUNT(DISTINCT fa.actor_id)
+                FROM film_actors fa
+                JOIN films f ON fa.film_id = f.id
+                WHERE f.date_of_release >= $1 AND f.date_of_release < $2::TIMESTAMP + INTERVAL '1 day'),
+               (SELECT COUNT(*) FROM users WHERE created_at >= $1 AND created_at < $2::TIMESTAMP + INTERVAL '1 day')
+    `, period.From, period.To).
+		Scan(&stats.FilmsCount, &stats.ActorsCount, &stats.UsersCount)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.FilmsPerYear, err = r.getHistogram(ctx, `
+        SELECT DATE_PART('year', date_of_release)::INT, COUNT(*)
+        FROM films
+        WHERE date_of_release >= $1 AND date_of_release < $2::TIMESTAMP + INTERVAL '1 day'
+        GROUP BY 1
+    `, period)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.RatingDistribution, err = r.getHistogram(ctx, `
+        SELECT FLOOR(rating)::INT, COUNT(*)
+        FROM films
+        WHERE date_of_release >= $1 AND date_of_release < $2::TIMESTAMP + INTERVAL '1 day'
+        GROUP BY 1
+    `, period)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.TopActors, err = r.getTopActors(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.FilmsWithoutCast, err = r.getFilmsWithoutCast(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.ActorsWithoutFilms, err = r.getActorsWithoutFilms(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *StatsRepoPG) getHistogram(ctx context.Context, query string, period dto.StatsPeriod) (map[int]int, error) {
+	rows, err := r.db.QueryContext(ctx, query, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			r.zapLogger.Errorf("error in closing query rows: %s", err)
+		}
+	}(rows)
+	histogram := make(map[int]int)
+	for rows.Next() {
+		var bucket, count int
+		err = rows.Scan(&bucket, &count)
+		if err != nil {
+			return nil, err
+		}
+		histogram[bucket] = count
+	}
+	return histogram, rows.Err()
+}
+
+func (r *StatsRepoPG) getTopActors(ctx context.Context, period dto.StatsPeriod) ([]dto.ActorFilmsCount, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT a.id, a.name, a.surname, COUNT(f.id)
+        FROM actors a
+        JOIN film_actors fa ON a.id = fa.actor_id
+        JOIN films f ON fa.film_id = f.id
+        WHERE f.date_of_release >= $1 AND date_of_release < $2::TIMESTAMP + INTERVAL '1 day'
+        GROUP BY a.id
+        ORDER BY COUNT(f.id) DESC, a.id
+        LIMIT $3
+    `, period.From, period.To, topActorsLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			r.zapLogger.Errorf("error in closing query rows: %s", err)
+		}
+	}(rows)
+	topActors := make([]dto.ActorFilmsCount, 0)
+	for rows.Next() {
+		var actor dto.ActorFilmsCount
+		err = rows.Scan(&actor.ActorID, &actor.Name, &actor.Surname, &actor.FilmsCount)
+		if err != nil {
+			return nil, err
+		}
+		topActors = append(topActors, actor)
+	}
+	return topActors, rows.Err()
+}
+
+func (r *StatsRepoPG) getFilmsWithoutCast(ctx context.Context, period dto.StatsPeriod) ([]entityFilm.Film, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT f.id, f.name, f.description, f.date_of_release, f.rating
+        FROM films f
+        WHERE f.date_of_release >= $1 AND date_of_release < $2::TIMESTAMP + INTERVAL '1 day'
+        AND NOT EXISTS (SELECT 1 FROM film_actors fa WHERE fa.film_id = f.id)
+        ORDER BY f.id
+    `, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			r.zapLogger.Errorf("error in closing query rows: %s", err)
+		}
+	}(rows)
+	films := make([]entityFilm.Film, 0)
+	for rows.Next() {
+		film := entityFilm.Film{}
+		err = rows.Scan(&film.ID, &film.Name, &film.Description, &film.DateOfRelease, &film.Rating)
+		if err != nil {
+			return nil, err
+		}
+		films = append(films, film)
+	}
+	return films, rows.Err()
+}
+
+func (r *StatsRepoPG) getActorsWithoutFilms(ctx context.Context, period dto.StatsPeriod) ([]entityActor.Actor, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT a.id, a.name, a.surname, a.gender, a.birthday, a.death_date
+        FROM actors a
+        WHERE NOT EXISTS (
+            SELECT 1
+            FROM film_actors fa
+            JOIN films f ON fa.film_id = f.id
+            WHERE fa.actor_id = a.id AND f.date_of_release >= $1 AND date_of_release < $2::TIMESTAMP + INTERVAL '1 day'
+        )
+        ORDER BY a.id
+    `, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err = rows.Close()
+		if err != nil {
+			r.zapLogger.Errorf("error in closing query rows: %s", err)
+		}
+	}(rows)
+	actors := make([]entityActor.Actor, 0)
+	for rows.Next() {
+		actor := entityActor.Actor{}
+		err = rows.Scan(&actor.ID, &actor.Name, &actor.Surname, &actor.Gender, &actor.Birthday, &actor.DeathDate)
+		if err != nil {
+			return nil, err
+		}
+		actors = append(actors, actor)
+	}
+	return actors, rows.Err()
+}
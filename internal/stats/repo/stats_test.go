@@ -0,0 +1,64 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestGetCatalogStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("can not create mock")
+	}
+	defer db.Close()
+	testRepo := NewStatsRepo(db, zap.NewNop().Sugar())
+
+	period := dto.StatsPeriod{
+		From: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	var nilStats *dto.CatalogStats
+
+	mock.ExpectQuery("SELECT (.+) FROM films (.+) FROM film_actors (.+) FROM users WHERE created_at").
+		WithArgs(period.From, period.To).WillReturnError(fmt.Errorf("error"))
+	stats, err := testRepo.GetCatalogStats(context.Background(), period)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, nilStats, stats)
+
+	mock.ExpectQuery("SELECT (.+) FROM films (.+) FROM film_actors (.+) FROM users WHERE created_at").
+		WithArgs(period.From, period.To).
+		WillReturnRows(sqlmock.NewRows([]string{"films", "actors", "users"}).AddRow(3, 2, 1))
+	mock.ExpectQuery("SELECT DATE_PART(.+) FROM films").
+		WithArgs(period.From, period.To).
+		WillReturnRows(sqlmock.NewRows([]string{"year", "count"}).AddRow(2001, 2).AddRow(2005, 1))
+	mock.ExpectQuery("SELECT FLOOR(.+) FROM films").
+		WithArgs(period.From, period.To).
+		WillReturnRows(sqlmock.NewRows([]string{"rating", "count"}).AddRow(7, 3))
+	mock.ExpectQuery("SELECT (.+) FROM actors a JOIN film_actors").
+		WithArgs(period.From, period.To, topActorsLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "surname", "count"}).AddRow(1, "John", "Doe", 3))
+	mock.ExpectQuery("SELECT (.+) FROM films f WHERE (.+) NOT EXISTS").
+		WithArgs(period.From, period.To).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "date_of_release", "rating"}))
+	mock.ExpectQuery("SELECT (.+) FROM actors a WHERE NOT EXISTS").
+		WithArgs(period.From, period.To).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "surname", "gender", "birthday", "death_date"}).
+			AddRow(2, "Jane", "Smith", "female", time.Time{}, nil))
+	stats, err = testRepo.GetCatalogStats(context.Background(), period)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, stats.FilmsCount)
+	assert.Equal(t, map[int]int{2001: 2, 2005: 1}, stats.FilmsPerYear)
+	assert.Equal(t, map[int]int{7: 3}, stats.RatingDistribution)
+	assert.Equal(t, 1, len(stats.TopActors))
+	assert.Equal(t, 0, len(stats.FilmsWithoutCast))
+	assert.Equal(t, 1, len(stats.ActorsWithoutFilms))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
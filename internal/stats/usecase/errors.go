@@ -0,0 +1,5 @@
+package usecase
+
+import "errors"
+
+var ErrBadPeriod = errors.New("period start is after period end")
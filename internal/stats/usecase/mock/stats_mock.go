@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: stats.go
+
+// Package usecase is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	dto "github.com/ilyushkaaa/Filmoteka/internal/dto"
+)
+
+// MockStatsUseCase is a mock of StatsUseCase interface.
+type MockStatsUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsUseCaseMockRecorder
+}
+
+// MockStatsUseCaseMockRecorder is the mock recorder for MockStatsUseCase.
+type MockStatsUseCaseMockRecorder struct {
+	mock *MockStatsUseCase
+}
+
+// NewMockStatsUseCase creates a new mock instance.
+func NewMockStatsUseCase(ctrl *gomock.Controller) *MockStatsUseCase {
+	mock := &MockStatsUseCase{ctrl: ctrl}
+	mock.recorder = &MockStatsUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsUseCase) EXPECT() *MockStatsUseCaseMockRecorder {
+	return m.recorder
+}
+
+// GetCatalogStats mocks base method.
+func (m *MockStatsUseCase) GetCatalogStats(ctx context.Context, period dto.StatsPeriod) (*dto.CatalogStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCatalogStats", ctx, period)
+	ret0, _ := ret[0].(*dto.CatalogStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCatalogStats indicates an expected call of GetCatalogStats.
+func (mr *MockStatsUseCaseMockRecorder) GetCatalogStats(ctx, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCatalogStats", reflect.TypeOf((*MockStatsUseCase)(nil).GetCatalogStats), ctx, period)
+}
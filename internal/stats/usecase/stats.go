@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/stats/repo"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+)
+
+const statsCacheKeyPrefix = "admin_stats"
+
+//go:generate mockgen -source=stats.go -destination=stats_mock.go -package=usecase StatsUseCase
+type StatsUseCase interface {
+	GetCatalogStats(ctx context.Context, period dto.StatsPeriod) (*dto.CatalogStats, error)
+}
+
+type StatsUseCaseApp struct {
+	statsRepo  repo.StatsRepo
+	statsCache repo.StatsCache
+	cacheTTL   time.Duration
+}
+
+func NewStatsUseCase(statsRepo repo.StatsRepo, statsCache repo.StatsCache, cacheTTL time.Duration) *StatsUseCaseApp {
+	return &StatsUseCaseApp{
+		statsRepo:  statsRepo,
+		statsCache: statsCache,
+		cacheTTL:   cacheTTL,
+	}
+}
+
+func (uc *StatsUseCaseApp) GetCatalogStats(ctx context.Context, period dto.StatsPeriod) (*dto.CatalogStats, error) {
+	if period.From.After(period.To) {
+		return nil, ErrBadPeriod
+	}
+	if uc.cacheTTL <= 0 {
+		return uc.statsRepo.GetCatalogStats(ctx, period)
+	}
+	cacheKey := fmt.Sprintf("%s:%d:%d", statsCacheKeyPrefix, period.From.Unix(), period.To.Unix())
+	// кэш не обязателен: при его недоступности статистика считается по базе
+	stats, err := uc.statsCache.GetStats(ctx, cacheKey)
+	if err != nil {
+		logger.ErrorfFromContext(ctx, "error in getting stats from cache: %s", err)
+	}
+	if stats != nil {
+		return stats, nil
+	}
+	stats, err = uc.statsRepo.GetCatalogStats(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+	err = uc.statsCache.SetStats(ctx, cacheKey, stats, uc.cacheTTL)
+	if err != nil {
+		logger.ErrorfFromContext(ctx, "error in saving stats to cache: %s", err)
+	}
+	return stats, nil
+}
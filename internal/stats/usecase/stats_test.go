@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/stats/repo/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCatalogStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockStatsRepo(ctrl)
+	testCache := mock.NewMockStatsCache(ctrl)
+	testUseCase := NewStatsUseCase(testRepo, testCache, time.Minute)
+
+	period := dto.StatsPeriod{
+		From: time.Unix(0, 0),
+		To:   time.Unix(100, 0),
+	}
+	cacheKey := "admin_stats:0:100"
+	var statsExpected *dto.CatalogStats
+
+	stats, err := testUseCase.GetCatalogStats(context.Background(), dto.StatsPeriod{From: period.To, To: period.From})
+	assert.Equal(t, ErrBadPeriod, err)
+	assert.Equal(t, statsExpected, stats)
+
+	// ошибки кэша не мешают получить статистику из базы
+	dbStats := &dto.CatalogStats{FilmsCount: 3}
+	testCache.EXPECT().GetStats(gomock.Any(), cacheKey).Return(nil, fmt.Errorf("error"))
+	testRepo.EXPECT().GetCatalogStats(gomock.Any(), period).Return(dbStats, nil)
+	testCache.EXPECT().SetStats(gomock.Any(), cacheKey, dbStats, time.Minute).Return(fmt.Errorf("error"))
+	stats, err = testUseCase.GetCatalogStats(context.Background(), period)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, dbStats, stats)
+
+	cachedStats := &dto.CatalogStats{FilmsCount: 1}
+	testCache.EXPECT().GetStats(gomock.Any(), cacheKey).Return(cachedStats, nil)
+	stats, err = testUseCase.GetCatalogStats(context.Background(), period)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, cachedStats, stats)
+
+	testCache.EXPECT().GetStats(gomock.Any(), cacheKey).Return(nil, nil)
+	testRepo.EXPECT().GetCatalogStats(gomock.Any(), period).Return(nil, fmt.Errorf("error"))
+	stats, err = testUseCase.GetCatalogStats(context.Background(), period)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, statsExpected, stats)
+
+	freshStats := &dto.CatalogStats{FilmsCount: 2}
+	testCache.EXPECT().GetStats(gomock.Any(), cacheKey).Return(nil, nil)
+	testRepo.EXPECT().GetCatalogStats(gomock.Any(), period).Return(freshStats, nil)
+	testCache.EXPECT().SetStats(gomock.Any(), cacheKey, freshStats, time.Minute).Return(nil)
+	stats, err = testUseCase.GetCatalogStats(context.Background(), period)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, freshStats, stats)
+
+	noCacheUseCase := NewStatsUseCase(testRepo, testCache, 0)
+	testRepo.EXPECT().GetCatalogStats(gomock.Any(), period).Return(freshStats, nil)
+	stats, err = noCacheUseCase.GetCatalogStats(context.Background(), period)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, freshStats, stats)
+}
@@ -0,0 +1,192 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/token/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+	"go.uber.org/zap"
+)
+
+type TokenHandler struct {
+	tokenUseCase usecase.TokenUseCase
+}
+
+func NewTokenHandler(tokenUseCase usecase.TokenUseCase) *TokenHandler {
+	return &TokenHandler{
+		tokenUseCase: tokenUseCase,
+	}
+}
+
+// Refresh @Summary Обновление токенов
+// @Description Обменять refresh токен на новую пару access и refresh токенов. Каждый refresh токен одноразовый: его повторное использование отзывает все токены, выданные при этом входе
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param body body dto.RefreshTokenRequest true "Refresh токен"
+// @Success 200 {object} dto.TokenResponse "Новая пара токенов"
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Refresh токен недействителен или уже был использован"
+// @Failure 422 {object} string "Данные не прошли валидацию"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/token/refresh [post]
+func (h *TokenHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	refreshRequest, err := getRefreshTokenRequest(zapLogger, w, r)
+	if err != nil {
+		return
+	}
+	tokens, err := h.tokenUseCase.RefreshTokens(r.Context(), refreshRequest.RefreshToken)
+	if errors.Is(err, usecase.ErrRefreshTokenReused) {
+		zapLogger.Errorf("reuse of refresh token was detected, token family was revoked")
+		errText := fmt.Sprintf(`{"error": "%s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusUnauthorized)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if errors.Is(err, usecase.ErrBadRefreshToken) {
+		zapLogger.Errorf("bad refresh token was passed")
+		errText := fmt.Sprintf(`{"error": "%s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusUnauthorized)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		zapLogger.Errorf("error in refreshing tokens: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	tokensJSON, err := json.Marshal(dto.NewTokenResponse(tokens))
+	if err != nil {
+		zapLogger.Errorf("error marshalling response: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, tokensJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+// Revoke @Summary Отзыв refresh токена
+// @Description Отозвать refresh токен и все токены, выданные при том же входе. Используется для выхода при аутентификации по токенам
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param body body dto.RefreshTokenRequest true "Refresh токен"
+// @Success 200 {object} string "Токен отозван"
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Refresh токен недействителен"
+// @Failure 422 {object} string "Данные не прошли валидацию"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/token/revoke [post]
+func (h *TokenHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	refreshRequest, err := getRefreshTokenRequest(zapLogger, w, r)
+	if err != nil {
+		return
+	}
+	err = h.tokenUseCase.RevokeRefreshToken(r.Context(), refreshRequest.RefreshToken)
+	if errors.Is(err, usecase.ErrBadRefreshToken) {
+		zapLogger.Errorf("bad refresh token was passed")
+		errText := fmt.Sprintf(`{"error": "%s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusUnauthorized)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		zapLogger.Errorf("error in revoking refresh token: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	result := `{"result": "success"}`
+	err = response.WriteResponse(w, []byte(result), http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+func getRefreshTokenRequest(zapLogger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request) (*dto.RefreshTokenRequest, error) {
+	rBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		zapLogger.Errorf("error in reading request body: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in reading request body: %s"}`, err)
+		writeErr := response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if writeErr != nil {
+			zapLogger.Errorf("error in writing response: %s", writeErr)
+		}
+		return nil, err
+	}
+	refreshRequest := &dto.RefreshTokenRequest{}
+	err = json.Unmarshal(rBody, refreshRequest)
+	if err != nil {
+		zapLogger.Errorf("error in unmarshalling refresh token request: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in decoding refresh token request: %s"}`, err)
+		writeErr := response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if writeErr != nil {
+			zapLogger.Errorf("error in writing response: %s", writeErr)
+		}
+		return nil, err
+	}
+	validationErrors := refreshRequest.Validate()
+	if len(validationErrors) != 0 {
+		zapLogger.Errorf("refresh token request did not pass validation: %v", validationErrors)
+		errorsJSON, err := json.Marshal(validationErrors)
+		if err != nil {
+			zapLogger.Errorf("error in marshalling validation errors: %s", err)
+			errText := `{"error": "internal server error"}`
+			writeErr := response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if writeErr != nil {
+				zapLogger.Errorf("error in writing response: %s", writeErr)
+			}
+			return nil, err
+		}
+		err = response.WriteResponse(w, errorsJSON, http.StatusUnprocessableEntity)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return nil, fmt.Errorf("refresh token request did not pass validation")
+	}
+	return refreshRequest, nil
+}
@@ -0,0 +1,152 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ilyushkaaa/Filmoteka/internal/token/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/token/usecase"
+	"github.com/ilyushkaaa/Filmoteka/internal/token/usecase/mock"
+	logger2 "github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func TestRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockTokenUseCase(ctrl)
+	testHandler := NewTokenHandler(testUseCase)
+
+	request := httptest.NewRequest(http.MethodPost, "/token/refresh", nil)
+	respWriter := httptest.NewRecorder()
+	testHandler.Refresh(respWriter, request)
+	resp := respWriter.Result()
+	err := resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 500 {
+		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	testCases := []struct {
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{body: `{"`, statusCode: http.StatusBadRequest},
+		{body: `{}`, statusCode: http.StatusUnprocessableEntity},
+		{
+			body: `{"refresh_token":"old"}`,
+			setup: func() {
+				testUseCase.EXPECT().RefreshTokens(gomock.Any(), "old").Return(nil, usecase.ErrBadRefreshToken)
+			},
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			body: `{"refresh_token":"old"}`,
+			setup: func() {
+				testUseCase.EXPECT().RefreshTokens(gomock.Any(), "old").Return(nil, usecase.ErrRefreshTokenReused)
+			},
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			body: `{"refresh_token":"old"}`,
+			setup: func() {
+				testUseCase.EXPECT().RefreshTokens(gomock.Any(), "old").Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			body: `{"refresh_token":"old"}`,
+			setup: func() {
+				testUseCase.EXPECT().RefreshTokens(gomock.Any(), "old").Return(&entity.TokenPair{
+					AccessToken:          "access",
+					AccessTokenExpiresAt: time.Now().Add(time.Minute),
+					RefreshToken:         "new",
+				}, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request = httptest.NewRequest(http.MethodPost, "/token/refresh", strings.NewReader(tc.body))
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter = httptest.NewRecorder()
+		testHandler.Refresh(respWriter, request.WithContext(ctx))
+		resp = respWriter.Result()
+		err = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockTokenUseCase(ctrl)
+	testHandler := NewTokenHandler(testUseCase)
+
+	testCases := []struct {
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{body: `{}`, statusCode: http.StatusUnprocessableEntity},
+		{
+			body: `{"refresh_token":"old"}`,
+			setup: func() {
+				testUseCase.EXPECT().RevokeRefreshToken(gomock.Any(), "old").Return(usecase.ErrBadRefreshToken)
+			},
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			body: `{"refresh_token":"old"}`,
+			setup: func() {
+				testUseCase.EXPECT().RevokeRefreshToken(gomock.Any(), "old").Return(fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			body: `{"refresh_token":"old"}`,
+			setup: func() {
+				testUseCase.EXPECT().RevokeRefreshToken(gomock.Any(), "old").Return(nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodPost, "/token/revoke", strings.NewReader(tc.body))
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.Revoke(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type RefreshToken struct {
+	UserID   uint64 `json:"user_id"`
+	FamilyID string `json:"family_id"`
+}
+
+type TokenPair struct {
+	AccessToken          string
+	AccessTokenExpiresAt time.Time
+	RefreshToken         string
+}
@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: token.go
+
+// Package repo is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/ilyushkaaa/Filmoteka/internal/token/entity"
+)
+
+// MockRefreshTokenRepo is a mock of RefreshTokenRepo interface.
+type MockRefreshTokenRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefreshTokenRepoMockRecorder
+}
+
+// MockRefreshTokenRepoMockRecorder is the mock recorder for MockRefreshTokenRepo.
+type MockRefreshTokenRepoMockRecorder struct {
+	mock *MockRefreshTokenRepo
+}
+
+// NewMockRefreshTokenRepo creates a new mock instance.
+func NewMockRefreshTokenRepo(ctrl *gomock.Controller) *MockRefreshTokenRepo {
+	mock := &MockRefreshTokenRepo{ctrl: ctrl}
+	mock.recorder = &MockRefreshTokenRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefreshTokenRepo) EXPECT() *MockRefreshTokenRepoMockRecorder {
+	return m.recorder
+}
+
+// GetRefreshToken mocks base method.
+func (m *MockRefreshTokenRepo) GetRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshToken", ctx, tokenHash)
+	ret0, _ := ret[0].(*entity.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshToken indicates an expected call of GetRefreshToken.
+func (mr *MockRefreshTokenRepoMockRecorder) GetRefreshToken(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshToken", reflect.TypeOf((*MockRefreshTokenRepo)(nil).GetRefreshToken), ctx, tokenHash)
+}
+
+// IsFamilyActive mocks base method.
+func (m *MockRefreshTokenRepo) IsFamilyActive(ctx context.Context, familyID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsFamilyActive", ctx, familyID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsFamilyActive indicates an expected call of IsFamilyActive.
+func (mr *MockRefreshTokenRepoMockRecorder) IsFamilyActive(ctx, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsFamilyActive", reflect.TypeOf((*MockRefreshTokenRepo)(nil).IsFamilyActive), ctx, familyID)
+}
+
+// MarkRefreshTokenUsed mocks base method.
+func (m *MockRefreshTokenRepo) MarkRefreshTokenUsed(ctx context.Context, tokenHash string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRefreshTokenUsed", ctx, tokenHash, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRefreshTokenUsed indicates an expected call of MarkRefreshTokenUsed.
+func (mr *MockRefreshTokenRepoMockRecorder) MarkRefreshTokenUsed(ctx, tokenHash, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRefreshTokenUsed", reflect.TypeOf((*MockRefreshTokenRepo)(nil).MarkRefreshTokenUsed), ctx, tokenHash, ttl)
+}
+
+// RevokeFamily mocks base method.
+func (m *MockRefreshTokenRepo) RevokeFamily(ctx context.Context, userID uint64, familyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeFamily", ctx, userID, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeFamily indicates an expected call of RevokeFamily.
+func (mr *MockRefreshTokenRepoMockRecorder) RevokeFamily(ctx, userID, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFamily", reflect.TypeOf((*MockRefreshTokenRepo)(nil).RevokeFamily), ctx, userID, familyID)
+}
+
+// RevokeUserFamilies mocks base method.
+func (m *MockRefreshTokenRepo) RevokeUserFamilies(ctx context.Context, userID uint64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserFamilies", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeUserFamilies indicates an expected call of RevokeUserFamilies.
+func (mr *MockRefreshTokenRepoMockRecorder) RevokeUserFamilies(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserFamilies", reflect.TypeOf((*MockRefreshTokenRepo)(nil).RevokeUserFamilies), ctx, userID)
+}
+
+// SaveRefreshToken mocks base method.
+func (m *MockRefreshTokenRepo) SaveRefreshToken(ctx context.Context, tokenHash string, token *entity.RefreshToken, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRefreshToken", ctx, tokenHash, token, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRefreshToken indicates an expected call of SaveRefreshToken.
+func (mr *MockRefreshTokenRepoMockRecorder) SaveRefreshToken(ctx, tokenHash, token, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRefreshToken", reflect.TypeOf((*MockRefreshTokenRepo)(nil).SaveRefreshToken), ctx, tokenHash, token, ttl)
+}
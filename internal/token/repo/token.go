@@ -0,0 +1,162 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/ilyushkaaa/Filmoteka/internal/token/entity"
+)
+
+//go:generate mockgen -source=token.go -destination=token_mock.go -package=repo RefreshTokenRepo
+type RefreshTokenRepo interface {
+	SaveRefreshToken(ctx context.Context, tokenHash string, token *entity.RefreshToken, ttl time.Duration) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, tokenHash string, ttl time.Duration) (bool, error)
+	IsFamilyActive(ctx context.Context, familyID string) (bool, error)
+	RevokeFamily(ctx context.Context, userID uint64, familyID string) error
+	RevokeUserFamilies(ctx context.Context, userID uint64) (int, error)
+}
+
+const (
+	refreshTokenKeyPrefix     = "refresh_token:"
+	refreshTokenUsedKeyPrefix = "refresh_token_used:"
+	refreshFamilyKeyPrefix    = "refresh_family:"
+	userFamiliesKeyPrefix     = "refresh_user_families:"
+)
+
+type RefreshTokenRepoRedis struct {
+	redisPool *redis.Pool
+}
+
+func NewRefreshTokenRepo(redisPool *redis.Pool) *RefreshTokenRepoRedis {
+	return &RefreshTokenRepoRedis{
+		redisPool: redisPool,
+	}
+}
+
+func (r *RefreshTokenRepoRedis) SaveRefreshToken(ctx context.Context, tokenHash string, token *entity.RefreshToken, ttl time.Duration) error {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	tokenJSON, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	ttlSeconds := int(ttl.Seconds())
+	_, err = redis.DoContext(conn, ctx, "SET", refreshTokenKeyPrefix+tokenHash, tokenJSON, "EX", ttlSeconds)
+	if err != nil {
+		return err
+	}
+	_, err = redis.DoContext(conn, ctx, "SET", refreshFamilyKeyPrefix+token.FamilyID, token.UserID, "EX", ttlSeconds)
+	if err != nil {
+		return err
+	}
+	userFamiliesKey := fmt.Sprint(userFamiliesKeyPrefix, token.UserID)
+	_, err = redis.DoContext(conn, ctx, "SADD", userFamiliesKey, token.FamilyID)
+	if err != nil {
+		return err
+	}
+	_, err = redis.DoContext(conn, ctx, "EXPIRE", userFamiliesKey, ttlSeconds)
+	return err
+}
+
+func (r *RefreshTokenRepoRedis) GetRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	tokenJSON, err := redis.Bytes(redis.DoContext(conn, ctx, "GET", refreshTokenKeyPrefix+tokenHash))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	token := &entity.RefreshToken{}
+	err = json.Unmarshal(tokenJSON, token)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *RefreshTokenRepoRedis) MarkRefreshTokenUsed(ctx context.Context, tokenHash string, ttl time.Duration) (bool, error) {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, err = redis.String(redis.DoContext(conn, ctx, "SET", refreshTokenUsedKeyPrefix+tokenHash, 1, "NX", "EX", int(ttl.Seconds())))
+	if errors.Is(err, redis.ErrNil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *RefreshTokenRepoRedis) IsFamilyActive(ctx context.Context, familyID string) (bool, error) {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	return redis.Bool(redis.DoContext(conn, ctx, "EXISTS", refreshFamilyKeyPrefix+familyID))
+}
+
+func (r *RefreshTokenRepoRedis) RevokeFamily(ctx context.Context, userID uint64, familyID string) error {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, err = redis.DoContext(conn, ctx, "DEL", refreshFamilyKeyPrefix+familyID)
+	if err != nil {
+		return err
+	}
+	_, err = redis.DoContext(conn, ctx, "SREM", fmt.Sprint(userFamiliesKeyPrefix, userID), familyID)
+	return err
+}
+
+func (r *RefreshTokenRepoRedis) RevokeUserFamilies(ctx context.Context, userID uint64) (int, error) {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	userFamiliesKey := fmt.Sprint(userFamiliesKeyPrefix, userID)
+	familyIDs, err := redis.Strings(redis.DoContext(conn, ctx, "SMEMBERS", userFamiliesKey))
+	if err != nil {
+		return 0, err
+	}
+	revokedCount := 0
+	for _, familyID := range familyIDs {
+		deleted, err := redis.Int(redis.DoContext(conn, ctx, "DEL", refreshFamilyKeyPrefix+familyID))
+		if err != nil {
+			return revokedCount, err
+		}
+		revokedCount += deleted
+	}
+	_, err = redis.DoContext(conn, ctx, "DEL", userFamiliesKey)
+	return revokedCount, err
+}
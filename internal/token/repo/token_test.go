@@ -0,0 +1,192 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/ilyushkaaa/Filmoteka/internal/token/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockRedisConn struct {
+	values map[string][]byte
+	sets   map[string]map[string]struct{}
+}
+
+func newMockRedisConn() *MockRedisConn {
+	return &MockRedisConn{
+		values: make(map[string][]byte),
+		sets:   make(map[string]map[string]struct{}),
+	}
+}
+
+func (m *MockRedisConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	// пул отправляет пустую команду, когда соединение возвращается в него
+	if commandName == "" {
+		return nil, nil
+	}
+	key := args[0].(string)
+	if key == "refresh_token:broken" {
+		return nil, fmt.Errorf("err")
+	}
+	switch commandName {
+	case "SET":
+		if len(args) > 2 && args[2] == "NX" {
+			if _, ok := m.values[key]; ok {
+				return nil, nil
+			}
+		}
+		value, ok := args[1].([]byte)
+		if !ok {
+			value = []byte(fmt.Sprint(args[1]))
+		}
+		m.values[key] = value
+		return "OK", nil
+	case "GET":
+		value, ok := m.values[key]
+		if !ok {
+			return nil, nil
+		}
+		return value, nil
+	case "EXISTS":
+		_, ok := m.values[key]
+		if ok {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case "DEL":
+		_, isValue := m.values[key]
+		_, isSet := m.sets[key]
+		delete(m.values, key)
+		delete(m.sets, key)
+		if isValue || isSet {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case "EXPIRE":
+		return int64(1), nil
+	case "SADD":
+		if m.sets[key] == nil {
+			m.sets[key] = make(map[string]struct{})
+		}
+		m.sets[key][args[1].(string)] = struct{}{}
+		return int64(1), nil
+	case "SREM":
+		delete(m.sets[key], args[1].(string))
+		return int64(1), nil
+	case "SMEMBERS":
+		members := make([]interface{}, 0, len(m.sets[key]))
+		for member := range m.sets[key] {
+			members = append(members, []byte(member))
+		}
+		return members, nil
+	}
+	return nil, fmt.Errorf("err")
+}
+
+func (m *MockRedisConn) DoContext(_ context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	return m.Do(commandName, args...)
+}
+
+func (m *MockRedisConn) Close() error {
+	return nil
+}
+
+func (m *MockRedisConn) Err() error {
+	return nil
+}
+
+func (m *MockRedisConn) Send(_ string, _ ...interface{}) error {
+	return nil
+}
+
+func (m *MockRedisConn) Flush() error {
+	return nil
+}
+
+func (m *MockRedisConn) Receive() (interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRedisConn) ReceiveContext(_ context.Context) (interface{}, error) {
+	return nil, nil
+}
+
+var _ redis.ConnWithContext = &MockRedisConn{}
+
+func newMockRedisPool(redisConn redis.Conn) *redis.Pool {
+	return &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redisConn, nil
+		},
+	}
+}
+
+func TestSaveAndGetRefreshToken(t *testing.T) {
+	repo := NewRefreshTokenRepo(newMockRedisPool(newMockRedisConn()))
+
+	token := &entity.RefreshToken{UserID: 1, FamilyID: "family"}
+	err := repo.SaveRefreshToken(context.Background(), "hash", token, time.Hour)
+	assert.NoError(t, err)
+
+	storedToken, err := repo.GetRefreshToken(context.Background(), "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, token, storedToken)
+
+	storedToken, err = repo.GetRefreshToken(context.Background(), "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, storedToken)
+
+	_, err = repo.GetRefreshToken(context.Background(), "broken")
+	assert.Error(t, err)
+
+	err = repo.SaveRefreshToken(context.Background(), "broken", token, time.Hour)
+	assert.Error(t, err)
+
+	isActive, err := repo.IsFamilyActive(context.Background(), "family")
+	assert.NoError(t, err)
+	assert.True(t, isActive)
+}
+
+func TestMarkRefreshTokenUsed(t *testing.T) {
+	repo := NewRefreshTokenRepo(newMockRedisPool(newMockRedisConn()))
+
+	isFirstUse, err := repo.MarkRefreshTokenUsed(context.Background(), "hash", time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, isFirstUse)
+
+	isFirstUse, err = repo.MarkRefreshTokenUsed(context.Background(), "hash", time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, isFirstUse)
+}
+
+func TestRevokeFamilies(t *testing.T) {
+	repo := NewRefreshTokenRepo(newMockRedisPool(newMockRedisConn()))
+
+	for _, familyID := range []string{"first", "second", "third"} {
+		err := repo.SaveRefreshToken(context.Background(), familyID, &entity.RefreshToken{UserID: 1, FamilyID: familyID}, time.Hour)
+		assert.NoError(t, err)
+	}
+	err := repo.SaveRefreshToken(context.Background(), "other", &entity.RefreshToken{UserID: 2, FamilyID: "other"}, time.Hour)
+	assert.NoError(t, err)
+
+	err = repo.RevokeFamily(context.Background(), 1, "first")
+	assert.NoError(t, err)
+	isActive, err := repo.IsFamilyActive(context.Background(), "first")
+	assert.NoError(t, err)
+	assert.False(t, isActive)
+
+	revokedCount, err := repo.RevokeUserFamilies(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, revokedCount)
+	isActive, err = repo.IsFamilyActive(context.Background(), "third")
+	assert.NoError(t, err)
+	assert.False(t, isActive)
+
+	isActive, err = repo.IsFamilyActive(context.Background(), "other")
+	assert.NoError(t, err)
+	assert.True(t, isActive)
+}
@@ -0,0 +1,9 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrBadRefreshToken    = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused = errors.New("refresh token was already used")
+	ErrBadAccessToken     = errors.New("access token is invalid or expired")
+)
@@ -0,0 +1,112 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: token.go
+
+// Package usecase is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/ilyushkaaa/Filmoteka/internal/token/entity"
+	entity0 "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	access_token "github.com/ilyushkaaa/Filmoteka/pkg/access_token"
+)
+
+// MockTokenUseCase is a mock of TokenUseCase interface.
+type MockTokenUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenUseCaseMockRecorder
+}
+
+// MockTokenUseCaseMockRecorder is the mock recorder for MockTokenUseCase.
+type MockTokenUseCaseMockRecorder struct {
+	mock *MockTokenUseCase
+}
+
+// NewMockTokenUseCase creates a new mock instance.
+func NewMockTokenUseCase(ctrl *gomock.Controller) *MockTokenUseCase {
+	mock := &MockTokenUseCase{ctrl: ctrl}
+	mock.recorder = &MockTokenUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenUseCase) EXPECT() *MockTokenUseCaseMockRecorder {
+	return m.recorder
+}
+
+// IssueTokens mocks base method.
+func (m *MockTokenUseCase) IssueTokens(ctx context.Context, user *entity0.User) (*entity.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueTokens", ctx, user)
+	ret0, _ := ret[0].(*entity.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueTokens indicates an expected call of IssueTokens.
+func (mr *MockTokenUseCaseMockRecorder) IssueTokens(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueTokens", reflect.TypeOf((*MockTokenUseCase)(nil).IssueTokens), ctx, user)
+}
+
+// ParseAccessToken mocks base method.
+func (m *MockTokenUseCase) ParseAccessToken(ctx context.Context, accessToken string) (*access_token.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseAccessToken", ctx, accessToken)
+	ret0, _ := ret[0].(*access_token.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseAccessToken indicates an expected call of ParseAccessToken.
+func (mr *MockTokenUseCaseMockRecorder) ParseAccessToken(ctx, accessToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseAccessToken", reflect.TypeOf((*MockTokenUseCase)(nil).ParseAccessToken), ctx, accessToken)
+}
+
+// RefreshTokens mocks base method.
+func (m *MockTokenUseCase) RefreshTokens(ctx context.Context, refreshToken string) (*entity.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTokens", ctx, refreshToken)
+	ret0, _ := ret[0].(*entity.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshTokens indicates an expected call of RefreshTokens.
+func (mr *MockTokenUseCaseMockRecorder) RefreshTokens(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTokens", reflect.TypeOf((*MockTokenUseCase)(nil).RefreshTokens), ctx, refreshToken)
+}
+
+// RevokeRefreshToken mocks base method.
+func (m *MockTokenUseCase) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshToken", ctx, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshToken indicates an expected call of RevokeRefreshToken.
+func (mr *MockTokenUseCaseMockRecorder) RevokeRefreshToken(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshToken", reflect.TypeOf((*MockTokenUseCase)(nil).RevokeRefreshToken), ctx, refreshToken)
+}
+
+// RevokeUserTokens mocks base method.
+func (m *MockTokenUseCase) RevokeUserTokens(ctx context.Context, userID uint64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserTokens", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeUserTokens indicates an expected call of RevokeUserTokens.
+func (mr *MockTokenUseCaseMockRecorder) RevokeUserTokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserTokens", reflect.TypeOf((*MockTokenUseCase)(nil).RevokeUserTokens), ctx, userID)
+}
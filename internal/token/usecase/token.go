@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilyushkaaa/Filmoteka/internal/token/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/token/repo"
+	userEntity "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	userRepo "github.com/ilyushkaaa/Filmoteka/internal/users/repo"
+	"github.com/ilyushkaaa/Filmoteka/pkg/access_token"
+)
+
+const refreshTokenBytes = 32
+
+//go:generate mockgen -source=token.go -destination=token_mock.go -package=usecase TokenUseCase
+type TokenUseCase interface {
+	IssueTokens(ctx context.Context, user *userEntity.User) (*entity.TokenPair, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (*entity.TokenPair, error)
+	RevokeRefreshToken(ctx context.Context, refreshToken string) error
+	RevokeUserTokens(ctx context.Context, userID uint64) (int, error)
+	ParseAccessToken(ctx context.Context, accessToken string) (*access_token.Claims, error)
+}
+
+type TokenUseCaseApp struct {
+	tokenRepo    repo.RefreshTokenRepo
+	userRepo     userRepo.UserRepo
+	accessTokens *access_token.Manager
+	refreshTTL   time.Duration
+}
+
+func NewTokenUseCase(tokenRepo repo.RefreshTokenRepo, userRepo userRepo.UserRepo,
+	accessTokens *access_token.Manager, refreshTTL time.Duration) *TokenUseCaseApp {
+	return &TokenUseCaseApp{
+		tokenRepo:    tokenRepo,
+		userRepo:     userRepo,
+		accessTokens: accessTokens,
+		refreshTTL:   refreshTTL,
+	}
+}
+
+func (tu *TokenUseCaseApp) IssueTokens(ctx context.Context, user *userEntity.User) (*entity.TokenPair, error) {
+	return tu.issueTokens(ctx, user, uuid.New().String())
+}
+
+// RefreshTokens отзывает все семейство, если refresh токен предъявлен повторно
+func (tu *TokenUseCaseApp) RefreshTokens(ctx context.Context, refreshToken string) (*entity.TokenPair, error) {
+	tokenHash := hashRefreshToken(refreshToken)
+	storedToken, err := tu.tokenRepo.GetRefreshToken(ctx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if storedToken == nil {
+		return nil, ErrBadRefreshToken
+	}
+	isActive, err := tu.tokenRepo.IsFamilyActive(ctx, storedToken.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if !isActive {
+		return nil, ErrBadRefreshToken
+	}
+	isFirstUse, err := tu.tokenRepo.MarkRefreshTokenUsed(ctx, tokenHash, tu.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !isFirstUse {
+		err = tu.tokenRepo.RevokeFamily(ctx, storedToken.UserID, storedToken.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	user, err := tu.userRepo.GetUserByID(ctx, storedToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsDisabled {
+		err = tu.tokenRepo.RevokeFamily(ctx, storedToken.UserID, storedToken.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrBadRefreshToken
+	}
+	return tu.issueTokens(ctx, user, storedToken.FamilyID)
+}
+
+func (tu *TokenUseCaseApp) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
+	storedToken, err := tu.tokenRepo.GetRefreshToken(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	if storedToken == nil {
+		return ErrBadRefreshToken
+	}
+	return tu.tokenRepo.RevokeFamily(ctx, storedToken.UserID, storedToken.FamilyID)
+}
+
+func (tu *TokenUseCaseApp) RevokeUserTokens(ctx context.Context, userID uint64) (int, error) {
+	return tu.tokenRepo.RevokeUserFamilies(ctx, userID)
+}
+
+func (tu *TokenUseCaseApp) ParseAccessToken(ctx context.Context, accessToken string) (*access_token.Claims, error) {
+	claims, err := tu.accessTokens.Parse(accessToken)
+	if err != nil {
+		return nil, ErrBadAccessToken
+	}
+	return claims, nil
+}
+
+func (tu *TokenUseCaseApp) issueTokens(ctx context.Context, user *userEntity.User, familyID string) (*entity.TokenPair, error) {
+	accessToken, expiresAt, err := tu.accessTokens.Issue(user.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	refreshTokenRaw := make([]byte, refreshTokenBytes)
+	_, err = rand.Read(refreshTokenRaw)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(refreshTokenRaw)
+	storedToken := &entity.RefreshToken{
+		UserID:   user.ID,
+		FamilyID: familyID,
+	}
+	err = tu.tokenRepo.SaveRefreshToken(ctx, hashRefreshToken(refreshToken), storedToken, tu.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.TokenPair{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: expiresAt,
+		RefreshToken:         refreshToken,
+	}, nil
+}
+
+// hashRefreshToken - токен случаен и длинен, поэтому соль не нужна
+func hashRefreshToken(refreshToken string) string {
+	hash := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ilyushkaaa/Filmoteka/internal/token/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/token/repo/mock"
+	userEntity "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	userMock "github.com/ilyushkaaa/Filmoteka/internal/users/repo/mock"
+	"github.com/ilyushkaaa/Filmoteka/pkg/access_token"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestUseCase(t *testing.T, ctrl *gomock.Controller) (*TokenUseCaseApp, *mock.MockRefreshTokenRepo, *userMock.MockUserRepo) {
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 32)))
+	manager, err := access_token.NewManager(access_token.AlgorithmHS256, "k1:"+key, "k1", time.Minute)
+	if err != nil {
+		t.Fatalf("error creating access token manager: %s", err)
+	}
+	testRepo := mock.NewMockRefreshTokenRepo(ctrl)
+	testUserRepo := userMock.NewMockUserRepo(ctrl)
+	return NewTokenUseCase(testRepo, testUserRepo, manager, time.Hour), testRepo, testUserRepo
+}
+
+func TestIssueTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase, testRepo, _ := newTestUseCase(t, ctrl)
+	user := &userEntity.User{ID: 1, Username: "aaa", Role: userEntity.RoleEditor}
+
+	testRepo.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any(), gomock.Any(), time.Hour).Return(fmt.Errorf("error"))
+	tokens, err := testUseCase.IssueTokens(context.Background(), user)
+	assert.NotEqual(t, nil, err)
+	assert.Nil(t, tokens)
+
+	var savedHash string
+	testRepo.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any(), gomock.Any(), time.Hour).
+		DoAndReturn(func(_ context.Context, tokenHash string, token *entity.RefreshToken, _ time.Duration) error {
+			savedHash = tokenHash
+			assert.Equal(t, uint64(1), token.UserID)
+			assert.NotEqual(t, "", token.FamilyID)
+			return nil
+		})
+	tokens, err = testUseCase.IssueTokens(context.Background(), user)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, hashRefreshToken(tokens.RefreshToken), savedHash)
+	assert.NotEqual(t, tokens.RefreshToken, savedHash)
+
+	claims, err := testUseCase.ParseAccessToken(context.Background(), tokens.AccessToken)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(1), claims.UserID)
+	assert.Equal(t, userEntity.RoleEditor, claims.Role)
+
+	_, err = testUseCase.ParseAccessToken(context.Background(), "bad_token")
+	assert.Equal(t, ErrBadAccessToken, err)
+}
+
+func TestRefreshTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase, testRepo, testUserRepo := newTestUseCase(t, ctrl)
+	refreshToken := "refresh"
+	tokenHash := hashRefreshToken(refreshToken)
+	storedToken := &entity.RefreshToken{UserID: 1, FamilyID: "family"}
+	user := &userEntity.User{ID: 1, Username: "aaa", Role: userEntity.RoleAdmin}
+
+	testRepo.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(nil, fmt.Errorf("error"))
+	_, err := testUseCase.RefreshTokens(context.Background(), refreshToken)
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(nil, nil)
+	_, err = testUseCase.RefreshTokens(context.Background(), refreshToken)
+	assert.Equal(t, ErrBadRefreshToken, err)
+
+	testRepo.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(storedToken, nil)
+	testRepo.EXPECT().IsFamilyActive(gomock.Any(), "family").Return(false, nil)
+	_, err = testUseCase.RefreshTokens(context.Background(), refreshToken)
+	assert.Equal(t, ErrBadRefreshToken, err)
+
+	// повторное использование токена отзывает все семейство
+	testRepo.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(storedToken, nil)
+	testRepo.EXPECT().IsFamilyActive(gomock.Any(), "family").Return(true, nil)
+	testRepo.EXPECT().MarkRefreshTokenUsed(gomock.Any(), tokenHash, time.Hour).Return(false, nil)
+	testRepo.EXPECT().RevokeFamily(gomock.Any(), uint64(1), "family").Return(nil)
+	_, err = testUseCase.RefreshTokens(context.Background(), refreshToken)
+	assert.Equal(t, ErrRefreshTokenReused, err)
+
+	testRepo.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(storedToken, nil)
+	testRepo.EXPECT().IsFamilyActive(gomock.Any(), "family").Return(true, nil)
+	testRepo.EXPECT().MarkRefreshTokenUsed(gomock.Any(), tokenHash, time.Hour).Return(true, nil)
+	testUserRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&userEntity.User{ID: 1, IsDisabled: true}, nil)
+	testRepo.EXPECT().RevokeFamily(gomock.Any(), uint64(1), "family").Return(nil)
+	_, err = testUseCase.RefreshTokens(context.Background(), refreshToken)
+	assert.Equal(t, ErrBadRefreshToken, err)
+
+	testRepo.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(storedToken, nil)
+	testRepo.EXPECT().IsFamilyActive(gomock.Any(), "family").Return(true, nil)
+	testRepo.EXPECT().MarkRefreshTokenUsed(gomock.Any(), tokenHash, time.Hour).Return(true, nil)
+	testUserRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil)
+	testRepo.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any(), storedToken, time.Hour).Return(nil)
+	tokens, err := testUseCase.RefreshTokens(context.Background(), refreshToken)
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, refreshToken, tokens.RefreshToken)
+	claims, err := testUseCase.ParseAccessToken(context.Background(), tokens.AccessToken)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, userEntity.RoleAdmin, claims.Role)
+}
+
+func TestRevokeRefreshToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase, testRepo, _ := newTestUseCase(t, ctrl)
+	tokenHash := hashRefreshToken("refresh")
+
+	testRepo.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(nil, nil)
+	err := testUseCase.RevokeRefreshToken(context.Background(), "refresh")
+	assert.Equal(t, ErrBadRefreshToken, err)
+
+	testRepo.EXPECT().GetRefreshToken(gomock.Any(), tokenHash).Return(&entity.RefreshToken{UserID: 1, FamilyID: "family"}, nil)
+	testRepo.EXPECT().RevokeFamily(gomock.Any(), uint64(1), "family").Return(nil)
+	err = testUseCase.RevokeRefreshToken(context.Background(), "refresh")
+	assert.Equal(t, nil, err)
+
+	testRepo.EXPECT().RevokeUserFamilies(gomock.Any(), uint64(1)).Return(2, nil)
+	revokedCount, err := testUseCase.RevokeUserTokens(context.Background(), 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, revokedCount)
+}
@@ -0,0 +1,295 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
+	"github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/twofactor/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+	"go.uber.org/zap"
+)
+
+type validatableRequest interface {
+	Validate() []string
+}
+
+type TwoFactorHandler struct {
+	twoFactorUseCase usecase.TwoFactorUseCase
+}
+
+func NewTwoFactorHandler(twoFactorUseCase usecase.TwoFactorUseCase) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorUseCase: twoFactorUseCase,
+	}
+}
+
+// EnrollTOTP @Summary Подключить TOTP
+// @Description Создать секрет для приложения-аутентификатора. Второй фактор начнет действовать после подтверждения кодом через /api/v1/me/totp/verify
+// @Tags two-factor
+// @Produce json
+// @Security CookieAuth
+// @Success 200 {object} entity.Enrollment
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Вторым фактором нельзя управлять при аутентификации API ключом"
+// @Failure 409 {object} string "TOTP уже подключен"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/me/totp [post]
+func (h *TwoFactorHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(zapLogger, w, r, false)
+	if err != nil {
+		return
+	}
+	enrollment, err := h.twoFactorUseCase.Enroll(r.Context(), userID)
+	if err != nil {
+		writeTwoFactorError(zapLogger, w, err)
+		return
+	}
+	zapLogger.Infof("totp enrollment was started for user %d", userID)
+	writeEnrollment(zapLogger, w, enrollment)
+}
+
+// VerifyTOTP @Summary Подтвердить подключение TOTP
+// @Description Подтвердить подключение TOTP кодом из приложения-аутентификатора. В ответе возвращаются одноразовые коды восстановления, они показываются только один раз
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param body body dto.TOTPCodeRequest true "Код из приложения-аутентификатора"
+// @Success 200 {object} dto.RecoveryCodesResponse
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Вторым фактором нельзя управлять при аутентификации API ключом"
+// @Failure 409 {object} string "TOTP не подключается или уже подключен"
+// @Failure 422 {object} string "Неверный код"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/me/totp/verify [post]
+func (h *TwoFactorHandler) VerifyTOTP(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(zapLogger, w, r, false)
+	if err != nil {
+		return
+	}
+	codeRequest := &dto.TOTPCodeRequest{}
+	err = decodeRequest(zapLogger, w, r, codeRequest)
+	if err != nil {
+		return
+	}
+	recoveryCodes, err := h.twoFactorUseCase.VerifyEnrollment(r.Context(), userID, codeRequest.Code)
+	if err != nil {
+		writeTwoFactorError(zapLogger, w, err)
+		return
+	}
+	zapLogger.Infof("totp was enabled for user %d", userID)
+	recoveryCodesJSON, err := json.Marshal(dto.RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+	if err != nil {
+		zapLogger.Errorf("error marshalling response: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, recoveryCodesJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+// DisableTOTP @Summary Отключить TOTP
+// @Description Отключить второй фактор, подтвердив это кодом из приложения-аутентификатора или кодом восстановления. Для ролей с правом управления пользователями отключение запрещено
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param body body dto.TOTPCodeRequest true "Код из приложения-аутентификатора или код восстановления"
+// @Success 200 {object} string "TOTP отключен"
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Вторым фактором нельзя управлять при аутентификации API ключом"
+// @Failure 409 {object} string "TOTP не подключен или обязателен для роли пользователя"
+// @Failure 422 {object} string "Неверный код"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/me/totp [delete]
+func (h *TwoFactorHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(zapLogger, w, r, false)
+	if err != nil {
+		return
+	}
+	codeRequest := &dto.TOTPCodeRequest{}
+	err = decodeRequest(zapLogger, w, r, codeRequest)
+	if err != nil {
+		return
+	}
+	err = h.twoFactorUseCase.Disable(r.Context(), userID, codeRequest.Code)
+	if err != nil {
+		writeTwoFactorError(zapLogger, w, err)
+		return
+	}
+	zapLogger.Infof("totp was disabled for user %d", userID)
+	result := `{"result": "success"}`
+	err = response.WriteResponse(w, []byte(result), http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+// EnrollForLogin @Summary Подключить TOTP во время входа
+// @Description Создать секрет для пользователя, которому TOTP обязателен, но еще не подключен. Вход завершается кодом из приложения-аутентификатора через /api/v1/login/totp
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param body body dto.LoginChallengeEnrollRequest true "Идентификатор незавершенного входа"
+// @Success 200 {object} entity.Enrollment
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Вход не найден или истек"
+// @Failure 409 {object} string "TOTP уже подключен"
+// @Failure 422 {object} string "Данные не прошли валидацию"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/login/totp/enroll [post]
+func (h *TwoFactorHandler) EnrollForLogin(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	enrollRequest := &dto.LoginChallengeEnrollRequest{}
+	err = decodeRequest(zapLogger, w, r, enrollRequest)
+	if err != nil {
+		return
+	}
+	enrollment, err := h.twoFactorUseCase.EnrollForChallenge(r.Context(), enrollRequest.ChallengeID)
+	if err != nil {
+		writeTwoFactorError(zapLogger, w, err)
+		return
+	}
+	zapLogger.Infof("totp enrollment was started during login")
+	writeEnrollment(zapLogger, w, enrollment)
+}
+
+func writeEnrollment(zapLogger *zap.SugaredLogger, w http.ResponseWriter, enrollment *entity.Enrollment) {
+	enrollmentJSON, err := json.Marshal(enrollment)
+	if err != nil {
+		zapLogger.Errorf("error marshalling response: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, enrollmentJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+func writeTwoFactorError(zapLogger *zap.SugaredLogger, w http.ResponseWriter, err error) {
+	var statusCode int
+	switch {
+	case errors.Is(err, usecase.ErrBadChallenge):
+		statusCode = http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrNoUser):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, usecase.ErrAlreadyEnabled), errors.Is(err, usecase.ErrNotEnrolled),
+		errors.Is(err, usecase.ErrTwoFactorIsRequired):
+		statusCode = http.StatusConflict
+	case errors.Is(err, usecase.ErrBadCode):
+		statusCode = http.StatusUnprocessableEntity
+	default:
+		zapLogger.Errorf("error in two-factor authentication: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	zapLogger.Errorf("two-factor request was rejected: %s", err)
+	errText := fmt.Sprintf(`{"error": "%s"}`, err)
+	err = response.WriteResponse(w, []byte(errText), statusCode)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+func decodeRequest(zapLogger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request, request validatableRequest) error {
+	rBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		zapLogger.Errorf("error in reading request body: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in reading request body: %s"}`, err)
+		writeErr := response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if writeErr != nil {
+			zapLogger.Errorf("error in writing response: %s", writeErr)
+		}
+		return err
+	}
+	err = json.Unmarshal(rBody, request)
+	if err != nil {
+		zapLogger.Errorf("error in unmarshalling request: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in decoding request: %s"}`, err)
+		writeErr := response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if writeErr != nil {
+			zapLogger.Errorf("error in writing response: %s", writeErr)
+		}
+		return err
+	}
+	validationErrors := request.Validate()
+	if len(validationErrors) != 0 {
+		zapLogger.Errorf("request did not pass validation: %v", validationErrors)
+		errorsJSON, err := json.Marshal(validationErrors)
+		if err != nil {
+			zapLogger.Errorf("error in marshalling validation errors: %s", err)
+			errText := `{"error": "internal server error"}`
+			writeErr := response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if writeErr != nil {
+				zapLogger.Errorf("error in writing response: %s", writeErr)
+			}
+			return err
+		}
+		err = response.WriteResponse(w, errorsJSON, http.StatusUnprocessableEntity)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return fmt.Errorf("request did not pass validation")
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	apiKeyEntity "github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
+	"github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/twofactor/usecase"
+	"github.com/ilyushkaaa/Filmoteka/internal/twofactor/usecase/mock"
+	logger2 "github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func withUser(ctx context.Context) context.Context {
+	return context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+}
+
+func withAPIKey(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+	return context.WithValue(ctx, middleware.MyAPIKeyKey, &apiKeyEntity.APIKey{ID: 1, UserID: 1})
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewTwoFactorHandler(testUseCase)
+
+	testCases := []struct {
+		ctx        func(ctx context.Context) context.Context
+		setup      func()
+		statusCode int
+	}{
+		{
+			ctx:        withAPIKey,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			ctx: withUser,
+			setup: func() {
+				testUseCase.EXPECT().Enroll(gomock.Any(), uint64(1)).Return(nil, usecase.ErrAlreadyEnabled)
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			ctx: withUser,
+			setup: func() {
+				testUseCase.EXPECT().Enroll(gomock.Any(), uint64(1)).Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			ctx: withUser,
+			setup: func() {
+				testUseCase.EXPECT().Enroll(gomock.Any(), uint64(1)).Return(&entity.Enrollment{Secret: "SECRET", URI: "otpauth://totp/"}, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodPost, "/me/totp", nil)
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.EnrollTOTP(respWriter, request.WithContext(tc.ctx(ctx)))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestVerifyAndDisableTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewTwoFactorHandler(testUseCase)
+
+	testCases := []struct {
+		handler    http.HandlerFunc
+		ctx        func(ctx context.Context) context.Context
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{
+			handler:    testHandler.VerifyTOTP,
+			ctx:        withAPIKey,
+			body:       `{"code": "123456"}`,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			handler:    testHandler.VerifyTOTP,
+			ctx:        withUser,
+			body:       `{"code": 123456}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			handler:    testHandler.VerifyTOTP,
+			ctx:        withUser,
+			body:       `{"code": "1234"}`,
+			statusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			handler: testHandler.VerifyTOTP,
+			ctx:     withUser,
+			body:    `{"code": "123456"}`,
+			setup: func() {
+				testUseCase.EXPECT().VerifyEnrollment(gomock.Any(), uint64(1), "123456").Return(nil, usecase.ErrNotEnrolled)
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			handler: testHandler.VerifyTOTP,
+			ctx:     withUser,
+			body:    `{"code": "123456"}`,
+			setup: func() {
+				testUseCase.EXPECT().VerifyEnrollment(gomock.Any(), uint64(1), "123456").Return(nil, usecase.ErrBadCode)
+			},
+			statusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			handler: testHandler.VerifyTOTP,
+			ctx:     withUser,
+			body:    `{"code": "123456"}`,
+			setup: func() {
+				testUseCase.EXPECT().VerifyEnrollment(gomock.Any(), uint64(1), "123456").Return([]string{"abcdefgh-ijklmnop"}, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+		{
+			handler: testHandler.DisableTOTP,
+			ctx:     withUser,
+			body:    `{"code": "123456"}`,
+			setup: func() {
+				testUseCase.EXPECT().Disable(gomock.Any(), uint64(1), "123456").Return(usecase.ErrTwoFactorIsRequired)
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			handler: testHandler.DisableTOTP,
+			ctx:     withUser,
+			body:    `{"code": "123456"}`,
+			setup: func() {
+				testUseCase.EXPECT().Disable(gomock.Any(), uint64(1), "123456").Return(usecase.ErrNoUser)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			handler: testHandler.DisableTOTP,
+			ctx:     withUser,
+			body:    `{"code": "abcdefgh-ijklmnop"}`,
+			setup: func() {
+				testUseCase.EXPECT().Disable(gomock.Any(), uint64(1), "abcdefgh-ijklmnop").Return(nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodPost, "/me/totp", strings.NewReader(tc.body))
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		tc.handler(respWriter, request.WithContext(tc.ctx(ctx)))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestEnrollForLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewTwoFactorHandler(testUseCase)
+	challengeID := "0f8fad5b-d9cb-469f-a165-70867728950e"
+
+	testCases := []struct {
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{
+			body:       `{"challenge_id": "challenge"}`,
+			statusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			body: fmt.Sprintf(`{"challenge_id": "%s"}`, challengeID),
+			setup: func() {
+				testUseCase.EXPECT().EnrollForChallenge(gomock.Any(), challengeID).Return(nil, usecase.ErrBadChallenge)
+			},
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			body: fmt.Sprintf(`{"challenge_id": "%s"}`, challengeID),
+			setup: func() {
+				testUseCase.EXPECT().EnrollForChallenge(gomock.Any(), challengeID).Return(&entity.Enrollment{Secret: "SECRET"}, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodPost, "/login/totp/enroll", strings.NewReader(tc.body))
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.EnrollForLogin(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
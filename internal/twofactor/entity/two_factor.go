@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	userEntity "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+)
+
+type TOTP struct {
+	UserID       uint64
+	Secret       string
+	IsEnabled    bool
+	LastUsedStep int64
+}
+
+type Enrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type LoginChallenge struct {
+	ID                 string    `json:"id"`
+	UserID             uint64    `json:"user_id"`
+	Username           string    `json:"username"`
+	EnrollmentRequired bool      `json:"enrollment_required"`
+	ExpiresAt          time.Time `json:"expires_at"`
+}
+
+type LoginResult struct {
+	User          *userEntity.User
+	RecoveryCodes []string
+}
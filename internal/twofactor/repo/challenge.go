@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+)
+
+//go:generate mockgen -source=challenge.go -destination=challenge_mock.go -package=repo LoginChallengeRepo
+type LoginChallengeRepo interface {
+	SaveChallenge(ctx context.Context, challenge *entity.LoginChallenge) error
+	GetChallenge(ctx context.Context, challengeID string) (*entity.LoginChallenge, error)
+	AddChallengeAttempt(ctx context.Context, challengeID string, expiresAt time.Time) (int, error)
+	DeleteChallenge(ctx context.Context, challengeID string) error
+}
+
+const (
+	loginChallengeKeyPrefix         = "login_challenge:"
+	loginChallengeAttemptsKeyPrefix = "login_challenge_attempts:"
+)
+
+type LoginChallengeRepoRedis struct {
+	redisPool *redis.Pool
+}
+
+func NewLoginChallengeRepo(redisPool *redis.Pool) *LoginChallengeRepoRedis {
+	return &LoginChallengeRepoRedis{
+		redisPool: redisPool,
+	}
+}
+
+func (r *LoginChallengeRepoRedis) SaveChallenge(ctx context.Context, challenge *entity.LoginChallenge) error {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	challengeJSON, err := json.Marshal(challenge)
+	if err != nil {
+		return err
+	}
+	challengeKey := loginChallengeKeyPrefix + challenge.ID
+	_, err = redis.DoContext(conn, ctx, "SET", challengeKey, challengeJSON)
+	if err != nil {
+		return err
+	}
+	_, err = redis.DoContext(conn, ctx, "EXPIREAT", challengeKey, challenge.ExpiresAt.Unix())
+	return err
+}
+
+func (r *LoginChallengeRepoRedis) GetChallenge(ctx context.Context, challengeID string) (*entity.LoginChallenge, error) {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	challengeJSON, err := redis.Bytes(redis.DoContext(conn, ctx, "GET", loginChallengeKeyPrefix+challengeID))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	challenge := &entity.LoginChallenge{}
+	err = json.Unmarshal(challengeJSON, challenge)
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+func (r *LoginChallengeRepoRedis) AddChallengeAttempt(ctx context.Context, challengeID string, expiresAt time.Time) (int, error) {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	attemptsKey := loginChallengeAttemptsKeyPrefix + challengeID
+	attemptsCount, err := redis.Int(redis.DoContext(conn, ctx, "INCR", attemptsKey))
+	if err != nil {
+		return 0, err
+	}
+	_, err = redis.DoContext(conn, ctx, "EXPIREAT", attemptsKey, expiresAt.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return attemptsCount, nil
+}
+
+func (r *LoginChallengeRepoRedis) DeleteChallenge(ctx context.Context, challengeID string) error {
+	conn, err := r.redisPool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, err = redis.DoContext(conn, ctx, "DEL", loginChallengeKeyPrefix+challengeID, loginChallengeAttemptsKeyPrefix+challengeID)
+	return err
+}
@@ -0,0 +1,157 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockRedisConn struct {
+	values    map[string][]byte
+	expiresAt map[string]int64
+}
+
+func newMockRedisConn() *MockRedisConn {
+	return &MockRedisConn{
+		values:    make(map[string][]byte),
+		expiresAt: make(map[string]int64),
+	}
+}
+
+func (m *MockRedisConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	// пул отправляет пустую команду, когда соединение возвращается в него
+	if commandName == "" {
+		return nil, nil
+	}
+	key := args[0].(string)
+	if strings.HasSuffix(key, "broken") {
+		return nil, fmt.Errorf("err")
+	}
+	switch commandName {
+	case "INCR":
+		var counter int64
+		if value, ok := m.values[key]; ok {
+			_, _ = fmt.Sscan(string(value), &counter)
+		}
+		counter++
+		m.values[key] = []byte(fmt.Sprint(counter))
+		return counter, nil
+	case "SET":
+		m.values[key] = args[1].([]byte)
+		return "OK", nil
+	case "GET":
+		value, ok := m.values[key]
+		if !ok {
+			return nil, nil
+		}
+		return value, nil
+	case "EXPIREAT":
+		m.expiresAt[key] = args[1].(int64)
+		return int64(1), nil
+	case "DEL":
+		deleted := int64(0)
+		for _, arg := range args {
+			if _, ok := m.values[arg.(string)]; ok {
+				delete(m.values, arg.(string))
+				deleted++
+			}
+		}
+		return deleted, nil
+	}
+	return nil, fmt.Errorf("err")
+}
+
+func (m *MockRedisConn) DoContext(_ context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	return m.Do(commandName, args...)
+}
+
+func (m *MockRedisConn) Close() error {
+	return nil
+}
+
+func (m *MockRedisConn) Err() error {
+	return nil
+}
+
+func (m *MockRedisConn) Send(_ string, _ ...interface{}) error {
+	return nil
+}
+
+func (m *MockRedisConn) Flush() error {
+	return nil
+}
+
+func (m *MockRedisConn) Receive() (interface{}, error) {
+	return nil, nil
+}
+
+func (m *MockRedisConn) ReceiveContext(_ context.Context) (interface{}, error) {
+	return nil, nil
+}
+
+var _ redis.ConnWithContext = &MockRedisConn{}
+
+func newMockRedisPool(redisConn redis.Conn) *redis.Pool {
+	return &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redisConn, nil
+		},
+	}
+}
+
+func TestLoginChallenge(t *testing.T) {
+	redisConn := newMockRedisConn()
+	repo := NewLoginChallengeRepo(newMockRedisPool(redisConn))
+	expiresAt := time.Unix(time.Now().Add(time.Minute).Unix(), 0)
+	challenge := &entity.LoginChallenge{
+		ID:                 "challenge",
+		UserID:             1,
+		Username:           "admin",
+		EnrollmentRequired: true,
+		ExpiresAt:          expiresAt,
+	}
+
+	savedChallenge, err := repo.GetChallenge(context.Background(), "challenge")
+	assert.NoError(t, err)
+	assert.Nil(t, savedChallenge)
+
+	err = repo.SaveChallenge(context.Background(), challenge)
+	assert.NoError(t, err)
+	assert.Equal(t, expiresAt.Unix(), redisConn.expiresAt["login_challenge:challenge"])
+
+	savedChallenge, err = repo.GetChallenge(context.Background(), "challenge")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), savedChallenge.UserID)
+	assert.Equal(t, "admin", savedChallenge.Username)
+	assert.True(t, savedChallenge.EnrollmentRequired)
+	assert.True(t, expiresAt.Equal(savedChallenge.ExpiresAt))
+
+	for i := 1; i <= 2; i++ {
+		attemptsCount, err := repo.AddChallengeAttempt(context.Background(), "challenge", expiresAt)
+		assert.NoError(t, err)
+		assert.Equal(t, i, attemptsCount)
+	}
+	assert.Equal(t, expiresAt.Unix(), redisConn.expiresAt["login_challenge_attempts:challenge"])
+
+	err = repo.DeleteChallenge(context.Background(), "challenge")
+	assert.NoError(t, err)
+	savedChallenge, err = repo.GetChallenge(context.Background(), "challenge")
+	assert.NoError(t, err)
+	assert.Nil(t, savedChallenge)
+	attemptsCount, err := repo.AddChallengeAttempt(context.Background(), "challenge", expiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attemptsCount)
+
+	_, err = repo.GetChallenge(context.Background(), "broken")
+	assert.Error(t, err)
+	_, err = repo.AddChallengeAttempt(context.Background(), "broken", expiresAt)
+	assert.Error(t, err)
+	err = repo.SaveChallenge(context.Background(), &entity.LoginChallenge{ID: "broken", ExpiresAt: expiresAt})
+	assert.Error(t, err)
+}
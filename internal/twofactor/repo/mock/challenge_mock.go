@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: challenge.go
+
+// Package repo is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+)
+
+// MockLoginChallengeRepo is a mock of LoginChallengeRepo interface.
+type MockLoginChallengeRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginChallengeRepoMockRecorder
+}
+
+// MockLoginChallengeRepoMockRecorder is the mock recorder for MockLoginChallengeRepo.
+type MockLoginChallengeRepoMockRecorder struct {
+	mock *MockLoginChallengeRepo
+}
+
+// NewMockLoginChallengeRepo creates a new mock instance.
+func NewMockLoginChallengeRepo(ctrl *gomock.Controller) *MockLoginChallengeRepo {
+	mock := &MockLoginChallengeRepo{ctrl: ctrl}
+	mock.recorder = &MockLoginChallengeRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginChallengeRepo) EXPECT() *MockLoginChallengeRepoMockRecorder {
+	return m.recorder
+}
+
+// AddChallengeAttempt mocks base method.
+func (m *MockLoginChallengeRepo) AddChallengeAttempt(ctx context.Context, challengeID string, expiresAt time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddChallengeAttempt", ctx, challengeID, expiresAt)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddChallengeAttempt indicates an expected call of AddChallengeAttempt.
+func (mr *MockLoginChallengeRepoMockRecorder) AddChallengeAttempt(ctx, challengeID, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddChallengeAttempt", reflect.TypeOf((*MockLoginChallengeRepo)(nil).AddChallengeAttempt), ctx, challengeID, expiresAt)
+}
+
+// DeleteChallenge mocks base method.
+func (m *MockLoginChallengeRepo) DeleteChallenge(ctx context.Context, challengeID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteChallenge", ctx, challengeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteChallenge indicates an expected call of DeleteChallenge.
+func (mr *MockLoginChallengeRepoMockRecorder) DeleteChallenge(ctx, challengeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChallenge", reflect.TypeOf((*MockLoginChallengeRepo)(nil).DeleteChallenge), ctx, challengeID)
+}
+
+// GetChallenge mocks base method.
+func (m *MockLoginChallengeRepo) GetChallenge(ctx context.Context, challengeID string) (*entity.LoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChallenge", ctx, challengeID)
+	ret0, _ := ret[0].(*entity.LoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChallenge indicates an expected call of GetChallenge.
+func (mr *MockLoginChallengeRepoMockRecorder) GetChallenge(ctx, challengeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChallenge", reflect.TypeOf((*MockLoginChallengeRepo)(nil).GetChallenge), ctx, challengeID)
+}
+
+// SaveChallenge mocks base method.
+func (m *MockLoginChallengeRepo) SaveChallenge(ctx context.Context, challenge *entity.LoginChallenge) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveChallenge", ctx, challenge)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveChallenge indicates an expected call of SaveChallenge.
+func (mr *MockLoginChallengeRepoMockRecorder) SaveChallenge(ctx, challenge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveChallenge", reflect.TypeOf((*MockLoginChallengeRepo)(nil).SaveChallenge), ctx, challenge)
+}
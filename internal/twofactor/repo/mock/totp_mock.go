@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: totp.go
+
+// Package repo is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+)
+
+// MockTOTPRepo is a mock of TOTPRepo interface.
+type MockTOTPRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockTOTPRepoMockRecorder
+}
+
+// MockTOTPRepoMockRecorder is the mock recorder for MockTOTPRepo.
+type MockTOTPRepoMockRecorder struct {
+	mock *MockTOTPRepo
+}
+
+// NewMockTOTPRepo creates a new mock instance.
+func NewMockTOTPRepo(ctrl *gomock.Controller) *MockTOTPRepo {
+	mock := &MockTOTPRepo{ctrl: ctrl}
+	mock.recorder = &MockTOTPRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTOTPRepo) EXPECT() *MockTOTPRepoMockRecorder {
+	return m.recorder
+}
+
+// DeleteTOTP mocks base method.
+func (m *MockTOTPRepo) DeleteTOTP(ctx context.Context, userID uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTP", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteTOTP indicates an expected call of DeleteTOTP.
+func (mr *MockTOTPRepoMockRecorder) DeleteTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTP", reflect.TypeOf((*MockTOTPRepo)(nil).DeleteTOTP), ctx, userID)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockTOTPRepo) EnableTOTP(ctx context.Context, userID uint64, step int64, recoveryCodeHashes []string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", ctx, userID, step, recoveryCodeHashes)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockTOTPRepoMockRecorder) EnableTOTP(ctx, userID, step, recoveryCodeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockTOTPRepo)(nil).EnableTOTP), ctx, userID, step, recoveryCodeHashes)
+}
+
+// GetTOTP mocks base method.
+func (m *MockTOTPRepo) GetTOTP(ctx context.Context, userID uint64) (*entity.TOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", ctx, userID)
+	ret0, _ := ret[0].(*entity.TOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockTOTPRepoMockRecorder) GetTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockTOTPRepo)(nil).GetTOTP), ctx, userID)
+}
+
+// SaveTOTPSecret mocks base method.
+func (m *MockTOTPRepo) SaveTOTPSecret(ctx context.Context, userID uint64, secret string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTPSecret", ctx, userID, secret)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveTOTPSecret indicates an expected call of SaveTOTPSecret.
+func (mr *MockTOTPRepoMockRecorder) SaveTOTPSecret(ctx, userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTPSecret", reflect.TypeOf((*MockTOTPRepo)(nil).SaveTOTPSecret), ctx, userID, secret)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockTOTPRepo) UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockTOTPRepoMockRecorder) UseRecoveryCode(ctx, userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockTOTPRepo)(nil).UseRecoveryCode), ctx, userID, codeHash)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockTOTPRepo) UseTOTPStep(ctx context.Context, userID uint64, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, userID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockTOTPRepoMockRecorder) UseTOTPStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockTOTPRepo)(nil).UseTOTPStep), ctx, userID, step)
+}
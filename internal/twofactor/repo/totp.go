@@ -0,0 +1,171 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source=totp.go -destination=totp_mock.go -package=repo TOTPRepo
+type TOTPRepo interface {
+	GetTOTP(ctx context.Context, userID uint64) (*entity.TOTP, error)
+	SaveTOTPSecret(ctx context.Context, userID uint64, secret string) (bool, error)
+	EnableTOTP(ctx context.Context, userID uint64, step int64, recoveryCodeHashes []string) (bool, error)
+	UseTOTPStep(ctx context.Context, userID uint64, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) (bool, error)
+	DeleteTOTP(ctx context.Context, userID uint64) (bool, error)
+}
+
+type TOTPRepoPG struct {
+	db        *sql.DB
+	zapLogger *zap.SugaredLogger
+}
+
+func NewTOTPRepo(db *sql.DB, zapLogger *zap.SugaredLogger) *TOTPRepoPG {
+	return &TOTPRepoPG{
+		db:        db,
+		zapLogger: zapLogger,
+	}
+}
+
+func (r *TOTPRepoPG) GetTOTP(ctx context.Context, userID uint64) (*entity.TOTP, error) {
+	totpState := &entity.TOTP{}
+	err := r.db.
+		QueryRowContext(ctx, "SELECT user_id, secret, is_enabled, last_used_step FROM user_totp WHERE user_id = $1", userID).
+		Scan(&totpState.UserID, &totpState.Secret, &totpState.IsEnabled, &totpState.LastUsedStep)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return totpState, nil
+}
+
+func (r *TOTPRepoPG) SaveTOTPSecret(ctx context.Context, userID uint64, secret string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+        INSERT INTO user_totp (user_id, secret) VALUES ($1, $2)
+        ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, created_at = now()
+        WHERE NOT user_totp.is_enabled
+    `, userID, secret)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *TOTPRepoPG) EnableTOTP(ctx context.Context, userID uint64, step int64, recoveryCodeHashes []string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			err = tx.Rollback()
+			if err != nil {
+				r.zapLogger.Errorf("error in transaction rollback: %s", err)
+			}
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx, `
+        UPDATE user_totp SET is_enabled = TRUE, last_used_step = $2
+        WHERE user_id = $1 AND NOT is_enabled AND last_used_step < $2
+    `, userID, step)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		err = tx.Rollback()
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM totp_recovery_codes WHERE user_id = $1", userID)
+	if err != nil {
+		return false, err
+	}
+	for _, codeHash := range recoveryCodeHashes {
+		_, err = tx.ExecContext(ctx, "INSERT INTO totp_recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, codeHash)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *TOTPRepoPG) UseTOTPStep(ctx context.Context, userID uint64, step int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+        UPDATE user_totp SET last_used_step = $2
+        WHERE user_id = $1 AND is_enabled AND last_used_step < $2
+    `, userID, step)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *TOTPRepoPG) UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM totp_recovery_codes WHERE user_id = $1 AND code_hash = $2", userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *TOTPRepoPG) DeleteTOTP(ctx context.Context, userID uint64) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			err = tx.Rollback()
+			if err != nil {
+				r.zapLogger.Errorf("error in transaction rollback: %s", err)
+			}
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM totp_recovery_codes WHERE user_id = $1", userID)
+	if err != nil {
+		return false, err
+	}
+	result, err := tx.ExecContext(ctx, "DELETE FROM user_totp WHERE user_id = $1", userID)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
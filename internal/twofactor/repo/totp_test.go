@@ -0,0 +1,185 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func newTestTOTPRepo(t *testing.T) (*TOTPRepoPG, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	return NewTOTPRepo(db, zap.NewNop().Sugar()), mock, func() { _ = db.Close() }
+}
+
+func TestGetTOTP(t *testing.T) {
+	repo, mock, closeDB := newTestTOTPRepo(t)
+	defer closeDB()
+
+	mock.ExpectQuery("SELECT (.+) FROM user_totp WHERE user_id = (.+)").
+		WithArgs(uint64(1)).
+		WillReturnError(sql.ErrNoRows)
+	totpState, err := repo.GetTOTP(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Nil(t, totpState)
+
+	mock.ExpectQuery("SELECT (.+) FROM user_totp WHERE user_id = (.+)").
+		WithArgs(uint64(1)).
+		WillReturnError(fmt.Errorf("error"))
+	_, err = repo.GetTOTP(context.Background(), 1)
+	assert.Error(t, err)
+
+	mock.ExpectQuery("SELECT (.+) FROM user_totp WHERE user_id = (.+)").
+		WithArgs(uint64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "secret", "is_enabled", "last_used_step"}).
+			AddRow(1, "SECRET", true, 100))
+	totpState, err = repo.GetTOTP(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "SECRET", totpState.Secret)
+	assert.True(t, totpState.IsEnabled)
+	assert.Equal(t, int64(100), totpState.LastUsedStep)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveTOTPSecret(t *testing.T) {
+	repo, mock, closeDB := newTestTOTPRepo(t)
+	defer closeDB()
+
+	mock.ExpectExec("INSERT INTO user_totp (.+) ON CONFLICT (.+) WHERE NOT user_totp.is_enabled").
+		WithArgs(uint64(1), "SECRET").
+		WillReturnError(fmt.Errorf("error"))
+	_, err := repo.SaveTOTPSecret(context.Background(), 1, "SECRET")
+	assert.Error(t, err)
+
+	mock.ExpectExec("INSERT INTO user_totp (.+) ON CONFLICT (.+) WHERE NOT user_totp.is_enabled").
+		WithArgs(uint64(1), "SECRET").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	isSaved, err := repo.SaveTOTPSecret(context.Background(), 1, "SECRET")
+	assert.NoError(t, err)
+	assert.False(t, isSaved)
+
+	mock.ExpectExec("INSERT INTO user_totp (.+) ON CONFLICT (.+) WHERE NOT user_totp.is_enabled").
+		WithArgs(uint64(1), "SECRET").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	isSaved, err = repo.SaveTOTPSecret(context.Background(), 1, "SECRET")
+	assert.NoError(t, err)
+	assert.True(t, isSaved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnableTOTP(t *testing.T) {
+	repo, mock, closeDB := newTestTOTPRepo(t)
+	defer closeDB()
+	hashes := []string{"hash1", "hash2"}
+
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("error"))
+	_, err := repo.EnableTOTP(context.Background(), 1, 100, hashes)
+	assert.Error(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE user_totp SET is_enabled = TRUE").
+		WithArgs(uint64(1), int64(100)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	isEnabled, err := repo.EnableTOTP(context.Background(), 1, 100, hashes)
+	assert.NoError(t, err)
+	assert.False(t, isEnabled)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE user_totp SET is_enabled = TRUE").
+		WithArgs(uint64(1), int64(100)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM totp_recovery_codes WHERE user_id = (.+)").
+		WithArgs(uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO totp_recovery_codes (.+)").
+		WithArgs(uint64(1), "hash1").
+		WillReturnError(fmt.Errorf("error"))
+	mock.ExpectRollback()
+	_, err = repo.EnableTOTP(context.Background(), 1, 100, hashes)
+	assert.Error(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE user_totp SET is_enabled = TRUE").
+		WithArgs(uint64(1), int64(100)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM totp_recovery_codes WHERE user_id = (.+)").
+		WithArgs(uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	for _, hash := range hashes {
+		mock.ExpectExec("INSERT INTO totp_recovery_codes (.+)").
+			WithArgs(uint64(1), hash).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+	isEnabled, err = repo.EnableTOTP(context.Background(), 1, 100, hashes)
+	assert.NoError(t, err)
+	assert.True(t, isEnabled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUseTOTPStepAndRecoveryCode(t *testing.T) {
+	repo, mock, closeDB := newTestTOTPRepo(t)
+	defer closeDB()
+
+	mock.ExpectExec("UPDATE user_totp SET last_used_step = (.+) WHERE (.+) last_used_step < (.+)").
+		WithArgs(uint64(1), int64(100)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	isUsed, err := repo.UseTOTPStep(context.Background(), 1, 100)
+	assert.NoError(t, err)
+	assert.False(t, isUsed)
+
+	mock.ExpectExec("UPDATE user_totp SET last_used_step = (.+) WHERE (.+) last_used_step < (.+)").
+		WithArgs(uint64(1), int64(101)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	isUsed, err = repo.UseTOTPStep(context.Background(), 1, 101)
+	assert.NoError(t, err)
+	assert.True(t, isUsed)
+
+	mock.ExpectExec("DELETE FROM totp_recovery_codes WHERE user_id = (.+) AND code_hash = (.+)").
+		WithArgs(uint64(1), "hash").
+		WillReturnError(fmt.Errorf("error"))
+	_, err = repo.UseRecoveryCode(context.Background(), 1, "hash")
+	assert.Error(t, err)
+
+	mock.ExpectExec("DELETE FROM totp_recovery_codes WHERE user_id = (.+) AND code_hash = (.+)").
+		WithArgs(uint64(1), "hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	isUsed, err = repo.UseRecoveryCode(context.Background(), 1, "hash")
+	assert.NoError(t, err)
+	assert.True(t, isUsed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteTOTP(t *testing.T) {
+	repo, mock, closeDB := newTestTOTPRepo(t)
+	defer closeDB()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM totp_recovery_codes WHERE user_id = (.+)").
+		WithArgs(uint64(1)).
+		WillReturnError(fmt.Errorf("error"))
+	mock.ExpectRollback()
+	_, err := repo.DeleteTOTP(context.Background(), 1)
+	assert.Error(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM totp_recovery_codes WHERE user_id = (.+)").
+		WithArgs(uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectExec("DELETE FROM user_totp WHERE user_id = (.+)").
+		WithArgs(uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	isDeleted, err := repo.DeleteTOTP(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.True(t, isDeleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
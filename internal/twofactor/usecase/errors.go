@@ -0,0 +1,14 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrBadChallenge        = errors.New("login challenge is invalid or expired")
+	ErrTooManyAttempts     = errors.New("too many attempts for login challenge")
+	ErrBadCode             = errors.New("two-factor code is invalid")
+	ErrNoUser              = errors.New("user not exists")
+	ErrAlreadyEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrNotEnrolled         = errors.New("two-factor authentication is not enrolled")
+	ErrEnrollmentRequired  = errors.New("two-factor authentication must be enrolled to log in")
+	ErrTwoFactorIsRequired = errors.New("two-factor authentication is required for user role")
+)
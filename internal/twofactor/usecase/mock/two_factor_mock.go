@@ -0,0 +1,141 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: two_factor.go
+
+// Package usecase is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+	entity0 "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+)
+
+// MockTwoFactorUseCase is a mock of TwoFactorUseCase interface.
+type MockTwoFactorUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorUseCaseMockRecorder
+}
+
+// MockTwoFactorUseCaseMockRecorder is the mock recorder for MockTwoFactorUseCase.
+type MockTwoFactorUseCaseMockRecorder struct {
+	mock *MockTwoFactorUseCase
+}
+
+// NewMockTwoFactorUseCase creates a new mock instance.
+func NewMockTwoFactorUseCase(ctrl *gomock.Controller) *MockTwoFactorUseCase {
+	mock := &MockTwoFactorUseCase{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactorUseCase) EXPECT() *MockTwoFactorUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CompleteLogin mocks base method.
+func (m *MockTwoFactorUseCase) CompleteLogin(ctx context.Context, challenge *entity.LoginChallenge, code string) (*entity.LoginResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteLogin", ctx, challenge, code)
+	ret0, _ := ret[0].(*entity.LoginResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteLogin indicates an expected call of CompleteLogin.
+func (mr *MockTwoFactorUseCaseMockRecorder) CompleteLogin(ctx, challenge, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteLogin", reflect.TypeOf((*MockTwoFactorUseCase)(nil).CompleteLogin), ctx, challenge, code)
+}
+
+// Disable mocks base method.
+func (m *MockTwoFactorUseCase) Disable(ctx context.Context, userID uint64, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable.
+func (mr *MockTwoFactorUseCaseMockRecorder) Disable(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockTwoFactorUseCase)(nil).Disable), ctx, userID, code)
+}
+
+// Enroll mocks base method.
+func (m *MockTwoFactorUseCase) Enroll(ctx context.Context, userID uint64) (*entity.Enrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enroll", ctx, userID)
+	ret0, _ := ret[0].(*entity.Enrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enroll indicates an expected call of Enroll.
+func (mr *MockTwoFactorUseCaseMockRecorder) Enroll(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enroll", reflect.TypeOf((*MockTwoFactorUseCase)(nil).Enroll), ctx, userID)
+}
+
+// EnrollForChallenge mocks base method.
+func (m *MockTwoFactorUseCase) EnrollForChallenge(ctx context.Context, challengeID string) (*entity.Enrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollForChallenge", ctx, challengeID)
+	ret0, _ := ret[0].(*entity.Enrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollForChallenge indicates an expected call of EnrollForChallenge.
+func (mr *MockTwoFactorUseCaseMockRecorder) EnrollForChallenge(ctx, challengeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollForChallenge", reflect.TypeOf((*MockTwoFactorUseCase)(nil).EnrollForChallenge), ctx, challengeID)
+}
+
+// GetChallenge mocks base method.
+func (m *MockTwoFactorUseCase) GetChallenge(ctx context.Context, challengeID string) (*entity.LoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChallenge", ctx, challengeID)
+	ret0, _ := ret[0].(*entity.LoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChallenge indicates an expected call of GetChallenge.
+func (mr *MockTwoFactorUseCaseMockRecorder) GetChallenge(ctx, challengeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChallenge", reflect.TypeOf((*MockTwoFactorUseCase)(nil).GetChallenge), ctx, challengeID)
+}
+
+// StartLogin mocks base method.
+func (m *MockTwoFactorUseCase) StartLogin(ctx context.Context, user *entity0.User) (*entity.LoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartLogin", ctx, user)
+	ret0, _ := ret[0].(*entity.LoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartLogin indicates an expected call of StartLogin.
+func (mr *MockTwoFactorUseCaseMockRecorder) StartLogin(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartLogin", reflect.TypeOf((*MockTwoFactorUseCase)(nil).StartLogin), ctx, user)
+}
+
+// VerifyEnrollment mocks base method.
+func (m *MockTwoFactorUseCase) VerifyEnrollment(ctx context.Context, userID uint64, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEnrollment", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEnrollment indicates an expected call of VerifyEnrollment.
+func (mr *MockTwoFactorUseCaseMockRecorder) VerifyEnrollment(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEnrollment", reflect.TypeOf((*MockTwoFactorUseCase)(nil).VerifyEnrollment), ctx, userID, code)
+}
@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/twofactor/repo"
+	userEntity "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	userRepo "github.com/ilyushkaaa/Filmoteka/internal/users/repo"
+	"github.com/ilyushkaaa/Filmoteka/pkg/totp"
+)
+
+const (
+	totpIssuer = "Filmoteka"
+
+	challengeTTL          = 5 * time.Minute
+	maxChallengeAttempts  = 5
+	recoveryCodesCount    = 10
+	recoveryCodeBytes     = 5
+	recoveryCodeSeparator = "-"
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+//go:generate mockgen -source=two_factor.go -destination=two_factor_mock.go -package=usecase TwoFactorUseCase
+type TwoFactorUseCase interface {
+	StartLogin(ctx context.Context, user *userEntity.User) (*entity.LoginChallenge, error)
+	GetChallenge(ctx context.Context, challengeID string) (*entity.LoginChallenge, error)
+	EnrollForChallenge(ctx context.Context, challengeID string) (*entity.Enrollment, error)
+	CompleteLogin(ctx context.Context, challenge *entity.LoginChallenge, code string) (*entity.LoginResult, error)
+	Enroll(ctx context.Context, userID uint64) (*entity.Enrollment, error)
+	VerifyEnrollment(ctx context.Context, userID uint64, code string) ([]string, error)
+	Disable(ctx context.Context, userID uint64, code string) error
+}
+
+type TwoFactorUseCaseApp struct {
+	totpRepo      repo.TOTPRepo
+	challengeRepo repo.LoginChallengeRepo
+	userRepo      userRepo.UserRepo
+}
+
+func NewTwoFactorUseCase(totpRepo repo.TOTPRepo, challengeRepo repo.LoginChallengeRepo,
+	userRepo userRepo.UserRepo) *TwoFactorUseCaseApp {
+	return &TwoFactorUseCaseApp{
+		totpRepo:      totpRepo,
+		challengeRepo: challengeRepo,
+		userRepo:      userRepo,
+	}
+}
+
+// StartLogin возвращает nil, если второй фактор не нужен
+func (tu *TwoFactorUseCaseApp) StartLogin(ctx context.Context, user *userEntity.User) (*entity.LoginChallenge, error) {
+	totpState, err := tu.totpRepo.GetTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	isEnabled := totpState != nil && totpState.IsEnabled
+	if !isEnabled {
+		isRequired, err := tu.isRequired(ctx, user.Role)
+		if err != nil {
+			return nil, err
+		}
+		if !isRequired {
+			return nil, nil
+		}
+	}
+	challenge := &entity.LoginChallenge{
+		ID:                 uuid.New().String(),
+		UserID:             user.ID,
+		Username:           user.Username,
+		EnrollmentRequired: !isEnabled,
+		ExpiresAt:          time.Now().Add(challengeTTL),
+	}
+	err = tu.challengeRepo.SaveChallenge(ctx, challenge)
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+func (tu *TwoFactorUseCaseApp) GetChallenge(ctx context.Context, challengeID string) (*entity.LoginChallenge, error) {
+	return tu.challengeRepo.GetChallenge(ctx, challengeID)
+}
+
+func (tu *TwoFactorUseCaseApp) EnrollForChallenge(ctx context.Context, challengeID string) (*entity.Enrollment, error) {
+	challenge, err := tu.challengeRepo.GetChallenge(ctx, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil {
+		return nil, ErrBadChallenge
+	}
+	if !challenge.EnrollmentRequired {
+		return nil, ErrAlreadyEnabled
+	}
+	return tu.Enroll(ctx, challenge.UserID)
+}
+
+func (tu *TwoFactorUseCaseApp) CompleteLogin(ctx context.Context, challenge *entity.LoginChallenge, code string) (*entity.LoginResult, error) {
+	attemptsCount, err := tu.challengeRepo.AddChallengeAttempt(ctx, challenge.ID, challenge.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if attemptsCount > maxChallengeAttempts {
+		err = tu.challengeRepo.DeleteChallenge(ctx, challenge.ID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrTooManyAttempts
+	}
+	user, err := tu.userRepo.GetUserByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsDisabled {
+		return nil, ErrBadChallenge
+	}
+	totpState, err := tu.totpRepo.GetTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if totpState == nil {
+		return nil, ErrEnrollmentRequired
+	}
+
+	result := &entity.LoginResult{User: user}
+	if totpState.IsEnabled {
+		err = tu.verifyCode(ctx, totpState, code)
+	} else {
+		result.RecoveryCodes, err = tu.enable(ctx, totpState, code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = tu.challengeRepo.DeleteChallenge(ctx, challenge.ID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (tu *TwoFactorUseCaseApp) Enroll(ctx context.Context, userID uint64) (*entity.Enrollment, error) {
+	user, err := tu.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrNoUser
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	isSaved, err := tu.totpRepo.SaveTOTPSecret(ctx, userID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if !isSaved {
+		return nil, ErrAlreadyEnabled
+	}
+	return &entity.Enrollment{
+		Secret: secret,
+		URI:    totp.KeyURI(totpIssuer, user.Username, secret),
+	}, nil
+}
+
+func (tu *TwoFactorUseCaseApp) VerifyEnrollment(ctx context.Context, userID uint64, code string) ([]string, error) {
+	totpState, err := tu.totpRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if totpState == nil {
+		return nil, ErrNotEnrolled
+	}
+	if totpState.IsEnabled {
+		return nil, ErrAlreadyEnabled
+	}
+	return tu.enable(ctx, totpState, code)
+}
+
+func (tu *TwoFactorUseCaseApp) Disable(ctx context.Context, userID uint64, code string) error {
+	role, err := tu.userRepo.GetUserRole(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return ErrNoUser
+	}
+	isRequired, err := tu.isRequired(ctx, role)
+	if err != nil {
+		return err
+	}
+	if isRequired {
+		return ErrTwoFactorIsRequired
+	}
+	totpState, err := tu.totpRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if totpState == nil || !totpState.IsEnabled {
+		return ErrNotEnrolled
+	}
+	err = tu.verifyCode(ctx, totpState, code)
+	if err != nil {
+		return err
+	}
+	_, err = tu.totpRepo.DeleteTOTP(ctx, userID)
+	return err
+}
+
+func (tu *TwoFactorUseCaseApp) enable(ctx context.Context, totpState *entity.TOTP, code string) ([]string, error) {
+	step, isValid, err := totp.Validate(totpState.Secret, code, time.Now(), totpState.LastUsedStep)
+	if err != nil {
+		return nil, err
+	}
+	if !isValid {
+		return nil, ErrBadCode
+	}
+	recoveryCodes, recoveryCodeHashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	isEnabled, err := tu.totpRepo.EnableTOTP(ctx, totpState.UserID, step, recoveryCodeHashes)
+	if err != nil {
+		return nil, err
+	}
+	if !isEnabled {
+		return nil, ErrBadCode
+	}
+	return recoveryCodes, nil
+}
+
+func (tu *TwoFactorUseCaseApp) verifyCode(ctx context.Context, totpState *entity.TOTP, code string) error {
+	if len(code) == totp.Digits {
+		step, isValid, err := totp.Validate(totpState.Secret, code, time.Now(), totpState.LastUsedStep)
+		if err != nil {
+			return err
+		}
+		if !isValid {
+			return ErrBadCode
+		}
+		isUsed, err := tu.totpRepo.UseTOTPStep(ctx, totpState.UserID, step)
+		if err != nil {
+			return err
+		}
+		if !isUsed {
+			return ErrBadCode
+		}
+		return nil
+	}
+	isUsed, err := tu.totpRepo.UseRecoveryCode(ctx, totpState.UserID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !isUsed {
+		return ErrBadCode
+	}
+	return nil
+}
+
+func (tu *TwoFactorUseCaseApp) isRequired(ctx context.Context, role string) (bool, error) {
+	permissions, err := tu.userRepo.GetRolePermissions(ctx, role)
+	if err != nil {
+		return false, err
+	}
+	for _, permission := range permissions {
+		if permission == userEntity.PermissionUserManage {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	recoveryCodes := make([]string, 0, recoveryCodesCount)
+	recoveryCodeHashes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		codeBytes := make([]byte, recoveryCodeBytes*2)
+		_, err := rand.Read(codeBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(codeBytes[:recoveryCodeBytes]) +
+			recoveryCodeSeparator + recoveryCodeEncoding.EncodeToString(codeBytes[recoveryCodeBytes:]))
+		recoveryCodes = append(recoveryCodes, code)
+		recoveryCodeHashes = append(recoveryCodeHashes, hashRecoveryCode(code))
+	}
+	return recoveryCodes, recoveryCodeHashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalizedCode := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), recoveryCodeSeparator, ""))
+	hash := sha256.Sum256([]byte(normalizedCode))
+	return hex.EncodeToString(hash[:])
+}
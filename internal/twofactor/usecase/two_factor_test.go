@@ -0,0 +1,335 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/twofactor/repo/mock"
+	userEntity "github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	userMock "github.com/ilyushkaaa/Filmoteka/internal/users/repo/mock"
+	"github.com/ilyushkaaa/Filmoteka/pkg/totp"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRepos struct {
+	totpRepo      *mock.MockTOTPRepo
+	challengeRepo *mock.MockLoginChallengeRepo
+	userRepo      *userMock.MockUserRepo
+}
+
+func newTestUseCase(ctrl *gomock.Controller) (*TwoFactorUseCaseApp, testRepos) {
+	repos := testRepos{
+		totpRepo:      mock.NewMockTOTPRepo(ctrl),
+		challengeRepo: mock.NewMockLoginChallengeRepo(ctrl),
+		userRepo:      userMock.NewMockUserRepo(ctrl),
+	}
+	return NewTwoFactorUseCase(repos.totpRepo, repos.challengeRepo, repos.userRepo), repos
+}
+
+func newTestTOTP(t *testing.T, isEnabled bool) (*entity.TOTP, string) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("error generating secret: %s", err)
+	}
+	code, err := totp.GenerateCode(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("error generating code: %s", err)
+	}
+	return &entity.TOTP{UserID: 1, Secret: secret, IsEnabled: isEnabled}, code
+}
+
+func TestStartLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase, repos := newTestUseCase(ctrl)
+	editor := &userEntity.User{ID: 1, Username: "editor", Role: userEntity.RoleEditor}
+	admin := &userEntity.User{ID: 1, Username: "admin", Role: userEntity.RoleAdmin}
+	enabledTOTP, _ := newTestTOTP(t, true)
+
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(nil, fmt.Errorf("error"))
+	_, err := testUseCase.StartLogin(context.Background(), editor)
+	assert.Error(t, err)
+
+	// второй фактор не подключен и не обязателен
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(nil, nil)
+	repos.userRepo.EXPECT().GetRolePermissions(gomock.Any(), userEntity.RoleEditor).Return([]string{userEntity.PermissionFilmWrite}, nil)
+	challenge, err := testUseCase.StartLogin(context.Background(), editor)
+	assert.NoError(t, err)
+	assert.Nil(t, challenge)
+
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(enabledTOTP, nil)
+	repos.challengeRepo.EXPECT().SaveChallenge(gomock.Any(), gomock.Any()).Return(nil)
+	challenge, err = testUseCase.StartLogin(context.Background(), editor)
+	assert.NoError(t, err)
+	assert.False(t, challenge.EnrollmentRequired)
+	assert.Equal(t, "editor", challenge.Username)
+
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(&entity.TOTP{UserID: 1, IsEnabled: false}, nil)
+	repos.userRepo.EXPECT().GetRolePermissions(gomock.Any(), userEntity.RoleAdmin).Return([]string{userEntity.PermissionUserManage}, nil)
+	repos.challengeRepo.EXPECT().SaveChallenge(gomock.Any(), gomock.Any()).Return(nil)
+	challenge, err = testUseCase.StartLogin(context.Background(), admin)
+	assert.NoError(t, err)
+	assert.True(t, challenge.EnrollmentRequired)
+
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(nil, nil)
+	repos.userRepo.EXPECT().GetRolePermissions(gomock.Any(), userEntity.RoleAdmin).Return(nil, fmt.Errorf("error"))
+	_, err = testUseCase.StartLogin(context.Background(), admin)
+	assert.Error(t, err)
+
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(enabledTOTP, nil)
+	repos.challengeRepo.EXPECT().SaveChallenge(gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
+	_, err = testUseCase.StartLogin(context.Background(), editor)
+	assert.Error(t, err)
+}
+
+func TestCompleteLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase, repos := newTestUseCase(ctrl)
+	user := &userEntity.User{ID: 1, Username: "admin", Role: userEntity.RoleAdmin}
+	challenge := &entity.LoginChallenge{ID: "challenge", UserID: 1, Username: "admin", ExpiresAt: time.Now().Add(time.Minute)}
+	enabledTOTP, code := newTestTOTP(t, true)
+	pendingTOTP, pendingCode := newTestTOTP(t, false)
+
+	testCases := []struct {
+		code               string
+		setup              func()
+		err                error
+		withRecoveryCodes  bool
+		expectSuccessfully bool
+	}{
+		{
+			code: code,
+			setup: func() {
+				repos.challengeRepo.EXPECT().AddChallengeAttempt(gomock.Any(), "challenge", challenge.ExpiresAt).Return(6, nil)
+				repos.challengeRepo.EXPECT().DeleteChallenge(gomock.Any(), "challenge").Return(nil)
+			},
+			err: ErrTooManyAttempts,
+		},
+		{
+			code: code,
+			setup: func() {
+				repos.challengeRepo.EXPECT().AddChallengeAttempt(gomock.Any(), "challenge", challenge.ExpiresAt).Return(1, nil)
+				repos.userRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&userEntity.User{ID: 1, IsDisabled: true}, nil)
+			},
+			err: ErrBadChallenge,
+		},
+		{
+			code: code,
+			setup: func() {
+				repos.challengeRepo.EXPECT().AddChallengeAttempt(gomock.Any(), "challenge", challenge.ExpiresAt).Return(1, nil)
+				repos.userRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil)
+				repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(nil, nil)
+			},
+			err: ErrEnrollmentRequired,
+		},
+		{
+			code: "000000",
+			setup: func() {
+				repos.challengeRepo.EXPECT().AddChallengeAttempt(gomock.Any(), "challenge", challenge.ExpiresAt).Return(1, nil)
+				repos.userRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil)
+				repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(&entity.TOTP{UserID: 1, Secret: enabledTOTP.Secret,
+					IsEnabled: true, LastUsedStep: totp.Step(time.Now()) + 1}, nil)
+			},
+			err: ErrBadCode,
+		},
+		{
+			// код уже использован другим запросом
+			code: code,
+			setup: func() {
+				repos.challengeRepo.EXPECT().AddChallengeAttempt(gomock.Any(), "challenge", challenge.ExpiresAt).Return(1, nil)
+				repos.userRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil)
+				repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(enabledTOTP, nil)
+				repos.totpRepo.EXPECT().UseTOTPStep(gomock.Any(), uint64(1), totp.Step(time.Now())).Return(false, nil)
+			},
+			err: ErrBadCode,
+		},
+		{
+			code: code,
+			setup: func() {
+				repos.challengeRepo.EXPECT().AddChallengeAttempt(gomock.Any(), "challenge", challenge.ExpiresAt).Return(1, nil)
+				repos.userRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil)
+				repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(enabledTOTP, nil)
+				repos.totpRepo.EXPECT().UseTOTPStep(gomock.Any(), uint64(1), totp.Step(time.Now())).Return(true, nil)
+				repos.challengeRepo.EXPECT().DeleteChallenge(gomock.Any(), "challenge").Return(nil)
+			},
+			expectSuccessfully: true,
+		},
+		{
+			code: "ABCDE-FGHIJ",
+			setup: func() {
+				repos.challengeRepo.EXPECT().AddChallengeAttempt(gomock.Any(), "challenge", challenge.ExpiresAt).Return(1, nil)
+				repos.userRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil)
+				repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(enabledTOTP, nil)
+				repos.totpRepo.EXPECT().UseRecoveryCode(gomock.Any(), uint64(1), hashRecoveryCode("abcdefghij")).Return(true, nil)
+				repos.challengeRepo.EXPECT().DeleteChallenge(gomock.Any(), "challenge").Return(nil)
+			},
+			expectSuccessfully: true,
+		},
+		{
+			code: "abcde-fghij",
+			setup: func() {
+				repos.challengeRepo.EXPECT().AddChallengeAttempt(gomock.Any(), "challenge", challenge.ExpiresAt).Return(1, nil)
+				repos.userRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil)
+				repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(enabledTOTP, nil)
+				repos.totpRepo.EXPECT().UseRecoveryCode(gomock.Any(), uint64(1), gomock.Any()).Return(false, nil)
+			},
+			err: ErrBadCode,
+		},
+		{
+			// подключение обязательного TOTP во время входа
+			code: pendingCode,
+			setup: func() {
+				repos.challengeRepo.EXPECT().AddChallengeAttempt(gomock.Any(), "challenge", challenge.ExpiresAt).Return(1, nil)
+				repos.userRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil)
+				repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(pendingTOTP, nil)
+				repos.totpRepo.EXPECT().EnableTOTP(gomock.Any(), uint64(1), totp.Step(time.Now()), gomock.Len(recoveryCodesCount)).Return(true, nil)
+				repos.challengeRepo.EXPECT().DeleteChallenge(gomock.Any(), "challenge").Return(nil)
+			},
+			withRecoveryCodes:  true,
+			expectSuccessfully: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc.setup()
+		result, err := testUseCase.CompleteLogin(context.Background(), challenge, tc.code)
+		if !tc.expectSuccessfully {
+			assert.ErrorIs(t, err, tc.err)
+			assert.Nil(t, result)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, user, result.User)
+		if tc.withRecoveryCodes {
+			assert.Len(t, result.RecoveryCodes, recoveryCodesCount)
+		} else {
+			assert.Empty(t, result.RecoveryCodes)
+		}
+	}
+}
+
+func TestEnroll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase, repos := newTestUseCase(ctrl)
+	user := &userEntity.User{ID: 1, Username: "admin", Role: userEntity.RoleAdmin}
+
+	repos.userRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(nil, nil)
+	_, err := testUseCase.Enroll(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrNoUser)
+
+	repos.userRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil)
+	repos.totpRepo.EXPECT().SaveTOTPSecret(gomock.Any(), uint64(1), gomock.Any()).Return(false, nil)
+	_, err = testUseCase.Enroll(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrAlreadyEnabled)
+
+	repos.userRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil)
+	repos.totpRepo.EXPECT().SaveTOTPSecret(gomock.Any(), uint64(1), gomock.Any()).Return(true, nil)
+	enrollment, err := testUseCase.Enroll(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+	assert.Contains(t, enrollment.URI, "Filmoteka:admin")
+
+	repos.challengeRepo.EXPECT().GetChallenge(gomock.Any(), "challenge").Return(nil, nil)
+	_, err = testUseCase.EnrollForChallenge(context.Background(), "challenge")
+	assert.ErrorIs(t, err, ErrBadChallenge)
+
+	repos.challengeRepo.EXPECT().GetChallenge(gomock.Any(), "challenge").Return(&entity.LoginChallenge{ID: "challenge", UserID: 1}, nil)
+	_, err = testUseCase.EnrollForChallenge(context.Background(), "challenge")
+	assert.ErrorIs(t, err, ErrAlreadyEnabled)
+
+	repos.challengeRepo.EXPECT().GetChallenge(gomock.Any(), "challenge").
+		Return(&entity.LoginChallenge{ID: "challenge", UserID: 1, EnrollmentRequired: true}, nil)
+	repos.userRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil)
+	repos.totpRepo.EXPECT().SaveTOTPSecret(gomock.Any(), uint64(1), gomock.Any()).Return(true, nil)
+	enrollment, err = testUseCase.EnrollForChallenge(context.Background(), "challenge")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, enrollment.Secret)
+}
+
+func TestVerifyEnrollment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase, repos := newTestUseCase(ctrl)
+	pendingTOTP, code := newTestTOTP(t, false)
+	enabledTOTP, _ := newTestTOTP(t, true)
+
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(nil, nil)
+	_, err := testUseCase.VerifyEnrollment(context.Background(), 1, code)
+	assert.ErrorIs(t, err, ErrNotEnrolled)
+
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(enabledTOTP, nil)
+	_, err = testUseCase.VerifyEnrollment(context.Background(), 1, code)
+	assert.ErrorIs(t, err, ErrAlreadyEnabled)
+
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(pendingTOTP, nil)
+	_, err = testUseCase.VerifyEnrollment(context.Background(), 1, "12345x")
+	assert.ErrorIs(t, err, ErrBadCode)
+
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(pendingTOTP, nil)
+	repos.totpRepo.EXPECT().EnableTOTP(gomock.Any(), uint64(1), totp.Step(time.Now()), gomock.Any()).Return(false, nil)
+	_, err = testUseCase.VerifyEnrollment(context.Background(), 1, code)
+	assert.ErrorIs(t, err, ErrBadCode)
+
+	var storedHashes []string
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(pendingTOTP, nil)
+	repos.totpRepo.EXPECT().EnableTOTP(gomock.Any(), uint64(1), totp.Step(time.Now()), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uint64, _ int64, recoveryCodeHashes []string) (bool, error) {
+			storedHashes = recoveryCodeHashes
+			return true, nil
+		})
+	recoveryCodes, err := testUseCase.VerifyEnrollment(context.Background(), 1, code)
+	assert.NoError(t, err)
+	assert.Len(t, recoveryCodes, recoveryCodesCount)
+	// сохраняются только хеши кодов
+	for i, recoveryCode := range recoveryCodes {
+		assert.NotContains(t, storedHashes, recoveryCode)
+		assert.Equal(t, hashRecoveryCode(recoveryCode), storedHashes[i])
+	}
+}
+
+func TestDisable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase, repos := newTestUseCase(ctrl)
+	enabledTOTP, code := newTestTOTP(t, true)
+
+	repos.userRepo.EXPECT().GetUserRole(gomock.Any(), uint64(1)).Return("", nil)
+	err := testUseCase.Disable(context.Background(), 1, code)
+	assert.ErrorIs(t, err, ErrNoUser)
+
+	repos.userRepo.EXPECT().GetUserRole(gomock.Any(), uint64(1)).Return(userEntity.RoleAdmin, nil)
+	repos.userRepo.EXPECT().GetRolePermissions(gomock.Any(), userEntity.RoleAdmin).Return([]string{userEntity.PermissionUserManage}, nil)
+	err = testUseCase.Disable(context.Background(), 1, code)
+	assert.ErrorIs(t, err, ErrTwoFactorIsRequired)
+
+	repos.userRepo.EXPECT().GetUserRole(gomock.Any(), uint64(1)).Return(userEntity.RoleDefault, nil).Times(3)
+	repos.userRepo.EXPECT().GetRolePermissions(gomock.Any(), userEntity.RoleDefault).Return([]string{}, nil).Times(3)
+
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(&entity.TOTP{UserID: 1}, nil)
+	err = testUseCase.Disable(context.Background(), 1, code)
+	assert.ErrorIs(t, err, ErrNotEnrolled)
+
+	wrongCode := "000000"
+	if code == wrongCode {
+		wrongCode = "111111"
+	}
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(enabledTOTP, nil)
+	err = testUseCase.Disable(context.Background(), 1, wrongCode)
+	assert.ErrorIs(t, err, ErrBadCode)
+
+	repos.totpRepo.EXPECT().GetTOTP(gomock.Any(), uint64(1)).Return(enabledTOTP, nil)
+	repos.totpRepo.EXPECT().UseTOTPStep(gomock.Any(), uint64(1), totp.Step(time.Now())).Return(true, nil)
+	repos.totpRepo.EXPECT().DeleteTOTP(gomock.Any(), uint64(1)).Return(true, nil)
+	err = testUseCase.Disable(context.Background(), 1, code)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,475 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	usecaseUser "github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultUsersLimit = 20
+	maxUsersLimit     = 100
+)
+
+// GetUsers @Summary Получить список пользователей
+// @Description Получить постраничный список пользователей с возможностью поиска по имени пользователя
+// @Tags admin
+// @Accept json
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param search query string false "Часть имени пользователя"
+// @Param limit query int false "Количество пользователей на странице (от 1 до 100, по умолчанию 20)"
+// @Param offset query int false "Смещение от начала списка"
+// @Success 200 {object} dto.UsersPage
+// @Failure 400 {object} string "Параметры страницы переданы в неверном формате"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/users [get]
+func (uh *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	limit, offset, err := getPage(r)
+	if err != nil {
+		zapLogger.Errorf("error in page params conversion: %s", err)
+		errText := fmt.Sprintf(`{"error": "bad format of page params: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	users, total, err := uh.userUseCase.GetUsers(r.Context(), r.URL.Query().Get("search"), limit, offset)
+	if err != nil {
+		zapLogger.Errorf("error in getting users: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	usersPage := dto.UsersPage{
+		Users:  users,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	usersJSON, err := json.Marshal(usersPage)
+	if err != nil {
+		zapLogger.Errorf("error marshalling response: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, usersJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+// GetUserByID @Summary Получить пользователя по ID
+// @Description Получить имя, роль и статус блокировки пользователя по его идентификатору
+// @Tags admin
+// @Accept json
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param USER_ID path int true "Идентификатор пользователя"
+// @Success 200 {object} entity.User
+// @Failure 400 {object} string "Неверный формат идентификатора пользователя"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 404 {object} string "Пользователь не найден"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/users/{USER_ID} [get]
+func (uh *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := getUserIDFromPath(zapLogger, w, r)
+	if err != nil {
+		return
+	}
+	var user *entity.User
+	user, err = uh.userUseCase.GetUserByID(r.Context(), userID)
+	if err != nil {
+		writeUserChangeError(zapLogger, w, userID, err)
+		return
+	}
+	userJSON, err := json.Marshal(user)
+	if err != nil {
+		zapLogger.Errorf("error marshalling response: %s", err)
+		errText := `{"error": "internal server error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, userJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+// SetUserRole @Summary Изменить роль пользователя
+// @Description Назначить пользователю новую роль. Все сессии пользователя завершаются. Нельзя лишить роли последнего активного администратора
+// @Tags admin
+// @Accept json
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param USER_ID path int true "Идентификатор пользователя"
+// @Param body body dto.UserRoleUpdate true "Новая роль пользователя"
+// @Success 200 {object} string "Роль успешно изменена"
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 404 {object} string "Пользователь не найден"
+// @Failure 409 {object} string "Пользователь является последним активным администратором"
+// @Failure 422 {object} string "Данные не прошли валидацию или роль не существует"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/users/{USER_ID}/role [put]
+func (uh *UserHandler) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := getUserIDFromPath(zapLogger, w, r)
+	if err != nil {
+		return
+	}
+	rBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		zapLogger.Errorf("error in reading request body: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in reading request body: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	roleUpdate := &dto.UserRoleUpdate{}
+	err = json.Unmarshal(rBody, roleUpdate)
+	if err != nil {
+		zapLogger.Errorf("error in unmarshalling role update: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in decoding role update: %s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	validationErrors := roleUpdate.Validate()
+	if len(validationErrors) != 0 {
+		zapLogger.Errorf("role update did not pass validation: %v", validationErrors)
+		errorsJSON, err := json.Marshal(validationErrors)
+		if err != nil {
+			zapLogger.Errorf("error in marshalling validation errors: %s", err)
+			errText := `{"error": "internal server error"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if err != nil {
+				zapLogger.Errorf("error in writing response: %s", err)
+			}
+			return
+		}
+		err = response.WriteResponse(w, errorsJSON, http.StatusUnprocessableEntity)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	err = uh.userUseCase.SetUserRole(r.Context(), userID, roleUpdate.Role)
+	if err != nil {
+		writeUserChangeError(zapLogger, w, userID, err)
+		return
+	}
+	zapLogger.Infof("role of user %d was changed to %s", userID, roleUpdate.Role)
+	uh.revokeUserSessions(r.Context(), zapLogger, w, userID)
+}
+
+// DisableUser @Summary Заблокировать пользователя
+// @Description Запретить пользователю вход в систему и завершить все его сессии. Нельзя заблокировать последнего активного администратора
+// @Tags admin
+// @Accept json
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param USER_ID path int true "Идентификатор пользователя"
+// @Success 200 {object} string "Пользователь заблокирован"
+// @Failure 400 {object} string "Неверный формат идентификатора пользователя"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 404 {object} string "Пользователь не найден"
+// @Failure 409 {object} string "Пользователь является последним активным администратором"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/users/{USER_ID}/disable [post]
+func (uh *UserHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	uh.setUserDisabled(w, r, true)
+}
+
+// EnableUser @Summary Разблокировать пользователя
+// @Description Снова разрешить пользователю вход в систему
+// @Tags admin
+// @Accept json
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param USER_ID path int true "Идентификатор пользователя"
+// @Success 200 {object} string "Пользователь разблокирован"
+// @Failure 400 {object} string "Неверный формат идентификатора пользователя"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 404 {object} string "Пользователь не найден"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/users/{USER_ID}/enable [post]
+func (uh *UserHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	uh.setUserDisabled(w, r, false)
+}
+
+// UnlockUserLogin @Summary Снять блокировку входа
+// @Description Сбросить счетчик неудачных попыток входа пользователя и снять временную блокировку входа по его имени
+// @Tags admin
+// @Accept json
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param USER_ID path int true "Идентификатор пользователя"
+// @Success 200 {object} string "Блокировка входа снята"
+// @Failure 400 {object} string "Неверный формат идентификатора пользователя"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 404 {object} string "Пользователь не найден"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/users/{USER_ID}/unlock [post]
+func (uh *UserHandler) UnlockUserLogin(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := getUserIDFromPath(zapLogger, w, r)
+	if err != nil {
+		return
+	}
+	user, err := uh.userUseCase.GetUserByID(r.Context(), userID)
+	if err != nil {
+		writeUserChangeError(zapLogger, w, userID, err)
+		return
+	}
+	err = uh.lockoutUseCase.UnlockUser(r.Context(), user.Username)
+	if err != nil {
+		writeUserChangeError(zapLogger, w, userID, err)
+		return
+	}
+	zapLogger.Infof("login lockout of user %d was removed", userID)
+	result := `{"result": "success"}`
+	err = response.WriteResponse(w, []byte(result), http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+func (uh *UserHandler) setUserDisabled(w http.ResponseWriter, r *http.Request, isDisabled bool) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := getUserIDFromPath(zapLogger, w, r)
+	if err != nil {
+		return
+	}
+	err = uh.userUseCase.SetUserDisabled(r.Context(), userID, isDisabled)
+	if err != nil {
+		writeUserChangeError(zapLogger, w, userID, err)
+		return
+	}
+	zapLogger.Infof("user %d disabled status was set to %t", userID, isDisabled)
+	if isDisabled {
+		uh.revokeUserSessions(r.Context(), zapLogger, w, userID)
+		return
+	}
+	result := `{"result": "success"}`
+	err = response.WriteResponse(w, []byte(result), http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+// DeleteUser @Summary Удалить пользователя
+// @Description Удалить пользователя и завершить все его сессии. Нельзя удалить последнего активного администратора
+// @Tags admin
+// @Accept json
+// @Produce json
+// @SecurityRequirement CookieAuth
+// @Param USER_ID path int true "Идентификатор пользователя"
+// @Success 200 {object} string "Пользователь удален"
+// @Failure 400 {object} string "Неверный формат идентификатора пользователя"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Запрещено для данного пользователя"
+// @Failure 404 {object} string "Пользователь не найден"
+// @Failure 409 {object} string "Пользователь является последним активным администратором"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/admin/users/{USER_ID} [delete]
+func (uh *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := getUserIDFromPath(zapLogger, w, r)
+	if err != nil {
+		return
+	}
+	err = uh.userUseCase.DeleteUser(r.Context(), userID)
+	if err != nil {
+		writeUserChangeError(zapLogger, w, userID, err)
+		return
+	}
+	zapLogger.Infof("user %d was deleted", userID)
+	uh.revokeUserSessions(r.Context(), zapLogger, w, userID)
+}
+
+func (uh *UserHandler) revokeUserSessions(ctx context.Context, zapLogger *zap.SugaredLogger, w http.ResponseWriter, userID uint64) {
+	sessionsDeleted, err := uh.sessionUseCase.DeleteUserSessions(ctx, userID)
+	if err != nil {
+		zapLogger.Errorf("error in deleting sessions of user %d: %s", userID, err)
+		errText := `{"error": "user was updated, but user sessions were not revoked"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("error in writing response: %s", err)
+		}
+		return
+	}
+	zapLogger.Infof("%d sessions of user %d were revoked", sessionsDeleted, userID)
+	if uh.tokenUseCase != nil {
+		tokensRevoked, err := uh.tokenUseCase.RevokeUserTokens(ctx, userID)
+		if err != nil {
+			zapLogger.Errorf("error in revoking tokens of user %d: %s", userID, err)
+			errText := `{"error": "user was updated, but user tokens were not revoked"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if err != nil {
+				zapLogger.Errorf("error in writing response: %s", err)
+			}
+			return
+		}
+		zapLogger.Infof("%d token families of user %d were revoked", tokensRevoked, userID)
+	}
+	result := `{"result": "success"}`
+	err = response.WriteResponse(w, []byte(result), http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+func writeUserChangeError(zapLogger *zap.SugaredLogger, w http.ResponseWriter, userID uint64, err error) {
+	var (
+		errText    string
+		statusCode int
+	)
+	switch {
+	case errors.Is(err, usecaseUser.ErrNoUser):
+		zapLogger.Errorf("user with id %d is not found", userID)
+		errText = fmt.Sprintf(`{"error": "user with ID %d is not found"}`, userID)
+		statusCode = http.StatusNotFound
+	case errors.Is(err, usecaseUser.ErrBadRole):
+		zapLogger.Errorf("unknown role was passed for user with id %d", userID)
+		errText = `{"error": "unknown user role"}`
+		statusCode = http.StatusUnprocessableEntity
+	case errors.Is(err, usecaseUser.ErrLastAdmin):
+		zapLogger.Errorf("user with id %d is the last active admin", userID)
+		errText = fmt.Sprintf(`{"error": "user with ID %d is the last active admin"}`, userID)
+		statusCode = http.StatusConflict
+	default:
+		zapLogger.Errorf("error in changing user %d: %s", userID, err)
+		errText = `{"error": "internal server error"}`
+		statusCode = http.StatusInternalServerError
+	}
+	err = response.WriteResponse(w, []byte(errText), statusCode)
+	if err != nil {
+		zapLogger.Errorf("error in writing response: %s", err)
+	}
+}
+
+func getUserIDFromPath(zapLogger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request) (uint64, error) {
+	vars := mux.Vars(r)
+	userID, err := strconv.ParseUint(vars["USER_ID"], 10, 64)
+	if err != nil {
+		zapLogger.Errorf("error in user id conversion: %s", err)
+		errText := fmt.Sprintf(`{"error": "bad format of user id: %s"}`, err)
+		writeErr := response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if writeErr != nil {
+			zapLogger.Errorf("error in writing response: %s", writeErr)
+		}
+		return 0, err
+	}
+	return userID, nil
+}
+
+func getPage(r *http.Request) (uint64, uint64, error) {
+	query := r.URL.Query()
+	var (
+		limit  uint64 = defaultUsersLimit
+		offset uint64
+		err    error
+	)
+	if limitParam := query.Get("limit"); limitParam != "" {
+		limit, err = strconv.ParseUint(limitParam, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		if limit == 0 || limit > maxUsersLimit {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", maxUsersLimit)
+		}
+	}
+	if offsetParam := query.Get("offset"); offsetParam != "" {
+		offset, err = strconv.ParseUint(offsetParam, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return limit, offset, nil
+}
@@ -0,0 +1,484 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	lockoutMock "github.com/ilyushkaaa/Filmoteka/internal/lockout/usecase/mock"
+	usecase2 "github.com/ilyushkaaa/Filmoteka/internal/session/usecase/mock"
+	tokenMock "github.com/ilyushkaaa/Filmoteka/internal/token/usecase/mock"
+	twoFactorMock "github.com/ilyushkaaa/Filmoteka/internal/twofactor/usecase/mock"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/usecase/mock"
+	logger2 "github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func TestGetUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, nil, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
+
+	request := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+	respWriter := httptest.NewRecorder()
+	testHandler.GetUsers(respWriter, request)
+	resp := respWriter.Result()
+	err := resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 500 {
+		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	testCases := []struct {
+		query      string
+		setup      func()
+		statusCode int
+	}{
+		{query: "?limit=bad", statusCode: http.StatusBadRequest},
+		{query: "?limit=0", statusCode: http.StatusBadRequest},
+		{query: "?limit=101", statusCode: http.StatusBadRequest},
+		{query: "?offset=-1", statusCode: http.StatusBadRequest},
+		{
+			query: "",
+			setup: func() {
+				testUseCase.EXPECT().GetUsers(gomock.Any(), "", uint64(20), uint64(0)).Return(nil, uint64(0), fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			query: "?search=adm&limit=10&offset=10",
+			setup: func() {
+				testUseCase.EXPECT().GetUsers(gomock.Any(), "adm", uint64(10), uint64(10)).
+					Return([]entity.User{{ID: 1, Username: "admin", Role: entity.RoleAdmin}}, uint64(11), nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request = httptest.NewRequest(http.MethodGet, "/admin/users"+tc.query, nil)
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter = httptest.NewRecorder()
+		testHandler.GetUsers(respWriter, request.WithContext(ctx))
+		resp = respWriter.Result()
+		err = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestGetUserByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, nil, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
+
+	testCases := []struct {
+		id         string
+		setup      func()
+		statusCode int
+	}{
+		{id: "bad_id", statusCode: http.StatusBadRequest},
+		{
+			id: "1",
+			setup: func() {
+				testUseCase.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(nil, usecase.ErrNoUser)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			id: "1",
+			setup: func() {
+				testUseCase.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			id: "1",
+			setup: func() {
+				testUseCase.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&entity.User{ID: 1, Username: "admin"}, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodGet, "/admin/users/"+tc.id, nil)
+		request = mux.SetURLVars(request, map[string]string{"USER_ID": tc.id})
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.GetUserByID(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestSetUserRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, nil, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
+
+	testCases := []struct {
+		id         string
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{id: "bad_id", body: `{"role":"admin"}`, statusCode: http.StatusBadRequest},
+		{id: "1", body: `{"`, statusCode: http.StatusBadRequest},
+		{id: "1", body: `{"role":"Super User"}`, statusCode: http.StatusUnprocessableEntity},
+		{
+			id:   "1",
+			body: `{"role":"superuser"}`,
+			setup: func() {
+				testUseCase.EXPECT().SetUserRole(gomock.Any(), uint64(1), "superuser").Return(usecase.ErrBadRole)
+			},
+			statusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			id:   "1",
+			body: `{"role":"default"}`,
+			setup: func() {
+				testUseCase.EXPECT().SetUserRole(gomock.Any(), uint64(1), entity.RoleDefault).Return(usecase.ErrLastAdmin)
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			id:   "1",
+			body: `{"role":"admin"}`,
+			setup: func() {
+				testUseCase.EXPECT().SetUserRole(gomock.Any(), uint64(1), entity.RoleAdmin).Return(usecase.ErrNoUser)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			id:   "1",
+			body: `{"role":"admin"}`,
+			setup: func() {
+				testUseCase.EXPECT().SetUserRole(gomock.Any(), uint64(1), entity.RoleAdmin).Return(nil)
+				sessionTestUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(0, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			id:   "1",
+			body: `{"role":"admin"}`,
+			setup: func() {
+				testUseCase.EXPECT().SetUserRole(gomock.Any(), uint64(1), entity.RoleAdmin).Return(nil)
+				sessionTestUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(2, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodPut, "/admin/users/"+tc.id+"/role", strings.NewReader(tc.body))
+		request = mux.SetURLVars(request, map[string]string{"USER_ID": tc.id})
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.SetUserRole(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestDisableEnableUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, nil, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
+
+	testCases := []struct {
+		id         string
+		handler    http.HandlerFunc
+		setup      func()
+		statusCode int
+	}{
+		{id: "bad_id", handler: testHandler.DisableUser, statusCode: http.StatusBadRequest},
+		{
+			id:      "1",
+			handler: testHandler.DisableUser,
+			setup: func() {
+				testUseCase.EXPECT().SetUserDisabled(gomock.Any(), uint64(1), true).Return(usecase.ErrLastAdmin)
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			id:      "1",
+			handler: testHandler.DisableUser,
+			setup: func() {
+				testUseCase.EXPECT().SetUserDisabled(gomock.Any(), uint64(1), true).Return(fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			id:      "1",
+			handler: testHandler.DisableUser,
+			setup: func() {
+				testUseCase.EXPECT().SetUserDisabled(gomock.Any(), uint64(1), true).Return(nil)
+				sessionTestUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(1, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+		{
+			id:      "1",
+			handler: testHandler.EnableUser,
+			setup: func() {
+				testUseCase.EXPECT().SetUserDisabled(gomock.Any(), uint64(1), false).Return(usecase.ErrNoUser)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			id:      "1",
+			handler: testHandler.EnableUser,
+			setup: func() {
+				testUseCase.EXPECT().SetUserDisabled(gomock.Any(), uint64(1), false).Return(nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodPost, "/admin/users/"+tc.id, nil)
+		request = mux.SetURLVars(request, map[string]string{"USER_ID": tc.id})
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		tc.handler(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestUnlockUserLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, nil, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
+	user := &entity.User{ID: 1, Username: "hello12", Role: entity.RoleDefault}
+
+	testCases := []struct {
+		id         string
+		setup      func()
+		statusCode int
+	}{
+		{id: "bad_id", statusCode: http.StatusBadRequest},
+		{
+			id: "1",
+			setup: func() {
+				testUseCase.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(nil, usecase.ErrNoUser)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			id: "1",
+			setup: func() {
+				testUseCase.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil)
+				lockoutTestUseCase.EXPECT().UnlockUser(gomock.Any(), "hello12").Return(fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			id: "1",
+			setup: func() {
+				testUseCase.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(user, nil)
+				lockoutTestUseCase.EXPECT().UnlockUser(gomock.Any(), "hello12").Return(nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodPost, "/admin/users/"+tc.id+"/unlock", nil)
+		request = mux.SetURLVars(request, map[string]string{"USER_ID": tc.id})
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.UnlockUserLogin(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, nil, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
+
+	testCases := []struct {
+		id         string
+		setup      func()
+		statusCode int
+	}{
+		{id: "bad_id", statusCode: http.StatusBadRequest},
+		{
+			id: "1",
+			setup: func() {
+				testUseCase.EXPECT().DeleteUser(gomock.Any(), uint64(1)).Return(usecase.ErrLastAdmin)
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			id: "1",
+			setup: func() {
+				testUseCase.EXPECT().DeleteUser(gomock.Any(), uint64(1)).Return(usecase.ErrNoUser)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			id: "1",
+			setup: func() {
+				testUseCase.EXPECT().DeleteUser(gomock.Any(), uint64(1)).Return(nil)
+				sessionTestUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(0, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodDelete, "/admin/users/"+tc.id, nil)
+		request = mux.SetURLVars(request, map[string]string{"USER_ID": tc.id})
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.DeleteUser(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestRevokeUserTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	tokenTestUseCase := tokenMock.NewMockTokenUseCase(ctrl)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, tokenTestUseCase, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
+
+	testCases := []struct {
+		setup      func()
+		statusCode int
+	}{
+		{
+			setup: func() {
+				testUseCase.EXPECT().SetUserDisabled(gomock.Any(), uint64(1), true).Return(nil)
+				sessionTestUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(1, nil)
+				tokenTestUseCase.EXPECT().RevokeUserTokens(gomock.Any(), uint64(1)).Return(0, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			setup: func() {
+				testUseCase.EXPECT().SetUserDisabled(gomock.Any(), uint64(1), true).Return(nil)
+				sessionTestUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(1, nil)
+				tokenTestUseCase.EXPECT().RevokeUserTokens(gomock.Any(), uint64(1)).Return(2, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		tc.setup()
+		request := httptest.NewRequest(http.MethodPost, "/admin/users/1/disable", nil)
+		request = mux.SetURLVars(request, map[string]string{"USER_ID": "1"})
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.DisableUser(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
+	usecaseUser "github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+	"go.uber.org/zap"
+)
+
+// ChangePassword @Summary Сменить пароль
+// @Description Сменить пароль текущего пользователя. Требуется текущий пароль. Все сессии пользователя, кроме текущей, завершаются, а refresh токены отзываются
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param body body dto.PasswordChangeRequest true "Текущий и новый пароль"
+// @Success 200 {object} string "Пароль изменен"
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Неверный текущий пароль или аутентификация API ключом"
+// @Failure 404 {object} string "Пользователь не найден"
+// @Failure 422 {object} string "Данные не прошли валидацию"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/me/password [post]
+func (uh *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(zapLogger, w, r, false)
+	if err != nil {
+		return
+	}
+	changeRequest := &dto.PasswordChangeRequest{}
+	if err = decodeRequest(zapLogger, w, r, changeRequest); err != nil {
+		return
+	}
+	err = uh.userUseCase.ChangePassword(r.Context(), userID, changeRequest.CurrentPassword, changeRequest.NewPassword)
+	if errors.Is(err, usecaseUser.ErrBadCredentials) {
+		zapLogger.Errorf("user %d sent wrong current password", userID)
+		err = response.WriteResponse(w, []byte(`{"error": "current password is wrong"}`), http.StatusForbidden)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if errors.Is(err, usecaseUser.ErrNoUser) {
+		zapLogger.Errorf("user %d is not found", userID)
+		err = response.WriteResponse(w, []byte(`{"error": "user not found"}`), http.StatusNotFound)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		zapLogger.Errorf("error in changing password: %s", err)
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	zapLogger.Infof("user %d changed password", userID)
+	sessionID, _ := r.Context().Value(middleware.MySessionIDKey).(string)
+	uh.revokeOtherSessions(r.Context(), zapLogger, w, userID, sessionID)
+}
+
+func (uh *UserHandler) revokeOtherSessions(ctx context.Context, zapLogger *zap.SugaredLogger, w http.ResponseWriter, userID uint64, currentSessionID string) {
+	var sessionsDeleted int
+	var err error
+	if currentSessionID != "" {
+		sessionsDeleted, err = uh.sessionUseCase.DeleteOtherUserSessions(ctx, userID, currentSessionID)
+	} else {
+		sessionsDeleted, err = uh.sessionUseCase.DeleteUserSessions(ctx, userID)
+	}
+	if err != nil {
+		zapLogger.Errorf("error in deleting sessions of user %d: %s", userID, err)
+		errText := `{"error": "password was changed, but other sessions were not revoked"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	zapLogger.Infof("%d other sessions of user %d were deleted", sessionsDeleted, userID)
+	if uh.tokenUseCase != nil {
+		familiesRevoked, err := uh.tokenUseCase.RevokeUserTokens(ctx, userID)
+		if err != nil {
+			zapLogger.Errorf("error in revoking tokens of user %d: %s", userID, err)
+			errText := `{"error": "password was changed, but tokens were not revoked"}`
+			err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if err != nil {
+				zapLogger.Errorf("can not write response: %s", err)
+			}
+			return
+		}
+		zapLogger.Infof("%d token families of user %d were revoked", familiesRevoked, userID)
+	}
+	message := `{"result":"success"}`
+	err = response.WriteResponse(w, []byte(message), http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("can not write response: %s", err)
+	}
+}
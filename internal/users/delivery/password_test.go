@@ -0,0 +1,167 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	apiKeyEntity "github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+	lockoutMock "github.com/ilyushkaaa/Filmoteka/internal/lockout/usecase/mock"
+	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
+	usecase2 "github.com/ilyushkaaa/Filmoteka/internal/session/usecase/mock"
+	tokenMock "github.com/ilyushkaaa/Filmoteka/internal/token/usecase/mock"
+	twoFactorMock "github.com/ilyushkaaa/Filmoteka/internal/twofactor/usecase/mock"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/usecase/mock"
+	logger2 "github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func TestChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	tokenTestUseCase := tokenMock.NewMockTokenUseCase(ctrl)
+	sessionHandler := NewUserHandler(testUseCase, sessionTestUseCase, nil, lockoutMock.NewMockLockoutUseCase(ctrl),
+		twoFactorMock.NewMockTwoFactorUseCase(ctrl), testCookieConfig)
+	tokenHandler := NewUserHandler(testUseCase, sessionTestUseCase, tokenTestUseCase, lockoutMock.NewMockLockoutUseCase(ctrl),
+		twoFactorMock.NewMockTwoFactorUseCase(ctrl), testCookieConfig)
+	body := `{"current_password": "oldpassword", "new_password": "newpassword"}`
+
+	withSession := func(ctx context.Context) context.Context {
+		ctx = context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+		return context.WithValue(ctx, middleware.MySessionIDKey, "current")
+	}
+	withToken := func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+	}
+
+	testCases := []struct {
+		handler    *UserHandler
+		ctx        func(ctx context.Context) context.Context
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{
+			handler:    sessionHandler,
+			ctx:        func(ctx context.Context) context.Context { return ctx },
+			body:       body,
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			handler: sessionHandler,
+			ctx: func(ctx context.Context) context.Context {
+				ctx = context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+				return context.WithValue(ctx, middleware.MyAPIKeyKey, &apiKeyEntity.APIKey{ID: 1, UserID: 1})
+			},
+			body:       body,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			handler:    sessionHandler,
+			ctx:        withSession,
+			body:       `{"current_password": "oldpassword"`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			handler:    sessionHandler,
+			ctx:        withSession,
+			body:       `{"current_password": "oldpassword", "new_password": "short"}`,
+			statusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			handler: sessionHandler,
+			ctx:     withSession,
+			body:    body,
+			setup: func() {
+				testUseCase.EXPECT().ChangePassword(gomock.Any(), uint64(1), "oldpassword", "newpassword").Return(usecase.ErrBadCredentials)
+			},
+			statusCode: http.StatusForbidden,
+		},
+		{
+			handler: sessionHandler,
+			ctx:     withSession,
+			body:    body,
+			setup: func() {
+				testUseCase.EXPECT().ChangePassword(gomock.Any(), uint64(1), "oldpassword", "newpassword").Return(usecase.ErrNoUser)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			handler: sessionHandler,
+			ctx:     withSession,
+			body:    body,
+			setup: func() {
+				testUseCase.EXPECT().ChangePassword(gomock.Any(), uint64(1), "oldpassword", "newpassword").Return(fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			handler: sessionHandler,
+			ctx:     withSession,
+			body:    body,
+			setup: func() {
+				testUseCase.EXPECT().ChangePassword(gomock.Any(), uint64(1), "oldpassword", "newpassword").Return(nil)
+				sessionTestUseCase.EXPECT().DeleteOtherUserSessions(gomock.Any(), uint64(1), "current").Return(0, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			handler: sessionHandler,
+			ctx:     withSession,
+			body:    body,
+			setup: func() {
+				testUseCase.EXPECT().ChangePassword(gomock.Any(), uint64(1), "oldpassword", "newpassword").Return(nil)
+				sessionTestUseCase.EXPECT().DeleteOtherUserSessions(gomock.Any(), uint64(1), "current").Return(2, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+		{
+			handler: tokenHandler,
+			ctx:     withToken,
+			body:    body,
+			setup: func() {
+				testUseCase.EXPECT().ChangePassword(gomock.Any(), uint64(1), "oldpassword", "newpassword").Return(nil)
+				sessionTestUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(0, nil)
+				tokenTestUseCase.EXPECT().RevokeUserTokens(gomock.Any(), uint64(1)).Return(0, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			handler: tokenHandler,
+			ctx:     withToken,
+			body:    body,
+			setup: func() {
+				testUseCase.EXPECT().ChangePassword(gomock.Any(), uint64(1), "oldpassword", "newpassword").Return(nil)
+				sessionTestUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(0, nil)
+				tokenTestUseCase.EXPECT().RevokeUserTokens(gomock.Any(), uint64(1)).Return(1, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodPost, "/me/password", strings.NewReader(tc.body))
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		tc.handler.ChangePassword(respWriter, request.WithContext(tc.ctx(ctx)))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	usecaseAPIKey "github.com/ilyushkaaa/Filmoteka/internal/apikeys/usecase"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
+	usecaseSession "github.com/ilyushkaaa/Filmoteka/internal/session/usecase"
+	usecaseToken "github.com/ilyushkaaa/Filmoteka/internal/token/usecase"
+	usecaseUser "github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/cookie"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/ilyushkaaa/Filmoteka/pkg/response"
+	"go.uber.org/zap"
+)
+
+type validatableRequest interface {
+	Validate() []string
+}
+
+type ProfileHandler struct {
+	userUseCase    usecaseUser.UserUseCase
+	sessionUseCase usecaseSession.SessionUseCase
+	tokenUseCase   usecaseToken.TokenUseCase
+	apiKeyUseCase  usecaseAPIKey.APIKeyUseCase
+	cookieConfig   *cookie.Config
+}
+
+func NewProfileHandler(userUseCase usecaseUser.UserUseCase, sessionUseCase usecaseSession.SessionUseCase,
+	tokenUseCase usecaseToken.TokenUseCase, apiKeyUseCase usecaseAPIKey.APIKeyUseCase, cookieConfig *cookie.Config) *ProfileHandler {
+	return &ProfileHandler{
+		userUseCase:    userUseCase,
+		sessionUseCase: sessionUseCase,
+		tokenUseCase:   tokenUseCase,
+		apiKeyUseCase:  apiKeyUseCase,
+		cookieConfig:   cookieConfig,
+	}
+}
+
+// GetMe @Summary Получить свой профиль
+// @Description Получить профиль текущего пользователя
+// @Tags users
+// @Produce json
+// @Security CookieAuth
+// @Success 200 {object} dto.ProfileResponse
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 404 {object} string "Пользователь не найден"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/me [get]
+func (ph *ProfileHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, ok := r.Context().Value(middleware.MyUserKey).(uint64)
+	if !ok {
+		zapLogger.Errorf("can not get user id from context")
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	profile, err := ph.userUseCase.GetProfile(r.Context(), userID)
+	if err != nil {
+		writeProfileError(zapLogger, w, userID, err)
+		return
+	}
+	writeJSON(zapLogger, w, dto.NewProfileResponse(profile))
+}
+
+// UpdateMe @Summary Изменить свой профиль
+// @Description Изменить отображаемое имя, email, аватар, предпочитаемый язык или имя пользователя. Переданные поля заменяются, отсутствующие не меняются, пустая строка очищает значение
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param body body dto.ProfileUpdate true "Изменяемые поля профиля"
+// @Success 200 {object} dto.ProfileResponse
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Аутентификация API ключом"
+// @Failure 404 {object} string "Пользователь не найден"
+// @Failure 409 {object} string "Имя пользователя уже занято"
+// @Failure 422 {object} string "Данные не прошли валидацию"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/me [patch]
+func (ph *ProfileHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(zapLogger, w, r, false)
+	if err != nil {
+		return
+	}
+	profileUpdate := &dto.ProfileUpdate{}
+	if err = decodeRequest(zapLogger, w, r, profileUpdate); err != nil {
+		return
+	}
+	profile, err := ph.userUseCase.UpdateProfile(r.Context(), userID, profileUpdate.ToEntity())
+	if errors.Is(err, usecaseUser.ErrUserAlreadyExists) {
+		zapLogger.Errorf("user %d tried to take username %s that is already taken", userID, *profileUpdate.Username)
+		errText := fmt.Sprintf(`{"error": "user with username %s already exists"}`, *profileUpdate.Username)
+		err = response.WriteResponse(w, []byte(errText), http.StatusConflict)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		writeProfileError(zapLogger, w, userID, err)
+		return
+	}
+	zapLogger.Infof("user %d updated profile", userID)
+	writeJSON(zapLogger, w, dto.NewProfileResponse(profile))
+}
+
+// DeleteMe @Summary Удалить свою учетную запись
+// @Description Удалить учетную запись текущего пользователя после подтверждения паролем. Вместе с ней удаляются все связанные данные: API ключи, настройки второго фактора, токены сброса пароля, сессии и refresh токены
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param body body dto.AccountDeleteRequest true "Текущий пароль"
+// @Success 200 {object} string "Учетная запись удалена"
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Неверный пароль или аутентификация API ключом"
+// @Failure 404 {object} string "Пользователь не найден"
+// @Failure 409 {object} string "Нельзя удалить последнего администратора"
+// @Failure 422 {object} string "Данные не прошли валидацию"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/me [delete]
+func (ph *ProfileHandler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(zapLogger, w, r, false)
+	if err != nil {
+		return
+	}
+	deleteRequest := &dto.AccountDeleteRequest{}
+	if err = decodeRequest(zapLogger, w, r, deleteRequest); err != nil {
+		return
+	}
+	err = ph.userUseCase.DeleteAccount(r.Context(), userID, deleteRequest.Password)
+	if errors.Is(err, usecaseUser.ErrBadCredentials) {
+		zapLogger.Errorf("user %d sent wrong password for account deletion", userID)
+		err = response.WriteResponse(w, []byte(`{"error": "password is wrong"}`), http.StatusForbidden)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if errors.Is(err, usecaseUser.ErrLastAdmin) {
+		zapLogger.Errorf("user %d is the last active admin and can not be deleted", userID)
+		errText := `{"error": "the last active admin can not be deleted"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusConflict)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		writeProfileError(zapLogger, w, userID, err)
+		return
+	}
+	zapLogger.Infof("user %d deleted account", userID)
+	// строки в PostgreSQL удаляются каскадно, а сессии и refresh токены хранятся отдельно
+	if _, err = ph.sessionUseCase.DeleteUserSessions(r.Context(), userID); err != nil {
+		zapLogger.Errorf("error in deleting sessions of deleted user %d: %s", userID, err)
+	}
+	if ph.tokenUseCase != nil {
+		if _, err = ph.tokenUseCase.RevokeUserTokens(r.Context(), userID); err != nil {
+			zapLogger.Errorf("error in revoking tokens of deleted user %d: %s", userID, err)
+		}
+	}
+	ph.cookieConfig.ClearSessionCookies(w)
+	message := `{"result":"success"}`
+	err = response.WriteResponse(w, []byte(message), http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("can not write response: %s", err)
+	}
+}
+
+// ExportMe @Summary Выгрузить свои данные
+// @Description Выгрузить все данные текущего пользователя (профиль, сессии, API ключи) одним JSON файлом
+// @Tags users
+// @Produce json
+// @Security CookieAuth
+// @Success 200 {object} dto.AccountExport
+// @Failure 401 {object} string "Пользователь не аутентифицирован"
+// @Failure 403 {object} string "Аутентификация API ключом"
+// @Failure 404 {object} string "Пользователь не найден"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/me/export [get]
+func (ph *ProfileHandler) ExportMe(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(zapLogger, w, r, false)
+	if err != nil {
+		return
+	}
+	profile, err := ph.userUseCase.GetProfile(r.Context(), userID)
+	if err != nil {
+		writeProfileError(zapLogger, w, userID, err)
+		return
+	}
+	sessions, err := ph.sessionUseCase.GetUserSessions(r.Context(), userID)
+	if err != nil {
+		writeProfileError(zapLogger, w, userID, err)
+		return
+	}
+	apiKeys, err := ph.apiKeyUseCase.GetUserAPIKeys(r.Context(), userID)
+	if err != nil {
+		writeProfileError(zapLogger, w, userID, err)
+		return
+	}
+	sessionID, _ := r.Context().Value(middleware.MySessionIDKey).(string)
+	export := dto.AccountExport{
+		ExportedAt: time.Now().UTC(),
+		Profile:    dto.NewProfileResponse(profile),
+		Sessions:   dto.NewSessionInfos(sessions, sessionID),
+		APIKeys:    dto.NewAPIKeyInfos(apiKeys),
+	}
+	zapLogger.Infof("user %d exported account data", userID)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="filmoteka-export-%d.json"`, userID))
+	writeJSON(zapLogger, w, export)
+}
+
+func writeProfileError(zapLogger *zap.SugaredLogger, w http.ResponseWriter, userID uint64, err error) {
+	if errors.Is(err, usecaseUser.ErrNoUser) {
+		zapLogger.Errorf("user %d is not found", userID)
+		err = response.WriteResponse(w, []byte(`{"error": "user not found"}`), http.StatusNotFound)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	zapLogger.Errorf("error in processing profile of user %d: %s", userID, err)
+	err = response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+	if err != nil {
+		zapLogger.Errorf("can not write response: %s", err)
+	}
+}
+
+func writeJSON(zapLogger *zap.SugaredLogger, w http.ResponseWriter, data any) {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		zapLogger.Errorf("error in marshalling response: %s", err)
+		err = response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	err = response.WriteResponse(w, dataJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("can not write response: %s", err)
+	}
+}
+
+func decodeRequest(zapLogger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request, request validatableRequest) error {
+	rBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		zapLogger.Errorf("error in reading request body: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in reading request body: %s"}`, err)
+		writeErr := response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if writeErr != nil {
+			zapLogger.Errorf("can not write response: %s", writeErr)
+		}
+		return err
+	}
+	err = json.Unmarshal(rBody, request)
+	if err != nil {
+		zapLogger.Errorf("error in unmarshalling request: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in decoding request: %s"}`, err)
+		writeErr := response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if writeErr != nil {
+			zapLogger.Errorf("can not write response: %s", writeErr)
+		}
+		return err
+	}
+	validationErrors := request.Validate()
+	if len(validationErrors) != 0 {
+		zapLogger.Errorf("request did not pass validation: %v", validationErrors)
+		errorsJSON, err := json.Marshal(validationErrors)
+		if err != nil {
+			zapLogger.Errorf("error in marshalling validation errors: %s", err)
+			writeErr := response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+			if writeErr != nil {
+				zapLogger.Errorf("can not write response: %s", writeErr)
+			}
+			return err
+		}
+		err = response.WriteResponse(w, errorsJSON, http.StatusUnprocessableEntity)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return fmt.Errorf("request did not pass validation")
+	}
+	return nil
+}
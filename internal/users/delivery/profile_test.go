@@ -0,0 +1,294 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	apiKeyEntity "github.com/ilyushkaaa/Filmoteka/internal/apikeys/entity"
+	apiKeyMock "github.com/ilyushkaaa/Filmoteka/internal/apikeys/usecase/mock"
+	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
+	sessionEntity "github.com/ilyushkaaa/Filmoteka/internal/session/entity"
+	usecase2 "github.com/ilyushkaaa/Filmoteka/internal/session/usecase/mock"
+	tokenMock "github.com/ilyushkaaa/Filmoteka/internal/token/usecase/mock"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/usecase/mock"
+	logger2 "github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func withUser(ctx context.Context) context.Context {
+	return context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+}
+
+func withAPIKey(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, middleware.MyUserKey, uint64(1))
+	return context.WithValue(ctx, middleware.MyAPIKeyKey, &apiKeyEntity.APIKey{ID: 1, UserID: 1})
+}
+
+func serveProfile(handler http.HandlerFunc, method, body string, ctxFunc func(ctx context.Context) context.Context) *http.Response {
+	request := httptest.NewRequest(method, "/api/v1/me", strings.NewReader(body))
+	ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, zap.NewNop().Sugar())
+	respWriter := httptest.NewRecorder()
+	handler(respWriter, request.WithContext(ctxFunc(ctx)))
+	return respWriter.Result()
+}
+
+func TestGetMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	handler := NewProfileHandler(testUseCase, usecase2.NewMockSessionUseCase(ctrl), nil,
+		apiKeyMock.NewMockAPIKeyUseCase(ctrl), testCookieConfig)
+	profile := &entity.Profile{UserID: 1, Username: "aaa", Role: entity.RoleDefault, DisplayName: "A"}
+
+	testCases := []struct {
+		ctx        func(ctx context.Context) context.Context
+		setup      func()
+		statusCode int
+	}{
+		{
+			ctx:        func(ctx context.Context) context.Context { return ctx },
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			ctx: withUser,
+			setup: func() {
+				testUseCase.EXPECT().GetProfile(gomock.Any(), uint64(1)).Return(nil, usecase.ErrNoUser)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			ctx: withUser,
+			setup: func() {
+				testUseCase.EXPECT().GetProfile(gomock.Any(), uint64(1)).Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			ctx: withAPIKey,
+			setup: func() {
+				testUseCase.EXPECT().GetProfile(gomock.Any(), uint64(1)).Return(profile, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		resp := serveProfile(handler.GetMe, http.MethodGet, "", tc.ctx)
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestUpdateMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	handler := NewProfileHandler(testUseCase, usecase2.NewMockSessionUseCase(ctrl), nil,
+		apiKeyMock.NewMockAPIKeyUseCase(ctrl), testCookieConfig)
+	username, displayName := "bbb", "B"
+	profile := &entity.Profile{UserID: 1, Username: "bbb", Role: entity.RoleDefault, DisplayName: "B"}
+
+	testCases := []struct {
+		ctx        func(ctx context.Context) context.Context
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{
+			ctx:        withAPIKey,
+			body:       `{"display_name": "B"}`,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			ctx:        withUser,
+			body:       `{"display_name": "B"`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			ctx:        withUser,
+			body:       `{"username": ""}`,
+			statusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			ctx:        withUser,
+			body:       `{"avatar_url": "ftp://example.com/a.png", "preferred_language": "english"}`,
+			statusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			ctx:  withUser,
+			body: `{"username": "bbb"}`,
+			setup: func() {
+				testUseCase.EXPECT().UpdateProfile(gomock.Any(), uint64(1), &entity.ProfileUpdate{Username: &username}).
+					Return(nil, usecase.ErrUserAlreadyExists)
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			ctx:  withUser,
+			body: `{"display_name": "B"}`,
+			setup: func() {
+				testUseCase.EXPECT().UpdateProfile(gomock.Any(), uint64(1), &entity.ProfileUpdate{DisplayName: &displayName}).
+					Return(nil, usecase.ErrNoUser)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			ctx:  withUser,
+			body: `{"username": "bbb", "display_name": "B"}`,
+			setup: func() {
+				testUseCase.EXPECT().UpdateProfile(gomock.Any(), uint64(1), &entity.ProfileUpdate{Username: &username, DisplayName: &displayName}).
+					Return(profile, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		resp := serveProfile(handler.UpdateMe, http.MethodPatch, tc.body, tc.ctx)
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestDeleteMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	tokenTestUseCase := tokenMock.NewMockTokenUseCase(ctrl)
+	handler := NewProfileHandler(testUseCase, sessionTestUseCase, tokenTestUseCase,
+		apiKeyMock.NewMockAPIKeyUseCase(ctrl), testCookieConfig)
+	body := `{"password": "password"}`
+
+	testCases := []struct {
+		ctx        func(ctx context.Context) context.Context
+		body       string
+		setup      func()
+		statusCode int
+	}{
+		{
+			ctx:        withAPIKey,
+			body:       body,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			ctx:        withUser,
+			body:       `{}`,
+			statusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			ctx:  withUser,
+			body: body,
+			setup: func() {
+				testUseCase.EXPECT().DeleteAccount(gomock.Any(), uint64(1), "password").Return(usecase.ErrBadCredentials)
+			},
+			statusCode: http.StatusForbidden,
+		},
+		{
+			ctx:  withUser,
+			body: body,
+			setup: func() {
+				testUseCase.EXPECT().DeleteAccount(gomock.Any(), uint64(1), "password").Return(usecase.ErrLastAdmin)
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			ctx:  withUser,
+			body: body,
+			setup: func() {
+				testUseCase.EXPECT().DeleteAccount(gomock.Any(), uint64(1), "password").Return(fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			ctx:  withUser,
+			body: body,
+			setup: func() {
+				testUseCase.EXPECT().DeleteAccount(gomock.Any(), uint64(1), "password").Return(nil)
+				sessionTestUseCase.EXPECT().DeleteUserSessions(gomock.Any(), uint64(1)).Return(2, nil)
+				tokenTestUseCase.EXPECT().RevokeUserTokens(gomock.Any(), uint64(1)).Return(1, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		resp := serveProfile(handler.DeleteMe, http.MethodDelete, tc.body, tc.ctx)
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestExportMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	apiKeyTestUseCase := apiKeyMock.NewMockAPIKeyUseCase(ctrl)
+	handler := NewProfileHandler(testUseCase, sessionTestUseCase, nil, apiKeyTestUseCase, testCookieConfig)
+	profile := &entity.Profile{UserID: 1, Username: "aaa", Role: entity.RoleDefault, CreatedAt: time.Now()}
+	sessions := []sessionEntity.Session{{ID: "current", UserID: 1}, {ID: "other", UserID: 1}}
+	apiKeys := []apiKeyEntity.APIKey{{ID: 3, UserID: 1, Name: "ci", Prefix: "fk_abc", Scopes: []string{"film:read"}}}
+
+	resp := serveProfile(handler.ExportMe, http.MethodGet, "", withAPIKey)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	testUseCase.EXPECT().GetProfile(gomock.Any(), uint64(1)).Return(profile, nil)
+	sessionTestUseCase.EXPECT().GetUserSessions(gomock.Any(), uint64(1)).Return(nil, fmt.Errorf("error"))
+	resp = serveProfile(handler.ExportMe, http.MethodGet, "", withUser)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	testUseCase.EXPECT().GetProfile(gomock.Any(), uint64(1)).Return(profile, nil)
+	sessionTestUseCase.EXPECT().GetUserSessions(gomock.Any(), uint64(1)).Return(sessions, nil)
+	apiKeyTestUseCase.EXPECT().GetUserAPIKeys(gomock.Any(), uint64(1)).Return(apiKeys, nil)
+	resp = serveProfile(handler.ExportMe, http.MethodGet, "", func(ctx context.Context) context.Context {
+		return context.WithValue(withUser(ctx), middleware.MySessionIDKey, "current")
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `attachment; filename="filmoteka-export-1.json"`, resp.Header.Get("Content-Disposition"))
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	export := dto.AccountExport{}
+	assert.NoError(t, json.Unmarshal(body, &export))
+	assert.Equal(t, "aaa", export.Profile.Username)
+	assert.Len(t, export.Sessions, 2)
+	assert.True(t, export.Sessions[0].IsCurrent)
+	assert.Equal(t, []dto.APIKeyInfo{{ID: 3, Name: "ci", Prefix: "fk_abc", Scopes: []string{"film:read"}}}, export.APIKeys)
+}
@@ -1,43 +1,66 @@
 package delivery
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/ilyushkaaa/Filmoteka/internal/dto"
+	usecaseLockout "github.com/ilyushkaaa/Filmoteka/internal/lockout/usecase"
 	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
 	usecaseSession "github.com/ilyushkaaa/Filmoteka/internal/session/usecase"
+	usecaseToken "github.com/ilyushkaaa/Filmoteka/internal/token/usecase"
+	twoFactorEntity "github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+	usecaseTwoFactor "github.com/ilyushkaaa/Filmoteka/internal/twofactor/usecase"
 	"github.com/ilyushkaaa/Filmoteka/internal/users/entity"
 	usecaseUser "github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
+	"github.com/ilyushkaaa/Filmoteka/pkg/client_ip"
+	"github.com/ilyushkaaa/Filmoteka/pkg/cookie"
 	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
 	"github.com/ilyushkaaa/Filmoteka/pkg/response"
 	"go.uber.org/zap"
 )
 
 type UserHandler struct {
-	userUseCase    usecaseUser.UserUseCase
-	sessionUseCase usecaseSession.SessionUseCase
+	userUseCase      usecaseUser.UserUseCase
+	sessionUseCase   usecaseSession.SessionUseCase
+	tokenUseCase     usecaseToken.TokenUseCase
+	lockoutUseCase   usecaseLockout.LockoutUseCase
+	twoFactorUseCase usecaseTwoFactor.TwoFactorUseCase
+	cookieConfig     *cookie.Config
 }
 
-func NewUserHandler(userUseCase usecaseUser.UserUseCase, sessionUseCase usecaseSession.SessionUseCase) *UserHandler {
+func NewUserHandler(userUseCase usecaseUser.UserUseCase, sessionUseCase usecaseSession.SessionUseCase,
+	tokenUseCase usecaseToken.TokenUseCase, lockoutUseCase usecaseLockout.LockoutUseCase,
+	twoFactorUseCase usecaseTwoFactor.TwoFactorUseCase, cookieConfig *cookie.Config) *UserHandler {
 	return &UserHandler{
-		userUseCase:    userUseCase,
-		sessionUseCase: sessionUseCase,
+		userUseCase:      userUseCase,
+		sessionUseCase:   sessionUseCase,
+		tokenUseCase:     tokenUseCase,
+		lockoutUseCase:   lockoutUseCase,
+		twoFactorUseCase: twoFactorUseCase,
+		cookieConfig:     cookieConfig,
 	}
 }
 
 // Login @Summary Вход пользователя
-// @Description Данный метод позволяет пользователям войти в систему, используя свои учетные данные.
+// @Description Данный метод позволяет пользователям войти в систему, используя свои учетные данные. При аутентификации по сессиям устанавливает cookie session_id и csrf_token. После серии неудачных попыток вход для пользователя или IP адреса временно блокируется. Если пользователю нужен второй фактор, возвращается незавершенный вход (202), который завершается через /api/v1/login/totp.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param body body dto.AuthRequest true "Данные пользователя для входа"
-// @Success 200 {object} dto.AuthResponse "Успешный вход, получен идентификатор сессии"
+// @Success 200 {object} dto.AuthResponse "Успешный вход, получен идентификатор сессии (или dto.TokenResponse при аутентификации по токенам)"
+// @Success 202 {object} dto.LoginChallengeResponse "Пароль верный, требуется код второго фактора"
 // @Failure 401 {object} string "Неверные учетные данные"
+// @Failure 403 {object} string "Пользователь заблокирован"
+// @Failure 429 {object} string "Слишком много неудачных попыток входа, повторить можно через Retry-After секунд"
 // @Failure 500 {object} string "Внутренняя ошибка сервера"
 // @Router /api/v1/login [post]
 func (uh *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -55,16 +78,48 @@ func (uh *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	if err != nil || userFromLoginForm == nil {
 		return
 	}
-	loggedInUser, err := uh.userUseCase.Login(userFromLoginForm.Username, userFromLoginForm.Password)
+	clientIP := client_ip.FromRequest(r)
+	retryAfter, err := uh.lockoutUseCase.CheckLogin(r.Context(), userFromLoginForm.Username, clientIP)
+	if err != nil {
+		zapLogger.Errorf("error in checking login lockout: %s", err)
+		err = response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if retryAfter > 0 {
+		zapLogger.Errorf("login of user %s from %s was rejected, it is locked for %s",
+			userFromLoginForm.Username, clientIP, retryAfter)
+		writeTooManyAttempts(zapLogger, w, retryAfter)
+		return
+	}
+	loggedInUser, err := uh.userUseCase.Login(r.Context(), userFromLoginForm.Username, userFromLoginForm.Password)
 
 	if errors.Is(err, usecaseUser.ErrBadCredentials) {
 		zapLogger.Errorf("bad credentials were eneterd")
+		lockDuration, lockoutErr := uh.lockoutUseCase.LoginFailed(r.Context(), userFromLoginForm.Username, clientIP)
+		if lockoutErr != nil {
+			zapLogger.Errorf("error in registering failed login: %s", lockoutErr)
+		}
+		if lockDuration > 0 {
+			zapLogger.Infof("login of user %s from %s is locked for %s after repeated failures",
+				userFromLoginForm.Username, clientIP, lockDuration)
+		}
 		err = response.WriteResponse(w, []byte(`{"error": "bad username or password"}`), http.StatusUnauthorized)
 		if err != nil {
 			zapLogger.Errorf("can not write response: %s", err)
 		}
 		return
 	}
+	if errors.Is(err, usecaseUser.ErrUserDisabled) {
+		zapLogger.Errorf("disabled user %s tried to log in", userFromLoginForm.Username)
+		err = response.WriteResponse(w, []byte(`{"error": "user is disabled"}`), http.StatusForbidden)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
 	if err != nil {
 		zapLogger.Errorf("internal error in logging in user: %s", err)
 		errText := fmt.Sprintf(`{"error": "error in getting user by login and password: %s"}`, err)
@@ -74,18 +129,153 @@ func (uh *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	challenge, err := uh.twoFactorUseCase.StartLogin(r.Context(), loggedInUser)
+	if err != nil {
+		zapLogger.Errorf("error in starting two-factor login: %s", err)
+		err = response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if challenge != nil {
+		// счетчик неудач сбрасывается только после второго фактора, чтобы коды нельзя было перебирать
+		uh.writeLoginChallenge(w, challenge, zapLogger)
+		return
+	}
+	err = uh.lockoutUseCase.LoginSucceeded(r.Context(), userFromLoginForm.Username)
+	if err != nil {
+		zapLogger.Errorf("error in resetting failed logins of user %d: %s", loggedInUser.ID, err)
+	}
+
+	uh.handleAuthenticated(w, r, loggedInUser, nil, zapLogger)
 
-	uh.HandleGetSessionID(w, loggedInUser, zapLogger)
+}
+
+// LoginTOTP @Summary Завершение входа вторым фактором
+// @Description Завершить вход кодом из приложения-аутентификатора или одноразовым кодом восстановления. Если TOTP подключался во время входа, в ответе возвращаются коды восстановления
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param body body dto.LoginChallengeRequest true "Идентификатор незавершенного входа и код"
+// @Success 200 {object} dto.AuthResponse "Успешный вход, получен идентификатор сессии (или dto.TokenResponse при аутентификации по токенам)"
+// @Failure 400 {object} string "Ошибка в запросе"
+// @Failure 401 {object} string "Неверный код, вход не найден или истек"
+// @Failure 403 {object} string "Роль пользователя требует подключить TOTP"
+// @Failure 422 {object} string "Данные не прошли валидацию"
+// @Failure 429 {object} string "Слишком много неудачных попыток входа, повторить можно через Retry-After секунд"
+// @Failure 500 {object} string "Внутренняя ошибка сервера"
+// @Router /api/v1/login/totp [post]
+func (uh *UserHandler) LoginTOTP(w http.ResponseWriter, r *http.Request) {
+	zapLogger, err := logger.GetLoggerFromContext(r.Context())
+	if err != nil {
+		log.Printf("can not get logger from context: %s", err)
+		err = response.WriteResponse(w, []byte(`"error":"internal error"`), http.StatusInternalServerError)
+		if err != nil {
+			log.Printf("can not write response: %s", err)
+		}
+		return
+	}
+	challengeRequest, err := getLoginChallengeRequest(zapLogger, w, r)
+	if err != nil {
+		return
+	}
+	challenge, err := uh.twoFactorUseCase.GetChallenge(r.Context(), challengeRequest.ChallengeID)
+	if err != nil {
+		zapLogger.Errorf("error in getting login challenge: %s", err)
+		err = response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if challenge == nil {
+		zapLogger.Errorf("login challenge %s is not found", challengeRequest.ChallengeID)
+		errText := fmt.Sprintf(`{"error": "%s"}`, usecaseTwoFactor.ErrBadChallenge)
+		err = response.WriteResponse(w, []byte(errText), http.StatusUnauthorized)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	clientIP := client_ip.FromRequest(r)
+	retryAfter, err := uh.lockoutUseCase.CheckLogin(r.Context(), challenge.Username, clientIP)
+	if err != nil {
+		zapLogger.Errorf("error in checking login lockout: %s", err)
+		err = response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if retryAfter > 0 {
+		zapLogger.Errorf("second factor of user %s from %s was rejected, login is locked for %s",
+			challenge.Username, clientIP, retryAfter)
+		writeTooManyAttempts(zapLogger, w, retryAfter)
+		return
+	}
+	loginResult, err := uh.twoFactorUseCase.CompleteLogin(r.Context(), challenge, challengeRequest.Code)
+	if errors.Is(err, usecaseTwoFactor.ErrBadCode) {
+		zapLogger.Errorf("bad second factor code was entered for user %s", challenge.Username)
+		lockDuration, lockoutErr := uh.lockoutUseCase.LoginFailed(r.Context(), challenge.Username, clientIP)
+		if lockoutErr != nil {
+			zapLogger.Errorf("error in registering failed login: %s", lockoutErr)
+		}
+		if lockDuration > 0 {
+			zapLogger.Infof("login of user %s from %s is locked for %s after repeated failures",
+				challenge.Username, clientIP, lockDuration)
+		}
+		errText := fmt.Sprintf(`{"error": "%s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusUnauthorized)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if errors.Is(err, usecaseTwoFactor.ErrBadChallenge) || errors.Is(err, usecaseTwoFactor.ErrTooManyAttempts) {
+		zapLogger.Errorf("login challenge of user %s was rejected: %s", challenge.Username, err)
+		errText := fmt.Sprintf(`{"error": "%s, log in again"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusUnauthorized)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if errors.Is(err, usecaseTwoFactor.ErrEnrollmentRequired) {
+		zapLogger.Errorf("user %s has to enroll totp before login", challenge.Username)
+		errText := fmt.Sprintf(`{"error": "%s"}`, err)
+		err = response.WriteResponse(w, []byte(errText), http.StatusForbidden)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if err != nil {
+		zapLogger.Errorf("error in completing two-factor login: %s", err)
+		err = response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	if len(loginResult.RecoveryCodes) != 0 {
+		zapLogger.Infof("totp was enabled for user %d during login", loginResult.User.ID)
+	}
+	err = uh.lockoutUseCase.LoginSucceeded(r.Context(), challenge.Username)
+	if err != nil {
+		zapLogger.Errorf("error in resetting failed logins of user %d: %s", loginResult.User.ID, err)
+	}
 
+	uh.handleAuthenticated(w, r, loginResult.User, loginResult.RecoveryCodes, zapLogger)
 }
 
 // Register @Summary Регистрация пользователя
-// @Description Данный метод позволяет новым пользователям зарегистрироваться в системе.
+// @Description Данный метод позволяет новым пользователям зарегистрироваться в системе. При аутентификации по сессиям устанавливает cookie session_id и csrf_token.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param body body dto.AuthRequest true "Данные нового пользователя"
-// @Success 200 {object} dto.AuthResponse "Успешная регистрация, получен идентификатор сессии"
+// @Success 200 {object} dto.AuthResponse "Успешная регистрация, получен идентификатор сессии (или dto.TokenResponse при аутентификации по токенам)"
 // @Failure 422 {object} string "Пользователь уже существует"
 // @Failure 500 {object} string "Внутренняя ошибка сервера"
 // @Router /api/v1/register [post]
@@ -104,7 +294,8 @@ func (uh *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	if err != nil || userFromLoginForm == nil {
 		return
 	}
-	newUser, err := uh.userUseCase.Register(userFromLoginForm.Username, userFromLoginForm.Password)
+	newUser, err := uh.userUseCase.Register(r.Context(), userFromLoginForm.Username, userFromLoginForm.Password,
+		userFromLoginForm.Email)
 	if errors.Is(err, usecaseUser.ErrUserAlreadyExists) {
 		zapLogger.Errorf("user with username %s alredy exists", userFromLoginForm.Username)
 		err = response.WriteResponse(w, []byte(`{"error": "user already exists"}`), http.StatusUnprocessableEntity)
@@ -122,11 +313,69 @@ func (uh *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	uh.HandleGetSessionID(w, newUser, zapLogger)
+	uh.handleAuthenticated(w, r, newUser, nil, zapLogger)
+}
+
+func (uh *UserHandler) handleAuthenticated(w http.ResponseWriter, r *http.Request, user *entity.User,
+	recoveryCodes []string, zapLogger *zap.SugaredLogger) {
+	if uh.tokenUseCase != nil {
+		uh.HandleIssueTokens(r.Context(), w, user, recoveryCodes, zapLogger)
+		return
+	}
+	uh.HandleGetSessionID(w, r, user, recoveryCodes, zapLogger)
 }
 
-func (uh *UserHandler) HandleGetSessionID(w http.ResponseWriter, newUser *entity.User, zapLogger *zap.SugaredLogger) {
-	sessionID, err := uh.sessionUseCase.CreateSession(newUser.ID)
+func (uh *UserHandler) writeLoginChallenge(w http.ResponseWriter, challenge *twoFactorEntity.LoginChallenge, zapLogger *zap.SugaredLogger) {
+	challengeJSON, err := json.Marshal(dto.NewLoginChallengeResponse(challenge))
+	if err != nil {
+		zapLogger.Errorf("error in marshalling login challenge: %s", err)
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	zapLogger.Infof("login of user %d is waiting for second factor", challenge.UserID)
+	err = response.WriteResponse(w, challengeJSON, http.StatusAccepted)
+	if err != nil {
+		zapLogger.Errorf("can not write response: %s", err)
+	}
+}
+
+func (uh *UserHandler) HandleIssueTokens(ctx context.Context, w http.ResponseWriter, user *entity.User, recoveryCodes []string, zapLogger *zap.SugaredLogger) {
+	tokens, err := uh.tokenUseCase.IssueTokens(ctx, user)
+	if err != nil {
+		zapLogger.Errorf("internal error in issuing tokens: %s", err)
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	tokenResponse := dto.NewTokenResponse(tokens)
+	tokenResponse.RecoveryCodes = recoveryCodes
+	tokensJSON, err := json.Marshal(tokenResponse)
+	if err != nil {
+		zapLogger.Errorf("error in marshalling tokens: %s", err)
+		errText := `{"error": "internal error"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return
+	}
+	zapLogger.Infof("tokens were issued for user %d", user.ID)
+	err = response.WriteResponse(w, tokensJSON, http.StatusOK)
+	if err != nil {
+		zapLogger.Errorf("can not write response: %s", err)
+	}
+}
+
+func (uh *UserHandler) HandleGetSessionID(w http.ResponseWriter, r *http.Request, newUser *entity.User,
+	recoveryCodes []string, zapLogger *zap.SugaredLogger) {
+	session, err := uh.sessionUseCase.CreateSession(r.Context(), newUser.ID, client_ip.FromRequest(r), r.UserAgent())
 	if err != nil {
 		zapLogger.Errorf("internal error in getting session id: %s", err)
 		errText := `{"error": "internal error"}`
@@ -137,7 +386,8 @@ func (uh *UserHandler) HandleGetSessionID(w http.ResponseWriter, newUser *entity
 		return
 	}
 	resp := dto.AuthResponse{
-		SessionID: sessionID,
+		SessionID:     session.ID,
+		RecoveryCodes: recoveryCodes,
 	}
 	sessionIDJSON, err := json.Marshal(&resp)
 	if err != nil {
@@ -149,7 +399,8 @@ func (uh *UserHandler) HandleGetSessionID(w http.ResponseWriter, newUser *entity
 		}
 		return
 	}
-	zapLogger.Infof("new session id: %s", sessionID)
+	zapLogger.Infof("new session was created for user %d", newUser.ID)
+	uh.cookieConfig.SetSessionCookies(w, session.ID, session.ExpiresAt)
 	err = response.WriteResponse(w, sessionIDJSON, http.StatusOK)
 	if err != nil {
 		zapLogger.Errorf("can not write response: %s", err)
@@ -202,12 +453,13 @@ func checkRequestFormat(zapLogger *zap.SugaredLogger, w http.ResponseWriter, r *
 }
 
 // Logout @Summary Выход пользователя
-// @Description Данный метод позволяет пользователям выйти из системы, завершая сеанс.
+// @Description Данный метод позволяет пользователям выйти из системы, завершая сеанс и удаляя cookie сессии.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Security CookieAuth
 // @Success 200 {object} string "Успешный выход"
+// @Failure 400 {object} string "Запрос аутентифицирован не сессией, а токеном"
 // @Failure 401 {object} string "Неверный или отсутствующий токен аутентификации"
 // @Failure 404 {object} string "Сеанс не найден"
 // @Failure 500 {object} string "Внутренняя ошибка сервера"
@@ -225,14 +477,15 @@ func (uh *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	}
 	sessionID, ok := ctx.Value(middleware.MySessionIDKey).(string)
 	if !ok {
-		zapLogger.Errorf("can not get sesssion id from context: %s", err)
-		err = response.WriteResponse(w, []byte(`{"error": "internal error"}`), http.StatusInternalServerError)
+		zapLogger.Errorf("logout was called without session, probably with bearer token")
+		errText := `{"error": "logout is available only for session authentication, revoke refresh token instead"}`
+		err = response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
 		if err != nil {
 			zapLogger.Errorf("can not write response: %s", err)
 		}
 		return
 	}
-	isDeleted, err := uh.sessionUseCase.DeleteSession(sessionID)
+	isDeleted, err := uh.sessionUseCase.DeleteSession(r.Context(), sessionID)
 	if err != nil {
 		zapLogger.Errorf("error in deleting session: %s", err)
 		errText := `{"error": "internal error"}`
@@ -251,9 +504,64 @@ func (uh *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	uh.cookieConfig.ClearSessionCookies(w)
 	message := `{"result":"success"}`
 	err = response.WriteResponse(w, []byte(message), http.StatusOK)
 	if err != nil {
 		zapLogger.Errorf("can not write response: %s", err)
 	}
 }
+
+func getLoginChallengeRequest(zapLogger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request) (*dto.LoginChallengeRequest, error) {
+	rBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		zapLogger.Errorf("error in reading request body: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in reading request body: %s"}`, err)
+		writeErr := response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if writeErr != nil {
+			zapLogger.Errorf("can not write response: %s", writeErr)
+		}
+		return nil, err
+	}
+	challengeRequest := &dto.LoginChallengeRequest{}
+	err = json.Unmarshal(rBody, challengeRequest)
+	if err != nil {
+		zapLogger.Errorf("error in unmarshalling login challenge request: %s", err)
+		errText := fmt.Sprintf(`{"error": "error in decoding login challenge request: %s"}`, err)
+		writeErr := response.WriteResponse(w, []byte(errText), http.StatusBadRequest)
+		if writeErr != nil {
+			zapLogger.Errorf("can not write response: %s", writeErr)
+		}
+		return nil, err
+	}
+	validationErrors := challengeRequest.Validate()
+	if len(validationErrors) != 0 {
+		zapLogger.Errorf("login challenge request did not pass validation: %v", validationErrors)
+		errorsJSON, err := json.Marshal(validationErrors)
+		if err != nil {
+			zapLogger.Errorf("error in marshalling validation errors: %s", err)
+			errText := `{"error": "internal error"}`
+			writeErr := response.WriteResponse(w, []byte(errText), http.StatusInternalServerError)
+			if writeErr != nil {
+				zapLogger.Errorf("can not write response: %s", writeErr)
+			}
+			return nil, err
+		}
+		err = response.WriteResponse(w, errorsJSON, http.StatusUnprocessableEntity)
+		if err != nil {
+			zapLogger.Errorf("can not write response: %s", err)
+		}
+		return nil, fmt.Errorf("login challenge request did not pass validation")
+	}
+	return challengeRequest, nil
+}
+
+func writeTooManyAttempts(zapLogger *zap.SugaredLogger, w http.ResponseWriter, retryAfter time.Duration) {
+	retryAfterSeconds := int(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+	errText := fmt.Sprintf(`{"error": "too many failed login attempts, try again in %d seconds"}`, retryAfterSeconds)
+	err := response.WriteResponse(w, []byte(errText), http.StatusTooManyRequests)
+	if err != nil {
+		zapLogger.Errorf("can not write response: %s", err)
+	}
+}
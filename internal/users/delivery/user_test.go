@@ -8,16 +8,28 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	lockoutMock "github.com/ilyushkaaa/Filmoteka/internal/lockout/usecase/mock"
+	"github.com/ilyushkaaa/Filmoteka/internal/middleware"
+	sessionEntity "github.com/ilyushkaaa/Filmoteka/internal/session/entity"
 	usecase2 "github.com/ilyushkaaa/Filmoteka/internal/session/usecase/mock"
+	tokenEntity "github.com/ilyushkaaa/Filmoteka/internal/token/entity"
+	tokenMock "github.com/ilyushkaaa/Filmoteka/internal/token/usecase/mock"
+	twoFactorEntity "github.com/ilyushkaaa/Filmoteka/internal/twofactor/entity"
+	twoFactorUsecase "github.com/ilyushkaaa/Filmoteka/internal/twofactor/usecase"
+	twoFactorMock "github.com/ilyushkaaa/Filmoteka/internal/twofactor/usecase/mock"
 	"github.com/ilyushkaaa/Filmoteka/internal/users/entity"
 	"github.com/ilyushkaaa/Filmoteka/internal/users/usecase"
 	"github.com/ilyushkaaa/Filmoteka/internal/users/usecase/mock"
+	"github.com/ilyushkaaa/Filmoteka/pkg/cookie"
 	logger2 "github.com/ilyushkaaa/Filmoteka/pkg/logger"
 	"go.uber.org/zap"
 )
 
+var testCookieConfig = &cookie.Config{Path: "/", Secure: true, SameSite: http.SameSiteLaxMode}
+
 type errorReader struct{}
 
 func (er *errorReader) Read(_ []byte) (int, error) {
@@ -43,7 +55,9 @@ func TestLogin(t *testing.T) {
 
 	testUseCase := mock.NewMockUserUseCase(ctrl)
 	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
-	testHandler := NewUserHandler(testUseCase, sessionTestUseCase)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, nil, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
 
 	// can not read request body
 	request := httptest.NewRequest(http.MethodPost, "/login", &errorReader{})
@@ -154,7 +168,9 @@ func TestLogin(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusUnauthorized, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().Login("hello12", "qqqqqqqqq").Return(nil, usecase.ErrBadCredentials)
+	lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+	testUseCase.EXPECT().Login(gomock.Any(), "hello12", "qqqqqqqqq").Return(nil, usecase.ErrBadCredentials)
+	lockoutTestUseCase.EXPECT().LoginFailed(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
 	request = httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"hello12","password":"qqqqqqqqq"}`))
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -174,7 +190,29 @@ func TestLogin(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusUnauthorized, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().Login("hello12", "qqqqqqqqq").Return(nil, fmt.Errorf("internal server error"))
+	lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+	testUseCase.EXPECT().Login(gomock.Any(), "hello12", "qqqqqqqqq").Return(nil, usecase.ErrUserDisabled)
+	request = httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"hello12","password":"qqqqqqqqq"}`))
+	ctx = request.Context()
+	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.Login(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	_, err = io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable to read response body")
+		return
+	}
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 403 {
+		t.Errorf("expected status %d, got status %d", http.StatusForbidden, resp.StatusCode)
+	}
+
+	lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+	testUseCase.EXPECT().Login(gomock.Any(), "hello12", "qqqqqqqqq").Return(nil, fmt.Errorf("internal server error"))
 	request = httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"hello12","password":"qqqqqqqqq"}`))
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -194,7 +232,8 @@ func TestLogin(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().Login("hello12", "qqqqqqqqq").Return(nil, fmt.Errorf("internal server error"))
+	lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+	testUseCase.EXPECT().Login(gomock.Any(), "hello12", "qqqqqqqqq").Return(nil, fmt.Errorf("internal server error"))
 	request = httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"hello12","password":"qqqqqqqqq"}`))
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -217,8 +256,11 @@ func TestLogin(t *testing.T) {
 		ID:       1,
 		Username: "some_username",
 	}
-	testUseCase.EXPECT().Login("some_username", "aaaaaaaa").Return(loggedInUser, nil)
-	sessionTestUseCase.EXPECT().CreateSession(loggedInUser.ID).Return("", fmt.Errorf("error"))
+	lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "some_username", "192.0.2.1").Return(time.Duration(0), nil)
+	testUseCase.EXPECT().Login(gomock.Any(), "some_username", "aaaaaaaa").Return(loggedInUser, nil)
+	twoFactorTestUseCase.EXPECT().StartLogin(gomock.Any(), loggedInUser).Return(nil, nil)
+	lockoutTestUseCase.EXPECT().LoginSucceeded(gomock.Any(), "some_username").Return(nil)
+	sessionTestUseCase.EXPECT().CreateSession(gomock.Any(), loggedInUser.ID, "192.0.2.1", "").Return(nil, fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"some_username", "password":"aaaaaaaa"}`))
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -238,8 +280,11 @@ func TestLogin(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().Login("some_username", "aaaaaaaa").Return(loggedInUser, nil)
-	sessionTestUseCase.EXPECT().CreateSession(loggedInUser.ID).Return("some_token", nil)
+	lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "some_username", "192.0.2.1").Return(time.Duration(0), nil)
+	testUseCase.EXPECT().Login(gomock.Any(), "some_username", "aaaaaaaa").Return(loggedInUser, nil)
+	twoFactorTestUseCase.EXPECT().StartLogin(gomock.Any(), loggedInUser).Return(nil, nil)
+	lockoutTestUseCase.EXPECT().LoginSucceeded(gomock.Any(), "some_username").Return(nil)
+	sessionTestUseCase.EXPECT().CreateSession(gomock.Any(), loggedInUser.ID, "192.0.2.1", "").Return(&sessionEntity.Session{ID: "some_token", ExpiresAt: time.Now().Add(time.Hour)}, nil)
 	request = httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"some_username", "password":"aaaaaaaa"}`))
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -258,6 +303,270 @@ func TestLogin(t *testing.T) {
 	if resp.StatusCode != 200 {
 		t.Errorf("expected status %d, got status %d", http.StatusOK, resp.StatusCode)
 	}
+	cookies := resp.Cookies()
+	if len(cookies) != 2 || cookies[0].Name != cookie.SessionCookieName || cookies[0].Value != "some_token" ||
+		!cookies[0].HttpOnly || !cookies[0].Secure || cookies[1].Name != cookie.CSRFCookieName {
+		t.Errorf("expected session and csrf cookies, got %v", cookies)
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, nil, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
+
+	testCases := []struct {
+		setup      func()
+		statusCode int
+		retryAfter string
+	}{
+		{
+			setup: func() {
+				lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			setup: func() {
+				lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(1500*time.Millisecond, nil)
+			},
+			statusCode: http.StatusTooManyRequests,
+			retryAfter: "2",
+		},
+		{
+			// ошибка учета неудачной попытки не меняет ответ
+			setup: func() {
+				lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+				testUseCase.EXPECT().Login(gomock.Any(), "hello12", "qqqqqqqqq").Return(nil, usecase.ErrBadCredentials)
+				lockoutTestUseCase.EXPECT().LoginFailed(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), fmt.Errorf("error"))
+			},
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			setup: func() {
+				lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+				testUseCase.EXPECT().Login(gomock.Any(), "hello12", "qqqqqqqqq").Return(nil, usecase.ErrBadCredentials)
+				lockoutTestUseCase.EXPECT().LoginFailed(gomock.Any(), "hello12", "192.0.2.1").Return(time.Minute, nil)
+			},
+			statusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tc := range testCases {
+		tc.setup()
+		request := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"hello12","password":"qqqqqqqqq"}`))
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.Login(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		err := resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+		if resp.Header.Get("Retry-After") != tc.retryAfter {
+			t.Errorf("expected Retry-After %q, got %q", tc.retryAfter, resp.Header.Get("Retry-After"))
+		}
+	}
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, nil, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
+	loggedInUser := &entity.User{ID: 1, Username: "hello12", Role: entity.RoleAdmin}
+	challenge := &twoFactorEntity.LoginChallenge{
+		ID:                 "5f0c1a4e-3b1f-4a55-9f0e-2f4cf1e2b6a1",
+		UserID:             1,
+		Username:           "hello12",
+		EnrollmentRequired: true,
+		ExpiresAt:          time.Now().Add(time.Minute),
+	}
+
+	testCases := []struct {
+		setup      func()
+		statusCode int
+	}{
+		{
+			setup: func() {
+				testUseCase.EXPECT().Login(gomock.Any(), "hello12", "qqqqqqqqq").Return(loggedInUser, nil)
+				twoFactorTestUseCase.EXPECT().StartLogin(gomock.Any(), loggedInUser).Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			// сессия не создается и счетчик неудач не сбрасывается до проверки второго фактора
+			setup: func() {
+				testUseCase.EXPECT().Login(gomock.Any(), "hello12", "qqqqqqqqq").Return(loggedInUser, nil)
+				twoFactorTestUseCase.EXPECT().StartLogin(gomock.Any(), loggedInUser).Return(challenge, nil)
+			},
+			statusCode: http.StatusAccepted,
+		},
+	}
+	for _, tc := range testCases {
+		lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+		tc.setup()
+		request := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"hello12","password":"qqqqqqqqq"}`))
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.Login(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("unable to read response body")
+		}
+		err = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+		if tc.statusCode == http.StatusAccepted &&
+			(!strings.Contains(string(body), challenge.ID) || len(resp.Cookies()) != 0) {
+			t.Errorf("expected login challenge without cookies, got %s", body)
+		}
+	}
+}
+
+func TestLoginTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, nil, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
+	loggedInUser := &entity.User{ID: 1, Username: "hello12", Role: entity.RoleAdmin}
+	challengeID := "5f0c1a4e-3b1f-4a55-9f0e-2f4cf1e2b6a1"
+	challenge := &twoFactorEntity.LoginChallenge{ID: challengeID, UserID: 1, Username: "hello12"}
+	requestBody := fmt.Sprintf(`{"challenge_id":"%s","code":"123456"}`, challengeID)
+
+	testCases := []struct {
+		body          string
+		setup         func()
+		statusCode    int
+		responseParts []string
+	}{
+		{body: `{"challenge_id":`, statusCode: http.StatusBadRequest},
+		{body: `{"challenge_id":"bad","code":"1"}`, statusCode: http.StatusUnprocessableEntity},
+		{
+			body: requestBody,
+			setup: func() {
+				twoFactorTestUseCase.EXPECT().GetChallenge(gomock.Any(), challengeID).Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			body: requestBody,
+			setup: func() {
+				twoFactorTestUseCase.EXPECT().GetChallenge(gomock.Any(), challengeID).Return(nil, nil)
+			},
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			body: requestBody,
+			setup: func() {
+				twoFactorTestUseCase.EXPECT().GetChallenge(gomock.Any(), challengeID).Return(challenge, nil)
+				lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Minute, nil)
+			},
+			statusCode: http.StatusTooManyRequests,
+		},
+		{
+			body: requestBody,
+			setup: func() {
+				twoFactorTestUseCase.EXPECT().GetChallenge(gomock.Any(), challengeID).Return(challenge, nil)
+				lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+				twoFactorTestUseCase.EXPECT().CompleteLogin(gomock.Any(), challenge, "123456").Return(nil, twoFactorUsecase.ErrBadCode)
+				lockoutTestUseCase.EXPECT().LoginFailed(gomock.Any(), "hello12", "192.0.2.1").Return(time.Second, nil)
+			},
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			body: requestBody,
+			setup: func() {
+				twoFactorTestUseCase.EXPECT().GetChallenge(gomock.Any(), challengeID).Return(challenge, nil)
+				lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+				twoFactorTestUseCase.EXPECT().CompleteLogin(gomock.Any(), challenge, "123456").Return(nil, twoFactorUsecase.ErrTooManyAttempts)
+			},
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			body: requestBody,
+			setup: func() {
+				twoFactorTestUseCase.EXPECT().GetChallenge(gomock.Any(), challengeID).Return(challenge, nil)
+				lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+				twoFactorTestUseCase.EXPECT().CompleteLogin(gomock.Any(), challenge, "123456").Return(nil, twoFactorUsecase.ErrEnrollmentRequired)
+			},
+			statusCode: http.StatusForbidden,
+		},
+		{
+			body: requestBody,
+			setup: func() {
+				twoFactorTestUseCase.EXPECT().GetChallenge(gomock.Any(), challengeID).Return(challenge, nil)
+				lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+				twoFactorTestUseCase.EXPECT().CompleteLogin(gomock.Any(), challenge, "123456").Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			body: requestBody,
+			setup: func() {
+				twoFactorTestUseCase.EXPECT().GetChallenge(gomock.Any(), challengeID).Return(challenge, nil)
+				lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+				twoFactorTestUseCase.EXPECT().CompleteLogin(gomock.Any(), challenge, "123456").Return(&twoFactorEntity.LoginResult{
+					User:          loggedInUser,
+					RecoveryCodes: []string{"aaaaa-bbbbb"},
+				}, nil)
+				lockoutTestUseCase.EXPECT().LoginSucceeded(gomock.Any(), "hello12").Return(nil)
+				sessionTestUseCase.EXPECT().CreateSession(gomock.Any(), loggedInUser.ID, "192.0.2.1", "").
+					Return(&sessionEntity.Session{ID: "some_token", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+			},
+			statusCode:    http.StatusOK,
+			responseParts: []string{`"session_id":"some_token"`, `"recovery_codes":["aaaaa-bbbbb"]`},
+		},
+	}
+	for _, tc := range testCases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		request := httptest.NewRequest(http.MethodPost, "/login/totp", strings.NewReader(tc.body))
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.LoginTOTP(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("unable to read response body")
+		}
+		err = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+		for _, part := range tc.responseParts {
+			if !strings.Contains(string(body), part) {
+				t.Errorf("expected %s in response, got %s", part, body)
+			}
+		}
+	}
 }
 
 func TestRegister(t *testing.T) {
@@ -267,7 +576,9 @@ func TestRegister(t *testing.T) {
 
 	testUseCase := mock.NewMockUserUseCase(ctrl)
 	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
-	testHandler := NewUserHandler(testUseCase, sessionTestUseCase)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, nil, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
 
 	// can not read request body
 	request := httptest.NewRequest(http.MethodPost, "/register", &errorReader{})
@@ -342,7 +653,7 @@ func TestRegister(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusUnauthorized, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().Register("hello12", "qqqqqqqqq").Return(nil, usecase.ErrUserAlreadyExists)
+	testUseCase.EXPECT().Register(gomock.Any(), "hello12", "qqqqqqqqq", "").Return(nil, usecase.ErrUserAlreadyExists)
 	request = httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"username":"hello12","password":"qqqqqqqqq"}`))
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -362,7 +673,7 @@ func TestRegister(t *testing.T) {
 		t.Errorf("expected status %d, got status %d", http.StatusUnprocessableEntity, resp.StatusCode)
 	}
 
-	testUseCase.EXPECT().Register("hello12", "qqqqqqqqq").Return(nil, fmt.Errorf("internal server error"))
+	testUseCase.EXPECT().Register(gomock.Any(), "hello12", "qqqqqqqqq", "").Return(nil, fmt.Errorf("internal server error"))
 	request = httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"username":"hello12","password":"qqqqqqqqq"}`))
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -385,8 +696,8 @@ func TestRegister(t *testing.T) {
 		ID:       1,
 		Username: "some_username",
 	}
-	testUseCase.EXPECT().Register("some_username", "aaaaaaaa").Return(loggedInUser, nil)
-	sessionTestUseCase.EXPECT().CreateSession(loggedInUser.ID).Return("", fmt.Errorf("error"))
+	testUseCase.EXPECT().Register(gomock.Any(), "some_username", "aaaaaaaa", "").Return(loggedInUser, nil)
+	sessionTestUseCase.EXPECT().CreateSession(gomock.Any(), loggedInUser.ID, "192.0.2.1", "").Return(nil, fmt.Errorf("error"))
 	request = httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"username":"some_username", "password":"aaaaaaaa"}`))
 	ctx = request.Context()
 	ctx = context.WithValue(ctx, logger2.MyLoggerKey, logger)
@@ -405,6 +716,37 @@ func TestRegister(t *testing.T) {
 	if resp.StatusCode != 500 {
 		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
 	}
+
+	request = httptest.NewRequest(http.MethodPost, "/register",
+		strings.NewReader(`{"username":"some_username", "password":"aaaaaaaa", "email":"not an email"}`))
+	ctx = context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.Register(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got status %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	testUseCase.EXPECT().Register(gomock.Any(), "some_username", "aaaaaaaa", "user@example.com").Return(loggedInUser, nil)
+	sessionTestUseCase.EXPECT().CreateSession(gomock.Any(), loggedInUser.ID, "192.0.2.1", "").
+		Return(&sessionEntity.Session{ID: "session", UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	request = httptest.NewRequest(http.MethodPost, "/register",
+		strings.NewReader(`{"username":"some_username", "password":"aaaaaaaa", "email":"user@example.com"}`))
+	ctx = context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	respWriter = httptest.NewRecorder()
+	testHandler.Register(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d, got status %d", http.StatusOK, resp.StatusCode)
+	}
 }
 
 func TestLogout(t *testing.T) {
@@ -414,7 +756,9 @@ func TestLogout(t *testing.T) {
 
 	testUseCase := mock.NewMockUserUseCase(ctrl)
 	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
-	testHandler := NewUserHandler(testUseCase, sessionTestUseCase)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, nil, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
 
 	// can not read request body
 
@@ -467,8 +811,92 @@ func TestLogout(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to close response body")
 	}
-	if resp.StatusCode != 500 {
-		t.Errorf("expected status %d, got status %d", http.StatusInternalServerError, resp.StatusCode)
+	if resp.StatusCode != 400 {
+		t.Errorf("expected status %d, got status %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	sessionTestUseCase.EXPECT().DeleteSession(gomock.Any(), "some_token").Return(true, nil)
+	request = httptest.NewRequest(http.MethodPost, "/logout", nil)
+	ctx = context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+	ctx = context.WithValue(ctx, middleware.MySessionIDKey, "some_token")
+	respWriter = httptest.NewRecorder()
+	testHandler.Logout(respWriter, request.WithContext(ctx))
+	resp = respWriter.Result()
+	err = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to close response body")
+	}
+	if resp.StatusCode != 200 {
+		t.Errorf("expected status %d, got status %d", http.StatusOK, resp.StatusCode)
+	}
+	cookies := resp.Cookies()
+	if len(cookies) != 2 || cookies[0].MaxAge >= 0 || cookies[1].MaxAge >= 0 {
+		t.Errorf("expected session and csrf cookies to be cleared, got %v", cookies)
 	}
+}
 
+func TestLoginWithTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := zap.NewNop().Sugar()
+
+	testUseCase := mock.NewMockUserUseCase(ctrl)
+	sessionTestUseCase := usecase2.NewMockSessionUseCase(ctrl)
+	tokenTestUseCase := tokenMock.NewMockTokenUseCase(ctrl)
+	lockoutTestUseCase := lockoutMock.NewMockLockoutUseCase(ctrl)
+	twoFactorTestUseCase := twoFactorMock.NewMockTwoFactorUseCase(ctrl)
+	testHandler := NewUserHandler(testUseCase, sessionTestUseCase, tokenTestUseCase, lockoutTestUseCase, twoFactorTestUseCase, testCookieConfig)
+	loggedInUser := &entity.User{ID: 1, Username: "hello12", Role: entity.RoleEditor}
+
+	testCases := []struct {
+		setup      func()
+		statusCode int
+	}{
+		{
+			setup: func() {
+				lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+				testUseCase.EXPECT().Login(gomock.Any(), "hello12", "qqqqqqqqq").Return(loggedInUser, nil)
+				twoFactorTestUseCase.EXPECT().StartLogin(gomock.Any(), loggedInUser).Return(nil, nil)
+				lockoutTestUseCase.EXPECT().LoginSucceeded(gomock.Any(), "hello12").Return(nil)
+				tokenTestUseCase.EXPECT().IssueTokens(gomock.Any(), loggedInUser).Return(nil, fmt.Errorf("error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			setup: func() {
+				lockoutTestUseCase.EXPECT().CheckLogin(gomock.Any(), "hello12", "192.0.2.1").Return(time.Duration(0), nil)
+				testUseCase.EXPECT().Login(gomock.Any(), "hello12", "qqqqqqqqq").Return(loggedInUser, nil)
+				twoFactorTestUseCase.EXPECT().StartLogin(gomock.Any(), loggedInUser).Return(nil, nil)
+				lockoutTestUseCase.EXPECT().LoginSucceeded(gomock.Any(), "hello12").Return(nil)
+				tokenTestUseCase.EXPECT().IssueTokens(gomock.Any(), loggedInUser).Return(&tokenEntity.TokenPair{
+					AccessToken:          "access",
+					AccessTokenExpiresAt: time.Now().Add(time.Minute),
+					RefreshToken:         "refresh",
+				}, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		tc.setup()
+		request := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"hello12","password":"qqqqqqqqq"}`))
+		ctx := context.WithValue(request.Context(), logger2.MyLoggerKey, logger)
+		respWriter := httptest.NewRecorder()
+		testHandler.Login(respWriter, request.WithContext(ctx))
+		resp := respWriter.Result()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("unable to read response body")
+		}
+		err = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to close response body")
+		}
+		if resp.StatusCode != tc.statusCode {
+			t.Errorf("expected status %d, got status %d", tc.statusCode, resp.StatusCode)
+		}
+		if tc.statusCode == http.StatusOK && !strings.Contains(string(body), `"token_type":"Bearer"`) {
+			t.Errorf("expected token response, got %s", body)
+		}
+	}
 }
@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+type Profile struct {
+	UserID            uint64
+	Username          string
+	Role              string
+	DisplayName       string
+	Email             string
+	AvatarURL         string
+	PreferredLanguage string
+	CreatedAt         time.Time
+}
+
+// ProfileUpdate - nil не меняет поле, а пустая строка очищает его
+type ProfileUpdate struct {
+	Username          *string
+	DisplayName       *string
+	Email             *string
+	AvatarURL         *string
+	PreferredLanguage *string
+}
+
+func (u *ProfileUpdate) Apply(profile *Profile) {
+	if u.Username != nil {
+		profile.Username = *u.Username
+	}
+	if u.DisplayName != nil {
+		profile.DisplayName = *u.DisplayName
+	}
+	if u.Email != nil {
+		profile.Email = *u.Email
+	}
+	if u.AvatarURL != nil {
+		profile.AvatarURL = *u.AvatarURL
+	}
+	if u.PreferredLanguage != nil {
+		profile.PreferredLanguage = *u.PreferredLanguage
+	}
+}
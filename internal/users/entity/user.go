@@ -1,6 +1,23 @@
 package entity
 
+const (
+	RoleDefault   = "default"
+	RoleEditor    = "editor"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+const (
+	PermissionFilmWrite      = "film:write"
+	PermissionActorWrite     = "actor:write"
+	PermissionReviewModerate = "review:moderate"
+	PermissionStatsRead      = "stats:read"
+	PermissionUserManage     = "user:manage"
+)
+
 type User struct {
-	ID       uint64
-	Username string
+	ID         uint64
+	Username   string
+	Role       string
+	IsDisabled bool
 }
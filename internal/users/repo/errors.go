@@ -0,0 +1,10 @@
+package repo
+
+import "errors"
+
+const uniqueViolation = "23505"
+
+var (
+	ErrUserAlreadyExists = errors.New("user with such username already exists")
+	ErrLastAdmin         = errors.New("can not remove the last active admin")
+)
@@ -5,6 +5,7 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -34,62 +35,259 @@ func (m *MockUserRepo) EXPECT() *MockUserRepoMockRecorder {
 	return m.recorder
 }
 
+// DeleteUser mocks base method.
+func (m *MockUserRepo) DeleteUser(ctx context.Context, userID uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUserRepoMockRecorder) DeleteUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserRepo)(nil).DeleteUser), ctx, userID)
+}
+
+// GetPasswordHashByID mocks base method.
+func (m *MockUserRepo) GetPasswordHashByID(ctx context.Context, userID uint64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordHashByID", ctx, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordHashByID indicates an expected call of GetPasswordHashByID.
+func (mr *MockUserRepoMockRecorder) GetPasswordHashByID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordHashByID", reflect.TypeOf((*MockUserRepo)(nil).GetPasswordHashByID), ctx, userID)
+}
+
+// GetProfile mocks base method.
+func (m *MockUserRepo) GetProfile(ctx context.Context, userID uint64) (*entity.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", ctx, userID)
+	ret0, _ := ret[0].(*entity.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockUserRepoMockRecorder) GetProfile(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockUserRepo)(nil).GetProfile), ctx, userID)
+}
+
+// GetRolePermissions mocks base method.
+func (m *MockUserRepo) GetRolePermissions(ctx context.Context, role string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRolePermissions", ctx, role)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRolePermissions indicates an expected call of GetRolePermissions.
+func (mr *MockUserRepoMockRecorder) GetRolePermissions(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRolePermissions", reflect.TypeOf((*MockUserRepo)(nil).GetRolePermissions), ctx, role)
+}
+
+// GetUserByID mocks base method.
+func (m *MockUserRepo) GetUserByID(ctx context.Context, userID uint64) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserRepoMockRecorder) GetUserByID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepo)(nil).GetUserByID), ctx, userID)
+}
+
 // GetUserByUsername mocks base method.
-func (m *MockUserRepo) GetUserByUsername(username string) (*entity.User, error) {
+func (m *MockUserRepo) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByUsername", username)
+	ret := m.ctrl.Call(m, "GetUserByUsername", ctx, username)
 	ret0, _ := ret[0].(*entity.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserByUsername indicates an expected call of GetUserByUsername.
-func (mr *MockUserRepoMockRecorder) GetUserByUsername(username interface{}) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) GetUserByUsername(ctx, username interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUserRepo)(nil).GetUserByUsername), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUserRepo)(nil).GetUserByUsername), ctx, username)
+}
+
+// GetUserEmail mocks base method.
+func (m *MockUserRepo) GetUserEmail(ctx context.Context, userID uint64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserEmail", ctx, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserEmail indicates an expected call of GetUserEmail.
+func (mr *MockUserRepoMockRecorder) GetUserEmail(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserEmail", reflect.TypeOf((*MockUserRepo)(nil).GetUserEmail), ctx, userID)
+}
+
+// GetUserPasswordHash mocks base method.
+func (m *MockUserRepo) GetUserPasswordHash(ctx context.Context, username string) (*entity.User, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserPasswordHash", ctx, username)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUserPasswordHash indicates an expected call of GetUserPasswordHash.
+func (mr *MockUserRepoMockRecorder) GetUserPasswordHash(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPasswordHash", reflect.TypeOf((*MockUserRepo)(nil).GetUserPasswordHash), ctx, username)
 }
 
 // GetUserRole mocks base method.
-func (m *MockUserRepo) GetUserRole(userID uint64) (string, error) {
+func (m *MockUserRepo) GetUserRole(ctx context.Context, userID uint64) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserRole", userID)
+	ret := m.ctrl.Call(m, "GetUserRole", ctx, userID)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserRole indicates an expected call of GetUserRole.
-func (mr *MockUserRepoMockRecorder) GetUserRole(userID interface{}) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) GetUserRole(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRole", reflect.TypeOf((*MockUserRepo)(nil).GetUserRole), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRole", reflect.TypeOf((*MockUserRepo)(nil).GetUserRole), ctx, userID)
 }
 
-// Login mocks base method.
-func (m *MockUserRepo) Login(username, password string) (*entity.User, error) {
+// GetUsers mocks base method.
+func (m *MockUserRepo) GetUsers(ctx context.Context, search string, limit, offset uint64) ([]entity.User, uint64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", username, password)
-	ret0, _ := ret[0].(*entity.User)
+	ret := m.ctrl.Call(m, "GetUsers", ctx, search, limit, offset)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockUserRepoMockRecorder) GetUsers(ctx, search, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserRepo)(nil).GetUsers), ctx, search, limit, offset)
+}
+
+// GetUsersByEmail mocks base method.
+func (m *MockUserRepo) GetUsersByEmail(ctx context.Context, email string) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByEmail", ctx, email)
+	ret0, _ := ret[0].([]entity.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Login indicates an expected call of Login.
-func (mr *MockUserRepoMockRecorder) Login(username, password interface{}) *gomock.Call {
+// GetUsersByEmail indicates an expected call of GetUsersByEmail.
+func (mr *MockUserRepoMockRecorder) GetUsersByEmail(ctx, email interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserRepo)(nil).Login), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByEmail", reflect.TypeOf((*MockUserRepo)(nil).GetUsersByEmail), ctx, email)
 }
 
 // Register mocks base method.
-func (m *MockUserRepo) Register(username, password string) (*entity.User, error) {
+func (m *MockUserRepo) Register(ctx context.Context, username, password, email, role string) (*entity.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Register", username, password)
+	ret := m.ctrl.Call(m, "Register", ctx, username, password, email, role)
 	ret0, _ := ret[0].(*entity.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Register indicates an expected call of Register.
-func (mr *MockUserRepoMockRecorder) Register(username, password interface{}) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) Register(ctx, username, password, email, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUserRepo)(nil).Register), ctx, username, password, email, role)
+}
+
+// RoleExists mocks base method.
+func (m *MockUserRepo) RoleExists(ctx context.Context, role string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RoleExists", ctx, role)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RoleExists indicates an expected call of RoleExists.
+func (mr *MockUserRepoMockRecorder) RoleExists(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoleExists", reflect.TypeOf((*MockUserRepo)(nil).RoleExists), ctx, role)
+}
+
+// SetUserDisabled mocks base method.
+func (m *MockUserRepo) SetUserDisabled(ctx context.Context, userID uint64, isDisabled bool) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserDisabled", ctx, userID, isDisabled)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserDisabled indicates an expected call of SetUserDisabled.
+func (mr *MockUserRepoMockRecorder) SetUserDisabled(ctx, userID, isDisabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserDisabled", reflect.TypeOf((*MockUserRepo)(nil).SetUserDisabled), ctx, userID, isDisabled)
+}
+
+// SetUserRole mocks base method.
+func (m *MockUserRepo) SetUserRole(ctx context.Context, userID uint64, role string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, userID, role)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockUserRepoMockRecorder) SetUserRole(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockUserRepo)(nil).SetUserRole), ctx, userID, role)
+}
+
+// UpdatePasswordHash mocks base method.
+func (m *MockUserRepo) UpdatePasswordHash(ctx context.Context, userID uint64, passwordHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordHash", ctx, userID, passwordHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePasswordHash indicates an expected call of UpdatePasswordHash.
+func (mr *MockUserRepoMockRecorder) UpdatePasswordHash(ctx, userID, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordHash", reflect.TypeOf((*MockUserRepo)(nil).UpdatePasswordHash), ctx, userID, passwordHash)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockUserRepo) UpdateProfile(ctx context.Context, profile *entity.Profile) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, profile)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockUserRepoMockRecorder) UpdateProfile(ctx, profile interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUserRepo)(nil).Register), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserRepo)(nil).UpdateProfile), ctx, profile)
 }
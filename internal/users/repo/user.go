@@ -1,18 +1,34 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 
 	"github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
+	"github.com/jackc/pgx"
 )
 
 //go:generate mockgen -source=user.go -destination=user_mock.go -package=repo UserRepo
 type UserRepo interface {
-	Login(username, password string) (*entity.User, error)
-	Register(username, password string) (*entity.User, error)
-	GetUserByUsername(username string) (*entity.User, error)
-	GetUserRole(userID uint64) (string, error)
+	GetUserPasswordHash(ctx context.Context, username string) (*entity.User, string, error)
+	GetPasswordHashByID(ctx context.Context, userID uint64) (string, error)
+	UpdatePasswordHash(ctx context.Context, userID uint64, passwordHash string) (bool, error)
+	SetUserRole(ctx context.Context, userID uint64, role string) (bool, error)
+	GetUsers(ctx context.Context, search string, limit, offset uint64) ([]entity.User, uint64, error)
+	GetUserByID(ctx context.Context, userID uint64) (*entity.User, error)
+	SetUserDisabled(ctx context.Context, userID uint64, isDisabled bool) (bool, error)
+	DeleteUser(ctx context.Context, userID uint64) (bool, error)
+	Register(ctx context.Context, username, password, email, role string) (*entity.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*entity.User, error)
+	GetUserEmail(ctx context.Context, userID uint64) (string, error)
+	GetUsersByEmail(ctx context.Context, email string) ([]entity.User, error)
+	GetProfile(ctx context.Context, userID uint64) (*entity.Profile, error)
+	UpdateProfile(ctx context.Context, profile *entity.Profile) (bool, error)
+	GetUserRole(ctx context.Context, userID uint64) (string, error)
+	RoleExists(ctx context.Context, role string) (bool, error)
+	GetRolePermissions(ctx context.Context, role string) ([]string, error)
 }
 
 type UserRepoPG struct {
@@ -25,38 +41,70 @@ func NewUserRepo(db *sql.DB) *UserRepoPG {
 	}
 }
 
-func (u *UserRepoPG) Login(username, password string) (*entity.User, error) {
+func (u *UserRepoPG) GetUserPasswordHash(ctx context.Context, username string) (*entity.User, string, error) {
 	foundUser := &entity.User{}
+	var passwordHash string
 	err := u.db.
-		QueryRow("SELECT id, username FROM users WHERE username = $1 AND password = $2", username, password).
-		Scan(&foundUser.ID, &foundUser.Username)
+		QueryRowContext(ctx, "SELECT id, username, role, is_disabled, password FROM users WHERE LOWER(username) = LOWER($1)", username).
+		Scan(&foundUser.ID, &foundUser.Username, &foundUser.Role, &foundUser.IsDisabled, &passwordHash)
 	if errors.Is(err, sql.ErrNoRows) {
-		return nil, nil
+		return nil, "", nil
 	}
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return foundUser, nil
+	return foundUser, passwordHash, nil
+}
+
+func (u *UserRepoPG) GetPasswordHashByID(ctx context.Context, userID uint64) (string, error) {
+	var passwordHash string
+	err := u.db.
+		QueryRowContext(ctx, "SELECT password FROM users WHERE id = $1", userID).
+		Scan(&passwordHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return passwordHash, nil
+}
+
+func (u *UserRepoPG) UpdatePasswordHash(ctx context.Context, userID uint64, passwordHash string) (bool, error) {
+	result, err := u.db.ExecContext(ctx, "UPDATE users SET password = $1 WHERE id = $2", passwordHash, userID)
+	if err != nil {
+		return false, err
+	}
+	rowsUpdated, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsUpdated > 0, nil
 }
 
-func (u *UserRepoPG) Register(username, password string) (*entity.User, error) {
+func (u *UserRepoPG) Register(ctx context.Context, username, password, email, role string) (*entity.User, error) {
 	var userID uint64
 	err := u.db.
-		QueryRow("INSERT INTO users (username, password, role) VALUES ($1, $2, $3) RETURNING id", username, password, "default").
+		QueryRowContext(ctx, "INSERT INTO users (username, password, email, role) VALUES ($1, $2, NULLIF($3, ''), $4) RETURNING id",
+			username, password, email, role).
 		Scan(&userID)
+	if isUniqueViolation(err) {
+		return nil, ErrUserAlreadyExists
+	}
 	if err != nil {
 		return nil, err
 	}
 	return &entity.User{
 		ID:       userID,
 		Username: username,
+		Role:     role,
 	}, nil
 }
 
-func (u *UserRepoPG) GetUserByUsername(username string) (*entity.User, error) {
+func (u *UserRepoPG) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
 	foundUser := &entity.User{}
 	err := u.db.
-		QueryRow("SELECT id, username FROM users WHERE username = $1", username).
+		QueryRowContext(ctx, "SELECT id, username FROM users WHERE LOWER(username) = LOWER($1)", username).
 		Scan(&foundUser.ID, &foundUser.Username)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
@@ -67,10 +115,63 @@ func (u *UserRepoPG) GetUserByUsername(username string) (*entity.User, error) {
 	return foundUser, nil
 }
 
-func (u *UserRepoPG) GetUserRole(userID uint64) (string, error) {
+func (u *UserRepoPG) GetUserEmail(ctx context.Context, userID uint64) (string, error) {
+	var email sql.NullString
+	err := u.db.
+		QueryRowContext(ctx, "SELECT email FROM users WHERE id = $1", userID).
+		Scan(&email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return email.String, nil
+}
+
+func (u *UserRepoPG) GetProfile(ctx context.Context, userID uint64) (*entity.Profile, error) {
+	profile := &entity.Profile{}
+	var email sql.NullString
+	err := u.db.
+		QueryRowContext(ctx, `
+            SELECT id, username, role, email, display_name, avatar_url, preferred_language, created_at
+            FROM users WHERE id = $1
+        `, userID).
+		Scan(&profile.UserID, &profile.Username, &profile.Role, &email, &profile.DisplayName, &profile.AvatarURL,
+			&profile.PreferredLanguage, &profile.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	profile.Email = email.String
+	return profile, nil
+}
+
+func (u *UserRepoPG) UpdateProfile(ctx context.Context, profile *entity.Profile) (bool, error) {
+	result, err := u.db.ExecContext(ctx, `
+        UPDATE users
+        SET username = $1, email = NULLIF($2, ''), display_name = $3, avatar_url = $4, preferred_language = $5
+        WHERE id = $6
+    `, profile.Username, profile.Email, profile.DisplayName, profile.AvatarURL, profile.PreferredLanguage, profile.UserID)
+	if isUniqueViolation(err) {
+		return false, ErrUserAlreadyExists
+	}
+	if err != nil {
+		return false, err
+	}
+	rowsUpdated, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsUpdated > 0, nil
+}
+
+func (u *UserRepoPG) GetUserRole(ctx context.Context, userID uint64) (string, error) {
 	var userRole string
 	err := u.db.
-		QueryRow("SELECT role FROM users WHERE id = $1", userID).
+		QueryRowContext(ctx, "SELECT role FROM users WHERE id = $1", userID).
 		Scan(&userRole)
 	if errors.Is(err, sql.ErrNoRows) {
 		return "", nil
@@ -80,3 +181,195 @@ func (u *UserRepoPG) GetUserRole(userID uint64) (string, error) {
 	}
 	return userRole, nil
 }
+
+func (u *UserRepoPG) RoleExists(ctx context.Context, role string) (bool, error) {
+	var roleExists bool
+	err := u.db.
+		QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)", role).
+		Scan(&roleExists)
+	if err != nil {
+		return false, err
+	}
+	return roleExists, nil
+}
+
+func (u *UserRepoPG) GetRolePermissions(ctx context.Context, role string) ([]string, error) {
+	rows, err := u.db.QueryContext(ctx, "SELECT permission FROM role_permissions WHERE role = $1 ORDER BY permission", role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	permissions := make([]string, 0)
+	for rows.Next() {
+		var permission string
+		err = rows.Scan(&permission)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, rows.Err()
+}
+
+func (u *UserRepoPG) SetUserRole(ctx context.Context, userID uint64, role string) (bool, error) {
+	query := "UPDATE users SET role = $1 WHERE id = $2"
+	if role == entity.RoleAdmin {
+		result, err := u.db.ExecContext(ctx, query, role, userID)
+		if err != nil {
+			return false, err
+		}
+		rowsUpdated, err := result.RowsAffected()
+		if err != nil {
+			return false, err
+		}
+		return rowsUpdated > 0, nil
+	}
+	return u.execKeepingLastAdmin(ctx, userID, query, role, userID)
+}
+
+func (u *UserRepoPG) GetUsers(ctx context.Context, search string, limit, offset uint64) ([]entity.User, uint64, error) {
+	var total uint64
+	err := u.db.
+		QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE username ILIKE '%' || $1 || '%'", search).
+		Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := u.db.QueryContext(ctx, `
+        SELECT id, username, role, is_disabled
+        FROM users
+        WHERE username ILIKE '%' || $1 || '%'
+        ORDER BY id
+        LIMIT NULLIF($2, 0) OFFSET $3
+    `, search, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	users := make([]entity.User, 0)
+	for rows.Next() {
+		var user entity.User
+		err = rows.Scan(&user.ID, &user.Username, &user.Role, &user.IsDisabled)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	return users, total, rows.Err()
+}
+
+func (u *UserRepoPG) GetUserByID(ctx context.Context, userID uint64) (*entity.User, error) {
+	foundUser := &entity.User{}
+	err := u.db.
+		QueryRowContext(ctx, "SELECT id, username, role, is_disabled FROM users WHERE id = $1", userID).
+		Scan(&foundUser.ID, &foundUser.Username, &foundUser.Role, &foundUser.IsDisabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return foundUser, nil
+}
+
+func (u *UserRepoPG) GetUsersByEmail(ctx context.Context, email string) ([]entity.User, error) {
+	rows, err := u.db.QueryContext(ctx, "SELECT id, username, role, is_disabled FROM users WHERE LOWER(email) = LOWER($1) ORDER BY id",
+		email)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	users := make([]entity.User, 0)
+	for rows.Next() {
+		var user entity.User
+		err = rows.Scan(&user.ID, &user.Username, &user.Role, &user.IsDisabled)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (u *UserRepoPG) SetUserDisabled(ctx context.Context, userID uint64, isDisabled bool) (bool, error) {
+	query := "UPDATE users SET is_disabled = $1 WHERE id = $2"
+	if !isDisabled {
+		result, err := u.db.ExecContext(ctx, query, isDisabled, userID)
+		if err != nil {
+			return false, err
+		}
+		rowsUpdated, err := result.RowsAffected()
+		if err != nil {
+			return false, err
+		}
+		return rowsUpdated > 0, nil
+	}
+	return u.execKeepingLastAdmin(ctx, userID, query, isDisabled, userID)
+}
+
+func (u *UserRepoPG) DeleteUser(ctx context.Context, userID uint64) (bool, error) {
+	return u.execKeepingLastAdmin(ctx, userID, "DELETE FROM users WHERE id = $1", userID)
+}
+
+// execKeepingLastAdmin блокирует строки активных администраторов, чтобы параллельные запросы не убрали их всех
+func (u *UserRepoPG) execKeepingLastAdmin(ctx context.Context, userID uint64, query string, args ...interface{}) (bool, error) {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			err = tx.Rollback()
+			if err != nil {
+				logger.ErrorfFromContext(ctx, "error in transaction rollback: %s", err)
+			}
+		}
+	}()
+
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM users WHERE role = $1 AND NOT is_disabled FOR UPDATE",
+		entity.RoleAdmin)
+	if err != nil {
+		return false, err
+	}
+	var adminsCount int
+	isAdmin := false
+	for rows.Next() {
+		var adminID uint64
+		err = rows.Scan(&adminID)
+		if err != nil {
+			_ = rows.Close()
+			return false, err
+		}
+		adminsCount++
+		isAdmin = isAdmin || adminID == userID
+	}
+	err = rows.Err()
+	if err != nil {
+		return false, err
+	}
+	if isAdmin && adminsCount == 1 {
+		err = ErrLastAdmin
+		return false, err
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr pgx.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
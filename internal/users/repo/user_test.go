@@ -1,11 +1,14 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	"github.com/jackc/pgx"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/DATA-DOG/go-sqlmock.v1"
 )
@@ -22,13 +25,13 @@ func TestRegister(t *testing.T) {
 	username := "testuser"
 	password := "testpassword"
 
-	expectedUser := &entity.User{ID: 1, Username: username}
+	expectedUser := &entity.User{ID: 1, Username: username, Role: entity.RoleDefault}
 
 	mock.ExpectQuery("INSERT INTO users (.+) RETURNING id").
-		WithArgs(username, password, "default").
+		WithArgs(username, password, "", "default").
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(expectedUser.ID))
 
-	user, err := repo.Register(username, password)
+	user, err := repo.Register(context.Background(), username, password, "", entity.RoleDefault)
 
 	assert.NoError(t, err, "unexpected error")
 	assert.NotNil(t, user, "user is nil")
@@ -37,15 +40,26 @@ func TestRegister(t *testing.T) {
 	assert.NoError(t, err)
 
 	mock.ExpectQuery("INSERT INTO users (.+) RETURNING id").
-		WithArgs(username, password, "default").
+		WithArgs(username, password, "", "default").
 		WillReturnError(fmt.Errorf("error"))
 
-	user, err = repo.Register(username, password)
+	user, err = repo.Register(context.Background(), username, password, "", entity.RoleDefault)
 
 	assert.Error(t, err)
 	assert.Nil(t, user)
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
+
+	mock.ExpectQuery("INSERT INTO users (.+) RETURNING id").
+		WithArgs("TestUser", password, "", "default").
+		WillReturnError(pgx.PgError{Code: "23505", ConstraintName: "idx_users_username_lower"})
+
+	user, err = repo.Register(context.Background(), "TestUser", password, "", entity.RoleDefault)
+
+	assert.ErrorIs(t, err, ErrUserAlreadyExists)
+	assert.Nil(t, user)
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
 }
 
 func TestGetUserByUsername(t *testing.T) {
@@ -61,11 +75,11 @@ func TestGetUserByUsername(t *testing.T) {
 
 	expectedUser := &entity.User{ID: 1, Username: username}
 
-	mock.ExpectQuery("SELECT id, username FROM users WHERE username = ?").
+	mock.ExpectQuery("SELECT id, username FROM users WHERE LOWER\\(username\\) = LOWER\\(\\$1\\)").
 		WithArgs(username).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(expectedUser.ID, expectedUser.Username))
 
-	user, err := repo.GetUserByUsername(username)
+	user, err := repo.GetUserByUsername(context.Background(), username)
 
 	assert.NoError(t, err, "unexpected error")
 	assert.NotNil(t, user, "user is nil")
@@ -73,22 +87,22 @@ func TestGetUserByUsername(t *testing.T) {
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 
-	mock.ExpectQuery("SELECT id, username FROM users WHERE username = ?").
+	mock.ExpectQuery("SELECT id, username FROM users WHERE LOWER\\(username\\) = LOWER\\(\\$1\\)").
 		WithArgs(username).
 		WillReturnError(sql.ErrNoRows)
 
-	user, err = repo.GetUserByUsername(username)
+	user, err = repo.GetUserByUsername(context.Background(), username)
 
 	assert.NoError(t, err, "unexpected error")
 	assert.Nil(t, user, "user is nil")
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 
-	mock.ExpectQuery("SELECT id, username FROM users WHERE username = ?").
+	mock.ExpectQuery("SELECT id, username FROM users WHERE LOWER\\(username\\) = LOWER\\(\\$1\\)").
 		WithArgs(username).
 		WillReturnError(fmt.Errorf("error"))
 
-	user, err = repo.GetUserByUsername(username)
+	user, err = repo.GetUserByUsername(context.Background(), username)
 	assert.Error(t, err)
 	assert.Nil(t, user, "user is nil")
 	err = mock.ExpectationsWereMet()
@@ -112,7 +126,7 @@ func TestGetUserRole(t *testing.T) {
 		WithArgs(userID).
 		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(expectedRole))
 
-	role, err := repo.GetUserRole(userID)
+	role, err := repo.GetUserRole(context.Background(), userID)
 	assert.NoError(t, err, "unexpected error")
 	assert.NotEmpty(t, role, "user role is empty")
 	assert.Equal(t, expectedRole, role, "unexpected user role returned")
@@ -123,7 +137,7 @@ func TestGetUserRole(t *testing.T) {
 		WithArgs(userID).
 		WillReturnError(fmt.Errorf("error"))
 
-	role, err = repo.GetUserRole(userID)
+	role, err = repo.GetUserRole(context.Background(), userID)
 	assert.Error(t, err)
 	assert.Empty(t, role)
 	err = mock.ExpectationsWereMet()
@@ -133,9 +147,657 @@ func TestGetUserRole(t *testing.T) {
 		WithArgs(userID).
 		WillReturnError(sql.ErrNoRows)
 
-	role, err = repo.GetUserRole(userID)
+	role, err = repo.GetUserRole(context.Background(), userID)
 	assert.NoError(t, err)
 	assert.Empty(t, role)
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 }
+
+func TestGetUserPasswordHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+
+	username := "testuser"
+	expectedUser := &entity.User{ID: 1, Username: username, Role: entity.RoleDefault}
+	expectedHash := "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$a2V5"
+
+	mock.ExpectQuery("SELECT id, username, role, is_disabled, password FROM users WHERE LOWER\\(username\\) = LOWER\\(\\$1\\)").
+		WithArgs(username).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "role", "is_disabled", "password"}).
+			AddRow(expectedUser.ID, expectedUser.Username, expectedUser.Role, false, expectedHash))
+
+	user, passwordHash, err := repo.GetUserPasswordHash(context.Background(), username)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUser, user)
+	assert.Equal(t, expectedHash, passwordHash)
+
+	mock.ExpectQuery("SELECT id, username, role, is_disabled, password FROM users WHERE LOWER\\(username\\) = LOWER\\(\\$1\\)").
+		WithArgs(username).
+		WillReturnError(sql.ErrNoRows)
+
+	user, passwordHash, err = repo.GetUserPasswordHash(context.Background(), username)
+	assert.NoError(t, err)
+	assert.Nil(t, user)
+	assert.Equal(t, "", passwordHash)
+
+	mock.ExpectQuery("SELECT id, username, role, is_disabled, password FROM users WHERE LOWER\\(username\\) = LOWER\\(\\$1\\)").
+		WithArgs(username).
+		WillReturnError(fmt.Errorf("error"))
+
+	user, _, err = repo.GetUserPasswordHash(context.Background(), username)
+	assert.Error(t, err)
+	assert.Nil(t, user)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestUpdatePasswordHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+
+	mock.ExpectExec("UPDATE users SET password = (.+) WHERE id = (.+)").
+		WithArgs("new_hash", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	wasUpdated, err := repo.UpdatePasswordHash(context.Background(), 1, "new_hash")
+	assert.NoError(t, err)
+	assert.True(t, wasUpdated)
+
+	mock.ExpectExec("UPDATE users SET password = (.+) WHERE id = (.+)").
+		WithArgs("new_hash", 1).
+		WillReturnError(fmt.Errorf("error"))
+	_, err = repo.UpdatePasswordHash(context.Background(), 1, "new_hash")
+	assert.Error(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestRoleExists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+
+	mock.ExpectQuery("SELECT EXISTS (.+) FROM roles WHERE name = (.+)").
+		WithArgs(entity.RoleEditor).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	roleExists, err := repo.RoleExists(context.Background(), entity.RoleEditor)
+	assert.NoError(t, err)
+	assert.True(t, roleExists)
+
+	mock.ExpectQuery("SELECT EXISTS (.+) FROM roles WHERE name = (.+)").
+		WithArgs("superuser").
+		WillReturnError(fmt.Errorf("error"))
+	_, err = repo.RoleExists(context.Background(), "superuser")
+	assert.Error(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestGetRolePermissions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+
+	mock.ExpectQuery("SELECT permission FROM role_permissions WHERE role = (.+) ORDER BY permission").
+		WithArgs(entity.RoleEditor).
+		WillReturnRows(sqlmock.NewRows([]string{"permission"}).
+			AddRow(entity.PermissionActorWrite).
+			AddRow(entity.PermissionFilmWrite))
+	permissions, err := repo.GetRolePermissions(context.Background(), entity.RoleEditor)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{entity.PermissionActorWrite, entity.PermissionFilmWrite}, permissions)
+
+	mock.ExpectQuery("SELECT permission FROM role_permissions WHERE role = (.+) ORDER BY permission").
+		WithArgs(entity.RoleDefault).
+		WillReturnRows(sqlmock.NewRows([]string{"permission"}))
+	permissions, err = repo.GetRolePermissions(context.Background(), entity.RoleDefault)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, permissions)
+
+	mock.ExpectQuery("SELECT permission FROM role_permissions WHERE role = (.+) ORDER BY permission").
+		WithArgs(entity.RoleEditor).
+		WillReturnError(fmt.Errorf("error"))
+	permissions, err = repo.GetRolePermissions(context.Background(), entity.RoleEditor)
+	assert.Error(t, err)
+	assert.Nil(t, permissions)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestSetUserRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+
+	mock.ExpectExec("UPDATE users SET role = (.+) WHERE id = (.+)").
+		WithArgs(entity.RoleAdmin, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	wasUpdated, err := repo.SetUserRole(context.Background(), 1, entity.RoleAdmin)
+	assert.NoError(t, err)
+	assert.True(t, wasUpdated)
+
+	mock.ExpectExec("UPDATE users SET role = (.+) WHERE id = (.+)").
+		WithArgs(entity.RoleAdmin, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	wasUpdated, err = repo.SetUserRole(context.Background(), 2, entity.RoleAdmin)
+	assert.NoError(t, err)
+	assert.False(t, wasUpdated)
+
+	mock.ExpectExec("UPDATE users SET role = (.+) WHERE id = (.+)").
+		WithArgs(entity.RoleAdmin, 1).
+		WillReturnError(fmt.Errorf("error"))
+	_, err = repo.SetUserRole(context.Background(), 1, entity.RoleAdmin)
+	assert.Error(t, err)
+
+	// снятие роли admin проверяется в транзакции, заблокировавшей строки администраторов
+	expectLockActiveAdmins(mock, 1, 2)
+	mock.ExpectExec("UPDATE users SET role = (.+) WHERE id = (.+)").
+		WithArgs(entity.RoleDefault, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	wasUpdated, err = repo.SetUserRole(context.Background(), 1, entity.RoleDefault)
+	assert.NoError(t, err)
+	assert.True(t, wasUpdated)
+
+	expectLockActiveAdmins(mock, 1)
+	mock.ExpectRollback()
+	_, err = repo.SetUserRole(context.Background(), 1, entity.RoleDefault)
+	assert.ErrorIs(t, err, ErrLastAdmin)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM users WHERE role = (.+) AND NOT is_disabled FOR UPDATE").
+		WithArgs(entity.RoleAdmin).
+		WillReturnError(fmt.Errorf("error"))
+	mock.ExpectRollback()
+	_, err = repo.SetUserRole(context.Background(), 1, entity.RoleDefault)
+	assert.Error(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func expectLockActiveAdmins(mock sqlmock.Sqlmock, adminIDs ...uint64) {
+	rows := sqlmock.NewRows([]string{"id"})
+	for _, adminID := range adminIDs {
+		rows.AddRow(adminID)
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM users WHERE role = (.+) AND NOT is_disabled FOR UPDATE").
+		WithArgs(entity.RoleAdmin).
+		WillReturnRows(rows)
+}
+
+func TestGetUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+
+	expectedUsers := []entity.User{
+		{ID: 1, Username: "admin", Role: entity.RoleAdmin},
+		{ID: 2, Username: "user", Role: entity.RoleDefault, IsDisabled: true},
+	}
+	mock.ExpectQuery("SELECT COUNT(.+) FROM users WHERE username ILIKE").
+		WithArgs("a").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectQuery("SELECT id, username, role, is_disabled FROM users WHERE username ILIKE (.+) ORDER BY id LIMIT").
+		WithArgs("a", 2, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "role", "is_disabled"}).
+			AddRow(1, "admin", entity.RoleAdmin, false).
+			AddRow(2, "user", entity.RoleDefault, true))
+	users, total, err := repo.GetUsers(context.Background(), "a", 2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUsers, users)
+	assert.Equal(t, uint64(5), total)
+
+	mock.ExpectQuery("SELECT COUNT(.+) FROM users WHERE username ILIKE").
+		WithArgs("").
+		WillReturnError(fmt.Errorf("error"))
+	users, _, err = repo.GetUsers(context.Background(), "", 0, 0)
+	assert.Error(t, err)
+	assert.Nil(t, users)
+
+	mock.ExpectQuery("SELECT COUNT(.+) FROM users WHERE username ILIKE").
+		WithArgs("").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectQuery("SELECT id, username, role, is_disabled FROM users WHERE username ILIKE (.+) ORDER BY id LIMIT").
+		WithArgs("", 0, 0).
+		WillReturnError(fmt.Errorf("error"))
+	users, _, err = repo.GetUsers(context.Background(), "", 0, 0)
+	assert.Error(t, err)
+	assert.Nil(t, users)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestGetUserByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+
+	expectedUser := &entity.User{ID: 1, Username: "admin", Role: entity.RoleAdmin}
+	mock.ExpectQuery("SELECT id, username, role, is_disabled FROM users WHERE id = ?").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "role", "is_disabled"}).
+			AddRow(1, "admin", entity.RoleAdmin, false))
+	user, err := repo.GetUserByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUser, user)
+
+	mock.ExpectQuery("SELECT id, username, role, is_disabled FROM users WHERE id = ?").
+		WithArgs(2).
+		WillReturnError(sql.ErrNoRows)
+	user, err = repo.GetUserByID(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Nil(t, user)
+
+	mock.ExpectQuery("SELECT id, username, role, is_disabled FROM users WHERE id = ?").
+		WithArgs(1).
+		WillReturnError(fmt.Errorf("error"))
+	user, err = repo.GetUserByID(context.Background(), 1)
+	assert.Error(t, err)
+	assert.Nil(t, user)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestGetUsersByEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+
+	mock.ExpectQuery("SELECT id, username, role, is_disabled FROM users WHERE LOWER\\(email\\) = LOWER\\(\\$1\\)").
+		WithArgs("User@Example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "role", "is_disabled"}).
+			AddRow(1, "user", entity.RoleDefault, false).
+			AddRow(3, "user2", entity.RoleEditor, true))
+	users, err := repo.GetUsersByEmail(context.Background(), "User@Example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.User{
+		{ID: 1, Username: "user", Role: entity.RoleDefault},
+		{ID: 3, Username: "user2", Role: entity.RoleEditor, IsDisabled: true},
+	}, users)
+
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE LOWER\\(email\\)").
+		WithArgs("nobody@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "role", "is_disabled"}))
+	users, err = repo.GetUsersByEmail(context.Background(), "nobody@example.com")
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE LOWER\\(email\\)").
+		WillReturnError(fmt.Errorf("error"))
+	_, err = repo.GetUsersByEmail(context.Background(), "user@example.com")
+	assert.Error(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestSetUserDisabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+
+	expectLockActiveAdmins(mock, 2)
+	mock.ExpectExec("UPDATE users SET is_disabled = (.+) WHERE id = (.+)").
+		WithArgs(true, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	wasUpdated, err := repo.SetUserDisabled(context.Background(), 1, true)
+	assert.NoError(t, err)
+	assert.True(t, wasUpdated)
+
+	mock.ExpectExec("UPDATE users SET is_disabled = (.+) WHERE id = (.+)").
+		WithArgs(false, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	wasUpdated, err = repo.SetUserDisabled(context.Background(), 2, false)
+	assert.NoError(t, err)
+	assert.False(t, wasUpdated)
+
+	expectLockActiveAdmins(mock, 1)
+	mock.ExpectRollback()
+	_, err = repo.SetUserDisabled(context.Background(), 1, true)
+	assert.ErrorIs(t, err, ErrLastAdmin)
+
+	expectLockActiveAdmins(mock, 2)
+	mock.ExpectExec("UPDATE users SET is_disabled = (.+) WHERE id = (.+)").
+		WithArgs(true, 1).
+		WillReturnError(fmt.Errorf("error"))
+	mock.ExpectRollback()
+	_, err = repo.SetUserDisabled(context.Background(), 1, true)
+	assert.Error(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestDeleteUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+
+	expectLockActiveAdmins(mock, 1, 2)
+	mock.ExpectExec("DELETE FROM users WHERE id = (.+)").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	wasDeleted, err := repo.DeleteUser(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.True(t, wasDeleted)
+
+	// последний администратор не мешает удалять других пользователей
+	expectLockActiveAdmins(mock, 1)
+	mock.ExpectExec("DELETE FROM users WHERE id = (.+)").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	wasDeleted, err = repo.DeleteUser(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.False(t, wasDeleted)
+
+	expectLockActiveAdmins(mock, 1)
+	mock.ExpectRollback()
+	_, err = repo.DeleteUser(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrLastAdmin)
+
+	expectLockActiveAdmins(mock, 1, 2)
+	mock.ExpectExec("DELETE FROM users WHERE id = (.+)").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("error"))
+	_, err = repo.DeleteUser(context.Background(), 1)
+	assert.Error(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestGetPasswordHashByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+
+	mock.ExpectQuery("SELECT password FROM users WHERE id = (.+)").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"password"}).AddRow("hash"))
+	passwordHash, err := repo.GetPasswordHashByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "hash", passwordHash)
+
+	mock.ExpectQuery("SELECT password FROM users WHERE id = (.+)").
+		WithArgs(2).
+		WillReturnError(sql.ErrNoRows)
+	passwordHash, err = repo.GetPasswordHashByID(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "", passwordHash)
+
+	mock.ExpectQuery("SELECT password FROM users WHERE id = (.+)").
+		WithArgs(1).
+		WillReturnError(fmt.Errorf("error"))
+	_, err = repo.GetPasswordHashByID(context.Background(), 1)
+	assert.Error(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestGetUserEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+
+	mock.ExpectQuery("SELECT email FROM users WHERE id = (.+)").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("user@example.com"))
+	email, err := repo.GetUserEmail(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "user@example.com", email)
+
+	mock.ExpectQuery("SELECT email FROM users WHERE id = (.+)").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow(nil))
+	email, err = repo.GetUserEmail(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "", email)
+
+	mock.ExpectQuery("SELECT email FROM users WHERE id = (.+)").
+		WithArgs(2).
+		WillReturnError(sql.ErrNoRows)
+	email, err = repo.GetUserEmail(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "", email)
+
+	mock.ExpectQuery("SELECT email FROM users WHERE id = (.+)").
+		WithArgs(1).
+		WillReturnError(fmt.Errorf("error"))
+	_, err = repo.GetUserEmail(context.Background(), 1)
+	assert.Error(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestGetProfile(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+	createdAt := time.Now()
+	profileColumns := []string{"id", "username", "role", "email", "display_name", "avatar_url", "preferred_language", "created_at"}
+
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+)").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(profileColumns).
+			AddRow(1, "hello12", entity.RoleDefault, nil, "Hello", "", "en", createdAt))
+	profile, err := repo.GetProfile(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.Profile{UserID: 1, Username: "hello12", Role: entity.RoleDefault, DisplayName: "Hello",
+		PreferredLanguage: "en", CreatedAt: createdAt}, profile)
+
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+)").
+		WithArgs(2).
+		WillReturnError(sql.ErrNoRows)
+	profile, err = repo.GetProfile(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Nil(t, profile)
+
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+)").
+		WithArgs(1).
+		WillReturnError(fmt.Errorf("error"))
+	_, err = repo.GetProfile(context.Background(), 1)
+	assert.Error(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestUpdateProfile(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+	profile := &entity.Profile{UserID: 1, Username: "hello12", Email: "user@example.com", DisplayName: "Hello",
+		AvatarURL: "https://example.com/avatar.png", PreferredLanguage: "en"}
+
+	mock.ExpectExec("UPDATE users SET (.+) WHERE id = (.+)").
+		WithArgs("hello12", "user@example.com", "Hello", "https://example.com/avatar.png", "en", uint64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	wasUpdated, err := repo.UpdateProfile(context.Background(), profile)
+	assert.NoError(t, err)
+	assert.True(t, wasUpdated)
+
+	mock.ExpectExec("UPDATE users SET (.+) WHERE id = (.+)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	wasUpdated, err = repo.UpdateProfile(context.Background(), profile)
+	assert.NoError(t, err)
+	assert.False(t, wasUpdated)
+
+	mock.ExpectExec("UPDATE users SET (.+) WHERE id = (.+)").
+		WillReturnError(fmt.Errorf("error"))
+	_, err = repo.UpdateProfile(context.Background(), profile)
+	assert.Error(t, err)
+
+	mock.ExpectExec("UPDATE users SET (.+) WHERE id = (.+)").
+		WillReturnError(pgx.PgError{Code: "23505", ConstraintName: "idx_users_username_lower"})
+	_, err = repo.UpdateProfile(context.Background(), profile)
+	assert.ErrorIs(t, err, ErrUserAlreadyExists)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestCancelledContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepo(db)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name string
+		call func() error
+	}{
+		{name: "GetUserPasswordHash", call: func() error {
+			_, _, err := repo.GetUserPasswordHash(ctx, "testuser")
+			return err
+		}},
+		{name: "GetPasswordHashByID", call: func() error {
+			_, err := repo.GetPasswordHashByID(ctx, 1)
+			return err
+		}},
+		{name: "UpdatePasswordHash", call: func() error {
+			_, err := repo.UpdatePasswordHash(ctx, 1, "hash")
+			return err
+		}},
+		{name: "Register", call: func() error {
+			_, err := repo.Register(ctx, "testuser", "testpassword", "", entity.RoleDefault)
+			return err
+		}},
+		{name: "GetUserByUsername", call: func() error {
+			_, err := repo.GetUserByUsername(ctx, "testuser")
+			return err
+		}},
+		{name: "GetUserEmail", call: func() error {
+			_, err := repo.GetUserEmail(ctx, 1)
+			return err
+		}},
+		{name: "GetProfile", call: func() error {
+			_, err := repo.GetProfile(ctx, 1)
+			return err
+		}},
+		{name: "GetUserRole", call: func() error {
+			_, err := repo.GetUserRole(ctx, 1)
+			return err
+		}},
+		{name: "RoleExists", call: func() error {
+			_, err := repo.RoleExists(ctx, entity.RoleAdmin)
+			return err
+		}},
+		{name: "GetRolePermissions", call: func() error {
+			_, err := repo.GetRolePermissions(ctx, entity.RoleAdmin)
+			return err
+		}},
+		{name: "SetUserRole", call: func() error {
+			_, err := repo.SetUserRole(ctx, 1, entity.RoleAdmin)
+			return err
+		}},
+		{name: "GetUsers", call: func() error {
+			_, _, err := repo.GetUsers(ctx, "", 10, 0)
+			return err
+		}},
+		{name: "GetUserByID", call: func() error {
+			_, err := repo.GetUserByID(ctx, 1)
+			return err
+		}},
+		{name: "GetUsersByEmail", call: func() error {
+			_, err := repo.GetUsersByEmail(ctx, "user@example.com")
+			return err
+		}},
+		{name: "SetUserDisabled", call: func() error {
+			_, err := repo.SetUserDisabled(ctx, 1, true)
+			return err
+		}},
+		{name: "DeleteUser", call: func() error {
+			_, err := repo.DeleteUser(ctx, 1)
+			return err
+		}},
+		{name: "SetUserRole to default", call: func() error {
+			_, err := repo.SetUserRole(ctx, 1, entity.RoleDefault)
+			return err
+		}},
+		{name: "SetUserDisabled to false", call: func() error {
+			_, err := repo.SetUserDisabled(ctx, 1, false)
+			return err
+		}},
+	}
+	for _, tc := range testCases {
+		assert.ErrorIs(t, tc.call(), context.Canceled, tc.name)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
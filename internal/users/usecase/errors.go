@@ -1,9 +1,16 @@
 package usecase
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/ilyushkaaa/Filmoteka/internal/users/repo"
+)
 
 var (
 	ErrBadCredentials    = errors.New("bad auth data for user")
-	ErrUserAlreadyExists = errors.New("user with such username already exists")
+	ErrUserAlreadyExists = repo.ErrUserAlreadyExists
 	ErrNoUser            = errors.New("user not exists")
+	ErrBadRole           = errors.New("unknown user role")
+	ErrUserDisabled      = errors.New("user is disabled")
+	ErrLastAdmin         = repo.ErrLastAdmin
 )
@@ -5,6 +5,7 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -34,47 +35,267 @@ func (m *MockUserUseCase) EXPECT() *MockUserUseCaseMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockUserUseCase) ChangePassword(ctx context.Context, userID uint64, currentPassword, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userID, currentPassword, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUserUseCaseMockRecorder) ChangePassword(ctx, userID, currentPassword, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUserUseCase)(nil).ChangePassword), ctx, userID, currentPassword, newPassword)
+}
+
+// CreateUser mocks base method.
+func (m *MockUserUseCase) CreateUser(ctx context.Context, username, password, email, role string) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, username, password, email, role)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockUserUseCaseMockRecorder) CreateUser(ctx, username, password, email, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserUseCase)(nil).CreateUser), ctx, username, password, email, role)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockUserUseCase) DeleteAccount(ctx context.Context, userID uint64, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, userID, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockUserUseCaseMockRecorder) DeleteAccount(ctx, userID, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockUserUseCase)(nil).DeleteAccount), ctx, userID, password)
+}
+
+// DeleteUser mocks base method.
+func (m *MockUserUseCase) DeleteUser(ctx context.Context, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUserUseCaseMockRecorder) DeleteUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserUseCase)(nil).DeleteUser), ctx, userID)
+}
+
+// GetProfile mocks base method.
+func (m *MockUserUseCase) GetProfile(ctx context.Context, userID uint64) (*entity.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", ctx, userID)
+	ret0, _ := ret[0].(*entity.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockUserUseCaseMockRecorder) GetProfile(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockUserUseCase)(nil).GetProfile), ctx, userID)
+}
+
+// GetRolePermissions mocks base method.
+func (m *MockUserUseCase) GetRolePermissions(ctx context.Context, role string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRolePermissions", ctx, role)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRolePermissions indicates an expected call of GetRolePermissions.
+func (mr *MockUserUseCaseMockRecorder) GetRolePermissions(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRolePermissions", reflect.TypeOf((*MockUserUseCase)(nil).GetRolePermissions), ctx, role)
+}
+
+// GetUserByID mocks base method.
+func (m *MockUserUseCase) GetUserByID(ctx context.Context, userID uint64) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserUseCaseMockRecorder) GetUserByID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserUseCase)(nil).GetUserByID), ctx, userID)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockUserUseCase) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", ctx, username)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockUserUseCaseMockRecorder) GetUserByUsername(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUserUseCase)(nil).GetUserByUsername), ctx, username)
+}
+
+// GetUserPermissions mocks base method.
+func (m *MockUserUseCase) GetUserPermissions(ctx context.Context, userID uint64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserPermissions", ctx, userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserPermissions indicates an expected call of GetUserPermissions.
+func (mr *MockUserUseCaseMockRecorder) GetUserPermissions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPermissions", reflect.TypeOf((*MockUserUseCase)(nil).GetUserPermissions), ctx, userID)
+}
+
 // GetUserRole mocks base method.
-func (m *MockUserUseCase) GetUserRole(userID uint64) (string, error) {
+func (m *MockUserUseCase) GetUserRole(ctx context.Context, userID uint64) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserRole", userID)
+	ret := m.ctrl.Call(m, "GetUserRole", ctx, userID)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserRole indicates an expected call of GetUserRole.
-func (mr *MockUserUseCaseMockRecorder) GetUserRole(userID interface{}) *gomock.Call {
+func (mr *MockUserUseCaseMockRecorder) GetUserRole(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRole", reflect.TypeOf((*MockUserUseCase)(nil).GetUserRole), ctx, userID)
+}
+
+// GetUsers mocks base method.
+func (m *MockUserUseCase) GetUsers(ctx context.Context, search string, limit, offset uint64) ([]entity.User, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", ctx, search, limit, offset)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockUserUseCaseMockRecorder) GetUsers(ctx, search, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserUseCase)(nil).GetUsers), ctx, search, limit, offset)
+}
+
+// GetUsersByEmail mocks base method.
+func (m *MockUserUseCase) GetUsersByEmail(ctx context.Context, email string) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByEmail", ctx, email)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByEmail indicates an expected call of GetUsersByEmail.
+func (mr *MockUserUseCaseMockRecorder) GetUsersByEmail(ctx, email interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRole", reflect.TypeOf((*MockUserUseCase)(nil).GetUserRole), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByEmail", reflect.TypeOf((*MockUserUseCase)(nil).GetUsersByEmail), ctx, email)
 }
 
 // Login mocks base method.
-func (m *MockUserUseCase) Login(username, password string) (*entity.User, error) {
+func (m *MockUserUseCase) Login(ctx context.Context, username, password string) (*entity.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", username, password)
+	ret := m.ctrl.Call(m, "Login", ctx, username, password)
 	ret0, _ := ret[0].(*entity.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Login indicates an expected call of Login.
-func (mr *MockUserUseCaseMockRecorder) Login(username, password interface{}) *gomock.Call {
+func (mr *MockUserUseCaseMockRecorder) Login(ctx, username, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserUseCase)(nil).Login), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserUseCase)(nil).Login), ctx, username, password)
 }
 
 // Register mocks base method.
-func (m *MockUserUseCase) Register(username, password string) (*entity.User, error) {
+func (m *MockUserUseCase) Register(ctx context.Context, username, password, email string) (*entity.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Register", username, password)
+	ret := m.ctrl.Call(m, "Register", ctx, username, password, email)
 	ret0, _ := ret[0].(*entity.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Register indicates an expected call of Register.
-func (mr *MockUserUseCaseMockRecorder) Register(username, password interface{}) *gomock.Call {
+func (mr *MockUserUseCaseMockRecorder) Register(ctx, username, password, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUserUseCase)(nil).Register), ctx, username, password, email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockUserUseCase) ResetPassword(ctx context.Context, userID uint64, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, userID, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUserUseCaseMockRecorder) ResetPassword(ctx, userID, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserUseCase)(nil).ResetPassword), ctx, userID, newPassword)
+}
+
+// SetUserDisabled mocks base method.
+func (m *MockUserUseCase) SetUserDisabled(ctx context.Context, userID uint64, isDisabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserDisabled", ctx, userID, isDisabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserDisabled indicates an expected call of SetUserDisabled.
+func (mr *MockUserUseCaseMockRecorder) SetUserDisabled(ctx, userID, isDisabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserDisabled", reflect.TypeOf((*MockUserUseCase)(nil).SetUserDisabled), ctx, userID, isDisabled)
+}
+
+// SetUserRole mocks base method.
+func (m *MockUserUseCase) SetUserRole(ctx context.Context, userID uint64, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockUserUseCaseMockRecorder) SetUserRole(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockUserUseCase)(nil).SetUserRole), ctx, userID, role)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockUserUseCase) UpdateProfile(ctx context.Context, userID uint64, update *entity.ProfileUpdate) (*entity.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, userID, update)
+	ret0, _ := ret[0].(*entity.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockUserUseCaseMockRecorder) UpdateProfile(ctx, userID, update interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUserUseCase)(nil).Register), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserUseCase)(nil).UpdateProfile), ctx, userID, update)
 }
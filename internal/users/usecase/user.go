@@ -1,21 +1,44 @@
 package usecase
 
 import (
+	"context"
+	"sync"
+
 	"github.com/ilyushkaaa/Filmoteka/internal/users/entity"
 	"github.com/ilyushkaaa/Filmoteka/internal/users/repo"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
 	passwordHash "github.com/ilyushkaaa/Filmoteka/pkg/password_hash"
 )
 
+const dummyPassword = "filmoteka-dummy-password"
+
 //go:generate mockgen -source=user.go -destination=user_mock.go -package=usecase UserUseCase
 type UserUseCase interface {
-	Login(username, password string) (*entity.User, error)
-	Register(username, password string) (*entity.User, error)
-	GetUserRole(userID uint64) (string, error)
+	Login(ctx context.Context, username, password string) (*entity.User, error)
+	Register(ctx context.Context, username, password, email string) (*entity.User, error)
+	GetUserRole(ctx context.Context, userID uint64) (string, error)
+	GetUserPermissions(ctx context.Context, userID uint64) ([]string, error)
+	GetRolePermissions(ctx context.Context, role string) ([]string, error)
+	CreateUser(ctx context.Context, username, password, email, role string) (*entity.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*entity.User, error)
+	GetUsers(ctx context.Context, search string, limit, offset uint64) ([]entity.User, uint64, error)
+	GetUserByID(ctx context.Context, userID uint64) (*entity.User, error)
+	GetUsersByEmail(ctx context.Context, email string) ([]entity.User, error)
+	SetUserRole(ctx context.Context, userID uint64, role string) error
+	SetUserDisabled(ctx context.Context, userID uint64, isDisabled bool) error
+	DeleteUser(ctx context.Context, userID uint64) error
+	ResetPassword(ctx context.Context, userID uint64, newPassword string) error
+	ChangePassword(ctx context.Context, userID uint64, currentPassword, newPassword string) error
+	GetProfile(ctx context.Context, userID uint64) (*entity.Profile, error)
+	UpdateProfile(ctx context.Context, userID uint64, update *entity.ProfileUpdate) (*entity.Profile, error)
+	DeleteAccount(ctx context.Context, userID uint64, password string) error
 }
 
 type UserUseCaseApp struct {
-	userRepo repo.UserRepo
-	hasher   passwordHash.Hasher
+	userRepo      repo.UserRepo
+	hasher        passwordHash.Hasher
+	dummyHash     string
+	dummyHashOnce sync.Once
 }
 
 func NewUserUseCase(userRepo repo.UserRepo, hasher passwordHash.Hasher) *UserUseCaseApp {
@@ -25,23 +48,64 @@ func NewUserUseCase(userRepo repo.UserRepo, hasher passwordHash.Hasher) *UserUse
 	}
 }
 
-func (uc *UserUseCaseApp) Login(username, password string) (*entity.User, error) {
-	hashPassword, err := uc.hasher.GetHashPassword(password)
+func (uc *UserUseCaseApp) Login(ctx context.Context, username, password string) (*entity.User, error) {
+	loggedInUser, passwordHash, err := uc.userRepo.GetUserPasswordHash(ctx, username)
 	if err != nil {
 		return nil, err
 	}
-	loggedInUser, err := uc.userRepo.Login(username, hashPassword)
+	if loggedInUser == nil {
+		// выравнивает время ответа для несуществующего пользователя
+		_, _ = uc.hasher.VerifyPassword(password, uc.getDummyHash())
+		return nil, ErrBadCredentials
+	}
+	isValid, err := uc.hasher.VerifyPassword(password, passwordHash)
 	if err != nil {
 		return nil, err
 	}
-	if loggedInUser == nil {
+	if !isValid {
 		return nil, ErrBadCredentials
 	}
+	if loggedInUser.IsDisabled {
+		return nil, ErrUserDisabled
+	}
+	if uc.hasher.NeedsRehash(passwordHash) {
+		uc.rehashPassword(ctx, loggedInUser.ID, password)
+	}
 	return loggedInUser, nil
 }
 
-func (uc *UserUseCaseApp) Register(username, password string) (*entity.User, error) {
-	loggedInUser, err := uc.userRepo.GetUserByUsername(username)
+func (uc *UserUseCaseApp) rehashPassword(ctx context.Context, userID uint64, password string) {
+	newPasswordHash, err := uc.hasher.GetHashPassword(password)
+	if err != nil {
+		logger.ErrorfFromContext(ctx, "error in rehashing password of user %d: %s", userID, err)
+		return
+	}
+	isUpdated, err := uc.userRepo.UpdatePasswordHash(ctx, userID, newPasswordHash)
+	if err != nil {
+		logger.ErrorfFromContext(ctx, "error in updating password hash of user %d: %s", userID, err)
+		return
+	}
+	if !isUpdated {
+		logger.ErrorfFromContext(ctx, "password hash of user %d was not updated: user not found", userID)
+	}
+}
+
+func (uc *UserUseCaseApp) getDummyHash() string {
+	uc.dummyHashOnce.Do(func() {
+		uc.dummyHash, _ = uc.hasher.GetHashPassword(dummyPassword)
+	})
+	return uc.dummyHash
+}
+
+func (uc *UserUseCaseApp) Register(ctx context.Context, username, password, email string) (*entity.User, error) {
+	return uc.CreateUser(ctx, username, password, email, entity.RoleDefault)
+}
+
+func (uc *UserUseCaseApp) CreateUser(ctx context.Context, username, password, email, role string) (*entity.User, error) {
+	if err := uc.checkRoleExists(ctx, role); err != nil {
+		return nil, err
+	}
+	loggedInUser, err := uc.userRepo.GetUserByUsername(ctx, username)
 	if err != nil {
 		return nil, err
 	}
@@ -53,15 +117,15 @@ func (uc *UserUseCaseApp) Register(username, password string) (*entity.User, err
 		return nil, err
 	}
 
-	newUser, err := uc.userRepo.Register(username, hashPassword)
+	newUser, err := uc.userRepo.Register(ctx, username, hashPassword, email, role)
 	if err != nil || newUser == nil {
 		return nil, err
 	}
 	return newUser, nil
 }
 
-func (uc *UserUseCaseApp) GetUserRole(userID uint64) (string, error) {
-	role, err := uc.userRepo.GetUserRole(userID)
+func (uc *UserUseCaseApp) GetUserRole(ctx context.Context, userID uint64) (string, error) {
+	role, err := uc.userRepo.GetUserRole(ctx, userID)
 	if err != nil {
 		return "", err
 	}
@@ -70,3 +134,187 @@ func (uc *UserUseCaseApp) GetUserRole(userID uint64) (string, error) {
 	}
 	return role, nil
 }
+
+func (uc *UserUseCaseApp) GetUserPermissions(ctx context.Context, userID uint64) ([]string, error) {
+	role, err := uc.GetUserRole(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.GetRolePermissions(ctx, role)
+}
+
+func (uc *UserUseCaseApp) GetRolePermissions(ctx context.Context, role string) ([]string, error) {
+	permissions, err := uc.userRepo.GetRolePermissions(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (uc *UserUseCaseApp) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
+	user, err := uc.userRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrNoUser
+	}
+	return user, nil
+}
+
+func (uc *UserUseCaseApp) GetUsers(ctx context.Context, search string, limit, offset uint64) ([]entity.User, uint64, error) {
+	users, total, err := uc.userRepo.GetUsers(ctx, search, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (uc *UserUseCaseApp) GetUserByID(ctx context.Context, userID uint64) (*entity.User, error) {
+	user, err := uc.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrNoUser
+	}
+	return user, nil
+}
+
+func (uc *UserUseCaseApp) GetUsersByEmail(ctx context.Context, email string) ([]entity.User, error) {
+	users, err := uc.userRepo.GetUsersByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (uc *UserUseCaseApp) SetUserRole(ctx context.Context, userID uint64, role string) error {
+	if err := uc.checkRoleExists(ctx, role); err != nil {
+		return err
+	}
+	wasUpdated, err := uc.userRepo.SetUserRole(ctx, userID, role)
+	if err != nil {
+		return err
+	}
+	if !wasUpdated {
+		return ErrNoUser
+	}
+	return nil
+}
+
+func (uc *UserUseCaseApp) ResetPassword(ctx context.Context, userID uint64, newPassword string) error {
+	hashPassword, err := uc.hasher.GetHashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	wasUpdated, err := uc.userRepo.UpdatePasswordHash(ctx, userID, hashPassword)
+	if err != nil {
+		return err
+	}
+	if !wasUpdated {
+		return ErrNoUser
+	}
+	return nil
+}
+
+func (uc *UserUseCaseApp) ChangePassword(ctx context.Context, userID uint64, currentPassword, newPassword string) error {
+	if err := uc.checkPassword(ctx, userID, currentPassword); err != nil {
+		return err
+	}
+	return uc.ResetPassword(ctx, userID, newPassword)
+}
+
+func (uc *UserUseCaseApp) GetProfile(ctx context.Context, userID uint64) (*entity.Profile, error) {
+	profile, err := uc.userRepo.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, ErrNoUser
+	}
+	return profile, nil
+}
+
+func (uc *UserUseCaseApp) UpdateProfile(ctx context.Context, userID uint64, update *entity.ProfileUpdate) (*entity.Profile, error) {
+	profile, err := uc.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if update.Username != nil && *update.Username != profile.Username {
+		owner, err := uc.userRepo.GetUserByUsername(ctx, *update.Username)
+		if err != nil {
+			return nil, err
+		}
+		if owner != nil && owner.ID != userID {
+			return nil, ErrUserAlreadyExists
+		}
+	}
+	update.Apply(profile)
+	wasUpdated, err := uc.userRepo.UpdateProfile(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+	if !wasUpdated {
+		return nil, ErrNoUser
+	}
+	return profile, nil
+}
+
+func (uc *UserUseCaseApp) DeleteAccount(ctx context.Context, userID uint64, password string) error {
+	if err := uc.checkPassword(ctx, userID, password); err != nil {
+		return err
+	}
+	return uc.DeleteUser(ctx, userID)
+}
+
+func (uc *UserUseCaseApp) SetUserDisabled(ctx context.Context, userID uint64, isDisabled bool) error {
+	wasUpdated, err := uc.userRepo.SetUserDisabled(ctx, userID, isDisabled)
+	if err != nil {
+		return err
+	}
+	if !wasUpdated {
+		return ErrNoUser
+	}
+	return nil
+}
+
+func (uc *UserUseCaseApp) DeleteUser(ctx context.Context, userID uint64) error {
+	wasDeleted, err := uc.userRepo.DeleteUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !wasDeleted {
+		return ErrNoUser
+	}
+	return nil
+}
+
+func (uc *UserUseCaseApp) checkPassword(ctx context.Context, userID uint64, password string) error {
+	passwordHash, err := uc.userRepo.GetPasswordHashByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if passwordHash == "" {
+		return ErrNoUser
+	}
+	isValid, err := uc.hasher.VerifyPassword(password, passwordHash)
+	if err != nil {
+		return err
+	}
+	if !isValid {
+		return ErrBadCredentials
+	}
+	return nil
+}
+
+func (uc *UserUseCaseApp) checkRoleExists(ctx context.Context, role string) error {
+	roleExists, err := uc.userRepo.RoleExists(ctx, role)
+	if err != nil {
+		return err
+	}
+	if !roleExists {
+		return ErrBadRole
+	}
+	return nil
+}
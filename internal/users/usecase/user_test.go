@@ -1,14 +1,19 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/ilyushkaaa/Filmoteka/internal/users/entity"
+	"github.com/ilyushkaaa/Filmoteka/internal/users/repo"
 	"github.com/ilyushkaaa/Filmoteka/internal/users/repo/mock"
+	"github.com/ilyushkaaa/Filmoteka/pkg/logger"
 	"github.com/ilyushkaaa/Filmoteka/pkg/password_hash"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 type errorHasher struct{}
@@ -17,39 +22,114 @@ func (h *errorHasher) GetHashPassword(password string) (string, error) {
 	return "", fmt.Errorf("error")
 }
 
+func (h *errorHasher) VerifyPassword(password, encodedHash string) (bool, error) {
+	return false, fmt.Errorf("error")
+}
+
+func (h *errorHasher) NeedsRehash(encodedHash string) bool {
+	return false
+}
+
+// countingHasher запоминает хеши, с которыми сверялся пароль
+type countingHasher struct {
+	password_hash.Hasher
+	verifiedHashes []string
+}
+
+func (h *countingHasher) VerifyPassword(password, encodedHash string) (bool, error) {
+	h.verifiedHashes = append(h.verifiedHashes, encodedHash)
+	return h.Hasher.VerifyPassword(password, encodedHash)
+}
+
 func TestLogin(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	testRepo := mock.NewMockUserRepo(ctrl)
-	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+	hasher := &countingHasher{Hasher: &password_hash.Argon2idHasher{Time: 1, Memory: 1024, Threads: 1, KeyLen: 16, SaltLen: 8}}
+	testUseCase := NewUserUseCase(testRepo, hasher)
+	observedCore, observedLogs := observer.New(zap.ErrorLevel)
+	ctx := context.WithValue(context.Background(), logger.MyLoggerKey, zap.New(observedCore).Sugar())
+
+	passwordHash, err := hasher.GetHashPassword("11111111")
+	assert.Equal(t, nil, err)
+	legacyPasswordHash := "ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1"
 
 	var userExpected *entity.User
+	returnedUser := &entity.User{ID: 1, Username: "aaa"}
 
-	errorUseCase := NewUserUseCase(testRepo, &errorHasher{})
-	user, err := errorUseCase.Login("aaa", "11111111")
+	testRepo.EXPECT().GetUserPasswordHash(gomock.Any(), "aaa").Return(nil, "", fmt.Errorf("error"))
+	user, err := testUseCase.Login(context.Background(), "aaa", "11111111")
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, userExpected, user)
 
-	testRepo.EXPECT().Login("aaa", "ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1").
-		Return(nil, fmt.Errorf("error"))
-	user, err = testUseCase.Login("aaa", "11111111")
+	// для несуществующего пользователя пароль сверяется с фиктивным хешем того же хешера
+	testRepo.EXPECT().GetUserPasswordHash(gomock.Any(), "aaa").Return(nil, "", nil)
+	user, err = testUseCase.Login(context.Background(), "aaa", "11111111")
+	assert.Equal(t, ErrBadCredentials, err)
+	assert.Equal(t, userExpected, user)
+	if assert.Len(t, hasher.verifiedHashes, 1) {
+		isValid, err := password_hash.VerifyPassword(dummyPassword, hasher.verifiedHashes[0])
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, isValid)
+	}
+
+	errorUseCase := NewUserUseCase(testRepo, &errorHasher{})
+	testRepo.EXPECT().GetUserPasswordHash(gomock.Any(), "aaa").Return(returnedUser, passwordHash, nil)
+	user, err = errorUseCase.Login(context.Background(), "aaa", "11111111")
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, userExpected, user)
 
-	testRepo.EXPECT().Login("aaa", "ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1").
-		Return(nil, nil)
-	user, err = testUseCase.Login("aaa", "11111111")
+	testRepo.EXPECT().GetUserPasswordHash(gomock.Any(), "aaa").Return(returnedUser, passwordHash, nil)
+	user, err = testUseCase.Login(context.Background(), "aaa", "22222222")
 	assert.Equal(t, ErrBadCredentials, err)
 	assert.Equal(t, userExpected, user)
 
-	returnedUser := &entity.User{}
-	testRepo.EXPECT().Login("aaa", "ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1").
-		Return(returnedUser, nil)
-	user, err = testUseCase.Login("aaa", "11111111")
+	testRepo.EXPECT().GetUserPasswordHash(gomock.Any(), "aaa").Return(returnedUser, passwordHash, nil)
+	user, err = testUseCase.Login(context.Background(), "aaa", "11111111")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, returnedUser, user)
+
+	disabledUser := &entity.User{ID: 1, Username: "aaa", IsDisabled: true}
+	testRepo.EXPECT().GetUserPasswordHash(gomock.Any(), "aaa").Return(disabledUser, passwordHash, nil)
+	user, err = testUseCase.Login(context.Background(), "aaa", "11111111")
+	assert.Equal(t, ErrUserDisabled, err)
+	assert.Equal(t, userExpected, user)
+
+	testRepo.EXPECT().GetUserPasswordHash(gomock.Any(), "aaa").Return(returnedUser, legacyPasswordHash, nil)
+	testRepo.EXPECT().UpdatePasswordHash(gomock.Any(), returnedUser.ID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, userID uint64, newPasswordHash string) (bool, error) {
+			isValid, err := password_hash.VerifyPassword("11111111", newPasswordHash)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, true, isValid)
+			assert.Equal(t, false, hasher.NeedsRehash(newPasswordHash))
+			return true, nil
+		})
+	user, err = testUseCase.Login(context.Background(), "aaa", "11111111")
 	assert.Equal(t, nil, err)
 	assert.Equal(t, returnedUser, user)
 
+	testRepo.EXPECT().GetUserPasswordHash(gomock.Any(), "aaa").Return(returnedUser, legacyPasswordHash, nil)
+	testRepo.EXPECT().UpdatePasswordHash(gomock.Any(), returnedUser.ID, gomock.Any()).Return(false, fmt.Errorf("error"))
+	user, err = testUseCase.Login(ctx, "aaa", "11111111")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, returnedUser, user)
+
+	testRepo.EXPECT().GetUserPasswordHash(gomock.Any(), "aaa").Return(returnedUser, legacyPasswordHash, nil)
+	testRepo.EXPECT().UpdatePasswordHash(gomock.Any(), returnedUser.ID, gomock.Any()).Return(false, nil)
+	user, err = testUseCase.Login(ctx, "aaa", "11111111")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, returnedUser, user)
+
+	// неудачное обновление хеша не мешает входу, но попадает в лог
+	loggedMessages := make([]string, 0, observedLogs.Len())
+	for _, entry := range observedLogs.All() {
+		loggedMessages = append(loggedMessages, entry.Message)
+	}
+	assert.Equal(t, []string{
+		"error in updating password hash of user 1: error",
+		"password hash of user 1 was not updated: user not found",
+	}, loggedMessages)
 }
 
 func TestRegister(t *testing.T) {
@@ -58,39 +138,417 @@ func TestRegister(t *testing.T) {
 
 	testRepo := mock.NewMockUserRepo(ctrl)
 	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+	testRepo.EXPECT().RoleExists(gomock.Any(), entity.RoleDefault).Return(true, nil).AnyTimes()
 
-	testRepo.EXPECT().GetUserByUsername("aaa").Return(nil, fmt.Errorf("error"))
-	user, err := testUseCase.Register("aaa", "11111111")
+	testRepo.EXPECT().GetUserByUsername(gomock.Any(), "aaa").Return(nil, fmt.Errorf("error"))
+	user, err := testUseCase.Register(context.Background(), "aaa", "11111111", "aaa@example.com")
 	var userExpected *entity.User
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, userExpected, user)
 
 	returnedUser := &entity.User{}
-	testRepo.EXPECT().GetUserByUsername("aaa").Return(returnedUser, nil)
-	user, err = testUseCase.Register("aaa", "11111111")
+	testRepo.EXPECT().GetUserByUsername(gomock.Any(), "aaa").Return(returnedUser, nil)
+	user, err = testUseCase.Register(context.Background(), "aaa", "11111111", "aaa@example.com")
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, userExpected, user)
 
-	testRepo.EXPECT().GetUserByUsername("aaa").Return(nil, nil)
-	testRepo.EXPECT().Register("aaa",
-		"ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1").
+	testRepo.EXPECT().GetUserByUsername(gomock.Any(), "aaa").Return(nil, nil)
+	testRepo.EXPECT().Register(gomock.Any(), "aaa",
+		"ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1", "aaa@example.com", entity.RoleDefault).
 		Return(nil, fmt.Errorf("error"))
-	user, err = testUseCase.Register("aaa", "11111111")
+	user, err = testUseCase.Register(context.Background(), "aaa", "11111111", "aaa@example.com")
 	assert.NotEqual(t, nil, err)
 	assert.Equal(t, userExpected, user)
 
-	testRepo.EXPECT().GetUserByUsername("aaa").Return(nil, nil)
-	testRepo.EXPECT().Register("aaa",
-		"ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1").
+	// другой пользователь зарегистрировался между проверкой и вставкой
+	testRepo.EXPECT().GetUserByUsername(gomock.Any(), "aaa").Return(nil, nil)
+	testRepo.EXPECT().Register(gomock.Any(), "aaa",
+		"ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1", "aaa@example.com", entity.RoleDefault).
+		Return(nil, repo.ErrUserAlreadyExists)
+	user, err = testUseCase.Register(context.Background(), "aaa", "11111111", "aaa@example.com")
+	assert.ErrorIs(t, err, ErrUserAlreadyExists)
+	assert.Equal(t, userExpected, user)
+
+	testRepo.EXPECT().GetUserByUsername(gomock.Any(), "aaa").Return(nil, nil)
+	testRepo.EXPECT().Register(gomock.Any(), "aaa",
+		"ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1", "aaa@example.com", entity.RoleDefault).
 		Return(returnedUser, nil)
-	user, err = testUseCase.Register("aaa", "11111111")
+	user, err = testUseCase.Register(context.Background(), "aaa", "11111111", "aaa@example.com")
 	assert.Equal(t, nil, err)
 	assert.Equal(t, returnedUser, user)
 
 	errorUseCase := NewUserUseCase(testRepo, &errorHasher{})
-	testRepo.EXPECT().GetUserByUsername("aaa").Return(nil, nil)
-	user, err = errorUseCase.Register("aaa", "11111111")
+	testRepo.EXPECT().GetUserByUsername(gomock.Any(), "aaa").Return(nil, nil)
+	user, err = errorUseCase.Register(context.Background(), "aaa", "11111111", "aaa@example.com")
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, userExpected, user)
+
+}
+
+func TestCreateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+
+	var userExpected *entity.User
+
+	testRepo.EXPECT().RoleExists(gomock.Any(), "superuser").Return(false, fmt.Errorf("error"))
+	user, err := testUseCase.CreateUser(context.Background(), "aaa", "11111111", "", "superuser")
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, userExpected, user)
+
+	testRepo.EXPECT().RoleExists(gomock.Any(), "superuser").Return(false, nil)
+	user, err = testUseCase.CreateUser(context.Background(), "aaa", "11111111", "", "superuser")
+	assert.Equal(t, ErrBadRole, err)
+	assert.Equal(t, userExpected, user)
+
+	returnedUser := &entity.User{ID: 1, Username: "aaa", Role: entity.RoleAdmin}
+	testRepo.EXPECT().RoleExists(gomock.Any(), entity.RoleAdmin).Return(true, nil)
+	testRepo.EXPECT().GetUserByUsername(gomock.Any(), "aaa").Return(nil, nil)
+	testRepo.EXPECT().Register(gomock.Any(), "aaa",
+		"ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1", "", entity.RoleAdmin).
+		Return(returnedUser, nil)
+	user, err = testUseCase.CreateUser(context.Background(), "aaa", "11111111", "", entity.RoleAdmin)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, returnedUser, user)
+}
+
+func TestGetUserPermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+
+	testRepo.EXPECT().GetUserRole(gomock.Any(), uint64(1)).Return("", nil)
+	permissions, err := testUseCase.GetUserPermissions(context.Background(), 1)
+	assert.Equal(t, ErrNoUser, err)
+	assert.Nil(t, permissions)
+
+	testRepo.EXPECT().GetUserRole(gomock.Any(), uint64(1)).Return(entity.RoleEditor, nil)
+	testRepo.EXPECT().GetRolePermissions(gomock.Any(), entity.RoleEditor).Return(nil, fmt.Errorf("error"))
+	permissions, err = testUseCase.GetUserPermissions(context.Background(), 1)
 	assert.NotEqual(t, nil, err)
+	assert.Nil(t, permissions)
+
+	returnedPermissions := []string{entity.PermissionActorWrite, entity.PermissionFilmWrite}
+	testRepo.EXPECT().GetUserRole(gomock.Any(), uint64(1)).Return(entity.RoleEditor, nil)
+	testRepo.EXPECT().GetRolePermissions(gomock.Any(), entity.RoleEditor).Return(returnedPermissions, nil)
+	permissions, err = testUseCase.GetUserPermissions(context.Background(), 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, returnedPermissions, permissions)
+}
+
+func TestGetUserByUsername(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+
+	var userExpected *entity.User
+
+	testRepo.EXPECT().GetUserByUsername(gomock.Any(), "aaa").Return(nil, fmt.Errorf("error"))
+	user, err := testUseCase.GetUserByUsername(context.Background(), "aaa")
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, userExpected, user)
+
+	testRepo.EXPECT().GetUserByUsername(gomock.Any(), "aaa").Return(nil, nil)
+	user, err = testUseCase.GetUserByUsername(context.Background(), "aaa")
+	assert.Equal(t, ErrNoUser, err)
+	assert.Equal(t, userExpected, user)
+
+	returnedUser := &entity.User{ID: 1, Username: "aaa"}
+	testRepo.EXPECT().GetUserByUsername(gomock.Any(), "aaa").Return(returnedUser, nil)
+	user, err = testUseCase.GetUserByUsername(context.Background(), "aaa")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, returnedUser, user)
+}
+
+func TestGetUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+
+	testRepo.EXPECT().GetUsers(gomock.Any(), "a", uint64(20), uint64(0)).Return(nil, uint64(0), fmt.Errorf("error"))
+	users, _, err := testUseCase.GetUsers(context.Background(), "a", 20, 0)
+	assert.NotEqual(t, nil, err)
+	assert.Nil(t, users)
+
+	returnedUsers := []entity.User{{ID: 1, Username: "aaa", Role: entity.RoleAdmin}}
+	testRepo.EXPECT().GetUsers(gomock.Any(), "a", uint64(20), uint64(0)).Return(returnedUsers, uint64(1), nil)
+	users, total, err := testUseCase.GetUsers(context.Background(), "a", 20, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, returnedUsers, users)
+	assert.Equal(t, uint64(1), total)
+}
+
+func TestGetUsersByEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+
+	testRepo.EXPECT().GetUsersByEmail(gomock.Any(), "aaa@example.com").Return(nil, fmt.Errorf("error"))
+	users, err := testUseCase.GetUsersByEmail(context.Background(), "aaa@example.com")
+	assert.NotEqual(t, nil, err)
+	assert.Nil(t, users)
+
+	returnedUsers := []entity.User{{ID: 1, Username: "aaa", Role: entity.RoleDefault}}
+	testRepo.EXPECT().GetUsersByEmail(gomock.Any(), "aaa@example.com").Return(returnedUsers, nil)
+	users, err = testUseCase.GetUsersByEmail(context.Background(), "aaa@example.com")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, returnedUsers, users)
+}
+
+func TestGetUserByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+	var userExpected *entity.User
+
+	testRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(nil, fmt.Errorf("error"))
+	user, err := testUseCase.GetUserByID(context.Background(), 1)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, userExpected, user)
+
+	testRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(nil, nil)
+	user, err = testUseCase.GetUserByID(context.Background(), 1)
+	assert.Equal(t, ErrNoUser, err)
 	assert.Equal(t, userExpected, user)
 
+	returnedUser := &entity.User{ID: 1, Username: "aaa", Role: entity.RoleDefault}
+	testRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(returnedUser, nil)
+	user, err = testUseCase.GetUserByID(context.Background(), 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, returnedUser, user)
+}
+
+func TestSetUserRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+	testRepo.EXPECT().RoleExists(gomock.Any(), entity.RoleAdmin).Return(true, nil).AnyTimes()
+	testRepo.EXPECT().RoleExists(gomock.Any(), entity.RoleDefault).Return(true, nil).AnyTimes()
+
+	testRepo.EXPECT().RoleExists(gomock.Any(), "superuser").Return(false, nil)
+	err := testUseCase.SetUserRole(context.Background(), 1, "superuser")
+	assert.Equal(t, ErrBadRole, err)
+
+	testRepo.EXPECT().SetUserRole(gomock.Any(), uint64(1), entity.RoleAdmin).Return(false, fmt.Errorf("error"))
+	err = testUseCase.SetUserRole(context.Background(), 1, entity.RoleAdmin)
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().SetUserRole(gomock.Any(), uint64(1), entity.RoleAdmin).Return(false, nil)
+	err = testUseCase.SetUserRole(context.Background(), 1, entity.RoleAdmin)
+	assert.Equal(t, ErrNoUser, err)
+
+	testRepo.EXPECT().SetUserRole(gomock.Any(), uint64(1), entity.RoleAdmin).Return(true, nil)
+	err = testUseCase.SetUserRole(context.Background(), 1, entity.RoleAdmin)
+	assert.Equal(t, nil, err)
+
+	testRepo.EXPECT().SetUserRole(gomock.Any(), uint64(1), entity.RoleDefault).Return(false, repo.ErrLastAdmin)
+	err = testUseCase.SetUserRole(context.Background(), 1, entity.RoleDefault)
+	assert.Equal(t, ErrLastAdmin, err)
+
+	testRepo.EXPECT().SetUserRole(gomock.Any(), uint64(1), entity.RoleDefault).Return(true, nil)
+	err = testUseCase.SetUserRole(context.Background(), 1, entity.RoleDefault)
+	assert.Equal(t, nil, err)
+}
+
+func TestSetUserDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+
+	testRepo.EXPECT().SetUserDisabled(gomock.Any(), uint64(1), true).Return(false, repo.ErrLastAdmin)
+	err := testUseCase.SetUserDisabled(context.Background(), 1, true)
+	assert.Equal(t, ErrLastAdmin, err)
+
+	testRepo.EXPECT().SetUserDisabled(gomock.Any(), uint64(2), true).Return(false, fmt.Errorf("error"))
+	err = testUseCase.SetUserDisabled(context.Background(), 2, true)
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().SetUserDisabled(gomock.Any(), uint64(2), true).Return(true, nil)
+	err = testUseCase.SetUserDisabled(context.Background(), 2, true)
+	assert.Equal(t, nil, err)
+
+	testRepo.EXPECT().SetUserDisabled(gomock.Any(), uint64(3), false).Return(false, nil)
+	err = testUseCase.SetUserDisabled(context.Background(), 3, false)
+	assert.Equal(t, ErrNoUser, err)
+
+	testRepo.EXPECT().SetUserDisabled(gomock.Any(), uint64(1), false).Return(true, nil)
+	err = testUseCase.SetUserDisabled(context.Background(), 1, false)
+	assert.Equal(t, nil, err)
+}
+
+func TestDeleteUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+
+	testRepo.EXPECT().DeleteUser(gomock.Any(), uint64(1)).Return(false, repo.ErrLastAdmin)
+	err := testUseCase.DeleteUser(context.Background(), 1)
+	assert.Equal(t, ErrLastAdmin, err)
+
+	testRepo.EXPECT().DeleteUser(gomock.Any(), uint64(2)).Return(false, fmt.Errorf("error"))
+	err = testUseCase.DeleteUser(context.Background(), 2)
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().DeleteUser(gomock.Any(), uint64(2)).Return(false, nil)
+	err = testUseCase.DeleteUser(context.Background(), 2)
+	assert.Equal(t, ErrNoUser, err)
+
+	testRepo.EXPECT().DeleteUser(gomock.Any(), uint64(1)).Return(true, nil)
+	err = testUseCase.DeleteUser(context.Background(), 1)
+	assert.Equal(t, nil, err)
+}
+
+func TestResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+	newHash := "ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1"
+
+	errorUseCase := NewUserUseCase(testRepo, &errorHasher{})
+	err := errorUseCase.ResetPassword(context.Background(), 1, "11111111")
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().UpdatePasswordHash(gomock.Any(), uint64(1), newHash).Return(false, fmt.Errorf("error"))
+	err = testUseCase.ResetPassword(context.Background(), 1, "11111111")
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().UpdatePasswordHash(gomock.Any(), uint64(1), newHash).Return(false, nil)
+	err = testUseCase.ResetPassword(context.Background(), 1, "11111111")
+	assert.Equal(t, ErrNoUser, err)
+
+	testRepo.EXPECT().UpdatePasswordHash(gomock.Any(), uint64(1), newHash).Return(true, nil)
+	err = testUseCase.ResetPassword(context.Background(), 1, "11111111")
+	assert.Equal(t, nil, err)
+}
+
+func TestChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+	currentHash := "ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1"
+	newHash := "33a7d3da476a32ac237b3f603a1be62fad00299e0d4b5a8db8d913104edec629"
+
+	testRepo.EXPECT().GetPasswordHashByID(gomock.Any(), uint64(1)).Return("", fmt.Errorf("error"))
+	err := testUseCase.ChangePassword(context.Background(), 1, "11111111", "22222222")
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().GetPasswordHashByID(gomock.Any(), uint64(1)).Return("", nil)
+	err = testUseCase.ChangePassword(context.Background(), 1, "11111111", "22222222")
+	assert.Equal(t, ErrNoUser, err)
+
+	testRepo.EXPECT().GetPasswordHashByID(gomock.Any(), uint64(1)).Return(currentHash, nil)
+	err = testUseCase.ChangePassword(context.Background(), 1, "33333333", "22222222")
+	assert.Equal(t, ErrBadCredentials, err)
+
+	testRepo.EXPECT().GetPasswordHashByID(gomock.Any(), uint64(1)).Return(currentHash, nil)
+	testRepo.EXPECT().UpdatePasswordHash(gomock.Any(), uint64(1), newHash).Return(true, nil)
+	err = testUseCase.ChangePassword(context.Background(), 1, "11111111", "22222222")
+	assert.Equal(t, nil, err)
+}
+
+func TestGetProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+	profile := &entity.Profile{UserID: 1, Username: "aaa", Role: entity.RoleDefault}
+
+	testRepo.EXPECT().GetProfile(gomock.Any(), uint64(1)).Return(nil, fmt.Errorf("error"))
+	_, err := testUseCase.GetProfile(context.Background(), 1)
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().GetProfile(gomock.Any(), uint64(1)).Return(nil, nil)
+	_, err = testUseCase.GetProfile(context.Background(), 1)
+	assert.Equal(t, ErrNoUser, err)
+
+	testRepo.EXPECT().GetProfile(gomock.Any(), uint64(1)).Return(profile, nil)
+	result, err := testUseCase.GetProfile(context.Background(), 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, profile, result)
+}
+
+func TestUpdateProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+	newProfile := func() *entity.Profile {
+		return &entity.Profile{UserID: 1, Username: "aaa", Role: entity.RoleDefault, DisplayName: "A", Email: "a@example.com"}
+	}
+	newUsername, displayName, email := "bbb", "B", ""
+
+	testRepo.EXPECT().GetProfile(gomock.Any(), uint64(1)).Return(nil, nil)
+	_, err := testUseCase.UpdateProfile(context.Background(), 1, &entity.ProfileUpdate{DisplayName: &displayName})
+	assert.Equal(t, ErrNoUser, err)
+
+	testRepo.EXPECT().GetProfile(gomock.Any(), uint64(1)).Return(newProfile(), nil)
+	testRepo.EXPECT().GetUserByUsername(gomock.Any(), "bbb").Return(&entity.User{ID: 2, Username: "bbb"}, nil)
+	_, err = testUseCase.UpdateProfile(context.Background(), 1, &entity.ProfileUpdate{Username: &newUsername})
+	assert.Equal(t, ErrUserAlreadyExists, err)
+
+	testRepo.EXPECT().GetProfile(gomock.Any(), uint64(1)).Return(newProfile(), nil)
+	testRepo.EXPECT().GetUserByUsername(gomock.Any(), "bbb").Return(nil, fmt.Errorf("error"))
+	_, err = testUseCase.UpdateProfile(context.Background(), 1, &entity.ProfileUpdate{Username: &newUsername})
+	assert.NotEqual(t, nil, err)
+
+	testRepo.EXPECT().GetProfile(gomock.Any(), uint64(1)).Return(newProfile(), nil)
+	testRepo.EXPECT().UpdateProfile(gomock.Any(), gomock.Any()).Return(false, nil)
+	_, err = testUseCase.UpdateProfile(context.Background(), 1, &entity.ProfileUpdate{DisplayName: &displayName})
+	assert.Equal(t, ErrNoUser, err)
+
+	expected := &entity.Profile{UserID: 1, Username: "bbb", Role: entity.RoleDefault, DisplayName: "B"}
+	testRepo.EXPECT().GetProfile(gomock.Any(), uint64(1)).Return(newProfile(), nil)
+	testRepo.EXPECT().GetUserByUsername(gomock.Any(), "bbb").Return(nil, nil)
+	testRepo.EXPECT().UpdateProfile(gomock.Any(), expected).Return(true, nil)
+	result, err := testUseCase.UpdateProfile(context.Background(), 1, &entity.ProfileUpdate{Username: &newUsername, DisplayName: &displayName,
+		Email: &email})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestDeleteAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testRepo := mock.NewMockUserRepo(ctrl)
+	testUseCase := NewUserUseCase(testRepo, &password_hash.SHA256Hasher{})
+	passwordHash := "ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1"
+
+	testRepo.EXPECT().GetPasswordHashByID(gomock.Any(), uint64(2)).Return("", nil)
+	err := testUseCase.DeleteAccount(context.Background(), 2, "11111111")
+	assert.Equal(t, ErrNoUser, err)
+
+	testRepo.EXPECT().GetPasswordHashByID(gomock.Any(), uint64(2)).Return(passwordHash, nil)
+	err = testUseCase.DeleteAccount(context.Background(), 2, "33333333")
+	assert.Equal(t, ErrBadCredentials, err)
+
+	testRepo.EXPECT().GetPasswordHashByID(gomock.Any(), uint64(2)).Return(passwordHash, nil)
+	testRepo.EXPECT().DeleteUser(gomock.Any(), uint64(2)).Return(true, nil)
+	err = testUseCase.DeleteAccount(context.Background(), 2, "11111111")
+	assert.Equal(t, nil, err)
 }
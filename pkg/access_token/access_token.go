@@ -0,0 +1,164 @@
+package access_token
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var ErrInvalidToken = errors.New("invalid access token")
+
+type Claims struct {
+	UserID uint64 `json:"uid"`
+	Role   string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+type signingKey struct {
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+type Manager struct {
+	method      jwt.SigningMethod
+	keys        map[string]signingKey
+	activeKeyID string
+	ttl         time.Duration
+}
+
+// NewManager принимает ключи в формате "kid1:base64,kid2:base64", для EdDSA - seed или приватный ключ ed25519
+func NewManager(algorithm, keys, activeKeyID string, ttl time.Duration) (*Manager, error) {
+	var method jwt.SigningMethod
+	switch algorithm {
+	case "", AlgorithmHS256:
+		method = jwt.SigningMethodHS256
+	case AlgorithmEdDSA:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unknown access token algorithm: %s", algorithm)
+	}
+	parsedKeys, err := parseKeys(method, keys)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := parsedKeys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active access token key %q is not in key set", activeKeyID)
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("access token ttl must be positive")
+	}
+	return &Manager{
+		method:      method,
+		keys:        parsedKeys,
+		activeKeyID: activeKeyID,
+		ttl:         ttl,
+	}, nil
+}
+
+func parseKeys(method jwt.SigningMethod, keys string) (map[string]signingKey, error) {
+	parsedKeys := make(map[string]signingKey)
+	for _, keyPair := range strings.Split(keys, ",") {
+		keyPair = strings.TrimSpace(keyPair)
+		if keyPair == "" {
+			continue
+		}
+		keyID, encodedKey, found := strings.Cut(keyPair, ":")
+		if !found || keyID == "" {
+			return nil, fmt.Errorf("access token key must be in format kid:base64")
+		}
+		rawKey, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("bad encoding of access token key %q: %w", keyID, err)
+		}
+		key, err := newSigningKey(method, rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("bad access token key %q: %w", keyID, err)
+		}
+		parsedKeys[keyID] = key
+	}
+	if len(parsedKeys) == 0 {
+		return nil, fmt.Errorf("no access token keys were passed")
+	}
+	return parsedKeys, nil
+}
+
+func newSigningKey(method jwt.SigningMethod, rawKey []byte) (signingKey, error) {
+	if method == jwt.SigningMethodHS256 {
+		if len(rawKey) < 32 {
+			return signingKey{}, fmt.Errorf("HS256 secret must be at least 32 bytes")
+		}
+		return signingKey{signKey: rawKey, verifyKey: rawKey}, nil
+	}
+	var privateKey ed25519.PrivateKey
+	switch len(rawKey) {
+	case ed25519.SeedSize:
+		privateKey = ed25519.NewKeyFromSeed(rawKey)
+	case ed25519.PrivateKeySize:
+		privateKey = ed25519.PrivateKey(rawKey)
+	default:
+		return signingKey{}, fmt.Errorf("ed25519 key must be %d or %d bytes", ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+	return signingKey{signKey: privateKey, verifyKey: privateKey.Public()}, nil
+}
+
+func (m *Manager) TTL() time.Duration {
+	return m.ttl
+}
+
+func (m *Manager) Issue(userID uint64, role string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.ttl)
+	claims := &Claims{
+		UserID: userID,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   strconv.FormatUint(userID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	token := jwt.NewWithClaims(m.method, claims)
+	token.Header["kid"] = m.activeKeyID
+	signedToken, err := token.SignedString(m.keys[m.activeKeyID].signKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signedToken, expiresAt, nil
+}
+
+func (m *Manager) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, m.getVerifyKey,
+		jwt.WithValidMethods([]string{m.method.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+func (m *Manager) getVerifyKey(token *jwt.Token) (interface{}, error) {
+	keyID, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, fmt.Errorf("token has no key id")
+	}
+	key, ok := m.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", keyID)
+	}
+	return key.verifyKey, nil
+}
@@ -0,0 +1,102 @@
+package access_token
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	hmacKey1 = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 32)))
+	hmacKey2 = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("b", 32)))
+	edSeed   = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("c", 32)))
+)
+
+func TestNewManager(t *testing.T) {
+	_, err := NewManager("RS256", "k1:"+hmacKey1, "k1", time.Minute)
+	assert.Error(t, err)
+
+	_, err = NewManager(AlgorithmHS256, "", "k1", time.Minute)
+	assert.Error(t, err)
+
+	_, err = NewManager(AlgorithmHS256, "k1", "k1", time.Minute)
+	assert.Error(t, err)
+
+	_, err = NewManager(AlgorithmHS256, "k1:not_base64", "k1", time.Minute)
+	assert.Error(t, err)
+
+	_, err = NewManager(AlgorithmHS256, "k1:"+base64.StdEncoding.EncodeToString([]byte("short")), "k1", time.Minute)
+	assert.Error(t, err)
+
+	_, err = NewManager(AlgorithmHS256, "k1:"+hmacKey1, "k2", time.Minute)
+	assert.Error(t, err)
+
+	_, err = NewManager(AlgorithmHS256, "k1:"+hmacKey1, "k1", 0)
+	assert.Error(t, err)
+
+	_, err = NewManager(AlgorithmEdDSA, "k1:"+hmacKey1+hmacKey1, "k1", time.Minute)
+	assert.Error(t, err)
+
+	_, err = NewManager(AlgorithmEdDSA, "k1:"+edSeed, "k1", time.Minute)
+	assert.NoError(t, err)
+}
+
+func TestIssueAndParse(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmHS256, AlgorithmEdDSA} {
+		key := hmacKey1
+		if algorithm == AlgorithmEdDSA {
+			key = edSeed
+		}
+		manager, err := NewManager(algorithm, "k1:"+key, "k1", time.Minute)
+		assert.NoError(t, err)
+
+		token, expiresAt, err := manager.Issue(7, "editor")
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, 5*time.Second)
+
+		claims, err := manager.Parse(token)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(7), claims.UserID)
+		assert.Equal(t, "editor", claims.Role)
+		assert.Equal(t, "7", claims.Subject)
+
+		_, err = manager.Parse(token + "x")
+		assert.True(t, errors.Is(err, ErrInvalidToken))
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldManager, err := NewManager(AlgorithmHS256, "k1:"+hmacKey1, "k1", time.Minute)
+	assert.NoError(t, err)
+	oldToken, _, err := oldManager.Issue(1, "default")
+	assert.NoError(t, err)
+
+	rotatedManager, err := NewManager(AlgorithmHS256, "k1:"+hmacKey1+",k2:"+hmacKey2, "k2", time.Minute)
+	assert.NoError(t, err)
+	_, err = rotatedManager.Parse(oldToken)
+	assert.NoError(t, err)
+
+	newToken, _, err := rotatedManager.Issue(1, "default")
+	assert.NoError(t, err)
+	_, err = oldManager.Parse(newToken)
+	assert.True(t, errors.Is(err, ErrInvalidToken))
+
+	retiredManager, err := NewManager(AlgorithmHS256, "k2:"+hmacKey2, "k2", time.Minute)
+	assert.NoError(t, err)
+	_, err = retiredManager.Parse(oldToken)
+	assert.True(t, errors.Is(err, ErrInvalidToken))
+}
+
+func TestExpiredToken(t *testing.T) {
+	manager, err := NewManager(AlgorithmHS256, "k1:"+hmacKey1, "k1", time.Nanosecond)
+	assert.NoError(t, err)
+	token, _, err := manager.Issue(1, "default")
+	assert.NoError(t, err)
+	time.Sleep(time.Second)
+	_, err = manager.Parse(token)
+	assert.True(t, errors.Is(err, ErrInvalidToken))
+}
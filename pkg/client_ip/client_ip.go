@@ -0,0 +1,15 @@
+package client_ip
+
+import (
+	"net"
+	"net/http"
+)
+
+// FromRequest не учитывает заголовки прокси, так как неизвестно, каким прокси можно доверять
+func FromRequest(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,26 @@
+package client_ip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromRequest(t *testing.T) {
+	testCases := []struct {
+		remoteAddr string
+		clientIP   string
+	}{
+		{remoteAddr: "192.0.2.1:1234", clientIP: "192.0.2.1"},
+		{remoteAddr: "[2001:db8::1]:1234", clientIP: "2001:db8::1"},
+		{remoteAddr: "192.0.2.1", clientIP: "192.0.2.1"},
+	}
+	for _, tc := range testCases {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.RemoteAddr = tc.remoteAddr
+		request.Header.Set("X-Forwarded-For", "198.51.100.1")
+		assert.Equal(t, tc.clientIP, FromRequest(request))
+	}
+}
@@ -0,0 +1,99 @@
+package cookie
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	SessionCookieName   = "session_id"
+	CSRFCookieName      = "csrf_token"
+	CSRFHeaderName      = "X-CSRF-Token"
+	OIDCStateCookieName = "oidc_state"
+)
+
+const csrfTokenSalt = "filmoteka-csrf:"
+
+type Config struct {
+	Domain   string
+	Path     string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+func NewConfig(domain, path string, secure bool, sameSite string) (*Config, error) {
+	if path == "" {
+		path = "/"
+	}
+	var sameSiteMode http.SameSite
+	switch strings.ToLower(sameSite) {
+	case "", "lax":
+		sameSiteMode = http.SameSiteLaxMode
+	case "strict":
+		sameSiteMode = http.SameSiteStrictMode
+	case "none":
+		if !secure {
+			return nil, fmt.Errorf("SameSite=None cookies must be secure")
+		}
+		sameSiteMode = http.SameSiteNoneMode
+	default:
+		return nil, fmt.Errorf("unknown SameSite mode: %s", sameSite)
+	}
+	return &Config{
+		Domain:   domain,
+		Path:     path,
+		Secure:   secure,
+		SameSite: sameSiteMode,
+	}, nil
+}
+
+func (c *Config) SetSessionCookies(w http.ResponseWriter, sessionID string, expiresAt time.Time) {
+	http.SetCookie(w, c.newCookie(SessionCookieName, sessionID, expiresAt, true))
+	http.SetCookie(w, c.newCookie(CSRFCookieName, CSRFToken(sessionID), expiresAt, false))
+}
+
+func (c *Config) ClearSessionCookies(w http.ResponseWriter) {
+	for _, name := range []string{SessionCookieName, CSRFCookieName} {
+		expiredCookie := c.newCookie(name, "", time.Unix(0, 0), name == SessionCookieName)
+		expiredCookie.MaxAge = -1
+		http.SetCookie(w, expiredCookie)
+	}
+}
+
+// SetOIDCStateCookie заменяет SameSite=Strict на Lax: провайдер возвращает пользователя межсайтовым переходом
+func (c *Config) SetOIDCStateCookie(w http.ResponseWriter, state string, expiresAt time.Time) {
+	stateCookie := c.newCookie(OIDCStateCookieName, state, expiresAt, true)
+	if stateCookie.SameSite == http.SameSiteStrictMode {
+		stateCookie.SameSite = http.SameSiteLaxMode
+	}
+	http.SetCookie(w, stateCookie)
+}
+
+func (c *Config) ClearOIDCStateCookie(w http.ResponseWriter) {
+	expiredCookie := c.newCookie(OIDCStateCookieName, "", time.Unix(0, 0), true)
+	expiredCookie.MaxAge = -1
+	http.SetCookie(w, expiredCookie)
+}
+
+func (c *Config) newCookie(name, value string, expiresAt time.Time, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		Expires:  expiresAt,
+		Secure:   c.Secure,
+		HttpOnly: httpOnly,
+		SameSite: c.SameSite,
+	}
+}
+
+// CSRFToken выводит токен из идентификатора сессии, который недоступен стороннему сайту
+func CSRFToken(sessionID string) string {
+	hash := sha256.Sum256([]byte(csrfTokenSalt + sessionID))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
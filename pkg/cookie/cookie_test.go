@@ -0,0 +1,91 @@
+package cookie
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfig(t *testing.T) {
+	config, err := NewConfig("", "", false, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "/", config.Path)
+	assert.Equal(t, http.SameSiteLaxMode, config.SameSite)
+
+	config, err = NewConfig("films.example", "/api", true, "Strict")
+	assert.NoError(t, err)
+	assert.Equal(t, http.SameSiteStrictMode, config.SameSite)
+
+	_, err = NewConfig("", "/", false, "none")
+	assert.Error(t, err)
+
+	_, err = NewConfig("", "/", true, "sometimes")
+	assert.Error(t, err)
+}
+
+func TestSetAndClearSessionCookies(t *testing.T) {
+	config, err := NewConfig("films.example", "/", true, "strict")
+	assert.NoError(t, err)
+	expiresAt := time.Now().Add(time.Hour)
+
+	recorder := httptest.NewRecorder()
+	config.SetSessionCookies(recorder, "session", expiresAt)
+	cookies := recorder.Result().Cookies()
+	if assert.Len(t, cookies, 2) {
+		assert.Equal(t, SessionCookieName, cookies[0].Name)
+		assert.Equal(t, "session", cookies[0].Value)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.True(t, cookies[0].Secure)
+		assert.Equal(t, "films.example", cookies[0].Domain)
+		assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+		assert.Equal(t, expiresAt.Unix(), cookies[0].Expires.Unix())
+
+		assert.Equal(t, CSRFCookieName, cookies[1].Name)
+		assert.Equal(t, CSRFToken("session"), cookies[1].Value)
+		assert.False(t, cookies[1].HttpOnly)
+	}
+
+	recorder = httptest.NewRecorder()
+	config.ClearSessionCookies(recorder)
+	cookies = recorder.Result().Cookies()
+	if assert.Len(t, cookies, 2) {
+		for _, cookie := range cookies {
+			assert.Equal(t, "", cookie.Value)
+			assert.Equal(t, -1, cookie.MaxAge)
+		}
+	}
+}
+
+func TestCSRFToken(t *testing.T) {
+	assert.Equal(t, CSRFToken("session"), CSRFToken("session"))
+	assert.NotEqual(t, CSRFToken("session"), CSRFToken("other_session"))
+	assert.NotContains(t, CSRFToken("session"), "session")
+}
+
+func TestSetAndClearOIDCStateCookie(t *testing.T) {
+	config, err := NewConfig("films.example", "/", true, "strict")
+	assert.NoError(t, err)
+	expiresAt := time.Now().Add(10 * time.Minute)
+
+	recorder := httptest.NewRecorder()
+	config.SetOIDCStateCookie(recorder, "state", expiresAt)
+	cookies := recorder.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, OIDCStateCookieName, cookies[0].Name)
+		assert.Equal(t, "state", cookies[0].Value)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.True(t, cookies[0].Secure)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	}
+
+	recorder = httptest.NewRecorder()
+	config.ClearOIDCStateCookie(recorder)
+	cookies = recorder.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, "", cookies[0].Value)
+		assert.Equal(t, -1, cookies[0].MaxAge)
+	}
+}
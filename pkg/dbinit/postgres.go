@@ -3,7 +3,6 @@ package dbinit
 import (
 	"database/sql"
 	"fmt"
-	"os"
 	"time"
 
 	_ "github.com/jackc/pgx/stdlib"
@@ -14,15 +13,9 @@ const (
 	maxPingDBAttempts = 20
 )
 
-func GetPostgres() (*sql.DB, error) {
-	pass := os.Getenv("pass")
-	user := os.Getenv("user")
-	dbName := os.Getenv("dbName")
-	host := os.Getenv("hostPG")
-	port := os.Getenv("portPG")
-	sslMode := os.Getenv("sslMode")
+func GetPostgres(host, port, user, password, dbName, sslMode string) (*sql.DB, error) {
 	dsn := fmt.Sprintf("user=%s dbname=%s password=%s host=%s port=%s sslmode=%s",
-		user, dbName, pass, host, port, sslMode)
+		user, dbName, password, host, port, sslMode)
 	db, err := sql.Open("pgx", dsn)
 
 	if err != nil {
@@ -2,18 +2,52 @@ package dbinit
 
 import (
 	"fmt"
-	"os"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
 )
 
-func GetRedis() (redis.Conn, error) {
-	host := os.Getenv("hostRD")
-	port := os.Getenv("portRD")
-	c, err := redis.DialURL(fmt.Sprintf("redis://user:@%s:%s/0", host, port))
+// RedisOptions - ограничения пула и таймауты соединений с Redis
+type RedisOptions struct {
+	MaxIdle             int
+	MaxActive           int
+	IdleTimeout         time.Duration
+	ConnectTimeout      time.Duration
+	ReadTimeout         time.Duration
+	WriteTimeout        time.Duration
+	HealthCheckInterval time.Duration
+}
+
+func GetRedis(host, port string, opts RedisOptions) (*redis.Pool, error) {
+	redisURL := fmt.Sprintf("redis://user:@%s:%s/0", host, port)
+	pool := &redis.Pool{
+		MaxIdle:     opts.MaxIdle,
+		MaxActive:   opts.MaxActive,
+		IdleTimeout: opts.IdleTimeout,
+		Wait:        true,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(redisURL,
+				redis.DialConnectTimeout(opts.ConnectTimeout),
+				redis.DialReadTimeout(opts.ReadTimeout),
+				redis.DialWriteTimeout(opts.WriteTimeout),
+			)
+		},
+		TestOnBorrow: func(c redis.Conn, lastUsed time.Time) error {
+			if time.Since(lastUsed) < opts.HealthCheckInterval {
+				return nil
+			}
+			_, err := c.Do("PING")
+			return err
+		},
+	}
+	conn := pool.Get()
+	_, err := conn.Do("PING")
+	if closeErr := conn.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
+		_ = pool.Close()
 		return nil, err
 	}
-	return c, nil
-
+	return pool, nil
 }
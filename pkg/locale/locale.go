@@ -0,0 +1,88 @@
+package locale
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const langQueryParam = "lang"
+
+// FromRequest возвращает языки в порядке предпочтения: сначала ?lang=, затем Accept-Language по убыванию q
+func FromRequest(r *http.Request) []string {
+	langs := make([]string, 0)
+	if lang := Normalize(r.URL.Query().Get(langQueryParam)); lang != "" {
+		langs = append(langs, lang)
+	}
+	for _, lang := range parseAcceptLanguage(r.Header.Get("Accept-Language")) {
+		if !contains(langs, lang) {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
+
+// Normalize приводит языковой тег (например, "en-US") к коду языка в нижнем регистре ("en").
+func Normalize(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	tag = strings.ToLower(tag)
+	if len(tag) < 2 || len(tag) > 3 {
+		return ""
+	}
+	for _, c := range tag {
+		if c < 'a' || c > 'z' {
+			return ""
+		}
+	}
+	return tag
+}
+
+type weightedLang struct {
+	lang    string
+	quality float64
+}
+
+func parseAcceptLanguage(header string) []string {
+	weighted := make([]weightedLang, 0)
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		params = strings.TrimSpace(params)
+		if strings.HasPrefix(params, "q=") {
+			parsed, err := strconv.ParseFloat(params[len("q="):], 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		lang := Normalize(tag)
+		if lang == "" || quality <= 0 {
+			continue
+		}
+		weighted = append(weighted, weightedLang{lang: lang, quality: quality})
+	}
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].quality > weighted[j].quality
+	})
+
+	langs := make([]string, 0, len(weighted))
+	for _, w := range weighted {
+		if !contains(langs, w.lang) {
+			langs = append(langs, w.lang)
+		}
+	}
+	return langs
+}
+
+func contains(langs []string, lang string) bool {
+	for _, l := range langs {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package locale
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "en", Normalize("en-US"))
+	assert.Equal(t, "ru", Normalize(" RU "))
+	assert.Equal(t, "pt", Normalize("pt_BR"))
+	assert.Equal(t, "", Normalize("*"))
+	assert.Equal(t, "", Normalize("english"))
+	assert.Equal(t, "", Normalize("e1"))
+}
+
+func TestFromRequest(t *testing.T) {
+	testCases := []struct {
+		url            string
+		acceptLanguage string
+		expected       []string
+	}{
+		{url: "/films", expected: []string{}},
+		{url: "/films?lang=ru", acceptLanguage: "en-US,en;q=0.9", expected: []string{"ru", "en"}},
+		{url: "/films?lang=en", acceptLanguage: "en-US,ru;q=0.9", expected: []string{"en", "ru"}},
+		{url: "/films?lang=12", acceptLanguage: "en-US", expected: []string{"en"}},
+		{url: "/films", acceptLanguage: "de;q=0.5, ru;q=0.8, *;q=0.1", expected: []string{"ru", "de"}},
+		{url: "/films", acceptLanguage: "fr;q=abc, en;q=0.3", expected: []string{"en"}},
+		{url: "/films", acceptLanguage: "fr, de;q=0, pt-BR;q=0.7, pt;q=0.9", expected: []string{"fr", "pt"}},
+	}
+	for _, tc := range testCases {
+		request := httptest.NewRequest(http.MethodGet, tc.url, nil)
+		if tc.acceptLanguage != "" {
+			request.Header.Set("Accept-Language", tc.acceptLanguage)
+		}
+		assert.Equal(t, tc.expected, FromRequest(request), tc.url+" "+tc.acceptLanguage)
+	}
+}
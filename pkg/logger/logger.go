@@ -29,3 +29,13 @@ func GetLoggerFromContext(ctx context.Context) (*zap.SugaredLogger, error) {
 	}
 	return myLogger, nil
 }
+
+// ErrorfFromContext пишет в стандартный лог, если логгера нет в контексте
+func ErrorfFromContext(ctx context.Context, format string, args ...interface{}) {
+	myLogger, err := GetLoggerFromContext(ctx)
+	if err != nil {
+		log.Printf(format, args...)
+		return
+	}
+	myLogger.Errorf(format, args...)
+}
@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+const messageSeparator = "\r\n----------\r\n"
+
+type FileMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+func NewFileMailer(w io.Writer, from string) *FileMailer {
+	return &FileMailer{
+		w:    w,
+		from: from,
+	}
+}
+
+func (m *FileMailer) Send(to, subject, body string) error {
+	message, err := buildMessage(m.from, to, subject, body, time.Now())
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err = m.w.Write(append(message, messageSeparator...))
+	return err
+}
@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+var ErrBadHeader = errors.New("mail header contains line break")
+
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// buildMessage запрещает переводы строк в заголовках, чтобы в письмо нельзя было добавить чужие заголовки
+func buildMessage(from, to, subject, body string, date time.Time) ([]byte, error) {
+	for _, header := range []string{from, to, subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, ErrBadHeader
+		}
+	}
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", to)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	message.WriteString("\r\n")
+	return message.Bytes(), nil
+}
@@ -0,0 +1,105 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMailer(t *testing.T) {
+	var output bytes.Buffer
+	testMailer := NewFileMailer(&output, "noreply@filmoteka.local")
+
+	err := testMailer.Send("user@example.com", "Сброс пароля", "first line\nsecond line")
+	assert.NoError(t, err)
+	message := output.String()
+	assert.Contains(t, message, "From: noreply@filmoteka.local\r\n")
+	assert.Contains(t, message, "To: user@example.com\r\n")
+	assert.Contains(t, message, "Subject: =?utf-8?q?")
+	assert.Contains(t, message, "\r\n\r\nfirst line\r\nsecond line\r\n")
+
+	output.Reset()
+	err = testMailer.Send("user@example.com\r\nBcc: other@example.com", "subject", "body")
+	assert.ErrorIs(t, err, ErrBadHeader)
+	err = testMailer.Send("user@example.com", "subject\nBcc: other@example.com", "body")
+	assert.ErrorIs(t, err, ErrBadHeader)
+	assert.Empty(t, output.String())
+}
+
+// serveSMTP принимает одно письмо по минимальному подмножеству SMTP и возвращает полученные команды и данные
+func serveSMTP(t *testing.T, listener net.Listener, received chan<- []string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Errorf("error in accepting connection: %s", err)
+		close(received)
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	writeLine := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+	lines := make([]string, 0)
+	writeLine("220 localhost ESMTP")
+	inData := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+		lines = append(lines, line)
+		if inData {
+			if line == "." {
+				inData = false
+				writeLine("250 OK")
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+			writeLine("250 localhost")
+		case line == "DATA":
+			inData = true
+			writeLine("354 go ahead")
+		case line == "QUIT":
+			writeLine("221 bye")
+			received <- lines
+			return
+		default:
+			writeLine("250 OK")
+		}
+	}
+	received <- lines
+}
+
+func TestSMTPMailer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error in starting smtp server: %s", err)
+	}
+	defer listener.Close()
+	received := make(chan []string, 1)
+	go serveSMTP(t, listener, received)
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	testMailer := NewSMTPMailer("127.0.0.1", port, "", "", "noreply@filmoteka.local")
+	assert.Equal(t, "127.0.0.1:"+strconv.Itoa(port), testMailer.addr)
+
+	err = testMailer.Send("user@example.com", "subject", "reset token")
+	assert.NoError(t, err)
+	lines := <-received
+	session := strings.Join(lines, "\n")
+	assert.Contains(t, session, "MAIL FROM:<noreply@filmoteka.local>")
+	assert.Contains(t, session, "RCPT TO:<user@example.com>")
+	assert.Contains(t, session, "To: user@example.com")
+	assert.Contains(t, session, "reset token")
+
+	err = testMailer.Send("user@example.com\nBcc: other@example.com", "subject", "body")
+	assert.ErrorIs(t, err, ErrBadHeader)
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		auth: auth,
+		from: from,
+	}
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	message, err := buildMessage(m.from, to, subject, body, time.Now())
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, message)
+}
@@ -0,0 +1,246 @@
+// Package oidctest - локальный OpenID Connect провайдер для тестов и разработки
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	keyID    = "oidctest"
+	tokenTTL = time.Hour
+)
+
+type User struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Name              string
+	Groups            []string
+}
+
+type authorization struct {
+	user          User
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+}
+
+type Server struct {
+	URL          string
+	ClientID     string
+	ClientSecret string
+
+	httpServer *httptest.Server
+	key        *rsa.PrivateKey
+
+	mu    sync.Mutex
+	user  User
+	codes map[string]authorization
+}
+
+func NewServer(clientID, clientSecret string) (*Server, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		codes:        make(map[string]authorization),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.handleDiscovery)
+	mux.HandleFunc("/jwks", s.handleJWKS)
+	mux.HandleFunc("/authorize", s.handleAuthorize)
+	mux.HandleFunc("/token", s.handleToken)
+	s.httpServer = httptest.NewServer(mux)
+	s.URL = s.httpServer.URL
+	return s, nil
+}
+
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+func (s *Server) SetUser(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = user
+}
+
+// Authorize выполняет запрос браузера к /authorize и возвращает адрес перенаправления на callback
+func (s *Server) Authorize(authURL string) (*url.URL, error) {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	return resp.Location()
+}
+
+func (s *Server) handleDiscovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, _ *http.Request) {
+	publicKey := s.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}},
+	})
+}
+
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != s.ClientID || query.Get("response_type") != "code" {
+		writeError(w, "unauthorized_client")
+		return
+	}
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		writeError(w, "invalid_request")
+		return
+	}
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Host == "" {
+		writeError(w, "invalid_request")
+		return
+	}
+	code, err := randomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.mu.Lock()
+	s.codes[code] = authorization{
+		user:          s.user,
+		redirectURI:   query.Get("redirect_uri"),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+	}
+	s.mu.Unlock()
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeError(w, "invalid_request")
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != s.ClientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(s.ClientSecret)) != 1 {
+		writeError(w, "invalid_client")
+		return
+	}
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	auth, ok := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+	if !ok || auth.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeError(w, "invalid_grant")
+		return
+	}
+	verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(verifierHash[:]) != auth.codeChallenge {
+		writeError(w, "invalid_grant")
+		return
+	}
+	idToken, err := s.signIDToken(auth)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	accessToken, err := randomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int(tokenTTL.Seconds()),
+		"id_token":     idToken,
+	})
+}
+
+func (s *Server) signIDToken(auth authorization) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":                s.URL,
+		"aud":                s.ClientID,
+		"sub":                auth.user.Subject,
+		"iat":                now.Unix(),
+		"exp":                now.Add(tokenTTL).Unix(),
+		"nonce":              auth.nonce,
+		"email":              auth.user.Email,
+		"email_verified":     auth.user.EmailVerified,
+		"preferred_username": auth.user.PreferredUsername,
+		"name":               auth.user.Name,
+		"groups":             auth.user.Groups,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(s.key)
+}
+
+func randomString() (string, error) {
+	randomBytes := make([]byte, 16)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
+
+func writeError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,113 @@
+package password_hash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const argon2idPrefix = "$argon2id$"
+
+const (
+	DefaultArgon2Time    uint32 = 3
+	DefaultArgon2Memory  uint32 = 64 * 1024
+	DefaultArgon2Threads uint8  = 2
+	DefaultArgon2KeyLen  uint32 = 32
+	DefaultArgon2SaltLen uint32 = 16
+)
+
+// Argon2idHasher хранит параметры в самом хеше (формат PHC), поэтому их можно менять
+type Argon2idHasher struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+	KeyLen  uint32
+	SaltLen uint32
+}
+
+func NewArgon2idHasher() *Argon2idHasher {
+	return &Argon2idHasher{
+		Time:    DefaultArgon2Time,
+		Memory:  DefaultArgon2Memory,
+		Threads: DefaultArgon2Threads,
+		KeyLen:  DefaultArgon2KeyLen,
+		SaltLen: DefaultArgon2SaltLen,
+	}
+}
+
+type argon2idParams struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func (h *Argon2idHasher) GetHashPassword(password string) (string, error) {
+	salt := make([]byte, h.SaltLen)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, h.KeyLen)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *Argon2idHasher) VerifyPassword(password, encodedHash string) (bool, error) {
+	return VerifyPassword(password, encodedHash)
+}
+
+func (h *Argon2idHasher) NeedsRehash(encodedHash string) bool {
+	params, err := decodeArgon2id(encodedHash)
+	if err != nil {
+		return true
+	}
+	return params.memory != h.Memory || params.time != h.Time || params.threads != h.Threads ||
+		uint32(len(params.salt)) != h.SaltLen || uint32(len(params.key)) != h.KeyLen
+}
+
+func verifyArgon2id(password, encodedHash string) (bool, error) {
+	params, err := decodeArgon2id(encodedHash)
+	if err != nil {
+		return false, err
+	}
+	key := argon2.IDKey([]byte(password), params.salt, params.time, params.memory, params.threads, uint32(len(params.key)))
+	return subtle.ConstantTimeCompare(key, params.key) == 1, nil
+}
+
+func decodeArgon2id(encodedHash string) (*argon2idParams, error) {
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrUnknownHashFormat
+	}
+	var version int
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil {
+		return nil, fmt.Errorf("bad argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+	params := &argon2idParams{}
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads)
+	if err != nil {
+		return nil, fmt.Errorf("bad argon2id params: %w", err)
+	}
+	params.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, fmt.Errorf("bad argon2id salt: %w", err)
+	}
+	params.key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, fmt.Errorf("bad argon2id key: %w", err)
+	}
+	if len(params.key) == 0 {
+		return nil, ErrUnknownHashFormat
+	}
+	return params, nil
+}
@@ -0,0 +1,54 @@
+package password_hash
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type BcryptHasher struct {
+	Cost int
+}
+
+func NewBcryptHasher() *BcryptHasher {
+	return &BcryptHasher{
+		Cost: bcrypt.DefaultCost,
+	}
+}
+
+func (h *BcryptHasher) GetHashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h *BcryptHasher) VerifyPassword(password, encodedHash string) (bool, error) {
+	return VerifyPassword(password, encodedHash)
+}
+
+func (h *BcryptHasher) NeedsRehash(encodedHash string) bool {
+	if !isBcryptHash(encodedHash) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	return err != nil || cost != h.Cost
+}
+
+func isBcryptHash(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$2a$") || strings.HasPrefix(encodedHash, "$2b$") ||
+		strings.HasPrefix(encodedHash, "$2y$")
+}
+
+func verifyBcrypt(password, encodedHash string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
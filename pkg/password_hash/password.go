@@ -2,13 +2,90 @@ package password_hash
 
 import (
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
 )
 
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
 type Hasher interface {
 	GetHashPassword(password string) (string, error)
+	VerifyPassword(password, encodedHash string) (bool, error)
+	NeedsRehash(encodedHash string) bool
+}
+
+// Params - Argon2Memory задается в КиБ
+type Params struct {
+	Argon2Time    int
+	Argon2Memory  int
+	Argon2Threads int
+	BcryptCost    int
+}
+
+func DefaultParams() Params {
+	return Params{
+		Argon2Time:    int(DefaultArgon2Time),
+		Argon2Memory:  int(DefaultArgon2Memory),
+		Argon2Threads: int(DefaultArgon2Threads),
+		BcryptCost:    bcrypt.DefaultCost,
+	}
+}
+
+func NewHasher(algorithm string, params Params) (Hasher, error) {
+	switch algorithm {
+	case "", AlgorithmArgon2id:
+		if params.Argon2Time < 1 || int64(params.Argon2Time) > math.MaxUint32 {
+			return nil, fmt.Errorf("argon2 time must be between 1 and %d", uint32(math.MaxUint32))
+		}
+		if params.Argon2Threads < 1 || params.Argon2Threads > math.MaxUint8 {
+			return nil, fmt.Errorf("argon2 threads must be between 1 and %d", math.MaxUint8)
+		}
+		if params.Argon2Memory < 8*params.Argon2Threads || int64(params.Argon2Memory) > math.MaxUint32 {
+			return nil, fmt.Errorf("argon2 memory must be at least 8 KiB per thread")
+		}
+		hasher := NewArgon2idHasher()
+		hasher.Time = uint32(params.Argon2Time)
+		hasher.Memory = uint32(params.Argon2Memory)
+		hasher.Threads = uint8(params.Argon2Threads)
+		return hasher, nil
+	case AlgorithmBcrypt:
+		if params.BcryptCost < bcrypt.MinCost || params.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		hasher := NewBcryptHasher()
+		hasher.Cost = params.BcryptCost
+		return hasher, nil
+	default:
+		return nil, fmt.Errorf("unknown password hashing algorithm: %s", algorithm)
+	}
+}
+
+// VerifyPassword определяет алгоритм по формату сохраненного хеша
+func VerifyPassword(password, encodedHash string) (bool, error) {
+	switch {
+	case strings.HasPrefix(encodedHash, argon2idPrefix):
+		return verifyArgon2id(password, encodedHash)
+	case isBcryptHash(encodedHash):
+		return verifyBcrypt(password, encodedHash)
+	case isSHA256Hash(encodedHash):
+		return verifySHA256(password, encodedHash)
+	default:
+		return false, ErrUnknownHashFormat
+	}
 }
 
+// SHA256Hasher оставлен только для проверки старых паролей
 type SHA256Hasher struct{}
 
 func (h *SHA256Hasher) GetHashPassword(password string) (string, error) {
@@ -21,3 +98,27 @@ func (h *SHA256Hasher) GetHashPassword(password string) (string, error) {
 	hashPass := hex.EncodeToString(hashBytes)
 	return hashPass, nil
 }
+
+func (h *SHA256Hasher) VerifyPassword(password, encodedHash string) (bool, error) {
+	return VerifyPassword(password, encodedHash)
+}
+
+func (h *SHA256Hasher) NeedsRehash(encodedHash string) bool {
+	return !isSHA256Hash(encodedHash)
+}
+
+func isSHA256Hash(encodedHash string) bool {
+	if len(encodedHash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(encodedHash)
+	return err == nil
+}
+
+func verifySHA256(password, encodedHash string) (bool, error) {
+	hashPass, err := (&SHA256Hasher{}).GetHashPassword(password)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare([]byte(hashPass), []byte(strings.ToLower(encodedHash))) == 1, nil
+}
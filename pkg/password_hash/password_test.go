@@ -0,0 +1,107 @@
+package password_hash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := &Argon2idHasher{Time: 1, Memory: 1024, Threads: 1, KeyLen: 16, SaltLen: 8}
+
+	hash, err := hasher.GetHashPassword("password")
+	assert.NoError(t, err)
+	assert.Contains(t, hash, "$argon2id$v=19$m=1024,t=1,p=1$")
+
+	otherHash, err := hasher.GetHashPassword("password")
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, otherHash)
+
+	isValid, err := hasher.VerifyPassword("password", hash)
+	assert.NoError(t, err)
+	assert.True(t, isValid)
+
+	isValid, err = hasher.VerifyPassword("wrong", hash)
+	assert.NoError(t, err)
+	assert.False(t, isValid)
+
+	assert.False(t, hasher.NeedsRehash(hash))
+	assert.True(t, NewArgon2idHasher().NeedsRehash(hash))
+
+	_, err = hasher.VerifyPassword("password", "$argon2id$v=19$m=bad$salt$key")
+	assert.Error(t, err)
+}
+
+func TestBcryptHasher(t *testing.T) {
+	hasher := &BcryptHasher{Cost: 4}
+
+	hash, err := hasher.GetHashPassword("password")
+	assert.NoError(t, err)
+
+	isValid, err := hasher.VerifyPassword("password", hash)
+	assert.NoError(t, err)
+	assert.True(t, isValid)
+
+	isValid, err = hasher.VerifyPassword("wrong", hash)
+	assert.NoError(t, err)
+	assert.False(t, isValid)
+
+	assert.False(t, hasher.NeedsRehash(hash))
+	assert.True(t, (&BcryptHasher{Cost: 5}).NeedsRehash(hash))
+	assert.True(t, hasher.NeedsRehash("ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1"))
+}
+
+func TestVerifyPassword(t *testing.T) {
+	legacyHash := "ee79976c9380d5e337fc1c095ece8c8f22f91f306ceeb161fa51fecede2c4ba1"
+
+	isValid, err := VerifyPassword("11111111", legacyHash)
+	assert.NoError(t, err)
+	assert.True(t, isValid)
+
+	isValid, err = VerifyPassword("22222222", legacyHash)
+	assert.NoError(t, err)
+	assert.False(t, isValid)
+
+	assert.True(t, NewArgon2idHasher().NeedsRehash(legacyHash))
+
+	_, err = VerifyPassword("11111111", "plain text")
+	assert.ErrorIs(t, err, ErrUnknownHashFormat)
+}
+
+func TestNewHasher(t *testing.T) {
+	hasher, err := NewHasher("", DefaultParams())
+	assert.NoError(t, err)
+	assert.Equal(t, NewArgon2idHasher(), hasher)
+
+	hasher, err = NewHasher(AlgorithmBcrypt, DefaultParams())
+	assert.NoError(t, err)
+	assert.Equal(t, NewBcryptHasher(), hasher)
+
+	params := Params{Argon2Time: 2, Argon2Memory: 32 * 1024, Argon2Threads: 4, BcryptCost: 12}
+	hasher, err = NewHasher(AlgorithmArgon2id, params)
+	assert.NoError(t, err)
+	argon2Hasher := hasher.(*Argon2idHasher)
+	assert.Equal(t, uint32(2), argon2Hasher.Time)
+	assert.Equal(t, uint32(32*1024), argon2Hasher.Memory)
+	assert.Equal(t, uint8(4), argon2Hasher.Threads)
+
+	hasher, err = NewHasher(AlgorithmBcrypt, params)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, hasher.(*BcryptHasher).Cost)
+
+	badParams := []Params{
+		{Argon2Time: 0, Argon2Memory: 1024, Argon2Threads: 1},
+		{Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 0},
+		{Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 256},
+		{Argon2Time: 1, Argon2Memory: 8, Argon2Threads: 2},
+	}
+	for _, p := range badParams {
+		_, err = NewHasher(AlgorithmArgon2id, p)
+		assert.Error(t, err, p)
+	}
+	_, err = NewHasher(AlgorithmBcrypt, Params{BcryptCost: 100})
+	assert.Error(t, err)
+
+	_, err = NewHasher("md5", DefaultParams())
+	assert.Error(t, err)
+}
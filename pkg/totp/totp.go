@@ -0,0 +1,89 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Параметры по умолчанию приложений-аутентификаторов, поэтому в otpauth URI они не передаются
+const (
+	Digits     = 6
+	Period     = 30 * time.Second
+	SecretSize = 20
+	Skew       = 1
+)
+
+var ErrBadSecret = errors.New("totp secret is not valid base32")
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func GenerateSecret() (string, error) {
+	secret := make([]byte, SecretSize)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return secretEncoding.EncodeToString(secret), nil
+}
+
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// GenerateCode вычисляет код по RFC 6238
+func GenerateCode(secret string, step int64) (string, error) {
+	key, err := secretEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", ErrBadSecret
+	}
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%1000000), nil
+}
+
+// Validate отклоняет коды шагов не позже lastUsedStep, чтобы код нельзя было использовать повторно
+func Validate(secret, code string, t time.Time, lastUsedStep int64) (int64, bool, error) {
+	if len(code) != Digits {
+		return 0, false, nil
+	}
+	currentStep := Step(t)
+	for step := currentStep - Skew; step <= currentStep+Skew; step++ {
+		if step <= lastUsedStep {
+			continue
+		}
+		expectedCode, err := GenerateCode(secret, step)
+		if err != nil {
+			return 0, false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expectedCode), []byte(code)) == 1 {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+func KeyURI(issuer, accountName, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	return (&url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + accountName,
+		RawQuery: query.Encode(),
+	}).String()
+}
@@ -0,0 +1,82 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// секрет "12345678901234567890" из приложения B RFC 6238
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateCode(t *testing.T) {
+	testCases := []struct {
+		unixTime int64
+		code     string
+	}{
+		{unixTime: 59, code: "287082"},
+		{unixTime: 1111111109, code: "081804"},
+		{unixTime: 1111111111, code: "050471"},
+		{unixTime: 1234567890, code: "005924"},
+		{unixTime: 2000000000, code: "279037"},
+	}
+	for _, tc := range testCases {
+		code, err := GenerateCode(rfcSecret, Step(time.Unix(tc.unixTime, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.code, code)
+	}
+
+	_, err := GenerateCode("not base32!", 1)
+	assert.ErrorIs(t, err, ErrBadSecret)
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	currentStep := Step(now)
+	previousCode, _ := GenerateCode(rfcSecret, currentStep-1)
+	currentCode, _ := GenerateCode(rfcSecret, currentStep)
+	oldCode, _ := GenerateCode(rfcSecret, currentStep-2)
+
+	testCases := []struct {
+		code         string
+		lastUsedStep int64
+		isValid      bool
+		step         int64
+	}{
+		{code: currentCode, isValid: true, step: currentStep},
+		{code: previousCode, isValid: true, step: currentStep - 1},
+		{code: oldCode, isValid: false},
+		{code: "12345", isValid: false},
+		// повторное использование кода
+		{code: currentCode, lastUsedStep: currentStep, isValid: false},
+		{code: previousCode, lastUsedStep: currentStep - 1, isValid: false},
+	}
+	for _, tc := range testCases {
+		step, isValid, err := Validate(rfcSecret, tc.code, now, tc.lastUsedStep)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.isValid, isValid)
+		assert.Equal(t, tc.step, step)
+	}
+
+	_, _, err := Validate("not base32!", "123456", now, 0)
+	assert.Error(t, err)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+	otherSecret, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.NotEqual(t, secret, otherSecret)
+}
+
+func TestKeyURI(t *testing.T) {
+	uri := KeyURI("Filmoteka", "admin", "ABC")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Filmoteka:admin?"))
+	assert.Contains(t, uri, "secret=ABC")
+	assert.Contains(t, uri, "issuer=Filmoteka")
+}